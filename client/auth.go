@@ -62,6 +62,19 @@ func (c AuthCredentials) Copy() *AuthCredentials {
 	return &c
 }
 
+// Authenticator performs the client side of a SASL authentication exchange, as initiated by an AUTHENTICATE response
+// to STARTUP. Use CqlClient.Authenticator to authenticate with a custom implementation.
+type Authenticator interface {
+
+	// InitialResponse returns the token of the first AUTH_RESPONSE message, given the authenticator class name
+	// advertised by the server in its AUTHENTICATE message.
+	InitialResponse(authenticator string) ([]byte, error)
+
+	// EvaluateChallenge returns the token of the AUTH_RESPONSE message to send in reply to the given AUTH_CHALLENGE
+	// token.
+	EvaluateChallenge(challenge []byte) ([]byte, error)
+}
+
 // A simple authenticator to perform plain-text authentications for CQL clients.
 type PlainTextAuthenticator struct {
 	Credentials *AuthCredentials
@@ -0,0 +1,128 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm       = "AWS4-HMAC-SHA256"
+	sigV4Service         = "cassandra"
+	sigV4TimestampLayout = "2006-01-02T15:04:05.000Z"
+	sigV4DateLayout      = "20060102"
+)
+
+var (
+	sigV4Mechanism   = []byte("SigV4\000\000")
+	sigV4NonceMarker = []byte("nonce=")
+)
+
+// SigV4Authenticator performs AWS Signature Version 4 authentications, as required by the SigV4 SASL mechanism of
+// Amazon Keyspaces (for Apache Cassandra): the initial response selects the mechanism, then the server sends a nonce
+// challenge, which is answered with a response signed with the configured AWS credentials. The authenticator class
+// name advertised by the server is ignored. Amazon Keyspaces also accepts plain-text authentication with
+// service-specific credentials, see PlainTextAuthenticator.
+type SigV4Authenticator struct {
+	// Region is the AWS region of the Amazon Keyspaces endpoint, e.g. "us-east-1".
+	Region string
+	// AccessKeyId is the AWS access key id.
+	AccessKeyId string
+	// SecretAccessKey is the AWS secret access key.
+	SecretAccessKey string
+	// SessionToken is the optional session token, required when using temporary AWS credentials.
+	SessionToken string
+
+	// now returns the signing time; it defaults to time.Now.
+	now func() time.Time
+}
+
+func (a *SigV4Authenticator) InitialResponse(string) ([]byte, error) {
+	return sigV4Mechanism, nil
+}
+
+func (a *SigV4Authenticator) EvaluateChallenge(challenge []byte) ([]byte, error) {
+	nonce, err := extractSigV4Nonce(challenge)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	return []byte(a.signedResponse(nonce, now().UTC())), nil
+}
+
+// extractSigV4Nonce extracts the nonce from a challenge of the form "nonce=<nonce>[,...]".
+func extractSigV4Nonce(challenge []byte) (string, error) {
+	start := bytes.Index(challenge, sigV4NonceMarker)
+	if start < 0 {
+		return "", fmt.Errorf("incorrect SASL challenge from server, expecting nonce, got: %v", string(challenge))
+	}
+	nonce := challenge[start+len(sigV4NonceMarker):]
+	if end := bytes.IndexByte(nonce, ','); end >= 0 {
+		nonce = nonce[:end]
+	}
+	return string(nonce), nil
+}
+
+func (a *SigV4Authenticator) signedResponse(nonce string, t time.Time) string {
+	timestamp := t.Format(sigV4TimestampLayout)
+	scope := strings.Join([]string{t.Format(sigV4DateLayout), a.Region, sigV4Service, "aws4_request"}, "/")
+	nonceHash := sha256.Sum256([]byte(nonce))
+	// the query string parameters are already in lexicographic order
+	canonicalRequest := strings.Join([]string{
+		"PUT",
+		"/authenticate",
+		"X-Amz-Algorithm=" + sigV4Algorithm +
+			"&X-Amz-Credential=" + a.AccessKeyId + "%2F" + url.QueryEscape(scope) +
+			"&X-Amz-Date=" + url.QueryEscape(timestamp) +
+			"&X-Amz-Expires=900",
+		"host:" + sigV4Service,
+		"",
+		"host",
+		hex.EncodeToString(nonceHash[:]),
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		timestamp,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+	signingKey := []byte("AWS4" + a.SecretAccessKey)
+	for _, part := range []string{t.Format(sigV4DateLayout), a.Region, sigV4Service, "aws4_request"} {
+		signingKey = hmacSha256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+	response := fmt.Sprintf("signature=%v,access_key=%v,amzdate=%v", signature, a.AccessKeyId, timestamp)
+	if a.SessionToken != "" {
+		response += ",session_token=" + a.SessionToken
+	}
+	return response
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -16,6 +16,7 @@ package client
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -69,3 +70,32 @@ func TestAuthCredentials_Unmarshal(t *testing.T) {
 	assert.Equal(t, "user1", credentials.Username)
 	assert.Equal(t, "pass1", credentials.Password)
 }
+
+func TestSigV4Authenticator(t *testing.T) {
+	authenticator := &SigV4Authenticator{
+		Region:          "us-west-2",
+		AccessKeyId:     "UserID-1",
+		SecretAccessKey: "UserSecretKey-1",
+		now: func() time.Time {
+			return time.Date(2020, 6, 9, 15, 41, 51, 0, time.FixedZone("PDT", -7*60*60))
+		},
+	}
+
+	initialResponse, err := authenticator.InitialResponse("com.amazonaws.cassandra.DefaultPasswordAuthenticator")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("SigV4\000\000"), initialResponse)
+
+	response, err := authenticator.EvaluateChallenge([]byte("nonce=91703fdc2ef562e19fbdab0f58e42fe5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "signature=7f3691c18a81b8ce7457699effbfae5b09b4e0714ab38c1292dbdf082c9ddd87,"+
+		"access_key=UserID-1,amzdate=2020-06-09T22:41:51.000Z", string(response))
+
+	authenticator.SessionToken = "sess-token-1"
+	response, err = authenticator.EvaluateChallenge([]byte("nonce=91703fdc2ef562e19fbdab0f58e42fe5,other=1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "signature=7f3691c18a81b8ce7457699effbfae5b09b4e0714ab38c1292dbdf082c9ddd87,"+
+		"access_key=UserID-1,amzdate=2020-06-09T22:41:51.000Z,session_token=sess-token-1", string(response))
+
+	_, err = authenticator.EvaluateChallenge([]byte("PLAIN-START"))
+	assert.Error(t, err)
+}
@@ -51,6 +51,16 @@ const ManagedStreamId int16 = 0
 // event.
 type EventHandler func(event *frame.Frame, conn *CqlClientConnection)
 
+// LateResponseHandler is a callback function that gets invoked whenever a CqlClientConnection receives a response for
+// a request that has already timed out. The elapsed parameter is the time elapsed since the request was sent. Late
+// responses are always discarded after this handler is invoked.
+type LateResponseHandler func(response *frame.Frame, conn *CqlClientConnection, elapsed time.Duration)
+
+// WarningHandler is a callback function that gets invoked whenever a CqlClientConnection receives a response frame
+// containing server warnings; it is invoked once per warning, before the frame is delivered. The opCode and streamId
+// parameters are those of the response frame.
+type WarningHandler func(warning string, opCode primitive.OpCode, streamId int16, conn *CqlClientConnection)
+
 // CqlClient is a client for Cassandra-compatible backends. It is preferable to create CqlClient instances using the
 // constructor function NewCqlClient. Once the client is created and properly configured, use Connect or ConnectAndInit
 // to establish new connections to the server.
@@ -59,11 +69,23 @@ type CqlClient struct {
 	RemoteAddress string
 	// The AuthCredentials for authenticated servers. If nil, no authentication will be used.
 	Credentials *AuthCredentials
+	// An optional Authenticator for authenticated servers; if set, it takes precedence over Credentials, which are
+	// otherwise used with a PlainTextAuthenticator.
+	Authenticator Authenticator
 	// The compression to use; if unspecified, no compression will be used.
 	Compression primitive.Compression
 	// The maximum number of in-flight requests to apply for each connection created with Connect. Must be strictly
 	// positive.
 	MaxInFlight int
+	// StreamIdExhaustionStrategy determines how requests with managed stream ids are handled when all stream ids are
+	// in flight; the default is StreamIdExhaustionFail.
+	StreamIdExhaustionStrategy StreamIdExhaustionStrategy
+	// StreamIdWaitTimeout is the maximum time to wait for a stream id with StreamIdExhaustionBlock. If zero, the
+	// request timeout is used instead.
+	StreamIdWaitTimeout time.Duration
+	// MaxOverflow is the maximum number of requests to queue with StreamIdExhaustionQueue, for each connection
+	// created with Connect. Must be strictly positive with that strategy.
+	MaxOverflow int
 	// The maximum number of pending responses awaiting delivery to store per request. Must be strictly positive.
 	// This is only useful when using continuous paging, a feature specific to DataStax Enterprise.
 	MaxPending int
@@ -73,6 +95,37 @@ type CqlClient struct {
 	ReadTimeout time.Duration
 	// An optional list of handlers to handle incoming events.
 	EventHandlers []EventHandler
+	// An optional handler to handle late responses, that is, responses received after their request timed out.
+	LateResponseHandler LateResponseHandler
+	// An optional handler to handle server warnings attached to response frames.
+	WarningHandler WarningHandler
+	// Recorder is an optional recorder for the frames sent and received by all connections created with Connect.
+	Recorder *FrameRecorder
+	// CosmosCompatibility enables workarounds for the Cassandra API of Azure Cosmos DB, and its limited feature set:
+	// error responses are decoded leniently, see message.NewLenientErrorCodec; handshakes with protocol versions
+	// higher than CosmosMaxProtocolVersion are rejected; and protocol version discovery never picks such versions.
+	// See also CosmosRequestCharge.
+	CosmosCompatibility bool
+	// BatchLimits, if not nil, are enforced on BATCH requests sent by all connections created with Connect: requests
+	// that do not honor them are rejected with a message.BatchLimitError before being sent.
+	BatchLimits *message.BatchLimits
+	// TimestampGenerator, if not nil, generates the default timestamps of QUERY, EXECUTE and BATCH requests sent by
+	// all connections created with Connect, unless the requests already have one.
+	TimestampGenerator TimestampGenerator
+	// WriteBatchSize is the maximum number of outgoing frames to buffer before writing them to the network, for each
+	// connection created with Connect; batching writes amortizes the cost of system calls when many requests are sent
+	// concurrently. Buffered frames are also written when WriteBatchDelay elapses. Zero or one, which is the default,
+	// disables batching: each frame is written as soon as it is sent. Batching only applies to the legacy framing
+	// layout; in the modern framing layout of protocol v5 and higher, outgoing frames are always coalesced into
+	// segments.
+	WriteBatchSize int
+	// WriteBatchDelay is the maximum time to wait for more outgoing frames before writing a partial batch to the
+	// network, see WriteBatchSize. If zero, a partial batch is written as soon as no more frames are waiting to be
+	// written.
+	WriteBatchDelay time.Duration
+	// ReadLoopMode determines which goroutine reads incoming frames on each connection created with Connect; the
+	// default is ReadLoopOwned. See ReadLoopMode.
+	ReadLoopMode ReadLoopMode
 	// TLSConfig is the TLS configuration to use.
 	TLSConfig *tls.Config
 }
@@ -114,16 +167,7 @@ func (client *CqlClient) Connect(ctx context.Context) (*CqlClientConnection, err
 		return nil, fmt.Errorf("%v: cannot establish TCP connection: %w", client, err)
 	} else {
 		log.Debug().Msgf("%v: new TCP connection established", client)
-		if connection, err := newCqlClientConnection(
-			conn,
-			ctx,
-			client.Credentials,
-			client.Compression,
-			client.MaxInFlight,
-			client.MaxPending,
-			client.ReadTimeout,
-			client.EventHandlers,
-		); err != nil {
+		if connection, err := newCqlClientConnection(client, conn, ctx); err != nil {
 			log.Err(err).Msgf("%v: cannot establish CQL connection", client)
 			_ = conn.Close()
 			return nil, err
@@ -143,13 +187,43 @@ func (client *CqlClient) ConnectAndInit(
 	version primitive.ProtocolVersion,
 	streamId int16,
 ) (*CqlClientConnection, error) {
-	if connection, err := client.Connect(ctx); err != nil {
+	if err := client.checkProtocolVersion(version); err != nil {
+		return nil, err
+	} else if connection, err := client.Connect(ctx); err != nil {
 		return nil, err
 	} else {
 		return connection, connection.InitiateHandshake(version, streamId)
 	}
 }
 
+// ConnectAndInitWithDiscovery establishes a new TCP connection to the server, then discovers the highest protocol
+// version supported by both the server and this library, see CqlClientConnection.DiscoverProtocolVersion, and finally
+// initiates a handshake procedure using that version. The given version is used for the discovery request, and for
+// the handshake if the server does not advertise its protocol versions. The CqlClientConnection connection will be
+// fully initialized when this method returns, along with the protocol version in use.
+// Use stream id zero to activate automatic stream id management.
+// Set ctx to context.Background if no parent context exists.
+func (client *CqlClient) ConnectAndInitWithDiscovery(
+	ctx context.Context,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (*CqlClientConnection, primitive.ProtocolVersion, error) {
+	if client.CosmosCompatibility && version > CosmosMaxProtocolVersion {
+		version = CosmosMaxProtocolVersion
+	}
+	connection, err := client.Connect(ctx)
+	if err != nil {
+		return nil, version, err
+	}
+	if version, err = connection.DiscoverProtocolVersion(version, streamId); err != nil {
+		return connection, version, err
+	}
+	if client.CosmosCompatibility && version > CosmosMaxProtocolVersion {
+		version = CosmosMaxProtocolVersion
+	}
+	return connection, version, connection.InitiateHandshake(version, streamId)
+}
+
 // CqlClientConnection encapsulates a TCP client connection to a remote Cassandra-compatible backend.
 // CqlClientConnection instances should be created by calling CqlClient.Connect or CqlClient.ConnectAndInit.
 type CqlClientConnection struct {
@@ -160,8 +234,18 @@ type CqlClientConnection struct {
 	modernLayout       bool
 	readTimeout        time.Duration
 	credentials        *AuthCredentials
+	authenticator      Authenticator
 	handlers           []EventHandler
+	warningHandler     WarningHandler
+	recorder           *FrameRecorder
+	batchLimits        *message.BatchLimits
+	timestampGenerator TimestampGenerator
+	writeBatchSize     int
+	writeBatchDelay    time.Duration
+	readLoopMode       ReadLoopMode
+	pulled             []*frame.Frame
 	inFlightHandler    *inFlightRequestsHandler
+	channelsLock       sync.RWMutex // guards sends to outgoing and events against their closing
 	outgoing           chan *frame.Frame
 	events             chan *frame.Frame
 	waitGroup          *sync.WaitGroup
@@ -169,55 +253,97 @@ type CqlClientConnection struct {
 	ctx                context.Context
 	cancel             context.CancelFunc
 	payloadAccumulator *payloadAccumulator
+	prepared           *PreparedRegistry
+	supported          supportedCache
 }
 
-func newCqlClientConnection(
-	conn net.Conn,
-	ctx context.Context,
-	credentials *AuthCredentials,
-	compression primitive.Compression,
-	maxInFlight int,
-	maxPending int,
-	readTimeout time.Duration,
-	handlers []EventHandler,
-) (*CqlClientConnection, error) {
+// newCqlClientConnection creates a new CqlClientConnection over the given TCP connection, configured with the settings
+// of the given client.
+func newCqlClientConnection(client *CqlClient, conn net.Conn, ctx context.Context) (*CqlClientConnection, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("TCP connection cannot be nil")
 	}
 	if ctx == nil {
 		return nil, fmt.Errorf("context cannot be nil")
 	}
-	if maxInFlight < 1 {
-		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", maxInFlight)
-	} else if maxInFlight > math.MaxInt16 {
-		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, maxInFlight)
+	if client.MaxInFlight < 1 {
+		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", client.MaxInFlight)
+	} else if client.MaxInFlight > math.MaxInt16 {
+		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, client.MaxInFlight)
+	}
+	if client.MaxPending < 1 {
+		return nil, fmt.Errorf("max pending: expecting positive, got: %v", client.MaxPending)
+	}
+	switch client.StreamIdExhaustionStrategy {
+	case StreamIdExhaustionFail, StreamIdExhaustionBlock:
+	case StreamIdExhaustionQueue:
+		if client.MaxOverflow < 1 {
+			return nil, fmt.Errorf("max overflow: expecting positive, got: %v", client.MaxOverflow)
+		}
+	default:
+		return nil, fmt.Errorf("unknown stream id exhaustion strategy: %v", client.StreamIdExhaustionStrategy)
+	}
+	if client.StreamIdWaitTimeout < 0 {
+		return nil, fmt.Errorf("stream id wait timeout: expecting positive or zero, got: %v", client.StreamIdWaitTimeout)
+	}
+	if client.WriteBatchSize < 0 {
+		return nil, fmt.Errorf("write batch size: expecting positive or zero, got: %v", client.WriteBatchSize)
+	} else if client.WriteBatchDelay < 0 {
+		return nil, fmt.Errorf("write batch delay: expecting positive or zero, got: %v", client.WriteBatchDelay)
 	}
-	if maxPending < 1 {
-		return nil, fmt.Errorf("max pending: expecting positive, got: %v", maxInFlight)
+	if client.ReadLoopMode != ReadLoopOwned && client.ReadLoopMode != ReadLoopPull {
+		return nil, fmt.Errorf("unknown read loop mode: %v", client.ReadLoopMode)
 	}
-	frameCodec := frame.NewCodecWithCompression(NewBodyCompressor(compression))
+	compression := client.Compression
+	frameCodec := frame.NewClientCodecWithCompression(NewBodyCompressor(compression), client.messageCodecs()...)
 	segmentCodec := segment.NewCodecWithCompression(NewPayloadCompressor(compression))
 	if compression == "" {
 		compression = primitive.CompressionNone
 	}
 	connection := &CqlClientConnection{
-		conn:         conn,
-		frameCodec:   frameCodec,
-		segmentCodec: segmentCodec,
-		compression:  compression,
-		readTimeout:  readTimeout,
-		credentials:  credentials,
-		handlers:     handlers,
-		outgoing:     make(chan *frame.Frame, maxInFlight),
-		events:       make(chan *frame.Frame, maxInFlight),
-		waitGroup:    &sync.WaitGroup{},
+		conn:               conn,
+		frameCodec:         frameCodec,
+		segmentCodec:       segmentCodec,
+		compression:        compression,
+		readTimeout:        client.ReadTimeout,
+		credentials:        client.Credentials,
+		authenticator:      client.Authenticator,
+		handlers:           client.EventHandlers,
+		warningHandler:     client.WarningHandler,
+		recorder:           client.Recorder,
+		batchLimits:        client.BatchLimits,
+		timestampGenerator: client.TimestampGenerator,
+		writeBatchSize:     client.WriteBatchSize,
+		writeBatchDelay:    client.WriteBatchDelay,
+		readLoopMode:       client.ReadLoopMode,
+		outgoing:           make(chan *frame.Frame, client.MaxInFlight),
+		events:             make(chan *frame.Frame, client.MaxInFlight),
+		waitGroup:          &sync.WaitGroup{},
 		payloadAccumulator: &payloadAccumulator{
-			frameCodec: frame.NewRawCodec(), // without compression
+			frameCodec: frame.NewClientCodec(), // without compression
 		},
+		prepared: NewPreparedRegistry(),
 	}
 	connection.ctx, connection.cancel = context.WithCancel(ctx)
-	connection.inFlightHandler = newInFlightRequestsHandler(connection.String(), connection.ctx, maxInFlight, maxPending, readTimeout)
-	connection.incomingLoop()
+	connection.inFlightHandler = newInFlightRequestsHandler(
+		connection.String(),
+		connection.ctx,
+		client.MaxInFlight,
+		client.MaxPending,
+		client.ReadTimeout,
+	)
+	connection.inFlightHandler.strategy = client.StreamIdExhaustionStrategy
+	connection.inFlightHandler.waitTimeout = client.StreamIdWaitTimeout
+	connection.inFlightHandler.maxOverflow = client.MaxOverflow
+	connection.inFlightHandler.dispatch = connection.enqueueOutgoing
+	if client.LateResponseHandler != nil {
+		connection.inFlightHandler.onLateFrame = func(f *frame.Frame, elapsed time.Duration) {
+			client.LateResponseHandler(f, connection, elapsed)
+		}
+	}
+	if client.ReadLoopMode == ReadLoopOwned {
+		connection.incomingLoop()
+	}
 	connection.outgoingLoop()
 	connection.awaitDone()
 	return connection, nil
@@ -292,8 +418,9 @@ func (c *CqlClientConnection) outgoingLoop() {
 			} else {
 				log.Debug().Msgf("%v: sending outgoing frame: %v", c, outgoing)
 				if c.modernLayout {
-					// TODO write coalescer
-					abort = c.writeSegment(outgoing, c.conn)
+					abort = c.writeSegments(outgoing)
+				} else if c.writeBatchSize > 1 {
+					abort = c.writeBatch(outgoing)
 				} else {
 					abort = c.writeFrame(outgoing, c.conn)
 				}
@@ -359,23 +486,43 @@ func (c *CqlClientConnection) addMultiSegmentPayload(payload *segment.Payload) (
 	return false
 }
 
-func (c *CqlClientConnection) writeSegment(outgoing *frame.Frame, dest io.Writer) (abort bool) {
-	// never compress frames individually when included in a segment
-	outgoing.Header.Flags = outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
-	encodedFrame := &bytes.Buffer{}
-	if abort = c.writeFrame(outgoing, encodedFrame); abort {
-		abort = true
-	} else {
-		seg := &segment.Segment{
-			Header:  &segment.Header{IsSelfContained: true},
-			Payload: &segment.Payload{UncompressedData: encodedFrame.Bytes()},
-		}
-		if err := c.segmentCodec.EncodeSegment(seg, dest); err != nil {
-			abort = c.reportConnectionFailure(err, false)
+// writeSegments writes the given frame, along with the frames already waiting in the outgoing queue, if any,
+// coalescing small frames into shared segments.
+func (c *CqlClientConnection) writeSegments(outgoing *frame.Frame) (abort bool) {
+	writer := segment.NewWriter(c.conn, c.segmentCodec)
+	for outgoing != nil {
+		// never compress frames individually when included in a segment
+		outgoing.Header.Flags = outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+		if length, err := c.frameCodec.EncodedFrameLength(outgoing); err == nil && length > segment.MaxPayloadLength {
+			// encode large frames, e.g. frames with streamed values, directly into multi-segment parts
+			if envelope, err := writer.LargeEnvelopeWriter(length); err != nil {
+				return c.reportConnectionFailure(err, false)
+			} else if abort = c.writeFrame(outgoing, envelope); abort {
+				return abort
+			} else if err := envelope.Close(); err != nil {
+				return c.reportConnectionFailure(err, false)
+			}
 		} else {
-			log.Debug().Msgf("%v: outgoing segment successfully written: %v (frame: %v)", c, seg, outgoing)
+			encodedFrame := &bytes.Buffer{}
+			if abort = c.writeFrame(outgoing, encodedFrame); abort {
+				return abort
+			} else if err := writer.WriteEnvelope(encodedFrame.Bytes()); err != nil {
+				return c.reportConnectionFailure(err, false)
+			}
 		}
+		select {
+		case next, ok := <-c.outgoing:
+			if outgoing = next; ok {
+				log.Debug().Msgf("%v: coalescing outgoing frame: %v", c, outgoing)
+			}
+		default:
+			outgoing = nil
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return c.reportConnectionFailure(err, false)
 	}
+	log.Debug().Msgf("%v: outgoing segments successfully written", c)
 	return abort
 }
 
@@ -389,6 +536,46 @@ func (c *CqlClientConnection) readFrame(source io.Reader) (abort bool) {
 	return abort
 }
 
+// writeBatch writes the given frame, along with the frames sent until the batch is full or the batch delay elapses, in
+// a single network write.
+func (c *CqlClientConnection) writeBatch(outgoing *frame.Frame) (abort bool) {
+	var deadline <-chan time.Time
+	if c.writeBatchDelay > 0 {
+		timer := time.NewTimer(c.writeBatchDelay)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	batch := &bytes.Buffer{}
+	for count := 1; outgoing != nil; count++ {
+		if abort = c.writeFrame(outgoing, batch); abort {
+			return abort
+		} else if count == c.writeBatchSize {
+			break
+		}
+		var ok bool
+		if deadline == nil {
+			select {
+			case outgoing, ok = <-c.outgoing:
+			default:
+			}
+		} else {
+			select {
+			case outgoing, ok = <-c.outgoing:
+			case <-deadline:
+			case <-c.ctx.Done():
+			}
+		}
+		if !ok {
+			outgoing = nil
+		}
+	}
+	if _, err := batch.WriteTo(c.conn); err != nil {
+		return c.reportConnectionFailure(err, false)
+	}
+	log.Debug().Msgf("%v: outgoing batch successfully written", c)
+	return abort
+}
+
 func (c *CqlClientConnection) maybeSwitchToModernLayout(incoming *frame.Frame) {
 	if !c.modernLayout &&
 		incoming.Header.Version.SupportsModernFramingLayout() &&
@@ -401,10 +588,17 @@ func (c *CqlClientConnection) maybeSwitchToModernLayout(incoming *frame.Frame) {
 }
 
 func (c *CqlClientConnection) writeFrame(outgoing *frame.Frame, dest io.Writer) (abort bool) {
+	var kept *frame.Frame
+	if c.recorder != nil {
+		kept = c.recorder.keep(outgoing)
+	}
 	if err := c.frameCodec.EncodeFrame(outgoing, dest); err != nil {
 		abort = c.reportConnectionFailure(err, false)
 	} else {
 		log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+		if c.recorder != nil {
+			c.recorder.record(FrameSent, outgoing.Header, kept, c.compression, c)
+		}
 	}
 	return abort
 }
@@ -427,17 +621,29 @@ func (c *CqlClientConnection) reportConnectionFailure(err error, read bool) (abo
 
 func (c *CqlClientConnection) processIncomingFrame(incoming *frame.Frame) (abort bool) {
 	log.Debug().Msgf("%v: received incoming frame: %v", c, incoming)
+	if c.recorder != nil {
+		c.recorder.RecordFrame(FrameReceived, incoming, c.compression, c)
+	}
+	if c.warningHandler != nil {
+		for _, warning := range incoming.Body.Warnings {
+			c.warningHandler(warning, incoming.Header.OpCode, incoming.Header.StreamId, c)
+		}
+	}
+	if c.readLoopMode == ReadLoopPull {
+		c.pulled = append(c.pulled, incoming)
+	}
 	if incoming.Header.OpCode == primitive.OpCodeEvent {
 		for _, handler := range c.handlers {
 			handler(incoming, c)
 		}
-		select {
-		case c.events <- incoming:
-			log.Debug().Msgf("%v: incoming event frame successfully delivered: %v", c, incoming)
-		default:
-			log.Error().Msgf("%v: events queue is full, discarding event frame: %v", c, incoming)
+		if c.readLoopMode == ReadLoopPull {
+			return
 		}
+		c.deliverEvent(incoming)
 	} else {
+		if supported, ok := incoming.Body.Message.(*message.Supported); ok {
+			c.supported.set(supported)
+		}
 		if err := c.inFlightHandler.onIncomingFrameReceived(incoming); err != nil {
 			log.Error().Err(err).Msgf("%v: incoming frame delivery failed: %v", c, incoming)
 		} else {
@@ -454,6 +660,20 @@ func (c *CqlClientConnection) processIncomingFrame(incoming *frame.Frame) (abort
 	return
 }
 
+func (c *CqlClientConnection) deliverEvent(incoming *frame.Frame) {
+	c.channelsLock.RLock()
+	defer c.channelsLock.RUnlock()
+	if c.IsClosed() {
+		return
+	}
+	select {
+	case c.events <- incoming:
+		log.Debug().Msgf("%v: incoming event frame successfully delivered: %v", c, incoming)
+	default:
+		log.Error().Msgf("%v: events queue is full, discarding event frame: %v", c, incoming)
+	}
+}
+
 func (c *CqlClientConnection) awaitDone() {
 	c.waitGroup.Add(1)
 	go func() {
@@ -470,11 +690,12 @@ func (c *CqlClientConnection) awaitDone() {
 func (c *CqlClientConnection) NewStartupRequest(version primitive.ProtocolVersion, streamId int16) (*frame.Frame, error) {
 	startup := message.NewStartup()
 	if c.compression != primitive.CompressionNone {
-		if version.SupportsCompression(c.compression) {
-			startup.SetCompression(c.compression)
-		} else {
+		if !version.SupportsCompression(c.compression) {
 			return nil, fmt.Errorf("%v does not support compression %v", version, c.compression)
+		} else if supported, known := c.SupportsCompression(c.compression); known && !supported {
+			return nil, fmt.Errorf("server does not support compression %v", c.compression)
 		}
+		startup.SetCompression(c.compression)
 	}
 	startup.SetDriverName("DataStax Go client")
 	return frame.NewFrame(version, streamId, startup), nil
@@ -511,24 +732,60 @@ type InFlightRequest interface {
 // to be automatically assigned by the connection upon write. Users are free to choose between managed stream ids or
 // manually assigned ones, but it is not recommended mixing managed stream ids with non-managed ones on the same
 // connection.
+// Orphaned stream ids: when a request times out, its stream id is considered orphaned and will not be reused, neither
+// by managed nor by manually-assigned stream ids, until the late response arrives or the connection is closed. This
+// prevents late responses from being delivered to the wrong request.
+// Stream id exhaustion: when all managed stream ids are in flight, the request is either rejected with a *BusyError,
+// or delayed until a stream id is released, depending on CqlClient.StreamIdExhaustionStrategy.
 func (c *CqlClientConnection) Send(f *frame.Frame) (InFlightRequest, error) {
+	return c.SendWithTimeout(f, c.readTimeout)
+}
+
+// SendWithTimeout is similar to Send, but applies the given timeout to the request, instead of the connection's
+// configured read timeout. The timeout must be strictly positive.
+func (c *CqlClientConnection) SendWithTimeout(f *frame.Frame, timeout time.Duration) (InFlightRequest, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("%v: timeout: expecting positive, got: %v", c, timeout)
+	}
 	if f == nil {
 		return nil, fmt.Errorf("%v: frame cannot be nil", c)
 	}
 	if c.IsClosed() {
-		return nil, fmt.Errorf("%v: connection closed", c)
+		return nil, fmt.Errorf("%v: %w", c, ErrConnectionClosed)
+	}
+	if batch, ok := f.Body.Message.(*message.Batch); ok {
+		if err := c.batchLimits.Check(batch, f.Header.Version); err != nil {
+			return nil, fmt.Errorf("%v: cannot send frame: %v: %w", c, f, err)
+		}
+	}
+	if c.timestampGenerator != nil {
+		f = setDefaultTimestamp(f, c.timestampGenerator)
 	}
 	log.Debug().Msgf("%v: enqueuing outgoing frame: %v", c, f)
-	if inFlight, err := c.inFlightHandler.onOutgoingFrameEnqueued(f); err != nil {
+	if inFlight, queued, err := c.inFlightHandler.onOutgoingFrameEnqueued(f, timeout); err != nil {
 		return nil, fmt.Errorf("%v: failed to register in-flight handler for frame: %v: %w", c, f, err)
+	} else if queued {
+		log.Debug().Msgf("%v: outgoing frame queued until a stream id is available: %v", c, f)
+		return inFlight, nil
+	} else if err := c.enqueueOutgoing(f); err != nil {
+		return nil, err
 	} else {
-		select {
-		case c.outgoing <- f:
-			log.Debug().Msgf("%v: outgoing frame successfully enqueued: %v", c, f)
-			return inFlight, nil
-		default:
-			return nil, fmt.Errorf("%v: failed to enqueue outgoing frame: %v", c, f)
-		}
+		return inFlight, nil
+	}
+}
+
+func (c *CqlClientConnection) enqueueOutgoing(f *frame.Frame) error {
+	c.channelsLock.RLock()
+	defer c.channelsLock.RUnlock()
+	if c.IsClosed() {
+		return fmt.Errorf("%v: %w", c, ErrConnectionClosed)
+	}
+	select {
+	case c.outgoing <- f:
+		log.Debug().Msgf("%v: outgoing frame successfully enqueued: %v", c, f)
+		return nil
+	default:
+		return fmt.Errorf("%v: failed to enqueue outgoing frame: %v", c, f)
 	}
 }
 
@@ -563,6 +820,12 @@ func (c *CqlClientConnection) SendAndReceive(f *frame.Frame) (*frame.Frame, erro
 	}
 }
 
+// LateResponses returns the total number of late responses received so far on this connection, that is, responses
+// received after their request timed out. Late responses are always discarded.
+func (c *CqlClientConnection) LateResponses() uint64 {
+	return c.inFlightHandler.lateFramesCount()
+}
+
 // EventChannel is a receive-only channel for incoming events. A receive channel can be obtained through
 // CqlClientConnection.EventChannel.
 type EventChannel <-chan *frame.Frame
@@ -570,6 +833,9 @@ type EventChannel <-chan *frame.Frame
 // EventChannel returns a channel for listening to incoming events received on this connection. This channel will be
 // closed when the connection is closed. If this connection has already been closed, this method returns nil.
 func (c *CqlClientConnection) EventChannel() EventChannel {
+	if c.IsClosed() {
+		return nil
+	}
 	return c.events
 }
 
@@ -577,7 +843,7 @@ func (c *CqlClientConnection) EventChannel() EventChannel {
 // is closed, whichever happens first. Returns the event frame, if any.
 func (c *CqlClientConnection) ReceiveEvent() (*frame.Frame, error) {
 	if c.IsClosed() {
-		return nil, fmt.Errorf("%v: connection closed", c)
+		return nil, fmt.Errorf("%v: %w", c, ErrConnectionClosed)
 	}
 	select {
 	case incoming, ok := <-c.events:
@@ -603,12 +869,10 @@ func (c *CqlClientConnection) Close() (err error) {
 		log.Debug().Msgf("%v: closing", c)
 		c.cancel()
 		err = c.conn.Close()
-		outgoing := c.outgoing
-		events := c.events
-		c.outgoing = nil
-		c.events = nil
-		close(outgoing)
-		close(events)
+		c.channelsLock.Lock()
+		close(c.outgoing)
+		close(c.events)
+		c.channelsLock.Unlock()
 		c.inFlightHandler.close()
 		c.waitGroup.Wait()
 		if err != nil {
@@ -15,7 +15,9 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/datastax/go-cassandra-native-protocol/client"
 	"github.com/datastax/go-cassandra-native-protocol/frame"
@@ -23,11 +25,187 @@ import (
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math/rand"
 	"sync"
 	"testing"
 	"time"
 )
 
+// Frames larger than a segment payload are split into multi-segment parts in protocol v5, while small frames are
+// coalesced into shared segments.
+func TestLocalServer_ModernFramingLayout(t *testing.T) {
+	largeCell := make([]byte, 300_000)
+	rand.New(rand.NewSource(0)).Read(largeCell)
+	rowsHandler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if query, ok := request.Body.Message.(*message.Query); ok {
+			cell := largeCell
+			if len(query.Query) < 100 {
+				cell = []byte(query.Query)
+			}
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{
+				Metadata: &message.RowsMetadata{ColumnCount: 1},
+				Data:     message.RowSet{{cell}},
+			})
+		}
+		return nil
+	}
+	for _, compression := range []primitive.Compression{primitive.CompressionNone, primitive.CompressionLz4} {
+		t.Run(string(compression), func(t *testing.T) {
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, rowsHandler}
+			clt := client.NewCqlClient("127.0.0.1:9043", nil)
+			clt.Compression = compression
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			require.NoError(t, server.Start(ctx))
+			clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion5, client.ManagedStreamId)
+			require.NoError(t, err)
+
+			large := frame.NewFrame(primitive.ProtocolVersion5, client.ManagedStreamId, &message.Query{Query: string(largeCell)})
+			response, err := clientConn.SendAndReceive(large)
+			require.NoError(t, err)
+			require.IsType(t, &message.RowsResult{}, response.Body.Message)
+			assert.Equal(t, largeCell, response.Body.Message.(*message.RowsResult).Data[0][0])
+
+			wg := &sync.WaitGroup{}
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					query := fmt.Sprintf("SELECT %d", i)
+					small := frame.NewFrame(primitive.ProtocolVersion5, client.ManagedStreamId, &message.Query{Query: query})
+					response, err := clientConn.SendAndReceive(small)
+					if assert.NoError(t, err) && assert.IsType(t, &message.RowsResult{}, response.Body.Message) {
+						assert.Equal(t, []byte(query), response.Body.Message.(*message.RowsResult).Data[0][0])
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			cancelFn()
+			checkClosed(t, clientConn, server)
+		})
+	}
+}
+
+func TestLocalServer_StreamValue(t *testing.T) {
+	largeValue := make([]byte, 1_000_000)
+	rand.New(rand.NewSource(0)).Read(largeValue)
+	echoHandler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if query, ok := request.Body.Message.(*message.Query); ok {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{
+				Metadata: &message.RowsMetadata{ColumnCount: 1},
+				Data:     message.RowSet{{query.Options.PositionalValues[0].Contents}},
+			})
+		}
+		return nil
+	}
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion4, primitive.ProtocolVersion5} {
+		t.Run(version.String(), func(t *testing.T) {
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, echoHandler}
+			clt := client.NewCqlClient("127.0.0.1:9043", nil)
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			require.NoError(t, server.Start(ctx))
+			clientConn, err := clt.ConnectAndInit(ctx, version, client.ManagedStreamId)
+			require.NoError(t, err)
+
+			query := frame.NewFrame(version, client.ManagedStreamId, &message.Query{
+				Query: "INSERT INTO ks.blobs (id, contents) VALUES (1, ?)",
+				Options: &message.QueryOptions{
+					PositionalValues: []*primitive.Value{primitive.NewStreamValue(bytes.NewReader(largeValue), len(largeValue))},
+				},
+			})
+			response, err := clientConn.SendAndReceive(query)
+			require.NoError(t, err)
+			require.IsType(t, &message.RowsResult{}, response.Body.Message)
+			assert.Equal(t, largeValue, response.Body.Message.(*message.RowsResult).Data[0][0])
+
+			cancelFn()
+			checkClosed(t, clientConn, server)
+		})
+	}
+}
+
+func TestLocalServer_BatchLimits(t *testing.T) {
+	batchHandler := func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		if _, ok := request.Body.Message.(*message.Batch); ok {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+		}
+		return nil
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, batchHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.BatchLimits = &message.BatchLimits{MaxStatements: 2}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	child := &message.BatchChild{Query: "INSERT INTO ks.t (k) VALUES (1)"}
+	large := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Batch{
+		Children: []*message.BatchChild{child, child, child},
+	})
+	_, err = clientConn.SendAndReceive(large)
+	var limitErr *message.BatchLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 3, limitErr.Actual)
+
+	// the connection is still usable
+	small := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Batch{
+		Children: []*message.BatchChild{child, child},
+	})
+	response, err := clientConn.SendAndReceive(small)
+	require.NoError(t, err)
+	assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestLocalServer_WriteBatching(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.WriteBatchSize = 4
+	clt.WriteBatchDelay = 100 * time.Millisecond
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	// a partial batch is written when the batch delay elapses
+	start := time.Now()
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	assert.NotNil(t, response)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(clt.WriteBatchDelay))
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+			assert.NoError(t, err)
+			assert.NotNil(t, response)
+		}()
+	}
+	wg.Wait()
+
+	clt.WriteBatchSize = -1
+	_, err = clt.Connect(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "write batch size: expecting positive or zero, got: -1")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
 func TestLocalServer(t *testing.T) {
 
 	for _, version := range primitive.SupportedProtocolVersions() {
@@ -0,0 +1,221 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ViolationRule identifies a rule of the protocol specification that a client violated.
+type ViolationRule string
+
+const (
+	// ViolationRuleMalformed is reported when a frame cannot be decoded at all, e.g. because its protocol version is
+	// not supported, its direction bit is set, its opcode is unknown, or its body is truncated.
+	ViolationRuleMalformed = ViolationRule("malformed")
+	// ViolationRuleFlags is reported when a frame has unknown header flags, or flags that are not legal for its
+	// protocol version or in its context, e.g. the compressed flag when no compression was negotiated.
+	ViolationRuleFlags = ViolationRule("flags")
+	// ViolationRuleVersion is reported when a frame uses a protocol version different from the one negotiated on the
+	// connection, or an opcode that is not available in its protocol version.
+	ViolationRuleVersion = ViolationRule("version")
+	// ViolationRuleStreamId is reported when a request uses a negative stream id; negative stream ids are reserved
+	// for server-initiated messages.
+	ViolationRuleStreamId = ViolationRule("stream-id")
+	// ViolationRuleSequence is reported when a request is sent at the wrong time, e.g. a QUERY before STARTUP.
+	ViolationRuleSequence = ViolationRule("sequence")
+	// ViolationRuleLength is reported when the body length declared in the frame header is not consistent with the
+	// body contents, e.g. when the body contains trailing bytes after the message.
+	ViolationRuleLength = ViolationRule("length")
+)
+
+// ProtocolViolation is a violation of the protocol specification by a client, as detected by a
+// ClientConformanceChecker.
+type ProtocolViolation struct {
+	// Rule is the rule that was violated.
+	Rule ViolationRule
+	// Header is the header of the offending frame; it is nil if the header itself could not be decoded.
+	Header *frame.Header
+	// Message describes the violation.
+	Message string
+}
+
+// Error returns the same description as String, so that rejected violations can be surfaced as errors.
+func (v *ProtocolViolation) Error() string {
+	return v.String()
+}
+
+func (v *ProtocolViolation) String() string {
+	if v.Header == nil {
+		return fmt.Sprintf("[%v] %v", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("[%v] %v (stream id: %v, opcode: %v, version: %v)",
+		v.Rule, v.Message, v.Header.StreamId, v.Header.OpCode, v.Header.Version)
+}
+
+// ViolationHandler is a callback function that gets invoked whenever a ClientConformanceChecker detects a protocol
+// violation on a server connection.
+type ViolationHandler func(violation *ProtocolViolation, conn *CqlServerConnection)
+
+// ClientConformanceChecker validates incoming client frames against the protocol specification, and reports
+// violations; this allows driver authors to test their implementations against a CqlServer. Set it on
+// CqlServer.ConformanceChecker to enable it. Violations are reported but do not affect how requests are processed,
+// unless their rule is listed in Reject. It is preferable to create ClientConformanceChecker instances using the
+// constructor function NewClientConformanceChecker. It is safe for concurrent use.
+type ClientConformanceChecker struct {
+	// OnViolation is an optional callback invoked for each detected violation.
+	OnViolation ViolationHandler
+	// Reject is an optional set of rules whose violations are rejected: the offending request is answered with a
+	// ProtocolError response carrying its protocol version and stream id, and is neither delivered to request handlers
+	// nor returned by CqlServerConnection.Receive. In particular, if ViolationRuleMalformed is set, requests whose body
+	// cannot be decoded are rejected this way instead of causing the connection to be closed. Frames whose header
+	// cannot be decoded always cause the connection to be closed, since the stream id to respond to is unknown.
+	Reject map[ViolationRule]bool
+
+	lock       sync.Mutex
+	violations []*ProtocolViolation
+}
+
+func NewClientConformanceChecker() *ClientConformanceChecker {
+	return &ClientConformanceChecker{}
+}
+
+// Violations returns the violations detected so far, in the order they were detected.
+func (checker *ClientConformanceChecker) Violations() []*ProtocolViolation {
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+	return append([]*ProtocolViolation(nil), checker.violations...)
+}
+
+// Reset discards the violations detected so far.
+func (checker *ClientConformanceChecker) Reset() {
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+	checker.violations = nil
+}
+
+func (checker *ClientConformanceChecker) report(violation *ProtocolViolation, conn *CqlServerConnection) {
+	log.Warn().Msgf("%v: protocol violation: %v", conn, violation)
+	checker.lock.Lock()
+	checker.violations = append(checker.violations, violation)
+	checker.lock.Unlock()
+	if checker.OnViolation != nil {
+		checker.OnViolation(violation, conn)
+	}
+}
+
+// requestValidator holds the per-connection state of a ClientConformanceChecker.
+type requestValidator struct {
+	checker *ClientConformanceChecker
+	// startupVersion is the protocol version of the STARTUP request, or zero if no STARTUP was received yet.
+	startupVersion primitive.ProtocolVersion
+	// startupRecorded is true if startupVersion was set by the current request.
+	startupRecorded bool
+	// rejection is the first violation reported for the current request whose rule is rejected, if any.
+	rejection *ProtocolViolation
+}
+
+func (v *requestValidator) report(violation *ProtocolViolation, conn *CqlServerConnection) {
+	v.checker.report(violation, conn)
+	if v.rejection == nil && violation.Header != nil && v.checker.Reject[violation.Rule] {
+		v.rejection = violation
+	}
+}
+
+// takeRejection returns the violation for which the current request must be rejected, if any, and resets it. A
+// rejected STARTUP request is forgotten, so that the client can send another one.
+func (v *requestValidator) takeRejection() *ProtocolViolation {
+	rejection := v.rejection
+	if rejection != nil && v.startupRecorded {
+		v.startupVersion = 0
+	}
+	v.rejection = nil
+	v.startupRecorded = false
+	return rejection
+}
+
+func (v *requestValidator) reportMalformed(err error, header *frame.Header, conn *CqlServerConnection) {
+	v.report(&ProtocolViolation{Rule: ViolationRuleMalformed, Header: header, Message: err.Error()}, conn)
+}
+
+// validateHeader validates the header of an incoming request.
+func (v *requestValidator) validateHeader(header *frame.Header, conn *CqlServerConnection) {
+	report := func(rule ViolationRule, format string, args ...interface{}) {
+		v.report(&ProtocolViolation{Rule: rule, Header: header, Message: fmt.Sprintf(format, args...)}, conn)
+	}
+	version := header.Version
+	if unknown := header.Flags &^ primitive.KnownHeaderFlags; unknown != 0 {
+		report(ViolationRuleFlags, "unknown header flags: %08b", uint8(unknown))
+	}
+	if header.Flags.Contains(primitive.HeaderFlagWarning) {
+		report(ViolationRuleFlags, "WARNING flag set on a request")
+	}
+	if header.Flags.Contains(primitive.HeaderFlagCustomPayload) && version < primitive.ProtocolVersion4 {
+		report(ViolationRuleFlags, "CUSTOM_PAYLOAD flag set with %v", version)
+	}
+	if header.Flags.Contains(primitive.HeaderFlagUseBeta) && !version.IsBeta() {
+		report(ViolationRuleFlags, "USE_BETA flag set with non-beta %v", version)
+	}
+	if header.Flags.Contains(primitive.HeaderFlagCompressed) {
+		if conn.modernLayout {
+			report(ViolationRuleFlags, "COMPRESSED flag set on a frame in the modern framing layout")
+		} else if _, _, compression := conn.codecs(); compression == primitive.CompressionNone {
+			report(ViolationRuleFlags, "COMPRESSED flag set but no compression was negotiated")
+		}
+	}
+	if v.startupVersion != 0 && version != v.startupVersion {
+		report(ViolationRuleVersion, "expected %v as negotiated in STARTUP, got %v", v.startupVersion, version)
+	}
+	if !version.SupportsOpCode(header.OpCode) {
+		report(ViolationRuleVersion, "opcode %v not supported by %v", header.OpCode, version)
+	}
+	if header.StreamId < 0 {
+		report(ViolationRuleStreamId, "negative stream id %v", header.StreamId)
+	}
+	switch header.OpCode {
+	case primitive.OpCodeOptions:
+	case primitive.OpCodeStartup:
+		if v.startupVersion != 0 {
+			report(ViolationRuleSequence, "STARTUP already received")
+		} else {
+			v.startupVersion = version
+			v.startupRecorded = true
+		}
+	default:
+		if v.startupVersion == 0 {
+			report(ViolationRuleSequence, "%v received before STARTUP", header.OpCode)
+		}
+	}
+	if header.BodyLength < 0 || header.BodyLength > frame.DefaultMaxBodyLength {
+		report(ViolationRuleLength, "body length %v out of bounds", header.BodyLength)
+	}
+}
+
+// validateBodyLength validates that the entire body was consumed when decoding the message.
+func (v *requestValidator) validateBodyLength(header *frame.Header, remaining int, conn *CqlServerConnection) {
+	if remaining > 0 {
+		v.report(&ProtocolViolation{
+			Rule:    ViolationRuleLength,
+			Header:  header,
+			Message: fmt.Sprintf("%d trailing bytes after message (declared body length: %d)", remaining, header.BodyLength),
+		}, conn)
+	}
+}
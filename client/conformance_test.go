@@ -0,0 +1,179 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestClientConformanceChecker_ConformantClient(t *testing.T) {
+	checker := client.NewClientConformanceChecker()
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.ConformanceChecker = checker
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.RegisterHandler}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion4, primitive.ProtocolVersion5} {
+		clientConn, err := client.NewCqlClient("127.0.0.1:9043", nil).ConnectAndInit(ctx, version, client.ManagedStreamId)
+		require.NoError(t, err)
+		register := frame.NewFrame(version, client.ManagedStreamId, &message.Register{
+			EventTypes: []primitive.EventType{primitive.EventTypeSchemaChange},
+		})
+		_, err = clientConn.SendAndReceive(register)
+		require.NoError(t, err)
+	}
+	assert.Empty(t, checker.Violations())
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestClientConformanceChecker_Violations(t *testing.T) {
+	reported := make(chan *client.ProtocolViolation, 10)
+	checker := client.NewClientConformanceChecker()
+	checker.OnViolation = func(violation *client.ProtocolViolation, conn *client.CqlServerConnection) {
+		reported <- violation
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.ConformanceChecker = checker
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	codec := frame.NewClientCodec()
+	send := func(f *frame.Frame, alter func(encoded []byte) []byte) {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(f, encoded))
+		_, err := conn.Write(alter(encoded.Bytes()))
+		require.NoError(t, err)
+	}
+	noop := func(encoded []byte) []byte { return encoded }
+	query := &message.Query{Query: "SELECT * FROM system.local"}
+
+	send(frame.NewFrame(primitive.ProtocolVersion4, 1, query), noop)
+	send(frame.NewFrame(primitive.ProtocolVersion4, -1, &message.Options{}), noop)
+	send(frame.NewFrame(primitive.ProtocolVersion4, 2, message.NewStartup()), noop)
+	send(frame.NewFrame(primitive.ProtocolVersion3, 3, query), noop)
+	send(frame.NewFrame(primitive.ProtocolVersion4, 4, query), func(encoded []byte) []byte {
+		encoded[1] |= byte(primitive.HeaderFlagWarning)
+		return encoded
+	})
+	send(frame.NewFrame(primitive.ProtocolVersion4, 5, query), func(encoded []byte) []byte {
+		binary.BigEndian.PutUint32(encoded[5:], uint32(len(encoded)-9+2))
+		return append(encoded, 0xCA, 0xFE)
+	})
+
+	expected := []struct {
+		rule     client.ViolationRule
+		streamId int16
+	}{
+		{client.ViolationRuleSequence, 1},
+		{client.ViolationRuleStreamId, -1},
+		{client.ViolationRuleVersion, 3},
+		{client.ViolationRuleFlags, 4},
+		{client.ViolationRuleLength, 5},
+	}
+	assert.Eventually(t, func() bool { return len(checker.Violations()) == len(expected) }, time.Second*10, time.Millisecond*10)
+	violations := checker.Violations()
+	require.Len(t, violations, len(expected))
+	for i, violation := range violations {
+		assert.Equal(t, expected[i].rule, violation.Rule, violation.String())
+		assert.Equal(t, expected[i].streamId, violation.Header.StreamId, violation.String())
+		assert.Equal(t, violation, <-reported)
+	}
+
+	checker.Reset()
+	assert.Empty(t, checker.Violations())
+
+	_ = conn.Close()
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestClientConformanceChecker_Reject(t *testing.T) {
+	checker := client.NewClientConformanceChecker()
+	checker.Reject = map[client.ViolationRule]bool{
+		client.ViolationRuleMalformed: true,
+		client.ViolationRuleStreamId:  true,
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.ConformanceChecker = checker
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	conn, err := net.Dial("tcp", "127.0.0.1:9043")
+	require.NoError(t, err)
+	codec := frame.NewClientCodec()
+	send := func(f *frame.Frame, alter func(encoded []byte) []byte) *frame.Frame {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(f, encoded))
+		_, err := conn.Write(alter(encoded.Bytes()))
+		require.NoError(t, err)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		response, err := codec.DecodeFrame(conn)
+		require.NoError(t, err)
+		return response
+	}
+	noop := func(encoded []byte) []byte { return encoded }
+
+	response := send(frame.NewFrame(primitive.ProtocolVersion4, 1, message.NewStartup()), noop)
+	assert.IsType(t, &message.Ready{}, response.Body.Message)
+
+	// truncated query string: the body is entirely consumed, so the connection remains usable
+	response = send(frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Query{Query: "SELECT"}), func(encoded []byte) []byte {
+		binary.BigEndian.PutUint32(encoded[5:], 5)
+		return append(encoded[:9], 0, 0, 0, 6, 'S')
+	})
+	assert.Equal(t, int16(2), response.Header.StreamId)
+	assert.Equal(t, primitive.ProtocolVersion4, response.Header.Version)
+	assert.IsType(t, &message.ProtocolError{}, response.Body.Message)
+
+	response = send(frame.NewFrame(primitive.ProtocolVersion4, -1, &message.Options{}), noop)
+	assert.Equal(t, int16(-1), response.Header.StreamId)
+	assert.Equal(t, &message.ProtocolError{ErrorMessage: "negative stream id -1"}, response.Body.Message)
+
+	response = send(frame.NewFrame(primitive.ProtocolVersion4, 3, &message.Options{}), noop)
+	assert.Equal(t, int16(3), response.Header.StreamId)
+	assert.IsType(t, &message.Supported{}, response.Body.Message)
+
+	violations := checker.Violations()
+	require.Len(t, violations, 2)
+	assert.Equal(t, client.ViolationRuleMalformed, violations[0].Rule)
+	assert.Equal(t, client.ViolationRuleStreamId, violations[1].Rule)
+
+	_ = conn.Close()
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ContinuousPage is a page of results received through a ContinuousPagingSession.
+type ContinuousPage struct {
+	// Number is the page number, starting at 1.
+	Number int32
+	// Last is true if this is the last page of the session.
+	Last bool
+	// Result contains the page rows.
+	Result *message.RowsResult
+	// Frame is the frame that carried the page.
+	Frame *frame.Frame
+}
+
+// ContinuousPagingSession is a client-side continuous paging session (DataStax Enterprise only). A session can be
+// obtained with CqlClientConnection.StartContinuousPaging; pages are then retrieved one at a time with NextPage.
+//
+// If the request's ContinuousPagingOptions.NextPages is strictly positive and the protocol version is DSE v2 or
+// higher, the session applies backpressure: the server sends at most NextPages pages ahead of the consumer, and the
+// session requests more pages with a REVISE_REQUEST message every time half of these pages have been consumed. The
+// value of NextPages should not exceed the connection's maximum number of pending frames per in-flight request.
+//
+// A ContinuousPagingSession is not safe for concurrent use.
+type ContinuousPagingSession struct {
+	conn       *CqlClientConnection
+	request    *frame.Frame
+	inFlight   InFlightRequest
+	nextPages  int32
+	consumed   int32
+	pageNumber int32
+	done       bool
+}
+
+// StartContinuousPaging sends the given QUERY or EXECUTE request, which must have continuous paging options set, and
+// returns a ContinuousPagingSession to consume the resulting pages.
+func (c *CqlClientConnection) StartContinuousPaging(request *frame.Frame) (*ContinuousPagingSession, error) {
+	if request == nil {
+		return nil, fmt.Errorf("%v: frame cannot be nil", c)
+	} else if !request.Header.Version.IsDse() {
+		return nil, fmt.Errorf("%v: continuous paging requires a DSE protocol version, got: %v", c, request.Header.Version)
+	}
+	var options *message.QueryOptions
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		options = msg.Options
+	case *message.Execute:
+		options = msg.Options
+	default:
+		return nil, fmt.Errorf("%v: continuous paging requires a QUERY or EXECUTE request, got: %v", c, request.Header.OpCode)
+	}
+	if options == nil || options.ContinuousPagingOptions == nil {
+		return nil, fmt.Errorf("%v: continuous paging options not set", c)
+	}
+	inFlight, err := c.Send(request)
+	if err != nil {
+		return nil, err
+	}
+	session := &ContinuousPagingSession{
+		conn:     c,
+		request:  request,
+		inFlight: inFlight,
+	}
+	if request.Header.Version.SupportsDseRevisionType(primitive.DseRevisionTypeMoreContinuousPages) {
+		session.nextPages = options.ContinuousPagingOptions.NextPages
+	}
+	return session, nil
+}
+
+func (s *ContinuousPagingSession) String() string {
+	return fmt.Sprintf("%v [continuous paging stream id %d]", s.conn, s.inFlight.StreamId())
+}
+
+// StreamId returns the stream id of the continuous paging request.
+func (s *ContinuousPagingSession) StreamId() int16 {
+	return s.inFlight.StreamId()
+}
+
+// PageNumber returns the number of the last page returned by NextPage, or zero if no page was returned yet.
+func (s *ContinuousPagingSession) PageNumber() int32 {
+	return s.pageNumber
+}
+
+// IsDone returns true if the last page was returned, or if the session failed or was cancelled.
+func (s *ContinuousPagingSession) IsDone() bool {
+	return s.done
+}
+
+// NextPage waits until the next page is received and returns it. It returns a nil page and a nil error when the
+// session is done. Pages are checked to arrive in order; an ERROR response, an unexpected message or a page received
+// out of order terminates the session with an error.
+func (s *ContinuousPagingSession) NextPage() (*ContinuousPage, error) {
+	if s.done {
+		return nil, nil
+	}
+	incoming, err := s.conn.Receive(s.inFlight)
+	if err != nil {
+		s.done = true
+		return nil, err
+	} else if incoming == nil {
+		s.done = true
+		return nil, fmt.Errorf("%v: session ended before last page was received", s)
+	}
+	var result *message.RowsResult
+	switch msg := incoming.Body.Message.(type) {
+	case *message.RowsResult:
+		result = msg
+	case message.Error:
+		s.done = true
+		return nil, fmt.Errorf("%v: server replied with error: %v", s, msg)
+	default:
+		s.done = true
+		return nil, fmt.Errorf("%v: expected RESULT Rows, got: %v", s, incoming.Body.Message)
+	}
+	if result.Metadata == nil || result.Metadata.ContinuousPageNumber != s.pageNumber+1 {
+		s.done = true
+		var number int32
+		if result.Metadata != nil {
+			number = result.Metadata.ContinuousPageNumber
+		}
+		return nil, fmt.Errorf("%v: expected page %d, got: %d", s, s.pageNumber+1, number)
+	}
+	s.pageNumber++
+	page := &ContinuousPage{
+		Number: s.pageNumber,
+		Last:   result.Metadata.LastContinuousPage,
+		Result: result,
+		Frame:  incoming,
+	}
+	if page.Last {
+		s.done = true
+	} else if err := s.maybeRequestMorePages(); err != nil {
+		s.done = true
+		return nil, err
+	}
+	return page, nil
+}
+
+// Cancel cancels the session by sending a REVISE_REQUEST message to the server. It is a no-op if the session is done.
+// Pages that were already received are discarded.
+func (s *ContinuousPagingSession) Cancel() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	log.Debug().Msgf("%v: cancelling continuous paging", s)
+	return s.revise(&message.Revise{
+		RevisionType:   primitive.DseRevisionTypeCancelContinuousPaging,
+		TargetStreamId: int32(s.inFlight.StreamId()),
+	})
+}
+
+func (s *ContinuousPagingSession) maybeRequestMorePages() error {
+	if s.nextPages <= 0 {
+		return nil
+	}
+	s.consumed++
+	threshold := s.nextPages / 2
+	if threshold < 1 {
+		threshold = 1
+	}
+	if s.consumed < threshold {
+		return nil
+	}
+	log.Debug().Msgf("%v: requesting %d more pages", s, s.consumed)
+	err := s.revise(&message.Revise{
+		RevisionType:   primitive.DseRevisionTypeMoreContinuousPages,
+		TargetStreamId: int32(s.inFlight.StreamId()),
+		NextPages:      s.consumed,
+	})
+	s.consumed = 0
+	return err
+}
+
+func (s *ContinuousPagingSession) revise(revise *message.Revise) error {
+	request := frame.NewFrame(s.request.Header.Version, ManagedStreamId, revise)
+	request.SetCompress(s.request.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	if response, err := s.conn.SendAndReceive(request); err != nil {
+		return fmt.Errorf("%v: cannot send %v: %w", s, revise, err)
+	} else if response == nil {
+		return fmt.Errorf("%v: no response received for %v", s, revise)
+	} else if errMsg, ok := response.Body.Message.(message.Error); ok {
+		return fmt.Errorf("%v: server replied to %v with error: %v", s, revise, errMsg)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// newContinuousPagingHandler emulates a DSE server sending the given number of pages, never sending more pages than
+// the client asked for.
+func newContinuousPagingHandler(totalPages int32, revisions *[]int32) client.RequestHandler {
+	lock := &sync.Mutex{}
+	var streamId int16
+	var sent, allowed int32
+	sendPages := func(version primitive.ProtocolVersion, conn *client.CqlServerConnection) {
+		for ; sent < allowed && sent < totalPages; sent++ {
+			page := &message.RowsResult{
+				Metadata: &message.RowsMetadata{
+					ColumnCount:          1,
+					Columns:              []*message.ColumnMetadata{{Keyspace: "ks", Table: "t", Name: "v", Type: datatype.Int}},
+					ContinuousPageNumber: sent + 1,
+					LastContinuousPage:   sent+1 == totalPages,
+				},
+				Data: message.RowSet{{{0, 0, 0, byte(sent + 1)}}},
+			}
+			_ = conn.Send(frame.NewFrame(version, streamId, page))
+		}
+	}
+	return func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		lock.Lock()
+		defer lock.Unlock()
+		version := request.Header.Version
+		switch msg := request.Body.Message.(type) {
+		case *message.Query:
+			streamId = request.Header.StreamId
+			allowed = msg.Options.ContinuousPagingOptions.NextPages
+			sendPages(version, conn)
+		case *message.Revise:
+			if int16(msg.TargetStreamId) == streamId {
+				*revisions = append(*revisions, msg.NextPages)
+				allowed += msg.NextPages
+				sendPages(version, conn)
+			}
+			return frame.NewFrame(version, request.Header.StreamId, &message.RowsResult{
+				Metadata: &message.RowsMetadata{ColumnCount: 1},
+				Data:     message.RowSet{{{1}}},
+			})
+		}
+		return nil
+	}
+}
+
+func TestContinuousPagingSession(t *testing.T) {
+	var revisions []int32
+	handler := newContinuousPagingHandler(10, &revisions)
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+
+	query := frame.NewFrame(primitive.ProtocolVersionDse2, client.ManagedStreamId, &message.Query{
+		Query: "SELECT v FROM ks.t",
+		Options: &message.QueryOptions{
+			PageSize:                1,
+			ContinuousPagingOptions: &message.ContinuousPagingOptions{NextPages: 4},
+		},
+	})
+	session, err := clientConn.StartContinuousPaging(query)
+	require.NoError(t, err)
+	for i := int32(1); i <= 10; i++ {
+		page, err := session.NextPage()
+		require.NoError(t, err)
+		require.NotNil(t, page)
+		assert.Equal(t, i, page.Number)
+		assert.Equal(t, i == 10, page.Last)
+		assert.Equal(t, message.Column{0, 0, 0, byte(i)}, page.Result.Data[0][0])
+	}
+	assert.True(t, session.IsDone())
+	page, err := session.NextPage()
+	assert.NoError(t, err)
+	assert.Nil(t, page)
+	// 4 pages allowed initially, then 2 more pages requested every 2 pages consumed
+	assert.Equal(t, []int32{2, 2, 2, 2}, revisions)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestContinuousPagingSession_InvalidRequest(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, nil, nil)
+
+	_, err := clientConn.StartContinuousPaging(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{
+		Query:   "SELECT v FROM ks.t",
+		Options: &message.QueryOptions{ContinuousPagingOptions: &message.ContinuousPagingOptions{}},
+	}))
+	assert.Error(t, err)
+	_, err = clientConn.StartContinuousPaging(frame.NewFrame(primitive.ProtocolVersionDse2, client.ManagedStreamId, &message.Query{
+		Query: "SELECT v FROM ks.t",
+	}))
+	assert.Error(t, err)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
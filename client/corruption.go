@@ -0,0 +1,185 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+	"github.com/datastax/go-cassandra-native-protocol/segment"
+)
+
+// corruptionHeaderDecoder decodes the headers of the frames to corrupt.
+var corruptionHeaderDecoder = frame.NewRawCodec()
+
+// Corruption is a kind of corruption applied by a CorruptionPolicy.
+type Corruption string
+
+const (
+	// CorruptionBitFlip is reported when random bits of a frame body are flipped.
+	CorruptionBitFlip = Corruption("bit-flip")
+	// CorruptionTruncation is reported when a frame body is truncated; the body length in the frame header is
+	// adjusted accordingly, so that the stream of frames remains in sync.
+	CorruptionTruncation = Corruption("truncation")
+	// CorruptionChecksum is reported when a random bit of a segment header CRC-24 or of a segment payload CRC-32 is
+	// flipped. Segments only exist in the modern framing layout introduced in protocol v5.
+	CorruptionChecksum = Corruption("checksum")
+)
+
+// CorruptionPolicy describes how outgoing frames are corrupted, to let consumers of this library verify how their
+// implementations handle corrupt data; set it on CqlServer.CorruptionPolicy to corrupt the responses sent by a server
+// stub. This is meant for testing only. For each frame subject to corruption, at most one of CorruptionBitFlip and
+// CorruptionTruncation is drawn, according to the configured probabilities, whose sum should not exceed 1; in the
+// modern framing layout, each segment is then subject to CorruptionChecksum. Draws are deterministic for a given seed
+// and sequence of frames. It is preferable to create CorruptionPolicy instances using the constructor function
+// NewCorruptionPolicy. It is safe for concurrent use.
+type CorruptionPolicy struct {
+	// Filter optionally selects the frames subject to corruption, based on their header. If nil, DefaultPolicyFilter
+	// is used, so that connections can still be established.
+	Filter func(header *frame.Header) bool
+	// BitFlipProbability is the probability, between 0 and 1, that bits of a frame body are flipped.
+	BitFlipProbability float64
+	// MaxBitFlips is the maximum number of bits to flip in a frame body; the actual number is random. Defaults to 1.
+	MaxBitFlips int
+	// TruncationProbability is the probability, between 0 and 1, that a frame body is truncated.
+	TruncationProbability float64
+	// ChecksumProbability is the probability, between 0 and 1, that a checksum of a segment is mangled.
+	ChecksumProbability float64
+	// OnCorruption is an optional callback invoked for each applied corruption. The header is that of the corrupt
+	// frame, before corruption; it is nil for CorruptionChecksum.
+	OnCorruption func(corruption Corruption, header *frame.Header)
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// NewCorruptionPolicy creates a new CorruptionPolicy with the given seed, and no corruption configured.
+func NewCorruptionPolicy(seed int64) *CorruptionPolicy {
+	return &CorruptionPolicy{MaxBitFlips: 1, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (p *CorruptionPolicy) isSubject(header *frame.Header) bool {
+	if p.Filter != nil {
+		return p.Filter(header)
+	}
+	return DefaultPolicyFilter(header)
+}
+
+// random returns the source of randomness of this policy; the lock must be held.
+func (p *CorruptionPolicy) random() *rand.Rand {
+	if p.rand == nil {
+		p.rand = rand.New(rand.NewSource(0))
+	}
+	return p.rand
+}
+
+func (p *CorruptionPolicy) report(corruption Corruption, header *frame.Header) {
+	log.Debug().Msgf("[corruption policy]: applying corruption %v", corruption)
+	if p.OnCorruption != nil {
+		p.OnCorruption(corruption, header)
+	}
+}
+
+// CorruptFrame possibly corrupts the given encoded frame, according to this policy. It returns the corrupt frame, or
+// the given frame unchanged if no corruption was drawn, if the frame is not subject to corruption, or if its header
+// cannot be decoded. The given slice may be modified in place.
+func (p *CorruptionPolicy) CorruptFrame(encoded []byte) []byte {
+	if p == nil || len(encoded) == 0 {
+		return encoded
+	}
+	headerLength := primitive.FrameHeaderLengthV3AndHigher
+	if primitive.ProtocolVersion(encoded[0]&0b0111_1111) < primitive.ProtocolVersion3 {
+		headerLength = primitive.FrameHeaderLengthV2AndLower
+	}
+	bodyLength := len(encoded) - headerLength
+	if bodyLength <= 0 {
+		return encoded
+	}
+	header, err := corruptionHeaderDecoder.DecodeHeader(bytes.NewReader(encoded[:headerLength]))
+	if err != nil || !p.isSubject(header) {
+		return encoded
+	}
+	p.lock.Lock()
+	r := p.random()
+	draw := r.Float64()
+	var corruption Corruption
+	if draw < p.BitFlipProbability {
+		corruption = CorruptionBitFlip
+		flips := 1
+		if p.MaxBitFlips > 1 {
+			flips += r.Intn(p.MaxBitFlips)
+		}
+		for i := 0; i < flips; i++ {
+			encoded[headerLength+r.Intn(bodyLength)] ^= 1 << r.Intn(8)
+		}
+	} else if draw < p.BitFlipProbability+p.TruncationProbability {
+		corruption = CorruptionTruncation
+		bodyLength = r.Intn(bodyLength)
+		encoded = encoded[:headerLength+bodyLength]
+		binary.BigEndian.PutUint32(encoded[headerLength-4:], uint32(bodyLength))
+	}
+	p.lock.Unlock()
+	if corruption != "" {
+		p.report(corruption, header)
+	}
+	return encoded
+}
+
+// CorruptSegments possibly mangles the checksums of the given encoded segments, according to this policy; the given
+// slice may contain several consecutive segments, and is modified in place. Set compressed to true if the segments
+// were encoded with compression, since their header layout differs.
+func (p *CorruptionPolicy) CorruptSegments(encoded []byte, compressed bool) {
+	if p == nil {
+		return
+	}
+	headerLength := segment.UncompressedHeaderLength
+	if compressed {
+		headerLength = segment.CompressedHeaderLength
+	}
+	for offset := 0; offset+headerLength+segment.Crc24Length <= len(encoded); {
+		var headerData uint64
+		for i := headerLength - 1; i >= 0; i-- {
+			headerData = headerData<<8 | uint64(encoded[offset+i])
+		}
+		payloadLength := int(headerData & segment.MaxPayloadLength)
+		headerCrcOffset := offset + headerLength
+		payloadCrcOffset := headerCrcOffset + segment.Crc24Length + payloadLength
+		next := payloadCrcOffset + segment.Crc32Length
+		if next > len(encoded) {
+			return
+		}
+		p.lock.Lock()
+		r := p.random()
+		corrupt := r.Float64() < p.ChecksumProbability
+		if corrupt {
+			if r.Intn(2) == 0 {
+				encoded[headerCrcOffset+r.Intn(segment.Crc24Length)] ^= 1 << r.Intn(8)
+			} else {
+				encoded[payloadCrcOffset+r.Intn(segment.Crc32Length)] ^= 1 << r.Intn(8)
+			}
+		}
+		p.lock.Unlock()
+		if corrupt {
+			p.report(CorruptionChecksum, nil)
+		}
+		offset = next
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+	"github.com/datastax/go-cassandra-native-protocol/segment"
+)
+
+func encodeResponse(t *testing.T, msg message.Message) []byte {
+	encoded := &bytes.Buffer{}
+	require.NoError(t, frame.NewServerCodec().EncodeFrame(frame.NewFrame(primitive.ProtocolVersion4, 1, msg), encoded))
+	return encoded.Bytes()
+}
+
+func TestCorruptionPolicy_CorruptFrame(t *testing.T) {
+	rows := &message.RowsResult{Metadata: &message.RowsMetadata{ColumnCount: 1}, Data: message.RowSet{{[]byte("hello world")}}}
+	original := encodeResponse(t, rows)
+	headerLength := primitive.FrameHeaderLengthV3AndHigher
+
+	t.Run("nil policy", func(t *testing.T) {
+		var policy *client.CorruptionPolicy
+		assert.Equal(t, original, policy.CorruptFrame(append([]byte(nil), original...)))
+	})
+
+	t.Run("no corruption", func(t *testing.T) {
+		policy := client.NewCorruptionPolicy(1)
+		assert.Equal(t, original, policy.CorruptFrame(append([]byte(nil), original...)))
+	})
+
+	t.Run("bit flip", func(t *testing.T) {
+		policy := client.NewCorruptionPolicy(1)
+		policy.BitFlipProbability = 1
+		var reported []client.Corruption
+		policy.OnCorruption = func(corruption client.Corruption, header *frame.Header) {
+			assert.Equal(t, primitive.OpCodeResult, header.OpCode)
+			reported = append(reported, corruption)
+		}
+		corrupt := policy.CorruptFrame(append([]byte(nil), original...))
+		require.Len(t, corrupt, len(original))
+		assert.Equal(t, original[:headerLength], corrupt[:headerLength])
+		flipped := 0
+		for i := range original {
+			for diff := original[i] ^ corrupt[i]; diff != 0; diff &= diff - 1 {
+				flipped++
+			}
+		}
+		assert.Equal(t, 1, flipped)
+		assert.Equal(t, []client.Corruption{client.CorruptionBitFlip}, reported)
+	})
+
+	t.Run("max bit flips", func(t *testing.T) {
+		policy := client.NewCorruptionPolicy(1)
+		policy.BitFlipProbability = 1
+		policy.MaxBitFlips = 5
+		for i := 0; i < 20; i++ {
+			corrupt := policy.CorruptFrame(append([]byte(nil), original...))
+			assert.Equal(t, original[:headerLength], corrupt[:headerLength])
+			assert.NotEqual(t, original, corrupt)
+		}
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		policy := client.NewCorruptionPolicy(1)
+		policy.TruncationProbability = 1
+		corrupt := policy.CorruptFrame(append([]byte(nil), original...))
+		require.Less(t, len(corrupt), len(original))
+		assert.Equal(t, original[:headerLength-4], corrupt[:headerLength-4])
+		assert.Equal(t, uint32(len(corrupt)-headerLength), binary.BigEndian.Uint32(corrupt[headerLength-4:]))
+		_, err := frame.NewClientCodec().DecodeFrame(bytes.NewReader(corrupt))
+		assert.Error(t, err)
+	})
+
+	t.Run("handshake frames not subject by default", func(t *testing.T) {
+		policy := client.NewCorruptionPolicy(1)
+		policy.TruncationProbability = 1
+		authenticate := encodeResponse(t, &message.Authenticate{Authenticator: "org.apache.cassandra.auth.PasswordAuthenticator"})
+		assert.Equal(t, authenticate, policy.CorruptFrame(append([]byte(nil), authenticate...)))
+		policy.Filter = func(header *frame.Header) bool { return true }
+		assert.NotEqual(t, authenticate, policy.CorruptFrame(append([]byte(nil), authenticate...)))
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		corrupt := func(seed int64) [][]byte {
+			policy := client.NewCorruptionPolicy(seed)
+			policy.BitFlipProbability = 0.5
+			policy.TruncationProbability = 0.5
+			var frames [][]byte
+			for i := 0; i < 10; i++ {
+				frames = append(frames, policy.CorruptFrame(append([]byte(nil), original...)))
+			}
+			return frames
+		}
+		assert.Equal(t, corrupt(42), corrupt(42))
+		assert.NotEqual(t, corrupt(42), corrupt(43))
+	})
+}
+
+func TestCorruptionPolicy_CorruptSegments(t *testing.T) {
+	for _, compression := range []primitive.Compression{primitive.CompressionNone, primitive.CompressionLz4} {
+		t.Run(string(compression), func(t *testing.T) {
+			codec := segment.NewCodecWithCompression(client.NewPayloadCompressor(compression))
+			encoded := &bytes.Buffer{}
+			for i := 0; i < 3; i++ {
+				payload := bytes.Repeat([]byte{byte(i)}, 100)
+				require.NoError(t, codec.EncodeSegment(&segment.Segment{
+					Header:  &segment.Header{IsSelfContained: true},
+					Payload: &segment.Payload{UncompressedData: payload},
+				}, encoded))
+			}
+			original := append([]byte(nil), encoded.Bytes()...)
+			compressed := compression != primitive.CompressionNone
+
+			policy := client.NewCorruptionPolicy(1)
+			policy.CorruptSegments(encoded.Bytes(), compressed)
+			assert.Equal(t, original, encoded.Bytes())
+
+			policy.ChecksumProbability = 1
+			reported := 0
+			policy.OnCorruption = func(corruption client.Corruption, header *frame.Header) {
+				assert.Equal(t, client.CorruptionChecksum, corruption)
+				assert.Nil(t, header)
+				reported++
+			}
+			policy.CorruptSegments(encoded.Bytes(), compressed)
+			assert.Equal(t, 3, reported)
+			_, err := codec.DecodeSegment(bytes.NewReader(encoded.Bytes()))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "crc mismatch")
+		})
+	}
+}
+
+func TestCqlServer_CorruptionPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   primitive.ProtocolVersion
+		configure func(policy *client.CorruptionPolicy)
+		expected  client.Corruption
+	}{
+		{"truncation", primitive.ProtocolVersion4, func(policy *client.CorruptionPolicy) { policy.TruncationProbability = 1 }, client.CorruptionTruncation},
+		{"checksum", primitive.ProtocolVersion5, func(policy *client.CorruptionPolicy) { policy.ChecksumProbability = 1 }, client.CorruptionChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := client.NewCorruptionPolicy(1)
+			reported := make(chan client.Corruption, 10)
+			policy.OnCorruption = func(corruption client.Corruption, _ *frame.Header) { reported <- corruption }
+			tt.configure(policy)
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, voidResultHandler}
+			server.CorruptionPolicy = policy
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			require.NoError(t, server.Start(ctx))
+
+			clientConn, err := client.NewCqlClient("127.0.0.1:9043", nil).ConnectAndInit(ctx, tt.version, client.ManagedStreamId)
+			require.NoError(t, err)
+			query := frame.NewFrame(tt.version, client.ManagedStreamId, &message.Query{Query: "SELECT * FROM ks.t"})
+			inFlight, err := clientConn.SendWithTimeout(query, time.Second)
+			require.NoError(t, err)
+			_, err = clientConn.Receive(inFlight)
+			assert.Error(t, err)
+			assert.Equal(t, tt.expected, <-reported)
+
+			cancelFn()
+			checkClosed(t, clientConn, server)
+		})
+	}
+}
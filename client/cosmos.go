@@ -0,0 +1,64 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CosmosMaxProtocolVersion is the highest protocol version supported by the Cassandra API of Azure Cosmos DB.
+const CosmosMaxProtocolVersion = primitive.ProtocolVersion4
+
+// CosmosRequestChargeKey is the key of the custom payload entry containing the request charge of a response, in
+// request units, as sent by the Cassandra API of Azure Cosmos DB.
+const CosmosRequestChargeKey = "RequestCharge"
+
+// CosmosRequestCharge returns the request charge of the given response, in request units, as sent by the Cassandra API
+// of Azure Cosmos DB in the response custom payload; ok is false if the response has no request charge. The charge is
+// encoded as a big-endian 64-bit floating point number.
+func CosmosRequestCharge(response *frame.Frame) (charge float64, ok bool, err error) {
+	value, ok := response.Body.CustomPayload[CosmosRequestChargeKey]
+	if !ok {
+		return 0, false, nil
+	} else if len(value) != 8 {
+		return 0, true, fmt.Errorf("cannot read %v: expected 8 bytes, got %d", CosmosRequestChargeKey, len(value))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(value)), true, nil
+}
+
+// messageCodecs returns the message codecs to register in the frame codec of new connections, in addition to the
+// default ones.
+func (client *CqlClient) messageCodecs() []message.Codec {
+	if client.CosmosCompatibility {
+		return []message.Codec{message.NewLenientErrorCodec()}
+	}
+	return nil
+}
+
+// checkProtocolVersion returns an error if the given protocol version is not supported by the backend, as far as the
+// client knows.
+func (client *CqlClient) checkProtocolVersion(version primitive.ProtocolVersion) error {
+	if client.CosmosCompatibility && version > CosmosMaxProtocolVersion {
+		return fmt.Errorf("%v is not supported by Azure Cosmos DB, the highest supported version is %v",
+			version, CosmosMaxProtocolVersion)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCosmosRequestCharge(t *testing.T) {
+	response := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.VoidResult{})
+	_, ok, err := client.CosmosRequestCharge(response)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, math.Float64bits(2.38))
+	response.SetCustomPayload(map[string][]byte{client.CosmosRequestChargeKey: encoded})
+	charge, ok, err := client.CosmosRequestCharge(response)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.38, charge)
+
+	response.SetCustomPayload(map[string][]byte{client.CosmosRequestChargeKey: encoded[:4]})
+	_, ok, err = client.CosmosRequestCharge(response)
+	assert.True(t, ok)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 8 bytes, got 4")
+}
+
+func TestCqlClient_CosmosCompatibility(t *testing.T) {
+	truncatedError := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) []byte {
+		if _, ok := request.Body.Message.(*message.Query); !ok {
+			return nil
+		}
+		readTimeout := &message.ReadTimeout{ErrorMessage: "BOOM", Consistency: primitive.ConsistencyLevelOne}
+		encoded := &bytes.Buffer{}
+		err := frame.NewCodec().EncodeFrame(frame.NewFrame(request.Header.Version, request.Header.StreamId, readTimeout), encoded)
+		if err != nil {
+			return nil
+		}
+		// keep only the error code and message, drop the fields specific to read timeouts
+		bodyLength := primitive.LengthOfInt + primitive.LengthOfString(readTimeout.ErrorMessage)
+		truncated := encoded.Bytes()[:primitive.FrameHeaderLengthV3AndHigher+bodyLength]
+		binary.BigEndian.PutUint32(truncated[5:], uint32(bodyLength))
+		return truncated
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler}
+	server.RequestRawHandlers = []client.RawRequestHandler{truncatedError}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.CosmosCompatibility = true
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	_, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion5, client.ManagedStreamId)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported by Azure Cosmos DB")
+
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	query := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT * FROM ks.t"})
+	response, err := clientConn.SendAndReceive(query)
+	require.NoError(t, err)
+	assert.Equal(t, &message.ReadTimeout{ErrorMessage: "BOOM"}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CustomPayloadPolicy describes how the custom payload of a frame is altered as the frame passes through a proxy or a
+// chain of request handlers, e.g. to inject a tenant identifier in all requests, or to strip internal entries from all
+// responses. The policy is applied in the following order: entries are stripped, then injected, then Augment is
+// invoked.
+type CustomPayloadPolicy struct {
+	// StripAll, if true, removes all the existing custom payload entries.
+	StripAll bool
+	// Strip contains the keys of the custom payload entries to remove.
+	Strip []string
+	// Inject contains the entries to add to the custom payload, replacing existing entries with the same keys.
+	Inject map[string][]byte
+	// Augment is an optional function to read and modify the custom payload in place; the payload passed to the
+	// function is never nil. Removing all the entries removes the custom payload from the frame.
+	Augment func(f *frame.Frame, payload map[string][]byte)
+}
+
+// Apply applies this policy to the given frame, adjusting its header flags accordingly. The frame's custom payload map
+// is never modified in place: a new map is created if the payload needs to be modified. Custom payloads are not
+// supported in protocol versions lesser than 4: for such frames, this method is a no-op.
+func (p *CustomPayloadPolicy) Apply(f *frame.Frame) {
+	if p == nil || f.Header.Version < primitive.ProtocolVersion4 {
+		return
+	}
+	payload := make(map[string][]byte, len(f.Body.CustomPayload)+len(p.Inject))
+	if !p.StripAll {
+		for key, value := range f.Body.CustomPayload {
+			payload[key] = value
+		}
+		for _, key := range p.Strip {
+			delete(payload, key)
+		}
+	}
+	for key, value := range p.Inject {
+		payload[key] = value
+	}
+	if p.Augment != nil {
+		p.Augment(f, payload)
+	}
+	f.SetCustomPayload(payload)
+}
+
+// NewCustomPayloadHandler creates a new RequestHandler that applies the given request policy to all incoming requests,
+// then delegates to the given handler, and finally applies the given response policy to the response produced by that
+// handler, if any. Either policy can be nil, in which case the corresponding frames are left unchanged. This is
+// typically used in proxies, to propagate and alter custom payloads in each direction.
+func NewCustomPayloadHandler(
+	requestPolicy *CustomPayloadPolicy,
+	responsePolicy *CustomPayloadPolicy,
+	handler RequestHandler,
+) RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+		requestPolicy.Apply(request)
+		if response = handler(request, conn, ctx); response != nil {
+			responsePolicy.Apply(response)
+			// only keys are logged: custom payload values often carry credentials or tokens
+			log.Debug().Msgf("%v: [custom payload handler]: response custom payload keys: %v",
+				conn, customPayloadKeys(response.Body.CustomPayload))
+		}
+		return
+	}
+}
+
+// customPayloadKeys returns the sorted keys of the given custom payload.
+func customPayloadKeys(payload map[string][]byte) []string {
+	keys := make([]string, 0, len(payload))
+	for key := range payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCustomPayloadPolicy_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  primitive.ProtocolVersion
+		payload  map[string][]byte
+		policy   *client.CustomPayloadPolicy
+		expected map[string][]byte
+	}{
+		{
+			"nil policy",
+			primitive.ProtocolVersion4,
+			map[string][]byte{"a": {1}},
+			nil,
+			map[string][]byte{"a": {1}},
+		},
+		{
+			"strip",
+			primitive.ProtocolVersion4,
+			map[string][]byte{"a": {1}, "b": {2}},
+			&client.CustomPayloadPolicy{Strip: []string{"a"}},
+			map[string][]byte{"b": {2}},
+		},
+		{
+			"strip all and inject",
+			primitive.ProtocolVersion4,
+			map[string][]byte{"a": {1}, "b": {2}},
+			&client.CustomPayloadPolicy{StripAll: true, Inject: map[string][]byte{"tenant": []byte("t1")}},
+			map[string][]byte{"tenant": []byte("t1")},
+		},
+		{
+			"inject replaces existing",
+			primitive.ProtocolVersion5,
+			map[string][]byte{"tenant": []byte("t0")},
+			&client.CustomPayloadPolicy{Inject: map[string][]byte{"tenant": []byte("t1")}},
+			map[string][]byte{"tenant": []byte("t1")},
+		},
+		{
+			"augment",
+			primitive.ProtocolVersion4,
+			map[string][]byte{"a": {1}},
+			&client.CustomPayloadPolicy{Augment: func(f *frame.Frame, payload map[string][]byte) {
+				payload["opcode"] = []byte{byte(f.Header.OpCode)}
+			}},
+			map[string][]byte{"a": {1}, "opcode": {byte(primitive.OpCodeQuery)}},
+		},
+		{
+			"strip everything",
+			primitive.ProtocolVersion4,
+			map[string][]byte{"a": {1}},
+			&client.CustomPayloadPolicy{StripAll: true},
+			map[string][]byte{},
+		},
+		{
+			"unsupported version",
+			primitive.ProtocolVersion3,
+			nil,
+			&client.CustomPayloadPolicy{Inject: map[string][]byte{"tenant": []byte("t1")}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := frame.NewFrame(tt.version, 1, &message.Query{Query: "SELECT"})
+			f.SetCustomPayload(tt.payload)
+			original := f.Body.CustomPayload
+			tt.policy.Apply(f)
+			assert.Equal(t, tt.expected, f.Body.CustomPayload)
+			assert.Equal(t, len(tt.expected) > 0, f.Header.Flags.Contains(primitive.HeaderFlagCustomPayload))
+			assert.Equal(t, tt.payload, original)
+		})
+	}
+}
+
+func TestNewCustomPayloadHandler(t *testing.T) {
+	var received map[string][]byte
+	handler := client.NewCustomPayloadHandler(
+		&client.CustomPayloadPolicy{Inject: map[string][]byte{"tenant": []byte("t1")}},
+		&client.CustomPayloadPolicy{Strip: []string{"internal"}},
+		func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+			received = request.Body.CustomPayload
+			response := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+			response.SetCustomPayload(map[string][]byte{"internal": {1}, "public": {2}})
+			return response
+		},
+	)
+
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT"})
+	request.SetCustomPayload(map[string][]byte{"client": {3}})
+	response, err := clientConn.SendAndReceive(request)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"client": {3}, "tenant": []byte("t1")}, received)
+	assert.Equal(t, map[string][]byte{"public": {2}}, response.Body.CustomPayload)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestNewCustomPayloadHandler_LogsKeysOnly(t *testing.T) {
+	logs := &bytes.Buffer{}
+	defer func(logger zerolog.Logger, level zerolog.Level) {
+		log.Logger = logger
+		zerolog.SetGlobalLevel(level)
+	}(log.Logger, zerolog.GlobalLevel())
+	log.Logger = zerolog.New(logs)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+	handler := client.NewCustomPayloadHandler(nil, nil,
+		func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+			response := frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+			response.SetCustomPayload(map[string][]byte{"token": []byte("s3cr3t"), "tenant": []byte("t1")})
+			return response
+		},
+	)
+	handler(frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"}), nil, nil)
+	assert.Contains(t, logs.String(), "response custom payload keys: [tenant token]")
+	assert.NotContains(t, logs.String(), "s3cr3t")
+	assert.NotContains(t, logs.String(), fmt.Sprint([]byte("s3cr3t")))
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// NewStatusChangeDebouncer returns an EventDebouncer that coalesces rapid UP/DOWN flaps for the same node address.
+// STATUS_CHANGE events are held back until no other STATUS_CHANGE event has been received for the same address during
+// the given window; only the last event received for that address is then forwarded to the given handlers. If the
+// last event carries the same status as the one previously forwarded for that address, and that status was forwarded
+// less than one window before the first held-back event was received, it is discarded altogether. All other events
+// are forwarded immediately.
+func NewStatusChangeDebouncer(window time.Duration, handlers ...EventHandler) *EventDebouncer {
+	d := newEventDebouncer(window, handlers)
+	d.key = func(event *frame.Frame) (string, bool) {
+		if statusChange, ok := event.Body.Message.(*message.StatusChangeEvent); ok && statusChange.Address != nil {
+			return statusChange.Address.String(), true
+		}
+		return "", false
+	}
+	forwarded := make(map[string]*forwardedStatus)
+	forwardedLock := &sync.Mutex{}
+	d.filter = func(key string, event *frame.Frame, since time.Time) bool {
+		changeType := event.Body.Message.(*message.StatusChangeEvent).ChangeType
+		forwardedLock.Lock()
+		defer forwardedLock.Unlock()
+		// statuses forwarded more than one window before the current burst started are forgotten
+		for address, previous := range forwarded {
+			if previous.at.Before(since.Add(-window)) {
+				delete(forwarded, address)
+			}
+		}
+		if previous, found := forwarded[key]; found && previous.changeType == changeType {
+			log.Debug().Msgf("[status change debouncer]: discarding redundant %v event for %v", changeType, key)
+			return false
+		}
+		forwarded[key] = &forwardedStatus{changeType: changeType, at: time.Now()}
+		return true
+	}
+	return d
+}
+
+type forwardedStatus struct {
+	changeType primitive.StatusChangeType
+	at         time.Time
+}
+
+// NewSchemaChangeCoalescer returns an EventDebouncer that batches bursts of SCHEMA_CHANGE events, such as the ones
+// produced during schema migrations. SCHEMA_CHANGE events are grouped by affected schema object (its target, keyspace,
+// object name and, for functions and aggregates, argument types) and held back until no other SCHEMA_CHANGE event has
+// been received for the same object during the given quiet period; a single consolidated notification is then
+// forwarded to the given handlers. The change types of the coalesced events are merged: an UPDATED change never
+// overrides a CREATED or DROPPED one, e.g. CREATED followed by UPDATED is notified as CREATED; otherwise, the last
+// change wins, e.g. CREATED followed by DROPPED is notified as DROPPED. All other events are forwarded immediately.
+func NewSchemaChangeCoalescer(quietPeriod time.Duration, handlers ...EventHandler) *EventDebouncer {
+	d := newEventDebouncer(quietPeriod, handlers)
+	d.key = func(event *frame.Frame) (string, bool) {
+		if schemaChange, ok := event.Body.Message.(*message.SchemaChangeEvent); ok {
+			return schemaChangeKey(schemaChange), true
+		}
+		return "", false
+	}
+	d.merge = mergeSchemaChanges
+	return d
+}
+
+// schemaChangeKey identifies the schema object affected by the given event, e.g. "TABLE ks.t" or
+// "FUNCTION ks.f(int,text)": tables and types sharing the same name, and function or aggregate overloads, are distinct
+// objects.
+func schemaChangeKey(event *message.SchemaChangeEvent) string {
+	key := string(event.Target) + " " + event.Keyspace
+	if event.Object != "" {
+		key += "." + event.Object
+	}
+	switch event.Target {
+	case primitive.SchemaChangeTargetFunction, primitive.SchemaChangeTargetAggregate:
+		key += "(" + strings.Join(event.Arguments, ",") + ")"
+	}
+	return key
+}
+
+// mergeSchemaChanges merges two successive SCHEMA_CHANGE events affecting the same schema object, see
+// NewSchemaChangeCoalescer. The returned event is the next event, or a copy of it with the change type of the previous
+// event.
+func mergeSchemaChanges(previous, next *frame.Frame) *frame.Frame {
+	previousChange := previous.Body.Message.(*message.SchemaChangeEvent)
+	nextChange := next.Body.Message.(*message.SchemaChangeEvent)
+	if nextChange.ChangeType != primitive.SchemaChangeTypeUpdated ||
+		previousChange.ChangeType == primitive.SchemaChangeTypeUpdated {
+		return next
+	}
+	merged := *nextChange
+	merged.ChangeType = previousChange.ChangeType
+	body := *next.Body
+	body.Message = &merged
+	return &frame.Frame{Header: next.Header, Body: &body}
+}
+
+// EventDebouncer holds back events sharing the same key until no other event with that key has been received during
+// the configured window, then forwards a single event to its handlers. EventDebouncer instances should be created with
+// NewStatusChangeDebouncer or NewSchemaChangeCoalescer; their HandleEvent method is meant to be registered as an
+// EventHandler, e.g. in CqlClient.EventHandlers. They are safe for concurrent use.
+type EventDebouncer struct {
+	window   time.Duration
+	handlers []EventHandler
+	// key returns the key of the given event, or false if the event must be forwarded immediately.
+	key func(event *frame.Frame) (string, bool)
+	// filter, if set, is invoked right before forwarding a held-back event, with the time the first event of the burst
+	// was received; returning false discards it.
+	filter func(key string, event *frame.Frame, since time.Time) bool
+	// merge, if set, is invoked when an event is received while another one with the same key is held back, and
+	// returns the event to hold back instead; by default, the last event received is held back.
+	merge   func(previous, next *frame.Frame) *frame.Frame
+	pending map[string]*pendingEvent
+	closed  bool
+	lock    *sync.Mutex
+}
+
+type pendingEvent struct {
+	event *frame.Frame
+	conn  *CqlClientConnection
+	since time.Time
+	timer *time.Timer
+}
+
+func newEventDebouncer(window time.Duration, handlers []EventHandler) *EventDebouncer {
+	return &EventDebouncer{
+		window:   window,
+		handlers: handlers,
+		pending:  make(map[string]*pendingEvent),
+		lock:     &sync.Mutex{},
+	}
+}
+
+// HandleEvent is an EventHandler that holds back the given event, or forwards it immediately to the handlers of this
+// debouncer if the event is not subject to debouncing, or if this debouncer was closed.
+func (d *EventDebouncer) HandleEvent(event *frame.Frame, conn *CqlClientConnection) {
+	if key, ok := d.key(event); !ok || !d.onEvent(key, event, conn) {
+		d.forward(event, conn)
+	}
+}
+
+// Close stops all the pending timers; the events held back are discarded, and events received afterwards are
+// forwarded immediately.
+func (d *EventDebouncer) Close() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.closed = true
+	for key, p := range d.pending {
+		p.timer.Stop()
+		delete(d.pending, key)
+	}
+}
+
+// onEvent holds back the given event, and returns false if this debouncer was closed.
+func (d *EventDebouncer) onEvent(key string, event *frame.Frame, conn *CqlClientConnection) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return false
+	} else if p, found := d.pending[key]; found {
+		if d.merge != nil {
+			event = d.merge(p.event, event)
+		}
+		p.event = event
+		p.conn = conn
+		p.timer.Reset(d.window)
+	} else {
+		p = &pendingEvent{event: event, conn: conn, since: time.Now()}
+		p.timer = time.AfterFunc(d.window, func() { d.onWindowElapsed(key, p) })
+		d.pending[key] = p
+	}
+	return true
+}
+
+func (d *EventDebouncer) onWindowElapsed(key string, p *pendingEvent) {
+	d.lock.Lock()
+	if d.pending[key] != p {
+		d.lock.Unlock()
+		return
+	}
+	delete(d.pending, key)
+	event, conn := p.event, p.conn
+	d.lock.Unlock()
+	if d.filter == nil || d.filter(key, event, p.since) {
+		d.forward(event, conn)
+	}
+}
+
+func (d *EventDebouncer) forward(event *frame.Frame, conn *CqlClientConnection) {
+	for _, handler := range d.handlers {
+		handler(event, conn)
+	}
+}
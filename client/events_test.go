@@ -0,0 +1,260 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+type eventRecorder struct {
+	events []*frame.Frame
+	lock   sync.Mutex
+}
+
+func (r *eventRecorder) handler(event *frame.Frame, _ *client.CqlClientConnection) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) recorded() []*frame.Frame {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*frame.Frame(nil), r.events...)
+}
+
+func statusChangeEvent(changeType primitive.StatusChangeType, ip string) *frame.Frame {
+	return frame.NewFrame(primitive.ProtocolVersion4, -1, &message.StatusChangeEvent{
+		ChangeType: changeType,
+		Address:    &primitive.Inet{Addr: net.ParseIP(ip), Port: 9042},
+	})
+}
+
+func TestNewStatusChangeDebouncer(t *testing.T) {
+	recorder := &eventRecorder{}
+	debouncer := client.NewStatusChangeDebouncer(50*time.Millisecond, recorder.handler)
+	defer debouncer.Close()
+
+	// node 1 flaps and ends up DOWN; node 2 flaps and ends up UP
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.2"), nil)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeUp, "127.0.0.1"), nil)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeUp, "127.0.0.2"), nil)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+
+	// non-status events are forwarded immediately
+	topologyChange := frame.NewFrame(primitive.ProtocolVersion4, -1, &message.TopologyChangeEvent{
+		ChangeType: primitive.TopologyChangeTypeNewNode,
+		Address:    &primitive.Inet{Addr: net.ParseIP("127.0.0.3"), Port: 9042},
+	})
+	debouncer.HandleEvent(topologyChange, nil)
+	require.Equal(t, []*frame.Frame{topologyChange}, recorder.recorded())
+
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 3 }, time.Second, 10*time.Millisecond)
+	forwarded := map[string]primitive.StatusChangeType{}
+	for _, event := range recorder.recorded()[1:] {
+		statusChange := event.Body.Message.(*message.StatusChangeEvent)
+		forwarded[statusChange.Address.Addr.String()] = statusChange.ChangeType
+	}
+	assert.Equal(t, map[string]primitive.StatusChangeType{
+		"127.0.0.1": primitive.StatusChangeTypeDown,
+		"127.0.0.2": primitive.StatusChangeTypeUp,
+	}, forwarded)
+
+	// same status as the last forwarded one: discarded
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+	time.Sleep(150 * time.Millisecond)
+	assert.Len(t, recorder.recorded(), 3)
+
+	// status actually changed: forwarded
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeUp, "127.0.0.1"), nil)
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 4 }, time.Second, 10*time.Millisecond)
+}
+
+func TestNewStatusChangeDebouncer_Expiry(t *testing.T) {
+	recorder := &eventRecorder{}
+	debouncer := client.NewStatusChangeDebouncer(20*time.Millisecond, recorder.handler)
+	defer debouncer.Close()
+
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+
+	// same status as the last forwarded one, but forwarded more than one window ago: forwarded again
+	time.Sleep(50 * time.Millisecond)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 2 }, time.Second, 5*time.Millisecond)
+}
+
+func TestEventDebouncer_Close(t *testing.T) {
+	recorder := &eventRecorder{}
+	debouncer := client.NewStatusChangeDebouncer(50*time.Millisecond, recorder.handler)
+	debouncer.HandleEvent(statusChangeEvent(primitive.StatusChangeTypeDown, "127.0.0.1"), nil)
+	debouncer.Close()
+
+	// held-back events are discarded
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, recorder.recorded())
+
+	// events received after closing are forwarded immediately
+	event := statusChangeEvent(primitive.StatusChangeTypeUp, "127.0.0.1")
+	debouncer.HandleEvent(event, nil)
+	assert.Equal(t, []*frame.Frame{event}, recorder.recorded())
+	debouncer.Close()
+}
+
+func TestNewSchemaChangeCoalescer_DistinctObjects(t *testing.T) {
+	recorder := &eventRecorder{}
+	coalescer := client.NewSchemaChangeCoalescer(50*time.Millisecond, recorder.handler)
+	defer coalescer.Close()
+
+	function := func(arguments ...string) *frame.Frame {
+		event := schemaChangeEvent(primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetFunction, "ks1", "f")
+		event.Body.Message.(*message.SchemaChangeEvent).Arguments = arguments
+		return event
+	}
+	events := []*frame.Frame{
+		schemaChangeEvent(primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetTable, "ks1", "o"),
+		schemaChangeEvent(primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetType, "ks1", "o"),
+		function("int"),
+		function("int", "text"),
+		function(),
+	}
+	for _, event := range events {
+		coalescer.HandleEvent(event, nil)
+	}
+	// same overload: coalesced, UPDATED does not override CREATED
+	lastOverload := function("int", "text")
+	lastOverload.Body.Message.(*message.SchemaChangeEvent).ChangeType = primitive.SchemaChangeTypeUpdated
+	coalescer.HandleEvent(lastOverload, nil)
+
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == len(events) }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	recorded := recorder.recorded()
+	require.Len(t, recorded, len(events))
+	assert.Contains(t, recorded, events[3])
+	assert.NotContains(t, recorded, lastOverload)
+}
+
+func schemaChangeEvent(changeType primitive.SchemaChangeType, target primitive.SchemaChangeTarget, keyspace, object string) *frame.Frame {
+	return frame.NewFrame(primitive.ProtocolVersion4, -1, &message.SchemaChangeEvent{
+		ChangeType: changeType,
+		Target:     target,
+		Keyspace:   keyspace,
+		Object:     object,
+	})
+}
+
+func TestNewSchemaChangeCoalescer(t *testing.T) {
+	recorder := &eventRecorder{}
+	coalescer := client.NewSchemaChangeCoalescer(50*time.Millisecond, recorder.handler)
+	defer coalescer.Close()
+
+	coalescer.HandleEvent(schemaChangeEvent(primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetKeyspace, "ks1", ""), nil)
+	coalescer.HandleEvent(schemaChangeEvent(primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetTable, "ks1", "t1"), nil)
+	coalescer.HandleEvent(schemaChangeEvent(primitive.SchemaChangeTypeUpdated, primitive.SchemaChangeTargetTable, "ks1", "t1"), nil)
+	coalescer.HandleEvent(schemaChangeEvent(primitive.SchemaChangeTypeUpdated, primitive.SchemaChangeTargetTable, "ks1", "t1"), nil)
+	lastTableChange := schemaChangeEvent(primitive.SchemaChangeTypeUpdated, primitive.SchemaChangeTargetTable, "ks1", "t1")
+	coalescer.HandleEvent(lastTableChange, nil)
+
+	statusChange := statusChangeEvent(primitive.StatusChangeTypeUp, "127.0.0.1")
+	coalescer.HandleEvent(statusChange, nil)
+	require.Equal(t, []*frame.Frame{statusChange}, recorder.recorded())
+
+	require.Eventually(t, func() bool { return len(recorder.recorded()) == 3 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	recorded := recorder.recorded()
+	require.Len(t, recorded, 3)
+	// UPDATED does not override CREATED
+	assert.Contains(t, recorded, schemaChangeEvent(
+		primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTargetTable, "ks1", "t1"))
+	assert.NotContains(t, recorded, lastTableChange)
+}
+
+func TestNewSchemaChangeCoalescer_MergedChangeTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		changes  []primitive.SchemaChangeType
+		expected primitive.SchemaChangeType
+	}{
+		{
+			"created then updated",
+			[]primitive.SchemaChangeType{primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTypeUpdated},
+			primitive.SchemaChangeTypeCreated,
+		},
+		{
+			"dropped then updated",
+			[]primitive.SchemaChangeType{primitive.SchemaChangeTypeDropped, primitive.SchemaChangeTypeUpdated},
+			primitive.SchemaChangeTypeDropped,
+		},
+		{
+			"updated then dropped",
+			[]primitive.SchemaChangeType{primitive.SchemaChangeTypeUpdated, primitive.SchemaChangeTypeDropped},
+			primitive.SchemaChangeTypeDropped,
+		},
+		{
+			"created then dropped",
+			[]primitive.SchemaChangeType{primitive.SchemaChangeTypeCreated, primitive.SchemaChangeTypeDropped},
+			primitive.SchemaChangeTypeDropped,
+		},
+		{
+			"dropped then created then updated",
+			[]primitive.SchemaChangeType{
+				primitive.SchemaChangeTypeDropped,
+				primitive.SchemaChangeTypeCreated,
+				primitive.SchemaChangeTypeUpdated,
+			},
+			primitive.SchemaChangeTypeCreated,
+		},
+		{
+			"updated then updated",
+			[]primitive.SchemaChangeType{primitive.SchemaChangeTypeUpdated, primitive.SchemaChangeTypeUpdated},
+			primitive.SchemaChangeTypeUpdated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &eventRecorder{}
+			coalescer := client.NewSchemaChangeCoalescer(20*time.Millisecond, recorder.handler)
+			defer coalescer.Close()
+			var events []*frame.Frame
+			for _, changeType := range tt.changes {
+				event := schemaChangeEvent(changeType, primitive.SchemaChangeTargetTable, "ks1", "t1")
+				events = append(events, event)
+				coalescer.HandleEvent(event, nil)
+			}
+			require.Eventually(t, func() bool { return len(recorder.recorded()) == 1 }, time.Second, 5*time.Millisecond)
+			forwarded := recorder.recorded()[0].Body.Message.(*message.SchemaChangeEvent)
+			assert.Equal(t, tt.expected, forwarded.ChangeType)
+			assert.Equal(t, primitive.SchemaChangeTargetTable, forwarded.Target)
+			assert.Equal(t, "ks1", forwarded.Keyspace)
+			assert.Equal(t, "t1", forwarded.Object)
+			// the received events are left untouched
+			for i, changeType := range tt.changes {
+				assert.Equal(t, changeType, events[i].Body.Message.(*message.SchemaChangeEvent).ChangeType)
+			}
+		})
+	}
+}
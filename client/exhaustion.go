@@ -0,0 +1,77 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+)
+
+// StreamIdExhaustionStrategy determines how a connection handles a request with a managed stream id when all stream
+// ids are in flight.
+type StreamIdExhaustionStrategy int
+
+const (
+	// StreamIdExhaustionFail rejects the request immediately with a *BusyError. This is the default strategy.
+	StreamIdExhaustionFail = StreamIdExhaustionStrategy(iota)
+	// StreamIdExhaustionBlock blocks the sender until a stream id is released, or until a deadline is reached, in
+	// which case the request is rejected with a *BusyError. See CqlClient.StreamIdWaitTimeout.
+	StreamIdExhaustionBlock
+	// StreamIdExhaustionQueue spills the request to an overflow queue: the request is accepted immediately, and is
+	// sent as soon as a stream id is released. Requests are dequeued in the order they were enqueued; their timeout
+	// starts when they are enqueued. If the overflow queue is full, the request is rejected with a *BusyError. See
+	// CqlClient.MaxOverflow.
+	StreamIdExhaustionQueue
+)
+
+func (s StreamIdExhaustionStrategy) String() string {
+	switch s {
+	case StreamIdExhaustionFail:
+		return "StreamIdExhaustionStrategy FAIL"
+	case StreamIdExhaustionBlock:
+		return "StreamIdExhaustionStrategy BLOCK"
+	case StreamIdExhaustionQueue:
+		return "StreamIdExhaustionStrategy QUEUE"
+	}
+	return fmt.Sprintf("StreamIdExhaustionStrategy ? [%d]", int(s))
+}
+
+// BusyError is returned when a request with a managed stream id cannot be sent because all stream ids on the
+// connection are in flight, and the connection's StreamIdExhaustionStrategy gave up on it.
+type BusyError struct {
+	// Strategy is the strategy that rejected the request.
+	Strategy StreamIdExhaustionStrategy
+	// MaxInFlight is the maximum number of in-flight requests on the connection.
+	MaxInFlight int
+	// MaxOverflow is the maximum size of the overflow queue; it is only relevant with StreamIdExhaustionQueue.
+	MaxOverflow int
+}
+
+func (e *BusyError) Error() string {
+	switch e.Strategy {
+	case StreamIdExhaustionBlock:
+		return fmt.Sprintf("no stream id available: %d requests in flight, timed out waiting for a stream id", e.MaxInFlight)
+	case StreamIdExhaustionQueue:
+		return fmt.Sprintf("no stream id available: %d requests in flight, overflow queue full: %d", e.MaxInFlight, e.MaxOverflow)
+	}
+	return fmt.Sprintf("no stream id available: %d requests in flight", e.MaxInFlight)
+}
+
+// overflowRequest is a request waiting in the overflow queue for a stream id.
+type overflowRequest struct {
+	frame    *frame.Frame
+	inFlight *inFlightRequest
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Fault is a fault injected by a fault injection handler, see NewFaultInjectionHandler.
+type Fault string
+
+const (
+	// FaultDropped is reported when the response to a request is dropped: no response is sent at all.
+	FaultDropped = Fault("dropped")
+	// FaultOverloaded is reported when an Overloaded error response is sent instead of the actual response.
+	FaultOverloaded = Fault("overloaded")
+	// FaultReadTimeout is reported when a ReadTimeout error response is sent instead of the actual response.
+	FaultReadTimeout = Fault("read-timeout")
+)
+
+// FaultInjectionPolicy describes the faults to inject in the responses produced by a chain of request handlers, for
+// chaos testing clients: delays, dropped responses and synthesized error responses. For each request subject to fault
+// injection, at most one fault among FaultDropped, FaultOverloaded and FaultReadTimeout is drawn, according to the
+// configured probabilities, whose sum should not exceed 1; delays apply to all requests subject to fault injection,
+// regardless of the fault drawn.
+type FaultInjectionPolicy struct {
+	// Filter optionally selects the requests subject to fault injection. If nil, DefaultPolicyFilter is used, so that
+	// connections can still be established and initialized.
+	Filter func(request *frame.Frame) bool
+	// Delay is the delay to apply before sending the response to each request subject to fault injection.
+	Delay time.Duration
+	// DelayJitter is the maximum random delay to add to Delay.
+	DelayJitter time.Duration
+	// DropProbability is the probability, between 0 and 1, that the response to a request is dropped.
+	DropProbability float64
+	// OverloadedProbability is the probability, between 0 and 1, that an Overloaded error response is sent instead of
+	// the actual response to a request.
+	OverloadedProbability float64
+	// ReadTimeoutProbability is the probability, between 0 and 1, that a ReadTimeout error response is sent instead
+	// of the actual response to a request. The error reports the consistency level of the request, and no replica
+	// response.
+	ReadTimeoutProbability float64
+	// OnFault is an optional callback invoked for each injected fault; delays are not reported.
+	OnFault func(fault Fault, request *frame.Frame)
+}
+
+// isSubject returns true if the given request is subject to fault injection.
+func (p *FaultInjectionPolicy) isSubject(request *frame.Frame) bool {
+	if p.Filter != nil {
+		return p.Filter(request)
+	}
+	return DefaultPolicyFilter(request.Header)
+}
+
+// drawFault draws the fault to inject, if any.
+func (p *FaultInjectionPolicy) drawFault() (Fault, bool) {
+	draw := rand.Float64()
+	if draw < p.DropProbability {
+		return FaultDropped, true
+	} else if draw < p.DropProbability+p.OverloadedProbability {
+		return FaultOverloaded, true
+	} else if draw < p.DropProbability+p.OverloadedProbability+p.ReadTimeoutProbability {
+		return FaultReadTimeout, true
+	}
+	return "", false
+}
+
+// delay waits for the configured delay; it returns false if the connection was closed in the meantime.
+func (p *FaultInjectionPolicy) delay(conn *CqlServerConnection) bool {
+	delay := p.Delay
+	if p.DelayJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.DelayJitter) + 1))
+	}
+	if delay <= 0 {
+		return true
+	}
+	var done <-chan struct{}
+	if conn != nil {
+		done = conn.ctx.Done()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// NewFaultInjectionHandler creates a new RequestHandler that injects faults in the responses produced by the given
+// handler, according to the given policy. Requests are delegated to the handler unless their response is replaced
+// with a synthesized error. Since a dropped response is materialized by a nil response, and the server then invokes
+// the next registered handler, the fault injection handler should wrap all the request handlers, e.g. with
+// NewCompositeRequestHandler, and be registered as the only request handler. This is typically used in proxies and
+// server stubs, to test how clients react to slow and faulty servers.
+func NewFaultInjectionHandler(policy *FaultInjectionPolicy, handler RequestHandler) RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+		if !policy.isSubject(request) {
+			return handler(request, conn, ctx)
+		}
+		fault, injected := policy.drawFault()
+		if !policy.delay(conn) {
+			return nil
+		}
+		if !injected {
+			return handler(request, conn, ctx)
+		}
+		log.Debug().Msgf("%v: [fault injection handler]: injecting fault %v for request: %v", conn, fault, request)
+		if policy.OnFault != nil {
+			policy.OnFault(fault, request)
+		}
+		switch fault {
+		case FaultOverloaded:
+			response = frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{
+				ErrorMessage: "Injected fault: server overloaded",
+			})
+		case FaultReadTimeout:
+			response = frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.ReadTimeout{
+				ErrorMessage: "Injected fault: read timeout",
+				Consistency:  requestConsistency(request),
+				BlockFor:     1,
+			})
+		}
+		return
+	}
+}
+
+// requestConsistency returns the consistency level of the given request, or ONE if the request has none, e.g. PREPARE.
+// Requests with nil query options have the default consistency level ANY.
+func requestConsistency(request *frame.Frame) primitive.ConsistencyLevel {
+	var options *message.QueryOptions
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		options = msg.Options
+	case *message.Execute:
+		options = msg.Options
+	case *message.Batch:
+		return msg.Consistency
+	default:
+		return primitive.ConsistencyLevelOne
+	}
+	if options == nil {
+		return primitive.ConsistencyLevelAny
+	}
+	return options.Consistency
+}
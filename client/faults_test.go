@@ -0,0 +1,153 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var voidResultHandler client.RequestHandler = func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+}
+
+func TestNewFaultInjectionHandler(t *testing.T) {
+	query := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query:   "SELECT * FROM ks.t",
+		Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelLocalQuorum},
+	})
+	options := frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Options{})
+	tests := []struct {
+		name     string
+		policy   *client.FaultInjectionPolicy
+		request  *frame.Frame
+		expected message.Message
+		fault    client.Fault
+	}{
+		{"no fault", &client.FaultInjectionPolicy{}, query, &message.VoidResult{}, ""},
+		{"dropped", &client.FaultInjectionPolicy{DropProbability: 1}, query, nil, client.FaultDropped},
+		{
+			"overloaded",
+			&client.FaultInjectionPolicy{OverloadedProbability: 1},
+			query,
+			&message.Overloaded{ErrorMessage: "Injected fault: server overloaded"},
+			client.FaultOverloaded,
+		},
+		{
+			"read timeout",
+			&client.FaultInjectionPolicy{ReadTimeoutProbability: 1},
+			query,
+			&message.ReadTimeout{
+				ErrorMessage: "Injected fault: read timeout",
+				Consistency:  primitive.ConsistencyLevelLocalQuorum,
+				BlockFor:     1,
+			},
+			client.FaultReadTimeout,
+		},
+		{"not subject by default", &client.FaultInjectionPolicy{DropProbability: 1}, options, &message.VoidResult{}, ""},
+		{
+			"filter",
+			&client.FaultInjectionPolicy{
+				DropProbability: 1,
+				Filter:          func(request *frame.Frame) bool { return request.Header.OpCode == primitive.OpCodeOptions },
+			},
+			options,
+			nil,
+			client.FaultDropped,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var faults []client.Fault
+			tt.policy.OnFault = func(fault client.Fault, request *frame.Frame) {
+				assert.Equal(t, tt.request, request)
+				faults = append(faults, fault)
+			}
+			response := client.NewFaultInjectionHandler(tt.policy, voidResultHandler)(tt.request, nil, nil)
+			if tt.expected == nil {
+				assert.Nil(t, response)
+			} else {
+				require.NotNil(t, response)
+				assert.Equal(t, tt.request.Header.StreamId, response.Header.StreamId)
+				assert.Equal(t, tt.expected, response.Body.Message)
+			}
+			if tt.fault == "" {
+				assert.Empty(t, faults)
+			} else {
+				assert.Equal(t, []client.Fault{tt.fault}, faults)
+			}
+		})
+	}
+}
+
+func TestNewFaultInjectionHandler_Probabilities(t *testing.T) {
+	counts := map[client.Fault]int{}
+	policy := &client.FaultInjectionPolicy{
+		DropProbability:        0.1,
+		OverloadedProbability:  0.2,
+		ReadTimeoutProbability: 0.3,
+		OnFault:                func(fault client.Fault, _ *frame.Frame) { counts[fault]++ },
+	}
+	handler := client.NewFaultInjectionHandler(policy, voidResultHandler)
+	query := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM ks.t"})
+	for i := 0; i < 10000; i++ {
+		handler(query, nil, nil)
+	}
+	assert.InDelta(t, 1000, counts[client.FaultDropped], 300)
+	assert.InDelta(t, 2000, counts[client.FaultOverloaded], 300)
+	assert.InDelta(t, 3000, counts[client.FaultReadTimeout], 300)
+}
+
+func TestNewFaultInjectionHandler_Server(t *testing.T) {
+	policy := &client.FaultInjectionPolicy{Delay: 200 * time.Millisecond}
+	handler := client.NewFaultInjectionHandler(policy, client.NewCompositeRequestHandler(
+		client.HeartbeatHandler,
+		voidResultHandler,
+	))
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+	defer cancelFn()
+
+	query := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "INSERT"})
+	start := time.Now()
+	response, err := clientConn.SendAndReceive(query)
+	require.NoError(t, err)
+	assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+
+	start = time.Now()
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, response.Body.Message)
+	assert.Less(t, int64(time.Since(start)), int64(200*time.Millisecond))
+
+	policy.Delay = 0
+	policy.DropProbability = 1
+	inFlight, err := clientConn.SendWithTimeout(query, 200*time.Millisecond)
+	require.NoError(t, err)
+	_, err = clientConn.Receive(inFlight)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
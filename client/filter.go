@@ -0,0 +1,41 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DefaultPolicyFilter is the filter used by RateLimitPolicy, FaultInjectionPolicy and CorruptionPolicy when they have
+// no Filter configured. It returns false for the frames involved in connection establishment: STARTUP, OPTIONS,
+// SUPPORTED, AUTH_RESPONSE and REGISTER requests, and READY, AUTHENTICATE, AUTH_CHALLENGE and AUTH_SUCCESS responses;
+// it returns true for all other frames, so that policies never prevent connections from being established and
+// initialized. It can be used to compose custom filters.
+func DefaultPolicyFilter(header *frame.Header) bool {
+	switch header.OpCode {
+	case primitive.OpCodeStartup,
+		primitive.OpCodeOptions,
+		primitive.OpCodeSupported,
+		primitive.OpCodeAuthResponse,
+		primitive.OpCodeRegister,
+		primitive.OpCodeReady,
+		primitive.OpCodeAuthenticate,
+		primitive.OpCodeAuthChallenge,
+		primitive.OpCodeAuthSuccess:
+		return false
+	}
+	return true
+}
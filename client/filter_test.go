@@ -0,0 +1,52 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestDefaultPolicyFilter(t *testing.T) {
+	for _, opCode := range []primitive.OpCode{
+		primitive.OpCodeStartup,
+		primitive.OpCodeOptions,
+		primitive.OpCodeSupported,
+		primitive.OpCodeAuthResponse,
+		primitive.OpCodeRegister,
+		primitive.OpCodeReady,
+		primitive.OpCodeAuthenticate,
+		primitive.OpCodeAuthChallenge,
+		primitive.OpCodeAuthSuccess,
+	} {
+		assert.False(t, client.DefaultPolicyFilter(&frame.Header{OpCode: opCode}), opCode.String())
+	}
+	for _, opCode := range []primitive.OpCode{
+		primitive.OpCodeQuery,
+		primitive.OpCodePrepare,
+		primitive.OpCodeExecute,
+		primitive.OpCodeBatch,
+		primitive.OpCodeResult,
+		primitive.OpCodeError,
+		primitive.OpCodeEvent,
+	} {
+		assert.True(t, client.DefaultPolicyFilter(&frame.Header{OpCode: opCode}), opCode.String())
+	}
+}
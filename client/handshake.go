@@ -53,8 +53,9 @@ func PerformHandshake(clientConn *CqlClientConnection, serverConn *CqlServerConn
 }
 
 // InitiateHandshake initiates the handshake procedure to initialize the client connection, using the given protocol
-// version. The handshake will use authentication if the connection was created with auth credentials; otherwise it will
-// proceed without authentication. Use stream id zero to activate automatic stream id management.
+// version. The handshake will use authentication if the connection was created with auth credentials or with an
+// Authenticator; otherwise it will proceed without authentication. Use stream id zero to activate automatic stream id
+// management.
 func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersion, streamId int16) (err error) {
 	log.Debug().Msgf("%v: performing handshake", c)
 	if startup, err := c.NewStartupRequest(version, streamId); err != nil {
@@ -62,7 +63,7 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 	} else {
 		var response *frame.Frame
 		if response, err = c.SendAndReceive(startup); err == nil {
-			if c.credentials == nil {
+			if c.credentials == nil && c.authenticator == nil {
 				if _, authSuccess := response.Body.Message.(*message.Ready); !authSuccess {
 					err = fmt.Errorf("expected READY, got %v", response.Body.Message)
 				}
@@ -72,7 +73,10 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 					log.Warn().Msgf("%v: expected AUTHENTICATE, got READY – is authentication required?", c)
 					break
 				case *message.Authenticate:
-					authenticator := &PlainTextAuthenticator{c.credentials}
+					authenticator := c.authenticator
+					if authenticator == nil {
+						authenticator = &PlainTextAuthenticator{c.credentials}
+					}
 					var initialResponse []byte
 					if initialResponse, err = authenticator.InitialResponse(msg.Authenticator); err == nil {
 						authResponse := frame.NewFrame(version, streamId, &message.AuthResponse{Token: initialResponse})
@@ -111,6 +115,25 @@ func (c *CqlClientConnection) InitiateHandshake(version primitive.ProtocolVersio
 	}
 }
 
+// DiscoverProtocolVersion sends an OPTIONS request using the given protocol version, and returns the highest protocol
+// version supported by both the server and this library, as advertised in the PROTOCOL_VERSIONS option of the
+// SUPPORTED response (Cassandra 4.0+). If the server does not advertise its protocol versions, the given version is
+// returned. This must be done before the handshake, and avoids the protocol version downgrade procedure on modern
+// clusters. The SUPPORTED response is cached, see Supported. Use stream id zero to activate automatic stream id
+// management.
+func (c *CqlClientConnection) DiscoverProtocolVersion(version primitive.ProtocolVersion, streamId int16) (primitive.ProtocolVersion, error) {
+	supported, err := c.RefreshSupported(version, streamId)
+	if err != nil {
+		return version, err
+	}
+	if highest, found := supported.HighestSupportedVersion(); found {
+		log.Debug().Msgf("%v: server supports protocol versions up to %v", c, highest)
+		return highest, nil
+	}
+	log.Debug().Msgf("%v: server did not advertise its protocol versions, using %v", c, version)
+	return version, nil
+}
+
 // AcceptHandshake Listens for a client STARTUP request and proceeds with the server-side handshake procedure.
 // Authentication will be required if the connection was created with auth credentials; otherwise the handshake will
 // proceed without authentication.
@@ -182,8 +205,24 @@ const (
 
 // HandshakeHandler is a RequestHandler to handle server-side handshakes. This is an alternative to
 // CqlServerConnection.AcceptHandshake to make the server connection automatically handle all incoming handshake
-// attempts.
-var HandshakeHandler RequestHandler = func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+// attempts. To customize how STARTUP requests are handled, use NewHandshakeHandler instead.
+var HandshakeHandler = NewHandshakeHandler()
+
+// NewHandshakeHandler creates a RequestHandler that handles server-side handshakes just like HandshakeHandler, except
+// that STARTUP requests are first passed to the given hooks, in order; the first non-nil response returned by a hook
+// is sent instead of the default response. See StartupHook.
+func NewHandshakeHandler(hooks ...StartupHook) RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+		return handleHandshake(request, conn, ctx, hooks)
+	}
+}
+
+func handleHandshake(
+	request *frame.Frame,
+	conn *CqlServerConnection,
+	ctx RequestHandlerContext,
+	hooks []StartupHook,
+) (response *frame.Frame) {
 	if ctx.GetAttribute(handshakeStateKey) == handshakeStateDone {
 		return
 	}
@@ -194,6 +233,20 @@ var HandshakeHandler RequestHandler = func(request *frame.Frame, conn *CqlServer
 		log.Debug().Msgf("%v: [handshake handler]: intercepted OPTIONS before STARTUP", conn)
 		response = frame.NewFrame(version, id, &message.Supported{})
 	case *message.Startup:
+		for _, hook := range hooks {
+			if response = hook(request, msg, conn); response != nil {
+				switch response.Body.Message.(type) {
+				case *message.Ready:
+					ctx.PutAttribute(handshakeStateKey, handshakeStateDone)
+					log.Info().Msgf("%v: [handshake handler]: handshake successful", conn)
+				case *message.Authenticate:
+					ctx.PutAttribute(handshakeStateKey, handshakeStateStarted)
+				default:
+					log.Debug().Msgf("%v: [handshake handler]: STARTUP rejected: %v", conn, response.Body.Message)
+				}
+				return
+			}
+		}
 		if conn.Credentials() == nil {
 			ctx.PutAttribute(handshakeStateKey, handshakeStateDone)
 			log.Info().Msgf("%v: [handshake handler]: handshake successful", conn)
@@ -207,8 +260,8 @@ var HandshakeHandler RequestHandler = func(request *frame.Frame, conn *CqlServer
 			userCredentials := &AuthCredentials{}
 			if err := userCredentials.Unmarshal(msg.Token); err == nil {
 				serverCredentials := conn.Credentials()
-				if userCredentials.Username == serverCredentials.Username &&
-					userCredentials.Password == serverCredentials.Password {
+				if serverCredentials == nil || (userCredentials.Username == serverCredentials.Username &&
+					userCredentials.Password == serverCredentials.Password) {
 					log.Info().Msgf("%v: [handshake handler]: handshake successful", conn)
 					response = frame.NewFrame(version, id, &message.AuthSuccess{})
 				} else {
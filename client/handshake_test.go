@@ -17,9 +17,12 @@ package client_test
 import (
 	"context"
 	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 	"time"
 )
@@ -81,3 +84,80 @@ func TestHandshakeHandler_Auth(t *testing.T) {
 	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
 
 }
+
+func TestCqlClient_ConnectAndInitWithDiscovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string][]string
+		expected primitive.ProtocolVersion
+	}{
+		{"advertised", map[string][]string{message.SupportedProtocolVersions: {"3/v3", "4/v4", "5/v5", "6/v6-beta"}}, primitive.ProtocolVersion5},
+		{"not advertised", map[string][]string{}, primitive.ProtocolVersion3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			optionsHandler := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+				if _, ok := request.Body.Message.(*message.Options); ok {
+					return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Supported{Options: tt.options})
+				}
+				return nil
+			}
+			server.RequestHandlers = []client.RequestHandler{optionsHandler, client.HandshakeHandler}
+			ctx, cancelFn := context.WithCancel(context.Background())
+			require.NoError(t, server.Start(ctx))
+
+			clientConn, version, err := client.NewCqlClient("127.0.0.1:9043", nil).
+				ConnectAndInitWithDiscovery(ctx, primitive.ProtocolVersion3, client.ManagedStreamId)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+
+			cancelFn()
+			assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+			assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+		})
+	}
+}
+
+func TestCqlClient_Authenticator(t *testing.T) {
+	// mimics the SigV4 SASL mechanism of Amazon Keyspaces
+	sigV4Handler := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) *frame.Frame {
+		version := request.Header.Version
+		id := request.Header.StreamId
+		switch msg := request.Body.Message.(type) {
+		case *message.Startup:
+			return frame.NewFrame(version, id, &message.Authenticate{Authenticator: "com.amazonaws.cassandra.DefaultPasswordAuthenticator"})
+		case *message.AuthResponse:
+			if string(msg.Token) == "SigV4\000\000" {
+				return frame.NewFrame(version, id, &message.AuthChallenge{Token: []byte("nonce=91703fdc2ef562e19fbdab0f58e42fe5")})
+			} else if strings.HasPrefix(string(msg.Token), "signature=") && strings.Contains(string(msg.Token), "access_key=UserID-1") {
+				return frame.NewFrame(version, id, &message.AuthSuccess{})
+			}
+			return frame.NewFrame(version, id, &message.AuthenticationError{ErrorMessage: "invalid signature"})
+		}
+		return nil
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{sigV4Handler}
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Authenticator = &client.SigV4Authenticator{
+		Region:          "us-west-2",
+		AccessKeyId:     "UserID-1",
+		SecretAccessKey: "UserSecretKey-1",
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	cancelFn()
+
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
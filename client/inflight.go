@@ -16,6 +16,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -38,6 +39,22 @@ type inFlightRequestsHandler struct {
 	inFlight     map[int16]*inFlightRequest
 	inFlightLock *sync.RWMutex
 	closed       int32
+	// draining is set by CloseGracefully: new requests are rejected, but in-flight ones are allowed to complete.
+	draining int32
+	// onLateFrame, if set, is invoked whenever a late frame is received for an orphaned request.
+	onLateFrame func(f *frame.Frame, elapsed time.Duration)
+	lateFrames  uint64
+	// strategy is applied when no managed stream id is available, see StreamIdExhaustionStrategy.
+	strategy StreamIdExhaustionStrategy
+	// waitTimeout is the maximum time to wait for a stream id with StreamIdExhaustionBlock; if zero, the request
+	// timeout is used instead.
+	waitTimeout time.Duration
+	// maxOverflow is the maximum size of the overflow queue with StreamIdExhaustionQueue.
+	maxOverflow  int
+	overflow     []*overflowRequest
+	overflowLock *sync.Mutex
+	// dispatch sends a request dequeued from the overflow queue; it must be set with StreamIdExhaustionQueue.
+	dispatch func(f *frame.Frame) error
 }
 
 func (h *inFlightRequestsHandler) String() string {
@@ -60,6 +77,7 @@ func newInFlightRequestsHandler(
 		streamIds:    make(chan int16, maxInFlight),
 		inFlight:     make(map[int16]*inFlightRequest, maxInFlight),
 		inFlightLock: &sync.RWMutex{},
+		overflowLock: &sync.Mutex{},
 	}
 	for i := 1; i <= maxInFlight; i++ {
 		handler.streamIds <- int16(i)
@@ -67,19 +85,41 @@ func newInFlightRequestsHandler(
 	return handler
 }
 
-func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame) (InFlightRequest, error) {
+// onOutgoingFrameEnqueued registers the given outgoing frame as an in-flight request. If the returned boolean is true,
+// the frame was spilled to the overflow queue and must not be sent by the caller: it will be dispatched as soon as a
+// stream id is released.
+func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame, timeout time.Duration) (InFlightRequest, bool, error) {
 	if h.isClosed() {
-		return nil, fmt.Errorf("%v: handler closed", h)
+		return nil, false, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
+	} else if h.isDraining() {
+		return nil, false, fmt.Errorf("%v: handler draining: %w", h, ErrConnectionClosed)
+	}
+	if timeout <= 0 {
+		timeout = h.timeout
 	}
 	var err error
 	streamId := f.Header.StreamId
 	managedStreamId := streamId == ManagedStreamId
 	if managedStreamId {
-		if streamId, err = h.borrowStreamId(); err != nil {
-			return nil, err
+		if h.strategy == StreamIdExhaustionQueue {
+			// hold the overflow lock to prevent a stream id from being released between the failed attempt to borrow
+			// it and the enqueueing of the request
+			h.overflowLock.Lock()
+			streamId, err = h.borrowStreamId(timeout)
+			var busy *BusyError
+			if errors.As(err, &busy) {
+				inFlight, err := h.enqueueOverflow(f, timeout)
+				h.overflowLock.Unlock()
+				return inFlight, inFlight != nil, err
+			}
+			h.overflowLock.Unlock()
 		} else {
-			f.Header.StreamId = streamId
+			streamId, err = h.borrowStreamId(timeout)
 		}
+		if err != nil {
+			return nil, false, err
+		}
+		f.Header.StreamId = streamId
 	}
 	h.inFlightLock.RLock()
 	if len(h.inFlight) == h.maxInFlight {
@@ -90,18 +130,18 @@ func (h *inFlightRequestsHandler) onOutgoingFrameEnqueued(f *frame.Frame) (InFli
 	h.inFlightLock.RUnlock()
 	if err == nil {
 		var inFlight *inFlightRequest
-		inFlight, err = h.addInFlight(streamId, managedStreamId)
+		inFlight, err = h.addInFlight(streamId, managedStreamId, timeout)
 		if err == nil {
 			inFlight.startTimeout()
-			return inFlight, nil
+			return inFlight, false, nil
 		}
 	}
-	return nil, err
+	return nil, false, err
 }
 
 func (h *inFlightRequestsHandler) onIncomingFrameReceived(f *frame.Frame) error {
 	if h.isClosed() {
-		return fmt.Errorf("%v: handler closed", h)
+		return fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
 	}
 	streamId := f.Header.StreamId
 	var err error
@@ -121,17 +161,29 @@ func (h *inFlightRequestsHandler) onIncomingFrameReceived(f *frame.Frame) error
 				}
 			}
 		}
-		err = inFlight.onFrameReceived(f)
+		if inFlight.isOrphaned() {
+			elapsed := time.Since(inFlight.sentAt)
+			log.Debug().Msgf("%v: late frame received for orphaned stream id %d after %v, discarding", h, streamId, elapsed)
+			atomic.AddUint64(&h.lateFrames, 1)
+			if h.onLateFrame != nil {
+				h.onLateFrame(f, elapsed)
+			}
+		} else {
+			err = inFlight.onFrameReceived(f)
+		}
 	}
 	return err
 }
 
-func (h *inFlightRequestsHandler) addInFlight(streamId int16, managedStreamId bool) (*inFlightRequest, error) {
-	inFlight := newInFlightRequest(h.String(), streamId, managedStreamId, h.ctx, h.maxPending, h.timeout)
+func (h *inFlightRequestsHandler) addInFlight(streamId int16, managedStreamId bool, timeout time.Duration) (*inFlightRequest, error) {
+	if timeout <= 0 {
+		timeout = h.timeout
+	}
+	inFlight := newInFlightRequest(h.String(), streamId, managedStreamId, h.ctx, h.maxPending, timeout)
 	h.inFlightLock.Lock()
 	defer h.inFlightLock.Unlock()
 	if h.isClosed() {
-		return nil, fmt.Errorf("%v: handler closed", h)
+		return nil, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
 	}
 	h.inFlight[streamId] = inFlight
 	return inFlight, nil
@@ -145,25 +197,66 @@ func (h *inFlightRequestsHandler) removeInFlight(streamId int16) {
 	}
 }
 
-func (h *inFlightRequestsHandler) borrowStreamId() (int16, error) {
+func (h *inFlightRequestsHandler) lateFramesCount() uint64 {
+	return atomic.LoadUint64(&h.lateFrames)
+}
+
+func (h *inFlightRequestsHandler) borrowStreamId(timeout time.Duration) (int16, error) {
 	if h.isClosed() {
-		return -1, fmt.Errorf("%v: handler closed", h)
+		return -1, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
 	}
+	streamIds := h.streamIds
 	select {
-	case id, ok := <-h.streamIds:
+	case id, ok := <-streamIds:
 		if !ok {
-			return -1, fmt.Errorf("%v: handler closed", h)
+			return -1, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
 		}
 		log.Debug().Msgf("%v: borrowed stream id: %v", h, id)
 		return id, nil
 	default:
-		return -1, fmt.Errorf("%v: no stream id available", h)
+		if h.strategy != StreamIdExhaustionBlock {
+			return -1, fmt.Errorf("%v: %w", h, h.busyError())
+		}
+	}
+	if h.waitTimeout > 0 {
+		timeout = h.waitTimeout
+	}
+	log.Debug().Msgf("%v: no stream id available, waiting up to %v", h, timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case id, ok := <-streamIds:
+		if !ok {
+			return -1, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
+		}
+		log.Debug().Msgf("%v: borrowed stream id: %v", h, id)
+		return id, nil
+	case <-timer.C:
+		return -1, fmt.Errorf("%v: %w", h, h.busyError())
+	case <-h.ctx.Done():
+		return -1, fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
 	}
 }
 
+func (h *inFlightRequestsHandler) busyError() *BusyError {
+	return &BusyError{Strategy: h.strategy, MaxInFlight: h.maxInFlight, MaxOverflow: h.maxOverflow}
+}
+
+// releaseStreamId releases the given stream id. If the overflow queue is not empty, the stream id is assigned to the
+// first queued request instead, and that request is dispatched.
 func (h *inFlightRequestsHandler) releaseStreamId(id int16) error {
 	if h.isClosed() {
-		return fmt.Errorf("%v: handler closed", h)
+		return fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed)
+	}
+	h.overflowLock.Lock()
+	defer h.overflowLock.Unlock()
+	for len(h.overflow) > 0 {
+		next := h.overflow[0]
+		h.overflow[0] = nil
+		h.overflow = h.overflow[1:]
+		if h.dequeueOverflow(id, next) {
+			return nil
+		}
 	}
 	select {
 	case h.streamIds <- id:
@@ -174,6 +267,54 @@ func (h *inFlightRequestsHandler) releaseStreamId(id int16) error {
 	}
 }
 
+// enqueueOverflow spills the given frame to the overflow queue. Must be called while holding the overflow lock.
+func (h *inFlightRequestsHandler) enqueueOverflow(f *frame.Frame, timeout time.Duration) (*inFlightRequest, error) {
+	// discard requests that timed out while queued
+	pending := h.overflow[:0]
+	for _, queued := range h.overflow {
+		if !queued.inFlight.IsDone() {
+			pending = append(pending, queued)
+		}
+	}
+	for i := len(pending); i < len(h.overflow); i++ {
+		h.overflow[i] = nil
+	}
+	h.overflow = pending
+	if len(h.overflow) >= h.maxOverflow {
+		return nil, fmt.Errorf("%v: %w", h, h.busyError())
+	}
+	inFlight := newInFlightRequest(h.String(), ManagedStreamId, true, h.ctx, h.maxPending, timeout)
+	inFlight.startTimeout()
+	h.overflow = append(h.overflow, &overflowRequest{frame: f, inFlight: inFlight})
+	log.Debug().Msgf("%v: no stream id available, request queued (%d queued)", h, len(h.overflow))
+	return inFlight, nil
+}
+
+// dequeueOverflow assigns the given stream id to the given queued request, then dispatches it. It returns false if
+// the request could not be dispatched, in which case the stream id is still available. Must be called while holding
+// the overflow lock.
+func (h *inFlightRequestsHandler) dequeueOverflow(id int16, queued *overflowRequest) bool {
+	if queued.inFlight.IsDone() {
+		return false
+	}
+	queued.frame.Header.StreamId = id
+	queued.inFlight.assignStreamId(id)
+	h.inFlightLock.Lock()
+	if h.isClosed() {
+		h.inFlightLock.Unlock()
+		return false
+	}
+	h.inFlight[id] = queued.inFlight
+	h.inFlightLock.Unlock()
+	if err := h.dispatch(queued.frame); err != nil {
+		h.removeInFlight(id)
+		queued.inFlight.close(fmt.Errorf("%v: cannot dispatch queued request: %w", h, err))
+		return false
+	}
+	log.Debug().Msgf("%v: dispatched queued request with stream id: %v", h, id)
+	return true
+}
+
 func (h *inFlightRequestsHandler) isClosed() bool {
 	return atomic.LoadInt32(&h.closed) == 1
 }
@@ -188,9 +329,15 @@ func (h *inFlightRequestsHandler) close() {
 		h.inFlightLock.Lock()
 		for streamId, inFlight := range h.inFlight {
 			delete(h.inFlight, streamId)
-			inFlight.close(fmt.Errorf("%v: handler closed", h))
+			inFlight.close(fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed))
 		}
 		h.inFlightLock.Unlock()
+		h.overflowLock.Lock()
+		for _, queued := range h.overflow {
+			queued.inFlight.close(fmt.Errorf("%v: handler closed: %w", h, ErrConnectionClosed))
+		}
+		h.overflow = nil
+		h.overflowLock.Unlock()
 		streamIds := h.streamIds
 		h.streamIds = nil
 		close(streamIds)
@@ -206,11 +353,16 @@ type inFlightRequest struct {
 	incoming        chan *frame.Frame // exposed externally; never nil
 	err             error
 	done            bool
-	timeout         time.Duration
-	ctx             context.Context
-	cancel          context.CancelFunc
-	timeoutCtx      context.Context
-	timeoutCancel   context.CancelFunc
+	// sentAt is the time when the request was registered.
+	sentAt time.Time
+	// orphaned is true if the request timed out; its stream id remains in use until the late response arrives, or the
+	// connection is closed, whichever happens first.
+	orphaned      bool
+	timeout       time.Duration
+	ctx           context.Context
+	cancel        context.CancelFunc
+	timeoutCtx    context.Context
+	timeoutCancel context.CancelFunc
 
 	// lock guards the closing of incoming chan and the assignment of done and err;
 	// required to fulfill the interface contract:
@@ -220,6 +372,8 @@ type inFlightRequest struct {
 }
 
 func (r *inFlightRequest) StreamId() int16 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
 	return r.streamId
 }
 
@@ -241,6 +395,12 @@ func (r *inFlightRequest) Err() error {
 	return r.err
 }
 
+func (r *inFlightRequest) isOrphaned() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.orphaned
+}
+
 func newInFlightRequest(
 	handlerId string,
 	streamId int16,
@@ -261,6 +421,7 @@ func newInFlightRequest(
 		ctx:             ctx,
 		cancel:          cancel,
 		lock:            &sync.RWMutex{},
+		sentAt:          time.Now(),
 	}
 }
 
@@ -268,6 +429,14 @@ func (r *inFlightRequest) String() string {
 	return fmt.Sprintf("%v [stream id %d]", r.handlerId, r.streamId)
 }
 
+// assignStreamId assigns a stream id to a request dequeued from the overflow queue.
+func (r *inFlightRequest) assignStreamId(id int16) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.streamId = id
+	r.sentAt = time.Now()
+}
+
 func (r *inFlightRequest) onFrameReceived(f *frame.Frame) error {
 	select {
 	case r._incoming <- f:
@@ -288,15 +457,17 @@ func (r *inFlightRequest) onFrameReceived(f *frame.Frame) error {
 }
 
 func (r *inFlightRequest) startTimeout() {
-	r.timeoutCtx, r.timeoutCancel = context.WithTimeout(r.ctx, r.timeout)
+	// the timeout context is captured locally since the fields may be reassigned by resetTimeout
+	timeoutCtx, timeoutCancel := context.WithTimeout(r.ctx, r.timeout)
+	r.timeoutCtx, r.timeoutCancel = timeoutCtx, timeoutCancel
 	log.Trace().Msgf("%v: timeout started", r)
 	go func() {
 		select {
-		case <-r.timeoutCtx.Done():
-			switch r.timeoutCtx.Err() {
+		case <-timeoutCtx.Done():
+			switch timeoutCtx.Err() {
 			case context.DeadlineExceeded:
 				err := fmt.Errorf("%v: timed out waiting for incoming frames", r)
-				r.close(err)
+				r.orphan(err)
 			case context.Canceled:
 				log.Trace().Msgf("%v: timeout canceled", r)
 			}
@@ -310,7 +481,7 @@ func (r *inFlightRequest) stopTimeout() {
 	}
 }
 
-func (r inFlightRequest) resetTimeout() {
+func (r *inFlightRequest) resetTimeout() {
 	r.stopTimeout()
 	r.startTimeout()
 }
@@ -331,6 +502,16 @@ func (r *inFlightRequest) close(err error) {
 	log.Trace().Msgf("%v: successfully closed", r)
 }
 
+func (r *inFlightRequest) orphan(err error) {
+	r.lock.Lock()
+	if !r.done {
+		log.Debug().Msgf("%v: request timed out, stream id is now orphaned", r)
+		r.orphaned = true
+	}
+	r.lock.Unlock()
+	r.close(err)
+}
+
 func isLastFrame(f *frame.Frame) bool {
 	if f.Header.OpCode == primitive.OpCodeResult {
 		result := f.Body.Message.(message.Result)
@@ -0,0 +1,344 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_SendWithTimeout_OrphanedStreamId(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.MaxInFlight = 1
+	lateResponses := make(chan *frame.Frame, 1)
+	clt.LateResponseHandler = func(response *frame.Frame, conn *client.CqlClientConnection, elapsed time.Duration) {
+		assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+		lateResponses <- response
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, serverConn, err := server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	_, err = clientConn.SendWithTimeout(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}), 0)
+	require.Error(t, err)
+
+	request := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{})
+	inFlight, err := clientConn.SendWithTimeout(request, 100*time.Millisecond)
+	require.NoError(t, err)
+	incoming, err := serverConn.Receive()
+	require.NoError(t, err)
+
+	// the request times out: its stream id is now orphaned
+	response, err := clientConn.Receive(inFlight)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Nil(t, response)
+	assert.True(t, inFlight.IsDone())
+
+	// the only stream id available is still in use
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no stream id available")
+
+	// late response arrives: the stream id is released
+	err = serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, incoming.Header.StreamId, &message.Supported{}))
+	require.NoError(t, err)
+	select {
+	case late := <-lateResponses:
+		assert.Equal(t, incoming.Header.StreamId, late.Header.StreamId)
+		assert.IsType(t, &message.Supported{}, late.Body.Message)
+	case <-time.After(time.Second * 5):
+		t.Fatal("late response handler was not invoked")
+	}
+	assert.EqualValues(t, 1, clientConn.LateResponses())
+	require.Eventually(t, func() bool {
+		inFlight, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+		if err != nil {
+			return false
+		}
+		incoming, err := serverConn.Receive()
+		require.NoError(t, err)
+		err = serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, incoming.Header.StreamId, &message.Supported{}))
+		require.NoError(t, err)
+		response, err := clientConn.Receive(inFlight)
+		require.NoError(t, err)
+		require.IsType(t, &message.Supported{}, response.Body.Message)
+		return true
+	}, time.Second*5, time.Millisecond*10)
+
+	cancelFn()
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestCqlClientConnection_WarningHandler(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	type warning struct {
+		text     string
+		opCode   primitive.OpCode
+		streamId int16
+	}
+	warnings := make(chan warning, 2)
+	clt.WarningHandler = func(text string, opCode primitive.OpCode, streamId int16, conn *client.CqlClientConnection) {
+		warnings <- warning{text, opCode, streamId}
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, serverConn, err := server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	inFlight, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT"}))
+	require.NoError(t, err)
+	incoming, err := serverConn.Receive()
+	require.NoError(t, err)
+	response := frame.NewFrame(primitive.ProtocolVersion4, incoming.Header.StreamId, &message.VoidResult{})
+	response.SetWarnings([]string{"warning 1", "warning 2"})
+	err = serverConn.Send(response)
+	require.NoError(t, err)
+	_, err = clientConn.Receive(inFlight)
+	require.NoError(t, err)
+
+	for _, expected := range []string{"warning 1", "warning 2"} {
+		select {
+		case actual := <-warnings:
+			assert.Equal(t, warning{expected, primitive.OpCodeResult, incoming.Header.StreamId}, actual)
+		case <-time.After(time.Second * 5):
+			t.Fatal("warning handler was not invoked")
+		}
+	}
+
+	cancelFn()
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestCqlClientConnection_StreamIdExhaustion(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy client.StreamIdExhaustionStrategy
+	}{
+		{"fail", client.StreamIdExhaustionFail},
+		{"block", client.StreamIdExhaustionBlock},
+		{"queue", client.StreamIdExhaustionQueue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			server := client.NewCqlServer("127.0.0.1:9043", nil)
+			clt := client.NewCqlClient("127.0.0.1:9043", nil)
+			clt.MaxInFlight = 1
+			clt.StreamIdExhaustionStrategy = tt.strategy
+			clt.StreamIdWaitTimeout = 200 * time.Millisecond
+			clt.MaxOverflow = 1
+
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+
+			err := server.Start(ctx)
+			require.NoError(t, err)
+
+			clientConn, serverConn, err := server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+			require.NoError(t, err)
+
+			newRequest := func() *frame.Frame {
+				return frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{})
+			}
+			respond := func() {
+				incoming, err := serverConn.Receive()
+				require.NoError(t, err)
+				err = serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, incoming.Header.StreamId, &message.Supported{}))
+				require.NoError(t, err)
+			}
+
+			// the only stream id available is now in use
+			first, err := clientConn.Send(newRequest())
+			require.NoError(t, err)
+
+			second, err := clientConn.Send(newRequest())
+			var busy *client.BusyError
+			switch tt.strategy {
+			case client.StreamIdExhaustionFail, client.StreamIdExhaustionBlock:
+				require.Error(t, err)
+				require.True(t, errors.As(err, &busy))
+				assert.Equal(t, tt.strategy, busy.Strategy)
+				assert.Equal(t, 1, busy.MaxInFlight)
+				assert.Contains(t, err.Error(), "no stream id available")
+				if tt.strategy == client.StreamIdExhaustionBlock {
+					// a blocked request gets the stream id as soon as it is released
+					go func() {
+						time.Sleep(50 * time.Millisecond)
+						respond()
+					}()
+					second, err = clientConn.Send(newRequest())
+					require.NoError(t, err)
+					_, err = clientConn.Receive(first)
+					require.NoError(t, err)
+					first = second
+				}
+			case client.StreamIdExhaustionQueue:
+				require.NoError(t, err)
+				assert.Equal(t, client.ManagedStreamId, second.StreamId())
+				// the overflow queue is full
+				_, err = clientConn.Send(newRequest())
+				require.Error(t, err)
+				require.True(t, errors.As(err, &busy))
+				assert.Equal(t, 1, busy.MaxOverflow)
+				// the queued request is dispatched as soon as the stream id is released
+				respond()
+				_, err = clientConn.Receive(first)
+				require.NoError(t, err)
+				first = second
+			}
+
+			respond()
+			response, err := clientConn.Receive(first)
+			require.NoError(t, err)
+			assert.IsType(t, &message.Supported{}, response.Body.Message)
+			assert.Equal(t, int16(1), first.StreamId())
+
+			cancelFn()
+			assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+			assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+		})
+	}
+}
+
+func TestCqlClientConnection_StreamIdExhaustion_QueueTimeout(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.MaxInFlight = 1
+	clt.StreamIdExhaustionStrategy = client.StreamIdExhaustionQueue
+	clt.MaxOverflow = 1
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	clientConn, _, err := server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	queued, err := clientConn.SendWithTimeout(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}), 100*time.Millisecond)
+	require.NoError(t, err)
+
+	// the queued request times out before a stream id is released
+	_, err = clientConn.Receive(queued)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	// the timed out request does not occupy the overflow queue anymore
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+
+	cancelFn()
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestCqlClientConnection_CloseGracefully(t *testing.T) {
+
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	err := server.Start(ctx)
+	require.NoError(t, err)
+
+	// in-flight requests complete before the connection is closed
+	clientConn, serverConn, err := server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	inFlight, err := clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	incoming, err := serverConn.Receive()
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = serverConn.Send(frame.NewFrame(primitive.ProtocolVersion4, incoming.Header.StreamId, &message.Supported{}))
+	}()
+	assert.Error(t, clientConn.CloseGracefully(0))
+	require.NoError(t, clientConn.CloseGracefully(time.Second*5))
+	assert.True(t, clientConn.IsClosed())
+	response, err := clientConn.Receive(inFlight)
+	require.NoError(t, err)
+	assert.IsType(t, &message.Supported{}, response.Body.Message)
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	assert.True(t, errors.Is(err, client.ErrConnectionClosed))
+	require.NoError(t, clientConn.CloseGracefully(time.Second))
+
+	// requests still in flight after the timeout are completed with ErrConnectionClosed
+	clientConn, serverConn, err = server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	inFlight, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	_, err = serverConn.Receive()
+	require.NoError(t, err)
+	err = clientConn.CloseGracefully(100 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 request(s) abandoned")
+	_, err = clientConn.Receive(inFlight)
+	assert.True(t, errors.Is(err, client.ErrConnectionClosed))
+
+	// connections closed by the peer while draining are not reported as timed out
+	clientConn, serverConn, err = server.BindAndInit(clt, ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	inFlight, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	_, err = serverConn.Receive()
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = serverConn.Close()
+	}()
+	start := time.Now()
+	require.NoError(t, clientConn.CloseGracefully(time.Second*5))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second*5))
+	assert.True(t, clientConn.IsClosed())
+	_, err = clientConn.Receive(inFlight)
+	assert.True(t, errors.Is(err, client.ErrConnectionClosed))
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Murmur3Token returns the token of the given routing key, as computed by Cassandra's Murmur3Partitioner, the default
+// partitioner since Cassandra 1.2. The routing key is the encoded value of the partition key; for composite partition
+// keys, see CompositeRoutingKey. Like in Cassandra, an empty routing key maps to the minimum token, math.MinInt64.
+func Murmur3Token(routingKey []byte) int64 {
+	if len(routingKey) == 0 {
+		return math.MinInt64
+	}
+	h1 := murmur3H1(routingKey)
+	// Long.MIN_VALUE is reserved by the partitioner as the minimum token
+	if h1 == math.MinInt64 {
+		return math.MaxInt64
+	}
+	return h1
+}
+
+// CompositeRoutingKey returns the routing key of a composite partition key, given the encoded values of its
+// components, in partition key order. A partition key with a single component is its own routing key.
+func CompositeRoutingKey(components ...[]byte) []byte {
+	if len(components) == 1 {
+		return components[0]
+	}
+	buf := &bytes.Buffer{}
+	for _, component := range components {
+		_ = primitive.WriteShort(uint16(len(component)), buf)
+		buf.Write(component)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+const (
+	murmur3C1 uint64 = 0x87c37b91114253d5
+	murmur3C2 uint64 = 0x4cf5ad432745937f
+)
+
+// murmur3H1 returns the first half of the 128-bit MurmurHash3 x64 hash of the given data, with seed zero. This is a
+// port of Cassandra's MurmurHash.hash3_x64_128, including its sign extension of the tail bytes, which makes it differ
+// from the reference implementation for data whose length is not a multiple of 16.
+func murmur3H1(data []byte) int64 {
+	length := len(data)
+	var h1, h2 uint64
+	blocks := length / 16
+	for i := 0; i < blocks; i++ {
+		k1 := binary.LittleEndian.Uint64(data[i*16:])
+		k2 := binary.LittleEndian.Uint64(data[i*16+8:])
+		h1 ^= murmur3MixK1(k1)
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+		h2 ^= murmur3MixK2(k2)
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+	tail := data[blocks*16:]
+	var k1, k2 uint64
+	for i := len(tail) - 1; i >= 0; i-- {
+		// Java bytes are signed
+		b := uint64(int64(int8(tail[i])))
+		if i >= 8 {
+			k2 ^= b << (8 * (i - 8))
+		} else {
+			k1 ^= b << (8 * i)
+		}
+	}
+	if len(tail) > 8 {
+		h2 ^= murmur3MixK2(k2)
+	}
+	if len(tail) > 0 {
+		h1 ^= murmur3MixK1(k1)
+	}
+	h1 ^= uint64(length)
+	h2 ^= uint64(length)
+	h1 += h2
+	h2 += h1
+	h1 = murmur3Fmix(h1)
+	h2 = murmur3Fmix(h2)
+	h1 += h2
+	return int64(h1)
+}
+
+func murmur3MixK1(k1 uint64) uint64 {
+	k1 *= murmur3C1
+	k1 = bits.RotateLeft64(k1, 31)
+	return k1 * murmur3C2
+}
+
+func murmur3MixK2(k2 uint64) uint64 {
+	k2 *= murmur3C2
+	k2 = bits.RotateLeft64(k2, 33)
+	return k2 * murmur3C1
+}
+
+func murmur3Fmix(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
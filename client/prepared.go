@@ -0,0 +1,149 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+)
+
+// ErrUnknownPreparedId is returned by CqlClientConnection.ExecuteWithReprepare when the server replies with an
+// Unprepared error, and the prepared id is not found in the connection's PreparedRegistry: the statement cannot be
+// prepared again since its query string is unknown.
+var ErrUnknownPreparedId = errors.New("unknown prepared id")
+
+// PreparedRegistry records the PREPARE requests that succeeded, indexed by their prepared id, so that they can be
+// prepared again when a server replies to an EXECUTE request with an Unprepared error, e.g. after a restart. It is
+// preferable to create PreparedRegistry instances using the constructor function NewPreparedRegistry. It is safe for
+// concurrent use.
+type PreparedRegistry struct {
+	lock     sync.RWMutex
+	prepared map[string]*message.Prepare
+}
+
+func NewPreparedRegistry() *PreparedRegistry {
+	return &PreparedRegistry{prepared: map[string]*message.Prepare{}}
+}
+
+// Register records the given PREPARE request under the given prepared id. The request is copied, so that later
+// changes to it do not affect the registry.
+func (r *PreparedRegistry) Register(preparedId []byte, prepare *message.Prepare) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.prepared[string(preparedId)] = prepare.DeepCopy()
+}
+
+// Get returns the PREPARE request recorded under the given prepared id, if any.
+func (r *PreparedRegistry) Get(preparedId []byte) (*message.Prepare, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	prepare, found := r.prepared[string(preparedId)]
+	return prepare, found
+}
+
+// Remove discards the PREPARE request recorded under the given prepared id, if any.
+func (r *PreparedRegistry) Remove(preparedId []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.prepared, string(preparedId))
+}
+
+// Len returns the number of PREPARE requests recorded so far.
+func (r *PreparedRegistry) Len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.prepared)
+}
+
+// PreparedStatements returns the registry of statements prepared through Prepare on this connection.
+func (c *CqlClientConnection) PreparedStatements() *PreparedRegistry {
+	return c.prepared
+}
+
+// Prepare is a convenience method that sends the given PREPARE request frame and waits for its response, see
+// SendAndReceive. If the response is a Prepared RESULT, the request is recorded in the connection's
+// PreparedRegistry, which allows ExecuteWithReprepare to prepare it again if needed.
+func (c *CqlClientConnection) Prepare(f *frame.Frame) (*frame.Frame, error) {
+	if f == nil {
+		return nil, fmt.Errorf("%v: frame cannot be nil", c)
+	}
+	prepare, ok := f.Body.Message.(*message.Prepare)
+	if !ok {
+		return nil, fmt.Errorf("%v: expected PREPARE request, got: %v", c, f.Body.Message)
+	}
+	response, err := c.SendAndReceive(f)
+	if err == nil && response != nil {
+		if result, ok := response.Body.Message.(*message.PreparedResult); ok {
+			c.prepared.Register(result.PreparedQueryId, prepare)
+		}
+	}
+	return response, err
+}
+
+// ExecuteWithReprepare sends the given EXECUTE request frame and waits for its response, see SendAndReceive. If the
+// server replies with an Unprepared error, the statement is prepared again, using the PREPARE request recorded in the
+// connection's PreparedRegistry, then the EXECUTE request is retried once, with the prepared id and result metadata
+// id returned by the new PREPARE response. The final response is returned, whatever its outcome; it may still be an
+// error response, including another Unprepared error.
+// If the prepared id is not found in the registry, the Unprepared response is returned along with an error wrapping
+// ErrUnknownPreparedId. If the new PREPARE request fails, its response is returned along with an error.
+func (c *CqlClientConnection) ExecuteWithReprepare(f *frame.Frame) (*frame.Frame, error) {
+	if f == nil {
+		return nil, fmt.Errorf("%v: frame cannot be nil", c)
+	}
+	if _, ok := f.Body.Message.(*message.Execute); !ok {
+		return nil, fmt.Errorf("%v: expected EXECUTE request, got: %v", c, f.Body.Message)
+	}
+	// the stream id of the original frame is assigned upon write when using managed stream ids
+	retry := f.DeepCopy()
+	response, err := c.SendAndReceive(f)
+	if err != nil || response == nil {
+		return response, err
+	}
+	unprepared, ok := response.Body.Message.(*message.Unprepared)
+	if !ok {
+		return response, nil
+	}
+	execute := retry.Body.Message.(*message.Execute)
+	prepare, found := c.prepared.Get(unprepared.Id)
+	if !found {
+		return response, fmt.Errorf("%v: cannot prepare again %x: %w", c, unprepared.Id, ErrUnknownPreparedId)
+	}
+	log.Debug().Msgf("%v: statement %x is not prepared, preparing it again", c, unprepared.Id)
+	reprepare := frame.NewFrame(retry.Header.Version, retry.Header.StreamId, prepare.DeepCopy())
+	if response, err = c.SendAndReceive(reprepare); err != nil || response == nil {
+		return response, err
+	}
+	result, ok := response.Body.Message.(*message.PreparedResult)
+	if !ok {
+		return response, fmt.Errorf("%v: cannot prepare again %x: expected RESULT Prepared, got: %v",
+			c, unprepared.Id, response.Body.Message)
+	}
+	if string(result.PreparedQueryId) != string(unprepared.Id) {
+		c.prepared.Register(result.PreparedQueryId, prepare)
+	}
+	execute.QueryId = result.PreparedQueryId
+	if result.ResultMetadataId != nil {
+		execute.ResultMetadataId = result.ResultMetadataId
+	}
+	log.Debug().Msgf("%v: statement %x prepared again, retrying EXECUTE", c, unprepared.Id)
+	return c.SendAndReceive(retry)
+}
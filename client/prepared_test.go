@@ -0,0 +1,102 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_ExecuteWithReprepare(t *testing.T) {
+	query := "SELECT v FROM ks.t1"
+	columns := &message.RowsMetadata{
+		ColumnCount: 1,
+		Columns: []*message.ColumnMetadata{{
+			Keyspace: "ks",
+			Table:    "t1",
+			Name:     "v",
+			Index:    0,
+			Type:     datatype.Varchar,
+		}},
+	}
+	row := message.Row{message.Column("v1")}
+	handler := client.NewPreparedStatementHandler(query, &message.VariablesMetadata{}, columns,
+		func(options *message.QueryOptions) message.RowSet { return message.RowSet{row} },
+	)
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+
+	newExecute := func(id string) *frame.Frame {
+		return frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Execute{
+			QueryId: []byte(id),
+			Options: &message.QueryOptions{},
+		})
+	}
+
+	// statement unknown to the registry: cannot prepare again
+	response, err := clientConn.ExecuteWithReprepare(newExecute(query))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, client.ErrUnknownPreparedId))
+	require.NotNil(t, response)
+	assert.IsType(t, &message.Unprepared{}, response.Body.Message)
+
+	// statement known to the registry, e.g. prepared before a server restart: prepared again, then executed
+	clientConn.PreparedStatements().Register([]byte(query), &message.Prepare{Query: query})
+	response, err = clientConn.ExecuteWithReprepare(newExecute(query))
+	require.NoError(t, err)
+	require.NotNil(t, response)
+	require.IsType(t, &message.RowsResult{}, response.Body.Message)
+	assert.Equal(t, message.RowSet{row}, response.Body.Message.(*message.RowsResult).Data)
+
+	// statement already prepared: executed directly
+	response, err = clientConn.ExecuteWithReprepare(newExecute(query))
+	require.NoError(t, err)
+	assert.IsType(t, &message.RowsResult{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_Prepare(t *testing.T) {
+	query := "SELECT v FROM ks.t1"
+	handler := client.NewPreparedStatementHandler(query, &message.VariablesMetadata{}, &message.RowsMetadata{},
+		func(options *message.QueryOptions) message.RowSet { return nil },
+	)
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+
+	prepare := &message.Prepare{Query: query}
+	response, err := clientConn.Prepare(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, prepare))
+	require.NoError(t, err)
+	require.IsType(t, &message.PreparedResult{}, response.Body.Message)
+	recorded, found := clientConn.PreparedStatements().Get([]byte(query))
+	require.True(t, found)
+	assert.Equal(t, prepare, recorded)
+	assert.Equal(t, 1, clientConn.PreparedStatements().Len())
+
+	_, err = clientConn.Prepare(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected PREPARE request")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// RateLimitScope determines how requests share token buckets, see RateLimitPolicy.
+type RateLimitScope int
+
+const (
+	// RateLimitPerConnection limits the rate of requests on each connection, regardless of their opcode. This is the
+	// default scope.
+	RateLimitPerConnection = RateLimitScope(iota)
+	// RateLimitPerOpCode limits the rate of requests of each opcode, across all the connections of the server.
+	RateLimitPerOpCode
+	// RateLimitPerConnectionAndOpCode limits the rate of requests of each opcode on each connection.
+	RateLimitPerConnectionAndOpCode
+)
+
+func (s RateLimitScope) String() string {
+	switch s {
+	case RateLimitPerConnection:
+		return "RateLimitScope CONNECTION"
+	case RateLimitPerOpCode:
+		return "RateLimitScope OPCODE"
+	case RateLimitPerConnectionAndOpCode:
+		return "RateLimitScope CONNECTION_AND_OPCODE"
+	}
+	return fmt.Sprintf("RateLimitScope ? [%d]", int(s))
+}
+
+// RateLimitPolicy describes how a rate limiting handler limits incoming requests, see NewRateLimitHandler. Requests
+// are limited with token buckets: each bucket holds up to Burst tokens, and is refilled at Rate tokens per second;
+// each request takes one token from its bucket, and is rejected if the bucket is empty.
+type RateLimitPolicy struct {
+	// Rate is the number of requests per second allowed for each bucket. If zero or negative, no request is limited.
+	Rate float64
+	// Burst is the maximum number of requests allowed at once for each bucket. If zero or negative, the integer part
+	// of Rate is used instead, or 1 if Rate is lesser than 1.
+	Burst int
+	// Scope determines how requests share token buckets.
+	Scope RateLimitScope
+	// Filter optionally selects the requests subject to rate limiting. If nil, DefaultPolicyFilter is used, so that
+	// connections can still be established and initialized.
+	Filter func(request *frame.Frame) bool
+	// OnRejected is an optional callback invoked for each request rejected because its rate limit was exceeded.
+	OnRejected func(request *frame.Frame, conn *CqlServerConnection)
+}
+
+// isSubject returns true if the given request is subject to rate limiting.
+func (p *RateLimitPolicy) isSubject(request *frame.Frame) bool {
+	if p.Filter != nil {
+		return p.Filter(request)
+	}
+	return DefaultPolicyFilter(request.Header)
+}
+
+func (p *RateLimitPolicy) burst() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return math.Max(1, math.Floor(p.Rate))
+}
+
+// rateLimitKey identifies a token bucket; its fields are zero when they are not relevant to the scope.
+type rateLimitKey struct {
+	conn   *CqlServerConnection
+	opCode primitive.OpCode
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket according to the time elapsed since the last refill, then takes a token from it, if any.
+func (b *tokenBucket) take(now time.Time, rate float64, burst float64) bool {
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type rateLimiter struct {
+	policy  *RateLimitPolicy
+	lock    sync.Mutex
+	buckets map[rateLimitKey]*tokenBucket
+}
+
+func (l *rateLimiter) key(request *frame.Frame, conn *CqlServerConnection) rateLimitKey {
+	switch l.policy.Scope {
+	case RateLimitPerOpCode:
+		return rateLimitKey{opCode: request.Header.OpCode}
+	case RateLimitPerConnectionAndOpCode:
+		return rateLimitKey{conn: conn, opCode: request.Header.OpCode}
+	}
+	return rateLimitKey{conn: conn}
+}
+
+// allow returns true if the given request is within its rate limit.
+func (l *rateLimiter) allow(request *frame.Frame, conn *CqlServerConnection) bool {
+	key := l.key(request, conn)
+	now := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	bucket, found := l.buckets[key]
+	if !found {
+		l.pruneClosedConnections()
+		bucket = &tokenBucket{tokens: l.policy.burst(), last: now}
+		l.buckets[key] = bucket
+	}
+	return bucket.take(now, l.policy.Rate, l.policy.burst())
+}
+
+// pruneClosedConnections discards the buckets of closed connections. Must be called while holding the lock.
+func (l *rateLimiter) pruneClosedConnections() {
+	for key := range l.buckets {
+		if key.conn != nil && key.conn.IsClosed() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// NewRateLimitHandler creates a new RequestHandler that limits the rate of incoming requests according to the given
+// policy, to protect upstream servers, e.g. behind a proxy. Requests exceeding their rate limit are answered with a
+// synthesized Overloaded error response; other requests are not handled, so that the next registered handlers can
+// handle them: the rate limiting handler should thus be registered first.
+func NewRateLimitHandler(policy *RateLimitPolicy) RequestHandler {
+	limiter := &rateLimiter{policy: policy, buckets: map[rateLimitKey]*tokenBucket{}}
+	return func(request *frame.Frame, conn *CqlServerConnection, _ RequestHandlerContext) (response *frame.Frame) {
+		if policy.Rate <= 0 || !policy.isSubject(request) || limiter.allow(request, conn) {
+			return nil
+		}
+		log.Debug().Msgf("%v: [rate limit handler]: rate limit exceeded for request: %v", conn, request)
+		if policy.OnRejected != nil {
+			policy.OnRejected(request, conn)
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{
+			ErrorMessage: fmt.Sprintf("Rate limit exceeded: %v requests per second", policy.Rate),
+		})
+	}
+}
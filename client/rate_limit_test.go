@@ -0,0 +1,96 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewRateLimitHandler(t *testing.T) {
+	query := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM ks.t"})
+	prepare := frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Prepare{Query: "SELECT * FROM ks.t"})
+	options := frame.NewFrame(primitive.ProtocolVersion4, 3, &message.Options{})
+
+	var rejected []*frame.Frame
+	policy := &client.RateLimitPolicy{
+		Rate:  10,
+		Burst: 2,
+		OnRejected: func(request *frame.Frame, _ *client.CqlServerConnection) {
+			rejected = append(rejected, request)
+		},
+	}
+	handler := client.NewRateLimitHandler(policy)
+	assert.Nil(t, handler(query, nil, nil))
+	assert.Nil(t, handler(prepare, nil, nil))
+	response := handler(query, nil, nil)
+	require.NotNil(t, response)
+	assert.Equal(t, query.Header.StreamId, response.Header.StreamId)
+	assert.Equal(t, &message.Overloaded{ErrorMessage: "Rate limit exceeded: 10 requests per second"}, response.Body.Message)
+	assert.Equal(t, []*frame.Frame{query}, rejected)
+	// not subject by default
+	assert.Nil(t, handler(options, nil, nil))
+	// the bucket is refilled over time
+	time.Sleep(150 * time.Millisecond)
+	assert.Nil(t, handler(query, nil, nil))
+	assert.NotNil(t, handler(query, nil, nil))
+
+	handler = client.NewRateLimitHandler(&client.RateLimitPolicy{Rate: 0.001, Scope: client.RateLimitPerOpCode})
+	assert.Nil(t, handler(query, nil, nil))
+	assert.Nil(t, handler(prepare, nil, nil))
+	assert.NotNil(t, handler(query, nil, nil))
+	assert.NotNil(t, handler(prepare, nil, nil))
+
+	handler = client.NewRateLimitHandler(&client.RateLimitPolicy{
+		Rate:   0.001,
+		Filter: func(request *frame.Frame) bool { return request.Header.OpCode == primitive.OpCodeOptions },
+	})
+	assert.Nil(t, handler(options, nil, nil))
+	assert.NotNil(t, handler(options, nil, nil))
+	assert.Nil(t, handler(query, nil, nil))
+
+	handler = client.NewRateLimitHandler(&client.RateLimitPolicy{})
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, handler(query, nil, nil))
+	}
+}
+
+func TestNewRateLimitHandler_Server(t *testing.T) {
+	handler := client.NewRateLimitHandler(&client.RateLimitPolicy{Rate: 0.001, Scope: client.RateLimitPerConnectionAndOpCode})
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler, client.HeartbeatHandler, voidResultHandler}, nil)
+	defer cancelFn()
+
+	query := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "INSERT"})
+	response, err := clientConn.SendAndReceive(query)
+	require.NoError(t, err)
+	assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "INSERT"}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Overloaded{}, response.Body.Message)
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Prepare{Query: "INSERT"}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+)
+
+// ReadLoopMode determines which goroutine reads incoming frames from a CqlClientConnection.
+type ReadLoopMode int
+
+const (
+	// ReadLoopOwned is the default mode: the connection owns a goroutine that continuously reads incoming frames, and
+	// delivers them to in-flight requests, event handlers and the event channel.
+	ReadLoopOwned = ReadLoopMode(iota)
+	// ReadLoopPull leaves it to the user to read incoming frames, by calling CqlClientConnection.NextFrame, e.g. from a
+	// single-threaded event loop: no read goroutine is started. Frames read by NextFrame are still delivered to
+	// in-flight requests and event handlers, but event frames are not delivered to the event channel. Note that
+	// methods waiting for responses, such as SendAndReceive or ConnectAndInit, block until another goroutine reads the
+	// responses with NextFrame; requests whose response is not read in time time out as usual.
+	ReadLoopPull
+)
+
+func (m ReadLoopMode) String() string {
+	switch m {
+	case ReadLoopOwned:
+		return "ReadLoopMode OWNED"
+	case ReadLoopPull:
+		return "ReadLoopMode PULL"
+	}
+	return fmt.Sprintf("ReadLoopMode ? [%d]", int(m))
+}
+
+// NextFrame reads and returns the next incoming frame, either a response or an event, on the caller goroutine; the
+// connection must use ReadLoopPull. The given context can be used to stop waiting for the next frame; it is only
+// honored until the frame starts being received, so that the connection remains usable after the context is done.
+// NextFrame must not be called concurrently.
+func (c *CqlClientConnection) NextFrame(ctx context.Context) (*frame.Frame, error) {
+	if c.readLoopMode != ReadLoopPull {
+		return nil, fmt.Errorf("%v: NextFrame requires %v, got %v", c, ReadLoopPull, c.readLoopMode)
+	}
+	for len(c.pulled) == 0 {
+		if c.IsClosed() {
+			return nil, fmt.Errorf("%v: %w", c, ErrConnectionClosed)
+		} else if err := c.pullIncomingData(ctx); err != nil {
+			return nil, err
+		}
+	}
+	next := c.pulled[0]
+	c.pulled[0] = nil
+	c.pulled = c.pulled[1:]
+	return next, nil
+}
+
+// pullIncomingData reads the next frame or segment from the connection, and processes the frames it contains.
+func (c *CqlClientConnection) pullIncomingData(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			// interrupt the pending read
+			_ = c.conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	source, err := c.waitForIncomingData()
+	close(done)
+	<-exited
+	_ = c.conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil && !c.IsClosed() {
+			// no data was consumed, the connection remains usable
+			return ctxErr
+		}
+		c.reportConnectionFailure(err, true)
+		c.abort()
+		return fmt.Errorf("%v: cannot read incoming frame: %w", c, err)
+	}
+	var abort bool
+	if c.modernLayout {
+		abort = c.readSegment(source)
+	} else {
+		abort = c.readFrame(source)
+	}
+	if abort {
+		c.abort()
+		if len(c.pulled) == 0 {
+			return fmt.Errorf("%v: %w", c, ErrConnectionClosed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_NextFrame(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.ReadLoopMode = client.ReadLoopPull
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	startup, err := clientConn.NewStartupRequest(primitive.ProtocolVersion4, 1)
+	require.NoError(t, err)
+	ch, err := clientConn.Send(startup)
+	require.NoError(t, err)
+	incoming, err := clientConn.NextFrame(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int16(1), incoming.Header.StreamId)
+	assert.IsType(t, &message.Ready{}, incoming.Body.Message)
+	// responses read with NextFrame are also delivered to in-flight requests
+	response, err := clientConn.Receive(ch)
+	require.NoError(t, err)
+	assert.Equal(t, incoming, response)
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer timeoutCancel()
+	_, err = clientConn.NextFrame(timeoutCtx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the connection remains usable after the context is done
+	_, err = clientConn.Send(frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Options{}))
+	require.NoError(t, err)
+	incoming, err = clientConn.NextFrame(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int16(2), incoming.Header.StreamId)
+
+	owned, err := client.NewCqlClient("127.0.0.1:9043", nil).Connect(ctx)
+	require.NoError(t, err)
+	_, err = owned.NextFrame(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NextFrame requires ReadLoopMode PULL")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,226 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// FrameDirection is the direction of a frame recorded by a FrameRecorder, from the point of view of the recorded
+// connection.
+type FrameDirection string
+
+const (
+	FrameSent     = FrameDirection("sent")
+	FrameReceived = FrameDirection("received")
+)
+
+// RecordedFrame is a frame recorded by a FrameRecorder. Only the frame header information is kept, unless the
+// recorder keeps frames, see FrameRecorder.KeepFrames.
+type RecordedFrame struct {
+	Direction FrameDirection
+	Version   primitive.ProtocolVersion
+	OpCode    primitive.OpCode
+	StreamId  int16
+	// Connection is the string representation of the connection the frame was exchanged on.
+	Connection string
+	// Time is the time the frame was recorded at.
+	Time time.Time
+	// Frame is a copy of the recorded frame, or nil if the recorder does not keep frames.
+	Frame *frame.Frame
+	// Compression is the compression algorithm negotiated on the connection the frame was exchanged on, if known.
+	Compression primitive.Compression
+}
+
+func (f *RecordedFrame) String() string {
+	arrow := "->"
+	if f.Direction == FrameReceived {
+		arrow = "<-"
+	}
+	return fmt.Sprintf("%v %v [stream id: %v]", arrow, opCodeName(f.OpCode), f.StreamId)
+}
+
+// FrameRecorder records the ordered sequence of frames exchanged on connections, and offers assertions on that
+// sequence, e.g. to verify in integration tests that a tool built on top of this library sends PREPARE before
+// EXECUTE. Set it on CqlClient.Recorder to record the frames sent and received by client connections, or on
+// CqlServer.Recorder to record the frames sent and received by server connections; when a recorder is shared by
+// several connections, their frames are interleaved in the order they were exchanged. Frames sent are recorded when
+// they are written to the connection, and frames received when they are decoded. It is preferable to create
+// FrameRecorder instances using the constructor function NewFrameRecorder. It is safe for concurrent use.
+type FrameRecorder struct {
+	// KeepFrames, if true, makes the recorder keep a copy of each frame exchanged on connections, see
+	// RecordedFrame.Frame; the recorded frames of a client connection can then be replayed, see package replay.
+	// Defaults to false, since keeping frames is costly.
+	KeepFrames bool
+	lock       sync.Mutex
+	frames     []*RecordedFrame
+}
+
+func NewFrameRecorder() *FrameRecorder {
+	return &FrameRecorder{}
+}
+
+// Record records a frame with the given header.
+func (r *FrameRecorder) Record(direction FrameDirection, header *frame.Header, connection fmt.Stringer) {
+	r.record(direction, header, nil, "", connection)
+}
+
+// RecordFrame records the given frame, exchanged on a connection using the given compression algorithm. If this
+// recorder keeps frames, a copy of the frame is kept; note that the contents of a stream value can only be copied
+// before the value is written, see primitive.NewStreamValue.
+func (r *FrameRecorder) RecordFrame(
+	direction FrameDirection,
+	f *frame.Frame,
+	compression primitive.Compression,
+	connection fmt.Stringer,
+) {
+	r.record(direction, f.Header, r.keep(f), compression, connection)
+}
+
+// keep returns a copy of the given frame if this recorder keeps frames, or nil otherwise. Connections copy the frames
+// they send before encoding them, and record the copy once the frame was successfully written.
+func (r *FrameRecorder) keep(f *frame.Frame) *frame.Frame {
+	if !r.KeepFrames {
+		return nil
+	}
+	return f.DeepCopy()
+}
+
+func (r *FrameRecorder) record(
+	direction FrameDirection,
+	header *frame.Header,
+	kept *frame.Frame,
+	compression primitive.Compression,
+	connection fmt.Stringer,
+) {
+	recorded := &RecordedFrame{
+		Direction:   direction,
+		Version:     header.Version,
+		OpCode:      header.OpCode,
+		StreamId:    header.StreamId,
+		Time:        time.Now(),
+		Frame:       kept,
+		Compression: compression,
+	}
+	if kept != nil {
+		// the header of the kept frame was copied before encoding: report its final state
+		*kept.Header = *header
+	}
+	if connection != nil {
+		recorded.Connection = connection.String()
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.frames = append(r.frames, recorded)
+}
+
+// Frames returns the frames recorded so far, in the order they were recorded.
+func (r *FrameRecorder) Frames() []*RecordedFrame {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]*RecordedFrame(nil), r.frames...)
+}
+
+// OpCodes returns the opcodes of the frames recorded so far, in the order they were recorded.
+func (r *FrameRecorder) OpCodes() []primitive.OpCode {
+	frames := r.Frames()
+	opCodes := make([]primitive.OpCode, len(frames))
+	for i, f := range frames {
+		opCodes[i] = f.OpCode
+	}
+	return opCodes
+}
+
+// Reset discards the frames recorded so far.
+func (r *FrameRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.frames = nil
+}
+
+// String returns the recorded sequence, one frame per line.
+func (r *FrameRecorder) String() string {
+	return formatRecordedFrames(r.Frames())
+}
+
+// AssertOpCodes returns an error if the opcodes of the frames recorded so far are not exactly the given ones, in the
+// given order.
+func (r *FrameRecorder) AssertOpCodes(expected ...primitive.OpCode) error {
+	frames := r.Frames()
+	matches := len(frames) == len(expected)
+	for i := 0; matches && i < len(frames); i++ {
+		matches = frames[i].OpCode == expected[i]
+	}
+	if !matches {
+		names := make([]string, len(expected))
+		for i, opCode := range expected {
+			names[i] = opCodeName(opCode)
+		}
+		return fmt.Errorf("expected opcodes [%v], got:\n%v", strings.Join(names, " "), formatRecordedFrames(frames))
+	}
+	return nil
+}
+
+// AssertPrecedes returns an error if a frame with opcode second was recorded without any frame with opcode first
+// recorded before it, e.g. AssertPrecedes(primitive.OpCodePrepare, primitive.OpCodeExecute) fails if an EXECUTE was
+// sent before any PREPARE. It also returns an error if no frame with opcode second was recorded at all.
+func (r *FrameRecorder) AssertPrecedes(first primitive.OpCode, second primitive.OpCode) error {
+	frames := r.Frames()
+	found := false
+	for i, f := range frames {
+		if f.OpCode == first {
+			found = true
+		} else if f.OpCode == second {
+			if !found {
+				return fmt.Errorf("expected %v before %v, but %v recorded at index %d without preceding %v:\n%v",
+					opCodeName(first), opCodeName(second), opCodeName(second), i, opCodeName(first), formatRecordedFrames(frames))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %v before %v, but no %v recorded:\n%v",
+		opCodeName(first), opCodeName(second), opCodeName(second), formatRecordedFrames(frames))
+}
+
+// AssertNoneBefore returns an error if a frame with the given opcode was recorded before the first frame with opcode
+// before, e.g. AssertNoneBefore(primitive.OpCodeQuery, primitive.OpCodeReady) fails if a QUERY was sent before the
+// server replied READY. If no frame with opcode before was recorded, no frame with the given opcode may be recorded at
+// all.
+func (r *FrameRecorder) AssertNoneBefore(opCode primitive.OpCode, before primitive.OpCode) error {
+	frames := r.Frames()
+	for i, f := range frames {
+		if f.OpCode == before {
+			return nil
+		} else if f.OpCode == opCode {
+			return fmt.Errorf("expected no %v before %v, but %v recorded at index %d:\n%v",
+				opCodeName(opCode), opCodeName(before), opCodeName(opCode), i, formatRecordedFrames(frames))
+		}
+	}
+	return nil
+}
+
+func formatRecordedFrames(frames []*RecordedFrame) string {
+	lines := make([]string, len(frames))
+	for i, f := range frames {
+		lines[i] = fmt.Sprintf("%d: %v", i, f)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFrameRecorder_Assertions(t *testing.T) {
+	recorder := client.NewFrameRecorder()
+	record := func(direction client.FrameDirection, opCode primitive.OpCode, streamId int16) {
+		recorder.Record(direction, &frame.Header{Version: primitive.ProtocolVersion4, OpCode: opCode, StreamId: streamId}, nil)
+	}
+	record(client.FrameSent, primitive.OpCodeStartup, 1)
+	record(client.FrameReceived, primitive.OpCodeReady, 1)
+	record(client.FrameSent, primitive.OpCodeQuery, 2)
+	record(client.FrameReceived, primitive.OpCodeResult, 2)
+	record(client.FrameSent, primitive.OpCodeExecute, 3)
+	record(client.FrameReceived, primitive.OpCodeError, 3)
+	record(client.FrameSent, primitive.OpCodePrepare, 4)
+
+	assert.Equal(t, []primitive.OpCode{
+		primitive.OpCodeStartup,
+		primitive.OpCodeReady,
+		primitive.OpCodeQuery,
+		primitive.OpCodeResult,
+		primitive.OpCodeExecute,
+		primitive.OpCodeError,
+		primitive.OpCodePrepare,
+	}, recorder.OpCodes())
+	assert.Equal(t, "-> QUERY [stream id: 2]", recorder.Frames()[2].String())
+	assert.Equal(t, "<- RESULT [stream id: 2]", recorder.Frames()[3].String())
+
+	assert.NoError(t, recorder.AssertOpCodes(
+		primitive.OpCodeStartup,
+		primitive.OpCodeReady,
+		primitive.OpCodeQuery,
+		primitive.OpCodeResult,
+		primitive.OpCodeExecute,
+		primitive.OpCodeError,
+		primitive.OpCodePrepare,
+	))
+	err := recorder.AssertOpCodes(primitive.OpCodeStartup, primitive.OpCodeReady)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected opcodes [STARTUP READY], got:\n0: -> STARTUP [stream id: 1]")
+
+	assert.NoError(t, recorder.AssertPrecedes(primitive.OpCodeStartup, primitive.OpCodeQuery))
+	err = recorder.AssertPrecedes(primitive.OpCodePrepare, primitive.OpCodeExecute)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected PREPARE before EXECUTE, but EXECUTE recorded at index 4 without preceding PREPARE")
+	err = recorder.AssertPrecedes(primitive.OpCodeStartup, primitive.OpCodeBatch)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected STARTUP before BATCH, but no BATCH recorded")
+
+	assert.NoError(t, recorder.AssertNoneBefore(primitive.OpCodeQuery, primitive.OpCodeReady))
+	assert.NoError(t, recorder.AssertNoneBefore(primitive.OpCodeBatch, primitive.OpCodeRegister))
+	err = recorder.AssertNoneBefore(primitive.OpCodeExecute, primitive.OpCodePrepare)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected no EXECUTE before PREPARE, but EXECUTE recorded at index 4")
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Frames())
+}
+
+func TestFrameRecorder_Connections(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	server.Recorder = client.NewFrameRecorder()
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Recorder = client.NewFrameRecorder()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, 1)
+	require.NoError(t, err)
+	_, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 2, &message.Options{}))
+	require.NoError(t, err)
+
+	expected := []primitive.OpCode{primitive.OpCodeStartup, primitive.OpCodeReady, primitive.OpCodeOptions, primitive.OpCodeSupported}
+	assert.NoError(t, clt.Recorder.AssertOpCodes(expected...))
+	assert.Eventually(t, func() bool { return server.Recorder.AssertOpCodes(expected...) == nil }, time.Second*10, time.Millisecond*10)
+	assert.NoError(t, clt.Recorder.AssertNoneBefore(primitive.OpCodeOptions, primitive.OpCodeReady))
+
+	frames := clt.Recorder.Frames()
+	assert.Equal(t, client.FrameSent, frames[2].Direction)
+	assert.Equal(t, int16(2), frames[2].StreamId)
+	assert.Equal(t, client.FrameReceived, frames[3].Direction)
+	assert.Equal(t, clientConn.String(), frames[3].Connection)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
@@ -0,0 +1,258 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ResultCache caches Rows responses to QUERY and EXECUTE requests, as a building block for caching proxies; see
+// NewResultCacheHandler. Entries are keyed by the request fingerprint (see message.NewFingerprint) refined with the
+// keyspace of the statement, the exact query string or prepared id, the protocol version, and the request options,
+// including bound values, page size and paging state: each page of a result set is thus cached separately, and
+// responses are only served to requests of the same protocol version, which expect the same result metadata. The
+// default timestamp of the request is ignored. Entries expire after a configurable TTL, and the least recently used
+// entries are evicted when the cache exceeds its maximum number of entries or its maximum size. It is preferable to
+// create ResultCache instances using the constructor function NewResultCache. It is safe for concurrent use.
+// Only the results of SELECT statements are cached: the Rows results of other statements, e.g. the "[applied]" result
+// of conditional updates, are never cached, so that such statements always reach the server. EXECUTE requests are
+// only cached if the cache knows that their prepared statement is a SELECT, see Put.
+// Note that the cache is never invalidated by writes: caching proxies must accept serving results that are stale by
+// up to the TTL.
+type ResultCache struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	codec      frame.RawCodec
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	// selects holds the prepared ids of the SELECT statements prepared so far.
+	selects map[string]bool
+	lru     *list.List
+	bytes   int64
+	hits    uint64
+	misses  uint64
+}
+
+type resultCacheEntry struct {
+	key       string
+	response  *frame.Frame
+	length    int64
+	expiresAt time.Time
+}
+
+// NewResultCache creates a new ResultCache. The TTL must be strictly positive; maxEntries and maxBytes are the
+// maximum number of cached responses and their maximum total encoded length, in bytes: if zero or negative, the
+// corresponding limit is disabled.
+func NewResultCache(ttl time.Duration, maxEntries int, maxBytes int64) *ResultCache {
+	return &ResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		codec:      frame.NewRawCodec(),
+		entries:    map[string]*list.Element{},
+		selects:    map[string]bool{},
+		lru:        list.New(),
+	}
+}
+
+// resultCacheKey returns the cache key of the given request, or false if the request cannot be cached: requests other
+// than QUERY and EXECUTE, statements other than SELECT statements, requests with tracing or a custom payload, requests
+// with named values or continuous paging options, and QUERY requests whose keyspace cannot be determined, neither from
+// the query string nor from the request options. The latter depend on the session keyspace set with USE, which varies
+// across connections. Prepared ids already account for the keyspace the statement was prepared in.
+func (c *ResultCache) resultCacheKey(request *frame.Frame) (string, bool) {
+	if request.Header.IsTracing() || request.Header.HasCustomPayload() {
+		return "", false
+	}
+	var options *message.QueryOptions
+	key := &bytes.Buffer{}
+	key.WriteString(message.NewFingerprint(request.Body.Message, nil).Key())
+	key.WriteByte(0)
+	key.WriteByte(uint8(request.Header.Version))
+	switch msg := request.Body.Message.(type) {
+	case *message.Query:
+		target, found := message.NewStatementTarget(msg, nil)
+		if !found || target.Keyspace == "" || !message.IsSelectStatement(msg.Query) {
+			return "", false
+		}
+		_ = primitive.WriteString(target.Keyspace, key)
+		_ = primitive.WriteLongString(msg.Query, key)
+		options = msg.Options
+	case *message.Execute:
+		if !c.isSelect(msg.QueryId) {
+			return "", false
+		}
+		_ = primitive.WriteShortBytes(msg.QueryId, key)
+		_ = primitive.WriteShortBytes(msg.ResultMetadataId, key)
+		options = msg.Options
+	default:
+		return "", false
+	}
+	if options != nil {
+		if len(options.NamedValues) > 0 || options.ContinuousPagingOptions != nil {
+			return "", false
+		}
+		withoutTimestamp := *options
+		withoutTimestamp.DefaultTimestamp = nil
+		options = &withoutTimestamp
+	}
+	if err := message.EncodeQueryOptions(options, key, request.Header.Version); err != nil {
+		return "", false
+	}
+	return key.String(), true
+}
+
+// Get returns the cached response to the given request, if any. The returned frame is a copy, with the stream id of
+// the request.
+func (c *ResultCache) Get(request *frame.Frame) (*frame.Frame, bool) {
+	key, ok := c.resultCacheKey(request)
+	if !ok {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, found := c.entries[key]
+	if found && time.Now().After(element.Value.(*resultCacheEntry).expiresAt) {
+		c.remove(element)
+		found = false
+	}
+	if !found {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(element)
+	response := element.Value.(*resultCacheEntry).response.DeepCopy()
+	response.Header.StreamId = request.Header.StreamId
+	return response, true
+}
+
+// Put caches the given response to the given request, if the request can be cached, and if the response is a Rows
+// result that fits the cache size limit. It returns true if the response was cached. Prepared results to PREPARE
+// requests for SELECT statements are not cached, but their prepared ids are recorded: EXECUTE requests for these ids
+// can then be cached.
+func (c *ResultCache) Put(request *frame.Frame, response *frame.Frame) bool {
+	if prepared, ok := response.Body.Message.(*message.PreparedResult); ok {
+		if prepare, ok := request.Body.Message.(*message.Prepare); ok && message.IsSelectStatement(prepare.Query) {
+			c.lock.Lock()
+			c.selects[string(prepared.PreparedQueryId)] = true
+			c.lock.Unlock()
+		}
+		return false
+	}
+	if _, ok := response.Body.Message.(*message.RowsResult); !ok || response.Body.TracingId != nil {
+		return false
+	}
+	key, ok := c.resultCacheKey(request)
+	if !ok {
+		return false
+	}
+	response = response.DeepCopy()
+	response.SetCompress(false)
+	length, err := c.codec.EncodedFrameLength(response)
+	if err != nil || (c.maxBytes > 0 && int64(length) > c.maxBytes) {
+		return false
+	}
+	entry := &resultCacheEntry{key: key, response: response, length: int64(length), expiresAt: time.Now().Add(c.ttl)}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.bytes += entry.length
+	for (c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+	}
+	return true
+}
+
+func (c *ResultCache) isSelect(preparedId []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.selects[string(preparedId)]
+}
+
+// remove removes the given element. Must be called while holding the lock.
+func (c *ResultCache) remove(element *list.Element) {
+	entry := c.lru.Remove(element).(*resultCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.length
+}
+
+// Clear removes all cached responses.
+func (c *ResultCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// Len returns the number of cached responses, including expired ones not yet evicted.
+func (c *ResultCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// Bytes returns the total encoded length of the cached responses.
+func (c *ResultCache) Bytes() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.bytes
+}
+
+// Hits returns the number of requests served from the cache so far.
+func (c *ResultCache) Hits() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits
+}
+
+// Misses returns the number of cacheable requests that were not found in the cache so far.
+func (c *ResultCache) Misses() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.misses
+}
+
+// NewResultCacheHandler creates a new RequestHandler that serves Rows responses from the given cache, and caches the
+// Rows responses produced by the given handler otherwise. Requests that cannot be cached are delegated to the handler.
+// Since the cache handler only caches the responses of the handler it wraps, it should wrap all the request handlers
+// able to produce Rows responses, e.g. with NewCompositeRequestHandler.
+func NewResultCacheHandler(cache *ResultCache, handler RequestHandler) RequestHandler {
+	return func(request *frame.Frame, conn *CqlServerConnection, ctx RequestHandlerContext) (response *frame.Frame) {
+		if cached, found := cache.Get(request); found {
+			log.Debug().Msgf("%v: [result cache handler]: serving cached response for request: %v", conn, request)
+			return cached
+		}
+		if response = handler(request, conn, ctx); response != nil && cache.Put(request, response) {
+			log.Debug().Msgf("%v: [result cache handler]: cached response for request: %v", conn, request)
+		}
+		return response
+	}
+}
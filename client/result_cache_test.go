@@ -0,0 +1,160 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewResultCacheHandler(t *testing.T) {
+	invocations := 0
+	handler := func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		invocations++
+		if query, ok := request.Body.Message.(*message.Query); ok && query.Query == "INSERT" {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+		} else if prepare, ok := request.Body.Message.(*message.Prepare); ok {
+			return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.PreparedResult{
+				PreparedQueryId: []byte(prepare.Query[:1]),
+			})
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.RowsResult{
+			Metadata: &message.RowsMetadata{
+				ColumnCount: 1,
+				Columns:     []*message.ColumnMetadata{{Keyspace: "ks", Table: "t", Name: "v", Type: datatype.Int}},
+			},
+			Data: message.RowSet{{{0, 0, 0, byte(invocations)}}},
+		})
+	}
+	newQuery := func(streamId int16, query string, options *message.QueryOptions) *frame.Frame {
+		return frame.NewFrame(primitive.ProtocolVersion4, streamId, &message.Query{Query: query, Options: options})
+	}
+	timestamp := int64(123)
+	cache := client.NewResultCache(time.Hour, 0, 0)
+	cached := client.NewResultCacheHandler(cache, handler)
+
+	first := cached(newQuery(1, "SELECT v FROM ks.t WHERE k = 1", nil), nil, nil)
+	require.IsType(t, &message.RowsResult{}, first.Body.Message)
+	second := cached(newQuery(2, "SELECT v FROM ks.t WHERE k = 1", &message.QueryOptions{DefaultTimestamp: &timestamp}), nil, nil)
+	assert.Equal(t, 1, invocations)
+	assert.Equal(t, int16(2), second.Header.StreamId)
+	assert.Equal(t, first.Body.Message, second.Body.Message)
+	assert.Equal(t, uint64(1), cache.Hits())
+	assert.Equal(t, uint64(1), cache.Misses())
+	assert.Equal(t, 1, cache.Len())
+	assert.Positive(t, cache.Bytes())
+
+	// different literals, paging states and protocol versions are cached separately
+	cached(newQuery(3, "SELECT v FROM ks.t WHERE k = 2", nil), nil, nil)
+	cached(newQuery(4, "SELECT v FROM ks.t WHERE k = 1", &message.QueryOptions{PagingState: []byte{0xca, 0xfe}}), nil, nil)
+	cached(frame.NewFrame(primitive.ProtocolVersion5, 5, &message.Query{Query: "SELECT v FROM ks.t WHERE k = 1"}), nil, nil)
+	assert.Equal(t, 4, invocations)
+	assert.Equal(t, 4, cache.Len())
+
+	// requests that cannot be cached, and responses that are not rows, are never cached
+	traced := newQuery(6, "SELECT v FROM ks.t WHERE k = 1", nil)
+	traced.RequestTracingId(true)
+	cached(traced, nil, nil)
+	named := &message.QueryOptions{NamedValues: map[string]*primitive.Value{"k": primitive.NewValue([]byte{1})}}
+	cached(newQuery(7, "SELECT v FROM ks.t WHERE k = :k", named), nil, nil)
+	cached(newQuery(7, "SELECT v FROM ks.t WHERE k = :k", named), nil, nil)
+	cached(newQuery(8, "INSERT", nil), nil, nil)
+	cached(newQuery(8, "INSERT", nil), nil, nil)
+	assert.Equal(t, 9, invocations)
+	assert.Equal(t, 4, cache.Len())
+
+	// conditional updates return rows, but are never cached
+	lwt := "UPDATE ks.t SET v = 1 WHERE k = 1 IF v = 0"
+	cached(newQuery(9, lwt, nil), nil, nil)
+	cached(newQuery(9, lwt, nil), nil, nil)
+	assert.Equal(t, 11, invocations)
+	assert.Equal(t, 4, cache.Len())
+
+	// prepared statements are only cached once known to be SELECT statements
+	execute := frame.NewFrame(primitive.ProtocolVersion4, 9, &message.Execute{QueryId: []byte("S"), Options: &message.QueryOptions{}})
+	cached(execute, nil, nil)
+	cached(execute, nil, nil)
+	assert.Equal(t, 13, invocations)
+	cached(frame.NewFrame(primitive.ProtocolVersion4, 9, &message.Prepare{Query: "SELECT v FROM ks.t WHERE k = ?"}), nil, nil)
+	cached(execute, nil, nil)
+	cached(execute, nil, nil)
+	assert.Equal(t, 15, invocations)
+	preparedLwt := frame.NewFrame(primitive.ProtocolVersion4, 9, &message.Execute{QueryId: []byte("U"), Options: &message.QueryOptions{}})
+	cached(frame.NewFrame(primitive.ProtocolVersion4, 9, &message.Prepare{Query: lwt}), nil, nil)
+	cached(preparedLwt, nil, nil)
+	cached(preparedLwt, nil, nil)
+	assert.Equal(t, 18, invocations)
+
+	// unqualified queries are only cached if the keyspace is set in the request options
+	unqualified := "SELECT v FROM t WHERE k = 1"
+	cached(newQuery(10, unqualified, nil), nil, nil)
+	cached(newQuery(10, unqualified, nil), nil, nil)
+	assert.Equal(t, 20, invocations)
+	ks1 := cached(newQuery(11, unqualified, &message.QueryOptions{Keyspace: "ks1"}), nil, nil)
+	ks2 := cached(newQuery(12, unqualified, &message.QueryOptions{Keyspace: "ks2"}), nil, nil)
+	assert.NotEqual(t, ks1.Body.Message, ks2.Body.Message)
+	assert.Equal(t, ks2.Body.Message, cached(newQuery(13, unqualified, &message.QueryOptions{Keyspace: "ks2"}), nil, nil).Body.Message)
+	assert.Equal(t, 22, invocations)
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, int64(0), cache.Bytes())
+}
+
+func TestResultCache_Limits(t *testing.T) {
+	newQuery := func(table string) *frame.Frame {
+		return frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM ks." + table})
+	}
+	rows := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.RowsResult{
+		Metadata: &message.RowsMetadata{ColumnCount: 1},
+		Data:     message.RowSet{{make([]byte, 100)}},
+	})
+
+	cache := client.NewResultCache(time.Hour, 2, 0)
+	assert.True(t, cache.Put(newQuery("q1"), rows))
+	assert.True(t, cache.Put(newQuery("q2"), rows))
+	_, found := cache.Get(newQuery("q1"))
+	assert.True(t, found)
+	assert.True(t, cache.Put(newQuery("q3"), rows))
+	// q2 was the least recently used
+	_, found = cache.Get(newQuery("q2"))
+	assert.False(t, found)
+	_, found = cache.Get(newQuery("q1"))
+	assert.True(t, found)
+	assert.Equal(t, 2, cache.Len())
+
+	cache = client.NewResultCache(time.Hour, 0, 250)
+	assert.True(t, cache.Put(newQuery("q1"), rows))
+	assert.True(t, cache.Put(newQuery("q2"), rows))
+	assert.Equal(t, 1, cache.Len())
+	assert.LessOrEqual(t, cache.Bytes(), int64(250))
+	assert.False(t, client.NewResultCache(time.Hour, 0, 50).Put(newQuery("q1"), rows))
+
+	cache = client.NewResultCache(50*time.Millisecond, 0, 0)
+	assert.True(t, cache.Put(newQuery("q1"), rows))
+	time.Sleep(100 * time.Millisecond)
+	_, found = cache.Get(newQuery("q1"))
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.Len())
+}
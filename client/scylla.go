@@ -0,0 +1,95 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ScyllaTabletsRoutingKey is the key of the custom payload entry containing tablet routing information, as sent by
+// ScyllaDB in responses to requests that were routed to a node or shard that is not a replica of the tablet owning the
+// request's token. ScyllaDB only sends it to clients that opted in with the message.SupportedScyllaTabletsRoutingV1
+// STARTUP option.
+const ScyllaTabletsRoutingKey = "tablets-routing-v1"
+
+// scyllaTabletCodec encodes and decodes tablet routing information, serialized as a
+// tuple<bigint, bigint, list<tuple<uuid, int>>>.
+var scyllaTabletCodec, _ = datacodec.NewTuple(datatype.NewTuple(
+	datatype.Bigint,
+	datatype.Bigint,
+	datatype.NewList(datatype.NewTuple(datatype.Uuid, datatype.Int)),
+))
+
+// ScyllaTablet is the routing information of a ScyllaDB tablet: its token range, and its replicas.
+type ScyllaTablet struct {
+	// FirstToken is the exclusive lower bound of the tablet token range.
+	FirstToken int64
+	// LastToken is the inclusive upper bound of the tablet token range.
+	LastToken int64
+	// Replicas are the nodes and shards that own the tablet.
+	Replicas []ScyllaTabletReplica
+}
+
+// ScyllaTabletReplica is a replica of a ScyllaDB tablet.
+type ScyllaTabletReplica struct {
+	// HostId is the host id of the replica node, as found in the system.peers and system.local tables.
+	HostId primitive.UUID
+	// Shard is the shard of the replica node that owns the tablet.
+	Shard int32
+}
+
+func (t *ScyllaTablet) String() string {
+	return fmt.Sprintf("ScyllaTablet{range: (%v, %v], replicas: %v}", t.FirstToken, t.LastToken, t.Replicas)
+}
+
+// Owns returns true if the given token belongs to the token range of this tablet.
+func (t *ScyllaTablet) Owns(token int64) bool {
+	return token > t.FirstToken && token <= t.LastToken
+}
+
+// Marshal serializes this tablet to a custom payload value with the expected format for ScyllaTabletsRoutingKey.
+func (t *ScyllaTablet) Marshal() ([]byte, error) {
+	return scyllaTabletCodec.Encode(t, primitive.ProtocolVersion4)
+}
+
+// Unmarshal deserializes a custom payload value with the expected format for ScyllaTabletsRoutingKey into the current
+// ScyllaTablet.
+func (t *ScyllaTablet) Unmarshal(value []byte) error {
+	if wasNull, err := scyllaTabletCodec.Decode(value, t, primitive.ProtocolVersion4); err != nil {
+		return err
+	} else if wasNull {
+		return fmt.Errorf("empty tablet routing information")
+	}
+	return nil
+}
+
+// ScyllaTabletRouting returns the tablet routing information attached by ScyllaDB to the given response; ok is false if
+// the response has no such information, which means that the request was routed to a replica.
+func ScyllaTabletRouting(response *frame.Frame) (tablet *ScyllaTablet, ok bool, err error) {
+	value, ok := response.Body.CustomPayload[ScyllaTabletsRoutingKey]
+	if !ok {
+		return nil, false, nil
+	}
+	tablet = &ScyllaTablet{}
+	if err = tablet.Unmarshal(value); err != nil {
+		return nil, true, fmt.Errorf("cannot read %v: %w", ScyllaTabletsRoutingKey, err)
+	}
+	return tablet, true, nil
+}
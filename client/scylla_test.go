@@ -0,0 +1,72 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestScyllaTabletRouting(t *testing.T) {
+	hostId := primitive.UUID{0xC0, 0xD1, 0xD2, 0x1E, 0xBB, 0x01, 0x41, 0x96, 0x86, 0xDB, 0xBC, 0x31, 0x7B, 0xC1, 0x79, 0x6A}
+	encoded := []byte{
+		0, 0, 0, 8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x9c, // first token: -100
+		0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 0x64, // last token: 100
+		0, 0, 0, 36, // replicas list
+		0, 0, 0, 1, // list length
+		0, 0, 0, 28, // replica tuple
+		0, 0, 0, 16, 0xC0, 0xD1, 0xD2, 0x1E, 0xBB, 0x01, 0x41, 0x96, 0x86, 0xDB, 0xBC, 0x31, 0x7B, 0xC1, 0x79, 0x6A,
+		0, 0, 0, 4, 0, 0, 0, 3, // shard
+	}
+	expected := &client.ScyllaTablet{
+		FirstToken: -100,
+		LastToken:  100,
+		Replicas:   []client.ScyllaTabletReplica{{HostId: hostId, Shard: 3}},
+	}
+
+	marshaled, err := expected.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, encoded, marshaled)
+
+	response := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.VoidResult{})
+	tablet, ok, err := client.ScyllaTabletRouting(response)
+	assert.Nil(t, tablet)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	response.SetCustomPayload(map[string][]byte{client.ScyllaTabletsRoutingKey: encoded})
+	tablet, ok, err = client.ScyllaTabletRouting(response)
+	assert.Equal(t, expected, tablet)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.False(t, tablet.Owns(-100))
+	assert.True(t, tablet.Owns(-99))
+	assert.True(t, tablet.Owns(100))
+	assert.False(t, tablet.Owns(101))
+
+	response.SetCustomPayload(map[string][]byte{client.ScyllaTabletsRoutingKey: encoded[:20]})
+	tablet, ok, err = client.ScyllaTabletRouting(response)
+	assert.Nil(t, tablet)
+	assert.True(t, ok)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read tablets-routing-v1")
+}
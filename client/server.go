@@ -107,6 +107,19 @@ type CqlServer struct {
 	RequestRawHandlers []RawRequestHandler
 	// TLSConfig is the TLS configuration to use.
 	TLSConfig *tls.Config
+	// ConformanceChecker is an optional checker to validate incoming client frames against the protocol
+	// specification. If nil, incoming frames are not validated.
+	ConformanceChecker *ClientConformanceChecker
+	// EnforceProtocolStates enables the protocol state machine on each connection: requests that are not accepted in
+	// the current state of the connection, e.g. a QUERY before STARTUP, or a STARTUP after READY, are rejected with a
+	// ProtocolError response, and are neither delivered to request handlers nor returned by Receive. The state
+	// transitions are triggered by the responses sent: READY, AUTHENTICATE and AUTH_SUCCESS. See ProtocolState.
+	EnforceProtocolStates bool
+	// Recorder is an optional recorder for the frames sent and received by all server connections.
+	Recorder *FrameRecorder
+	// CorruptionPolicy is an optional policy to corrupt the frames sent by all server connections, to test how clients
+	// handle corrupt data. Raw responses are never corrupted. This is meant for testing only.
+	CorruptionPolicy *CorruptionPolicy
 
 	ctx                context.Context
 	cancel             context.CancelFunc
@@ -222,16 +235,7 @@ func (server *CqlServer) acceptLoop() {
 				break
 			} else {
 				log.Debug().Msgf("%v: new TCP connection accepted", server)
-				if connection, err := newCqlServerConnection(
-					conn,
-					server.ctx,
-					server.Credentials,
-					server.MaxInFlight,
-					server.IdleTimeout,
-					server.RequestHandlers,
-					server.RequestRawHandlers,
-					server.connectionsHandler.onConnectionClosed,
-				); err != nil {
+				if connection, err := newCqlServerConnection(server, conn); err != nil {
 					log.Error().Msgf("%v: failed to accept incoming CQL client connection: %v", server, connection)
 					_ = conn.Close()
 				} else if err := server.connectionsHandler.onConnectionAccepted(connection); err != nil {
@@ -365,14 +369,21 @@ func newRawResponse(rawResponse []byte) *response {
 type CqlServerConnection struct {
 	conn               net.Conn
 	credentials        *AuthCredentials
-	frameCodec         frame.Codec
+	codecLock          sync.RWMutex // guards frameCodec, segmentCodec, compression and startupHeader
+	frameCodec         frame.RawCodec
 	segmentCodec       segment.Codec
 	compression        primitive.Compression
+	startupHeader      *frame.Header
 	modernLayout       bool
 	idleTimeout        time.Duration
 	handlers           []RequestHandler
 	rawHandlers        []RawRequestHandler
 	handlerCtx         []RequestHandlerContext
+	validator          *requestValidator
+	stateMachine       *protocolStateMachine
+	recorder           *FrameRecorder
+	corruption         *CorruptionPolicy
+	channelsLock       sync.RWMutex // guards sends to incoming and outgoing against their closing
 	incoming           chan *frame.Frame
 	outgoing           chan *response
 	waitGroup          *sync.WaitGroup
@@ -383,45 +394,49 @@ type CqlServerConnection struct {
 	payloadAccumulator *payloadAccumulator
 }
 
-func newCqlServerConnection(
-	conn net.Conn,
-	ctx context.Context,
-	credentials *AuthCredentials,
-	maxInFlight int,
-	idleTimeout time.Duration,
-	handlers []RequestHandler,
-	rawHandlers []RawRequestHandler,
-	onClose func(*CqlServerConnection),
-) (*CqlServerConnection, error) {
+// newCqlServerConnection creates a new CqlServerConnection over the given TCP connection, configured with the settings
+// of the given server.
+func newCqlServerConnection(server *CqlServer, conn net.Conn) (*CqlServerConnection, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("TCP connection cannot be nil")
 	}
-	if maxInFlight < 1 {
-		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", maxInFlight)
-	} else if maxInFlight > math.MaxInt16 {
-		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, maxInFlight)
+	if server.MaxInFlight < 1 {
+		return nil, fmt.Errorf("max in-flight: expecting positive, got: %v", server.MaxInFlight)
+	} else if server.MaxInFlight > math.MaxInt16 {
+		return nil, fmt.Errorf("max in-flight: expecting <= %v, got: %v", math.MaxInt16, server.MaxInFlight)
 	}
-	frameCodec := frame.NewCodec()
+	frameCodec := frame.NewServerCodec()
 	segmentCodec := segment.NewCodec()
 	connection := &CqlServerConnection{
 		conn:         conn,
 		frameCodec:   frameCodec,
 		segmentCodec: segmentCodec,
 		compression:  primitive.CompressionNone,
-		credentials:  credentials,
-		idleTimeout:  idleTimeout,
-		handlers:     handlers,
-		rawHandlers:  rawHandlers,
-		handlerCtx:   make([]RequestHandlerContext, len(handlers)),
-		incoming:     make(chan *frame.Frame, maxInFlight),
-		outgoing:     make(chan *response, maxInFlight),
+		credentials:  server.Credentials,
+		idleTimeout:  server.IdleTimeout,
+		handlers:     server.RequestHandlers,
+		rawHandlers:  server.RequestRawHandlers,
+		handlerCtx:   make([]RequestHandlerContext, len(server.RequestHandlers)),
+		incoming:     make(chan *frame.Frame, server.MaxInFlight),
+		outgoing:     make(chan *response, server.MaxInFlight),
 		waitGroup:    &sync.WaitGroup{},
-		onClose:      onClose,
+		onClose:      server.connectionsHandler.onConnectionClosed,
+		payloadAccumulator: &payloadAccumulator{
+			frameCodec: frame.NewServerCodec(), // without compression
+		},
 	}
-	for i := range handlers {
+	for i := range server.RequestHandlers {
 		connection.handlerCtx[i] = requestHandlerContext{}
 	}
-	connection.ctx, connection.cancel = context.WithCancel(ctx)
+	if server.ConformanceChecker != nil {
+		connection.validator = &requestValidator{checker: server.ConformanceChecker}
+	}
+	connection.recorder = server.Recorder
+	connection.corruption = server.CorruptionPolicy
+	if server.EnforceProtocolStates {
+		connection.stateMachine = &protocolStateMachine{}
+	}
+	connection.ctx, connection.cancel = context.WithCancel(server.ctx)
 	connection.incomingLoop()
 	connection.outgoingLoop()
 	connection.awaitDone()
@@ -494,13 +509,12 @@ func (c *CqlServerConnection) outgoingLoop() {
 					abort = c.writeRawResponse(outgoing.rawResponse, c.conn)
 					log.Debug().Msgf("%v: sending outgoing raw response: %v", c, outgoing.rawResponse)
 				} else {
-					if c.compression != primitive.CompressionNone {
+					if _, _, compression := c.codecs(); compression != primitive.CompressionNone {
 						outgoing.responseFrame.Header.Flags = outgoing.responseFrame.Header.Flags.Add(primitive.HeaderFlagCompressed)
 					}
 					log.Debug().Msgf("%v: sending outgoing frame: %v", c, outgoing.responseFrame)
 					if c.modernLayout {
-						// TODO write coalescer
-						abort = c.writeSegment(outgoing.responseFrame, c.conn)
+						abort = c.writeSegments(outgoing)
 					} else {
 						abort = c.writeFrame(outgoing.responseFrame, c.conn)
 					}
@@ -534,7 +548,8 @@ func (c *CqlServerConnection) setIdleTimeout() (abort bool) {
 }
 
 func (c *CqlServerConnection) readSegment(source io.Reader) (abort bool) {
-	if incoming, err := c.segmentCodec.DecodeSegment(source); err != nil {
+	_, segmentCodec, _ := c.codecs()
+	if incoming, err := segmentCodec.DecodeSegment(source); err != nil {
 		abort = c.reportConnectionFailure(err, true)
 	} else if incoming.Header.IsSelfContained {
 		log.Debug().Msgf("%v: received incoming self-contained segment: %v", c, incoming)
@@ -577,55 +592,201 @@ func (c *CqlServerConnection) addMultiSegmentPayload(payload *segment.Payload) (
 	return false
 }
 
-func (c *CqlServerConnection) writeSegment(outgoing *frame.Frame, dest io.Writer) (abort bool) {
-	// never compress frames individually when included in a segment
-	outgoing.Header.Flags.Remove(primitive.HeaderFlagCompressed)
-	encodedFrame := &bytes.Buffer{}
-	if abort = c.writeFrame(outgoing, encodedFrame); abort {
-		abort = true
-	} else {
-		seg := &segment.Segment{
-			Header:  &segment.Header{IsSelfContained: true},
-			Payload: &segment.Payload{UncompressedData: encodedFrame.Bytes()},
+// writeSegments writes the given response, along with the responses already waiting in the outgoing queue, if any,
+// coalescing small frames into shared segments. Raw responses are written as is, after the frames preceding them.
+func (c *CqlServerConnection) writeSegments(outgoing *response) (abort bool) {
+	var dest io.Writer = c.conn
+	var segments *bytes.Buffer
+	if c.corruption != nil {
+		// buffer the encoded segments, so that their checksums can be corrupted before they are written
+		segments = &bytes.Buffer{}
+		dest = segments
+	}
+	_, segmentCodec, compression := c.codecs()
+	writer := segment.NewWriter(dest, segmentCodec)
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		} else if segments != nil && segments.Len() > 0 {
+			c.corruption.CorruptSegments(segments.Bytes(), compression != primitive.CompressionNone)
+			_, err = segments.WriteTo(c.conn)
+			return err
 		}
-		if err := c.segmentCodec.EncodeSegment(seg, dest); err != nil {
-			abort = c.reportConnectionFailure(err, false)
+		return nil
+	}
+	for outgoing != nil {
+		if outgoing.rawResponse != nil {
+			if err := flush(); err != nil {
+				return c.reportConnectionFailure(err, false)
+			} else if abort = c.writeRawResponse(outgoing.rawResponse, c.conn); abort {
+				return abort
+			}
 		} else {
-			log.Debug().Msgf("%v: outgoing segment successfully written: %v (frame: %v)", c, seg, outgoing)
+			// never compress frames individually when included in a segment
+			outgoing.responseFrame.Header.Flags = outgoing.responseFrame.Header.Flags.Remove(primitive.HeaderFlagCompressed)
+			encodedFrame := &bytes.Buffer{}
+			if abort = c.writeFrame(outgoing.responseFrame, encodedFrame); abort {
+				return abort
+			} else if err := writer.WriteEnvelope(encodedFrame.Bytes()); err != nil {
+				return c.reportConnectionFailure(err, false)
+			}
+		}
+		select {
+		case next, ok := <-c.outgoing:
+			if outgoing = next; ok {
+				log.Debug().Msgf("%v: coalescing outgoing response", c)
+			}
+		default:
+			outgoing = nil
 		}
 	}
+	if err := flush(); err != nil {
+		return c.reportConnectionFailure(err, false)
+	}
+	log.Debug().Msgf("%v: outgoing segments successfully written", c)
 	return abort
 }
 
 func (c *CqlServerConnection) readFrame(source io.Reader) (abort bool) {
-	if incoming, err := c.frameCodec.DecodeFrame(source); err != nil {
+	var rejection *ProtocolViolation
+	if incoming, err := c.decodeFrame(source); errors.As(err, &rejection) {
+		c.rejectRequest(rejection)
+	} else if err != nil {
 		abort = c.reportConnectionFailure(err, true)
 	} else {
 		if startup, ok := incoming.Body.Message.(*message.Startup); ok {
-			c.compression = startup.GetCompression()
-			c.frameCodec = frame.NewCodecWithCompression(NewBodyCompressor(c.compression))
-			c.segmentCodec = segment.NewCodecWithCompression(NewPayloadCompressor(c.compression))
+			c.onStartupRequest(incoming.Header, startup.GetCompression())
 		}
 		c.processIncomingFrame(incoming)
 	}
 	return abort
 }
 
+// codecs returns the codecs currently in use, along with the compression they were configured with. The compression
+// can change concurrently, since it is negotiated by STARTUP requests on the incoming loop, and reverted by
+// responses that can be sent from any goroutine.
+func (c *CqlServerConnection) codecs() (frame.RawCodec, segment.Codec, primitive.Compression) {
+	c.codecLock.RLock()
+	defer c.codecLock.RUnlock()
+	return c.frameCodec, c.segmentCodec, c.compression
+}
+
+// onStartupRequest switches to the compression requested by the given STARTUP request.
+func (c *CqlServerConnection) onStartupRequest(startup *frame.Header, compression primitive.Compression) {
+	c.codecLock.Lock()
+	defer c.codecLock.Unlock()
+	c.setCompression(compression)
+	c.startupHeader = startup
+}
+
+// setCompression must be called while holding the codec lock.
+func (c *CqlServerConnection) setCompression(compression primitive.Compression) {
+	c.compression = compression
+	c.frameCodec = frame.NewServerCodecWithCompression(NewBodyCompressor(compression))
+	c.segmentCodec = segment.NewCodecWithCompression(NewPayloadCompressor(compression))
+}
+
+// onStartupResponse reverts the compression negotiated by the last STARTUP request if the given response rejects it.
+func (c *CqlServerConnection) onStartupResponse(response *frame.Frame) {
+	c.codecLock.Lock()
+	defer c.codecLock.Unlock()
+	if startup := c.startupHeader; startup != nil && startup.StreamId == response.Header.StreamId {
+		c.startupHeader = nil
+		if response.Header.OpCode == primitive.OpCodeError {
+			c.setCompression(primitive.CompressionNone)
+		}
+	}
+}
+
+func (c *CqlServerConnection) decodeFrame(source io.Reader) (*frame.Frame, error) {
+	frameCodec, _, _ := c.codecs()
+	if c.validator == nil {
+		return frameCodec.DecodeFrame(source)
+	}
+	rawFrame, err := frameCodec.DecodeRawFrame(source)
+	if err != nil {
+		if !errors.Is(err, io.EOF) && !c.IsClosed() {
+			c.validator.reportMalformed(err, nil, c)
+		}
+		return nil, err
+	}
+	c.validator.validateHeader(rawFrame.Header, c)
+	bodyReader := bytes.NewReader(rawFrame.Body)
+	body, err := frameCodec.DecodeBody(rawFrame.Header, bodyReader)
+	if err != nil {
+		c.validator.reportMalformed(err, rawFrame.Header, c)
+		if rejection := c.validator.takeRejection(); rejection != nil {
+			return nil, rejection
+		}
+		return nil, fmt.Errorf("cannot decode body: %w", err)
+	}
+	if !rawFrame.Header.Flags.Contains(primitive.HeaderFlagCompressed) {
+		// compressed bodies are entirely consumed by the decompressor
+		c.validator.validateBodyLength(rawFrame.Header, bodyReader.Len(), c)
+	}
+	if rejection := c.validator.takeRejection(); rejection != nil {
+		return nil, rejection
+	}
+	return &frame.Frame{Header: rawFrame.Header, Body: body}, nil
+}
+
+// rejectRequest answers the request that committed the given violation with a ProtocolError response.
+func (c *CqlServerConnection) rejectRequest(violation *ProtocolViolation) {
+	log.Warn().Msgf("%v: rejecting non-conformant request: %v", c, violation)
+	rejection := frame.NewFrame(violation.Header.Version, violation.Header.StreamId, &message.ProtocolError{ErrorMessage: violation.Message})
+	if err := c.Send(rejection); err != nil {
+		log.Error().Err(err).Msgf("%v: send failed for frame: %v", c, rejection)
+	}
+}
+
 func (c *CqlServerConnection) writeFrame(outgoing *frame.Frame, dest io.Writer) (abort bool) {
 	c.maybeSwitchToModernLayout(outgoing)
-	if err := c.frameCodec.EncodeFrame(outgoing, dest); err != nil {
+	var kept *frame.Frame
+	if c.recorder != nil {
+		kept = c.recorder.keep(outgoing)
+	}
+	var err error
+	if c.corruption == nil {
+		frameCodec, _, _ := c.codecs()
+		err = frameCodec.EncodeFrame(outgoing, dest)
+	} else {
+		err = c.writeCorruptFrame(outgoing, dest)
+	}
+	if err != nil {
 		abort = c.reportConnectionFailure(err, false)
 	} else {
 		log.Debug().Msgf("%v: outgoing frame successfully written: %v", c, outgoing)
+		if c.recorder != nil {
+			_, _, compression := c.codecs()
+			c.recorder.record(FrameSent, outgoing.Header, kept, compression, c)
+		}
 	}
 	return abort
 }
 
+// writeCorruptFrame encodes the given frame, then writes it to dest, after it was possibly corrupted by the corruption
+// policy.
+func (c *CqlServerConnection) writeCorruptFrame(outgoing *frame.Frame, dest io.Writer) error {
+	encoded := &bytes.Buffer{}
+	frameCodec, _, _ := c.codecs()
+	if err := frameCodec.EncodeFrame(outgoing, encoded); err != nil {
+		return err
+	}
+	_, err := dest.Write(c.corruption.CorruptFrame(encoded.Bytes()))
+	return err
+}
+
 func (c *CqlServerConnection) writeRawResponse(outgoing []byte, dest io.Writer) (abort bool) {
 	if _, err := dest.Write(outgoing); err != nil {
 		abort = c.reportConnectionFailure(err, false)
 	} else {
 		log.Debug().Msgf("%v: outgoing raw response successfully written: %v", c, outgoing)
+		if c.recorder != nil {
+			frameCodec, _, _ := c.codecs()
+			if header, err := frameCodec.DecodeHeader(bytes.NewReader(outgoing)); err == nil {
+				c.recorder.Record(FrameSent, header, c)
+			}
+		}
 	}
 	return abort
 }
@@ -659,15 +820,38 @@ func (c *CqlServerConnection) reportConnectionFailure(err error, read bool) (abo
 
 func (c *CqlServerConnection) processIncomingFrame(incoming *frame.Frame) {
 	log.Debug().Msgf("%v: received incoming frame: %v", c, incoming)
+	if c.recorder != nil {
+		_, _, compression := c.codecs()
+		c.recorder.RecordFrame(FrameReceived, incoming, compression, c)
+	}
+	if c.stateMachine != nil {
+		if violation := c.stateMachine.validateRequest(incoming.Header.OpCode, incoming.Header.Version); violation != "" {
+			log.Warn().Msgf("%v: rejecting out-of-order request: %v", c, violation)
+			rejection := frame.NewFrame(incoming.Header.Version, incoming.Header.StreamId, &message.ProtocolError{ErrorMessage: violation})
+			if err := c.Send(rejection); err != nil {
+				log.Error().Err(err).Msgf("%v: send failed for frame: %v", c, rejection)
+			}
+			return
+		}
+	}
+	c.deliverIncoming(incoming)
+	if len(c.handlers) > 0 {
+		c.invokeRequestHandlers(incoming)
+	}
+}
+
+func (c *CqlServerConnection) deliverIncoming(incoming *frame.Frame) {
+	c.channelsLock.RLock()
+	defer c.channelsLock.RUnlock()
+	if c.IsClosed() {
+		return
+	}
 	select {
 	case c.incoming <- incoming:
 		log.Debug().Msgf("%v: incoming frame successfully delivered: %v", c, incoming)
 	default:
 		log.Error().Msgf("%v: incoming frames queue is full, discarding frame: %v", c, incoming)
 	}
-	if len(c.handlers) > 0 {
-		c.invokeRequestHandlers(incoming)
-	}
 }
 
 func (c *CqlServerConnection) awaitDone() {
@@ -720,6 +904,15 @@ func (c *CqlServerConnection) Send(f *frame.Frame) error {
 		return fmt.Errorf("%v: connection closed", c)
 	}
 	log.Debug().Msgf("%v: enqueuing outgoing frame: %v", c, f)
+	if c.stateMachine != nil {
+		c.stateMachine.onResponse(f.Header.OpCode)
+	}
+	c.onStartupResponse(f)
+	c.channelsLock.RLock()
+	defer c.channelsLock.RUnlock()
+	if c.IsClosed() {
+		return fmt.Errorf("%v: connection closed", c)
+	}
 	select {
 	case c.outgoing <- newFrameResponse(f):
 		log.Debug().Msgf("%v: outgoing frame successfully enqueued: %v", c, f)
@@ -735,6 +928,16 @@ func (c *CqlServerConnection) SendRaw(rawResponse []byte) error {
 		return fmt.Errorf("%v: connection closed", c)
 	}
 	log.Debug().Msgf("%v: enqueuing outgoing raw response: %v", c, rawResponse)
+	if c.stateMachine != nil {
+		if opCode, ok := rawResponseOpCode(rawResponse); ok {
+			c.stateMachine.onResponse(opCode)
+		}
+	}
+	c.channelsLock.RLock()
+	defer c.channelsLock.RUnlock()
+	if c.IsClosed() {
+		return fmt.Errorf("%v: connection closed", c)
+	}
 	select {
 	case c.outgoing <- newRawResponse(rawResponse):
 		log.Debug().Msgf("%v: outgoing frame successfully enqueued: %v", c, rawResponse)
@@ -744,6 +947,15 @@ func (c *CqlServerConnection) SendRaw(rawResponse []byte) error {
 	}
 }
 
+// ProtocolState returns the current state of this connection in the protocol state machine. It always returns
+// ProtocolStateUninitialized if the state machine is not enabled, see CqlServer.EnforceProtocolStates.
+func (c *CqlServerConnection) ProtocolState() ProtocolState {
+	if c.stateMachine == nil {
+		return ProtocolStateUninitialized
+	}
+	return c.stateMachine.current()
+}
+
 // Receive waits until the next request frame is received, or the configured idle timeout is triggered, or the
 // connection itself is closed, whichever happens first.
 func (c *CqlServerConnection) Receive() (*frame.Frame, error) {
@@ -776,12 +988,10 @@ func (c *CqlServerConnection) Close() (err error) {
 		log.Debug().Msgf("%v: closing", c)
 		c.cancel()
 		err = c.conn.Close()
-		incoming := c.incoming
-		outgoing := c.outgoing
-		c.incoming = nil
-		c.outgoing = nil
-		close(incoming)
-		close(outgoing)
+		c.channelsLock.Lock()
+		close(c.incoming)
+		close(c.outgoing)
+		c.channelsLock.Unlock()
 		c.waitGroup.Wait()
 		c.onClose(c)
 		if err != nil {
@@ -0,0 +1,99 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrConnectionClosed is returned when a request cannot be sent because the connection is closed or closing, and is
+// delivered to in-flight requests that were still waiting for a response when the connection was closed. Use
+// errors.Is to detect it.
+var ErrConnectionClosed = errors.New("connection closed")
+
+// drainPollInterval is the interval at which CloseGracefully checks whether all in-flight requests have completed.
+const drainPollInterval = 10 * time.Millisecond
+
+// CloseGracefully closes this connection after letting in-flight requests complete. New requests are rejected
+// immediately with ErrConnectionClosed; requests already in flight, including requests queued with
+// StreamIdExhaustionQueue, are given until the timeout elapses to receive their responses. The connection is then
+// closed, its stream ids are released, and any request still in flight is completed with ErrConnectionClosed.
+// If the timeout elapsed before all requests completed, the connection is closed anyway and an error reporting the
+// number of abandoned requests is returned. If the connection is closed concurrently while draining, e.g. by the peer,
+// no error is returned: the requests still in flight are then completed with ErrConnectionClosed.
+// With ReadLoopPull, responses are only processed when NextFrame is called: the caller must keep pulling frames
+// concurrently, otherwise in-flight requests cannot complete before the timeout.
+func (c *CqlClientConnection) CloseGracefully(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("%v: timeout: expecting positive, got: %v", c, timeout)
+	}
+	if c.IsClosed() {
+		log.Debug().Msgf("%v: already closed", c)
+		return nil
+	}
+	log.Debug().Msgf("%v: draining in-flight requests", c)
+	c.inFlightHandler.startDraining()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	timedOut := false
+drain:
+	for c.inFlightHandler.pendingCount() > 0 && !c.IsClosed() {
+		select {
+		case <-deadline.C:
+			timedOut = true
+			break drain
+		case <-ticker.C:
+		}
+	}
+	if !timedOut && c.IsClosed() {
+		// closed concurrently, e.g. by the peer: requests still in flight were completed with ErrConnectionClosed
+		log.Debug().Msgf("%v: connection closed while draining in-flight requests", c)
+		return nil
+	}
+	pending := c.inFlightHandler.pendingCount()
+	if err := c.Close(); err != nil {
+		return err
+	} else if timedOut && pending > 0 {
+		return fmt.Errorf("%v: timed out after %v waiting for in-flight requests, %d request(s) abandoned", c, timeout, pending)
+	}
+	return nil
+}
+
+// startDraining prevents new requests from being registered; requests already registered are not affected.
+func (h *inFlightRequestsHandler) startDraining() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+func (h *inFlightRequestsHandler) isDraining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+// pendingCount returns the number of requests in flight, including queued requests waiting for a stream id.
+func (h *inFlightRequestsHandler) pendingCount() int {
+	// the overflow lock is held while reading the in-flight map, since a queued request is removed from the overflow
+	// queue before it is registered as in flight
+	h.overflowLock.Lock()
+	defer h.overflowLock.Unlock()
+	h.inFlightLock.RLock()
+	defer h.inFlightLock.RUnlock()
+	return len(h.inFlight) + len(h.overflow)
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// StartupHook is a callback function invoked by handshake handlers created with NewHandshakeHandler whenever a STARTUP
+// request is received. It can return a response frame to override the default handshake behavior, or nil to let the
+// next hook, or the default behavior, handle the request. If the response is READY, the handshake is complete; if it
+// is AUTHENTICATE, the handshake proceeds with plain-text authentication against the server credentials, or accepts
+// any credentials if the server has none; any other response, typically an error, rejects the STARTUP request, and the
+// client may send another one, e.g. with a lower protocol version. The compression requested by a rejected STARTUP
+// request is not applied.
+type StartupHook func(request *frame.Frame, startup *message.Startup, conn *CqlServerConnection) (response *frame.Frame)
+
+// RequireAuthentication returns a StartupHook that requires clients to authenticate with plain-text auth, even if the
+// server has no credentials; in that case, any credentials are accepted.
+func RequireAuthentication() StartupHook {
+	return func(request *frame.Frame, _ *message.Startup, _ *CqlServerConnection) *frame.Frame {
+		return frame.NewAuthenticateResponse(request, "org.apache.cassandra.auth.PasswordAuthenticator")
+	}
+}
+
+// RejectCompression returns a StartupHook that rejects STARTUP requests asking for any compression algorithm, with
+// the ProtocolError sent by Cassandra for unknown algorithms.
+func RejectCompression() StartupHook {
+	return func(request *frame.Frame, startup *message.Startup, _ *CqlServerConnection) *frame.Frame {
+		if compression := startup.GetCompression(); compression != primitive.CompressionNone {
+			return frame.NewErrorResponse(request, primitive.ErrorCodeProtocolError,
+				fmt.Sprintf("Unknown compression algorithm: %v", compression))
+		}
+		return nil
+	}
+}
+
+// LimitProtocolVersion returns a StartupHook that rejects STARTUP requests using a protocol version greater than the
+// given one, with the ProtocolError sent by Cassandra for unsupported versions, see
+// message.ProtocolError.UnsupportedVersionHint. This forces clients to downgrade their protocol version.
+func LimitProtocolVersion(highest primitive.ProtocolVersion) StartupHook {
+	var supported []string
+	for _, version := range primitive.SupportedProtocolVersionsLesserThanOrEqualTo(highest) {
+		if !version.IsDse() {
+			supported = append(supported, fmt.Sprintf("%d/v%d", version, version))
+		}
+	}
+	return func(request *frame.Frame, _ *message.Startup, _ *CqlServerConnection) *frame.Frame {
+		if version := request.Header.Version; version > highest {
+			return frame.NewErrorResponse(request, primitive.ErrorCodeProtocolError, fmt.Sprintf(
+				"Invalid or unsupported protocol version (%d); supported versions are (%v)",
+				version, strings.Join(supported, ", ")))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewHandshakeHandler(t *testing.T) {
+	handler := client.NewHandshakeHandler(client.LimitProtocolVersion(primitive.ProtocolVersion4), client.RejectCompression())
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+	defer cancelFn()
+
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion5, 1, message.NewStartup()))
+	require.NoError(t, err)
+	protocolError, ok := response.Body.Message.(*message.ProtocolError)
+	require.True(t, ok, response.Body.Message)
+	hint, ok := protocolError.UnsupportedVersionHint()
+	require.True(t, ok, protocolError.ErrorMessage)
+	highest, ok := hint.HighestSupportedVersion()
+	assert.True(t, ok)
+	assert.Equal(t, primitive.ProtocolVersion4, highest)
+
+	// clients close their connection when receiving a protocol error
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	clientConn, err = client.NewCqlClient("127.0.0.1:9043", nil).Connect(context.Background())
+	require.NoError(t, err)
+	startup := message.NewStartup(message.StartupOptionCompression, string(primitive.CompressionLz4))
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 2, startup))
+	require.NoError(t, err)
+	assert.Equal(t, &message.ProtocolError{ErrorMessage: "Unknown compression algorithm: LZ4"}, response.Body.Message)
+
+	assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	clientConn, err = client.NewCqlClient("127.0.0.1:9043", nil).Connect(context.Background())
+	require.NoError(t, err)
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 3, message.NewStartup()))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Ready{}, response.Body.Message)
+
+	cancelFn()
+	assert.NoError(t, clientConn.Close())
+	checkClosed(t, clientConn, server)
+}
+
+func TestRequireAuthentication(t *testing.T) {
+	handler := client.NewHandshakeHandler(client.RequireAuthentication())
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+	defer cancelFn()
+
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 1, message.NewStartup()))
+	require.NoError(t, err)
+	assert.IsType(t, &message.Authenticate{}, response.Body.Message)
+	token := (&client.AuthCredentials{Username: "user", Password: "pass"}).Marshal()
+	response, err = clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, 2, &message.AuthResponse{Token: token}))
+	require.NoError(t, err)
+	assert.IsType(t, &message.AuthSuccess{}, response.Body.Message)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
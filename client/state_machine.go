@@ -0,0 +1,128 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ProtocolState is the state of a server connection in the protocol state machine, see
+// CqlServer.EnforceProtocolStates.
+type ProtocolState int
+
+const (
+	// ProtocolStateUninitialized is the initial state: only OPTIONS and STARTUP requests are accepted.
+	ProtocolStateUninitialized = ProtocolState(iota)
+	// ProtocolStateAuthentication is the state after the server replied to STARTUP with AUTHENTICATE: only
+	// AUTH_RESPONSE and CREDENTIALS requests are accepted.
+	ProtocolStateAuthentication
+	// ProtocolStateReady is the steady state, after the server replied with READY or AUTH_SUCCESS: all requests are
+	// accepted, except STARTUP.
+	ProtocolStateReady
+)
+
+func (s ProtocolState) String() string {
+	switch s {
+	case ProtocolStateUninitialized:
+		return "UNINITIALIZED"
+	case ProtocolStateAuthentication:
+		return "AUTHENTICATION"
+	case ProtocolStateReady:
+		return "READY"
+	}
+	return fmt.Sprintf("ProtocolState ? [%d]", int(s))
+}
+
+// protocolStateMachine holds the protocol state of a server connection. Incoming requests are validated against the
+// current state, and outgoing responses trigger state transitions. The transitions and error messages are the ones of
+// Cassandra's ServerConnection.
+type protocolStateMachine struct {
+	lock  sync.Mutex
+	state ProtocolState
+}
+
+func (m *protocolStateMachine) current() ProtocolState {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.state
+}
+
+// validateRequest returns an error message suitable for a ProtocolError response if the given request opcode is not
+// accepted in the current state, or an empty string otherwise.
+func (m *protocolStateMachine) validateRequest(opCode primitive.OpCode, version primitive.ProtocolVersion) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	switch m.state {
+	case ProtocolStateUninitialized:
+		if opCode != primitive.OpCodeStartup && opCode != primitive.OpCodeOptions {
+			return fmt.Sprintf("Unexpected message %v, expecting STARTUP or OPTIONS", opCodeName(opCode))
+		}
+	case ProtocolStateAuthentication:
+		if opCode != primitive.OpCodeAuthResponse && opCode != primitive.OpCodeCredentials {
+			expected := "SASL_RESPONSE"
+			if version < primitive.ProtocolVersion2 {
+				expected = "CREDENTIALS"
+			}
+			return fmt.Sprintf("Unexpected message %v, needs authentication through %v message", opCodeName(opCode), expected)
+		}
+	case ProtocolStateReady:
+		if opCode == primitive.OpCodeStartup {
+			return "Unexpected message STARTUP, the connection is already initialized"
+		}
+	}
+	return ""
+}
+
+// onResponse applies the state transition triggered by the given response opcode, if any.
+func (m *protocolStateMachine) onResponse(opCode primitive.OpCode) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	switch {
+	case m.state == ProtocolStateUninitialized && opCode == primitive.OpCodeReady:
+		m.state = ProtocolStateReady
+	case m.state == ProtocolStateUninitialized && opCode == primitive.OpCodeAuthenticate:
+		m.state = ProtocolStateAuthentication
+	case m.state == ProtocolStateAuthentication && opCode == primitive.OpCodeAuthSuccess:
+		m.state = ProtocolStateReady
+	}
+}
+
+// opCodeName returns the bare name of the given opcode, e.g. QUERY.
+func opCodeName(opCode primitive.OpCode) string {
+	name := strings.TrimPrefix(opCode.String(), "OpCode ")
+	if i := strings.Index(name, " ["); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// rawResponseOpCode returns the opcode of the given encoded response frame.
+func rawResponseOpCode(rawResponse []byte) (primitive.OpCode, bool) {
+	if len(rawResponse) == 0 {
+		return 0, false
+	}
+	offset := 4
+	if primitive.ProtocolVersion(rawResponse[0]&0b0111_1111) < primitive.ProtocolVersion3 {
+		offset = 3
+	}
+	if len(rawResponse) <= offset {
+		return 0, false
+	}
+	return primitive.OpCode(rawResponse[offset]), true
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var voidQueryHandler client.RequestHandler = func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) (response *frame.Frame) {
+	if _, ok := request.Body.Message.(*message.Query); ok {
+		response = frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.VoidResult{})
+	}
+	return
+}
+
+// ProtocolError is a fatal error: the client closes the connection after receiving it, so each rejection is
+// tested on a new connection.
+func TestCqlServer_EnforceProtocolStates(t *testing.T) {
+	credentials := &client.AuthCredentials{Username: "cassandra", Password: "cassandra"}
+	server := client.NewCqlServer("127.0.0.1:9043", credentials)
+	server.EnforceProtocolStates = true
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, voidQueryHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clt := client.NewCqlClient("127.0.0.1:9043", credentials)
+
+	query := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: "SELECT * FROM system.local"})
+	startup := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, message.NewStartup())
+	authResponse := frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.AuthResponse{Token: credentials.Marshal()})
+	assertRejected := func(clientConn *client.CqlClientConnection, request *frame.Frame, expected string) {
+		response, err := clientConn.SendAndReceive(request)
+		require.NoError(t, err)
+		require.IsType(t, &message.ProtocolError{}, response.Body.Message)
+		assert.Equal(t, expected, response.Body.Message.(*message.ProtocolError).ErrorMessage)
+		assert.Eventually(t, clientConn.IsClosed, time.Second*10, time.Millisecond*10)
+	}
+
+	t.Run("query before startup", func(t *testing.T) {
+		clientConn, serverConn, err := server.Bind(clt, ctx)
+		require.NoError(t, err)
+		assertRejected(clientConn, query, "Unexpected message QUERY, expecting STARTUP or OPTIONS")
+		assert.Equal(t, client.ProtocolStateUninitialized, serverConn.ProtocolState())
+	})
+
+	t.Run("query before authentication", func(t *testing.T) {
+		clientConn, serverConn, err := server.Bind(clt, ctx)
+		require.NoError(t, err)
+		response, err := clientConn.SendAndReceive(startup)
+		require.NoError(t, err)
+		require.IsType(t, &message.Authenticate{}, response.Body.Message)
+		assert.Equal(t, client.ProtocolStateAuthentication, serverConn.ProtocolState())
+		assertRejected(clientConn, query, "Unexpected message QUERY, needs authentication through SASL_RESPONSE message")
+		assert.Equal(t, client.ProtocolStateAuthentication, serverConn.ProtocolState())
+	})
+
+	t.Run("startup after ready", func(t *testing.T) {
+		clientConn, serverConn, err := server.Bind(clt, ctx)
+		require.NoError(t, err)
+		response, err := clientConn.SendAndReceive(startup)
+		require.NoError(t, err)
+		require.IsType(t, &message.Authenticate{}, response.Body.Message)
+		response, err = clientConn.SendAndReceive(authResponse)
+		require.NoError(t, err)
+		require.IsType(t, &message.AuthSuccess{}, response.Body.Message)
+		assert.Equal(t, client.ProtocolStateReady, serverConn.ProtocolState())
+		response, err = clientConn.SendAndReceive(query)
+		require.NoError(t, err)
+		assert.IsType(t, &message.VoidResult{}, response.Body.Message)
+		assertRejected(clientConn, startup, "Unexpected message STARTUP, the connection is already initialized")
+	})
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
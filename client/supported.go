@@ -0,0 +1,102 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// supportedCache holds the last SUPPORTED response received on a connection.
+type supportedCache struct {
+	lock      sync.RWMutex
+	supported *message.Supported
+}
+
+func (s *supportedCache) get() *message.Supported {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.supported
+}
+
+func (s *supportedCache) set(supported *message.Supported) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.supported = supported
+}
+
+// Supported returns the last SUPPORTED response received on this connection, in reply to an OPTIONS request, or nil
+// if no OPTIONS request was sent yet. The cache is populated by any OPTIONS request sent on this connection, e.g. by
+// DiscoverProtocolVersion; use RefreshSupported to populate or refresh it explicitly. The returned message must not
+// be modified.
+func (c *CqlClientConnection) Supported() *message.Supported {
+	return c.supported.get()
+}
+
+// RefreshSupported sends an OPTIONS request using the given protocol version, caches the SUPPORTED response, and
+// returns it. Use stream id zero to activate automatic stream id management.
+func (c *CqlClientConnection) RefreshSupported(version primitive.ProtocolVersion, streamId int16) (*message.Supported, error) {
+	response, err := c.SendAndReceive(frame.NewFrame(version, streamId, &message.Options{}))
+	if err != nil {
+		return nil, fmt.Errorf("could not send OPTIONS: %w", err)
+	} else if response == nil {
+		return nil, fmt.Errorf("could not send OPTIONS: no response received")
+	}
+	supported, ok := response.Body.Message.(*message.Supported)
+	if !ok {
+		return nil, fmt.Errorf("expected SUPPORTED, got %v", response.Body.Message)
+	}
+	c.supported.set(supported)
+	return supported, nil
+}
+
+// SupportsCompression returns whether the server supports the given compression algorithm, according to the COMPRESSION
+// option of the cached SUPPORTED response, see Supported. The second return value is false if the server support is
+// unknown, that is, if no SUPPORTED response was cached yet, or if it does not contain the COMPRESSION option.
+// primitive.CompressionNone is always supported.
+func (c *CqlClientConnection) SupportsCompression(compression primitive.Compression) (supported bool, known bool) {
+	if compression == primitive.CompressionNone || compression == "" {
+		return true, true
+	}
+	cached := c.supported.get()
+	if cached == nil {
+		return false, false
+	}
+	algorithms, found := cached.Options[message.StartupOptionCompression]
+	if !found {
+		return false, false
+	}
+	for _, algorithm := range algorithms {
+		if strings.EqualFold(algorithm, string(compression)) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// ScyllaShardingInfo returns the sharding information advertised by the ScyllaDB node this connection is established
+// to, according to the cached SUPPORTED response, see Supported and message.Supported.ScyllaShardingInfo. It returns
+// false if no SUPPORTED response was cached yet, or if the server is not a ScyllaDB node.
+func (c *CqlClientConnection) ScyllaShardingInfo() (*message.ScyllaShardingInfo, bool) {
+	if cached := c.supported.get(); cached != nil {
+		return cached.ScyllaShardingInfo()
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCqlClientConnection_RefreshSupported(t *testing.T) {
+	options := map[string][]string{
+		message.StartupOptionCompression: {"snappy"},
+		message.SupportedScyllaShard:     {"1"},
+		message.SupportedScyllaNrShards:  {"4"},
+	}
+	supportedHandler := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) (response *frame.Frame) {
+		if _, ok := request.Body.Message.(*message.Options); ok {
+			response = frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Supported{Options: options})
+		}
+		return
+	}
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{supportedHandler}, nil)
+	defer cancelFn()
+
+	assert.Nil(t, clientConn.Supported())
+	_, known := clientConn.SupportsCompression(primitive.CompressionSnappy)
+	assert.False(t, known)
+	_, found := clientConn.ScyllaShardingInfo()
+	assert.False(t, found)
+
+	supported, err := clientConn.RefreshSupported(primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	assert.Equal(t, options, supported.Options)
+	assert.Same(t, supported, clientConn.Supported())
+
+	supports, known := clientConn.SupportsCompression(primitive.CompressionSnappy)
+	assert.True(t, supports)
+	assert.True(t, known)
+	supports, known = clientConn.SupportsCompression(primitive.CompressionLz4)
+	assert.False(t, supports)
+	assert.True(t, known)
+	supports, known = clientConn.SupportsCompression(primitive.CompressionNone)
+	assert.True(t, supports)
+	assert.True(t, known)
+
+	info, found := clientConn.ScyllaShardingInfo()
+	require.True(t, found)
+	assert.Equal(t, 1, info.Shard)
+	assert.Equal(t, 4, info.NrShards)
+
+	// any SUPPORTED response refreshes the cache
+	options = map[string][]string{}
+	response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	assert.Same(t, response.Body.Message, clientConn.Supported())
+	_, found = clientConn.ScyllaShardingInfo()
+	assert.False(t, found)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_NewStartupRequest_UnsupportedCompression(t *testing.T) {
+	supportedHandler := func(request *frame.Frame, conn *client.CqlServerConnection, ctx client.RequestHandlerContext) (response *frame.Frame) {
+		if _, ok := request.Body.Message.(*message.Options); ok {
+			response = frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Supported{
+				Options: map[string][]string{message.StartupOptionCompression: {"snappy"}},
+			})
+		}
+		return
+	}
+	server := client.NewCqlServer("127.0.0.1:9043", nil)
+	server.RequestHandlers = []client.RequestHandler{supportedHandler}
+	clt := client.NewCqlClient("127.0.0.1:9043", nil)
+	clt.Compression = primitive.CompressionLz4
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+	clientConn, err := clt.Connect(ctx)
+	require.NoError(t, err)
+
+	// support is unknown until SUPPORTED is received
+	_, err = clientConn.NewStartupRequest(primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+
+	_, err = clientConn.RefreshSupported(primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	_, err = clientConn.NewStartupRequest(primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server does not support compression LZ4")
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
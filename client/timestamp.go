@@ -0,0 +1,111 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// TimestampGenerator generates client-side timestamps, in microseconds since the epoch, to use as default timestamps
+// for QUERY, EXECUTE and BATCH requests. Use CqlClient.TimestampGenerator to plug a generator in. Implementations must
+// be safe for concurrent use.
+type TimestampGenerator interface {
+
+	// Next returns the next timestamp.
+	Next() int64
+}
+
+// MonotonicTimestampGenerator is a TimestampGenerator that generates strictly increasing timestamps, with microsecond
+// precision, even when the system clock goes backwards, e.g. because of a clock adjustment: in that case, or when
+// more than one timestamp is requested in the same microsecond, the previous timestamp is incremented by one
+// microsecond. It is preferable to create MonotonicTimestampGenerator instances using the constructor function
+// NewMonotonicTimestampGenerator. It is safe for concurrent use.
+type MonotonicTimestampGenerator struct {
+	last  int64
+	clock func() time.Time
+}
+
+func NewMonotonicTimestampGenerator() *MonotonicTimestampGenerator {
+	return &MonotonicTimestampGenerator{clock: time.Now}
+}
+
+func (g *MonotonicTimestampGenerator) Next() int64 {
+	for {
+		last := atomic.LoadInt64(&g.last)
+		next := g.clock().UnixNano() / int64(time.Microsecond)
+		if next <= last {
+			next = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&g.last, last, next) {
+			return next
+		}
+	}
+}
+
+// setDefaultTimestamp returns a request with a default timestamp, if the given request is a QUERY, EXECUTE or BATCH
+// request without a default timestamp, and if its protocol version supports default timestamps. The given request is
+// never modified: when a timestamp is set, the returned frame shares its header with the given request, but carries a
+// shallow copy of its body, message and query options. Otherwise, the given request is returned as is.
+func setDefaultTimestamp(request *frame.Frame, generator TimestampGenerator) *frame.Frame {
+	if !request.Header.Version.SupportsQueryFlag(primitive.QueryFlagDefaultTimestamp) {
+		return request
+	}
+	var msg message.Message
+	switch original := request.Body.Message.(type) {
+	case *message.Query:
+		if original.Options != nil && original.Options.DefaultTimestamp != nil {
+			return request
+		}
+		query := *original
+		query.Options = withDefaultTimestamp(original.Options, generator.Next())
+		msg = &query
+	case *message.Execute:
+		if original.Options != nil && original.Options.DefaultTimestamp != nil {
+			return request
+		}
+		execute := *original
+		execute.Options = withDefaultTimestamp(original.Options, generator.Next())
+		msg = &execute
+	case *message.Batch:
+		if original.DefaultTimestamp != nil {
+			return request
+		}
+		batch := *original
+		timestamp := generator.Next()
+		batch.DefaultTimestamp = &timestamp
+		msg = &batch
+	default:
+		return request
+	}
+	body := *request.Body
+	body.Message = msg
+	return &frame.Frame{Header: request.Header, Body: &body}
+}
+
+// withDefaultTimestamp returns a shallow copy of the given query options, or new query options if nil, with the given
+// default timestamp.
+func withDefaultTimestamp(options *message.QueryOptions, timestamp int64) *message.QueryOptions {
+	result := &message.QueryOptions{}
+	if options != nil {
+		*result = *options
+	}
+	result.DefaultTimestamp = &timestamp
+	return result
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestMonotonicTimestampGenerator(t *testing.T) {
+	now := time.Unix(1_600_000_000, 0)
+	generator := NewMonotonicTimestampGenerator()
+	generator.clock = func() time.Time { return now }
+	assert.Equal(t, int64(1_600_000_000_000_000), generator.Next())
+	// same microsecond
+	assert.Equal(t, int64(1_600_000_000_000_001), generator.Next())
+	// clock going backwards
+	now = now.Add(-time.Second)
+	assert.Equal(t, int64(1_600_000_000_000_002), generator.Next())
+	// clock catching up
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, int64(1_600_000_001_000_000), generator.Next())
+}
+
+func TestMonotonicTimestampGenerator_Concurrent(t *testing.T) {
+	generator := NewMonotonicTimestampGenerator()
+	timestamps := make(chan int64, 10_000)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1_000; j++ {
+				timestamps <- generator.Next()
+			}
+		}()
+	}
+	wg.Wait()
+	close(timestamps)
+	unique := map[int64]bool{}
+	for timestamp := range timestamps {
+		unique[timestamp] = true
+	}
+	assert.Len(t, unique, 10_000)
+}
+
+type fixedTimestampGenerator int64
+
+func (g fixedTimestampGenerator) Next() int64 {
+	return int64(g)
+}
+
+func TestSetDefaultTimestamp(t *testing.T) {
+	generator := fixedTimestampGenerator(42)
+	existing := int64(123)
+	query := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM ks.t"})
+	execute := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Execute{
+		QueryId: []byte{1},
+		Options: &message.QueryOptions{DefaultTimestamp: &existing},
+	})
+	batch := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Batch{})
+	legacy := frame.NewFrame(primitive.ProtocolVersion2, 1, &message.Query{Query: "SELECT * FROM ks.t"})
+	assert.Equal(t, int64(42), *setDefaultTimestamp(query, generator).Body.Message.(*message.Query).Options.DefaultTimestamp)
+	assert.Same(t, execute, setDefaultTimestamp(execute, generator))
+	assert.Equal(t, int64(123), *execute.Body.Message.(*message.Execute).Options.DefaultTimestamp)
+	assert.Equal(t, int64(42), *setDefaultTimestamp(batch, generator).Body.Message.(*message.Batch).DefaultTimestamp)
+	assert.Same(t, legacy, setDefaultTimestamp(legacy, generator))
+	assert.Nil(t, legacy.Body.Message.(*message.Query).Options)
+	// the original requests are left untouched, so that they can be safely reused
+	assert.Nil(t, query.Body.Message.(*message.Query).Options)
+	assert.Nil(t, batch.Body.Message.(*message.Batch).DefaultTimestamp)
+	options := &message.QueryOptions{PageSize: 10}
+	reused := frame.NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM ks.t", Options: options})
+	first := setDefaultTimestamp(reused, generator).Body.Message.(*message.Query).Options
+	assert.Equal(t, int64(42), *first.DefaultTimestamp)
+	assert.Equal(t, int32(10), first.PageSize)
+	assert.Nil(t, options.DefaultTimestamp)
+}
@@ -0,0 +1,270 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Host is a node in a cluster, as described by the system.local and system.peers tables.
+type Host struct {
+	// Address is the address clients should connect to.
+	Address    net.IP
+	Datacenter string
+	Rack       string
+	// Tokens are the Murmur3 tokens owned by the host.
+	Tokens []int64
+}
+
+func (h *Host) String() string {
+	return fmt.Sprintf("%v (%v/%v)", h.Address, h.Datacenter, h.Rack)
+}
+
+// systemHostRow maps a row of system.local, system.peers or system.peers_v2; columns that do not exist in the
+// table remain zero.
+type systemHostRow struct {
+	Peer             net.IP   `cql:"peer"`
+	BroadcastAddress net.IP   `cql:"broadcast_address"`
+	RpcAddress       net.IP   `cql:"rpc_address"`
+	NativeAddress    net.IP   `cql:"native_address"`
+	Datacenter       string   `cql:"data_center"`
+	Rack             string   `cql:"rack"`
+	Tokens           []string `cql:"tokens"`
+}
+
+func (r *systemHostRow) toHost() (*Host, error) {
+	host := &Host{Datacenter: r.Datacenter, Rack: r.Rack}
+	for _, address := range []net.IP{r.RpcAddress, r.NativeAddress, r.BroadcastAddress, r.Peer} {
+		if address != nil && !address.IsUnspecified() {
+			host.Address = address
+			break
+		}
+	}
+	if host.Address == nil {
+		return nil, fmt.Errorf("host has no address")
+	}
+	for _, token := range r.Tokens {
+		if parsed, err := strconv.ParseInt(token, 10, 64); err != nil {
+			return nil, fmt.Errorf("host %v: unsupported token %q, only Murmur3 tokens are supported", host.Address, token)
+		} else {
+			host.Tokens = append(host.Tokens, parsed)
+		}
+	}
+	return host, nil
+}
+
+// NewHostsFromSystemTables returns the hosts described by the given results of "SELECT * FROM system.local" and
+// "SELECT * FROM system.peers" queries; system.peers_v2 results are accepted as well. The peers result can be nil or
+// empty for single-node clusters.
+func NewHostsFromSystemTables(local *message.RowsResult, peers *message.RowsResult, version primitive.ProtocolVersion) ([]*Host, error) {
+	var rows []*systemHostRow
+	if err := datacodec.ScanRows(local, &rows, version); err != nil {
+		return nil, fmt.Errorf("cannot decode system.local: %w", err)
+	} else if len(rows) != 1 {
+		return nil, fmt.Errorf("expected 1 system.local row, got: %d", len(rows))
+	}
+	if peers != nil && len(peers.Data) > 0 {
+		var peerRows []*systemHostRow
+		if err := datacodec.ScanRows(peers, &peerRows, version); err != nil {
+			return nil, fmt.Errorf("cannot decode system.peers: %w", err)
+		}
+		rows = append(rows, peerRows...)
+	}
+	hosts := make([]*Host, len(rows))
+	for i, row := range rows {
+		var err error
+		if hosts[i], err = row.toHost(); err != nil {
+			return nil, err
+		}
+	}
+	return hosts, nil
+}
+
+// ReplicationOptions are the replication options of a keyspace, as found in the replication column of the
+// system_schema.keyspaces table, e.g. {"class": "org.apache.cassandra.locator.SimpleStrategy",
+// "replication_factor": "3"} or {"class": "org.apache.cassandra.locator.NetworkTopologyStrategy", "dc1": "3"}.
+type ReplicationOptions map[string]string
+
+// TokenRing maps tokens to the hosts that own them, and computes the replicas of any token for SimpleStrategy and
+// NetworkTopologyStrategy keyspaces, which enables token-aware routing. Only the Murmur3Partitioner is supported.
+// TokenRing instances must be created with NewTokenRing; they are immutable and safe for concurrent use.
+type TokenRing struct {
+	tokens []int64
+	owners []*Host
+	// racks holds the number of distinct racks in each datacenter.
+	racks map[string]int
+}
+
+// NewTokenRing creates a token ring for the given hosts, typically obtained with NewHostsFromSystemTables.
+func NewTokenRing(hosts []*Host) (*TokenRing, error) {
+	ring := &TokenRing{racks: map[string]int{}}
+	owners := map[int64]*Host{}
+	racks := map[string]map[string]bool{}
+	for _, host := range hosts {
+		for _, token := range host.Tokens {
+			if owner, found := owners[token]; found {
+				return nil, fmt.Errorf("token %d owned by both %v and %v", token, owner, host)
+			}
+			owners[token] = host
+			ring.tokens = append(ring.tokens, token)
+		}
+		if racks[host.Datacenter] == nil {
+			racks[host.Datacenter] = map[string]bool{}
+		}
+		racks[host.Datacenter][host.Rack] = true
+	}
+	if len(ring.tokens) == 0 {
+		return nil, fmt.Errorf("cannot create token ring: no tokens")
+	}
+	sort.Slice(ring.tokens, func(i, j int) bool { return ring.tokens[i] < ring.tokens[j] })
+	ring.owners = make([]*Host, len(ring.tokens))
+	for i, token := range ring.tokens {
+		ring.owners[i] = owners[token]
+	}
+	for dc, dcRacks := range racks {
+		ring.racks[dc] = len(dcRacks)
+	}
+	return ring, nil
+}
+
+// Owner returns the primary replica of the given token: the host owning the smallest ring token that is greater than
+// or equal to the given token, wrapping around the ring.
+func (r *TokenRing) Owner(token int64) *Host {
+	return r.owners[r.ownerIndex(token)]
+}
+
+func (r *TokenRing) ownerIndex(token int64) int {
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= token })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return i
+}
+
+// Replicas returns the replicas of the given token for a keyspace with the given replication options, starting with
+// the primary replica, see Owner.
+func (r *TokenRing) Replicas(token int64, replication ReplicationOptions) ([]*Host, error) {
+	class := replication["class"]
+	switch {
+	case strings.HasSuffix(class, "SimpleStrategy"):
+		rf, err := parseReplicationFactor(replication["replication_factor"])
+		if err != nil {
+			return nil, err
+		}
+		return r.simpleStrategyReplicas(token, rf), nil
+	case strings.HasSuffix(class, "NetworkTopologyStrategy"):
+		rfs := map[string]int{}
+		for dc, value := range replication {
+			if dc != "class" {
+				rf, err := parseReplicationFactor(value)
+				if err != nil {
+					return nil, err
+				}
+				rfs[dc] = rf
+			}
+		}
+		return r.networkTopologyStrategyReplicas(token, rfs), nil
+	}
+	return nil, fmt.Errorf("unsupported replication strategy: %v", class)
+}
+
+// ReplicasForKey is a convenience method that computes the token of the given routing key with Murmur3Token, then
+// returns its replicas, see Replicas.
+func (r *TokenRing) ReplicasForKey(routingKey []byte, replication ReplicationOptions) ([]*Host, error) {
+	return r.Replicas(Murmur3Token(routingKey), replication)
+}
+
+// parseReplicationFactor parses a replication factor, ignoring transient replicas, e.g. "3/1" (Cassandra 4.0+).
+func parseReplicationFactor(value string) (int, error) {
+	if i := strings.IndexByte(value, '/'); i >= 0 {
+		value = value[:i]
+	}
+	rf, err := strconv.Atoi(value)
+	if err != nil || rf < 0 {
+		return 0, fmt.Errorf("invalid replication factor: %q", value)
+	}
+	return rf, nil
+}
+
+func containsHost(hosts []*Host, host *Host) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TokenRing) simpleStrategyReplicas(token int64, rf int) []*Host {
+	var replicas []*Host
+	start := r.ownerIndex(token)
+	for i := 0; i < len(r.owners) && len(replicas) < rf; i++ {
+		if host := r.owners[(start+i)%len(r.owners)]; !containsHost(replicas, host) {
+			replicas = append(replicas, host)
+		}
+	}
+	return replicas
+}
+
+// networkTopologyStrategyReplicas walks the ring from the token owner, and picks replicas in each datacenter on
+// distinct racks first; hosts on racks already holding a replica are only picked once all the racks of their
+// datacenter hold a replica. This is the algorithm of Cassandra's NetworkTopologyStrategy.
+func (r *TokenRing) networkTopologyStrategyReplicas(token int64, rfs map[string]int) []*Host {
+	var replicas []*Host
+	dcReplicas := map[string]int{}
+	seenRacks := map[string]map[string]bool{}
+	skipped := map[string][]*Host{}
+	done := func(dc string) bool { return dcReplicas[dc] >= rfs[dc] }
+	add := func(host *Host) {
+		replicas = append(replicas, host)
+		dcReplicas[host.Datacenter]++
+	}
+	start := r.ownerIndex(token)
+	for i := 0; i < len(r.owners); i++ {
+		host := r.owners[(start+i)%len(r.owners)]
+		dc := host.Datacenter
+		if done(dc) || containsHost(replicas, host) {
+			continue
+		}
+		if seenRacks[dc] == nil {
+			seenRacks[dc] = map[string]bool{}
+		}
+		if len(seenRacks[dc]) == r.racks[dc] {
+			add(host)
+		} else if !seenRacks[dc][host.Rack] {
+			add(host)
+			seenRacks[dc][host.Rack] = true
+			if len(seenRacks[dc]) == r.racks[dc] {
+				for _, skippedHost := range skipped[dc] {
+					if done(dc) {
+						break
+					}
+					add(skippedHost)
+				}
+			}
+		} else if !containsHost(skipped[dc], host) {
+			skipped[dc] = append(skipped[dc], host)
+		}
+	}
+	return replicas
+}
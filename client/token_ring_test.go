@@ -0,0 +1,185 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"math"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestMurmur3Token(t *testing.T) {
+	tests := []struct {
+		key      []byte
+		expected int64
+	}{
+		{nil, math.MinInt64},
+		{[]byte{}, math.MinInt64},
+		{[]byte{0, 0, 0, 1}, -4069959284402364209},
+		{[]byte("hello"), -3758069500696749310},
+		{[]byte("The quick brown fox jumps over the lazy dog."), -3631792323850337591},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, client.Murmur3Token(tt.key), "%q", tt.key)
+	}
+}
+
+func TestCompositeRoutingKey(t *testing.T) {
+	assert.Equal(t, []byte{1, 2}, client.CompositeRoutingKey([]byte{1, 2}))
+	assert.Equal(t, []byte{0, 1, 1, 0, 0, 2, 2, 3, 0}, client.CompositeRoutingKey([]byte{1}, []byte{2, 3}))
+}
+
+func TestTokenRing_SimpleStrategy(t *testing.T) {
+	host1 := &client.Host{Address: net.IPv4(10, 0, 0, 1), Datacenter: "dc1", Tokens: []int64{-100, 100}}
+	host2 := &client.Host{Address: net.IPv4(10, 0, 0, 2), Datacenter: "dc1", Tokens: []int64{0}}
+	host3 := &client.Host{Address: net.IPv4(10, 0, 0, 3), Datacenter: "dc1", Tokens: []int64{50}}
+	ring, err := client.NewTokenRing([]*client.Host{host1, host2, host3})
+	require.NoError(t, err)
+	replication := client.ReplicationOptions{"class": "org.apache.cassandra.locator.SimpleStrategy", "replication_factor": "2"}
+	tests := []struct {
+		token    int64
+		expected []*client.Host
+	}{
+		{-1000, []*client.Host{host1, host2}},
+		{-100, []*client.Host{host1, host2}},
+		{-99, []*client.Host{host2, host3}},
+		{50, []*client.Host{host3, host1}},
+		{1000, []*client.Host{host1, host2}},
+	}
+	for _, tt := range tests {
+		replicas, err := ring.Replicas(tt.token, replication)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, replicas, "token %d", tt.token)
+		assert.Equal(t, tt.expected[0], ring.Owner(tt.token))
+	}
+	replicas, err := ring.Replicas(0, client.ReplicationOptions{"class": "SimpleStrategy", "replication_factor": "5"})
+	require.NoError(t, err)
+	assert.Equal(t, []*client.Host{host2, host3, host1}, replicas)
+	_, err = ring.Replicas(0, client.ReplicationOptions{"class": "LocalStrategy"})
+	assert.EqualError(t, err, "unsupported replication strategy: LocalStrategy")
+	_, err = ring.Replicas(0, client.ReplicationOptions{"class": "SimpleStrategy", "replication_factor": "x"})
+	assert.EqualError(t, err, `invalid replication factor: "x"`)
+}
+
+func TestTokenRing_NetworkTopologyStrategy(t *testing.T) {
+	// ring order: a1 (dc1/r1), b1 (dc2/r1), a2 (dc1/r1), a3 (dc1/r2), b2 (dc2/r1)
+	a1 := &client.Host{Address: net.IPv4(10, 0, 1, 1), Datacenter: "dc1", Rack: "r1", Tokens: []int64{0}}
+	b1 := &client.Host{Address: net.IPv4(10, 0, 2, 1), Datacenter: "dc2", Rack: "r1", Tokens: []int64{10}}
+	a2 := &client.Host{Address: net.IPv4(10, 0, 1, 2), Datacenter: "dc1", Rack: "r1", Tokens: []int64{20}}
+	a3 := &client.Host{Address: net.IPv4(10, 0, 1, 3), Datacenter: "dc1", Rack: "r2", Tokens: []int64{30}}
+	b2 := &client.Host{Address: net.IPv4(10, 0, 2, 2), Datacenter: "dc2", Rack: "r1", Tokens: []int64{40}}
+	ring, err := client.NewTokenRing([]*client.Host{a1, b1, a2, a3, b2})
+	require.NoError(t, err)
+	replication := client.ReplicationOptions{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "2",
+		"dc2":   "1",
+	}
+	replicas, err := ring.Replicas(0, replication)
+	require.NoError(t, err)
+	// a2 is skipped in favor of a3, which is on a distinct rack
+	assert.Equal(t, []*client.Host{a1, b1, a3}, replicas)
+	replication["dc1"] = "3/1"
+	replicas, err = ring.Replicas(15, replication)
+	require.NoError(t, err)
+	assert.Equal(t, []*client.Host{a2, a3, b2, a1}, replicas)
+	replicas, err = ring.ReplicasForKey([]byte{0, 0, 0, 1}, client.ReplicationOptions{"class": "NetworkTopologyStrategy", "dc2": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, []*client.Host{b1, b2}, replicas)
+}
+
+func TestNewTokenRing_Errors(t *testing.T) {
+	_, err := client.NewTokenRing(nil)
+	assert.EqualError(t, err, "cannot create token ring: no tokens")
+	host1 := &client.Host{Address: net.IPv4(10, 0, 0, 1), Tokens: []int64{1}}
+	host2 := &client.Host{Address: net.IPv4(10, 0, 0, 2), Tokens: []int64{1}}
+	_, err = client.NewTokenRing([]*client.Host{host1, host2})
+	assert.EqualError(t, err, "token 1 owned by both 10.0.0.1 (/) and 10.0.0.2 (/)")
+}
+
+func TestNewHostsFromSystemTables(t *testing.T) {
+	handler := client.NewSystemTablesHandler("cluster_test", "datacenter_test")
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{handler}, nil)
+	query := func(q string) *message.RowsResult {
+		response, err := clientConn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Query{Query: q}))
+		require.NoError(t, err)
+		require.IsType(t, &message.RowsResult{}, response.Body.Message)
+		return response.Body.Message.(*message.RowsResult)
+	}
+	local := query("SELECT * FROM system.local")
+	peers := query("SELECT * FROM system.peers")
+	hosts, err := client.NewHostsFromSystemTables(local, peers, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	assert.True(t, hosts[0].Address.Equal(net.IPv4(127, 0, 0, 1)))
+	assert.Equal(t, "datacenter_test", hosts[0].Datacenter)
+	assert.Equal(t, "rack1", hosts[0].Rack)
+	assert.Equal(t, []int64{-9223372036854775808}, hosts[0].Tokens)
+	cancelFn()
+	checkClosed(t, clientConn, server)
+
+	// peers with an unspecified rpc address
+	column := func(name string, dt datatype.DataType) *message.ColumnMetadata {
+		return &message.ColumnMetadata{Keyspace: "system", Table: "peers", Name: name, Type: dt}
+	}
+	encode := func(value interface{}, dt datatype.DataType) message.Column {
+		codec, err := datacodec.NewCodec(dt)
+		require.NoError(t, err)
+		encoded, err := codec.Encode(value, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		return encoded
+	}
+	tokensType := datatype.NewSet(datatype.Varchar)
+	peers = &message.RowsResult{
+		Metadata: &message.RowsMetadata{
+			ColumnCount: 5,
+			Columns: []*message.ColumnMetadata{
+				column("peer", datatype.Inet),
+				column("rpc_address", datatype.Inet),
+				column("data_center", datatype.Varchar),
+				column("rack", datatype.Varchar),
+				column("tokens", tokensType),
+			},
+		},
+		Data: message.RowSet{{
+			encode(net.IPv4(10, 0, 0, 2), datatype.Inet),
+			encode(net.IPv4zero, datatype.Inet),
+			encode("dc2", datatype.Varchar),
+			encode("r2", datatype.Varchar),
+			encode([]string{"42", "-42"}, tokensType),
+		}},
+	}
+	hosts, err = client.NewHostsFromSystemTables(local, peers, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	require.Len(t, hosts, 2)
+	assert.True(t, hosts[1].Address.Equal(net.IPv4(10, 0, 0, 2)))
+	assert.Equal(t, "dc2", hosts[1].Datacenter)
+	assert.Equal(t, "r2", hosts[1].Rack)
+	assert.ElementsMatch(t, []int64{42, -42}, hosts[1].Tokens)
+
+	peers.Data[0][4] = encode([]string{"not a token"}, tokensType)
+	_, err = client.NewHostsFromSystemTables(local, peers, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, `host 10.0.0.2: unsupported token "not a token", only Murmur3 tokens are supported`)
+}
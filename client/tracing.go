@@ -0,0 +1,211 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ErrTraceNotReady is returned by CqlClientConnection.RetrieveTrace and DecodeTraceSession when the trace session was
+// not found, or was found but is not yet complete. Traces are written asynchronously by the server, so the retrieval
+// should be retried later.
+var ErrTraceNotReady = errors.New("trace not ready")
+
+const (
+	traceSessionQuery = "SELECT * FROM system_traces.sessions WHERE session_id = ?"
+	traceEventsQuery  = "SELECT * FROM system_traces.events WHERE session_id = ?"
+)
+
+// TraceSession is a query trace session, as stored in the system_traces.sessions table.
+type TraceSession struct {
+	SessionId   primitive.UUID
+	Request     string
+	Command     string
+	Client      net.IP
+	Coordinator net.IP
+	StartedAt   time.Time
+	// Duration is the total duration of the traced request, as measured by the coordinator.
+	Duration   time.Duration
+	Parameters map[string]string
+}
+
+// Trace is a query trace, as stored in the system_traces.sessions and system_traces.events tables.
+type Trace struct {
+	TraceSession
+	// Events are the trace events, in the order they were recorded.
+	Events []*TraceEvent
+}
+
+// TraceEvent is an event in a query trace, as stored in the system_traces.events table.
+type TraceEvent struct {
+	EventId  primitive.UUID
+	Activity string
+	Source   net.IP
+	// SourceElapsed is the time elapsed on the source node since the beginning of the traced request.
+	SourceElapsed time.Duration
+	Thread        string
+}
+
+type traceSessionRow struct {
+	SessionId   primitive.UUID    `cql:"session_id"`
+	Request     string            `cql:"request"`
+	Command     string            `cql:"command"`
+	Client      net.IP            `cql:"client"`
+	Coordinator net.IP            `cql:"coordinator"`
+	StartedAt   time.Time         `cql:"started_at"`
+	Duration    *int32            `cql:"duration"`
+	Parameters  map[string]string `cql:"parameters"`
+}
+
+type traceEventRow struct {
+	EventId       primitive.UUID `cql:"event_id"`
+	Activity      string         `cql:"activity"`
+	Source        net.IP         `cql:"source"`
+	SourceElapsed int32          `cql:"source_elapsed"`
+	Thread        string         `cql:"thread"`
+}
+
+// NewTraceSessionQuery creates the QUERY request frame that fetches the system_traces.sessions row of the trace
+// identified by the given tracing id. Its RESULT Rows response can be decoded with DecodeTraceSession. An error is
+// returned if the tracing id is nil, e.g. when it was obtained from a response without tracing.
+func NewTraceSessionQuery(
+	tracingId *primitive.UUID,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (*frame.Frame, error) {
+	return newTraceQuery(traceSessionQuery, tracingId, version, streamId)
+}
+
+// NewTraceEventsQuery creates the QUERY request frame that fetches the system_traces.events rows of the trace
+// identified by the given tracing id. Its RESULT Rows response can be decoded with DecodeTraceEvents. An error is
+// returned if the tracing id is nil, e.g. when it was obtained from a response without tracing.
+func NewTraceEventsQuery(
+	tracingId *primitive.UUID,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (*frame.Frame, error) {
+	return newTraceQuery(traceEventsQuery, tracingId, version, streamId)
+}
+
+func newTraceQuery(
+	query string,
+	tracingId *primitive.UUID,
+	version primitive.ProtocolVersion,
+	streamId int16,
+) (*frame.Frame, error) {
+	if tracingId == nil {
+		return nil, errors.New("cannot create trace query: tracing id cannot be nil")
+	}
+	return frame.NewFrame(version, streamId, &message.Query{
+		Query: query,
+		Options: &message.QueryOptions{
+			Consistency:      primitive.ConsistencyLevelOne,
+			PositionalValues: []*primitive.Value{primitive.NewValue(tracingId[:])},
+		},
+	}), nil
+}
+
+// DecodeTraceSession decodes the response to a request created with NewTraceSessionQuery. If the result contains no
+// rows, or if the trace session is not complete yet, ErrTraceNotReady is returned.
+func DecodeTraceSession(rows *message.RowsResult, version primitive.ProtocolVersion) (*TraceSession, error) {
+	var sessions []*traceSessionRow
+	if err := datacodec.ScanRows(rows, &sessions, version); err != nil {
+		return nil, fmt.Errorf("cannot decode trace session: %w", err)
+	} else if len(sessions) == 0 || sessions[0].Duration == nil {
+		return nil, ErrTraceNotReady
+	}
+	session := sessions[0]
+	return &TraceSession{
+		SessionId:   session.SessionId,
+		Request:     session.Request,
+		Command:     session.Command,
+		Client:      session.Client,
+		Coordinator: session.Coordinator,
+		StartedAt:   session.StartedAt,
+		Duration:    time.Duration(*session.Duration) * time.Microsecond,
+		Parameters:  session.Parameters,
+	}, nil
+}
+
+// DecodeTraceEvents decodes the response to a request created with NewTraceEventsQuery.
+func DecodeTraceEvents(rows *message.RowsResult, version primitive.ProtocolVersion) ([]*TraceEvent, error) {
+	var events []*traceEventRow
+	if err := datacodec.ScanRows(rows, &events, version); err != nil {
+		return nil, fmt.Errorf("cannot decode trace events: %w", err)
+	}
+	decoded := make([]*TraceEvent, len(events))
+	for i, event := range events {
+		decoded[i] = &TraceEvent{
+			EventId:       event.EventId,
+			Activity:      event.Activity,
+			Source:        event.Source,
+			SourceElapsed: time.Duration(event.SourceElapsed) * time.Microsecond,
+			Thread:        event.Thread,
+		}
+	}
+	return decoded, nil
+}
+
+// RetrieveTrace queries the system_traces.sessions and system_traces.events tables over this connection to retrieve
+// the trace identified by the given tracing id, typically obtained from the tracing id of a response frame, see
+// frame.Frame.RequestTracingId. The queries are executed with the given protocol version.
+//
+// If the trace session does not exist yet, or is not complete, ErrTraceNotReady is returned.
+func (c *CqlClientConnection) RetrieveTrace(tracingId *primitive.UUID, version primitive.ProtocolVersion) (*Trace, error) {
+	request, err := NewTraceSessionQuery(tracingId, version, ManagedStreamId)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", c, err)
+	}
+	rows, err := c.queryTrace(request, tracingId)
+	if err != nil {
+		return nil, err
+	}
+	session, err := DecodeTraceSession(rows, version)
+	if err != nil {
+		return nil, fmt.Errorf("%v: trace %v: %w", c, tracingId, err)
+	}
+	if request, err = NewTraceEventsQuery(tracingId, version, ManagedStreamId); err != nil {
+		return nil, fmt.Errorf("%v: %w", c, err)
+	} else if rows, err = c.queryTrace(request, tracingId); err != nil {
+		return nil, err
+	}
+	events, err := DecodeTraceEvents(rows, version)
+	if err != nil {
+		return nil, fmt.Errorf("%v: trace %v: %w", c, tracingId, err)
+	}
+	return &Trace{TraceSession: *session, Events: events}, nil
+}
+
+func (c *CqlClientConnection) queryTrace(request *frame.Frame, tracingId *primitive.UUID) (*message.RowsResult, error) {
+	response, err := c.SendAndReceive(request)
+	if err != nil {
+		return nil, fmt.Errorf("%v: cannot retrieve trace %v: %w", c, tracingId, err)
+	} else if response == nil {
+		return nil, fmt.Errorf("%v: cannot retrieve trace %v: no response received", c, tracingId)
+	}
+	rows, ok := response.Body.Message.(*message.RowsResult)
+	if !ok {
+		return nil, fmt.Errorf("%v: cannot retrieve trace %v: expected RESULT Rows, got: %v", c, tracingId, response.Body.Message)
+	}
+	return rows, nil
+}
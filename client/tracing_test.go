@@ -0,0 +1,175 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	traceSessionId, _ = primitive.ParseUuid("a1b2c3d4-0000-1000-8000-000000000001")
+	traceEventId, _   = primitive.ParseUuid("a1b2c3d4-0000-1000-8000-000000000002")
+	traceStartedAt    = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	traceCoordinator  = net.ParseIP("192.168.1.1").To4()
+)
+
+// newTraceHandler emulates the system_traces tables; if complete is false, the session duration is NULL.
+func newTraceHandler(t *testing.T, complete bool) client.RequestHandler {
+	column := func(table, name string, dt datatype.DataType) *message.ColumnMetadata {
+		return &message.ColumnMetadata{Keyspace: "system_traces", Table: table, Name: name, Type: dt}
+	}
+	encode := func(values ...interface{}) message.Row {
+		row := make(message.Row, len(values))
+		for i, value := range values {
+			if value != nil {
+				dt, err := datacodec.InferDataType(value)
+				require.NoError(t, err)
+				codec, err := datacodec.NewCodec(dt)
+				require.NoError(t, err)
+				row[i], err = codec.Encode(value, primitive.ProtocolVersion4)
+				require.NoError(t, err)
+			}
+		}
+		return row
+	}
+	return func(request *frame.Frame, conn *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+		query, ok := request.Body.Message.(*message.Query)
+		if !ok || len(query.Options.PositionalValues) != 1 {
+			return nil
+		}
+		assert.Equal(t, traceSessionId[:], query.Options.PositionalValues[0].Contents)
+		var result *message.RowsResult
+		if strings.Contains(query.Query, "system_traces.sessions") {
+			var duration interface{}
+			if complete {
+				duration = int32(1500)
+			}
+			result = &message.RowsResult{
+				Metadata: &message.RowsMetadata{
+					ColumnCount: 5,
+					Columns: []*message.ColumnMetadata{
+						column("sessions", "session_id", datatype.Uuid),
+						column("sessions", "coordinator", datatype.Inet),
+						column("sessions", "duration", datatype.Int),
+						column("sessions", "request", datatype.Varchar),
+						column("sessions", "started_at", datatype.Timestamp),
+					},
+				},
+				Data: message.RowSet{encode(*traceSessionId, traceCoordinator, duration, "Execute CQL3 query", traceStartedAt)},
+			}
+		} else if strings.Contains(query.Query, "system_traces.events") {
+			result = &message.RowsResult{
+				Metadata: &message.RowsMetadata{
+					ColumnCount: 4,
+					Columns: []*message.ColumnMetadata{
+						column("events", "session_id", datatype.Uuid),
+						column("events", "event_id", datatype.Timeuuid),
+						column("events", "activity", datatype.Varchar),
+						column("events", "source_elapsed", datatype.Int),
+					},
+				},
+				Data: message.RowSet{encode(*traceSessionId, *traceEventId, "Parsing query", int32(250))},
+			}
+		} else {
+			return nil
+		}
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, result)
+	}
+}
+
+func TestCqlClientConnection_RetrieveTrace(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{newTraceHandler(t, true)}, nil)
+
+	trace, err := clientConn.RetrieveTrace(traceSessionId, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, *traceSessionId, trace.SessionId)
+	assert.Equal(t, "Execute CQL3 query", trace.Request)
+	assert.Equal(t, traceCoordinator, trace.Coordinator)
+	assert.True(t, traceStartedAt.Equal(trace.StartedAt))
+	assert.Equal(t, 1500*time.Microsecond, trace.Duration)
+	require.Len(t, trace.Events, 1)
+	assert.Equal(t, *traceEventId, trace.Events[0].EventId)
+	assert.Equal(t, "Parsing query", trace.Events[0].Activity)
+	assert.Equal(t, 250*time.Microsecond, trace.Events[0].SourceElapsed)
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestCqlClientConnection_RetrieveTrace_NotReady(t *testing.T) {
+	server, clientConn, cancelFn := createServerAndClient(t, []client.RequestHandler{newTraceHandler(t, false)}, nil)
+
+	_, err := clientConn.RetrieveTrace(traceSessionId, primitive.ProtocolVersion4)
+	assert.True(t, errors.Is(err, client.ErrTraceNotReady))
+
+	cancelFn()
+	checkClosed(t, clientConn, server)
+}
+
+func TestTraceQueries(t *testing.T) {
+	handler := newTraceHandler(t, true)
+	version := primitive.ProtocolVersion4
+
+	request, err := client.NewTraceSessionQuery(traceSessionId, version, 42)
+	require.NoError(t, err)
+	assert.Equal(t, int16(42), request.Header.StreamId)
+	query := request.Body.Message.(*message.Query)
+	assert.Equal(t, "SELECT * FROM system_traces.sessions WHERE session_id = ?", query.Query)
+	assert.Equal(t, primitive.ConsistencyLevelOne, query.Options.Consistency)
+	response := handler(request, nil, nil)
+	require.NotNil(t, response)
+	session, err := client.DecodeTraceSession(response.Body.Message.(*message.RowsResult), version)
+	require.NoError(t, err)
+	assert.Equal(t, *traceSessionId, session.SessionId)
+	assert.Equal(t, traceCoordinator, session.Coordinator)
+	assert.Equal(t, 1500*time.Microsecond, session.Duration)
+
+	request, err = client.NewTraceEventsQuery(traceSessionId, version, 43)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM system_traces.events WHERE session_id = ?", request.Body.Message.(*message.Query).Query)
+	response = handler(request, nil, nil)
+	require.NotNil(t, response)
+	events, err := client.DecodeTraceEvents(response.Body.Message.(*message.RowsResult), version)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, *traceEventId, events[0].EventId)
+	assert.Equal(t, 250*time.Microsecond, events[0].SourceElapsed)
+
+	_, err = client.DecodeTraceSession(&message.RowsResult{Metadata: &message.RowsMetadata{Columns: []*message.ColumnMetadata{}}}, version)
+	assert.True(t, errors.Is(err, client.ErrTraceNotReady))
+
+	// a nil tracing id, e.g. from a response without tracing, is rejected
+	untraced := frame.NewFrame(version, 1, &message.VoidResult{})
+	_, err = client.NewTraceSessionQuery(untraced.Body.TracingId, version, 44)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing id cannot be nil")
+	_, err = client.NewTraceEventsQuery(nil, version, 45)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing id cannot be nil")
+}
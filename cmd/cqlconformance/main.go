@@ -0,0 +1,61 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cqlconformance checks that a CQL-speaking server conforms to the native protocol specification, and prints
+// a conformance report. It exits with a non-zero status if any check failed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/conformance"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:9042", "the address of the server to check")
+	username := flag.String("username", "", "the username to use if the server requires authentication")
+	password := flag.String("password", "", "the password to use if the server requires authentication")
+	versions := flag.String("versions", "", "a comma-separated list of protocol versions to check, e.g. 3,4,5; defaults to all OSS versions")
+	timeout := flag.Duration("timeout", conformance.DefaultCheckTimeout, "the timeout to apply when connecting and when waiting for a response")
+	flag.Parse()
+
+	var credentials *client.AuthCredentials
+	if *username != "" {
+		credentials = &client.AuthCredentials{Username: *username, Password: *password}
+	}
+	checker := conformance.NewServerChecker(*address, credentials)
+	checker.Timeout = *timeout
+	if *versions != "" {
+		checker.Versions = nil
+		for _, s := range strings.Split(*versions, ",") {
+			version, err := strconv.ParseUint(strings.TrimSpace(s), 0, 8)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "invalid protocol version: %v\n", s)
+				os.Exit(2)
+			}
+			checker.Versions = append(checker.Versions, primitive.ProtocolVersion(version))
+		}
+	}
+	report := checker.Run()
+	_, _ = report.WriteTo(os.Stdout)
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cqlreplay replays the client side of a frame capture against a CQL-speaking server, and prints a replay
+// report listing the responses that do not match the captured ones. It exits with a non-zero status if any request
+// failed or got a mismatching response. See package replay for the capture format, and for how to create captures from
+// the frames recorded by a client.FrameRecorder.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/replay"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:9042", "the address of the server to replay the capture against")
+	username := flag.String("username", "", "the username to use if the server requires authentication")
+	password := flag.String("password", "", "the password to use if the server requires authentication")
+	capturePath := flag.String("capture", "-", "the capture file to replay, or - to read it from the standard input")
+	speed := flag.Float64("speed", 0, "the pacing factor applied to the capture timeline, e.g. 1 for the captured pace, 2 for twice as fast; 0 replays as fast as possible")
+	interval := flag.Duration("interval", 0, "the minimum delay between two successive requests")
+	concurrent := flag.Bool("concurrent", false, "send requests without waiting for the response to the previous request")
+	strict := flag.Bool("strict", false, "compare response bodies field by field, instead of comparing response kinds only")
+	timeout := flag.Duration("timeout", replay.DefaultReplayTimeout, "the timeout to apply when connecting and when waiting for a response")
+	flag.Parse()
+
+	capture, err := readCapture(*capturePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var credentials *client.AuthCredentials
+	if *username != "" {
+		credentials = &client.AuthCredentials{Username: *username, Password: *password}
+	}
+	replayer := replay.NewReplayer(*address, credentials)
+	replayer.Speed = *speed
+	replayer.Interval = *interval
+	replayer.Concurrent = *concurrent
+	replayer.Timeout = *timeout
+	if *strict {
+		replayer.Compare = replay.CompareResponsesStrict
+	}
+	report, err := replayer.Run(context.Background(), capture)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	_, _ = report.WriteTo(os.Stdout)
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
+
+func readCapture(path string) (*replay.Capture, error) {
+	if path == "-" {
+		return replay.ReadCapture(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open capture: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	return replay.ReadCapture(file)
+}
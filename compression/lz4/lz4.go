@@ -94,7 +94,42 @@ func (c Compressor) DecompressWithLength(source io.Reader, dest io.Writer) error
 		}
 		return nil
 	}
-	return c.Decompress(source, dest)
+	if compressedMessage, err := bufferFromReader(source); err != nil {
+		return fmt.Errorf("cannot read compressed message: %w", err)
+	} else if maxLength := maxDecompressedLength(len(compressedMessage)); int64(decompressedLength) > maxLength {
+		// the declared length cannot be trusted before allocating: reject lengths that LZ4 cannot possibly reach
+		return fmt.Errorf("cannot decompress message: declared length %d exceeds the maximum of %d bytes for %d compressed bytes",
+			decompressedLength, maxLength, len(compressedMessage))
+	} else {
+		// the decompressed length is known: allocate exactly that, and reject messages that do not match it
+		decompressedMessage := make([]byte, decompressedLength)
+		if written, err := lz4.UncompressBlock(compressedMessage, decompressedMessage); err != nil {
+			return fmt.Errorf("cannot decompress message: %w", err)
+		} else if written != int(decompressedLength) {
+			return fmt.Errorf("cannot decompress message: expected %d bytes, got %d", decompressedLength, written)
+		} else if _, err := dest.Write(decompressedMessage); err != nil {
+			return fmt.Errorf("cannot write decompressed message: %w", err)
+		}
+		return nil
+	}
+}
+
+// DecompressedLength satisfies frame.DecompressedLengthReader: it returns the decompressed length stored in the 4
+// first bytes of the given compressed body.
+func (c Compressor) DecompressedLength(compressedBody []byte) (int, error) {
+	if len(compressedBody) < 4 {
+		return 0, fmt.Errorf("cannot read compressed length: expected 4 bytes, got %d", len(compressedBody))
+	}
+	return int(binary.BigEndian.Uint32(compressedBody)), nil
+}
+
+// maxCompressionRatio is the maximum expansion ratio of LZ4 blocks: each byte of a match length can extend a match by
+// at most 255 bytes.
+const maxCompressionRatio = 255
+
+// maxDecompressedLength returns the maximum length that the given number of compressed bytes can decompress to.
+func maxDecompressedLength(compressedLength int) int64 {
+	return int64(compressedLength) * maxCompressionRatio
 }
 
 func decompress(source []byte) (dest []byte, err error) {
@@ -50,6 +50,16 @@ func (l Compressor) DecompressWithLength(source io.Reader, dest io.Writer) error
 	}
 }
 
+// DecompressedLength satisfies frame.DecompressedLengthReader: it returns the decompressed length stored in the header
+// of the given compressed body.
+func (l Compressor) DecompressedLength(compressedBody []byte) (int, error) {
+	if length, err := snappy.DecodedLen(compressedBody); err != nil {
+		return 0, fmt.Errorf("cannot read decompressed length: %w", err)
+	} else {
+		return length, nil
+	}
+}
+
 func bufferFromReader(source io.Reader) (*bytes.Buffer, error) {
 	var buf *bytes.Buffer
 	switch s := source.(type) {
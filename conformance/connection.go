@@ -0,0 +1,173 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+	"github.com/datastax/go-cassandra-native-protocol/segment"
+)
+
+// checkStreamId is the stream id used for all requests: connections are synchronous, and send one request at a time.
+const checkStreamId int16 = 1
+
+// connection is a minimal, synchronous connection that can send arbitrary bytes to the server, including malformed
+// frames that the client package would refuse to encode.
+type connection struct {
+	conn         net.Conn
+	version      primitive.ProtocolVersion
+	codec        frame.RawCodec
+	segmentCodec segment.Codec
+	modernLayout bool
+	timeout      time.Duration
+}
+
+func dial(address string, version primitive.ProtocolVersion, timeout time.Duration) (*connection, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %v: %w", address, err)
+	}
+	return &connection{
+		conn:         conn,
+		version:      version,
+		codec:        frame.NewClientCodec(),
+		segmentCodec: segment.NewCodec(),
+		timeout:      timeout,
+	}, nil
+}
+
+// handshake sends a STARTUP request, authenticates if required, and switches to the modern framing layout if the
+// protocol version requires so.
+func (c *connection) handshake(credentials *client.AuthCredentials) error {
+	response, err := c.send(message.NewStartup())
+	if err != nil {
+		return err
+	}
+	if authenticate, ok := response.Body.Message.(*message.Authenticate); ok {
+		if credentials == nil {
+			return fmt.Errorf("server requires authentication with %v, but no credentials were provided", authenticate.Authenticator)
+		}
+		authenticator := &client.PlainTextAuthenticator{Credentials: credentials}
+		token, err := authenticator.InitialResponse(authenticate.Authenticator)
+		for err == nil {
+			if response, err = c.send(&message.AuthResponse{Token: token}); err != nil {
+				return err
+			}
+			challenge, ok := response.Body.Message.(*message.AuthChallenge)
+			if !ok {
+				break
+			}
+			token, err = authenticator.EvaluateChallenge(challenge.Token)
+		}
+		if err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		} else if _, ok := response.Body.Message.(*message.AuthSuccess); !ok {
+			return fmt.Errorf("expected AUTH_SUCCESS, got: %v", response.Body.Message)
+		}
+	} else if _, ok := response.Body.Message.(*message.Ready); !ok {
+		return fmt.Errorf("expected READY or AUTHENTICATE, got: %v", response.Body.Message)
+	}
+	c.modernLayout = c.version.SupportsModernFramingLayout()
+	return nil
+}
+
+// send sends the given message and returns the response.
+func (c *connection) send(msg message.Message) (*frame.Frame, error) {
+	encoded, err := c.encode(msg)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendRaw(encoded)
+}
+
+// encode encodes the given message as a request frame, without sending it, so that the encoded frame can be altered
+// before being sent with sendRaw.
+func (c *connection) encode(msg message.Message) ([]byte, error) {
+	encoded := &bytes.Buffer{}
+	if err := c.codec.EncodeFrame(frame.NewFrame(c.version, checkStreamId, msg), encoded); err != nil {
+		return nil, fmt.Errorf("cannot encode request: %w", err)
+	}
+	return encoded.Bytes(), nil
+}
+
+// sendRaw sends the given encoded frame and returns the response.
+func (c *connection) sendRaw(encodedFrame []byte) (*frame.Frame, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if err := c.write(encodedFrame); err != nil {
+		return nil, fmt.Errorf("cannot write request: %w", err)
+	}
+	response, err := c.read()
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("no response received within %v", c.timeout)
+		} else if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+			return nil, fmt.Errorf("server closed the connection without responding")
+		}
+		return nil, fmt.Errorf("cannot read response: %w", err)
+	}
+	return response, nil
+}
+
+func (c *connection) write(encodedFrame []byte) error {
+	if !c.modernLayout {
+		_, err := c.conn.Write(encodedFrame)
+		return err
+	}
+	return c.segmentCodec.EncodeSegment(&segment.Segment{
+		Header:  &segment.Header{IsSelfContained: true},
+		Payload: &segment.Payload{UncompressedData: encodedFrame},
+	}, c.conn)
+}
+
+func (c *connection) read() (*frame.Frame, error) {
+	if !c.modernLayout {
+		return c.codec.DecodeFrame(c.conn)
+	}
+	encodedFrame := &bytes.Buffer{}
+	for {
+		seg, err := c.segmentCodec.DecodeSegment(c.conn)
+		if err != nil {
+			return nil, err
+		}
+		encodedFrame.Write(seg.Payload.UncompressedData)
+		if seg.Header.IsSelfContained {
+			break
+		}
+		header, err := c.codec.DecodeHeader(bytes.NewReader(encodedFrame.Bytes()))
+		if err != nil {
+			return nil, err
+		} else if encodedFrame.Len() >= c.version.FrameHeaderLengthInBytes()+int(header.BodyLength) {
+			break
+		}
+	}
+	return c.codec.DecodeFrame(encodedFrame)
+}
+
+func (c *connection) close() {
+	_ = c.conn.Close()
+}
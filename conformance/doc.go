@@ -0,0 +1,23 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance contains tools to check that implementations of the CQL native protocol conform to the
+// specification.
+//
+// ServerChecker connects to a CQL-speaking server (Cassandra, DSE, Scylla, proxies, emulators, etc.), exercises the
+// protocol surface, including malformed frames and error paths, and produces a conformance Report. The same checks are
+// available from the command line with the cqlconformance tool:
+//
+//  go run github.com/datastax/go-cassandra-native-protocol/cmd/cqlconformance -address 127.0.0.1:9042
+package conformance
@@ -0,0 +1,107 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Outcome is the outcome of a single conformance check.
+type Outcome string
+
+const (
+	OutcomePassed  = Outcome("PASSED")
+	OutcomeFailed  = Outcome("FAILED")
+	OutcomeSkipped = Outcome("SKIPPED")
+)
+
+// CheckResult is the result of a single conformance check, for a given protocol version.
+type CheckResult struct {
+	// Name is the name of the check, e.g. "unknown-opcode".
+	Name string
+	// Description is a short description of the expected behavior.
+	Description string
+	// Version is the protocol version the check was run with; it is zero for checks that do not depend on a protocol
+	// version.
+	Version primitive.ProtocolVersion
+	// Outcome is the outcome of the check.
+	Outcome Outcome
+	// Details contains additional information about the outcome, e.g. the actual server response when the check
+	// failed.
+	Details string
+}
+
+func (r *CheckResult) String() string {
+	return fmt.Sprintf("%v [%v] %v: %v", r.Name, r.Version, r.Outcome, r.Details)
+}
+
+// Report is a conformance report, as produced by a conformance checker.
+type Report struct {
+	// Target is a description of the checked implementation, e.g. the address of the server.
+	Target string
+	// Results contains the result of each check, in the order the checks were run.
+	Results []*CheckResult
+}
+
+// Count returns the number of checks with the given outcome.
+func (r *Report) Count(outcome Outcome) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// Passed returns true if no check failed.
+func (r *Report) Passed() bool {
+	return r.Count(OutcomeFailed) == 0
+}
+
+// Find returns the result of the check with the given name and protocol version, or nil if no such check was run.
+func (r *Report) Find(name string, version primitive.ProtocolVersion) *CheckResult {
+	for _, result := range r.Results {
+		if result.Name == name && result.Version == version {
+			return result
+		}
+	}
+	return nil
+}
+
+// WriteTo writes a human-readable version of this report to the given writer.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	sb := &strings.Builder{}
+	_, _ = fmt.Fprintf(sb, "Conformance report for %v\n\n", r.Target)
+	tw := tabwriter.NewWriter(sb, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHECK\tVERSION\tOUTCOME\tDETAILS")
+	for _, result := range r.Results {
+		version := "-"
+		if result.Version != 0 {
+			version = fmt.Sprintf("%d", result.Version)
+		}
+		_, _ = fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", result.Name, version, result.Outcome, result.Details)
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintf(sb, "\n%d passed, %d failed, %d skipped\n",
+		r.Count(OutcomePassed), r.Count(OutcomeFailed), r.Count(OutcomeSkipped))
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
@@ -0,0 +1,361 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const DefaultCheckTimeout = 10 * time.Second
+
+// ServerChecker checks that a CQL-speaking server (Cassandra, DSE, Scylla, proxies, emulators, etc.) conforms to the
+// native protocol specification, by exercising the protocol surface: OPTIONS, STARTUP variants, malformed frames and
+// error paths. It is preferable to create ServerChecker instances using the constructor function NewServerChecker.
+type ServerChecker struct {
+	// Address is the address of the server to check.
+	Address string
+	// Credentials are the credentials to use if the server requires authentication; leave nil if the server does not
+	// require authentication. If the server requires authentication, checks are also run with wrong credentials.
+	Credentials *client.AuthCredentials
+	// Versions are the protocol versions to check. Versions not supported by the server are reported as skipped.
+	Versions []primitive.ProtocolVersion
+	// Timeout is the timeout to apply when connecting and when waiting for a response.
+	Timeout time.Duration
+}
+
+// NewServerChecker creates a new ServerChecker for the server at the given address, checking all the OSS protocol
+// versions supported by this library. Leave credentials nil if the server does not require authentication.
+func NewServerChecker(address string, credentials *client.AuthCredentials) *ServerChecker {
+	return &ServerChecker{
+		Address:     address,
+		Credentials: credentials,
+		Versions:    primitive.SupportedOssProtocolVersions(),
+		Timeout:     DefaultCheckTimeout,
+	}
+}
+
+// serverCheck is a check run against a server for a given protocol version. Each check is run on a new connection.
+// The check function returns the outcome and details about it.
+type serverCheck struct {
+	name        string
+	description string
+	run         func(checker *ServerChecker, conn *connection) (Outcome, string)
+}
+
+var serverChecks = []*serverCheck{
+	{"options", "OPTIONS is answered with SUPPORTED", checkOptions},
+	{"startup", "STARTUP is answered with READY or AUTHENTICATE", checkStartup},
+	{"startup-missing-cql-version", "STARTUP without CQL_VERSION is rejected with a protocol error", checkStartupMissingCqlVersion},
+	{"startup-unknown-compression", "STARTUP with an unknown compression is rejected with a protocol error", checkStartupUnknownCompression},
+	{"request-before-startup", "QUERY before STARTUP is rejected with a protocol error", checkRequestBeforeStartup},
+	{"unknown-opcode", "a request with an unknown opcode is rejected with a protocol error", checkUnknownOpCode},
+	{"response-opcode", "a request with a response opcode is rejected with a protocol error", checkResponseOpCode},
+	{"response-direction", "a request with the response direction bit set is rejected with a protocol error", checkResponseDirection},
+	{"authentication-error", "wrong credentials are rejected with an authentication error", checkAuthenticationError},
+	{"truncated-body", "a request whose body is shorter than its contents is rejected with a protocol error", checkTruncatedBody},
+	{"register", "REGISTER is answered with READY", checkRegister},
+	{"register-unknown-event", "REGISTER with an unknown event type is rejected with a protocol error", checkRegisterUnknownEvent},
+	{"system-local", "a query on system.local is answered with ROWS", checkSystemLocal},
+	{"syntax-error", "a query with invalid CQL is rejected with a syntax error", checkSyntaxError},
+	{"invalid-query", "a query on a non-existent table is rejected with an invalid error", checkInvalidQuery},
+	{"unprepared", "EXECUTE with an unknown query id is rejected with an unprepared error", checkUnprepared},
+}
+
+// unsupportedVersion is a protocol version that no server supports.
+const unsupportedVersion = primitive.ProtocolVersion(0x3F)
+
+// Run runs all the checks and returns the conformance report. Checks that cannot be run, e.g. because the server does
+// not support the protocol version being checked, are reported as skipped.
+func (checker *ServerChecker) Run() *Report {
+	report := &Report{Target: checker.Address}
+	outcome, details := checker.checkUnsupportedVersion()
+	report.Results = append(report.Results, &CheckResult{
+		Name:        "unsupported-version",
+		Description: "a request with an unsupported protocol version is rejected with a protocol error",
+		Outcome:     outcome,
+		Details:     details,
+	})
+	for _, version := range checker.Versions {
+		supported := true
+		for _, check := range serverChecks {
+			result := &CheckResult{Name: check.name, Description: check.description, Version: version}
+			if !supported {
+				result.Outcome, result.Details = OutcomeSkipped, "protocol version not supported by server"
+			} else if conn, err := dial(checker.Address, version, checker.Timeout); err != nil {
+				result.Outcome, result.Details = OutcomeFailed, err.Error()
+			} else {
+				result.Outcome, result.Details = check.run(checker, conn)
+				conn.close()
+			}
+			report.Results = append(report.Results, result)
+			if check.name == "options" && result.Outcome == OutcomeSkipped {
+				supported = false
+			}
+		}
+	}
+	return report
+}
+
+func (checker *ServerChecker) checkUnsupportedVersion() (Outcome, string) {
+	conn, err := dial(checker.Address, primitive.ProtocolVersion4, checker.Timeout)
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	defer conn.close()
+	encoded, err := conn.encode(&message.Options{})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	encoded[0] = byte(unsupportedVersion)
+	response, err := conn.sendRaw(encoded)
+	outcome, details := expectError(response, err)(primitive.ErrorCodeProtocolError)
+	if outcome == OutcomePassed {
+		details = fmt.Sprintf("server replied with %v", response.Header.Version)
+	}
+	return outcome, details
+}
+
+func checkOptions(_ *ServerChecker, conn *connection) (Outcome, string) {
+	response, err := conn.send(&message.Options{})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	switch msg := response.Body.Message.(type) {
+	case *message.Supported:
+		options := make([]string, 0, len(msg.Options))
+		for option := range msg.Options {
+			options = append(options, option)
+		}
+		sort.Strings(options)
+		return OutcomePassed, fmt.Sprintf("supported options: %v", options)
+	case *message.ProtocolError:
+		return OutcomeSkipped, fmt.Sprintf("protocol version not supported by server: %v", msg.ErrorMessage)
+	}
+	return OutcomeFailed, fmt.Sprintf("expected SUPPORTED, got: %v", response.Body.Message)
+}
+
+func checkStartup(_ *ServerChecker, conn *connection) (Outcome, string) {
+	response, err := conn.send(message.NewStartup())
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	switch msg := response.Body.Message.(type) {
+	case *message.Ready:
+		return OutcomePassed, "no authentication required"
+	case *message.Authenticate:
+		return OutcomePassed, fmt.Sprintf("authentication required: %v", msg.Authenticator)
+	}
+	return OutcomeFailed, fmt.Sprintf("expected READY or AUTHENTICATE, got: %v", response.Body.Message)
+}
+
+func checkStartupMissingCqlVersion(_ *ServerChecker, conn *connection) (Outcome, string) {
+	return expectError(conn.send(&message.Startup{Options: map[string]string{}}))(primitive.ErrorCodeProtocolError)
+}
+
+func checkStartupUnknownCompression(_ *ServerChecker, conn *connection) (Outcome, string) {
+	startup := message.NewStartup(message.StartupOptionCompression, "unknown")
+	return expectError(conn.send(startup))(primitive.ErrorCodeProtocolError)
+}
+
+func checkRequestBeforeStartup(_ *ServerChecker, conn *connection) (Outcome, string) {
+	return expectError(conn.send(newQuery("SELECT * FROM system.local")))(primitive.ErrorCodeProtocolError)
+}
+
+func checkUnknownOpCode(_ *ServerChecker, conn *connection) (Outcome, string) {
+	return sendWithOpCode(conn, 0x7F)
+}
+
+func checkResponseOpCode(_ *ServerChecker, conn *connection) (Outcome, string) {
+	return sendWithOpCode(conn, primitive.OpCodeReady)
+}
+
+// sendWithOpCode sends an OPTIONS request, after replacing its opcode with the given one.
+func sendWithOpCode(conn *connection, opCode primitive.OpCode) (Outcome, string) {
+	encoded, err := conn.encode(&message.Options{})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	encoded[opCodeOffset(conn.version)] = byte(opCode)
+	return expectError(conn.sendRaw(encoded))(primitive.ErrorCodeProtocolError)
+}
+
+func checkResponseDirection(_ *ServerChecker, conn *connection) (Outcome, string) {
+	encoded, err := conn.encode(&message.Options{})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	encoded[0] |= 0x80
+	response, err := conn.sendRaw(encoded)
+	return expectError(response, err)(primitive.ErrorCodeProtocolError)
+}
+
+func checkAuthenticationError(checker *ServerChecker, conn *connection) (Outcome, string) {
+	response, err := conn.send(message.NewStartup())
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	authenticate, ok := response.Body.Message.(*message.Authenticate)
+	if !ok {
+		return OutcomeSkipped, "server does not require authentication"
+	}
+	username := "conformance"
+	if checker.Credentials != nil {
+		username = checker.Credentials.Username
+	}
+	wrongCredentials := &client.AuthCredentials{Username: username, Password: "wrong password"}
+	authenticator := &client.PlainTextAuthenticator{Credentials: wrongCredentials}
+	token, err := authenticator.InitialResponse(authenticate.Authenticator)
+	if err != nil {
+		return OutcomeSkipped, err.Error()
+	}
+	for {
+		if response, err = conn.send(&message.AuthResponse{Token: token}); err != nil {
+			return OutcomeFailed, err.Error()
+		}
+		challenge, ok := response.Body.Message.(*message.AuthChallenge)
+		if !ok {
+			return expectError(response, nil)(primitive.ErrorCodeAuthenticationError)
+		}
+		if token, err = authenticator.EvaluateChallenge(challenge.Token); err != nil {
+			return OutcomeFailed, err.Error()
+		}
+	}
+}
+
+func checkTruncatedBody(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	encoded, err := conn.encode(newQuery("SELECT * FROM system.local"))
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	// the body starts with the query string length: make it larger than the whole body
+	binary.BigEndian.PutUint32(encoded[conn.version.FrameHeaderLengthInBytes():], uint32(len(encoded)))
+	return expectError(conn.sendRaw(encoded))(primitive.ErrorCodeProtocolError)
+}
+
+func checkRegister(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	response, err := conn.send(&message.Register{EventTypes: []primitive.EventType{
+		primitive.EventTypeSchemaChange,
+		primitive.EventTypeStatusChange,
+		primitive.EventTypeTopologyChange,
+	}})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	} else if _, ok := response.Body.Message.(*message.Ready); !ok {
+		return OutcomeFailed, fmt.Sprintf("expected READY, got: %v", response.Body.Message)
+	}
+	return OutcomePassed, ""
+}
+
+func checkRegisterUnknownEvent(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	encoded, err := conn.encode(&message.Register{EventTypes: []primitive.EventType{primitive.EventTypeStatusChange}})
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	// the codec refuses to encode unknown event types: replace the event type with another string of the same length
+	encoded = bytes.Replace(encoded, []byte(primitive.EventTypeStatusChange), []byte("UNKNOWN_EVENT"), 1)
+	return expectError(conn.sendRaw(encoded))(primitive.ErrorCodeProtocolError)
+}
+
+func checkSystemLocal(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	response, err := conn.send(newQuery("SELECT * FROM system.local"))
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	} else if rows, ok := response.Body.Message.(*message.RowsResult); !ok {
+		return OutcomeFailed, fmt.Sprintf("expected ROWS, got: %v", response.Body.Message)
+	} else if len(rows.Data) != 1 {
+		return OutcomeFailed, fmt.Sprintf("expected 1 row, got: %d", len(rows.Data))
+	}
+	return OutcomePassed, ""
+}
+
+func checkSyntaxError(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	return expectError(conn.send(newQuery("NOT A CQL QUERY")))(primitive.ErrorCodeSyntaxError)
+}
+
+func checkInvalidQuery(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	return expectError(conn.send(newQuery("SELECT * FROM system.nonexistent_table")))(primitive.ErrorCodeInvalid)
+}
+
+func checkUnprepared(checker *ServerChecker, conn *connection) (Outcome, string) {
+	if err := conn.handshake(checker.Credentials); err != nil {
+		return OutcomeFailed, err.Error()
+	}
+	queryId := []byte{0xCA, 0xFE, 0xBA, 0xBE}
+	execute := &message.Execute{
+		QueryId: queryId,
+		Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+	}
+	if conn.version.SupportsResultMetadataId() {
+		execute.ResultMetadataId = queryId
+	}
+	response, err := conn.send(execute)
+	outcome, details := expectError(response, err)(primitive.ErrorCodeUnprepared)
+	if outcome == OutcomePassed && !bytes.Equal(response.Body.Message.(*message.Unprepared).Id, queryId) {
+		return OutcomeFailed, fmt.Sprintf("expected unprepared id %x, got: %x", queryId, response.Body.Message.(*message.Unprepared).Id)
+	}
+	return outcome, details
+}
+
+func newQuery(query string) *message.Query {
+	return &message.Query{Query: query, Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne}}
+}
+
+// opCodeOffset returns the offset of the opcode in a frame header.
+func opCodeOffset(version primitive.ProtocolVersion) int {
+	// the opcode is followed by the 4-byte body length
+	return version.FrameHeaderLengthInBytes() - 5
+}
+
+// expectError returns a function that checks that the given response is an error with the given code.
+func expectError(response *frame.Frame, err error) func(code primitive.ErrorCode) (Outcome, string) {
+	return func(code primitive.ErrorCode) (Outcome, string) {
+		if err != nil {
+			return OutcomeFailed, err.Error()
+		} else if errMsg, ok := response.Body.Message.(message.Error); !ok {
+			return OutcomeFailed, fmt.Sprintf("expected %v, got: %v", code, response.Body.Message)
+		} else if errMsg.GetErrorCode() != code {
+			return OutcomeFailed, fmt.Sprintf("expected %v, got: %v", code, errMsg)
+		} else {
+			return OutcomePassed, errMsg.GetErrorMessage()
+		}
+	}
+}
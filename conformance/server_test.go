@@ -0,0 +1,90 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestServerChecker(t *testing.T) {
+	server := client.NewCqlServer("127.0.0.1:9044", nil)
+	server.RequestHandlers = []client.RequestHandler{
+		client.HandshakeHandler,
+		client.RegisterHandler,
+		client.NewSystemTablesHandler("cluster", "dc1"),
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	checker := NewServerChecker("127.0.0.1:9044", nil)
+	checker.Versions = []primitive.ProtocolVersion{primitive.ProtocolVersion4, primitive.ProtocolVersion5}
+	checker.Timeout = 500 * time.Millisecond
+	report := checker.Run()
+
+	for _, version := range checker.Versions {
+		for _, name := range []string{"options", "startup", "request-before-startup", "register", "system-local"} {
+			assert.Equal(t, OutcomePassed, report.Find(name, version).Outcome, report.Find(name, version).String())
+		}
+		assert.Equal(t, OutcomeSkipped, report.Find("authentication-error", version).Outcome)
+		// the stub server does not enforce CQL_VERSION and closes the connection on malformed frames
+		assert.Equal(t, OutcomeFailed, report.Find("startup-missing-cql-version", version).Outcome)
+		assert.Equal(t, OutcomeFailed, report.Find("unknown-opcode", version).Outcome)
+		assert.Equal(t, "server closed the connection without responding", report.Find("unknown-opcode", version).Details)
+	}
+	assert.Len(t, report.Results, 1+len(checker.Versions)*len(serverChecks))
+	assert.False(t, report.Passed())
+
+	out := &bytes.Buffer{}
+	_, err := report.WriteTo(out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Conformance report for 127.0.0.1:9044")
+	assert.Contains(t, out.String(), fmt.Sprintf("%d passed, %d failed, %d skipped",
+		report.Count(OutcomePassed), report.Count(OutcomeFailed), report.Count(OutcomeSkipped)))
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestServerChecker_Auth(t *testing.T) {
+	credentials := &client.AuthCredentials{Username: "user1", Password: "pass1"}
+	server := client.NewCqlServer("127.0.0.1:9044", credentials)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.RegisterHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	checker := NewServerChecker("127.0.0.1:9044", credentials)
+	checker.Versions = []primitive.ProtocolVersion{primitive.ProtocolVersion4}
+	checker.Timeout = 500 * time.Millisecond
+	report := checker.Run()
+
+	startup := report.Find("startup", primitive.ProtocolVersion4)
+	assert.Equal(t, OutcomePassed, startup.Outcome)
+	assert.Contains(t, startup.Details, "authentication required")
+	assert.Equal(t, OutcomePassed, report.Find("authentication-error", primitive.ProtocolVersion4).Outcome)
+	assert.Equal(t, OutcomePassed, report.Find("register", primitive.ProtocolVersion4).Outcome)
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
@@ -20,6 +20,25 @@ package crc
 const crc24Init uint32 = 0x875060
 const crc24Poly uint32 = 0x1974F0B
 
+// crc24Table contains the CRC-24 of each possible byte value, so that checksums can be computed one byte at a time
+// rather than one bit at a time.
+var crc24Table = makeCrc24Table()
+
+func makeCrc24Table() *[256]uint32 {
+	table := &[256]uint32{}
+	for i := range table {
+		crc := uint32(i) << 16
+		for j := 0; j < 8; j++ {
+			crc <<= 1
+			if (crc & 0x1000000) != 0 {
+				crc ^= crc24Poly
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}
+
 // ChecksumKoopman returns the CRC-24 checksum of the given data.
 // The parameter bytes is an up to 8-byte register containing bytes to compute the CRC over; bits will be read
 // least-significant to most significant.
@@ -33,14 +52,8 @@ const crc24Poly uint32 = 0x1974F0B
 func ChecksumKoopman(data uint64, len int) uint32 {
 	crc := crc24Init
 	for i := 0; i < len; i++ {
-		crc ^= (uint32)(data) << 16
+		crc = ((crc << 8) ^ crc24Table[byte(crc>>16)^byte(data)]) & 0xFFFFFF
 		data >>= 8
-		for j := 0; j < 8; j++ {
-			crc <<= 1
-			if (crc & 0x1000000) != 0 {
-				crc ^= crc24Poly
-			}
-		}
 	}
 	return crc
 }
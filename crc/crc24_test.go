@@ -16,6 +16,7 @@ package crc
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -50,3 +51,47 @@ func TestChecksumKoopman(t *testing.T) {
 		})
 	}
 }
+
+// checksumKoopmanBitwise is the original, bit-by-bit implementation of ChecksumKoopman, used as a reference.
+func checksumKoopmanBitwise(data uint64, len int) uint32 {
+	crc := crc24Init
+	for i := 0; i < len; i++ {
+		crc ^= (uint32)(data) << 16
+		data >>= 8
+		for j := 0; j < 8; j++ {
+			crc <<= 1
+			if (crc & 0x1000000) != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc
+}
+
+func TestChecksumKoopman_Bitwise(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for i := 0; i < 10000; i++ {
+		data := rnd.Uint64()
+		length := rnd.Intn(9)
+		if got, want := ChecksumKoopman(data, length), checksumKoopmanBitwise(data, length); got != want {
+			t.Fatalf("ChecksumKoopman(%v, %v) = %v, want %v", data, length, got, want)
+		}
+	}
+}
+
+// A segment header is 3 bytes long when the payload is not compressed, and 5 bytes long otherwise; see the segment
+// package. ChecksumKoopman is invoked once per segment.
+func BenchmarkChecksumKoopman(b *testing.B) {
+	for _, length := range []int{3, 5} {
+		b.Run(fmt.Sprintf("table/%d bytes", length), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ChecksumKoopman(uint64(i), length)
+			}
+		})
+		b.Run(fmt.Sprintf("bitwise/%d bytes", length), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				checksumKoopmanBitwise(uint64(i), length)
+			}
+		})
+	}
+}
@@ -16,7 +16,10 @@ package crc
 
 import "hash/crc32"
 
-var table = crc32.MakeTable(crc32.IEEE)
+// table must be crc32.IEEETable: it is the only table for which hash/crc32 uses a hardware-accelerated implementation
+// when the CPU supports it (e.g. SSE4.2 and PCLMULQDQ on amd64, CRC32 instructions on arm64), falling back to a
+// slicing-by-8 pure-Go implementation otherwise.
+var table = crc32.IEEETable
 var initialBytes = []byte{0xFA, 0x2D, 0x55, 0xCA}
 var initialChecksum = crc32.Update(0, table, initialBytes)
 
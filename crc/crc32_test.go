@@ -15,6 +15,8 @@
 package crc
 
 import (
+	"fmt"
+	"hash/crc32"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -58,4 +60,32 @@ func TestChecksumIEEE(t *testing.T) {
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
+	assert.Same(t, crc32.IEEETable, table)
+}
+
+// The payload of a segment is at most 131071 bytes long (see segment.MaxPayloadLength); ChecksumIEEE is invoked once
+// per segment.
+func BenchmarkChecksumIEEE(b *testing.B) {
+	for _, length := range []int{64, 1024, 16 * 1024, 131_071} {
+		data := make([]byte, length)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		b.Run(fmt.Sprintf("%d bytes", length), func(b *testing.B) {
+			b.SetBytes(int64(length))
+			for i := 0; i < b.N; i++ {
+				ChecksumIEEE(data)
+			}
+		})
+		// for comparison: hash/crc32 uses a simple byte-wise implementation for any table other than IEEETable, even
+		// if the table contents are identical
+		unaccelerated := &crc32.Table{}
+		*unaccelerated = *crc32.IEEETable
+		b.Run(fmt.Sprintf("%d bytes (unaccelerated)", length), func(b *testing.B) {
+			b.SetBytes(int64(length))
+			for i := 0; i < b.N; i++ {
+				crc32.Update(initialChecksum, unaccelerated, data)
+			}
+		})
+	}
 }
@@ -27,9 +27,16 @@ var Blob Codec = &blobCodec{dataType: datatype.Blob}
 // PassThrough is another name for the Blob codec.
 var PassThrough = Blob
 
-// NewCustom returns a codec for the CQL custom type. Its preferred Go type is []byte, but it can encode from and decode
-// to string as well. This codec is identical to the Blob codec.
+// NewCustom returns a codec for the CQL custom type. If a codec factory was registered for the type's class name with
+// RegisterCustomCodec, the codec is created by that factory. Otherwise, its preferred Go type is []byte, but it can
+// encode from and decode to string as well; such a codec is identical to the Blob codec. The registered factories are
+// not looked up if customType is nil.
 func NewCustom(customType *datatype.Custom) Codec {
+	if customType != nil {
+		if registration := customCodecRegistrationFor(customType); registration != nil {
+			return registration.factory(customType)
+		}
+	}
 	return &blobCodec{dataType: customType}
 }
 
@@ -46,8 +46,12 @@ type Codec interface {
 
 // NewCodec creates a new codec for the given data type. For simple CQL types, this function actually returns one of
 // the existing singletons. For complex CQL types, it delegates to one of the constructor functions available:
-// NewList, NewSet, NewMap, NewTuple, NewUserDefined and NewCustom.
+// NewList, NewSet, NewMap, NewTuple, NewUserDefined and NewCustom. An error is returned if the data type nesting
+// depth exceeds the maximum nesting depth, see datatype.SetMaxNestingDepth.
 func NewCodec(dt datatype.DataType) (Codec, error) {
+	if err := datatype.CheckNestingDepth(dt); err != nil {
+		return nil, err
+	}
 	switch dt.Code() {
 	case primitive.DataTypeCodeAscii:
 		return Ascii, nil
@@ -122,6 +126,9 @@ func PreferredGoType(dt datatype.DataType) (reflect.Type, error) {
 	case primitive.DataTypeCodeCounter:
 		return typeOfInt64, nil
 	case primitive.DataTypeCodeCustom:
+		if registration := customCodecRegistrationFor(dt.(*datatype.Custom)); registration != nil {
+			return registration.preferredType, nil
+		}
 		return typeOfByteSlice, nil
 	case primitive.DataTypeCodeDate:
 		return typeOfTime, nil
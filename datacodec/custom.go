@@ -0,0 +1,66 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+)
+
+// CustomCodecFactory creates a codec for the given CQL custom type. See RegisterCustomCodec.
+type CustomCodecFactory func(customType *datatype.Custom) Codec
+
+type customCodecRegistration struct {
+	preferredType reflect.Type
+	factory       CustomCodecFactory
+}
+
+var (
+	customCodecs = map[string]*customCodecRegistration{
+		DateRangeClassName: {preferredType: typeOfCqlDateRange, factory: newDateRangeCodec},
+	}
+	customCodecsLock = &sync.RWMutex{}
+)
+
+// RegisterCustomCodec registers a codec factory for CQL custom types with the given class name, e.g.
+// "org.apache.cassandra.db.marshal.DynamicCompositeType" or "org.apache.cassandra.db.marshal.PointType", replacing any
+// factory previously registered for the same class name. Class names must match exactly, as they appear in the
+// metadata sent by the server. Once registered, NewCustom and NewCodec return the codecs created by the factory
+// instead of a codec identical to the Blob codec, and PreferredGoType returns the given preferred type, which must be
+// the preferred Go type of the created codecs. This allows columns of custom types to be decoded into meaningful Go
+// types. This function is safe for concurrent use, but is meant to be called once per class name, before any encoding
+// or decoding takes place.
+func RegisterCustomCodec(className string, preferredType reflect.Type, factory CustomCodecFactory) error {
+	if className == "" {
+		return fmt.Errorf("custom type class name cannot be empty")
+	} else if preferredType == nil {
+		return fmt.Errorf("custom type preferred Go type cannot be nil")
+	} else if factory == nil {
+		return fmt.Errorf("custom codec factory cannot be nil")
+	}
+	customCodecsLock.Lock()
+	defer customCodecsLock.Unlock()
+	customCodecs[className] = &customCodecRegistration{preferredType: preferredType, factory: factory}
+	return nil
+}
+
+func customCodecRegistrationFor(customType *datatype.Custom) *customCodecRegistration {
+	customCodecsLock.RLock()
+	defer customCodecsLock.RUnlock()
+	return customCodecs[customType.ClassName]
+}
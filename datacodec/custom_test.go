@@ -0,0 +1,74 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// int32CustomCodec decodes a custom type whose values are encoded as CQL ints.
+type int32CustomCodec struct {
+	*intCodec
+	customType *datatype.Custom
+}
+
+func (c *int32CustomCodec) DataType() datatype.DataType {
+	return c.customType
+}
+
+func TestRegisterCustomCodec(t *testing.T) {
+	const className = "com.example.Int32Type"
+	err := RegisterCustomCodec(className, typeOfInt32, func(customType *datatype.Custom) Codec {
+		return &int32CustomCodec{intCodec: Int.(*intCodec), customType: customType}
+	})
+	require.NoError(t, err)
+
+	customType := datatype.NewCustom(className)
+	codec, err := NewCodec(customType)
+	require.NoError(t, err)
+	assert.Equal(t, customType, codec.DataType())
+	encoded, err := codec.Encode(int32(42), primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 42}, encoded)
+	var decoded interface{}
+	_, err = codec.Decode(encoded, &decoded, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), decoded)
+	preferred, err := PreferredGoType(customType)
+	require.NoError(t, err)
+	assert.Equal(t, typeOfInt32, preferred)
+
+	// other class names are not affected
+	otherType := datatype.NewCustom("com.example.OtherType")
+	assert.Equal(t, &blobCodec{dataType: otherType}, NewCustom(otherType))
+	preferred, err = PreferredGoType(otherType)
+	require.NoError(t, err)
+	assert.Equal(t, typeOfByteSlice, preferred)
+
+	// nil custom types do not look up registered factories
+	var nilType *datatype.Custom
+	assert.Equal(t, &blobCodec{dataType: nilType}, NewCustom(nil))
+
+	assert.Error(t, RegisterCustomCodec("", typeOfInt32, func(*datatype.Custom) Codec { return nil }))
+	assert.Error(t, RegisterCustomCodec(className, nil, func(*datatype.Custom) Codec { return nil }))
+	assert.Error(t, RegisterCustomCodec(className, reflect.TypeOf(0), nil))
+}
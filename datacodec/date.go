@@ -15,7 +15,10 @@
 package datacodec
 
 import (
+	"fmt"
 	"math"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
@@ -55,6 +58,128 @@ func ConvertEpochDaysToTime(days int32) time.Time {
 	return time.Unix(int64(days)*86400, 0).UTC()
 }
 
+// ConvertEpochDaysToDateWireValue converts from days since the Epoch into the value of a CQL date as stored on the
+// wire: an unsigned integer with the Epoch at the center of the range, that is, at 2^31.
+func ConvertEpochDaysToDateWireValue(days int32) uint32 {
+	return uint32(days) ^ 0x80000000
+}
+
+// ConvertDateWireValueToEpochDays converts from the value of a CQL date as stored on the wire into days since the
+// Epoch, see ConvertEpochDaysToDateWireValue.
+func ConvertDateWireValueToEpochDays(value uint32) int32 {
+	return int32(value ^ 0x80000000)
+}
+
+// ConvertEpochDaysToYearMonthDay converts from days since the Epoch into year, month and day components, in the
+// proleptic Gregorian calendar. Unlike ConvertEpochDaysToTime, this function does not involve time.Time and is exact
+// for the entire CQL date range.
+func ConvertEpochDaysToYearMonthDay(days int32) (year int, month time.Month, day int) {
+	// Algorithm from Howard Hinnant, see http://howardhinnant.github.io/date_algorithms.html#civil_from_days
+	z := int64(days) + 719468
+	era := z
+	if era < 0 {
+		era -= 146096
+	}
+	era /= 146097
+	doe := z - era*146097
+	yoe := (doe - doe/1460 + doe/36524 - doe/146096) / 365
+	doy := doe - (365*yoe + yoe/4 - yoe/100)
+	mp := (5*doy + 2) / 153
+	d := doy - (153*mp+2)/5 + 1
+	m := mp + 3
+	if mp >= 10 {
+		m = mp - 9
+	}
+	y := yoe + era*400
+	if m <= 2 {
+		y++
+	}
+	return int(y), time.Month(m), int(d)
+}
+
+// ConvertYearMonthDayToEpochDays converts from year, month and day components, in the proleptic Gregorian calendar,
+// into days since the Epoch. An error is returned if the components do not form a valid date, or if the date is
+// outside the valid range for CQL date values: from -5877641-06-23 to 5881580-07-11 inclusive.
+func ConvertYearMonthDayToEpochDays(year int, month time.Month, day int) (int32, error) {
+	if month < time.January || month > time.December || day < 1 || day > daysInMonth(int64(year), month) {
+		return 0, fmt.Errorf("invalid date: %d-%02d-%02d", year, int(month), day)
+	}
+	// Algorithm from Howard Hinnant, see http://howardhinnant.github.io/date_algorithms.html#days_from_civil
+	y := int64(year)
+	if month <= time.February {
+		y--
+	}
+	era := y
+	if era < 0 {
+		era -= 399
+	}
+	era /= 400
+	yoe := y - era*400
+	m := int64(month) - 3
+	if month <= time.February {
+		m = int64(month) + 9
+	}
+	doy := (153*m+2)/5 + int64(day) - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+	days := era*146097 + doe - 719468
+	if days < math.MinInt32 || days > math.MaxInt32 {
+		return 0, errValueOutOfRange(fmt.Sprintf("%d-%02d-%02d", year, int(month), day))
+	}
+	return int32(days), nil
+}
+
+func daysInMonth(year int64, month time.Month) int {
+	switch month {
+	case time.February:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case time.April, time.June, time.September, time.November:
+		return 30
+	}
+	return 31
+}
+
+// FormatDateLiteral formats the given days since the Epoch as an ISO-8601 date, e.g. "2021-10-12". Years are
+// formatted with at least 4 digits; years before year 0 are prefixed with a minus sign, and years after 9999 with a
+// plus sign, as in ISO-8601 expanded representations, e.g. "-5877641-06-23" and "+5881580-07-11". This is also the
+// format of CQL date literals.
+func FormatDateLiteral(days int32) string {
+	year, month, day := ConvertEpochDaysToYearMonthDay(days)
+	sign := ""
+	if year < 0 {
+		sign = "-"
+		year = -year
+	} else if year > 9999 {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s%04d-%02d-%02d", sign, year, int(month), day)
+}
+
+var dateLiteralRegexp = regexp.MustCompile(`^([+-]?\d+)-(\d{1,2})-(\d{1,2})$`)
+
+// ParseDateLiteral parses the given CQL date literal into days since the Epoch. Accepted literals are ISO-8601 dates,
+// with optionally signed years of any length, as produced by FormatDateLiteral, e.g. "2021-10-12" or
+// "+5881580-07-11"; and unsigned integers, which are interpreted as the value of the date as stored on the wire, see
+// ConvertDateWireValueToEpochDays.
+func ParseDateLiteral(literal string) (int32, error) {
+	if value, err := strconv.ParseUint(literal, 10, 32); err == nil {
+		return ConvertDateWireValueToEpochDays(uint32(value)), nil
+	}
+	matches := dateLiteralRegexp.FindStringSubmatch(literal)
+	if matches == nil {
+		return 0, fmt.Errorf("cannot parse %q as CQL date literal", literal)
+	}
+	year, err := strconv.ParseInt(matches[1], 10, 32)
+	if err != nil {
+		return 0, errValueOutOfRange(literal)
+	}
+	month, _ := strconv.Atoi(matches[2])
+	day, _ := strconv.Atoi(matches[3])
+	return ConvertYearMonthDayToEpochDays(int(year), time.Month(month), day)
+}
+
 // Date is a codec for the CQL date type with default layout. Its preferred Go type is time.Time, but it can
 // encode from and decode to string and to and from most numeric types as well.
 // When encoding from and decoding to time.Time, only the date part is considered, the clock part is ignored. Also note
@@ -0,0 +1,312 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DateRangeClassName is the class name of the DSE DateRangeType custom type.
+const DateRangeClassName = "org.apache.cassandra.db.marshal.DateRangeType"
+
+// DateRangePrecision is the precision of a CqlDateRangeBound.
+type DateRangePrecision uint8
+
+const (
+	DateRangePrecisionYear        = DateRangePrecision(0x00)
+	DateRangePrecisionMonth       = DateRangePrecision(0x01)
+	DateRangePrecisionDay         = DateRangePrecision(0x02)
+	DateRangePrecisionHour        = DateRangePrecision(0x03)
+	DateRangePrecisionMinute      = DateRangePrecision(0x04)
+	DateRangePrecisionSecond      = DateRangePrecision(0x05)
+	DateRangePrecisionMillisecond = DateRangePrecision(0x06)
+)
+
+func (p DateRangePrecision) IsValid() bool {
+	return p <= DateRangePrecisionMillisecond
+}
+
+func (p DateRangePrecision) String() string {
+	switch p {
+	case DateRangePrecisionYear:
+		return "YEAR"
+	case DateRangePrecisionMonth:
+		return "MONTH"
+	case DateRangePrecisionDay:
+		return "DAY"
+	case DateRangePrecisionHour:
+		return "HOUR"
+	case DateRangePrecisionMinute:
+		return "MINUTE"
+	case DateRangePrecisionSecond:
+		return "SECOND"
+	case DateRangePrecisionMillisecond:
+		return "MILLISECOND"
+	}
+	return fmt.Sprintf("DateRangePrecision ? [%#.2X]", uint8(p))
+}
+
+func (p DateRangePrecision) layout() string {
+	switch p {
+	case DateRangePrecisionYear:
+		return "2006"
+	case DateRangePrecisionMonth:
+		return "2006-01"
+	case DateRangePrecisionDay:
+		return "2006-01-02"
+	case DateRangePrecisionHour:
+		return "2006-01-02T15"
+	case DateRangePrecisionMinute:
+		return "2006-01-02T15:04"
+	case DateRangePrecisionSecond:
+		return "2006-01-02T15:04:05"
+	}
+	return "2006-01-02T15:04:05.000Z"
+}
+
+// CqlDateRangeBound is a bound of a CqlDateRange: a point in time with a precision, or an unbounded bound, noted "*".
+type CqlDateRangeBound struct {
+	// Timestamp is the bound timestamp, with millisecond precision; it is irrelevant if Unbounded is true.
+	Timestamp time.Time
+	// Precision is the bound precision; it is irrelevant if Unbounded is true.
+	Precision DateRangePrecision
+	// Unbounded is true if the bound is unbounded.
+	Unbounded bool
+}
+
+func (b CqlDateRangeBound) String() string {
+	if b.Unbounded {
+		return "*"
+	}
+	return b.Timestamp.UTC().Format(b.Precision.layout())
+}
+
+// CqlDateRange is the Go representation of DSE's DateRangeType custom type: either a single date, e.g. "2017-01", or
+// a range of dates, e.g. "[2017-01 TO 2017-03-15]", where each bound can be unbounded, e.g. "[2017-01 TO *]". A single
+// date can be unbounded as well, noted "*".
+type CqlDateRange struct {
+	// Lower is the lower bound of the range, or the single date.
+	Lower CqlDateRangeBound
+	// Upper is the upper bound of the range; it is nil if this is a single date.
+	Upper *CqlDateRangeBound
+}
+
+func (r CqlDateRange) String() string {
+	if r.Upper == nil {
+		return r.Lower.String()
+	}
+	return fmt.Sprintf("[%v TO %v]", r.Lower, r.Upper)
+}
+
+// DateRange is a codec for DSE's DateRangeType custom type. It can only encode from and decode to CqlDateRange. It is
+// registered by default for DateRangeClassName, see RegisterCustomCodec.
+var DateRange Codec = newDateRangeCodec(datatype.NewCustom(DateRangeClassName))
+
+func newDateRangeCodec(customType *datatype.Custom) Codec {
+	return &dateRangeCodec{dataType: customType}
+}
+
+type dateRangeCodec struct {
+	dataType *datatype.Custom
+}
+
+func (c *dateRangeCodec) DataType() datatype.DataType {
+	return c.dataType
+}
+
+func (c *dateRangeCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
+	var val CqlDateRange
+	var wasNil bool
+	if val, wasNil, err = convertToDateRange(source); err == nil && !wasNil {
+		dest, err = writeDateRange(val)
+	}
+	if err != nil {
+		err = errCannotEncode(source, c.DataType(), version, err)
+	}
+	return
+}
+
+func (c *dateRangeCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
+	var val CqlDateRange
+	if val, wasNull, err = readDateRange(source); err == nil {
+		err = convertFromDateRange(val, wasNull, dest)
+	}
+	if err != nil {
+		err = errCannotDecode(dest, c.DataType(), version, err)
+	}
+	return
+}
+
+func convertToDateRange(source interface{}) (val CqlDateRange, wasNil bool, err error) {
+	switch s := source.(type) {
+	case CqlDateRange:
+		val = s
+	case *CqlDateRange:
+		if wasNil = s == nil; !wasNil {
+			val = *s
+		}
+	case nil:
+		wasNil = true
+	default:
+		err = ErrConversionNotSupported
+	}
+	if err != nil {
+		err = errSourceConversionFailed(source, val, err)
+	}
+	return
+}
+
+func convertFromDateRange(val CqlDateRange, wasNull bool, dest interface{}) (err error) {
+	switch d := dest.(type) {
+	case *interface{}:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = nil
+		} else {
+			*d = val
+		}
+	case *CqlDateRange:
+		if d == nil {
+			err = ErrNilDestination
+		} else if wasNull {
+			*d = CqlDateRange{}
+		} else {
+			*d = val
+		}
+	default:
+		err = errDestinationInvalid(dest)
+	}
+	if err != nil {
+		err = errDestinationConversionFailed(val, dest, err)
+	}
+	return
+}
+
+// Implementation notes: a date range is encoded as a type byte, followed by zero, one or two bounds, depending on the
+// type; each bound is encoded as a [long] timestamp in milliseconds since the Epoch, followed by a precision byte.
+
+const (
+	dateRangeTypeSingleDate         = byte(0x00)
+	dateRangeTypeClosedRange        = byte(0x01)
+	dateRangeTypeOpenRangeHigh      = byte(0x02)
+	dateRangeTypeOpenRangeLow       = byte(0x03)
+	dateRangeTypeBothOpenRange      = byte(0x04)
+	dateRangeTypeSingleDateOpenDate = byte(0x05)
+)
+
+const lengthOfDateRangeBound = primitive.LengthOfLong + primitive.LengthOfByte
+
+func writeDateRange(val CqlDateRange) ([]byte, error) {
+	dest := &bytes.Buffer{}
+	var bounds []CqlDateRangeBound
+	if val.Upper == nil {
+		if val.Lower.Unbounded {
+			dest.WriteByte(dateRangeTypeSingleDateOpenDate)
+		} else {
+			dest.WriteByte(dateRangeTypeSingleDate)
+			bounds = append(bounds, val.Lower)
+		}
+	} else if val.Lower.Unbounded && val.Upper.Unbounded {
+		dest.WriteByte(dateRangeTypeBothOpenRange)
+	} else if val.Lower.Unbounded {
+		dest.WriteByte(dateRangeTypeOpenRangeLow)
+		bounds = append(bounds, *val.Upper)
+	} else if val.Upper.Unbounded {
+		dest.WriteByte(dateRangeTypeOpenRangeHigh)
+		bounds = append(bounds, val.Lower)
+	} else {
+		dest.WriteByte(dateRangeTypeClosedRange)
+		bounds = append(bounds, val.Lower, *val.Upper)
+	}
+	for _, bound := range bounds {
+		if !bound.Precision.IsValid() {
+			return nil, fmt.Errorf("invalid date range precision: %v", bound.Precision)
+		}
+		millis, err := ConvertTimeToEpochMillis(bound.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		_ = primitive.WriteLong(millis, dest)
+		dest.WriteByte(byte(bound.Precision))
+	}
+	return dest.Bytes(), nil
+}
+
+func readDateRange(source []byte) (val CqlDateRange, wasNull bool, err error) {
+	length := len(source)
+	if wasNull = length == 0; wasNull {
+		return
+	}
+	rangeType := source[0]
+	expected := primitive.LengthOfByte
+	switch rangeType {
+	case dateRangeTypeSingleDate, dateRangeTypeOpenRangeHigh, dateRangeTypeOpenRangeLow:
+		expected += lengthOfDateRangeBound
+	case dateRangeTypeClosedRange:
+		expected += 2 * lengthOfDateRangeBound
+	case dateRangeTypeBothOpenRange, dateRangeTypeSingleDateOpenDate:
+	default:
+		err = fmt.Errorf("unknown date range type: %#.2X", rangeType)
+	}
+	if err == nil && length != expected {
+		err = errWrongFixedLength(expected, length)
+	}
+	if err == nil {
+		unbounded := CqlDateRangeBound{Unbounded: true}
+		switch rangeType {
+		case dateRangeTypeSingleDate:
+			val.Lower, err = readDateRangeBound(source[1:])
+		case dateRangeTypeClosedRange:
+			var upper CqlDateRangeBound
+			if val.Lower, err = readDateRangeBound(source[1:]); err == nil {
+				upper, err = readDateRangeBound(source[1+lengthOfDateRangeBound:])
+				val.Upper = &upper
+			}
+		case dateRangeTypeOpenRangeHigh:
+			val.Lower, err = readDateRangeBound(source[1:])
+			val.Upper = &unbounded
+		case dateRangeTypeOpenRangeLow:
+			var upper CqlDateRangeBound
+			upper, err = readDateRangeBound(source[1:])
+			val.Lower, val.Upper = unbounded, &upper
+		case dateRangeTypeBothOpenRange:
+			val.Lower, val.Upper = unbounded, &CqlDateRangeBound{Unbounded: true}
+		case dateRangeTypeSingleDateOpenDate:
+			val.Lower = unbounded
+		}
+	}
+	if err != nil {
+		err = errCannotRead(val, err)
+	}
+	return
+}
+
+func readDateRangeBound(source []byte) (bound CqlDateRangeBound, err error) {
+	millis := int64(binary.BigEndian.Uint64(source))
+	bound.Precision = DateRangePrecision(source[primitive.LengthOfLong])
+	if !bound.Precision.IsValid() {
+		err = fmt.Errorf("invalid date range precision: %v", bound.Precision)
+	} else {
+		bound.Timestamp = ConvertEpochMillisToTime(millis)
+	}
+	return
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	dateRangeLowerTime = time.Date(2017, 2, 3, 10, 15, 30, 0, time.UTC)
+	dateRangeUpperTime = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateRangeLower     = CqlDateRangeBound{Timestamp: dateRangeLowerTime, Precision: DateRangePrecisionMillisecond}
+	dateRangeUpper     = CqlDateRangeBound{Timestamp: dateRangeUpperTime, Precision: DateRangePrecisionYear}
+	dateRangeUnbounded = CqlDateRangeBound{Unbounded: true}
+	// 2017-02-03T10:15:30Z = 1486116930000 ms = 0x15A0378C1D0
+	dateRangeLowerBytes = []byte{0, 0, 0x01, 0x5A, 0x03, 0x78, 0xC1, 0xD0, 0x06}
+	// 2018-01-01T00:00:00Z = 1514764800000 ms = 0x160AF049000
+	dateRangeUpperBytes = []byte{0, 0, 0x01, 0x60, 0xAF, 0x04, 0x90, 0x00, 0x00}
+)
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     CqlDateRange
+		encoded []byte
+		str     string
+	}{
+		{"single date", CqlDateRange{Lower: dateRangeLower}, append([]byte{0x00}, dateRangeLowerBytes...), "2017-02-03T10:15:30.000Z"},
+		{"closed range", CqlDateRange{Lower: dateRangeLower, Upper: &dateRangeUpper}, append(append([]byte{0x01}, dateRangeLowerBytes...), dateRangeUpperBytes...), "[2017-02-03T10:15:30.000Z TO 2018]"},
+		{"open range high", CqlDateRange{Lower: dateRangeLower, Upper: &dateRangeUnbounded}, append([]byte{0x02}, dateRangeLowerBytes...), "[2017-02-03T10:15:30.000Z TO *]"},
+		{"open range low", CqlDateRange{Lower: dateRangeUnbounded, Upper: &dateRangeUpper}, append([]byte{0x03}, dateRangeUpperBytes...), "[* TO 2018]"},
+		{"both open range", CqlDateRange{Lower: dateRangeUnbounded, Upper: &dateRangeUnbounded}, []byte{0x04}, "[* TO *]"},
+		{"single date open", CqlDateRange{Lower: dateRangeUnbounded}, []byte{0x05}, "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := DateRange.Encode(tt.val, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, tt.encoded, encoded)
+			var decoded CqlDateRange
+			wasNull, err := DateRange.Decode(tt.encoded, &decoded, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.False(t, wasNull)
+			assert.Equal(t, tt.val, decoded)
+			assert.Equal(t, tt.str, decoded.String())
+		})
+	}
+	t.Run("null", func(t *testing.T) {
+		encoded, err := DateRange.Encode((*CqlDateRange)(nil), primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Nil(t, encoded)
+		var decoded interface{} = "not nil"
+		wasNull, err := DateRange.Decode(nil, &decoded, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.True(t, wasNull)
+		assert.Nil(t, decoded)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := DateRange.Encode(CqlDateRange{Lower: CqlDateRangeBound{Precision: 7}}, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "invalid date range precision: DateRangePrecision ? [0X07]", err)
+		_, err = DateRange.Encode(123, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "conversion not supported", err)
+		var decoded CqlDateRange
+		_, err = DateRange.Decode([]byte{0x06}, &decoded, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "unknown date range type: 0X06", err)
+		_, err = DateRange.Decode([]byte{0x00, 0x01}, &decoded, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "expected 10 bytes but got: 2", err)
+		_, err = DateRange.Decode(append([]byte{0x00}, dateRangeLowerBytes[:8]...), &decoded, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "expected 10 bytes", err)
+		_, err = DateRange.Decode([]byte{0x05}, new(int), primitive.ProtocolVersion4)
+		assertErrorMessage(t, "conversion not supported", err)
+	})
+	t.Run("registered", func(t *testing.T) {
+		customType := datatype.NewCustom(DateRangeClassName)
+		codec, err := NewCodec(customType)
+		require.NoError(t, err)
+		assert.Equal(t, customType, codec.DataType())
+		var decoded interface{}
+		_, err = codec.Decode([]byte{0x05}, &decoded, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, CqlDateRange{Lower: dateRangeUnbounded}, decoded)
+		preferred, err := PreferredGoType(customType)
+		require.NoError(t, err)
+		assert.Equal(t, typeOfCqlDateRange, preferred)
+		inferred, err := InferDataType(CqlDateRange{})
+		require.NoError(t, err)
+		assert.Equal(t, customType, inferred)
+	})
+}
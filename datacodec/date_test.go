@@ -243,3 +243,108 @@ func Test_convertFromInt32Date(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertEpochDaysToDateWireValue(t *testing.T) {
+	assert.Equal(t, uint32(1<<31), ConvertEpochDaysToDateWireValue(0))
+	assert.Equal(t, uint32(0), ConvertEpochDaysToDateWireValue(math.MinInt32))
+	assert.Equal(t, uint32(math.MaxUint32), ConvertEpochDaysToDateWireValue(math.MaxInt32))
+	assert.Equal(t, uint32(1<<31+18912), ConvertEpochDaysToDateWireValue(18912))
+	for _, days := range []int32{math.MinInt32, -6766, 0, 18912, math.MaxInt32} {
+		assert.Equal(t, days, ConvertDateWireValueToEpochDays(ConvertEpochDaysToDateWireValue(days)))
+	}
+}
+
+func TestConvertEpochDaysToYearMonthDay(t *testing.T) {
+	for _, days := range []int32{math.MinInt32, math.MinInt32 + 1, -719529, -719528, -6766, -1, 0, 1, 18912, 951494, math.MaxInt32 - 1, math.MaxInt32} {
+		t.Run(fmt.Sprint(days), func(t *testing.T) {
+			expected := ConvertEpochDaysToTime(days)
+			year, month, day := ConvertEpochDaysToYearMonthDay(days)
+			assert.Equal(t, expected.Year(), year)
+			assert.Equal(t, expected.Month(), month)
+			assert.Equal(t, expected.Day(), day)
+			actual, err := ConvertYearMonthDayToEpochDays(year, month, day)
+			assert.NoError(t, err)
+			assert.Equal(t, days, actual)
+		})
+	}
+}
+
+func TestConvertYearMonthDayToEpochDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		year     int
+		month    time.Month
+		day      int
+		expected int32
+		err      string
+	}{
+		{"epoch", 1970, time.January, 1, 0, ""},
+		{"positive", 2021, time.October, 12, 18912, ""},
+		{"negative", 1951, time.June, 24, -6766, ""},
+		{"leap day", 2000, time.February, 29, 11016, ""},
+		{"min", -5877641, time.June, 23, math.MinInt32, ""},
+		{"max", 5881580, time.July, 11, math.MaxInt32, ""},
+		{"out of range negative", -5877641, time.June, 22, 0, "value out of range: -5877641-06-22"},
+		{"out of range positive", 5881580, time.July, 12, 0, "value out of range: 5881580-07-12"},
+		{"invalid month", 2021, 13, 1, 0, "invalid date: 2021-13-01"},
+		{"invalid day", 2021, time.April, 31, 0, "invalid date: 2021-04-31"},
+		{"invalid leap day", 1900, time.February, 29, 0, "invalid date: 1900-02-29"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ConvertYearMonthDayToEpochDays(tt.year, tt.month, tt.day)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
+
+func TestFormatAndParseDateLiteral(t *testing.T) {
+	tests := []struct {
+		literal string
+		days    int32
+	}{
+		{"1970-01-01", 0},
+		{"2021-10-12", 18912},
+		{"1951-06-24", -6766},
+		{"0000-01-01", -719528},
+		{"-0001-12-31", -719529},
+		{"+10000-01-01", 2932897},
+		{"-5877641-06-23", math.MinInt32},
+		{"+5881580-07-11", math.MaxInt32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			assert.Equal(t, tt.literal, FormatDateLiteral(tt.days))
+			actual, err := ParseDateLiteral(tt.literal)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.days, actual)
+		})
+	}
+}
+
+func TestParseDateLiteral(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected int32
+		err      string
+	}{
+		{"2021-1-2", 18629, ""},
+		{"5881580-07-11", math.MaxInt32, ""},
+		{"2147483648", 0, ""},
+		{"0", math.MinInt32, ""},
+		{"4294967295", math.MaxInt32, ""},
+		{"4294967296", 0, "cannot parse \"4294967296\" as CQL date literal"},
+		{"2021/10/12", 0, "cannot parse \"2021/10/12\" as CQL date literal"},
+		{"2021-02-30", 0, "invalid date: 2021-02-30"},
+		{"5881580-07-12", 0, "value out of range: 5881580-07-12"},
+		{"99999999999-01-01", 0, "value out of range: 99999999999-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			actual, err := ParseDateLiteral(tt.literal)
+			assert.Equal(t, tt.expected, actual)
+			assertErrorMessage(t, tt.err, err)
+		})
+	}
+}
@@ -17,6 +17,7 @@ package datacodec
 import (
 	"encoding/binary"
 	"math/big"
+	"reflect"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -37,7 +38,8 @@ type CqlDecimal struct {
 }
 
 // Decimal is a codec for the CQL decimal type. There is no built-in representation of arbitrary-precision
-// decimal values in Go's standard library. This is why this codec can only encode from and decode to CqlDecimal.
+// decimal values in Go's standard library. This is why this codec can only encode from and decode to CqlDecimal, and
+// to the alternative representations registered with RegisterDecimalBackend.
 var Decimal Codec = &decimalCodec{}
 
 type decimalCodec struct{}
@@ -80,7 +82,11 @@ func convertToDecimal(source interface{}) (val CqlDecimal, wasNil bool, err erro
 	case nil:
 		wasNil = true
 	default:
-		err = ErrConversionNotSupported
+		if backend := decimalBackendFor(reflect.TypeOf(source)); backend != nil {
+			val, wasNil, err = convertToDecimalWithBackend(source, backend)
+		} else {
+			err = ErrConversionNotSupported
+		}
 	}
 	if err != nil {
 		err = errSourceConversionFailed(source, val, err)
@@ -107,7 +113,11 @@ func convertFromDecimal(val CqlDecimal, wasNull bool, dest interface{}) (err err
 			*d = val
 		}
 	default:
-		err = errDestinationInvalid(dest)
+		if backend := decimalBackendFor(reflect.TypeOf(dest)); backend != nil {
+			err = convertFromDecimalWithBackend(val, wasNull, dest, backend)
+		} else {
+			err = errDestinationInvalid(dest)
+		}
 	}
 	if err != nil {
 		err = errDestinationConversionFailed(val, dest, err)
@@ -120,7 +130,7 @@ func writeDecimal(val CqlDecimal) []byte {
 	if n == nil {
 		n = zeroBigInt
 	}
-	unscaled := writeBigInt(n)
+	unscaled := primitive.EncodeTwosComplement(n)
 	dest := make([]byte, primitive.LengthOfInt, primitive.LengthOfInt+len(unscaled))
 	binary.BigEndian.PutUint32(dest, uint32(val.Scale))
 	return append(dest, unscaled...)
@@ -134,7 +144,7 @@ func readDecimal(source []byte) (val CqlDecimal, wasNull bool, err error) {
 		err = errWrongMinimumLength(primitive.LengthOfInt, length)
 	} else {
 		val.Scale = int32(binary.BigEndian.Uint32(source))
-		val.Unscaled = readBigInt(source[primitive.LengthOfInt:])
+		val.Unscaled = primitive.DecodeTwosComplement(source[primitive.LengthOfInt:])
 	}
 	if err != nil {
 		err = errCannotRead(val, err)
@@ -0,0 +1,107 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecimalBackend converts CQL decimal values from and to an alternative Go representation of arbitrary-precision
+// decimals, e.g. decimal.Decimal from github.com/shopspring/decimal. Once registered with RegisterDecimalBackend, the
+// Decimal codec can encode from values of the backend type and pointers thereto, and decode to pointers to the backend
+// type, in addition to CqlDecimal. Implementations must be safe for concurrent use.
+type DecimalBackend interface {
+
+	// Type returns the Go type of the alternative representation, e.g. reflect.TypeOf(decimal.Decimal{}). It must not
+	// be a pointer type.
+	Type() reflect.Type
+
+	// FromCqlDecimal converts the given CqlDecimal into the alternative representation. The returned value must be of
+	// the backend type.
+	FromCqlDecimal(val CqlDecimal) (interface{}, error)
+
+	// ToCqlDecimal converts the given value, of the backend type, into a CqlDecimal.
+	ToCqlDecimal(val interface{}) (CqlDecimal, error)
+}
+
+var (
+	decimalBackends     = map[reflect.Type]DecimalBackend{}
+	decimalBackendsLock = &sync.RWMutex{}
+)
+
+// RegisterDecimalBackend registers the given DecimalBackend, replacing any backend previously registered for the same
+// type. This function is safe for concurrent use, but is meant to be called once per backend, before any encoding or
+// decoding takes place.
+func RegisterDecimalBackend(backend DecimalBackend) error {
+	if backend == nil {
+		return fmt.Errorf("decimal backend cannot be nil")
+	} else if t := backend.Type(); t == nil || t.Kind() == reflect.Ptr {
+		return fmt.Errorf("decimal backend type must be a non-pointer type, got: %v", t)
+	} else if t == typeOfCqlDecimal {
+		return fmt.Errorf("decimal backend type cannot be %v", t)
+	}
+	decimalBackendsLock.Lock()
+	defer decimalBackendsLock.Unlock()
+	decimalBackends[backend.Type()] = backend
+	return nil
+}
+
+// decimalBackendFor returns the backend registered for the given type, or for its element type if it is a pointer
+// type, or nil if no such backend exists.
+func decimalBackendFor(t reflect.Type) DecimalBackend {
+	if t == nil {
+		return nil
+	} else if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	decimalBackendsLock.RLock()
+	defer decimalBackendsLock.RUnlock()
+	return decimalBackends[t]
+}
+
+func convertToDecimalWithBackend(source interface{}, backend DecimalBackend) (val CqlDecimal, wasNil bool, err error) {
+	s := reflect.ValueOf(source)
+	if s.Kind() == reflect.Ptr {
+		if wasNil = s.IsNil(); wasNil {
+			return
+		}
+		s = s.Elem()
+	}
+	val, err = backend.ToCqlDecimal(s.Interface())
+	return
+}
+
+func convertFromDecimalWithBackend(val CqlDecimal, wasNull bool, dest interface{}, backend DecimalBackend) (err error) {
+	d := reflect.ValueOf(dest)
+	if d.Kind() != reflect.Ptr {
+		return ErrPointerTypeExpected
+	} else if d.IsNil() {
+		return ErrNilDestination
+	} else if wasNull {
+		d.Elem().Set(reflect.Zero(backend.Type()))
+		return nil
+	}
+	var converted interface{}
+	if converted, err = backend.FromCqlDecimal(val); err == nil {
+		if reflect.TypeOf(converted) != backend.Type() {
+			err = fmt.Errorf("decimal backend returned %T, expected %v", converted, backend.Type())
+		} else {
+			d.Elem().Set(reflect.ValueOf(converted))
+		}
+	}
+	return
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// plainDecimal is a decimal in plain string notation, e.g. "-1.23".
+type plainDecimal string
+
+type plainDecimalBackend struct{}
+
+func (b plainDecimalBackend) Type() reflect.Type {
+	return reflect.TypeOf(plainDecimal(""))
+}
+
+func (b plainDecimalBackend) FromCqlDecimal(val CqlDecimal) (interface{}, error) {
+	if val.Scale < 0 {
+		return nil, fmt.Errorf("negative scale not supported")
+	}
+	unscaled := new(big.Int).Abs(val.Unscaled).String()
+	if pad := int(val.Scale) + 1 - len(unscaled); pad > 0 {
+		unscaled = strings.Repeat("0", pad) + unscaled
+	}
+	s := unscaled
+	if val.Scale > 0 {
+		s = unscaled[:len(unscaled)-int(val.Scale)] + "." + unscaled[len(unscaled)-int(val.Scale):]
+	}
+	if val.Unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return plainDecimal(s), nil
+}
+
+func (b plainDecimalBackend) ToCqlDecimal(val interface{}) (CqlDecimal, error) {
+	s := string(val.(plainDecimal))
+	scale := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		scale = len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+	unscaled, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return CqlDecimal{}, fmt.Errorf("invalid decimal: %v", val)
+	}
+	return CqlDecimal{Unscaled: unscaled, Scale: int32(scale)}, nil
+}
+
+func TestRegisterDecimalBackend(t *testing.T) {
+	require.NoError(t, RegisterDecimalBackend(plainDecimalBackend{}))
+	decimalPos := CqlDecimal{Unscaled: big.NewInt(123), Scale: 2}
+	decimalNeg := CqlDecimal{Unscaled: big.NewInt(-5), Scale: 3}
+	decimalPosBytes := []byte{0, 0, 0, 2, 123}
+	decimalNegBytes := []byte{0, 0, 0, 3, 0xfb}
+	t.Run("encode", func(t *testing.T) {
+		for _, version := range primitive.SupportedProtocolVersions() {
+			for _, tt := range []struct {
+				name     string
+				source   interface{}
+				expected []byte
+				err      string
+			}{
+				{"value", plainDecimal("1.23"), decimalPosBytes, ""},
+				{"pointer", plainDecimalPtr("-0.005"), decimalNegBytes, ""},
+				{"nil pointer", (*plainDecimal)(nil), nil, ""},
+				{"conversion failed", plainDecimal("abc"), nil, "invalid decimal: abc"},
+			} {
+				t.Run(fmt.Sprintf("%v/%v", version, tt.name), func(t *testing.T) {
+					actual, err := Decimal.Encode(tt.source, version)
+					assert.Equal(t, tt.expected, actual)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		}
+	})
+	t.Run("decode", func(t *testing.T) {
+		for _, version := range primitive.SupportedProtocolVersions() {
+			for _, tt := range []struct {
+				name     string
+				source   []byte
+				dest     interface{}
+				expected interface{}
+				wasNull  bool
+				err      string
+			}{
+				{"positive", decimalPosBytes, new(plainDecimal), plainDecimalPtr("1.23"), false, ""},
+				{"negative", decimalNegBytes, new(plainDecimal), plainDecimalPtr("-0.005"), false, ""},
+				{"null", nil, plainDecimalPtr("1"), new(plainDecimal), true, ""},
+				{"nil dest", decimalPosBytes, (*plainDecimal)(nil), (*plainDecimal)(nil), false, "destination is nil"},
+				{"conversion failed", []byte{0xff, 0xff, 0xff, 0xff, 1}, new(plainDecimal), new(plainDecimal), false, "negative scale not supported"},
+			} {
+				t.Run(fmt.Sprintf("%v/%v", version, tt.name), func(t *testing.T) {
+					wasNull, err := Decimal.Decode(tt.source, tt.dest, version)
+					assert.Equal(t, tt.expected, tt.dest)
+					assert.Equal(t, tt.wasNull, wasNull)
+					assertErrorMessage(t, tt.err, err)
+				})
+			}
+		}
+	})
+	t.Run("round trip", func(t *testing.T) {
+		for _, val := range []CqlDecimal{decimalPos, decimalNeg} {
+			encoded, err := Decimal.Encode(val, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			var decoded plainDecimal
+			_, err = Decimal.Decode(encoded, &decoded, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			reencoded, err := Decimal.Encode(decoded, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, encoded, reencoded)
+		}
+	})
+	t.Run("infer data type", func(t *testing.T) {
+		dt, err := InferDataType(plainDecimal("1"))
+		require.NoError(t, err)
+		assert.Equal(t, datatype.Decimal, dt)
+	})
+	t.Run("invalid backends", func(t *testing.T) {
+		assert.Error(t, RegisterDecimalBackend(nil))
+		assert.Error(t, RegisterDecimalBackend(typeDecimalBackend{t: reflect.TypeOf(new(big.Float))}))
+		assert.Error(t, RegisterDecimalBackend(typeDecimalBackend{t: typeOfCqlDecimal}))
+	})
+}
+
+func plainDecimalPtr(s string) *plainDecimal {
+	d := plainDecimal(s)
+	return &d
+}
+
+type typeDecimalBackend struct {
+	plainDecimalBackend
+	t reflect.Type
+}
+
+func (b typeDecimalBackend) Type() reflect.Type {
+	return b.t
+}
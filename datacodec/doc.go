@@ -45,6 +45,10 @@
 //  - NewTuple
 //  - NewUserDefined
 //
+// Codecs for CQL custom types can be obtained through NewCustom; by default they are identical to the Blob codec, but
+// codecs for specific custom type classes can be registered with RegisterCustomCodec. The DateRange codec, for DSE's
+// DateRangeType, is registered by default.
+//
 // Using a codec
 //
 // Codecs accept a wide variety of inputs, but they have a "preferred" Go type that is the ideal representation of the
@@ -63,6 +67,7 @@
 //                        | int[64-8], *int[64-8], uint[64-8], *uint[64-8]  | days since Unix epoch
 //                        | string, *string                                 | parsed according to layout, default is "2006-01-02"
 //  decimal               | CqlDecimal, *CqlDecimal                         |
+//                        | registered backend types (3)                    | see RegisterDecimalBackend
 //  double                | float64, *float64                               |
 //                        | float32, *float32                               |
 //                        | *big.Float                                      |
@@ -111,6 +116,8 @@
 // accepted when encoding, never when decoding.
 // (2) when mapping structs to user-defined types, the "cassandra" field tag can be used to override the corresponding
 // field name.
+// (3) alternative decimal representations, e.g. decimal.Decimal from github.com/shopspring/decimal, can be registered
+// with RegisterDecimalBackend.
 //
 // In addition to the accepted types above, all codecs also accept interface{} when encoding and *interface{} when
 // decoding. When encoding an interface{} value, the actual runtime value stored in the variable must be of an
@@ -122,6 +129,43 @@
 // Sources can be passed by value or by reference, unless specified otherwise in the table above. Nils are encoded as
 // CQL NULLs; encoding such a value is generally a no-op and returns a nil []byte.
 //
+// Encoding query values
+//
+// The EncodePositionalValues function can be used to convert plain Go values into protocol [value]s, suitable for use in
+// QUERY and EXECUTE requests. Data types can be provided as hints, typically from a prepared statement's variables
+// metadata; when no hint is provided, the data type is inferred from the Go value with InferDataType:
+//
+//  values, err := datacodec.EncodePositionalValues([]interface{}{"abc", int32(42)}, nil, primitive.ProtocolVersion4)
+//  query := &message.Query{
+// 	  Query:   "INSERT INTO ks.t (k, v) VALUES (?, ?)",
+// 	  Options: &message.QueryOptions{PositionalValues: values},
+//  }
+//
+// The EncodeNamedValues function is similar, but converts a map of named Go values; when the variables metadata of a
+// prepared statement is provided, names are matched against it case-insensitively, and missing or unused names are
+// reported as errors.
+//
+// Scanning rows
+//
+// The ScanRows function decodes a whole RowsResult into a slice of structs, using the "cql" field tag, or else the
+// field name, to map columns to struct fields; ScanRow does the same for a single row:
+//
+//  type User struct {
+// 	  Id   int32
+// 	  Name string `cql:"user_name"`
+//  }
+//  var users []User
+//  err := datacodec.ScanRows(rowsResult, &users, primitive.ProtocolVersion4)
+//
+// When no struct is available, the RowsToMaps and RowToMap functions can be used instead: they decode rows into maps
+// keyed by column name, using the preferred Go type of each column.
+//
+// By default, NULL cells are decoded into the zero value of their destination. A RowDecoder can be used to choose a
+// different NullHandling: NULLs can be decoded into pointers (NullHandlingPointer), or into sql.Null-style wrappers
+// such as sql.NullString or Nullable (NullHandlingWrapper). The NullBitmap function can be used to tell NULL cells
+// apart when decoding into zero values. Null handling is a property of the RowDecoder, not of codecs: codecs only
+// report NULL cells through their wasNull return value, see Codec.Decode.
+//
 // Decoding data
 //
 // Destination values must be passed by reference, see examples below. This is also valid for slices and maps, and is
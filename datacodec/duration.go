@@ -33,6 +33,31 @@ type CqlDuration struct {
 	Nanos  time.Duration
 }
 
+// NewCqlDuration creates a CqlDuration from the given time.Duration. The conversion is always lossless: the result has
+// zero months and days.
+func NewCqlDuration(d time.Duration) CqlDuration {
+	return CqlDuration{Nanos: d}
+}
+
+// ToDuration converts this CqlDuration to a time.Duration. Months and days do not have a fixed length: a month has 28
+// to 31 days, and a day may have 23 to 25 hours around daylight saving time transitions. This is why the conversion
+// fails with ErrDurationNotExact if Months or Days is not zero, instead of approximating. Use AddTo to apply such
+// durations to a point in time instead.
+func (d CqlDuration) ToDuration() (time.Duration, error) {
+	if d.Months != 0 || d.Days != 0 {
+		return 0, fmt.Errorf("cannot convert CQL duration with %d months and %d days to time.Duration: %w",
+			d.Months, d.Days, ErrDurationNotExact)
+	}
+	return d.Nanos, nil
+}
+
+// AddTo returns the given time plus this duration. Months and days are added first, using calendar arithmetic in the
+// time's location, see time.Time.AddDate; nanoseconds are added last. This is how Cassandra applies durations to
+// timestamps.
+func (d CqlDuration) AddTo(t time.Time) time.Time {
+	return t.AddDate(0, int(d.Months), int(d.Days)).Add(d.Nanos)
+}
+
 // Duration is a codec for the CQL duration type, introduced in protocol v5. There is no built-in representation of
 // arbitrary-precision duration values in Go's standard library. This is why this codec can only encode from and decode
 // to CqlDuration.
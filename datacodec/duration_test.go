@@ -15,9 +15,11 @@
 package datacodec
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -39,6 +41,63 @@ var (
 	cqlDurationMinBytes = []byte{0xf0, 0xff, 0xff, 0xff, 0xff, 0xf0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
 )
 
+func TestNewCqlDuration(t *testing.T) {
+	assert.Equal(t, CqlDuration{Nanos: 90 * time.Minute}, NewCqlDuration(90*time.Minute))
+	assert.Equal(t, CqlDuration{Nanos: -time.Nanosecond}, NewCqlDuration(-time.Nanosecond))
+}
+
+func TestCqlDuration_ToDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration CqlDuration
+		want     time.Duration
+		wantErr  string
+	}{
+		{"zero", cqlDurationZero, 0, ""},
+		{"nanos only", CqlDuration{Nanos: 90 * time.Minute}, 90 * time.Minute, ""},
+		{"negative nanos only", CqlDuration{Nanos: -3}, -3, ""},
+		{"max nanos", CqlDuration{Nanos: math.MaxInt64}, math.MaxInt64, ""},
+		{"months", CqlDuration{Months: 1}, 0, "cannot convert CQL duration with 1 months and 0 days to time.Duration: months and days have no fixed length"},
+		{"days", CqlDuration{Days: -2, Nanos: -3}, 0, "cannot convert CQL duration with 0 months and -2 days to time.Duration: months and days have no fixed length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := tt.duration.ToDuration()
+			assert.Equal(t, tt.want, got)
+			assertErrorMessage(t, tt.wantErr, gotErr)
+			if gotErr != nil {
+				assert.True(t, errors.Is(gotErr, ErrDurationNotExact))
+			}
+		})
+	}
+}
+
+func TestCqlDuration_AddTo(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	// 2021-03-28 is 23 hours long in Paris
+	start := time.Date(2021, 1, 31, 12, 0, 0, 0, paris)
+	tests := []struct {
+		name     string
+		duration CqlDuration
+		want     time.Time
+	}{
+		{"zero", cqlDurationZero, start},
+		{"nanos", CqlDuration{Nanos: time.Hour}, time.Date(2021, 1, 31, 13, 0, 0, 0, paris)},
+		{"months", CqlDuration{Months: 2}, time.Date(2021, 3, 31, 12, 0, 0, 0, paris)},
+		{"days across DST", CqlDuration{Days: 57}, time.Date(2021, 3, 29, 12, 0, 0, 0, paris)},
+		{"all", CqlDuration{1, 1, time.Minute}, time.Date(2021, 3, 4, 12, 1, 0, 0, paris)},
+		{"negative", CqlDuration{-1, -1, -time.Minute}, time.Date(2020, 12, 30, 11, 59, 0, 0, paris)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(tt.duration.AddTo(start)), tt.duration.AddTo(start))
+		})
+	}
+}
+
 func Test_durationCodec_DataType(t *testing.T) {
 	assert.Equal(t, datatype.Duration, Duration.DataType())
 }
@@ -18,6 +18,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -32,6 +34,10 @@ var ErrDestinationTypeNotSupported = errors.New("destination type not supported"
 
 var ErrPointerTypeExpected = errors.New("destination is not pointer")
 
+var ErrNoColumnMetadata = errors.New("rows metadata contains no column metadata")
+
+var ErrDurationNotExact = errors.New("months and days have no fixed length")
+
 func errCannotEncode(source interface{}, dataType datatype.DataType, version primitive.ProtocolVersion, err error) error {
 	return fmt.Errorf("cannot encode %T as CQL %s with %v: %w", source, dataType, version, err)
 }
@@ -248,3 +254,19 @@ func errDestinationInvalid(dest interface{}) error {
 		return ErrConversionNotSupported
 	}
 }
+
+func errCannotDecodeColumn(i int, name string, err error) error {
+	return fmt.Errorf("cannot decode column %d (%s): %w", i, name, err)
+}
+
+func errNamedValuesMismatch(missing []string, unused []string) error {
+	sort.Strings(unused)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing values for variables: %s", strings.Join(missing, ", ")))
+	}
+	if len(unused) > 0 {
+		problems = append(problems, fmt.Sprintf("unused values: %s", strings.Join(unused, ", ")))
+	}
+	return fmt.Errorf("named values do not match variables: %s", strings.Join(problems, "; "))
+}
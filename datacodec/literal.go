@@ -0,0 +1,384 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// literalProtocolVersion is the protocol version used by FormatCqlLiteral to encode values before formatting them. It
+// is the highest stable version, which supports all the CQL types.
+const literalProtocolVersion = primitive.ProtocolVersion5
+
+// literalTimestampLayout is the layout used to format CQL timestamp literals.
+const literalTimestampLayout = "2006-01-02T15:04:05.000Z"
+
+var unquotedIdentifierRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FormatCqlLiteral formats the given value as a CQL literal of the given data type, e.g. 'abc' for a varchar,
+// 0xcafe for a blob, [1, 2] for a list<int>, or {name: 'Alice', age: 42} for a user-defined type. The value can be of
+// any Go type accepted by the codec for the data type, see NewCodec; nil values are formatted as null. This is
+// primarily intended for debugging, and for reconstructing CQL statements from bound values, e.g. in proxies.
+func FormatCqlLiteral(value interface{}, dt datatype.DataType) (string, error) {
+	codec, err := NewCodec(dt)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := codec.Encode(value, literalProtocolVersion)
+	if err != nil {
+		return "", err
+	}
+	return FormatEncodedCqlLiteral(encoded, dt, literalProtocolVersion)
+}
+
+// FormatEncodedCqlLiteral formats the given encoded value as a CQL literal of the given data type, see
+// FormatCqlLiteral. The value must have been encoded with the given protocol version; a nil slice is formatted as null.
+// Map entries are formatted in the order of their formatted keys, so that the output is deterministic.
+func FormatEncodedCqlLiteral(encoded []byte, dt datatype.DataType, version primitive.ProtocolVersion) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := writeLiteral(buf, encoded, dt, version); err != nil {
+		return "", fmt.Errorf("cannot format %v literal: %w", dt, err)
+	}
+	return buf.String(), nil
+}
+
+func writeLiteral(buf *bytes.Buffer, encoded []byte, dt datatype.DataType, version primitive.ProtocolVersion) error {
+	if encoded == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeList:
+		return writeCollectionLiteral(buf, encoded, "[", "]", dt.(*datatype.List).ElementType, version)
+	case primitive.DataTypeCodeSet:
+		return writeCollectionLiteral(buf, encoded, "{", "}", dt.(*datatype.Set).ElementType, version)
+	case primitive.DataTypeCodeMap:
+		return writeMapLiteral(buf, encoded, dt.(*datatype.Map), version)
+	case primitive.DataTypeCodeTuple:
+		return writeTupleLiteral(buf, encoded, dt.(*datatype.Tuple), version)
+	case primitive.DataTypeCodeUdt:
+		return writeUdtLiteral(buf, encoded, dt.(*datatype.UserDefined), version)
+	}
+	literal, err := formatScalarLiteral(encoded, dt, version)
+	if err == nil {
+		buf.WriteString(literal)
+	}
+	return err
+}
+
+func readCollectionElement(reader *bytes.Reader, version primitive.ProtocolVersion) ([]byte, error) {
+	if version.Uses4BytesCollectionLength() {
+		return primitive.ReadBytes(reader)
+	}
+	return primitive.ReadShortBytes(reader)
+}
+
+func writeCollectionLiteral(
+	buf *bytes.Buffer,
+	encoded []byte,
+	open, end string,
+	elementType datatype.DataType,
+	version primitive.ProtocolVersion,
+) error {
+	reader := bytes.NewReader(encoded)
+	size, err := readCollectionSize(reader, version)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(open)
+	for i := 0; i < size; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if encodedElem, err := readCollectionElement(reader, version); err != nil {
+			return errCannotReadElement(i, err)
+		} else if err = writeLiteral(buf, encodedElem, elementType, version); err != nil {
+			return errCannotDecodeElement(i, err)
+		}
+	}
+	buf.WriteString(end)
+	if remaining := reader.Len(); remaining != 0 {
+		return errBytesRemaining(len(encoded), remaining)
+	}
+	return nil
+}
+
+func writeMapLiteral(buf *bytes.Buffer, encoded []byte, mapType *datatype.Map, version primitive.ProtocolVersion) error {
+	reader := bytes.NewReader(encoded)
+	size, err := readCollectionSize(reader, version)
+	if err != nil {
+		return err
+	}
+	entries := make([]string, size)
+	keys := make([]string, size)
+	for i := 0; i < size; i++ {
+		key := &bytes.Buffer{}
+		value := &bytes.Buffer{}
+		if encodedKey, err := readCollectionElement(reader, version); err != nil {
+			return errCannotReadMapKey(i, err)
+		} else if err = writeLiteral(key, encodedKey, mapType.KeyType, version); err != nil {
+			return errCannotDecodeMapKey(i, err)
+		} else if encodedValue, err := readCollectionElement(reader, version); err != nil {
+			return errCannotReadMapValue(i, err)
+		} else if err = writeLiteral(value, encodedValue, mapType.ValueType, version); err != nil {
+			return errCannotDecodeMapValue(i, err)
+		}
+		keys[i] = key.String()
+		entries[i] = key.String() + ": " + value.String()
+	}
+	if remaining := reader.Len(); remaining != 0 {
+		return errBytesRemaining(len(encoded), remaining)
+	}
+	sort.Sort(literalEntries{keys, entries})
+	buf.WriteString("{" + strings.Join(entries, ", ") + "}")
+	return nil
+}
+
+// literalEntries sorts formatted map entries by their formatted keys.
+type literalEntries struct {
+	keys    []string
+	entries []string
+}
+
+func (e literalEntries) Len() int           { return len(e.keys) }
+func (e literalEntries) Less(i, j int) bool { return e.keys[i] < e.keys[j] }
+func (e literalEntries) Swap(i, j int) {
+	e.keys[i], e.keys[j] = e.keys[j], e.keys[i]
+	e.entries[i], e.entries[j] = e.entries[j], e.entries[i]
+}
+
+func writeTupleLiteral(buf *bytes.Buffer, encoded []byte, tupleType *datatype.Tuple, version primitive.ProtocolVersion) error {
+	reader := bytes.NewReader(encoded)
+	buf.WriteString("(")
+	for i, fieldType := range tupleType.FieldTypes {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if encodedElem, err := primitive.ReadBytes(reader); err != nil {
+			return errCannotReadElement(i, err)
+		} else if err = writeLiteral(buf, encodedElem, fieldType, version); err != nil {
+			return errCannotDecodeElement(i, err)
+		}
+	}
+	buf.WriteString(")")
+	if remaining := reader.Len(); remaining != 0 {
+		return errBytesRemaining(len(encoded), remaining)
+	}
+	return nil
+}
+
+func writeUdtLiteral(buf *bytes.Buffer, encoded []byte, udtType *datatype.UserDefined, version primitive.ProtocolVersion) error {
+	reader := bytes.NewReader(encoded)
+	buf.WriteString("{")
+	for i, fieldType := range udtType.FieldTypes {
+		name := udtType.FieldNames[i]
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(FormatCqlIdentifier(name))
+		buf.WriteString(": ")
+		if encodedField, err := primitive.ReadBytes(reader); err != nil {
+			return errCannotReadUdtField(i, name, err)
+		} else if err = writeLiteral(buf, encodedField, fieldType, version); err != nil {
+			return errCannotDecodeUdtField(i, name, err)
+		}
+	}
+	buf.WriteString("}")
+	if remaining := reader.Len(); remaining != 0 {
+		return errBytesRemaining(len(encoded), remaining)
+	}
+	return nil
+}
+
+// FormatCqlIdentifier formats the given identifier, e.g. a UDT field name, for inclusion in a CQL statement: it is
+// returned as is if it only contains lowercase alphanumeric characters and underscores, and starts with a letter;
+// otherwise it is enclosed in double quotes.
+func FormatCqlIdentifier(identifier string) string {
+	if unquotedIdentifierRegexp.MatchString(identifier) {
+		return identifier
+	}
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func formatScalarLiteral(encoded []byte, dt datatype.DataType, version primitive.ProtocolVersion) (string, error) {
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii, primitive.DataTypeCodeVarchar:
+		var val string
+		if _, err := Varchar.Decode(encoded, &val, version); err != nil {
+			return "", err
+		}
+		return quoteLiteral(val), nil
+	case primitive.DataTypeCodeBlob, primitive.DataTypeCodeCustom:
+		return "0x" + hex.EncodeToString(encoded), nil
+	case primitive.DataTypeCodeVarint:
+		if len(encoded) == 0 {
+			return "null", nil
+		}
+		return primitive.DecodeTwosComplement(encoded).String(), nil
+	}
+	// the remaining types are fixed-length or never empty: an empty value is a null value
+	if len(encoded) == 0 {
+		return "null", nil
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeBigint, primitive.DataTypeCodeCounter:
+		val, _, err := readInt64(encoded)
+		return strconv.FormatInt(val, 10), err
+	case primitive.DataTypeCodeInt:
+		val, _, err := readInt32(encoded)
+		return strconv.FormatInt(int64(val), 10), err
+	case primitive.DataTypeCodeSmallint:
+		val, _, err := readInt16(encoded)
+		return strconv.FormatInt(int64(val), 10), err
+	case primitive.DataTypeCodeTinyint:
+		val, _, err := readInt8(encoded)
+		return strconv.FormatInt(int64(val), 10), err
+	case primitive.DataTypeCodeBoolean:
+		val, _, err := readBool(encoded)
+		return strconv.FormatBool(val), err
+	case primitive.DataTypeCodeFloat:
+		val, _, err := readFloat32(encoded)
+		return formatFloatLiteral(float64(val), 32), err
+	case primitive.DataTypeCodeDouble:
+		val, _, err := readFloat64(encoded)
+		return formatFloatLiteral(val, 64), err
+	case primitive.DataTypeCodeDecimal:
+		val, _, err := readDecimal(encoded)
+		return formatDecimalLiteral(val), err
+	case primitive.DataTypeCodeDuration:
+		val, _, err := readDuration(encoded)
+		return formatDurationLiteral(val), err
+	case primitive.DataTypeCodeInet:
+		val, _, err := readInet(encoded)
+		return quoteLiteral(val.String()), err
+	case primitive.DataTypeCodeUuid, primitive.DataTypeCodeTimeuuid:
+		val, _, err := readUuid(encoded)
+		if err != nil {
+			return "", err
+		}
+		var uuid primitive.UUID
+		copy(uuid[:], val)
+		return uuid.String(), nil
+	case primitive.DataTypeCodeTimestamp:
+		millis, _, err := readInt64(encoded)
+		if err != nil {
+			return "", err
+		}
+		if t := time.UnixMilli(millis).UTC(); t.Year() >= 0 && t.Year() <= 9999 {
+			return quoteLiteral(t.Format(literalTimestampLayout)), nil
+		}
+		// timestamps outside the range of the ISO-8601 layout can only be expressed as milliseconds since the Epoch
+		return strconv.FormatInt(millis, 10), nil
+	case primitive.DataTypeCodeDate:
+		if len(encoded) != primitive.LengthOfInt {
+			return "", errCannotRead(int32(0), errWrongFixedLength(primitive.LengthOfInt, len(encoded)))
+		}
+		return quoteLiteral(FormatDateLiteral(ConvertDateWireValueToEpochDays(binary.BigEndian.Uint32(encoded)))), nil
+	case primitive.DataTypeCodeTime:
+		nanos, _, err := readInt64(encoded)
+		if err != nil {
+			return "", err
+		}
+		d := time.Duration(nanos)
+		return fmt.Sprintf("'%02d:%02d:%02d.%09d'",
+			d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second, d%time.Second), nil
+	}
+	return "", errDataTypeNotSupported(dt, version)
+}
+
+func formatFloatLiteral(val float64, bitSize int) string {
+	switch {
+	case math.IsNaN(val):
+		return "NaN"
+	case math.IsInf(val, 1):
+		return "Infinity"
+	case math.IsInf(val, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(val, 'g', -1, bitSize)
+}
+
+func formatDecimalLiteral(val CqlDecimal) string {
+	unscaled := zeroBigInt
+	if val.Unscaled != nil {
+		unscaled = val.Unscaled
+	}
+	digits := unscaled.String()
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if val.Scale <= 0 {
+		if unscaled.Sign() == 0 {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", int(-val.Scale))
+	}
+	scale := int(val.Scale)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// formatDurationLiteral formats the given duration using the CQL duration format, e.g. 1mo2d3h4m5s6ms7us8ns.
+func formatDurationLiteral(val CqlDuration) string {
+	if val.Months == 0 && val.Days == 0 && val.Nanos == 0 {
+		return "0s"
+	}
+	buf := &strings.Builder{}
+	months, days, nanos := int64(val.Months), int64(val.Days), int64(val.Nanos)
+	if months < 0 || days < 0 || nanos < 0 {
+		buf.WriteString("-")
+		months, days, nanos = -months, -days, -nanos
+	}
+	units := []struct {
+		value  int64
+		suffix string
+	}{
+		{months / 12, "y"},
+		{months % 12, "mo"},
+		{days, "d"},
+		{nanos / int64(time.Hour), "h"},
+		{nanos % int64(time.Hour) / int64(time.Minute), "m"},
+		{nanos % int64(time.Minute) / int64(time.Second), "s"},
+		{nanos % int64(time.Second) / int64(time.Millisecond), "ms"},
+		{nanos % int64(time.Millisecond) / int64(time.Microsecond), "us"},
+		{nanos % int64(time.Microsecond), "ns"},
+	}
+	for _, unit := range units {
+		if unit.value != 0 {
+			buf.WriteString(strconv.FormatInt(unit.value, 10))
+			buf.WriteString(unit.suffix)
+		}
+	}
+	return buf.String()
+}
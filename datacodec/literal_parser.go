@@ -0,0 +1,589 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// literalTimestampLayouts are the layouts accepted when parsing CQL timestamp literals. Fractional seconds are
+// accepted after the seconds field in all layouts. Timestamps without time zone are interpreted in UTC.
+var literalTimestampLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var (
+	timeLiteralRegexp     = regexp.MustCompile(`^(\d{1,2}):(\d{2}):(\d{2})(?:\.(\d{1,9}))?$`)
+	decimalLiteralRegexp  = regexp.MustCompile(`^([+-]?)(\d*)(?:\.(\d*))?(?:[eE]([+-]?\d+))?$`)
+	durationLiteralRegexp = regexp.MustCompile(`(?i)(\d+)(y|mo|w|d|h|ms|m|s|us|µs|ns)`)
+)
+
+// ParseCqlLiteral parses the given CQL literal of the given data type, and returns its encoded value for the given
+// protocol version, e.g. 'abc' for a varchar, 0xcafe for a blob, [1, 2] for a list<int>, or
+// {name: 'Alice', age: 42} for a user-defined type. The literal null is parsed as a nil slice. This is the inverse of
+// FormatCqlLiteral, and is primarily intended to let test fixtures and tools specify bound values as text. In addition
+// to the literals produced by FormatCqlLiteral, the following are accepted:
+//  - timestamps in most ISO-8601 formats, e.g. '2021-10-12 13:14:15+0200'; timestamps without time zone are
+//    interpreted in UTC;
+//  - times without fractional seconds, e.g. '13:14:15', and times as nanoseconds since midnight;
+//  - dates as wire values, see ParseDateLiteral;
+//  - strings enclosed in double dollar signs, e.g. $$it's$$;
+//  - UDT fields in any order; missing fields are null.
+func ParseCqlLiteral(literal string, dt datatype.DataType, version primitive.ProtocolVersion) ([]byte, error) {
+	parser := &literalParser{input: literal, version: version}
+	encoded, err := parser.parseValue(dt)
+	if err == nil {
+		if parser.skipSpaces(); parser.pos < len(parser.input) {
+			err = parser.errUnexpected()
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %v literal %q: %w", dt, literal, err)
+	}
+	return encoded, nil
+}
+
+type literalParser struct {
+	input   string
+	pos     int
+	version primitive.ProtocolVersion
+}
+
+func (p *literalParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *literalParser) errUnexpected() error {
+	if p.pos >= len(p.input) {
+		return errors.New("unexpected end of literal")
+	}
+	return fmt.Errorf("unexpected character %q at position %d", p.input[p.pos], p.pos)
+}
+
+// accept skips spaces, then consumes the given character if it is the next one.
+func (p *literalParser) accept(c byte) bool {
+	if p.skipSpaces(); p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *literalParser) expect(c byte) error {
+	if !p.accept(c) {
+		return p.errUnexpected()
+	}
+	return nil
+}
+
+// acceptNull consumes the null keyword if it is the next token.
+func (p *literalParser) acceptNull() bool {
+	p.skipSpaces()
+	start := p.pos
+	if text, quoted, err := p.token(); err == nil && !quoted && strings.EqualFold(text, "null") {
+		return true
+	}
+	p.pos = start
+	return false
+}
+
+// token reads the next scalar token: either a quoted string, in which case its unescaped contents are returned, or a
+// sequence of characters up to the next space or delimiter.
+func (p *literalParser) token() (text string, quoted bool, err error) {
+	p.skipSpaces()
+	start := p.pos
+	if p.pos < len(p.input) && p.input[p.pos] == '\'' {
+		buf := &strings.Builder{}
+		for p.pos++; p.pos < len(p.input); p.pos++ {
+			if p.input[p.pos] != '\'' {
+				buf.WriteByte(p.input[p.pos])
+			} else if p.pos+1 < len(p.input) && p.input[p.pos+1] == '\'' {
+				buf.WriteByte('\'')
+				p.pos++
+			} else {
+				p.pos++
+				return buf.String(), true, nil
+			}
+		}
+		return "", false, fmt.Errorf("unterminated string starting at position %d", start)
+	} else if strings.HasPrefix(p.input[p.pos:], "$$") {
+		if end := strings.Index(p.input[p.pos+2:], "$$"); end >= 0 {
+			p.pos += end + 4
+			return p.input[start+2 : p.pos-2], true, nil
+		}
+		return "", false, fmt.Errorf("unterminated string starting at position %d", start)
+	}
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n,:[]{}()'", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", false, p.errUnexpected()
+	}
+	return p.input[start:p.pos], false, nil
+}
+
+func (p *literalParser) parseValue(dt datatype.DataType) ([]byte, error) {
+	if !p.version.SupportsDataType(dt.Code()) {
+		return nil, errDataTypeNotSupported(dt, p.version)
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeList:
+		return p.parseCollection('[', ']', dt.(*datatype.List).ElementType)
+	case primitive.DataTypeCodeSet:
+		return p.parseCollection('{', '}', dt.(*datatype.Set).ElementType)
+	case primitive.DataTypeCodeMap:
+		return p.parseMap(dt.(*datatype.Map))
+	case primitive.DataTypeCodeTuple:
+		return p.parseTuple(dt.(*datatype.Tuple))
+	case primitive.DataTypeCodeUdt:
+		return p.parseUdt(dt.(*datatype.UserDefined))
+	}
+	text, quoted, err := p.token()
+	if err != nil {
+		return nil, err
+	} else if !quoted && strings.EqualFold(text, "null") {
+		return nil, nil
+	}
+	return parseScalarLiteral(text, quoted, dt, p.version)
+}
+
+// parseElements parses a comma-separated sequence of elements, up to the given closing character. The opening
+// character must have been consumed already.
+func (p *literalParser) parseElements(end byte, parseElement func(i int) error) error {
+	if p.accept(end) {
+		return nil
+	}
+	for i := 0; ; i++ {
+		if err := parseElement(i); err != nil {
+			return err
+		} else if p.accept(end) {
+			return nil
+		} else if err = p.expect(','); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *literalParser) writeCollectionElement(encoded []byte, dest *bytes.Buffer) error {
+	if p.version.Uses4BytesCollectionLength() {
+		return primitive.WriteBytes(encoded, dest)
+	}
+	return primitive.WriteShortBytes(encoded, dest)
+}
+
+func (p *literalParser) parseCollection(start, end byte, elementType datatype.DataType) ([]byte, error) {
+	if p.acceptNull() {
+		return nil, nil
+	} else if err := p.expect(start); err != nil {
+		return nil, err
+	}
+	elements := &bytes.Buffer{}
+	size := 0
+	err := p.parseElements(end, func(i int) error {
+		size++
+		if encodedElem, err := p.parseValue(elementType); err != nil {
+			return errCannotEncodeElement(i, err)
+		} else {
+			return p.writeCollectionElement(encodedElem, elements)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeCollectionSize(size, buf, p.version); err != nil {
+		return nil, err
+	}
+	buf.Write(elements.Bytes())
+	return buf.Bytes(), nil
+}
+
+func (p *literalParser) parseMap(mapType *datatype.Map) ([]byte, error) {
+	if p.acceptNull() {
+		return nil, nil
+	} else if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	entries := &bytes.Buffer{}
+	size := 0
+	err := p.parseElements('}', func(i int) error {
+		size++
+		if encodedKey, err := p.parseValue(mapType.KeyType); err != nil {
+			return errCannotEncodeMapKey(i, err)
+		} else if err = p.writeCollectionElement(encodedKey, entries); err != nil {
+			return err
+		} else if err = p.expect(':'); err != nil {
+			return err
+		} else if encodedValue, err := p.parseValue(mapType.ValueType); err != nil {
+			return errCannotEncodeMapValue(i, err)
+		} else {
+			return p.writeCollectionElement(encodedValue, entries)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = writeCollectionSize(size, buf, p.version); err != nil {
+		return nil, err
+	}
+	buf.Write(entries.Bytes())
+	return buf.Bytes(), nil
+}
+
+func (p *literalParser) parseTuple(tupleType *datatype.Tuple) ([]byte, error) {
+	if p.acceptNull() {
+		return nil, nil
+	} else if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	count := 0
+	err := p.parseElements(')', func(i int) error {
+		count++
+		if i >= len(tupleType.FieldTypes) {
+			return fmt.Errorf("too many elements, expected %d", len(tupleType.FieldTypes))
+		} else if encodedElem, err := p.parseValue(tupleType.FieldTypes[i]); err != nil {
+			return errCannotEncodeElement(i, err)
+		} else {
+			return primitive.WriteBytes(encodedElem, buf)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// missing trailing elements are null
+	for i := count; i < len(tupleType.FieldTypes); i++ {
+		_ = primitive.WriteBytes(nil, buf)
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *literalParser) parseUdt(udtType *datatype.UserDefined) ([]byte, error) {
+	if p.acceptNull() {
+		return nil, nil
+	} else if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	fields := make([][]byte, len(udtType.FieldNames))
+	err := p.parseElements('}', func(i int) error {
+		name, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		index := -1
+		for j, fieldName := range udtType.FieldNames {
+			if fieldName == name {
+				index = j
+				break
+			}
+		}
+		if index == -1 {
+			return fmt.Errorf("unknown field: %v", name)
+		} else if err = p.expect(':'); err != nil {
+			return err
+		} else if fields[index], err = p.parseValue(udtType.FieldTypes[index]); err != nil {
+			return errCannotEncodeUdtField(index, name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	for _, field := range fields {
+		_ = primitive.WriteBytes(field, buf)
+	}
+	return buf.Bytes(), nil
+}
+
+// identifier reads a CQL identifier, as formatted by FormatCqlIdentifier. Unquoted identifiers are case-insensitive
+// and are converted to lowercase; quoted identifiers are returned as is, once unescaped.
+func (p *literalParser) identifier() (string, error) {
+	p.skipSpaces()
+	start := p.pos
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		buf := &strings.Builder{}
+		for p.pos++; p.pos < len(p.input); p.pos++ {
+			if p.input[p.pos] != '"' {
+				buf.WriteByte(p.input[p.pos])
+			} else if p.pos+1 < len(p.input) && p.input[p.pos+1] == '"' {
+				buf.WriteByte('"')
+				p.pos++
+			} else {
+				p.pos++
+				return buf.String(), nil
+			}
+		}
+		return "", fmt.Errorf("unterminated identifier starting at position %d", start)
+	}
+	text, quoted, err := p.token()
+	if err != nil {
+		return "", err
+	} else if quoted {
+		p.pos = start
+		return "", p.errUnexpected()
+	}
+	return strings.ToLower(text), nil
+}
+
+func parseScalarLiteral(text string, quoted bool, dt datatype.DataType, version primitive.ProtocolVersion) ([]byte, error) {
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii:
+		if !quoted {
+			return nil, errExpectedStringLiteral(text)
+		}
+		return Ascii.Encode(text, version)
+	case primitive.DataTypeCodeVarchar:
+		if !quoted {
+			return nil, errExpectedStringLiteral(text)
+		}
+		return Varchar.Encode(text, version)
+	case primitive.DataTypeCodeInet:
+		if !quoted {
+			return nil, errExpectedStringLiteral(text)
+		}
+		ip := net.ParseIP(text)
+		if ip == nil {
+			return nil, fmt.Errorf("cannot parse %q as inet address", text)
+		}
+		return writeInet(ip)
+	case primitive.DataTypeCodeTimestamp:
+		return parseTimestampLiteral(text, quoted)
+	case primitive.DataTypeCodeDate:
+		days, err := ParseDateLiteral(text)
+		if err != nil {
+			return nil, err
+		}
+		dest := make([]byte, primitive.LengthOfInt)
+		binary.BigEndian.PutUint32(dest, ConvertEpochDaysToDateWireValue(days))
+		return dest, nil
+	case primitive.DataTypeCodeTime:
+		return parseTimeLiteral(text, quoted)
+	}
+	if quoted {
+		return nil, fmt.Errorf("unexpected string literal: '%v'", text)
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeBigint, primitive.DataTypeCodeCounter:
+		val, err := strconv.ParseInt(text, 10, 64)
+		return writeInt64(val), err
+	case primitive.DataTypeCodeInt:
+		val, err := strconv.ParseInt(text, 10, 32)
+		return writeInt32(int32(val)), err
+	case primitive.DataTypeCodeSmallint:
+		val, err := strconv.ParseInt(text, 10, 16)
+		return writeInt16(int16(val)), err
+	case primitive.DataTypeCodeTinyint:
+		val, err := strconv.ParseInt(text, 10, 8)
+		return writeInt8(int8(val)), err
+	case primitive.DataTypeCodeVarint:
+		if val, ok := new(big.Int).SetString(text, 10); ok {
+			return primitive.EncodeTwosComplement(val), nil
+		}
+		return nil, fmt.Errorf("cannot parse %q as varint", text)
+	case primitive.DataTypeCodeBoolean:
+		val, err := strconv.ParseBool(strings.ToLower(text))
+		return writeBool(val), err
+	case primitive.DataTypeCodeFloat:
+		val, err := parseFloatLiteral(text, 32)
+		return writeFloat32(float32(val)), err
+	case primitive.DataTypeCodeDouble:
+		val, err := parseFloatLiteral(text, 64)
+		return writeFloat64(val), err
+	case primitive.DataTypeCodeDecimal:
+		val, err := parseDecimalLiteral(text)
+		if err != nil {
+			return nil, err
+		}
+		return writeDecimal(val), nil
+	case primitive.DataTypeCodeDuration:
+		val, err := parseDurationLiteral(text)
+		if err != nil {
+			return nil, err
+		}
+		return writeDuration(val), nil
+	case primitive.DataTypeCodeUuid, primitive.DataTypeCodeTimeuuid:
+		uuid, err := primitive.ParseUuid(text)
+		if err != nil {
+			return nil, err
+		}
+		return uuid[:], nil
+	case primitive.DataTypeCodeBlob, primitive.DataTypeCodeCustom:
+		if !strings.HasPrefix(text, "0x") && !strings.HasPrefix(text, "0X") {
+			return nil, fmt.Errorf("cannot parse %q as blob, expected 0x prefix", text)
+		}
+		val, err := hex.DecodeString(text[2:])
+		if err != nil {
+			return nil, err
+		}
+		// an empty blob is not a null value
+		return append([]byte{}, val...), nil
+	}
+	return nil, errDataTypeNotSupported(dt, version)
+}
+
+func errExpectedStringLiteral(text string) error {
+	return fmt.Errorf("expected string literal, got: %v", text)
+}
+
+func parseFloatLiteral(text string, bitSize int) (float64, error) {
+	switch text {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity", "+Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(text, bitSize)
+}
+
+func parseDecimalLiteral(text string) (val CqlDecimal, err error) {
+	matches := decimalLiteralRegexp.FindStringSubmatch(text)
+	if matches == nil || matches[2]+matches[3] == "" {
+		return val, fmt.Errorf("cannot parse %q as decimal", text)
+	}
+	scale := int64(len(matches[3]))
+	if matches[4] != "" {
+		exponent, err := strconv.ParseInt(matches[4], 10, 32)
+		if err != nil {
+			return val, errValueOutOfRange(text)
+		}
+		scale -= exponent
+	}
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return val, errValueOutOfRange(text)
+	}
+	val.Unscaled, _ = new(big.Int).SetString(matches[1]+matches[2]+matches[3], 10)
+	val.Scale = int32(scale)
+	return val, nil
+}
+
+func parseDurationLiteral(text string) (val CqlDuration, err error) {
+	negative := strings.HasPrefix(text, "-")
+	units := strings.TrimPrefix(text, "-")
+	matches := durationLiteralRegexp.FindAllStringSubmatchIndex(units, -1)
+	if len(matches) == 0 {
+		return val, fmt.Errorf("cannot parse %q as duration", text)
+	}
+	var months, days, nanos int64
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			return val, fmt.Errorf("cannot parse %q as duration", text)
+		}
+		end = match[1]
+		amount, err := strconv.ParseInt(units[match[2]:match[3]], 10, 64)
+		if err != nil {
+			return val, errValueOutOfRange(text)
+		}
+		switch strings.ToLower(units[match[4]:match[5]]) {
+		case "y":
+			months += amount * 12
+		case "mo":
+			months += amount
+		case "w":
+			days += amount * 7
+		case "d":
+			days += amount
+		case "h":
+			nanos += amount * int64(time.Hour)
+		case "m":
+			nanos += amount * int64(time.Minute)
+		case "s":
+			nanos += amount * int64(time.Second)
+		case "ms":
+			nanos += amount * int64(time.Millisecond)
+		case "us", "µs":
+			nanos += amount * int64(time.Microsecond)
+		case "ns":
+			nanos += amount
+		}
+	}
+	if end != len(units) {
+		return val, fmt.Errorf("cannot parse %q as duration", text)
+	} else if months > math.MaxInt32 || days > math.MaxInt32 || nanos < 0 {
+		return val, errValueOutOfRange(text)
+	}
+	if negative {
+		months, days, nanos = -months, -days, -nanos
+	}
+	return CqlDuration{Months: int32(months), Days: int32(days), Nanos: time.Duration(nanos)}, nil
+}
+
+func parseTimestampLiteral(text string, quoted bool) ([]byte, error) {
+	if !quoted {
+		millis, err := strconv.ParseInt(text, 10, 64)
+		return writeInt64(millis), err
+	}
+	for _, layout := range literalTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			return writeInt64(t.UnixMilli()), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot parse %q as timestamp", text)
+}
+
+func parseTimeLiteral(text string, quoted bool) ([]byte, error) {
+	var nanos int64
+	if !quoted {
+		var err error
+		if nanos, err = strconv.ParseInt(text, 10, 64); err != nil {
+			return nil, err
+		}
+	} else if matches := timeLiteralRegexp.FindStringSubmatch(text); matches != nil {
+		hours, _ := strconv.ParseInt(matches[1], 10, 64)
+		minutes, _ := strconv.ParseInt(matches[2], 10, 64)
+		seconds, _ := strconv.ParseInt(matches[3], 10, 64)
+		fraction, _ := strconv.ParseInt((matches[4] + "000000000")[:9], 10, 64)
+		if minutes > 59 || seconds > 59 {
+			return nil, fmt.Errorf("cannot parse %q as time", text)
+		}
+		nanos = hours*int64(time.Hour) + minutes*int64(time.Minute) + seconds*int64(time.Second) + fraction
+	} else {
+		return nil, fmt.Errorf("cannot parse %q as time", text)
+	}
+	if nanos < 0 || nanos >= int64(24*time.Hour) {
+		return nil, errValueOutOfRange(text)
+	}
+	return writeInt64(nanos), nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestParseCqlLiteral(t *testing.T) {
+	udtType, _ := datatype.NewUserDefined("ks1", "type1",
+		[]string{"name", "Age", "tags"},
+		[]datatype.DataType{datatype.Varchar, datatype.Int, datatype.NewSet(datatype.Varchar)},
+	)
+	tests := []struct {
+		literal  string
+		dt       datatype.DataType
+		expected string
+	}{
+		{"null", datatype.Int, "null"},
+		{"NULL", datatype.Varchar, "null"},
+		{"'it''s'", datatype.Varchar, "'it''s'"},
+		{"$$it's$$", datatype.Varchar, "'it''s'"},
+		{"''", datatype.Ascii, "''"},
+		{"-42", datatype.Int, "-42"},
+		{"9223372036854775807", datatype.Bigint, "9223372036854775807"},
+		{"-128", datatype.Tinyint, "-128"},
+		{"-123456789012345678901234567890", datatype.Varint, "-123456789012345678901234567890"},
+		{"TRUE", datatype.Boolean, "true"},
+		{"1.5", datatype.Float, "1.5"},
+		{"1e-3", datatype.Double, "0.001"},
+		{"NaN", datatype.Double, "NaN"},
+		{"-Infinity", datatype.Float, "-Infinity"},
+		{"-123.45", datatype.Decimal, "-123.45"},
+		{"1.5e3", datatype.Decimal, "1500"},
+		{".005", datatype.Decimal, "0.005"},
+		{"0xCAFE", datatype.Blob, "0xcafe"},
+		{"0x", datatype.Blob, "0x"},
+		{"fe2b4360-28c6-11e2-81c1-0800200c9a66", datatype.Timeuuid, "fe2b4360-28c6-11e2-81c1-0800200c9a66"},
+		{"'::1'", datatype.Inet, "'::1'"},
+		{"'2021-10-12T13:14:15.123Z'", datatype.Timestamp, "'2021-10-12T13:14:15.123Z'"},
+		{"'2021-10-12 15:14:15+0200'", datatype.Timestamp, "'2021-10-12T13:14:15.000Z'"},
+		{"'2021-10-12'", datatype.Timestamp, "'2021-10-12T00:00:00.000Z'"},
+		{"1634044455123", datatype.Timestamp, "'2021-10-12T13:14:15.123Z'"},
+		{"'2021-10-12'", datatype.Date, "'2021-10-12'"},
+		{"2147483648", datatype.Date, "'1970-01-01'"},
+		{"'13:14:15'", datatype.Time, "'13:14:15.000000000'"},
+		{"'13:14:15.5'", datatype.Time, "'13:14:15.500000000'"},
+		{"1", datatype.Time, "'00:00:00.000000001'"},
+		{"1y2mo2d1h3ms", datatype.Duration, "1y2mo2d1h3ms"},
+		{"-1w1s", datatype.Duration, "-7d1s"},
+		{"90m", datatype.Duration, "1h30m"},
+		{" [ 1 , 2,3 ] ", datatype.NewList(datatype.Int), "[1, 2, 3]"},
+		{"[]", datatype.NewList(datatype.Int), "[]"},
+		{"{'a', 'b'}", datatype.NewSet(datatype.Varchar), "{'a', 'b'}"},
+		{"{'b': 2, 'a': 1}", datatype.NewMap(datatype.Varchar, datatype.Int), "{'a': 1, 'b': 2}"},
+		{"{'k': [1, null]}", datatype.NewMap(datatype.Varchar, datatype.NewList(datatype.Int)), "{'k': [1, null]}"},
+		{"(1, 'a', null)", datatype.NewTuple(datatype.Int, datatype.Varchar, datatype.Int), "(1, 'a', null)"},
+		{"(1)", datatype.NewTuple(datatype.Int, datatype.Varchar), "(1, null)"},
+		{`{tags: {'x'}, NAME: 'Alice', "Age": 42}`, udtType, `{name: 'Alice', "Age": 42, tags: {'x'}}`},
+		{"{}", udtType, "{name: null, \"Age\": null, tags: null}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			encoded, err := ParseCqlLiteral(tt.literal, tt.dt, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			actual, err := FormatEncodedCqlLiteral(encoded, tt.dt, primitive.ProtocolVersion5)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseCqlLiteral_ProtocolV2(t *testing.T) {
+	encoded, err := ParseCqlLiteral("[1, 2]", datatype.NewList(datatype.Int), primitive.ProtocolVersion2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 2, 0, 4, 0, 0, 0, 1, 0, 4, 0, 0, 0, 2}, encoded)
+}
+
+func TestParseCqlLiteral_Errors(t *testing.T) {
+	udtType, _ := datatype.NewUserDefined("ks1", "type1", []string{"f1"}, []datatype.DataType{datatype.Int})
+	tests := []struct {
+		literal string
+		dt      datatype.DataType
+		err     string
+	}{
+		{"", datatype.Int, "unexpected end of literal"},
+		{"1 2", datatype.Int, "unexpected character '2' at position 2"},
+		{"abc", datatype.Varchar, "expected string literal, got: abc"},
+		{"'abc", datatype.Varchar, "unterminated string starting at position 0"},
+		{"'1'", datatype.Int, "unexpected string literal: '1'"},
+		{"2147483648", datatype.Int, "value out of range"},
+		{"0xZZ", datatype.Blob, "invalid byte"},
+		{"cafe", datatype.Blob, "expected 0x prefix"},
+		{"1x", datatype.Duration, "cannot parse \"1x\" as duration"},
+		{"'25:00:00'", datatype.Time, "value out of range: 25:00:00"},
+		{"'not a date'", datatype.Timestamp, "cannot parse \"not a date\" as timestamp"},
+		{"[1, 2", datatype.NewList(datatype.Int), "unexpected end of literal"},
+		{"[1; 2]", datatype.NewList(datatype.Int), `parsing "1;": invalid syntax`},
+		{"{1: }", datatype.NewMap(datatype.Int, datatype.Int), "cannot encode entry 0 value"},
+		{"(1, 2)", datatype.NewTuple(datatype.Int), "too many elements, expected 1"},
+		{"{f2: 1}", udtType, "unknown field: f2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			encoded, err := ParseCqlLiteral(tt.literal, tt.dt, primitive.ProtocolVersion5)
+			assert.Nil(t, encoded)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+	t.Run("unsupported data type", func(t *testing.T) {
+		_, err := ParseCqlLiteral("1s", datatype.Duration, primitive.ProtocolVersion4)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not supported")
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFormatCqlLiteral(t *testing.T) {
+	uuid, _ := primitive.ParseUuid("fe2b4360-28c6-11e2-81c1-0800200c9a66")
+	udtType, _ := datatype.NewUserDefined("ks1", "type1",
+		[]string{"name", "Age", "tags"},
+		[]datatype.DataType{datatype.Varchar, datatype.Int, datatype.NewSet(datatype.Varchar)},
+	)
+	tests := []struct {
+		name     string
+		value    interface{}
+		dt       datatype.DataType
+		expected string
+	}{
+		{"null", nil, datatype.Int, "null"},
+		{"varchar", "it's", datatype.Varchar, "'it''s'"},
+		{"ascii empty", "", datatype.Ascii, "''"},
+		{"int", int32(-42), datatype.Int, "-42"},
+		{"bigint", int64(math.MaxInt64), datatype.Bigint, "9223372036854775807"},
+		{"smallint", int16(7), datatype.Smallint, "7"},
+		{"tinyint", int8(-1), datatype.Tinyint, "-1"},
+		{"varint", big.NewInt(-123456789), datatype.Varint, "-123456789"},
+		{"boolean", true, datatype.Boolean, "true"},
+		{"float", float32(1.5), datatype.Float, "1.5"},
+		{"double", 0.1, datatype.Double, "0.1"},
+		{"double NaN", math.NaN(), datatype.Double, "NaN"},
+		{"double -Infinity", math.Inf(-1), datatype.Double, "-Infinity"},
+		{"decimal", CqlDecimal{Unscaled: big.NewInt(-12345), Scale: 2}, datatype.Decimal, "-123.45"},
+		{"decimal small", CqlDecimal{Unscaled: big.NewInt(5), Scale: 3}, datatype.Decimal, "0.005"},
+		{"decimal negative scale", CqlDecimal{Unscaled: big.NewInt(5), Scale: -2}, datatype.Decimal, "500"},
+		{"blob", []byte{0xca, 0xfe}, datatype.Blob, "0xcafe"},
+		{"blob empty", []byte{}, datatype.Blob, "0x"},
+		{"uuid", uuid, datatype.Uuid, "fe2b4360-28c6-11e2-81c1-0800200c9a66"},
+		{"inet", net.ParseIP("192.168.1.1"), datatype.Inet, "'192.168.1.1'"},
+		{"timestamp", time.Date(2021, 10, 12, 13, 14, 15, 123000000, time.UTC), datatype.Timestamp, "'2021-10-12T13:14:15.123Z'"},
+		{"timestamp out of range", int64(math.MaxInt64), datatype.Timestamp, "9223372036854775807"},
+		{"date", time.Date(2021, 10, 12, 0, 0, 0, 0, time.UTC), datatype.Date, "'2021-10-12'"},
+		{"time", 13*time.Hour + 14*time.Minute + 15*time.Second + 1, datatype.Time, "'13:14:15.000000001'"},
+		{"duration", CqlDuration{Months: 14, Days: 2, Nanos: time.Hour + 3*time.Millisecond}, datatype.Duration, "1y2mo2d1h3ms"},
+		{"duration negative", CqlDuration{Days: -1, Nanos: -time.Second}, datatype.Duration, "-1d1s"},
+		{"duration zero", CqlDuration{}, datatype.Duration, "0s"},
+		{"list", []int32{1, 2, 3}, datatype.NewList(datatype.Int), "[1, 2, 3]"},
+		{"list with null", []*int32{nil}, datatype.NewList(datatype.Int), "[null]"},
+		{"set", []string{"a", "b"}, datatype.NewSet(datatype.Varchar), "{'a', 'b'}"},
+		{"map", map[string]int32{"b": 2, "a": 1}, datatype.NewMap(datatype.Varchar, datatype.Int), "{'a': 1, 'b': 2}"},
+		{"tuple", []interface{}{int32(1), "a", nil}, datatype.NewTuple(datatype.Int, datatype.Varchar, datatype.Int), "(1, 'a', null)"},
+		{
+			"udt",
+			map[string]interface{}{"name": "Alice", "Age": int32(42), "tags": []string{"x"}},
+			udtType,
+			`{name: 'Alice', "Age": 42, tags: {'x'}}`,
+		},
+		{
+			"nested",
+			map[string][]int32{"k": {1}},
+			datatype.NewMap(datatype.Varchar, datatype.NewList(datatype.Int)),
+			"{'k': [1]}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := FormatCqlLiteral(tt.value, tt.dt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestFormatEncodedCqlLiteral(t *testing.T) {
+	t.Run("protocol v2 collection", func(t *testing.T) {
+		encoded := []byte{0, 2, 0, 4, 0, 0, 0, 1, 0, 4, 0, 0, 0, 2}
+		actual, err := FormatEncodedCqlLiteral(encoded, datatype.NewList(datatype.Int), primitive.ProtocolVersion2)
+		require.NoError(t, err)
+		assert.Equal(t, "[1, 2]", actual)
+	})
+	t.Run("wrong length", func(t *testing.T) {
+		_, err := FormatEncodedCqlLiteral([]byte{0, 1}, datatype.Int, primitive.ProtocolVersion4)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot format int literal")
+	})
+	t.Run("bytes remaining", func(t *testing.T) {
+		encoded := []byte{0, 0, 0, 4, 0, 0, 0, 1, 0xff}
+		_, err := FormatEncodedCqlLiteral(encoded, datatype.NewTuple(datatype.Int), primitive.ProtocolVersion4)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "remaining: 1")
+	})
+}
+
+func TestFormatCqlIdentifier(t *testing.T) {
+	assert.Equal(t, "f1", FormatCqlIdentifier("f1"))
+	assert.Equal(t, `"F1"`, FormatCqlIdentifier("F1"))
+	assert.Equal(t, `"1f"`, FormatCqlIdentifier("1f"))
+	assert.Equal(t, `"a""b"`, FormatCqlIdentifier(`a"b`))
+}
@@ -36,6 +36,7 @@ var (
 	typeOfBoolean              = reflect.TypeOf(false)
 	typeOfCqlDecimal           = reflect.TypeOf(CqlDecimal{})
 	typeOfCqlDuration          = reflect.TypeOf(CqlDuration{})
+	typeOfCqlDateRange         = reflect.TypeOf(CqlDateRange{})
 	typeOfTime                 = reflect.TypeOf(time.Time{})
 	typeOfDuration             = reflect.TypeOf(time.Duration(0))
 	typeOfNetIP                = reflect.TypeOf((*net.IP)(nil)).Elem()
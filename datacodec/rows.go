@@ -0,0 +1,379 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// NullHandling determines how NULL cells are decoded by a RowDecoder.
+type NullHandling int
+
+const (
+	// NullHandlingZeroValue is the default: NULL cells are decoded into the zero value of the destination, which may
+	// be indistinguishable from actual values. Use NullBitmap to tell NULL cells apart.
+	NullHandlingZeroValue = NullHandling(iota)
+	// NullHandlingPointer requires NULL cells to be decoded into nillable destinations (pointers, slices, maps or
+	// interfaces), which are then set to nil. When converting rows to maps, values of non-nillable preferred Go types
+	// are stored as pointers, e.g. *string instead of string.
+	NullHandlingPointer
+	// NullHandlingWrapper requires NULL cells to be decoded into destinations implementing sql.Scanner, such as
+	// sql.NullString or Nullable, which are then scanned with a nil value. When converting rows to maps, all values
+	// are stored as Nullable.
+	NullHandlingWrapper
+)
+
+func (n NullHandling) String() string {
+	switch n {
+	case NullHandlingZeroValue:
+		return "NullHandling ZERO_VALUE"
+	case NullHandlingPointer:
+		return "NullHandling POINTER"
+	case NullHandlingWrapper:
+		return "NullHandling WRAPPER"
+	}
+	return fmt.Sprintf("NullHandling ? [%d]", int(n))
+}
+
+// Nullable is a sql.Null-style wrapper that can hold a value of any CQL type. It implements sql.Scanner and can
+// therefore be used as a struct field type with ScanRows and ScanRow.
+type Nullable struct {
+	// Value is the decoded value, in the preferred Go type of the column data type; nil if the cell was NULL.
+	Value interface{}
+	// Valid is true if the cell was not NULL.
+	Valid bool
+}
+
+// Scan implements sql.Scanner.
+func (n *Nullable) Scan(src interface{}) error {
+	n.Value, n.Valid = src, src != nil
+	return nil
+}
+
+// NullBitmap returns, for each cell in the given row, whether the cell is NULL.
+func NullBitmap(row message.Row) []bool {
+	nulls := make([]bool, len(row))
+	for i, cell := range row {
+		nulls[i] = cell == nil
+	}
+	return nulls
+}
+
+// RowDecoder decodes rows into Go values. The zero value is ready to use and is the one used by the package-level
+// functions ScanRows, ScanRow, RowsToMaps and RowToMap.
+//
+// Null handling is configured here rather than on codecs, or on the custom codec registry, because codecs are
+// stateless and shared, e.g. Int or Varchar, and decode one cell at a time into whatever destination they are given,
+// reporting NULLs with their wasNull return value; only the row decoder knows the destination types, i.e. the struct
+// fields or map values, and can therefore decide whether NULLs are acceptable for them. Configuring null handling per
+// decoder also allows different parts of an application to use different NullHandlings.
+type RowDecoder struct {
+	// NullHandling determines how NULL cells are decoded.
+	NullHandling NullHandling
+}
+
+// ScanRows decodes all the rows in the given RowsResult into dest, which must be a pointer to a slice of structs, or
+// to a slice of pointers to structs. The slice is resized to contain exactly one element per row.
+//
+// Columns are mapped to struct fields by name: if a field has a "cql" tag, the tag must match the column name exactly;
+// otherwise, the field name must match the column name case-insensitively. Fields tagged with `cql:"-"` and
+// unexported fields are ignored, and so are columns that do not match any field. Each column is decoded with a codec
+// obtained from the column data type, see NewCodec; the field type must therefore be accepted by that codec. Fields
+// implementing sql.Scanner are also accepted: they are scanned with a value of the preferred Go type for the column
+// data type, see PreferredGoType.
+//
+// The RowsResult must contain column metadata: results obtained with the SKIP_METADATA flag cannot be scanned.
+func ScanRows(rows *message.RowsResult, dest interface{}, version primitive.ProtocolVersion) error {
+	return RowDecoder{}.ScanRows(rows, dest, version)
+}
+
+// ScanRow decodes a single row into dest, which must be a pointer to a struct. The given metadata must be the metadata
+// of the RowsResult the row belongs to. Columns are mapped to struct fields as in ScanRows.
+func ScanRow(metadata *message.RowsMetadata, row message.Row, dest interface{}, version primitive.ProtocolVersion) error {
+	return RowDecoder{}.ScanRow(metadata, row, dest, version)
+}
+
+// RowsToMaps decodes all the rows in the given RowsResult into maps keyed by column name. Each column is decoded with a
+// codec obtained from the column data type, see NewCodec, into the preferred Go type for that data type, see
+// PreferredGoType; NULL values are stored as nil. This is mostly useful for debugging and ad-hoc tooling, or when the
+// result must be serialized to JSON.
+//
+// The RowsResult must contain column metadata: results obtained with the SKIP_METADATA flag cannot be converted.
+func RowsToMaps(rows *message.RowsResult, version primitive.ProtocolVersion) ([]map[string]interface{}, error) {
+	return RowDecoder{}.RowsToMaps(rows, version)
+}
+
+// RowToMap decodes a single row into a map keyed by column name. The given metadata must be the metadata of the
+// RowsResult the row belongs to. Columns are decoded as in RowsToMaps.
+func RowToMap(metadata *message.RowsMetadata, row message.Row, version primitive.ProtocolVersion) (map[string]interface{}, error) {
+	return RowDecoder{}.RowToMap(metadata, row, version)
+}
+
+// ScanRows is similar to the package-level ScanRows function, but decodes NULL cells according to this decoder's
+// NullHandling.
+func (d RowDecoder) ScanRows(rows *message.RowsResult, dest interface{}, version primitive.ProtocolVersion) error {
+	if rows == nil || rows.Metadata == nil || rows.Metadata.Columns == nil {
+		return ErrNoColumnMetadata
+	}
+	destValue, err := reflectDest(dest, false)
+	if err != nil {
+		return err
+	} else if destValue.Kind() != reflect.Slice {
+		return errWrongContainerType("slice of structs", destValue.Type())
+	}
+	elemType := destValue.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return errWrongContainerType("slice of structs", destValue.Type())
+	}
+	mapping, err := d.newRowMapping(rows.Metadata.Columns, structType)
+	if err != nil {
+		return err
+	}
+	adjustSliceLength(destValue, len(rows.Data))
+	for i, row := range rows.Data {
+		elem := destValue.Index(i)
+		if elemType.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				elem.Set(reflect.New(structType))
+			}
+			elem = elem.Elem()
+		}
+		if err := mapping.scan(row, elem, version); err != nil {
+			return fmt.Errorf("cannot scan row %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ScanRow is similar to the package-level ScanRow function, but decodes NULL cells according to this decoder's
+// NullHandling.
+func (d RowDecoder) ScanRow(metadata *message.RowsMetadata, row message.Row, dest interface{}, version primitive.ProtocolVersion) error {
+	if metadata == nil || metadata.Columns == nil {
+		return ErrNoColumnMetadata
+	}
+	destValue, err := reflectDest(dest, false)
+	if err != nil {
+		return err
+	} else if destValue.Kind() != reflect.Struct {
+		return errWrongContainerType("struct", destValue.Type())
+	}
+	mapping, err := d.newRowMapping(metadata.Columns, destValue.Type())
+	if err != nil {
+		return err
+	}
+	return mapping.scan(row, destValue, version)
+}
+
+// RowsToMaps is similar to the package-level RowsToMaps function, but decodes NULL cells according to this decoder's
+// NullHandling.
+func (d RowDecoder) RowsToMaps(rows *message.RowsResult, version primitive.ProtocolVersion) ([]map[string]interface{}, error) {
+	if rows == nil || rows.Metadata == nil || rows.Metadata.Columns == nil {
+		return nil, ErrNoColumnMetadata
+	}
+	mapping, err := d.newMapMapping(rows.Metadata.Columns)
+	if err != nil {
+		return nil, err
+	}
+	maps := make([]map[string]interface{}, len(rows.Data))
+	for i, row := range rows.Data {
+		if maps[i], err = mapping.toMap(row, version); err != nil {
+			return nil, fmt.Errorf("cannot convert row %d: %w", i, err)
+		}
+	}
+	return maps, nil
+}
+
+// RowToMap is similar to the package-level RowToMap function, but decodes NULL cells according to this decoder's
+// NullHandling.
+func (d RowDecoder) RowToMap(metadata *message.RowsMetadata, row message.Row, version primitive.ProtocolVersion) (map[string]interface{}, error) {
+	if metadata == nil || metadata.Columns == nil {
+		return nil, ErrNoColumnMetadata
+	}
+	mapping, err := d.newMapMapping(metadata.Columns)
+	if err != nil {
+		return nil, err
+	}
+	return mapping.toMap(row, version)
+}
+
+var typeOfScanner = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// rowMapping maps each column in a row to a struct field index, or -1 if the column has no matching field.
+type rowMapping struct {
+	nullHandling NullHandling
+	columns      []*message.ColumnMetadata
+	fields       []int
+	scanners     []bool
+	codecs       []Codec
+}
+
+func (d RowDecoder) newRowMapping(columns []*message.ColumnMetadata, structType reflect.Type) (*rowMapping, error) {
+	mapping := &rowMapping{
+		nullHandling: d.NullHandling,
+		columns:      columns,
+		fields:       make([]int, len(columns)),
+		scanners:     make([]bool, len(columns)),
+		codecs:       make([]Codec, len(columns)),
+	}
+	for i, column := range columns {
+		mapping.fields[i] = locateFieldByColumnName(structType, column.Name)
+		if mapping.fields[i] >= 0 {
+			fieldType := structType.Field(mapping.fields[i]).Type
+			mapping.scanners[i] = reflect.PtrTo(fieldType).Implements(typeOfScanner)
+			var err error
+			if mapping.codecs[i], err = NewCodec(column.Type); err != nil {
+				return nil, errCannotDecodeColumn(i, column.Name, err)
+			}
+		}
+	}
+	return mapping, nil
+}
+
+func (m *rowMapping) scan(row message.Row, structValue reflect.Value, version primitive.ProtocolVersion) error {
+	if len(row) != len(m.columns) {
+		return fmt.Errorf("expected %d columns, got: %d", len(m.columns), len(row))
+	}
+	for i, column := range m.columns {
+		if m.fields[i] >= 0 {
+			field := structValue.Field(m.fields[i])
+			if err := m.checkNull(row[i], field, m.scanners[i]); err != nil {
+				return errCannotDecodeColumn(i, column.Name, err)
+			} else if m.scanners[i] {
+				var value interface{}
+				if _, err := m.codecs[i].Decode(row[i], &value, version); err != nil {
+					return errCannotDecodeColumn(i, column.Name, err)
+				} else if err := field.Addr().Interface().(sql.Scanner).Scan(value); err != nil {
+					return errCannotDecodeColumn(i, column.Name, err)
+				}
+			} else if field.Kind() == reflect.Ptr {
+				// codecs do not accept pointers to pointers: decode into the pointer target instead
+				if row[i] == nil {
+					field.Set(reflect.Zero(field.Type()))
+				} else {
+					if field.IsNil() {
+						field.Set(reflect.New(field.Type().Elem()))
+					}
+					if _, err := m.codecs[i].Decode(row[i], field.Interface(), version); err != nil {
+						return errCannotDecodeColumn(i, column.Name, err)
+					}
+				}
+			} else if _, err := m.codecs[i].Decode(row[i], field.Addr().Interface(), version); err != nil {
+				return errCannotDecodeColumn(i, column.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *rowMapping) checkNull(cell message.Column, field reflect.Value, scanner bool) error {
+	if cell != nil {
+		return nil
+	}
+	switch m.nullHandling {
+	case NullHandlingPointer:
+		if field.Type() != ensureNillable(field.Type()) {
+			return fmt.Errorf("cannot decode NULL into non-nillable %v with %v", field.Type(), m.nullHandling)
+		}
+	case NullHandlingWrapper:
+		if !scanner {
+			return fmt.Errorf("cannot decode NULL into non-scanner %v with %v", field.Type(), m.nullHandling)
+		}
+	}
+	return nil
+}
+
+// Locates the index of the struct field matching the given column name, or returns -1 if no field can be located. If
+// the struct field has a "cql" tag, then the tag must match the column name exactly; otherwise, the column name must
+// match the struct field name, but case insensitively. Exact tag matches take precedence over field name matches.
+func locateFieldByColumnName(structType reflect.Type, name string) int {
+	match := -1
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if tag, found := field.Tag.Lookup("cql"); found {
+			tag = strings.Split(tag, ",")[0]
+			if tag == name {
+				return i
+			}
+		} else if match == -1 && strings.EqualFold(name, field.Name) {
+			match = i
+		}
+	}
+	return match
+}
+
+// mapMapping holds the codecs and Go types used to convert each column in a row to a map entry.
+type mapMapping struct {
+	nullHandling NullHandling
+	columns      []*message.ColumnMetadata
+	codecs       []Codec
+	goTypes      []reflect.Type
+}
+
+func (d RowDecoder) newMapMapping(columns []*message.ColumnMetadata) (*mapMapping, error) {
+	mapping := &mapMapping{
+		nullHandling: d.NullHandling,
+		columns:      columns,
+		codecs:       make([]Codec, len(columns)),
+		goTypes:      make([]reflect.Type, len(columns)),
+	}
+	for i, column := range columns {
+		var err error
+		if mapping.codecs[i], err = NewCodec(column.Type); err != nil {
+			return nil, errCannotDecodeColumn(i, column.Name, err)
+		} else if mapping.goTypes[i], err = PreferredGoType(column.Type); err != nil {
+			return nil, errCannotDecodeColumn(i, column.Name, err)
+		}
+	}
+	return mapping, nil
+}
+
+func (m *mapMapping) toMap(row message.Row, version primitive.ProtocolVersion) (map[string]interface{}, error) {
+	if len(row) != len(m.columns) {
+		return nil, fmt.Errorf("expected %d columns, got: %d", len(m.columns), len(row))
+	}
+	decoded := make(map[string]interface{}, len(m.columns))
+	for i, column := range m.columns {
+		var value interface{}
+		if _, err := m.codecs[i].Decode(row[i], &value, version); err != nil {
+			return nil, errCannotDecodeColumn(i, column.Name, err)
+		}
+		switch m.nullHandling {
+		case NullHandlingPointer:
+			nillableType := ensureNillable(m.goTypes[i])
+			if value == nil {
+				value = reflect.Zero(nillableType).Interface()
+			} else if nillableType != m.goTypes[i] {
+				value = pointerTo(reflect.ValueOf(value)).Interface()
+			}
+		case NullHandlingWrapper:
+			value = Nullable{Value: value, Valid: value != nil}
+		}
+		decoded[column.Name] = value
+	}
+	return decoded, nil
+}
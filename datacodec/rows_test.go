@@ -0,0 +1,185 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+type scannedRow struct {
+	Id      int32
+	Name    string `cql:"user_name"`
+	Tags    []string
+	Ignored string `cql:"-"`
+	ignored string
+}
+
+func newScanRowsResult() *message.RowsResult {
+	return &message.RowsResult{
+		Metadata: &message.RowsMetadata{
+			ColumnCount: 5,
+			Columns: []*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "id", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "user_name", Index: 1, Type: datatype.Varchar},
+				{Keyspace: "ks1", Table: "table1", Name: "tags", Index: 2, Type: datatype.NewList(datatype.Varchar)},
+				{Keyspace: "ks1", Table: "table1", Name: "ignored", Index: 3, Type: datatype.Varchar},
+				{Keyspace: "ks1", Table: "table1", Name: "unknown", Index: 4, Type: datatype.Varchar},
+			},
+		},
+		Data: message.RowSet{
+			{{0, 0, 0, 1}, []byte("alice"), {0, 0, 0, 1, 0, 0, 0, 1, a}, []byte("x"), []byte("x")},
+			{{0, 0, 0, 2}, nil, nil, nil, nil},
+		},
+	}
+}
+
+func TestScanRows(t *testing.T) {
+	expected := []scannedRow{
+		{Id: 1, Name: "alice", Tags: []string{"a"}},
+		{Id: 2},
+	}
+	t.Run("slice of structs", func(t *testing.T) {
+		var dest []scannedRow
+		err := ScanRows(newScanRowsResult(), &dest, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, expected, dest)
+	})
+	t.Run("slice of pointers", func(t *testing.T) {
+		dest := []*scannedRow{{Id: 42}, {Id: 42}, {Id: 42}}
+		err := ScanRows(newScanRowsResult(), &dest, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, []*scannedRow{&expected[0], &expected[1]}, dest)
+	})
+	t.Run("no metadata", func(t *testing.T) {
+		var dest []scannedRow
+		err := ScanRows(&message.RowsResult{Metadata: &message.RowsMetadata{ColumnCount: 1}}, &dest, primitive.ProtocolVersion4)
+		assert.Equal(t, ErrNoColumnMetadata, err)
+	})
+	t.Run("not a pointer", func(t *testing.T) {
+		var dest []scannedRow
+		err := ScanRows(newScanRowsResult(), dest, primitive.ProtocolVersion4)
+		assert.Equal(t, ErrPointerTypeExpected, err)
+	})
+	t.Run("not a slice of structs", func(t *testing.T) {
+		var dest []int
+		err := ScanRows(newScanRowsResult(), &dest, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "expected slice of structs, got: []int", err)
+	})
+	t.Run("wrong field type", func(t *testing.T) {
+		var dest []struct{ Id bool }
+		err := ScanRows(newScanRowsResult(), &dest, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "cannot scan row 0: cannot decode column 0 (id): cannot decode CQL int as *bool", err)
+	})
+}
+
+func TestScanRow(t *testing.T) {
+	rows := newScanRowsResult()
+	var dest scannedRow
+	err := ScanRow(rows.Metadata, rows.Data[0], &dest, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, scannedRow{Id: 1, Name: "alice", Tags: []string{"a"}}, dest)
+	err = ScanRow(rows.Metadata, rows.Data[0][:2], &dest, primitive.ProtocolVersion4)
+	assertErrorMessage(t, "expected 5 columns, got: 2", err)
+}
+
+func TestRowsToMaps(t *testing.T) {
+	maps, err := RowsToMaps(newScanRowsResult(), primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": int32(1), "user_name": "alice", "tags": []*string{stringPtr("a")}, "ignored": "x", "unknown": "x"},
+		{"id": int32(2), "user_name": nil, "tags": nil, "ignored": nil, "unknown": nil},
+	}, maps)
+	_, err = RowsToMaps(&message.RowsResult{Metadata: &message.RowsMetadata{ColumnCount: 1}}, primitive.ProtocolVersion4)
+	assert.Equal(t, ErrNoColumnMetadata, err)
+}
+
+func TestRowToMap(t *testing.T) {
+	rows := newScanRowsResult()
+	m, err := RowToMap(rows.Metadata, rows.Data[0], primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id": int32(1), "user_name": "alice", "tags": []*string{stringPtr("a")}, "ignored": "x", "unknown": "x",
+	}, m)
+	_, err = RowToMap(rows.Metadata, rows.Data[0][:2], primitive.ProtocolVersion4)
+	assertErrorMessage(t, "expected 5 columns, got: 2", err)
+}
+
+func TestRowDecoder_NullHandling(t *testing.T) {
+	rows := newScanRowsResult()
+	t.Run("pointer", func(t *testing.T) {
+		decoder := RowDecoder{NullHandling: NullHandlingPointer}
+		var dest []struct {
+			Id       int32
+			UserName *string `cql:"user_name"`
+			Tags     []string
+		}
+		err := decoder.ScanRows(rows, &dest, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, stringPtr("alice"), dest[0].UserName)
+		assert.Nil(t, dest[1].UserName)
+		assert.Nil(t, dest[1].Tags)
+		var wrong []struct {
+			UserName string `cql:"user_name"`
+		}
+		err = decoder.ScanRows(rows, &wrong, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "cannot scan row 1: cannot decode column 1 (user_name): "+
+			"cannot decode NULL into non-nillable string with NullHandling POINTER", err)
+		maps, err := decoder.RowsToMaps(rows, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, int32Ptr(1), maps[0]["id"])
+		assert.Equal(t, stringPtr("alice"), maps[0]["user_name"])
+		assert.Equal(t, (*string)(nil), maps[1]["user_name"])
+		assert.Equal(t, ([]*string)(nil), maps[1]["tags"])
+	})
+	t.Run("wrapper", func(t *testing.T) {
+		decoder := RowDecoder{NullHandling: NullHandlingWrapper}
+		var dest []struct {
+			Id       int32
+			UserName sql.NullString `cql:"user_name"`
+			Tags     Nullable
+		}
+		err := decoder.ScanRows(rows, &dest, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, sql.NullString{String: "alice", Valid: true}, dest[0].UserName)
+		assert.Equal(t, Nullable{Value: []*string{stringPtr("a")}, Valid: true}, dest[0].Tags)
+		assert.Equal(t, sql.NullString{}, dest[1].UserName)
+		assert.Equal(t, Nullable{}, dest[1].Tags)
+		var wrong []struct {
+			UserName *string `cql:"user_name"`
+		}
+		err = decoder.ScanRows(rows, &wrong, primitive.ProtocolVersion4)
+		assertErrorMessage(t, "cannot scan row 1: cannot decode column 1 (user_name): "+
+			"cannot decode NULL into non-scanner *string with NullHandling WRAPPER", err)
+		maps, err := decoder.RowsToMaps(rows, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, Nullable{Value: int32(1), Valid: true}, maps[0]["id"])
+		assert.Equal(t, Nullable{}, maps[1]["user_name"])
+	})
+	t.Run("zero value", func(t *testing.T) {
+		var dest []scannedRow
+		err := RowDecoder{}.ScanRows(rows, &dest, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, "", dest[1].Name)
+		assert.Equal(t, []bool{false, true, true, true, true}, NullBitmap(rows.Data[1]))
+	})
+}
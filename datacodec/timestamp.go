@@ -15,6 +15,7 @@
 package datacodec
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/datastax/go-cassandra-native-protocol/datatype"
@@ -81,17 +82,47 @@ var Timestamp = NewTimestamp(TimestampLayoutDefault, time.UTC)
 // used only when encoding from or decoding to string; it is ignored otherwise. The location is only useful if the
 // layout does not include any time zone, in which case the time zone is assumed to be in the given location.
 func NewTimestamp(layout string, location *time.Location) Codec {
+	return NewTimestampWithOptions(TimestampOptions{Layout: layout, Location: location})
+}
+
+// TimestampOptions holds the options of a codec for CQL timestamp values, see NewTimestampWithOptions.
+type TimestampOptions struct {
+
+	// Layout is used only when encoding from or decoding to string; it is ignored otherwise. Defaults to
+	// TimestampLayoutDefault.
+	Layout string
+
+	// Location is the location of decoded time.Time values, and of decoded strings. It is also the assumed location
+	// of strings to encode, if the layout does not include any time zone. Defaults to UTC.
+	Location *time.Location
+
+	// RejectZonedTimes, if true, causes encoding from a time.Time whose location is not UTC to fail. CQL timestamps do
+	// not carry any time zone information: time.Time values are converted to instants, and their location is lost.
+	// Rejecting zoned times helps catch mistakes such as encoding local wall clock times that were meant to be UTC.
+	RejectZonedTimes bool
+}
+
+// NewTimestampWithOptions creates a new codec for CQL timestamp values, with the given options.
+func NewTimestampWithOptions(options TimestampOptions) Codec {
+	if options.Layout == "" {
+		options.Layout = TimestampLayoutDefault
+	}
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
 	return &timestampCodec{
-		layout:     layout,
-		location:   location,
-		innerCodec: &bigintCodec{dataType: datatype.Timestamp},
+		layout:           options.Layout,
+		location:         options.Location,
+		rejectZonedTimes: options.RejectZonedTimes,
+		innerCodec:       &bigintCodec{dataType: datatype.Timestamp},
 	}
 }
 
 type timestampCodec struct {
-	layout     string
-	location   *time.Location
-	innerCodec *bigintCodec
+	layout           string
+	location         *time.Location
+	rejectZonedTimes bool
+	innerCodec       *bigintCodec
 }
 
 func (c *timestampCodec) DataType() datatype.DataType {
@@ -101,8 +132,10 @@ func (c *timestampCodec) DataType() datatype.DataType {
 func (c *timestampCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
 	var val int64
 	var wasNil bool
-	if val, wasNil, err = convertToInt64Timestamp(source, c.layout, c.location); err == nil && !wasNil {
-		dest = writeInt64(val)
+	if err = c.checkZone(source); err == nil {
+		if val, wasNil, err = convertToInt64Timestamp(source, c.layout, c.location); err == nil && !wasNil {
+			dest = writeInt64(val)
+		}
 	}
 	if err != nil {
 		err = errCannotEncode(source, c.DataType(), version, err)
@@ -121,6 +154,30 @@ func (c *timestampCodec) Decode(source []byte, dest interface{}, version primiti
 	return
 }
 
+// checkZone returns an error if zoned times are rejected, and the given source is a time.Time whose location is not
+// UTC.
+func (c *timestampCodec) checkZone(source interface{}) error {
+	if !c.rejectZonedTimes {
+		return nil
+	}
+	var t time.Time
+	switch s := source.(type) {
+	case time.Time:
+		t = s
+	case *time.Time:
+		if s == nil {
+			return nil
+		}
+		t = *s
+	default:
+		return nil
+	}
+	if t.Location() != time.UTC {
+		return fmt.Errorf("zoned times are rejected, expected UTC time, got location: %v", t.Location())
+	}
+	return nil
+}
+
 func convertToInt64Timestamp(source interface{}, layout string, location *time.Location) (val int64, wasNil bool, err error) {
 	switch s := source.(type) {
 	case time.Time:
@@ -110,6 +110,45 @@ func Test_timestampCodec_Encode(t *testing.T) {
 	}
 }
 
+func TestNewTimestampWithOptions(t *testing.T) {
+	codec := NewTimestampWithOptions(TimestampOptions{Location: paris, RejectZonedTimes: true})
+	version := primitive.ProtocolVersion4
+	actual, err := codec.Encode(timestampPosUTC, version)
+	assert.NoError(t, err)
+	assert.Equal(t, timestampPosBytes, actual)
+	actual, err = codec.Encode(&timestampPosUTC, version)
+	assert.NoError(t, err)
+	assert.Equal(t, timestampPosBytes, actual)
+	actual, err = codec.Encode(timeNilPtr(), version)
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+	_, err = codec.Encode(timestampPosZoned, version)
+	assertErrorMessage(t, "zoned times are rejected, expected UTC time, got location: ", err)
+	_, err = codec.Encode(timestampPosUTC.In(paris), version)
+	assertErrorMessage(t, "zoned times are rejected, expected UTC time, got location: Europe/Paris", err)
+	// strings and numbers are not affected
+	actual, err = codec.Encode("2021-10-12T01:00:00.999+02:00", version)
+	assert.NoError(t, err)
+	assert.Equal(t, timestampPosBytes, actual)
+	var decoded time.Time
+	_, err = codec.Decode(timestampPosBytes, &decoded, version)
+	assert.NoError(t, err)
+	assert.Equal(t, paris, decoded.Location())
+	assert.True(t, timestampPosUTC.Equal(decoded))
+	var decodedString string
+	_, err = codec.Decode(timestampPosBytes, &decodedString, version)
+	assert.NoError(t, err)
+	assert.Equal(t, "2021-10-12T01:00:00.999+02:00", decodedString)
+
+	codec = NewTimestampWithOptions(TimestampOptions{})
+	actual, err = codec.Encode(timestampPosZoned, version)
+	assert.NoError(t, err)
+	assert.Equal(t, timestampPosBytes, actual)
+	_, err = codec.Decode(timestampPosBytes, &decoded, version)
+	assert.NoError(t, err)
+	assert.Equal(t, time.UTC, decoded.Location())
+}
+
 func Test_timestampCodec_Decode(t *testing.T) {
 	for _, version := range primitive.SupportedProtocolVersions() {
 		t.Run(version.String(), func(t *testing.T) {
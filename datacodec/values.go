@@ -0,0 +1,229 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// EncodePositionalValues converts the given Go values into protocol [value]s, suitable for use as positional values in
+// QUERY and EXECUTE requests.
+//
+// The parameter dataTypes is optional and contains data type hints, typically obtained from the variables metadata of
+// a prepared statement; if non-nil, it must have the same length as values. When a hint is nil or missing, the data
+// type is inferred from the Go value with InferDataType.
+//
+// A nil value is encoded as a NULL [value]. Values that are already of type *primitive.Value are included as is; this
+// can be used to include unset values, see primitive.NewUnsetValue.
+func EncodePositionalValues(
+	values []interface{},
+	dataTypes []datatype.DataType,
+	version primitive.ProtocolVersion,
+) ([]*primitive.Value, error) {
+	if dataTypes != nil && len(dataTypes) != len(values) {
+		return nil, fmt.Errorf("expected %d data types, got: %d", len(values), len(dataTypes))
+	}
+	encoded := make([]*primitive.Value, len(values))
+	for i, value := range values {
+		var dt datatype.DataType
+		if dataTypes != nil {
+			dt = dataTypes[i]
+		}
+		var err error
+		if encoded[i], err = encodeValue(value, dt, version); err != nil {
+			return nil, fmt.Errorf("cannot encode positional value %d: %w", i, err)
+		}
+	}
+	return encoded, nil
+}
+
+// EncodeNamedValues converts the given Go values into protocol [value]s, suitable for use as named values in QUERY and
+// EXECUTE requests.
+//
+// The parameter variables is optional and typically contains the variables metadata of a prepared statement. If nil,
+// all values are encoded and their data types are inferred with InferDataType. If non-nil, each variable is matched
+// against the given values by name, case-insensitively if no exact match is found, and the value is encoded using the
+// variable's data type; the returned map is then keyed by variable name. In that case, if some variables have no
+// matching value, or if some values do not match any variable, an error is returned listing all missing and unused
+// names; an error is also returned if a variable has no exact match but matches several values case-insensitively.
+//
+// Nil values and values of type *primitive.Value are handled as in EncodePositionalValues.
+func EncodeNamedValues(
+	values map[string]interface{},
+	variables []*message.ColumnMetadata,
+	version primitive.ProtocolVersion,
+) (map[string]*primitive.Value, error) {
+	encoded := make(map[string]*primitive.Value, len(values))
+	if variables == nil {
+		for name, value := range values {
+			var err error
+			if encoded[name], err = encodeValue(value, nil, version); err != nil {
+				return nil, fmt.Errorf("cannot encode named value '%s': %w", name, err)
+			}
+		}
+		return encoded, nil
+	}
+	used := make(map[string]bool, len(values))
+	var missing []string
+	for _, variable := range variables {
+		if name, found, err := matchVariableName(variable.Name, values); err != nil {
+			return nil, err
+		} else if !found {
+			missing = append(missing, variable.Name)
+		} else {
+			used[name] = true
+			var err error
+			if encoded[variable.Name], err = encodeValue(values[name], variable.Type, version); err != nil {
+				return nil, fmt.Errorf("cannot encode named value '%s': %w", name, err)
+			}
+		}
+	}
+	var unused []string
+	for name := range values {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(missing) > 0 || len(unused) > 0 {
+		return nil, errNamedValuesMismatch(missing, unused)
+	}
+	return encoded, nil
+}
+
+// matchVariableName returns the key in values matching the given variable name, exactly or else case-insensitively.
+// An error is returned if there is no exact match and several keys match case-insensitively.
+func matchVariableName(variable string, values map[string]interface{}) (string, bool, error) {
+	if _, found := values[variable]; found {
+		return variable, true, nil
+	}
+	var matches []string
+	for name := range values {
+		if strings.EqualFold(name, variable) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return matches[0], true, nil
+	}
+	sort.Strings(matches)
+	return "", false, fmt.Errorf(
+		"ambiguous named values for variable '%s': %s",
+		variable,
+		strings.Join(matches, ", "),
+	)
+}
+
+func encodeValue(value interface{}, dt datatype.DataType, version primitive.ProtocolVersion) (*primitive.Value, error) {
+	if v, ok := value.(*primitive.Value); ok {
+		return v, nil
+	} else if value == nil {
+		return primitive.NewNullValue(), nil
+	}
+	if dt == nil {
+		var err error
+		if dt, err = InferDataType(value); err != nil {
+			return nil, err
+		}
+	}
+	if codec, err := NewCodec(dt); err != nil {
+		return nil, err
+	} else if contents, err := codec.Encode(value, version); err != nil {
+		return nil, err
+	} else {
+		return primitive.NewValue(contents), nil
+	}
+}
+
+// InferDataType returns the CQL data type that best matches the given Go value. This is roughly the reverse of
+// PreferredGoType: e.g. for a string it returns varchar, and for a time.Time it returns timestamp. Pointers are
+// de-referenced; slices, arrays and maps are inferred as lists and maps of their element types. Tuples and
+// user-defined types cannot be inferred.
+func InferDataType(value interface{}) (datatype.DataType, error) {
+	if value == nil {
+		return nil, fmt.Errorf("cannot infer data type of nil value: %w", ErrSourceTypeNotSupported)
+	}
+	return inferDataType(reflect.TypeOf(value))
+}
+
+func inferDataType(t reflect.Type) (datatype.DataType, error) {
+	switch t {
+	case typeOfString:
+		return datatype.Varchar, nil
+	case typeOfInt64, typeOfInt:
+		return datatype.Bigint, nil
+	case typeOfInt32:
+		return datatype.Int, nil
+	case typeOfInt16:
+		return datatype.Smallint, nil
+	case typeOfInt8:
+		return datatype.Tinyint, nil
+	case typeOfBoolean:
+		return datatype.Boolean, nil
+	case typeOfFloat64:
+		return datatype.Double, nil
+	case typeOfFloat32:
+		return datatype.Float, nil
+	case typeOfByteSlice:
+		return datatype.Blob, nil
+	case typeOfTime:
+		return datatype.Timestamp, nil
+	case typeOfDuration:
+		return datatype.Time, nil
+	case typeOfNetIP:
+		return datatype.Inet, nil
+	case typeOfUUID:
+		return datatype.Uuid, nil
+	case typeOfBigIntPointer:
+		return datatype.Varint, nil
+	case typeOfCqlDecimal:
+		return datatype.Decimal, nil
+	case typeOfCqlDuration:
+		return datatype.Duration, nil
+	case typeOfCqlDateRange:
+		return DateRange.DataType(), nil
+	}
+	if backend := decimalBackendFor(t); backend != nil && backend.Type() == t {
+		return datatype.Decimal, nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return inferDataType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if elementType, err := inferDataType(t.Elem()); err != nil {
+			return nil, err
+		} else {
+			return datatype.NewList(elementType), nil
+		}
+	case reflect.Map:
+		if keyType, err := inferDataType(t.Key()); err != nil {
+			return nil, err
+		} else if valueType, err := inferDataType(t.Elem()); err != nil {
+			return nil, err
+		} else {
+			return datatype.NewMap(keyType, valueType), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot infer data type of %v: %w", t, ErrSourceTypeNotSupported)
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datacodec
+
+import (
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestInferDataType(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    datatype.DataType
+		wantErr string
+	}{
+		{"string", "abc", datatype.Varchar, ""},
+		{"*string", stringPtr("abc"), datatype.Varchar, ""},
+		{"int", 123, datatype.Bigint, ""},
+		{"int64", int64(123), datatype.Bigint, ""},
+		{"int32", int32(123), datatype.Int, ""},
+		{"int16", int16(123), datatype.Smallint, ""},
+		{"int8", int8(123), datatype.Tinyint, ""},
+		{"bool", true, datatype.Boolean, ""},
+		{"float64", 1.5, datatype.Double, ""},
+		{"float32", float32(1.5), datatype.Float, ""},
+		{"[]byte", []byte{1}, datatype.Blob, ""},
+		{"time.Time", time.Now(), datatype.Timestamp, ""},
+		{"time.Duration", time.Second, datatype.Time, ""},
+		{"net.IP", net.ParseIP("127.0.0.1"), datatype.Inet, ""},
+		{"UUID", primitive.UUID{}, datatype.Uuid, ""},
+		{"*big.Int", big.NewInt(1), datatype.Varint, ""},
+		{"CqlDecimal", CqlDecimal{}, datatype.Decimal, ""},
+		{"CqlDuration", CqlDuration{}, datatype.Duration, ""},
+		{"[]string", []string{"a"}, datatype.NewList(datatype.Varchar), ""},
+		{"[2]int32", [2]int32{1, 2}, datatype.NewList(datatype.Int), ""},
+		{"map[string]int32", map[string]int32{"a": 1}, datatype.NewMap(datatype.Varchar, datatype.Int), ""},
+		{"nil", nil, nil, "cannot infer data type of nil value: source type not supported"},
+		{"struct", struct{}{}, nil, "cannot infer data type of struct {}: source type not supported"},
+		{"[]interface{}", []interface{}{1}, nil, "cannot infer data type of interface {}: source type not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := InferDataType(tt.value)
+			assert.Equal(t, tt.want, got)
+			assertErrorMessage(t, tt.wantErr, gotErr)
+		})
+	}
+}
+
+func TestEncodePositionalValues(t *testing.T) {
+	unset := primitive.NewUnsetValue()
+	tests := []struct {
+		name      string
+		values    []interface{}
+		dataTypes []datatype.DataType
+		want      []*primitive.Value
+		wantErr   string
+	}{
+		{"empty", []interface{}{}, nil, []*primitive.Value{}, ""},
+		{
+			"inferred",
+			[]interface{}{"abc", int32(1), nil, unset},
+			nil,
+			[]*primitive.Value{
+				primitive.NewValue([]byte("abc")),
+				primitive.NewValue([]byte{0, 0, 0, 1}),
+				primitive.NewNullValue(),
+				unset,
+			},
+			"",
+		},
+		{
+			"with hints",
+			[]interface{}{1, "abc", int16(1)},
+			[]datatype.DataType{datatype.Int, datatype.Ascii, nil},
+			[]*primitive.Value{
+				primitive.NewValue([]byte{0, 0, 0, 1}),
+				primitive.NewValue([]byte("abc")),
+				primitive.NewValue([]byte{0, 1}),
+			},
+			"",
+		},
+		{
+			"wrong number of hints",
+			[]interface{}{1},
+			[]datatype.DataType{},
+			nil,
+			"expected 1 data types, got: 0",
+		},
+		{
+			"cannot infer",
+			[]interface{}{1, struct{}{}},
+			nil,
+			nil,
+			"cannot encode positional value 1: cannot infer data type of struct {}",
+		},
+		{
+			"cannot encode",
+			[]interface{}{"abc"},
+			[]datatype.DataType{datatype.Int},
+			nil,
+			"cannot encode positional value 0: cannot encode string as CQL int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := EncodePositionalValues(tt.values, tt.dataTypes, primitive.ProtocolVersion4)
+			assert.Equal(t, tt.want, got)
+			assertErrorMessage(t, tt.wantErr, gotErr)
+		})
+	}
+}
+
+func TestEncodeNamedValues(t *testing.T) {
+	variables := []*message.ColumnMetadata{
+		{Keyspace: "ks1", Table: "table1", Name: "k", Index: 0, Type: datatype.Int},
+		{Keyspace: "ks1", Table: "table1", Name: "v", Index: 1, Type: datatype.Ascii},
+	}
+	tests := []struct {
+		name      string
+		values    map[string]interface{}
+		variables []*message.ColumnMetadata
+		want      map[string]*primitive.Value
+		wantErr   string
+	}{
+		{"empty", map[string]interface{}{}, nil, map[string]*primitive.Value{}, ""},
+		{
+			"inferred",
+			map[string]interface{}{"k": int32(1), "v": nil},
+			nil,
+			map[string]*primitive.Value{
+				"k": primitive.NewValue([]byte{0, 0, 0, 1}),
+				"v": primitive.NewNullValue(),
+			},
+			"",
+		},
+		{
+			"with variables",
+			map[string]interface{}{"K": 1, "v": "abc"},
+			variables,
+			map[string]*primitive.Value{
+				"k": primitive.NewValue([]byte{0, 0, 0, 1}),
+				"v": primitive.NewValue([]byte("abc")),
+			},
+			"",
+		},
+		{
+			"exact match preferred",
+			map[string]interface{}{"k": 1, "K": 2, "v": "abc"},
+			[]*message.ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "k", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "K", Index: 1, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "v", Index: 2, Type: datatype.Ascii},
+			},
+			map[string]*primitive.Value{
+				"k": primitive.NewValue([]byte{0, 0, 0, 1}),
+				"K": primitive.NewValue([]byte{0, 0, 0, 2}),
+				"v": primitive.NewValue([]byte("abc")),
+			},
+			"",
+		},
+		{
+			"ambiguous",
+			map[string]interface{}{"Kv": 1, "kV": 2},
+			[]*message.ColumnMetadata{{Keyspace: "ks1", Table: "table1", Name: "kv", Index: 0, Type: datatype.Int}},
+			nil,
+			"ambiguous named values for variable 'kv': Kv, kV",
+		},
+		{
+			"missing and unused",
+			map[string]interface{}{"k": 1, "x": "abc", "y": "abc"},
+			variables,
+			nil,
+			"named values do not match variables: missing values for variables: v; unused values: x, y",
+		},
+		{
+			"cannot infer",
+			map[string]interface{}{"k": struct{}{}},
+			nil,
+			nil,
+			"cannot encode named value 'k': cannot infer data type of struct {}",
+		},
+		{
+			"cannot encode",
+			map[string]interface{}{"k": "abc", "v": "abc"},
+			variables,
+			nil,
+			"cannot encode named value 'k': cannot encode string as CQL int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := EncodeNamedValues(tt.values, tt.variables, primitive.ProtocolVersion4)
+			assert.Equal(t, tt.want, got)
+			assertErrorMessage(t, tt.wantErr, gotErr)
+		})
+	}
+}
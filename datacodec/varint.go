@@ -35,7 +35,7 @@ func (c *varintCodec) DataType() datatype.DataType {
 func (c *varintCodec) Encode(source interface{}, version primitive.ProtocolVersion) (dest []byte, err error) {
 	var val *big.Int
 	if val, err = convertToBigInt(source); err == nil && val != nil {
-		dest = val.Bytes()
+		dest = primitive.EncodeTwosComplement(val)
 	}
 	if err != nil {
 		err = errCannotEncode(source, c.DataType(), version, err)
@@ -44,7 +44,7 @@ func (c *varintCodec) Encode(source interface{}, version primitive.ProtocolVersi
 }
 
 func (c *varintCodec) Decode(source []byte, dest interface{}, version primitive.ProtocolVersion) (wasNull bool, err error) {
-	val := readBigInt(source)
+	val := primitive.DecodeTwosComplement(source)
 	wasNull = val == nil
 	if err = convertFromBigInt(val, wasNull, dest); err != nil {
 		err = errCannotDecode(dest, c.DataType(), version, err)
@@ -252,46 +252,3 @@ var (
 	zeroBigInt = big.NewInt(0)
 	oneBigInt  = big.NewInt(1)
 )
-
-// Implementation note: the encoding scheme used for CQL varint is dictated by Java's implementation of
-// BigInteger.toByteArray(). This scheme has nothing to do with the "Varint" functions declared in Go's binary package.
-// Relevant readings for varint encoding in Go:
-// https://groups.google.com/g/golang-nuts/c/TV4bRVrHZUw
-// https://github.com/gocql/gocql/blob/go1.2/marshal.go#L729-L767
-
-func writeBigInt(n *big.Int) []byte {
-	if n == nil {
-		return nil
-	}
-	switch n.Sign() {
-	case 1:
-		b := n.Bytes()
-		if b[0]&0x80 > 0 {
-			b = append([]byte{0}, b...)
-		}
-		return b
-	case -1:
-		length := uint(n.BitLen()/8+1) * 8
-		b := new(big.Int).Add(n, new(big.Int).Lsh(oneBigInt, length)).Bytes()
-		// When the most significant bit is on a byte
-		// boundary, we can get some extra significant
-		// bits, so strip them off when that happens.
-		if len(b) >= 2 && b[0] == 0xff && b[1]&0x80 != 0 {
-			b = b[1:]
-		}
-		return b
-	default:
-		return []byte{0}
-	}
-}
-
-func readBigInt(source []byte) (val *big.Int) {
-	length := len(source)
-	if length > 0 {
-		val = new(big.Int).SetBytes(source)
-		if source[0]&0x80 > 0 {
-			val.Sub(val, new(big.Int).Lsh(oneBigInt, uint(length)*8))
-		}
-	}
-	return
-}
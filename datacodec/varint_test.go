@@ -47,6 +47,8 @@ func Test_varintCodec_Encode(t *testing.T) {
 				{"nil", nil, nil, ""},
 				{"nil pointer", bigIntNilPtr(), nil, ""},
 				{"non nil", oneBigInt, []byte{1}, ""},
+				{"negative", big.NewInt(-1), []byte{0xff}, ""},
+				{"positive with sign byte", big.NewInt(128), []byte{0, 0x80}, ""},
 				{"conversion failed", float64(0), nil, fmt.Sprintf("cannot encode float64 as CQL varint with %v: cannot convert from float64 to *big.Int: conversion not supported", version)},
 			}
 			for _, tt := range tests {
@@ -225,59 +227,3 @@ func Test_convertFromBigInt(t *testing.T) {
 		})
 	}
 }
-
-func Test_writeBigInt(t *testing.T) {
-	hugeNeg, ok := new(big.Int).SetString("-1042342234234123423435647768234", 10)
-	assert.True(t, ok)
-	tests := []struct {
-		name     string
-		val      *big.Int
-		expected []byte
-	}{
-		{"nil", nil, nil},
-		{"zero", zeroBigInt, []byte{0}},
-		{"1", oneBigInt, []byte{1}},
-		{"-1", big.NewInt(-1), []byte{0xff}},
-		{"100", big.NewInt(100), []byte{0x64}},
-		{"-100", big.NewInt(-100), []byte{0x9c}},
-		{"128", big.NewInt(128), []byte{0x00, 0x80}},
-		{"255", big.NewInt(255), []byte{0x00, 0xff}},
-		{"MinInt32", big.NewInt(math.MinInt32), []byte{0x80, 0x00, 0x00, 0x00}},
-		{"MaxInt32", big.NewInt(math.MaxInt32), []byte{0x7f, 0xff, 0xff, 0xff}},
-		{"huge neg", hugeNeg, []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}},
-		{"huge pos", new(big.Int).SetUint64(math.MaxUint64), []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			actual := writeBigInt(tt.val)
-			assert.Equal(t, tt.expected, actual)
-		})
-	}
-}
-
-func Test_readBigInt(t *testing.T) {
-	hugeNeg, ok := new(big.Int).SetString("-1042342234234123423435647768234", 10)
-	assert.True(t, ok)
-	tests := []struct {
-		name     string
-		source   []byte
-		expected *big.Int
-	}{
-		{"nil", nil, nil},
-		{"empty", []byte{}, nil},
-		{"zero", []byte{0}, zeroBigInt},
-		{"-1", []byte{0xff}, big.NewInt(-1)},
-		{"100", []byte{0x64}, big.NewInt(100)},
-		{"-100", []byte{0x9c}, big.NewInt(-100)},
-		{"128", []byte{0x00, 0x80}, big.NewInt(128)},
-		{"255", []byte{0x00, 0xff}, big.NewInt(255)},
-		{"huge neg", []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}, hugeNeg},
-		{"huge pos", []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, new(big.Int).SetUint64(math.MaxUint64)},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			actual := readBigInt(tt.source)
-			assert.Zero(t, tt.expected.Cmp(actual))
-		})
-	}
-}
@@ -76,9 +76,17 @@ func LengthOfDataType(t DataType, version primitive.ProtocolVersion) (length int
 	return length + dataTypeLength, nil
 }
 
+// ReadDataType reads a data type from the given source. An error is returned if the data type nesting depth exceeds
+// the maximum nesting depth, see SetMaxNestingDepth.
 func ReadDataType(source io.Reader, version primitive.ProtocolVersion) (decoded DataType, err error) {
+	return readDataType(source, version, 1)
+}
+
+func readDataType(source io.Reader, version primitive.ProtocolVersion, depth int) (decoded DataType, err error) {
 	var typeCode uint16
-	if typeCode, err = primitive.ReadShort(source); err != nil {
+	if err = checkReadNestingDepth(depth); err != nil {
+		return nil, err
+	} else if typeCode, err = primitive.ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read data type code: %w", err)
 	} else if err := primitive.CheckValidDataTypeCode(primitive.DataTypeCode(typeCode), version); err != nil {
 		return nil, err
@@ -127,15 +135,15 @@ func ReadDataType(source io.Reader, version primitive.ProtocolVersion) (decoded
 		case primitive.DataTypeCodeCustom:
 			return readCustomType(source, version)
 		case primitive.DataTypeCodeList:
-			return readListType(source, version)
+			return readListType(source, version, depth)
 		case primitive.DataTypeCodeMap:
-			return readMapType(source, version)
+			return readMapType(source, version, depth)
 		case primitive.DataTypeCodeSet:
-			return readSetType(source, version)
+			return readSetType(source, version, depth)
 		case primitive.DataTypeCodeUdt:
-			return readUserDefinedType(source, version)
+			return readUserDefinedType(source, version, depth)
 		case primitive.DataTypeCodeTuple:
-			return readTupleType(source, version)
+			return readTupleType(source, version, depth)
 		}
 		return nil, fmt.Errorf("unknown type code: %w", err)
 	}
@@ -0,0 +1,88 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMaxNestingDepth is the default maximum nesting depth of data types, see SetMaxNestingDepth. It is far above
+// the nesting depth of any realistic schema.
+const DefaultMaxNestingDepth = 64
+
+var maxNestingDepth = int32(DefaultMaxNestingDepth)
+
+// MaxNestingDepth returns the maximum nesting depth of data types currently in effect, see SetMaxNestingDepth.
+func MaxNestingDepth() int {
+	return int(atomic.LoadInt32(&maxNestingDepth))
+}
+
+// SetMaxNestingDepth sets the maximum nesting depth of lists, sets, maps, tuples and user-defined types allowed when
+// reading data types with ReadDataType, e.g. in result metadata; a type with no type parameters, such as int, has a
+// nesting depth of 1, and list<int> has a nesting depth of 2. This protects applications from stack exhaustion caused
+// by maliciously deep type definitions. Since values are decoded according to their data type, this limit also
+// applies to nested values, see datacodec.NewCodec. A depth lesser than or equal to zero disables the limit. The
+// default is DefaultMaxNestingDepth. This function is safe for concurrent use, but is meant to be called once, before
+// any decoding takes place.
+func SetMaxNestingDepth(depth int) {
+	atomic.StoreInt32(&maxNestingDepth, int32(depth))
+}
+
+// NestingDepth returns the nesting depth of the given data type, e.g. 1 for int, and 3 for list<frozen<set<int>>>.
+func NestingDepth(t DataType) int {
+	depth := 0
+	switch t := t.(type) {
+	case *List:
+		depth = NestingDepth(t.ElementType)
+	case *Set:
+		depth = NestingDepth(t.ElementType)
+	case *Map:
+		if depth = NestingDepth(t.KeyType); depth < NestingDepth(t.ValueType) {
+			depth = NestingDepth(t.ValueType)
+		}
+	case *Tuple:
+		for _, fieldType := range t.FieldTypes {
+			if fieldDepth := NestingDepth(fieldType); fieldDepth > depth {
+				depth = fieldDepth
+			}
+		}
+	case *UserDefined:
+		for _, fieldType := range t.FieldTypes {
+			if fieldDepth := NestingDepth(fieldType); fieldDepth > depth {
+				depth = fieldDepth
+			}
+		}
+	}
+	return depth + 1
+}
+
+// CheckNestingDepth returns an error if the nesting depth of the given data type exceeds the maximum nesting depth,
+// see SetMaxNestingDepth.
+func CheckNestingDepth(t DataType) error {
+	if max := MaxNestingDepth(); max > 0 {
+		if depth := NestingDepth(t); depth > max {
+			return fmt.Errorf("data type nesting depth %d exceeds maximum allowed %d", depth, max)
+		}
+	}
+	return nil
+}
+
+func checkReadNestingDepth(depth int) error {
+	if max := MaxNestingDepth(); max > 0 && depth > max {
+		return fmt.Errorf("data type nesting depth exceeds maximum allowed %d", max)
+	}
+	return nil
+}
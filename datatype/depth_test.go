@@ -0,0 +1,63 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datatype
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNestingDepth(t *testing.T) {
+	udt, _ := NewUserDefined("ks", "udt", []string{"f1", "f2"}, []DataType{Int, NewList(NewSet(Int))})
+	assert.Equal(t, 1, NestingDepth(Int))
+	assert.Equal(t, 2, NestingDepth(NewList(Int)))
+	assert.Equal(t, 3, NestingDepth(NewMap(Int, NewList(Int))))
+	assert.Equal(t, 3, NestingDepth(NewMap(NewList(Int), Int)))
+	assert.Equal(t, 3, NestingDepth(NewTuple(Int, NewSet(Varchar))))
+	assert.Equal(t, 4, NestingDepth(udt))
+}
+
+func TestReadDataType_MaxNestingDepth(t *testing.T) {
+	defer SetMaxNestingDepth(DefaultMaxNestingDepth)
+	var dt DataType = Int
+	for i := 0; i < 9; i++ {
+		dt = NewList(dt)
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, WriteDataType(dt, encoded, primitive.ProtocolVersion4))
+
+	SetMaxNestingDepth(10)
+	assert.Equal(t, 10, MaxNestingDepth())
+	decoded, err := ReadDataType(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4)
+	assert.NoError(t, err)
+	assert.Equal(t, dt, decoded)
+	assert.NoError(t, CheckNestingDepth(dt))
+
+	SetMaxNestingDepth(9)
+	_, err = ReadDataType(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data type nesting depth exceeds maximum allowed 9")
+	assert.EqualError(t, CheckNestingDepth(dt), "data type nesting depth 10 exceeds maximum allowed 9")
+
+	SetMaxNestingDepth(0)
+	_, err = ReadDataType(bytes.NewReader(encoded.Bytes()), primitive.ProtocolVersion4)
+	assert.NoError(t, err)
+	assert.NoError(t, CheckNestingDepth(dt))
+}
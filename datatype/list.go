@@ -64,9 +64,9 @@ func lengthOfListType(t DataType, version primitive.ProtocolVersion) (length int
 	return length, nil
 }
 
-func readListType(source io.Reader, version primitive.ProtocolVersion) (decoded DataType, err error) {
+func readListType(source io.Reader, version primitive.ProtocolVersion, depth int) (decoded DataType, err error) {
 	listType := &List{}
-	if listType.ElementType, err = ReadDataType(source, version); err != nil {
+	if listType.ElementType, err = readDataType(source, version, depth+1); err != nil {
 		return nil, fmt.Errorf("cannot read list element type: %w", err)
 	}
 	return listType, nil
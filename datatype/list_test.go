@@ -145,7 +145,7 @@ func TestReadListType(t *testing.T) {
 					var source = bytes.NewBuffer(test.input)
 					var actual DataType
 					var err error
-					actual, err = readListType(source, version)
+					actual, err = readListType(source, version, 1)
 					assert.Equal(t, test.expected, actual)
 					assert.Equal(t, test.err, err)
 				})
@@ -75,11 +75,11 @@ func lengthOfMapType(t DataType, version primitive.ProtocolVersion) (length int,
 	return length, nil
 }
 
-func readMapType(source io.Reader, version primitive.ProtocolVersion) (decoded DataType, err error) {
+func readMapType(source io.Reader, version primitive.ProtocolVersion, depth int) (decoded DataType, err error) {
 	mapType := &Map{}
-	if mapType.KeyType, err = ReadDataType(source, version); err != nil {
+	if mapType.KeyType, err = readDataType(source, version, depth+1); err != nil {
 		return nil, fmt.Errorf("cannot read map key type: %w", err)
-	} else if mapType.ValueType, err = ReadDataType(source, version); err != nil {
+	} else if mapType.ValueType, err = readDataType(source, version, depth+1); err != nil {
 		return nil, fmt.Errorf("cannot read map value type: %w", err)
 	}
 	return mapType, nil
@@ -175,7 +175,7 @@ func TestReadMapType(t *testing.T) {
 					var source = bytes.NewBuffer(test.input)
 					var actual DataType
 					var err error
-					actual, err = readMapType(source, version)
+					actual, err = readMapType(source, version, 1)
 					assert.Equal(t, test.expected, actual)
 					assert.Equal(t, test.err, err)
 				})
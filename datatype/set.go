@@ -64,9 +64,9 @@ func lengthOfSetType(t DataType, version primitive.ProtocolVersion) (length int,
 	return length, nil
 }
 
-func readSetType(source io.Reader, version primitive.ProtocolVersion) (decoded DataType, err error) {
+func readSetType(source io.Reader, version primitive.ProtocolVersion, depth int) (decoded DataType, err error) {
 	setType := &Set{}
-	if setType.ElementType, err = ReadDataType(source, version); err != nil {
+	if setType.ElementType, err = readDataType(source, version, depth+1); err != nil {
 		return nil, fmt.Errorf("cannot read set element type: %w", err)
 	}
 	return setType, nil
@@ -145,7 +145,7 @@ func TestReadSetType(t *testing.T) {
 					var source = bytes.NewBuffer(test.input)
 					var actual DataType
 					var err error
-					actual, err = readSetType(source, version)
+					actual, err = readSetType(source, version, 1)
 					assert.Equal(t, test.expected, actual)
 					assert.Equal(t, test.err, err)
 				})
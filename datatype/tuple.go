@@ -85,14 +85,14 @@ func lengthOfTupleType(t DataType, version primitive.ProtocolVersion) (int, erro
 	}
 }
 
-func readTupleType(source io.Reader, version primitive.ProtocolVersion) (DataType, error) {
+func readTupleType(source io.Reader, version primitive.ProtocolVersion, depth int) (DataType, error) {
 	if fieldCount, err := primitive.ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read tuple field count: %w", err)
 	} else {
 		tupleType := &Tuple{}
 		tupleType.FieldTypes = make([]DataType, fieldCount)
 		for i := 0; i < int(fieldCount); i++ {
-			if tupleType.FieldTypes[i], err = ReadDataType(source, version); err != nil {
+			if tupleType.FieldTypes[i], err = readDataType(source, version, depth+1); err != nil {
 				return nil, fmt.Errorf("cannot read tuple field %d: %w", i, err)
 			}
 		}
@@ -116,7 +116,7 @@ func lengthOfUserDefinedType(t DataType, version primitive.ProtocolVersion) (len
 	return length, nil
 }
 
-func readUserDefinedType(source io.Reader, version primitive.ProtocolVersion) (decoded DataType, err error) {
+func readUserDefinedType(source io.Reader, version primitive.ProtocolVersion, depth int) (decoded DataType, err error) {
 	userDefinedType := &UserDefined{}
 	if userDefinedType.Keyspace, err = primitive.ReadString(source); err != nil {
 		return nil, fmt.Errorf("cannot read udt keyspace: %w", err)
@@ -130,7 +130,7 @@ func readUserDefinedType(source io.Reader, version primitive.ProtocolVersion) (d
 		for i := 0; i < int(fieldCount); i++ {
 			if userDefinedType.FieldNames[i], err = primitive.ReadString(source); err != nil {
 				return nil, fmt.Errorf("cannot read udt field %d name: %w", i, err)
-			} else if userDefinedType.FieldTypes[i], err = ReadDataType(source, version); err != nil {
+			} else if userDefinedType.FieldTypes[i], err = readDataType(source, version, depth+1); err != nil {
 				return nil, fmt.Errorf("cannot read udt field %d: %w", i, err)
 			}
 		}
@@ -0,0 +1,138 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// maxPooledArenaCapacity is the maximum capacity of the buffers retained by released arenas; larger buffers are left
+// to the garbage collector, so that a few very large frames do not pin memory forever.
+const maxPooledArenaCapacity = 1024 * 1024
+
+var arenaPool = sync.Pool{New: func() interface{} { return &Arena{} }}
+
+// Arena holds the memory of a frame decoded with RawCodec.DecodeFrameInArena. The frame body is read into a buffer
+// owned by the arena, and the contents of all the [bytes], [short bytes] and [value] elements of the decoded frame,
+// e.g. query parameters, prepared statement ids and row cells, are slices of that buffer rather than separate
+// allocations. Once the frame is not needed anymore, the arena must be released with Release, which makes its buffer
+// available for decoding other frames. This significantly reduces garbage collection work in applications that decode
+// many short-lived frames, such as proxies.
+//
+// After Release is called, the byte slices of the frame decoded in the arena must not be accessed anymore, since they
+// may be overwritten at any time; use Frame.DeepCopy before releasing the arena to retain the frame. Note that the
+// other parts of the decoded frame, such as the message itself and its strings, are still regular allocations.
+//
+// Arena instances should be obtained with AcquireArena. An arena can hold only one frame at a time, and is not safe
+// for concurrent use.
+type Arena struct {
+	body         []byte
+	decompressed []byte
+}
+
+// AcquireArena returns an empty Arena, reusing a released one if possible.
+func AcquireArena() *Arena {
+	return arenaPool.Get().(*Arena)
+}
+
+// Release releases the memory held by this arena in one call, invalidating the byte slices of the frame decoded in
+// it. The arena must not be used after this method is called.
+func (a *Arena) Release() {
+	if cap(a.body) > maxPooledArenaCapacity {
+		a.body = nil
+	}
+	if cap(a.decompressed) > maxPooledArenaCapacity {
+		a.decompressed = nil
+	}
+	arenaPool.Put(a)
+}
+
+// readBody reads the given number of bytes from source into the arena body buffer, growing it if needed.
+func (a *Arena) readBody(source io.Reader, length int) ([]byte, error) {
+	if cap(a.body) < length {
+		a.body = make([]byte, length)
+	}
+	a.body = a.body[:length]
+	if _, err := io.ReadFull(source, a.body); err != nil {
+		return nil, err
+	}
+	return a.body, nil
+}
+
+// arenaReader reads from an arena buffer and implements primitive.Slicer, so that primitive byte contents are decoded
+// without copying.
+type arenaReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *arenaReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func (r *arenaReader) Slice(length int) ([]byte, error) {
+	if length > len(r.data)-r.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	slice := r.data[r.pos : r.pos+length : r.pos+length]
+	r.pos += length
+	return slice, nil
+}
+
+func (c *codec) DecodeFrameInArena(source io.Reader, arena *Arena) (*Frame, error) {
+	header, err := c.DecodeHeader(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode frame header: %w", err)
+	} else if header.BodyLength < 0 {
+		return nil, fmt.Errorf("cannot decode frame body: invalid body length: %d", header.BodyLength)
+	}
+	release, err := c.acquireBody(header)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode frame body: %w", err)
+	}
+	defer release()
+	data, err := arena.readBody(source, int(header.BodyLength))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode frame body: cannot read body: %w", err)
+	}
+	if header.Flags.Contains(primitive.HeaderFlagCompressed) {
+		decompressed := bytes.NewBuffer(arena.decompressed[:0])
+		if err := c.decompressBody(header, bytes.NewReader(data), decompressed); err != nil {
+			return nil, fmt.Errorf("cannot decode frame body: %w", err)
+		}
+		arena.decompressed = decompressed.Bytes()
+		data = arena.decompressed
+	}
+	reader := &arenaReader{data: data}
+	body, err := c.decodeBodyPrefix(header, reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode frame body: %w", err)
+	} else if body.Message, err = c.decodeMessage(header, reader); err != nil {
+		return nil, fmt.Errorf("cannot decode frame body: %w", err)
+	} else if err = c.checkTrailingBytes(header, body, reader); err != nil {
+		return nil, fmt.Errorf("cannot decode frame body: %w", err)
+	}
+	return &Frame{Header: header, Body: body}, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func newArenaTestRows(rowCount int) *Frame {
+	rows := &message.RowsResult{Metadata: &message.RowsMetadata{ColumnCount: 1}}
+	for i := 0; i < rowCount; i++ {
+		rows.Data = append(rows.Data, message.Row{[]byte{0xca, 0xfe, byte(i)}})
+	}
+	f := NewFrame(primitive.ProtocolVersion4, 1, rows)
+	f.SetCustomPayload(map[string][]byte{"key": {0x01, 0x02}})
+	return f
+}
+
+func TestCodecDecodeFrameInArena(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query: "SELECT * FROM ks.table WHERE pk = ?",
+		Options: &message.QueryOptions{
+			PositionalValues: []*primitive.Value{primitive.NewValue([]byte{0xca, 0xfe}), primitive.NewNullValue()},
+			PagingState:      []byte{0xff},
+		},
+	})
+	tests := []struct {
+		name     string
+		frame    *Frame
+		compress bool
+	}{
+		{"query", query, false},
+		{"query compressed", query, true},
+		{"rows", newArenaTestRows(10), false},
+		{"rows compressed", newArenaTestRows(10), true},
+	}
+	codec := NewRawCodecWithCompression(lz4.Compressor{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.frame.DeepCopy()
+			f.SetCompress(tt.compress)
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(f, encoded))
+			expected, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			arena := AcquireArena()
+			defer arena.Release()
+			actual, err := codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), arena)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestCodecDecodeFrameInArena_Aliasing(t *testing.T) {
+	codec := NewRawCodec()
+	encode := func(f *Frame) []byte {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(f, encoded))
+		return encoded.Bytes()
+	}
+	first := newArenaTestRows(1)
+	second := newArenaTestRows(1)
+	second.Body.Message.(*message.RowsResult).Data[0][0] = []byte{0xba, 0xbe, 0x00}
+
+	arena := &Arena{}
+	decoded, err := codec.DecodeFrameInArena(bytes.NewReader(encode(first)), arena)
+	require.NoError(t, err)
+	retained := decoded.DeepCopy()
+	cell := decoded.Body.Message.(*message.RowsResult).Data[0][0]
+	assert.Equal(t, []byte{0xca, 0xfe, 0x00}, cell)
+	assert.Equal(t, len(cell), cap(cell))
+
+	// decoding another frame in the same arena reuses its memory
+	_, err = codec.DecodeFrameInArena(bytes.NewReader(encode(second)), arena)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xba, 0xbe, 0x00}, cell)
+	assert.Equal(t, []byte{0xca, 0xfe, 0x00}, retained.Body.Message.(*message.RowsResult).Data[0][0])
+}
+
+func TestCodecDecodeFrameInArena_Allocations(t *testing.T) {
+	codec := NewRawCodec()
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(newArenaTestRows(100), encoded))
+	regular := testing.AllocsPerRun(10, func() {
+		_, _ = codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	})
+	arena := &Arena{}
+	inArena := testing.AllocsPerRun(10, func() {
+		_, _ = codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), arena)
+	})
+	assert.Less(t, inArena, regular-90)
+}
+
+func TestCodecDecodeFrameInArena_Errors(t *testing.T) {
+	codec := NewRawCodec()
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(newArenaTestRows(1), encoded))
+	truncated := encoded.Bytes()[:encoded.Len()-1]
+	_, err := codec.DecodeFrameInArena(bytes.NewReader(truncated), AcquireArena())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode frame body: cannot read body")
+
+	budget, err := NewMemoryBudget(1)
+	require.NoError(t, err)
+	codec.SetMemoryBudget(budget)
+	_, err = codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), AcquireArena())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrMemoryBudgetExceeded)
+}
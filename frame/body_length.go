@@ -0,0 +1,43 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxBodyLength is the default maximum frame body length enforced by codecs, see RawCodec.SetMaxBodyLength:
+// 256 MiB, the maximum frame length allowed by Cassandra.
+const DefaultMaxBodyLength = 256 * 1024 * 1024
+
+// ErrBodyTooLarge is returned, possibly wrapped, when a frame body exceeds the maximum body length of the codec.
+var ErrBodyTooLarge = errors.New("frame body too large")
+
+func (c *codec) checkBodyLength(length int32) error {
+	if c.maxBodyLength > 0 && length > c.maxBodyLength {
+		return fmt.Errorf("%w: %d bytes, maximum is %d", ErrBodyTooLarge, length, c.maxBodyLength)
+	}
+	return nil
+}
+
+// checkDecompressedBodyLength checks the length of a decompressed body against the maximum body length: compressed
+// bodies may expand well beyond the body length declared by their header.
+func (c *codec) checkDecompressedBodyLength(length int) error {
+	if c.maxBodyLength > 0 && length > int(c.maxBodyLength) {
+		return fmt.Errorf("%w: %d bytes once decompressed, maximum is %d", ErrBodyTooLarge, length, c.maxBodyLength)
+	}
+	return nil
+}
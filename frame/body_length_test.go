@@ -0,0 +1,121 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/compression/snappy"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecMaxBodyLength(t *testing.T) {
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewRawCodec().EncodeFrame(request, encoded))
+	bodyLength := int32(encoded.Len() - primitive.FrameHeaderLengthV3AndHigher)
+
+	codec := NewRawCodec()
+	codec.SetMaxBodyLength(bodyLength)
+	_, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, codec.EncodeFrame(request, &bytes.Buffer{}))
+
+	codec.SetMaxBodyLength(bodyLength - 1)
+	_, err = codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	_, err = codec.DecodeRawFrame(bytes.NewReader(encoded.Bytes()))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	arena := AcquireArena()
+	_, err = codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), arena)
+	arena.Release()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	dest := &bytes.Buffer{}
+	err = codec.EncodeFrame(request, dest)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	assert.Equal(t, 0, dest.Len())
+
+	// a corrupt header declaring a huge body is rejected by default, without reading the body
+	corrupt := append([]byte(nil), encoded.Bytes()[:primitive.FrameHeaderLengthV3AndHigher]...)
+	binary.BigEndian.PutUint32(corrupt[5:], uint32(DefaultMaxBodyLength+1))
+	_, err = NewRawCodec().DecodeFrame(bytes.NewReader(corrupt))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+	assert.Contains(t, err.Error(), "268435457 bytes, maximum is 268435456")
+
+	codec.SetMaxBodyLength(0)
+	_, err = codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.NoError(t, err)
+}
+
+// opaqueCompressor hides the DecompressedLengthReader implementation of the compressor it wraps.
+type opaqueCompressor struct {
+	BodyCompressor
+}
+
+func TestCodecMaxBodyLength_Decompressed(t *testing.T) {
+	// a highly compressible body: its compressed length is far below the limit, its decompressed length far above
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: string(make([]byte, 64*1024))})
+	for _, compressor := range []BodyCompressor{lz4.Compressor{}, snappy.Compressor{}, opaqueCompressor{lz4.Compressor{}}} {
+		t.Run(fmt.Sprintf("%T", compressor), func(t *testing.T) {
+			request.SetCompress(true)
+			encoded := &bytes.Buffer{}
+			require.NoError(t, NewRawCodecWithCompression(compressor).EncodeFrame(request, encoded))
+			compressedLength := int32(encoded.Len() - primitive.FrameHeaderLengthV3AndHigher)
+			require.Less(t, compressedLength, int32(4096))
+
+			codec := NewRawCodecWithCompression(compressor)
+			codec.SetMaxBodyLength(4096)
+			_, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrBodyTooLarge))
+			assert.Contains(t, err.Error(), "once decompressed, maximum is 4096")
+
+			codec.SetMaxBodyLength(0)
+			decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, request.Body.Message.(*message.Query).Query, decoded.Body.Message.(*message.Query).Query)
+		})
+	}
+
+	// an LZ4 body declaring a decompressed length lesser than its actual one is rejected
+	encoded := &bytes.Buffer{}
+	require.NoError(t, lz4.Compressor{}.CompressWithLength(bytes.NewReader(make([]byte, 64*1024)), encoded))
+	binary.BigEndian.PutUint32(encoded.Bytes(), 1024)
+	err := lz4.Compressor{}.DecompressWithLength(encoded, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decompress message")
+
+	// an LZ4 body declaring a decompressed length beyond the maximum LZ4 expansion is rejected before allocating
+	encoded = &bytes.Buffer{}
+	require.NoError(t, lz4.Compressor{}.CompressWithLength(bytes.NewReader([]byte("hello")), encoded))
+	binary.BigEndian.PutUint32(encoded.Bytes(), 0xFFFFFFFF)
+	err = lz4.Compressor{}.DecompressWithLength(encoded, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum")
+}
@@ -17,6 +17,7 @@ package frame
 import (
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -24,8 +25,19 @@ import (
 
 type Encoder interface {
 
-	// EncodeFrame encodes the entire frame, compressing the body if needed.
+	// EncodeFrame encodes the entire frame, compressing the body if needed. The frame is written directly to the given
+	// destination, e.g. a connection: an uncompressed body is streamed right after the header, without any
+	// intermediate buffer; a compressed body is first compressed into a pooled buffer, since the header must contain
+	// the compressed body length. Since the frame is written in several small writes, it is recommended to wrap
+	// unbuffered destinations, such as a net.Conn, in a bufio.Writer.
 	EncodeFrame(frame *Frame, dest io.Writer) error
+
+	// EncodedFrameLength returns the total length of the given frame once encoded, that is, the length of its header
+	// plus the length of its body: tracing id, custom payload, query warnings and message. This can be used to
+	// pre-size network buffers, or to enforce frame length limits, before doing any encoding work. The length of a
+	// compressed body cannot be known before the body is actually compressed: an error is returned if the frame has
+	// the compressed flag set, unless its uncompressed body length is below the codec compression threshold.
+	EncodedFrameLength(frame *Frame) (int, error)
 }
 
 type RawEncoder interface {
@@ -45,7 +57,10 @@ type RawEncoder interface {
 
 type Decoder interface {
 
-	// DecodeFrame decodes the entire frame, decompressing the body if needed.
+	// DecodeFrame decodes the entire frame, decompressing the body if needed. The frame is decoded directly from the
+	// given source: its header is read first, then exactly the body length declared in the header, so that the source
+	// is positioned at the beginning of the next frame when this method returns successfully. The source can thus be a
+	// connection, e.g. a net.Conn, without the need to read the frame into memory beforehand.
 	DecodeFrame(source io.Reader) (*Frame, error)
 }
 
@@ -60,9 +75,18 @@ type RawDecoder interface {
 	DecodeHeader(source io.Reader) (*Header, error)
 
 	// DecodeBody decodes a frame Body from the given source, decompressing it if required. This is a partial
-	// operation; It is illegal to call this method before calling DecodeHeader.
+	// operation; It is illegal to call this method before calling DecodeHeader. Unlike DecodeFrame, this method leaves
+	// unread the bytes remaining after the message when they are ignored by the TrailingBytesPolicy.
 	DecodeBody(header *Header, source io.Reader) (*Body, error)
 
+	// DecodeBodyPrefix decodes the parts of a frame body that precede the message (tracing id, custom payload and
+	// query warnings), and returns a Body whose Message field is nil, together with a reader positioned at the
+	// beginning of the encoded message. This is useful to decode very large messages incrementally, see for example
+	// message.NewRowsDecoder. If the body is compressed, it is decompressed in memory first; otherwise, the returned
+	// reader reads directly from the source, and is limited to the remaining body bytes. Callers are responsible for
+	// consuming the returned reader entirely before decoding the next frame from the same source.
+	DecodeBodyPrefix(header *Header, source io.Reader) (body *Body, message io.Reader, err error)
+
 	// DecodeRawBody decodes a frame RawBody from the given source. This is a partial operation; it is illegal to call
 	// this method before calling DecodeHeader.
 	DecodeRawBody(header *Header, source io.Reader) ([]byte, error)
@@ -85,24 +109,173 @@ type RawConverter interface {
 
 // Codec exposes basic encoding and decoding operations for Frame instances. It should be the preferred interface to
 // use in typical client applications such as drivers.
+// Codecs are safe for concurrent use once configured: a single instance can be shared by all the connections of an
+// application, and used to encode and decode frames from several goroutines at once. Codecs hold no mutable state of
+// their own; temporary buffers are taken from pools. However, a given frame must not be encoded by several goroutines
+// at once, since encoding sets its header body length; configured hooks, such as observers and compressors, must be
+// safe for concurrent use as well.
 type Codec interface {
 	Encoder
 	Decoder
 }
 
+// Introspector exposes information about how a codec is configured. It is mostly useful for diagnostics purposes, e.g.
+// to expose the codec configuration through an administration endpoint.
+type Introspector interface {
+
+	// RegisteredOpCodes returns the opcodes for which a message codec is registered, sorted in ascending order. In
+	// CodecModeClient and CodecModeServer, only opcodes that the codec can either encode or decode are returned.
+	RegisteredOpCodes() []primitive.OpCode
+
+	// SupportedProtocolVersions returns the protocol versions in which messages with the given opcode can be encoded
+	// or decoded by this codec, or nil if no message codec is registered for that opcode.
+	SupportedProtocolVersions(opCode primitive.OpCode) []primitive.ProtocolVersion
+
+	// HasBodyCompressor returns true if a BodyCompressor is configured, false otherwise.
+	HasBodyCompressor() bool
+}
+
 // RawCodec exposes advanced encoding and decoding operations for both Frame and RawFrame instances. It should be used
 // only by applications that need to access the frame header without necessarily accessing the frame body, such as
-// proxies or gateways.
+// proxies or gateways. Such applications typically decode frames with DecodeRawFrame, route them based on their
+// header only, e.g. on their opcode or stream id, and decode their body lazily with ConvertFromRawFrame, only when
+// required. Encoded frames already held in memory can be decoded the same way, by wrapping them in a bytes.Reader.
+// Like Codec, RawCodec is safe for concurrent use once configured; its setters however are not, and must be called
+// before the codec is shared.
 type RawCodec interface {
 	Codec
 	RawEncoder
 	RawDecoder
 	RawConverter
+	Introspector
+
+	// SetCustomPayloadLimits sets the limits to enforce on custom payloads, when decoding and when encoding frames. A
+	// decoded custom payload that does not honor the limits results in a decoding error; this protects applications
+	// from peers sending unbounded amounts of data through custom payloads. If limits is nil, which is the default,
+	// custom payloads are not checked. This method should be called before the codec is used.
+	SetCustomPayloadLimits(limits *primitive.BytesMapLimits)
+
+	// SetCompressionObserver sets a CompressionObserver to notify whenever a frame body is compressed or decompressed,
+	// or whenever compression is skipped. If observer is nil, which is the default, no statistics are recorded. This
+	// method should be called before the codec is used.
+	SetCompressionObserver(observer CompressionObserver)
+
+	// SetCompressionThreshold sets the minimum length of an uncompressed frame body for it to be compressed. When
+	// EncodeFrame is invoked with a frame whose compressed flag is set, but whose uncompressed body length is lesser
+	// than the threshold, the compressed flag is removed from the frame header and the frame is encoded uncompressed.
+	// Compressing small bodies is often counterproductive, as the compressed body may end up larger than the
+	// uncompressed one. A threshold lesser than or equal to zero, which is the default, disables this behavior. This
+	// method should be called before the codec is used.
+	SetCompressionThreshold(threshold int)
+
+	// SetMessageObserver sets a MessageObserver to notify whenever a message is encoded or decoded. If observer is
+	// nil, which is the default, no statistics are recorded. This method should be called before the codec is used.
+	SetMessageObserver(observer MessageObserver)
+
+	// DecodeFrameInArena decodes the entire frame, decompressing the body if needed, using the given Arena to hold
+	// the body contents. The byte slices of the decoded frame are only valid until the arena is released; see Arena.
+	DecodeFrameInArena(source io.Reader, arena *Arena) (*Frame, error)
+
+	// SetMemoryBudget sets a MemoryBudget to reserve frame body lengths from before decoding them; a frame body that
+	// would exceed the budget results in a decoding error wrapping ErrMemoryBudgetExceeded. The same budget can be
+	// shared by several codecs. If budget is nil, which is the default, body lengths are not checked. This method
+	// should be called before the codec is used.
+	SetMemoryBudget(budget *MemoryBudget)
+
+	// SetTrailingBytesPolicy sets the TrailingBytesPolicy to apply when unread bytes remain in a frame body after its
+	// message was decoded by DecodeFrame, DecodeBody or DecodeFrameInArena, and an optional handler to notify of such
+	// bytes. The default policy is TrailingBytesPolicyIgnore. This method should be called before the codec is used.
+	SetTrailingBytesPolicy(policy TrailingBytesPolicy, onTrailingBytes TrailingBytesHandler)
+
+	// SetUTF8Validation enables or disables the validation of decoded strings: when enabled, a decoded frame whose
+	// message or query warnings contain a [string] or [long string] that is not valid UTF-8, as required by the
+	// protocol specification, results in a decoding error wrapping a message.InvalidUTF8Error. Validation is disabled
+	// by default. This method should be called before the codec is used.
+	SetUTF8Validation(enabled bool)
+
+	// SetStreamIdValidation enables or disables the validation of stream ids against the protocol specification when
+	// encoding and decoding frame headers: when enabled, a request with a negative stream id, an EVENT response with a
+	// stream id other than -1, or any other response with a negative stream id results in an error wrapping
+	// ErrInvalidStreamId. Stream ids that do not fit the stream id range of the protocol version, e.g. stream ids
+	// beyond the 8-bit range in protocol versions 1 and 2, always result in such an error. Validation is disabled by
+	// default, so that tools such as conformance checkers can exchange invalid frames. This method should be called
+	// before the codec is used.
+	SetStreamIdValidation(enabled bool)
+
+	// SetMaxBodyLength sets the maximum frame body length, in bytes. When decoding, a frame whose header declares a
+	// longer body results in an error wrapping ErrBodyTooLarge, before any memory is allocated for the body; when
+	// encoding, a frame whose body is longer results in the same error, before anything is written. The default is
+	// DefaultMaxBodyLength; a value lesser than or equal to zero disables the check. Compressed bodies are checked
+	// twice: their compressed length is checked before they are read, and their decompressed length before they are
+	// decompressed, if the compressor implements DecompressedLengthReader, or after they are decompressed otherwise.
+	// This method should be called before the codec is used.
+	SetMaxBodyLength(maxBodyLength int32)
+
+	// SetUnknownHeaderFlagsPolicy sets the UnknownHeaderFlagsPolicy to apply when a decoded frame header has flags
+	// that are not defined by the protocol specification, and an optional handler to notify of such headers. The
+	// policy applies to all the decoding methods, including DecodeHeader and DecodeRawFrame. The default policy is
+	// UnknownHeaderFlagsPolicyIgnore. This method should be called before the codec is used.
+	SetUnknownHeaderFlagsPolicy(policy UnknownHeaderFlagsPolicy, onUnknownFlags UnknownHeaderFlagsHandler)
+}
+
+// CodecMode determines which messages a codec is allowed to encode and decode.
+type CodecMode int
+
+const (
+	// CodecModeAny is the default mode: codecs in this mode can encode and decode both requests and responses.
+	CodecModeAny = CodecMode(iota)
+	// CodecModeClient is the mode for codecs used on the client side of a connection: codecs in this mode can only
+	// encode requests and decode responses.
+	CodecModeClient
+	// CodecModeServer is the mode for codecs used on the server side of a connection: codecs in this mode can only
+	// decode requests and encode responses.
+	CodecModeServer
+)
+
+func (m CodecMode) String() string {
+	switch m {
+	case CodecModeAny:
+		return "CodecMode ANY"
+	case CodecModeClient:
+		return "CodecMode CLIENT"
+	case CodecModeServer:
+		return "CodecMode SERVER"
+	}
+	return fmt.Sprintf("CodecMode ? [%d]", int(m))
+}
+
+func (m CodecMode) canEncode(isResponse bool) bool {
+	return m == CodecModeAny || (m == CodecModeClient) != isResponse
+}
+
+func (m CodecMode) canDecode(isResponse bool) bool {
+	return m == CodecModeAny || (m == CodecModeClient) == isResponse
 }
 
 type codec struct {
-	messageCodecs map[primitive.OpCode]message.Codec
-	compressor    BodyCompressor
+	mode       CodecMode
+	encoders   map[primitive.OpCode]message.Encoder
+	decoders   map[primitive.OpCode]message.Decoder
+	compressor BodyCompressor
+	// customPayloadLimits may be nil, in which case custom payloads are not checked.
+	customPayloadLimits *primitive.BytesMapLimits
+	// compressionObserver may be nil, in which case no compression statistics are recorded.
+	compressionObserver  CompressionObserver
+	compressionThreshold int
+	// messageObserver may be nil, in which case no message statistics are recorded.
+	messageObserver MessageObserver
+	// memoryBudget may be nil, in which case body lengths are not checked.
+	memoryBudget *MemoryBudget
+	// onTrailingBytes may be nil, in which case trailing bytes are only handled according to trailingBytesPolicy.
+	trailingBytesPolicy TrailingBytesPolicy
+	onTrailingBytes     TrailingBytesHandler
+	validateUTF8        bool
+	validateStreamIds   bool
+	maxBodyLength       int32
+	// onUnknownFlags may be nil, in which case unknown header flags are only handled according to
+	// unknownFlagsPolicy.
+	unknownFlagsPolicy UnknownHeaderFlagsPolicy
+	onUnknownFlags     UnknownHeaderFlagsHandler
 }
 
 func NewCodec(messageCodecs ...message.Codec) Codec {
@@ -118,19 +291,61 @@ func NewRawCodec(messageCodecs ...message.Codec) RawCodec {
 }
 
 func NewRawCodecWithCompression(compressor BodyCompressor, messageCodecs ...message.Codec) RawCodec {
+	return newCodec(CodecModeAny, compressor, messageCodecs)
+}
+
+// NewClientCodec creates a new RawCodec in CodecModeClient: the returned codec can only encode requests and decode
+// responses; any attempt to encode a response or to decode a request results in an error.
+func NewClientCodec(messageCodecs ...message.Codec) RawCodec {
+	return NewClientCodecWithCompression(nil, messageCodecs...)
+}
+
+// NewClientCodecWithCompression is similar to NewClientCodec, but uses the given BodyCompressor.
+func NewClientCodecWithCompression(compressor BodyCompressor, messageCodecs ...message.Codec) RawCodec {
+	return newCodec(CodecModeClient, compressor, messageCodecs)
+}
+
+// NewServerCodec creates a new RawCodec in CodecModeServer: the returned codec can only decode requests and encode
+// responses; any attempt to encode a request or to decode a response results in an error.
+func NewServerCodec(messageCodecs ...message.Codec) RawCodec {
+	return NewServerCodecWithCompression(nil, messageCodecs...)
+}
+
+// NewServerCodecWithCompression is similar to NewServerCodec, but uses the given BodyCompressor.
+func NewServerCodecWithCompression(compressor BodyCompressor, messageCodecs ...message.Codec) RawCodec {
+	return newCodec(CodecModeServer, compressor, messageCodecs)
+}
+
+func newCodec(mode CodecMode, compressor BodyCompressor, messageCodecs []message.Codec) *codec {
 	frameCodec := &codec{
+		mode:          mode,
 		compressor:    compressor,
-		messageCodecs: make(map[primitive.OpCode]message.Codec, len(message.DefaultMessageCodecs)+len(messageCodecs)),
+		encoders:      make(map[primitive.OpCode]message.Encoder, len(message.DefaultMessageCodecs)+len(messageCodecs)),
+		decoders:      make(map[primitive.OpCode]message.Decoder, len(message.DefaultMessageCodecs)+len(messageCodecs)),
+		maxBodyLength: DefaultMaxBodyLength,
 	}
 	for _, messageCodec := range message.DefaultMessageCodecs {
-		frameCodec.messageCodecs[messageCodec.GetOpCode()] = messageCodec
+		frameCodec.registerMessageCodec(messageCodec)
 	}
 	for _, messageCodec := range messageCodecs {
-		frameCodec.messageCodecs[messageCodec.GetOpCode()] = messageCodec
+		frameCodec.registerMessageCodec(messageCodec)
 	}
 	return frameCodec
 }
 
+func (c *codec) registerMessageCodec(messageCodec message.Codec) {
+	opCode := messageCodec.GetOpCode()
+	// opcodes unknown to this library are considered valid in both directions
+	isRequest := opCode.IsRequest() || !opCode.IsValid()
+	isResponse := opCode.IsResponse() || !opCode.IsValid()
+	if (isRequest && c.mode.canEncode(false)) || (isResponse && c.mode.canEncode(true)) {
+		c.encoders[opCode] = messageCodec
+	}
+	if (isRequest && c.mode.canDecode(false)) || (isResponse && c.mode.canDecode(true)) {
+		c.decoders[opCode] = messageCodec
+	}
+}
+
 func (c *codec) GetBodyCompressor() BodyCompressor {
 	return c.compressor
 }
@@ -139,14 +354,124 @@ func (c *codec) SetBodyCompressor(compressor BodyCompressor) {
 	c.compressor = compressor
 }
 
-func (c *codec) findMessageCodec(opCode primitive.OpCode) (message.Codec, error) {
-	if encoder, found := c.messageCodecs[opCode]; !found {
-		return nil, fmt.Errorf("unsupported opcode %d", opCode)
+func (c *codec) SetCustomPayloadLimits(limits *primitive.BytesMapLimits) {
+	c.customPayloadLimits = limits
+}
+
+func (c *codec) SetCompressionObserver(observer CompressionObserver) {
+	c.compressionObserver = observer
+}
+
+func (c *codec) SetCompressionThreshold(threshold int) {
+	c.compressionThreshold = threshold
+}
+
+func (c *codec) SetMessageObserver(observer MessageObserver) {
+	c.messageObserver = observer
+}
+
+func (c *codec) SetMemoryBudget(budget *MemoryBudget) {
+	c.memoryBudget = budget
+}
+
+func (c *codec) SetTrailingBytesPolicy(policy TrailingBytesPolicy, onTrailingBytes TrailingBytesHandler) {
+	c.trailingBytesPolicy = policy
+	c.onTrailingBytes = onTrailingBytes
+}
+
+func (c *codec) SetUTF8Validation(enabled bool) {
+	c.validateUTF8 = enabled
+}
+
+func (c *codec) SetStreamIdValidation(enabled bool) {
+	c.validateStreamIds = enabled
+}
+
+func (c *codec) SetMaxBodyLength(maxBodyLength int32) {
+	c.maxBodyLength = maxBodyLength
+}
+
+func (c *codec) SetUnknownHeaderFlagsPolicy(policy UnknownHeaderFlagsPolicy, onUnknownFlags UnknownHeaderFlagsHandler) {
+	c.unknownFlagsPolicy = policy
+	c.onUnknownFlags = onUnknownFlags
+}
+
+func (c *codec) RegisteredOpCodes() []primitive.OpCode {
+	opCodes := make([]primitive.OpCode, 0, len(c.encoders)+len(c.decoders))
+	for opCode := range c.encoders {
+		opCodes = append(opCodes, opCode)
+	}
+	for opCode := range c.decoders {
+		if _, found := c.encoders[opCode]; !found {
+			opCodes = append(opCodes, opCode)
+		}
+	}
+	sort.Slice(opCodes, func(i, j int) bool { return opCodes[i] < opCodes[j] })
+	return opCodes
+}
+
+func (c *codec) SupportedProtocolVersions(opCode primitive.OpCode) []primitive.ProtocolVersion {
+	_, canEncode := c.encoders[opCode]
+	_, canDecode := c.decoders[opCode]
+	if !canEncode && !canDecode {
+		return nil
+	}
+	var versions []primitive.ProtocolVersion
+	for _, version := range primitive.SupportedProtocolVersions() {
+		if version.SupportsOpCode(opCode) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+func (c *codec) HasBodyCompressor() bool {
+	return c.compressor != nil
+}
+
+func (c *codec) findMessageEncoder(opCode primitive.OpCode) (message.Encoder, error) {
+	if encoder, found := c.encoders[opCode]; !found {
+		if opCode.IsValid() && !c.mode.canEncode(opCode.IsResponse()) {
+			return nil, fmt.Errorf("cannot encode %v in %v: %v", directionString(opCode.IsResponse()), c.mode, opCode)
+		}
+		return nil, fmt.Errorf("unsupported opcode for encoding in %v: %d", c.mode, opCode)
 	} else {
 		return encoder, nil
 	}
 }
 
+func (c *codec) findMessageDecoder(opCode primitive.OpCode) (message.Decoder, error) {
+	if decoder, found := c.decoders[opCode]; !found {
+		if opCode.IsValid() && !c.mode.canDecode(opCode.IsResponse()) {
+			return nil, fmt.Errorf("cannot decode %v in %v: %v", directionString(opCode.IsResponse()), c.mode, opCode)
+		}
+		return nil, fmt.Errorf("unsupported opcode for decoding in %v: %d", c.mode, opCode)
+	} else {
+		return decoder, nil
+	}
+}
+
+func (c *codec) checkEncodeDirection(header *Header) error {
+	if !c.mode.canEncode(header.IsResponse) {
+		return fmt.Errorf("cannot encode %v in %v", directionString(header.IsResponse), c.mode)
+	}
+	return nil
+}
+
+func (c *codec) checkDecodeDirection(header *Header) error {
+	if !c.mode.canDecode(header.IsResponse) {
+		return fmt.Errorf("cannot decode %v in %v", directionString(header.IsResponse), c.mode)
+	}
+	return nil
+}
+
+func directionString(isResponse bool) string {
+	if isResponse {
+		return "response"
+	}
+	return "request"
+}
+
 type ProtocolVersionErr struct {
 	Err     string
 	Version primitive.ProtocolVersion
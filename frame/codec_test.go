@@ -16,6 +16,12 @@ package frame
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -135,6 +141,217 @@ func TestConvertToRawFrame(t *testing.T) {
 	}
 }
 
+func TestDirectionAwareCodecs(t *testing.T) {
+	anyCodec := NewRawCodec()
+	clientCodec := NewClientCodec()
+	serverCodec := NewServerCodec()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			request, response := createFrames(version)
+			encodedRequest := &bytes.Buffer{}
+			require.NoError(t, anyCodec.EncodeFrame(request, encodedRequest))
+			encodedResponse := &bytes.Buffer{}
+			require.NoError(t, anyCodec.EncodeFrame(response, encodedResponse))
+			t.Run("client", func(t *testing.T) {
+				err := clientCodec.EncodeFrame(request, &bytes.Buffer{})
+				assert.NoError(t, err)
+				err = clientCodec.EncodeFrame(response, &bytes.Buffer{})
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot encode response in CodecMode CLIENT")
+				decoded, err := clientCodec.DecodeFrame(bytes.NewReader(encodedResponse.Bytes()))
+				assert.NoError(t, err)
+				assert.Equal(t, response, decoded)
+				_, err = clientCodec.DecodeFrame(bytes.NewReader(encodedRequest.Bytes()))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot decode request in CodecMode CLIENT")
+			})
+			t.Run("server", func(t *testing.T) {
+				err := serverCodec.EncodeFrame(response, &bytes.Buffer{})
+				assert.NoError(t, err)
+				err = serverCodec.EncodeFrame(request, &bytes.Buffer{})
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot encode request in CodecMode SERVER")
+				decoded, err := serverCodec.DecodeFrame(bytes.NewReader(encodedRequest.Bytes()))
+				assert.NoError(t, err)
+				assert.Equal(t, request, decoded)
+				_, err = serverCodec.DecodeFrame(bytes.NewReader(encodedResponse.Bytes()))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "cannot decode response in CodecMode SERVER")
+			})
+		})
+	}
+}
+
+func TestCodecIntrospection(t *testing.T) {
+	codec := NewRawCodec()
+	assert.False(t, codec.HasBodyCompressor())
+	assert.Len(t, codec.RegisteredOpCodes(), len(message.DefaultMessageCodecs))
+	assert.Equal(t, primitive.SupportedProtocolVersions(), codec.SupportedProtocolVersions(primitive.OpCodeQuery))
+	assert.Equal(t, primitive.SupportedDseProtocolVersions(), codec.SupportedProtocolVersions(primitive.OpCodeDseRevise))
+	assert.Equal(t, []primitive.ProtocolVersion{primitive.ProtocolVersion2}, codec.SupportedProtocolVersions(primitive.OpCodeCredentials))
+	assert.Nil(t, codec.SupportedProtocolVersions(primitive.OpCode(0x42)))
+
+	codec = NewClientCodecWithCompression(lz4.Compressor{})
+	assert.True(t, codec.HasBodyCompressor())
+	assert.Equal(t, []primitive.OpCode{
+		primitive.OpCodeError,
+		primitive.OpCodeStartup,
+		primitive.OpCodeReady,
+		primitive.OpCodeAuthenticate,
+		primitive.OpCodeCredentials,
+		primitive.OpCodeOptions,
+		primitive.OpCodeSupported,
+		primitive.OpCodeQuery,
+		primitive.OpCodeResult,
+		primitive.OpCodePrepare,
+		primitive.OpCodeExecute,
+		primitive.OpCodeRegister,
+		primitive.OpCodeEvent,
+		primitive.OpCodeBatch,
+		primitive.OpCodeAuthChallenge,
+		primitive.OpCodeAuthResponse,
+		primitive.OpCodeAuthSuccess,
+		primitive.OpCodeDseRevise,
+	}, codec.RegisteredOpCodes())
+}
+
+// customMessage is a message with an opcode unknown to this library, carrying a single [string].
+type customMessage struct {
+	Data string
+}
+
+func (m *customMessage) IsResponse() bool                 { return false }
+func (m *customMessage) GetOpCode() primitive.OpCode      { return 0x42 }
+func (m *customMessage) DeepCopyMessage() message.Message { return &customMessage{Data: m.Data} }
+
+type customMessageCodec struct{}
+
+func (c *customMessageCodec) GetOpCode() primitive.OpCode { return 0x42 }
+
+func (c *customMessageCodec) Encode(msg message.Message, dest io.Writer, _ primitive.ProtocolVersion) error {
+	return primitive.WriteString(msg.(*customMessage).Data, dest)
+}
+
+func (c *customMessageCodec) EncodedLength(msg message.Message, _ primitive.ProtocolVersion) (int, error) {
+	return primitive.LengthOfString(msg.(*customMessage).Data), nil
+}
+
+func (c *customMessageCodec) Decode(source io.Reader, _ primitive.ProtocolVersion) (message.Message, error) {
+	data, err := primitive.ReadString(source)
+	if err != nil {
+		return nil, err
+	}
+	return &customMessage{Data: data}, nil
+}
+
+func TestCodecCustomOpCode(t *testing.T) {
+	codec := NewRawCodec(&customMessageCodec{})
+	assert.Equal(t, primitive.SupportedProtocolVersions(), codec.SupportedProtocolVersions(0x42))
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			f := NewFrame(version, 1, &customMessage{Data: "hello"})
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(f, encoded))
+			decoded, err := codec.DecodeFrame(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, f, decoded)
+		})
+	}
+}
+
+func TestCodecCustomPayloadLimits(t *testing.T) {
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	request.SetCustomPayload(map[string][]byte{"key": []byte("this value is too long")})
+	encodedRequest := &bytes.Buffer{}
+	require.NoError(t, NewRawCodec().EncodeFrame(request, encodedRequest))
+
+	codec := NewRawCodec()
+	codec.SetCustomPayloadLimits(&primitive.BytesMapLimits{MaxValueLength: 10})
+	_, err := codec.DecodeFrame(bytes.NewReader(encodedRequest.Bytes()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is too long: 22 bytes (max 10)")
+	err = codec.EncodeFrame(request, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "value is too long: 22 bytes (max 10)")
+
+	codec.SetCustomPayloadLimits(nil)
+	decoded, err := codec.DecodeFrame(bytes.NewReader(encodedRequest.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, request, decoded)
+}
+
+func TestDecodeBodyPrefix(t *testing.T) {
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			for algorithm, codec := range createCodecs() {
+				t.Run(algorithm, func(t *testing.T) {
+					response := NewFrame(version, 1, &message.RowsResult{
+						Metadata: &message.RowsMetadata{ColumnCount: 1},
+						Data:     message.RowSet{{{0xca, 0xfe, 0xba, 0xbe}}},
+					})
+					if version >= primitive.ProtocolVersion4 {
+						response.SetWarnings([]string{"I'm warning you!!"})
+					}
+					if algorithm != "NONE" {
+						response.Header.Flags = response.Header.Flags.Add(primitive.HeaderFlagCompressed)
+					}
+					encoded := &bytes.Buffer{}
+					require.NoError(t, codec.EncodeFrame(response, encoded))
+					encoded.Write([]byte{0xca, 0xfe}) // next frame
+					header, err := codec.DecodeHeader(encoded)
+					require.NoError(t, err)
+					body, source, err := codec.DecodeBodyPrefix(header, encoded)
+					require.NoError(t, err)
+					assert.Nil(t, body.Message)
+					assert.Equal(t, response.Body.Warnings, body.Warnings)
+					decoder, err := message.NewRowsDecoder(source, version)
+					require.NoError(t, err)
+					row, err := decoder.Next()
+					require.NoError(t, err)
+					assert.Equal(t, response.Body.Message.(*message.RowsResult).Data[0], row)
+					assert.False(t, decoder.HasNext())
+					assert.Equal(t, []byte{0xca, 0xfe}, encoded.Bytes())
+				})
+			}
+		})
+	}
+}
+
+func TestEncodedFrameLength(t *testing.T) {
+	codec := NewRawCodec()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			request, response := createFrames(version)
+			for _, f := range []*Frame{request, response} {
+				length, err := codec.EncodedFrameLength(f)
+				require.NoError(t, err)
+				encoded := &bytes.Buffer{}
+				require.NoError(t, codec.EncodeFrame(f, encoded))
+				assert.Equal(t, encoded.Len(), length)
+				assert.Equal(t, int(f.Header.BodyLength), length-version.FrameHeaderLengthInBytes())
+			}
+		})
+	}
+	t.Run("compressed", func(t *testing.T) {
+		codec := NewRawCodecWithCompression(lz4.Compressor{})
+		f := NewFrame(primitive.ProtocolVersion4, 1, message.NewStartup())
+		f.Header.Flags = f.Header.Flags.Add(primitive.HeaderFlagCompressed)
+		_, err := codec.EncodedFrameLength(f)
+		assert.EqualError(t, err, "cannot compute length of compressed frame before compressing its body")
+		codec.SetCompressionThreshold(1024)
+		length, err := codec.EncodedFrameLength(f)
+		require.NoError(t, err)
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(f, encoded))
+		assert.Equal(t, encoded.Len(), length)
+		// skipping compression does not modify the frame being encoded
+		assert.True(t, f.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+		decoded, err := codec.DecodeFrame(encoded)
+		require.NoError(t, err)
+		assert.False(t, decoded.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	})
+}
+
 func createCodecs() map[string]RawCodec {
 	codecs := map[string]RawCodec{
 		"NONE":   NewRawCodec(),
@@ -160,3 +377,216 @@ func createFrames(version primitive.ProtocolVersion) (*Frame, *Frame) {
 	}
 	return request, response
 }
+
+func TestCodecUTF8Validation(t *testing.T) {
+	invalid := string([]byte{'a', 0xff, 'b'})
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: invalid, Options: &message.QueryOptions{}})
+	warnings := NewFrame(primitive.ProtocolVersion4, 1, &message.VoidResult{})
+	warnings.SetWarnings([]string{"ok", invalid})
+	valid := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM système", Options: &message.QueryOptions{}})
+	tests := []struct {
+		name  string
+		frame *Frame
+		path  string
+	}{
+		{"message", query, "(*message.Query).Query"},
+		{"warnings", warnings, "Warnings[1]"},
+		{"valid", valid, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := &bytes.Buffer{}
+			require.NoError(t, NewCodec().EncodeFrame(tt.frame, encoded))
+
+			codec := NewRawCodec()
+			decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, tt.frame, decoded)
+
+			codec.SetUTF8Validation(true)
+			for _, decode := range []func() (*Frame, error){
+				func() (*Frame, error) { return codec.DecodeFrame(bytes.NewReader(encoded.Bytes())) },
+				func() (*Frame, error) {
+					return codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), AcquireArena())
+				},
+			} {
+				decoded, err = decode()
+				if tt.path == "" {
+					require.NoError(t, err)
+					assert.Equal(t, tt.frame, decoded)
+				} else {
+					assert.Nil(t, decoded)
+					var utf8Err *message.InvalidUTF8Error
+					require.True(t, errors.As(err, &utf8Err), err)
+					assert.Equal(t, tt.path, utf8Err.Path)
+					assert.Equal(t, invalid, utf8Err.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeFrame_Stream(t *testing.T) {
+	codec := NewCodec()
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query:   "SELECT * FROM system.local",
+		Options: &message.QueryOptions{},
+	})
+	withTrailingBytes := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(query, withTrailingBytes))
+	binary.BigEndian.PutUint32(withTrailingBytes.Bytes()[5:], uint32(withTrailingBytes.Len()-primitive.FrameHeaderLengthV3AndHigher+2))
+	withTrailingBytes.Write([]byte{0xca, 0xfe})
+	frames := []*Frame{
+		query,
+		NewFrame(primitive.ProtocolVersion4, 2, &message.Options{}),
+		NewFrame(primitive.ProtocolVersion4, 3, &message.Prepare{Query: "SELECT * FROM system.peers"}),
+	}
+	client, server := net.Pipe()
+	go func() {
+		// the first frame has trailing bytes, which must be consumed even though they are ignored
+		_, _ = client.Write(withTrailingBytes.Bytes())
+		for _, f := range frames[1:] {
+			_ = codec.EncodeFrame(f, client)
+		}
+	}()
+	for _, expected := range frames {
+		decoded, err := codec.DecodeFrame(server)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Header.StreamId, decoded.Header.StreamId)
+		assert.Equal(t, expected.Body.Message, decoded.Body.Message)
+	}
+	_ = client.Close()
+	_, err := codec.DecodeFrame(server)
+	require.Error(t, err)
+	_ = server.Close()
+}
+
+// TestCodecLegacyHeader checks the 8-byte frame header of protocol versions 1 and 2.
+func TestCodecLegacyHeader(t *testing.T) {
+	codec := NewCodec()
+	tests := []struct {
+		name     string
+		frame    *Frame
+		expected []byte
+	}{
+		{
+			"v1 request",
+			NewFrame(primitive.ProtocolVersion1, 127, &message.Query{
+				Query:   "SELECT",
+				Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+			}),
+			[]byte{
+				0x01, 0x00, 0x7f, 0x07, 0x00, 0x00, 0x00, 0x0c, // header
+				0x00, 0x00, 0x00, 0x06, 'S', 'E', 'L', 'E', 'C', 'T', 0x00, 0x01, // query and consistency, no flags
+			},
+		},
+		{
+			"v1 response",
+			NewFrame(primitive.ProtocolVersion1, 1, &message.PreparedResult{
+				PreparedQueryId:   []byte{0xca, 0xfe},
+				VariablesMetadata: &message.VariablesMetadata{},
+			}),
+			[]byte{0x81, 0x00, 0x01, 0x08, 0x00, 0x00, 0x00, 0x10},
+		},
+		{
+			"v2 request",
+			NewFrame(primitive.ProtocolVersion2, 127, &message.Options{}),
+			[]byte{0x02, 0x00, 0x7f, 0x05, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			"v2 event",
+			NewFrame(primitive.ProtocolVersion2, -1, &message.StatusChangeEvent{
+				ChangeType: primitive.StatusChangeTypeUp,
+				Address:    &primitive.Inet{Addr: net.IPv4(127, 0, 0, 1), Port: 9042},
+			}),
+			[]byte{0x82, 0x00, 0xff, 0x0c},
+		},
+		{
+			"v3 request",
+			NewFrame(primitive.ProtocolVersion3, 128, &message.Options{}),
+			[]byte{0x03, 0x00, 0x00, 0x80, 0x05, 0x00, 0x00, 0x00, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(tt.frame, encoded))
+			assert.Equal(t, tt.expected, encoded.Bytes()[:len(tt.expected)])
+			assert.Equal(t, tt.frame.Header.Version.FrameHeaderLengthInBytes()+int(tt.frame.Header.BodyLength), encoded.Len())
+			decoded, err := codec.DecodeFrame(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.frame.Header, decoded.Header)
+			assert.Equal(t, tt.frame.Body.Message, decoded.Body.Message)
+		})
+	}
+	t.Run("v1 stream id range", func(t *testing.T) {
+		err := codec.EncodeFrame(NewFrame(primitive.ProtocolVersion1, 128, &message.Options{}), &bytes.Buffer{})
+		assert.True(t, errors.Is(err, ErrInvalidStreamId), "%v", err)
+	})
+}
+
+// TestCodecConcurrentUse shares a single codec among several goroutines; it is meant to be run with the race detector.
+func TestCodecConcurrentUse(t *testing.T) {
+	codec := NewRawCodecWithCompression(lz4.Compressor{})
+	compressionMetrics := &CompressionMetrics{}
+	messageMetrics := &MessageMetrics{}
+	codec.SetCompressionObserver(compressionMetrics)
+	codec.SetMessageObserver(messageMetrics)
+	budget, err := NewMemoryBudget(1024 * 1024 * 1024)
+	require.NoError(t, err)
+	codec.SetMemoryBudget(budget)
+	codec.SetStreamIdValidation(true)
+
+	const goroutines = 16
+	const iterations = 50
+	wg := &sync.WaitGroup{}
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				query := fmt.Sprintf("SELECT * FROM ks.t%d WHERE k = %d", i, j)
+				request := NewFrame(primitive.ProtocolVersion4, int16(j), &message.Query{Query: query})
+				request.SetCompress(true)
+				request.SetCustomPayload(map[string][]byte{"goroutine": {byte(i)}})
+				encoded := &bytes.Buffer{}
+				if err := codec.EncodeFrame(request, encoded); err != nil {
+					errs <- err
+					return
+				}
+				raw, err := codec.DecodeRawFrame(bytes.NewReader(encoded.Bytes()))
+				if err != nil {
+					errs <- err
+					return
+				}
+				decoded, err := codec.ConvertFromRawFrame(raw)
+				if err != nil {
+					errs <- err
+					return
+				}
+				arena := AcquireArena()
+				inArena, err := codec.DecodeFrameInArena(bytes.NewReader(encoded.Bytes()), arena)
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, f := range []*Frame{decoded, inArena} {
+					if f.Body.Message.(*message.Query).Query != query || f.Body.CustomPayload["goroutine"][0] != byte(i) {
+						errs <- fmt.Errorf("unexpected decoded frame: %v", f)
+						return
+					}
+				}
+				arena.Release()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(goroutines*iterations), compressionMetrics.Snapshot().CompressedFrames)
+	assert.Equal(t, uint64(goroutines*iterations*2), compressionMetrics.Snapshot().DecompressedFrames)
+	assert.Equal(t, uint64(goroutines*iterations), messageMetrics.Snapshot().Encoded[primitive.OpCodeQuery].Count)
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CompressionStats holds statistics about the compression or decompression of a single frame body.
+type CompressionStats struct {
+	// Header is the header of the frame whose body was compressed or decompressed.
+	Header *Header
+	// UncompressedLength is the length of the uncompressed body.
+	UncompressedLength int
+	// CompressedLength is the length of the compressed body, including the length prefix; it is zero if compression
+	// was skipped.
+	CompressedLength int
+	// Duration is the time spent compressing or decompressing the body; it is zero if compression was skipped.
+	Duration time.Duration
+}
+
+// Ratio returns the compression ratio, that is, the compressed length divided by the uncompressed length. It returns
+// 1 if compression was skipped or if the uncompressed body is empty.
+func (s *CompressionStats) Ratio() float64 {
+	if s.CompressedLength == 0 || s.UncompressedLength == 0 {
+		return 1
+	}
+	return float64(s.CompressedLength) / float64(s.UncompressedLength)
+}
+
+// CompressionObserver is a hook that receives statistics about frame body compression, e.g. to export them as metrics
+// for capacity planning. It can be set on a codec with RawCodec.SetCompressionObserver. Its methods are invoked
+// synchronously by the codec: implementations must be safe for concurrent use and should return quickly.
+type CompressionObserver interface {
+
+	// OnCompressed is invoked after a frame body was compressed.
+	OnCompressed(stats *CompressionStats)
+
+	// OnDecompressed is invoked after a frame body was decompressed.
+	OnDecompressed(stats *CompressionStats)
+
+	// OnCompressionSkipped is invoked when a frame with the compressed flag set was encoded without compression,
+	// because its body length was below the compression threshold. See RawCodec.SetCompressionThreshold.
+	OnCompressionSkipped(stats *CompressionStats)
+}
+
+// CompressionMetrics is a CompressionObserver that aggregates compression statistics. The zero value is ready to use.
+// It is safe for concurrent use.
+type CompressionMetrics struct {
+	compressedFrames        uint64
+	compressedBytesIn       uint64
+	compressedBytesOut      uint64
+	compressionNanos        uint64
+	decompressedFrames      uint64
+	decompressedBytesIn     uint64
+	decompressedBytesOut    uint64
+	decompressionNanos      uint64
+	skippedFrames           uint64
+	skippedUncompressedSize uint64
+}
+
+// CompressionMetricsSnapshot is a point-in-time copy of the statistics aggregated by CompressionMetrics.
+type CompressionMetricsSnapshot struct {
+	// CompressedFrames is the number of frame bodies compressed.
+	CompressedFrames uint64
+	// CompressedBytesIn is the total length of frame bodies before compression.
+	CompressedBytesIn uint64
+	// CompressedBytesOut is the total length of frame bodies after compression.
+	CompressedBytesOut uint64
+	// CompressionTime is the total time spent compressing frame bodies.
+	CompressionTime time.Duration
+	// DecompressedFrames is the number of frame bodies decompressed.
+	DecompressedFrames uint64
+	// DecompressedBytesIn is the total length of frame bodies before decompression.
+	DecompressedBytesIn uint64
+	// DecompressedBytesOut is the total length of frame bodies after decompression.
+	DecompressedBytesOut uint64
+	// DecompressionTime is the total time spent decompressing frame bodies.
+	DecompressionTime time.Duration
+	// SkippedFrames is the number of frame bodies not compressed because they were below the compression threshold.
+	SkippedFrames uint64
+	// SkippedBytes is the total length of frame bodies not compressed because they were below the compression
+	// threshold.
+	SkippedBytes uint64
+}
+
+// CompressionRatio returns the overall compression ratio of compressed frames, or 1 if no frame was compressed.
+func (s CompressionMetricsSnapshot) CompressionRatio() float64 {
+	if s.CompressedBytesIn == 0 {
+		return 1
+	}
+	return float64(s.CompressedBytesOut) / float64(s.CompressedBytesIn)
+}
+
+func (m *CompressionMetrics) OnCompressed(stats *CompressionStats) {
+	atomic.AddUint64(&m.compressedFrames, 1)
+	atomic.AddUint64(&m.compressedBytesIn, uint64(stats.UncompressedLength))
+	atomic.AddUint64(&m.compressedBytesOut, uint64(stats.CompressedLength))
+	atomic.AddUint64(&m.compressionNanos, uint64(stats.Duration))
+}
+
+func (m *CompressionMetrics) OnDecompressed(stats *CompressionStats) {
+	atomic.AddUint64(&m.decompressedFrames, 1)
+	atomic.AddUint64(&m.decompressedBytesIn, uint64(stats.CompressedLength))
+	atomic.AddUint64(&m.decompressedBytesOut, uint64(stats.UncompressedLength))
+	atomic.AddUint64(&m.decompressionNanos, uint64(stats.Duration))
+}
+
+func (m *CompressionMetrics) OnCompressionSkipped(stats *CompressionStats) {
+	atomic.AddUint64(&m.skippedFrames, 1)
+	atomic.AddUint64(&m.skippedUncompressedSize, uint64(stats.UncompressedLength))
+}
+
+// Snapshot returns a copy of the statistics aggregated so far.
+func (m *CompressionMetrics) Snapshot() CompressionMetricsSnapshot {
+	return CompressionMetricsSnapshot{
+		CompressedFrames:     atomic.LoadUint64(&m.compressedFrames),
+		CompressedBytesIn:    atomic.LoadUint64(&m.compressedBytesIn),
+		CompressedBytesOut:   atomic.LoadUint64(&m.compressedBytesOut),
+		CompressionTime:      time.Duration(atomic.LoadUint64(&m.compressionNanos)),
+		DecompressedFrames:   atomic.LoadUint64(&m.decompressedFrames),
+		DecompressedBytesIn:  atomic.LoadUint64(&m.decompressedBytesIn),
+		DecompressedBytesOut: atomic.LoadUint64(&m.decompressedBytesOut),
+		DecompressionTime:    time.Duration(atomic.LoadUint64(&m.decompressionNanos)),
+		SkippedFrames:        atomic.LoadUint64(&m.skippedFrames),
+		SkippedBytes:         atomic.LoadUint64(&m.skippedUncompressedSize),
+	}
+}
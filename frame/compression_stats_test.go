@@ -0,0 +1,73 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/snappy"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecCompressionStats(t *testing.T) {
+	metrics := &CompressionMetrics{}
+	codec := NewRawCodecWithCompression(snappy.Compressor{})
+	codec.SetCompressionObserver(metrics)
+	codec.SetCompressionThreshold(100)
+
+	// small body: compression skipped
+	small := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT"})
+	small.SetCompress(true)
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(small, encoded))
+	assert.True(t, small.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	decoded, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.False(t, decoded.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	assert.Equal(t, small.Body.Message.(*message.Query).Query, decoded.Body.Message.(*message.Query).Query)
+
+	// large, highly compressible body: compressed
+	large := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT " + strings.Repeat("a", 1000)})
+	large.SetCompress(true)
+	encoded.Reset()
+	require.NoError(t, codec.EncodeFrame(large, encoded))
+	assert.True(t, large.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+	decoded, err = codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, large.Body.Message.(*message.Query).Query, decoded.Body.Message.(*message.Query).Query)
+
+	snapshot := metrics.Snapshot()
+	assert.EqualValues(t, 1, snapshot.SkippedFrames)
+	assert.Less(t, snapshot.SkippedBytes, uint64(100))
+	assert.EqualValues(t, 1, snapshot.CompressedFrames)
+	assert.Greater(t, snapshot.CompressedBytesIn, uint64(1000))
+	assert.Equal(t, uint64(large.Header.BodyLength), snapshot.CompressedBytesOut)
+	assert.Less(t, snapshot.CompressionRatio(), 0.5)
+	assert.EqualValues(t, 1, snapshot.DecompressedFrames)
+	assert.Equal(t, snapshot.CompressedBytesOut, snapshot.DecompressedBytesIn)
+	assert.Equal(t, snapshot.CompressedBytesIn, snapshot.DecompressedBytesOut)
+}
+
+func TestCompressionStats_Ratio(t *testing.T) {
+	assert.Equal(t, 0.25, (&CompressionStats{UncompressedLength: 100, CompressedLength: 25}).Ratio())
+	assert.Equal(t, 1.0, (&CompressionStats{UncompressedLength: 100}).Ratio())
+	assert.Equal(t, 1.0, CompressionMetricsSnapshot{}.CompressionRatio())
+}
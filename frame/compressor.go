@@ -18,6 +18,8 @@ import (
 	"io"
 )
 
+// BodyCompressor compresses and decompresses frame bodies. Implementations must be safe for concurrent use, since
+// codecs may be shared by several goroutines, see Codec.
 type BodyCompressor interface {
 
 	// CompressWithLength compresses the source, reading it fully, and writes the compressed length and the compressed
@@ -28,3 +30,13 @@ type BodyCompressor interface {
 	// decompressed result to dest. This is Cassandra's expected format of compressed frame bodies.
 	DecompressWithLength(source io.Reader, dest io.Writer) error
 }
+
+// DecompressedLengthReader is an optional interface that BodyCompressor implementations can satisfy when their
+// compressed format declares the decompressed length upfront. Codecs use it to reject compressed bodies that would
+// exceed their maximum body length before decompressing them, see RawCodec.SetMaxBodyLength.
+type DecompressedLengthReader interface {
+
+	// DecompressedLength returns the decompressed length declared by the given compressed body, as produced by
+	// CompressWithLength.
+	DecompressedLength(compressedBody []byte) (int, error)
+}
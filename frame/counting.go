@@ -0,0 +1,57 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	io.Writer
+	count int
+}
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	w.count += n
+	return
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	io.Reader
+	count int
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	r.count += n
+	return
+}
+
+// countingSlicer is a countingReader that preserves the primitive.Slicer capability of the underlying reader.
+type countingSlicer struct {
+	*countingReader
+	slicer primitive.Slicer
+}
+
+func (s *countingSlicer) Slice(length int) ([]byte, error) {
+	b, err := s.slicer.Slice(length)
+	s.count += len(b)
+	return b, err
+}
@@ -20,14 +20,17 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"time"
+	"unicode/utf8"
 
+	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 func (c *codec) DecodeFrame(source io.Reader) (*Frame, error) {
 	if header, err := c.DecodeHeader(source); err != nil {
 		return nil, fmt.Errorf("cannot decode frame header: %w", err)
-	} else if body, err := c.DecodeBody(header, source); err != nil {
+	} else if body, err := c.decodeBody(header, source, true); err != nil {
 		return nil, fmt.Errorf("cannot decode frame body: %w", err)
 	} else {
 		return &Frame{Header: header, Body: body}, nil
@@ -63,7 +66,7 @@ func (c *codec) DecodeHeader(source io.Reader) (*Header, error) {
 		useBetaFlag := primitive.HeaderFlag(flags).Contains(primitive.HeaderFlagUseBeta)
 
 		var opCode uint8
-		if err = primitive.CheckSupportedProtocolVersion(version); err != nil {
+		if err = primitive.CheckSupportedFramingProtocolVersion(version); err != nil {
 			return nil, NewProtocolVersionErr(err.Error(), version, useBetaFlag)
 		} else if version.IsBeta() && !useBetaFlag {
 			return nil, NewProtocolVersionErr("expected USE_BETA flag to be set", version, useBetaFlag)
@@ -76,8 +79,21 @@ func (c *codec) DecodeHeader(source io.Reader) (*Header, error) {
 		}
 		header.Flags = primitive.HeaderFlag(flags)
 		header.OpCode = primitive.OpCode(opCode)
-		if err := primitive.CheckValidOpCode(header.OpCode); err != nil {
+		// opcodes unknown to this library are only accepted if a custom message codec was registered for them
+		_, custom := c.decoders[header.OpCode]
+		custom = custom && !header.OpCode.IsValid()
+		if err := primitive.CheckValidOpCode(header.OpCode); err != nil && !custom {
 			return nil, err
+		} else if err := c.checkDecodeDirection(header); err != nil {
+			return nil, err
+		} else if err := c.checkStreamId(header); err != nil {
+			return nil, fmt.Errorf("cannot decode header stream id: %w", err)
+		} else if err := checkOpCode(header); err != nil {
+			return nil, fmt.Errorf("cannot decode header opcode: %w", err)
+		} else if err := c.checkUnknownFlags(header); err != nil {
+			return nil, fmt.Errorf("cannot decode header flags: %w", err)
+		} else if custom {
+			return header, nil
 		} else if isResponse {
 			if err := primitive.CheckResponseOpCode(header.OpCode); err != nil {
 				return nil, err
@@ -92,18 +108,87 @@ func (c *codec) DecodeHeader(source io.Reader) (*Header, error) {
 }
 
 func (c *codec) DecodeBody(header *Header, source io.Reader) (body *Body, err error) {
-	if compressed := header.Flags.Contains(primitive.HeaderFlagCompressed); compressed {
-		if c.compressor == nil {
-			return nil, errors.New("cannot decompress body: no compressor available")
-		} else {
-			decompressedBody := &bytes.Buffer{}
-			if err := c.compressor.DecompressWithLength(io.LimitReader(source, int64(header.BodyLength)), decompressedBody); err != nil {
-				return nil, fmt.Errorf("cannot decompress body: %w", err)
-			} else {
-				source = decompressedBody
+	return c.decodeBody(header, source, false)
+}
+
+// decodeBody decodes a frame body from the given source. If consumeBody is true, the bytes remaining in the body after
+// the message, if any, are always consumed, even if they are ignored by the TrailingBytesPolicy, so that the source is
+// positioned at the beginning of the next frame when this method returns successfully.
+func (c *codec) decodeBody(header *Header, source io.Reader, consumeBody bool) (body *Body, err error) {
+	release, err := c.acquireBody(header)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if body, source, err = c.DecodeBodyPrefix(header, source); err != nil {
+		return nil, err
+	} else if body.Message, err = c.decodeMessage(header, source); err != nil {
+		return nil, err
+	} else if header.BodyLength >= 0 || header.Flags.Contains(primitive.HeaderFlagCompressed) {
+		// the source is bounded to the frame body
+		if err = c.checkTrailingBytes(header, body, source); err != nil {
+			return nil, err
+		} else if limited, ok := source.(*io.LimitedReader); ok && consumeBody && limited.N > 0 {
+			if _, err = io.Copy(ioutil.Discard, limited); err != nil {
+				return nil, fmt.Errorf("cannot discard trailing bytes: %w", err)
 			}
 		}
 	}
+	return body, nil
+}
+
+func (c *codec) DecodeBodyPrefix(header *Header, source io.Reader) (body *Body, message io.Reader, err error) {
+	if compressed := header.Flags.Contains(primitive.HeaderFlagCompressed); compressed {
+		decompressedBody := &bytes.Buffer{}
+		if err := c.decompressBody(header, source, decompressedBody); err != nil {
+			return nil, nil, err
+		}
+		source = decompressedBody
+	} else if header.BodyLength >= 0 {
+		source = io.LimitReader(source, int64(header.BodyLength))
+	}
+	if body, err = c.decodeBodyPrefix(header, source); err != nil {
+		return nil, nil, err
+	}
+	return body, source, nil
+}
+
+// decompressBody decompresses the body of the given header, read from source, into dest.
+func (c *codec) decompressBody(header *Header, source io.Reader, dest *bytes.Buffer) error {
+	if c.compressor == nil {
+		return errors.New("cannot decompress body: no compressor available")
+	}
+	start := time.Now()
+	source = io.LimitReader(source, int64(header.BodyLength))
+	if lengthReader, ok := c.compressor.(DecompressedLengthReader); ok && c.maxBodyLength > 0 {
+		compressed := &bytes.Buffer{}
+		if _, err := compressed.ReadFrom(source); err != nil {
+			return fmt.Errorf("cannot read compressed body: %w", err)
+		} else if length, err := lengthReader.DecompressedLength(compressed.Bytes()); err != nil {
+			return fmt.Errorf("cannot decompress body: %w", err)
+		} else if err := c.checkDecompressedBodyLength(length); err != nil {
+			return fmt.Errorf("cannot decompress body: %w", err)
+		}
+		source = compressed
+	}
+	if err := c.compressor.DecompressWithLength(source, dest); err != nil {
+		return fmt.Errorf("cannot decompress body: %w", err)
+	} else if err := c.checkDecompressedBodyLength(dest.Len()); err != nil {
+		return fmt.Errorf("cannot decompress body: %w", err)
+	}
+	if c.compressionObserver != nil {
+		c.compressionObserver.OnDecompressed(&CompressionStats{
+			Header:             header,
+			UncompressedLength: dest.Len(),
+			CompressedLength:   int(header.BodyLength),
+			Duration:           time.Since(start),
+		})
+	}
+	return nil
+}
+
+// decodeBodyPrefix decodes the body prefix from the given source, which must be uncompressed.
+func (c *codec) decodeBodyPrefix(header *Header, source io.Reader) (body *Body, err error) {
 	body = &Body{}
 	if header.IsResponse && header.Flags.Contains(primitive.HeaderFlagTracing) {
 		if body.TracingId, err = primitive.ReadUuid(source); err != nil {
@@ -111,21 +196,62 @@ func (c *codec) DecodeBody(header *Header, source io.Reader) (body *Body, err er
 		}
 	}
 	if header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
-		if body.CustomPayload, err = primitive.ReadBytesMap(source); err != nil {
+		if body.CustomPayload, err = primitive.ReadBytesMapWithLimits(source, c.customPayloadLimits); err != nil {
 			return nil, fmt.Errorf("cannot decode body custom payload: %w", err)
 		}
 	}
 	if header.IsResponse && header.Flags.Contains(primitive.HeaderFlagWarning) {
 		if body.Warnings, err = primitive.ReadStringList(source); err != nil {
 			return nil, fmt.Errorf("cannot decode body warnings: %w", err)
+		} else if c.validateUTF8 {
+			for i, warning := range body.Warnings {
+				if !utf8.ValidString(warning) {
+					err = &message.InvalidUTF8Error{Path: fmt.Sprintf("Warnings[%d]", i), Value: warning}
+					return nil, fmt.Errorf("cannot decode body warnings: %w", err)
+				}
+			}
 		}
 	}
-	if decoder, err := c.findMessageCodec(header.OpCode); err != nil {
+	return body, nil
+}
+
+// decodeMessage decodes the message from the given source, which must be positioned after the body prefix.
+func (c *codec) decodeMessage(header *Header, source io.Reader) (msg message.Message, err error) {
+	decoder, err := c.findMessageDecoder(header.OpCode)
+	if err != nil {
 		return nil, err
-	} else if body.Message, err = decoder.Decode(source, header.Version); err != nil {
+	} else if c.messageObserver == nil {
+		if msg, err = decoder.Decode(source, header.Version); err != nil {
+			return nil, fmt.Errorf("cannot decode body message: %w", err)
+		}
+		return c.checkUTF8(msg)
+	}
+	counter := &countingReader{Reader: source}
+	var counted io.Reader = counter
+	if slicer, ok := source.(primitive.Slicer); ok {
+		counted = &countingSlicer{countingReader: counter, slicer: slicer}
+	}
+	start := time.Now()
+	if msg, err = decoder.Decode(counted, header.Version); err != nil {
 		return nil, fmt.Errorf("cannot decode body message: %w", err)
 	}
-	return body, err
+	c.messageObserver.OnMessageDecoded(&MessageStats{
+		OpCode:   header.OpCode,
+		Version:  header.Version,
+		Length:   counter.count,
+		Duration: time.Since(start),
+	})
+	return c.checkUTF8(msg)
+}
+
+// checkUTF8 validates the strings of the given decoded message, if UTF-8 validation is enabled, and returns it.
+func (c *codec) checkUTF8(msg message.Message) (message.Message, error) {
+	if c.validateUTF8 {
+		if err := message.ValidateUTF8(msg); err != nil {
+			return nil, fmt.Errorf("cannot decode body message: %w", err)
+		}
+	}
+	return msg, nil
 }
 
 func (c *codec) DecodeRawBody(header *Header, source io.Reader) (body []byte, err error) {
@@ -134,6 +260,11 @@ func (c *codec) DecodeRawBody(header *Header, source io.Reader) (body []byte, er
 	} else if header.BodyLength == 0 {
 		return []byte{}, nil
 	}
+	release, err := c.acquireBody(header)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	count := int64(header.BodyLength)
 	buf := bytes.NewBuffer(make([]byte, 0, count))
 	if _, err := io.CopyN(buf, source, count); err != nil {
@@ -49,6 +49,11 @@ func (in *Body) DeepCopyInto(out *Body) {
 	if in.Message != nil {
 		out.Message = in.Message.DeepCopyMessage()
 	}
+	if in.TrailingBytes != nil {
+		in, out := &in.TrailingBytes, &out.TrailingBytes
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,229 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// maxDiffRanges is the maximum number of differing byte ranges reported by DiffEncoded.
+const maxDiffRanges = 10
+
+// maxDiffRangeBytes is the maximum number of bytes printed for each differing byte range reported by DiffEncoded.
+const maxDiffRangeBytes = 16
+
+// Diff returns a field-level textual diff between two decoded frames, with one line per differing field, e.g.
+// "Body.Message.(*message.Query).Options.Consistency: ONE != QUORUM". It returns an empty string if the frames are
+// equal. This is useful to make test failures and proxy bugs easier to interpret.
+func Diff(a, b *Frame) string {
+	var lines []string
+	diffValues("Frame", reflect.ValueOf(a), reflect.ValueOf(b), &lines)
+	return strings.Join(lines, "\n")
+}
+
+func diffValues(path string, a, b reflect.Value, lines *[]string) {
+	report := func(format string, args ...interface{}) {
+		*lines = append(*lines, path+": "+fmt.Sprintf(format, args...))
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			report("%v != %v", formatValue(a), formatValue(b))
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		report("%v != %v", a.Type(), b.Type())
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				report("%v != %v", formatValue(a), formatValue(b))
+			}
+			return
+		}
+		if a.Kind() == reflect.Interface {
+			if a.Elem().Type() != b.Elem().Type() {
+				report("%v != %v", a.Elem().Type(), b.Elem().Type())
+				return
+			}
+			path = fmt.Sprintf("%s.(%v)", path, a.Elem().Type())
+		}
+		diffValues(path, a.Elem(), b.Elem(), lines)
+	case reflect.Struct:
+		exported := 0
+		for i := 0; i < a.NumField(); i++ {
+			if field := a.Type().Field(i); field.PkgPath == "" {
+				exported++
+				diffValues(path+"."+field.Name, a.Field(i), b.Field(i), lines)
+			}
+		}
+		if exported == 0 && !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			// opaque struct, e.g. big.Int
+			report("%v != %v", formatValue(a), formatValue(b))
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			if !bytes.Equal(bytesOf(a), bytesOf(b)) {
+				report("%v != %v", formatValue(a), formatValue(b))
+			}
+			return
+		}
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			report("%v != %v", formatValue(a), formatValue(b))
+			return
+		}
+		if a.Len() != b.Len() {
+			report("length %d != %d", a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), lines)
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			report("%v != %v", formatValue(a), formatValue(b))
+			return
+		}
+		for _, key := range sortedMapKeys(a, b) {
+			diffValues(fmt.Sprintf("%s[%v]", path, formatValue(key)), a.MapIndex(key), b.MapIndex(key), lines)
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			report("%v != %v", formatValue(a), formatValue(b))
+		}
+	}
+}
+
+// sortedMapKeys returns the union of the keys of both maps, sorted by their textual representation.
+func sortedMapKeys(a, b reflect.Value) []reflect.Value {
+	keys := map[string]reflect.Value{}
+	for _, key := range append(a.MapKeys(), b.MapKeys()...) {
+		keys[formatValue(key)] = key
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sorted := make([]reflect.Value, len(names))
+	for i, name := range names {
+		sorted[i] = keys[name]
+	}
+	return sorted
+}
+
+func bytesOf(v reflect.Value) []byte {
+	if v.Kind() == reflect.Array {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b
+	}
+	return v.Bytes()
+}
+
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<missing>"
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map:
+		if v.IsNil() {
+			return "<nil>"
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return "<nil>"
+		} else if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("0x%x", v.Bytes())
+		}
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("0x%x", bytesOf(v))
+		}
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%v", v.Interface())
+	}
+	return v.String()
+}
+
+// DiffEncoded returns a byte-level textual diff between two encoded frames, with one line per range of differing
+// bytes, including its offset and whether it is located in the frame header or in the frame body. It returns an empty
+// string if the encoded frames are equal.
+func DiffEncoded(a, b []byte) string {
+	var lines []string
+	if len(a) != len(b) {
+		lines = append(lines, fmt.Sprintf("length: %d != %d", len(a), len(b)))
+	}
+	headerLength := encodedHeaderLength(a)
+	var ranges [][2]int
+	for i := 0; i < len(a) || i < len(b); {
+		if i < len(a) && i < len(b) && a[i] == b[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(a) || i < len(b) {
+			if i < len(a) && i < len(b) && a[i] == b[i] {
+				break
+			}
+			i++
+		}
+		ranges = append(ranges, [2]int{start, i})
+	}
+	for i, r := range ranges {
+		if i == maxDiffRanges {
+			lines = append(lines, fmt.Sprintf("... and %d more differing ranges", len(ranges)-maxDiffRanges))
+			break
+		}
+		location := "body"
+		if r[0] < headerLength {
+			location = "header"
+		}
+		lines = append(lines, fmt.Sprintf("offset %d-%d (%s): %s != %s",
+			r[0], r[1]-1, location, formatRange(a, r), formatRange(b, r)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// encodedHeaderLength returns the header length of the given encoded frame, as indicated by its version byte.
+func encodedHeaderLength(encoded []byte) int {
+	if len(encoded) > 0 && primitive.ProtocolVersion(encoded[0]&0b0111_1111) < primitive.ProtocolVersion3 {
+		return primitive.FrameHeaderLengthV2AndLower
+	}
+	return primitive.FrameHeaderLengthV3AndHigher
+}
+
+func formatRange(encoded []byte, r [2]int) string {
+	start, end := r[0], r[1]
+	if start >= len(encoded) {
+		return "<missing>"
+	} else if end > len(encoded) {
+		end = len(encoded)
+	}
+	if end-start > maxDiffRangeBytes {
+		return fmt.Sprintf("0x%x... (%d bytes)", encoded[start:start+maxDiffRangeBytes], end-start)
+	}
+	return fmt.Sprintf("0x%x", encoded[start:end])
+}
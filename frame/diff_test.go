@@ -0,0 +1,87 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestDiff(t *testing.T) {
+	newQuery := func() *Frame {
+		return NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+			Query: "SELECT * FROM ks.t WHERE pk = ?",
+			Options: &message.QueryOptions{
+				Consistency:      primitive.ConsistencyLevelOne,
+				PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1, 2})},
+			},
+		})
+	}
+	a, b := newQuery(), newQuery()
+	assert.Empty(t, Diff(a, b))
+
+	b.Header.StreamId = 2
+	b.Body.Message.(*message.Query).Options.Consistency = primitive.ConsistencyLevelQuorum
+	b.Body.Message.(*message.Query).Options.PositionalValues[0].Contents = []byte{1, 3}
+	b.Body.Message.(*message.Query).Options.PositionalValues = append(b.Body.Message.(*message.Query).Options.PositionalValues, primitive.NewValue(nil))
+	b.SetCustomPayload(map[string][]byte{"key": {0xca, 0xfe}})
+	assert.Equal(t,
+		"Frame.Header.Flags: HeaderFlag ? [0X00 0b00000000] != HeaderFlag CustomPayload [0x04 0b00000100]\n"+
+			"Frame.Header.StreamId: 1 != 2\n"+
+			"Frame.Body.CustomPayload: <nil> != map[key:[202 254]]\n"+
+			"Frame.Body.Message.(*message.Query).Options.Consistency: ConsistencyLevel ONE [0x0001] != ConsistencyLevel QUORUM [0x0004]\n"+
+			"Frame.Body.Message.(*message.Query).Options.PositionalValues: length 1 != 2\n"+
+			"Frame.Body.Message.(*message.Query).Options.PositionalValues[0].Contents: 0x0102 != 0x0103",
+		Diff(a, b))
+
+	b = NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	assert.Equal(t,
+		"Frame.Header.OpCode: OpCode QUERY [0x07] != OpCode OPTIONS [0x05]\n"+
+			"Frame.Body.Message: *message.Query != *message.Options",
+		Diff(a, b))
+	assert.Equal(t, "Frame: "+a.String()+" != <nil>", Diff(a, nil))
+}
+
+func TestDiffEncoded(t *testing.T) {
+	codec := NewCodec()
+	encode := func(f *Frame) []byte {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(f, encoded))
+		return encoded.Bytes()
+	}
+	a := encode(NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT a"}))
+	assert.Empty(t, DiffEncoded(a, a))
+
+	b := encode(NewFrame(primitive.ProtocolVersion4, 2, &message.Query{Query: "SELECT b"}))
+	assert.Equal(t,
+		"offset 3-3 (header): 0x01 != 0x02\n"+
+			"offset 20-20 (body): 0x61 != 0x62",
+		DiffEncoded(a, b))
+
+	b = encode(NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT abc"}))
+	assert.Equal(t,
+		"length: 24 != 26\n"+
+			"offset 8-8 (header): 0x0f != 0x11\n"+
+			"offset 12-12 (body): 0x08 != 0x0a\n"+
+			"offset 21-22 (body): 0x0000 != 0x6263\n"+
+			"offset 24-25 (body): <missing> != 0x0000",
+		DiffEncoded(a, b))
+}
@@ -0,0 +1,161 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DumpEncoding is the text encoding of the frame bytes in a frame dump.
+type DumpEncoding string
+
+const (
+	DumpEncodingHex    = DumpEncoding("hex")
+	DumpEncodingBase64 = DumpEncoding("base64")
+)
+
+// FrameDump is an encoded frame, along with the information required to decode it: its protocol version and the
+// compression algorithm negotiated on the connection it was captured from. Frame dumps are meant to be shared in bug
+// reports, and are exchanged in text form:
+//
+//  version=4 compression=NONE hex=040000010500000000
+//
+// Use String to produce the text form, and ParseFrameDump to parse it back.
+type FrameDump struct {
+	Version     primitive.ProtocolVersion
+	Compression primitive.Compression
+	Encoding    DumpEncoding
+	// Data is the encoded frame.
+	Data []byte
+}
+
+// NewFrameDump creates a dump of the given encoded frame. The protocol version is read from the frame header; the
+// compression is the algorithm negotiated on the connection the frame was captured from, or
+// primitive.CompressionNone.
+func NewFrameDump(encodedFrame []byte, compression primitive.Compression, encoding DumpEncoding) (*FrameDump, error) {
+	if len(encodedFrame) == 0 {
+		return nil, fmt.Errorf("cannot dump empty frame")
+	} else if !compression.IsValid() {
+		return nil, fmt.Errorf("invalid compression: %v", compression)
+	} else if encoding != DumpEncodingHex && encoding != DumpEncodingBase64 {
+		return nil, fmt.Errorf("invalid dump encoding: %v", encoding)
+	}
+	return &FrameDump{
+		Version:     primitive.ProtocolVersion(encodedFrame[0] & 0b0111_1111),
+		Compression: compression,
+		Encoding:    encoding,
+		Data:        encodedFrame,
+	}, nil
+}
+
+// DumpFrame encodes the given frame with the given encoder, then creates a dump of the encoded frame. The compression
+// must be the algorithm used by the encoder, if any.
+func DumpFrame(
+	frame *Frame,
+	encoder Encoder,
+	compression primitive.Compression,
+	encoding DumpEncoding,
+) (*FrameDump, error) {
+	encoded := &bytes.Buffer{}
+	if err := encoder.EncodeFrame(frame, encoded); err != nil {
+		return nil, fmt.Errorf("cannot encode frame: %w", err)
+	}
+	return NewFrameDump(encoded.Bytes(), compression, encoding)
+}
+
+// ParseFrameDump parses the text form of a frame dump, as produced by FrameDump.String. Whitespace in the encoded
+// data is ignored, so that dumps wrapped over several lines can be parsed as well.
+func ParseFrameDump(dump string) (*FrameDump, error) {
+	fields := strings.Fields(dump)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid frame dump: expected version, compression and data, got: %q", dump)
+	}
+	result := &FrameDump{}
+	if value, err := dumpField(fields[0], "version"); err != nil {
+		return nil, err
+	} else if version, err := strconv.ParseUint(value, 10, 7); err != nil {
+		return nil, fmt.Errorf("invalid frame dump version: %w", err)
+	} else {
+		result.Version = primitive.ProtocolVersion(version)
+	}
+	if value, err := dumpField(fields[1], "compression"); err != nil {
+		return nil, err
+	} else if result.Compression = primitive.Compression(strings.ToUpper(value)); !result.Compression.IsValid() {
+		return nil, fmt.Errorf("invalid frame dump compression: %v", value)
+	}
+	data := strings.Join(fields[2:], "")
+	var err error
+	if strings.HasPrefix(data, string(DumpEncodingHex)+"=") {
+		result.Encoding = DumpEncodingHex
+		result.Data, err = hex.DecodeString(strings.TrimPrefix(data, string(DumpEncodingHex)+"="))
+	} else if strings.HasPrefix(data, string(DumpEncodingBase64)+"=") {
+		result.Encoding = DumpEncodingBase64
+		result.Data, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(data, string(DumpEncodingBase64)+"="))
+	} else {
+		return nil, fmt.Errorf("invalid frame dump data: expected hex= or base64= prefix")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid frame dump data: %w", err)
+	} else if len(result.Data) == 0 {
+		return nil, fmt.Errorf("invalid frame dump data: empty frame")
+	} else if actual := primitive.ProtocolVersion(result.Data[0] & 0b0111_1111); actual != result.Version {
+		return nil, fmt.Errorf("invalid frame dump: version is %v, but frame header version is %v", result.Version, actual)
+	}
+	return result, nil
+}
+
+func dumpField(field string, name string) (string, error) {
+	if !strings.HasPrefix(field, name+"=") {
+		return "", fmt.Errorf("invalid frame dump: expected %v=, got: %q", name, field)
+	}
+	return strings.TrimPrefix(field, name+"="), nil
+}
+
+// String returns the text form of this dump, which can be parsed back with ParseFrameDump.
+func (d *FrameDump) String() string {
+	var data string
+	if d.Encoding == DumpEncodingBase64 {
+		data = base64.StdEncoding.EncodeToString(d.Data)
+	} else {
+		data = hex.EncodeToString(d.Data)
+	}
+	encoding := d.Encoding
+	if encoding == "" {
+		encoding = DumpEncodingHex
+	}
+	compression := d.Compression
+	if compression == "" {
+		compression = primitive.CompressionNone
+	}
+	return fmt.Sprintf("version=%d compression=%v %v=%v", uint8(d.Version), compression, encoding, data)
+}
+
+// Decode decodes the dumped frame with the given decoder. The decoder must use the same compression as the dump, see
+// the Compression field.
+func (d *FrameDump) Decode(decoder Decoder) (*Frame, error) {
+	return decoder.DecodeFrame(bytes.NewReader(d.Data))
+}
+
+// DecodeRaw decodes the dumped frame with the given decoder, without decoding its body.
+func (d *FrameDump) DecodeRaw(decoder RawDecoder) (*RawFrame, error) {
+	return decoder.DecodeRawFrame(bytes.NewReader(d.Data))
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/snappy"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestFrameDump(t *testing.T) {
+	options := NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	dump, err := DumpFrame(options, NewCodec(), primitive.CompressionNone, DumpEncodingHex)
+	require.NoError(t, err)
+	assert.Equal(t, "version=4 compression=NONE hex=040000010500000000", dump.String())
+
+	dump.Encoding = DumpEncodingBase64
+	assert.Equal(t, "version=4 compression=NONE base64=BAAAAQUAAAAA", dump.String())
+
+	for _, text := range []string{
+		"version=4 compression=NONE hex=040000010500000000",
+		"version=4 compression=none base64=BAAAAQUAAAAA",
+		"version=4 compression=NONE\nhex=0400000105\n00000000\n",
+	} {
+		parsed, err := ParseFrameDump(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, primitive.ProtocolVersion4, parsed.Version)
+		assert.Equal(t, primitive.CompressionNone, parsed.Compression)
+		decoded, err := parsed.Decode(NewCodec())
+		require.NoError(t, err)
+		assert.Equal(t, options, decoded)
+	}
+}
+
+func TestFrameDump_Compressed(t *testing.T) {
+	codec := NewRawCodecWithCompression(snappy.Compressor{})
+	query := NewFrame(primitive.ProtocolVersion3, 1, &message.Query{Query: "SELECT * FROM system.local", Options: &message.QueryOptions{}})
+	query.SetCompress(true)
+	dump, err := DumpFrame(query, codec, primitive.CompressionSnappy, DumpEncodingBase64)
+	require.NoError(t, err)
+	parsed, err := ParseFrameDump(dump.String())
+	require.NoError(t, err)
+	assert.Equal(t, dump, parsed)
+	decoded, err := parsed.Decode(codec)
+	require.NoError(t, err)
+	assert.Equal(t, query.Body, decoded.Body)
+	raw, err := parsed.DecodeRaw(codec)
+	require.NoError(t, err)
+	assert.True(t, raw.Header.Flags.Contains(primitive.HeaderFlagCompressed))
+}
+
+func TestParseFrameDump_Errors(t *testing.T) {
+	for text, expected := range map[string]string{
+		"hex=040000010500000000":                            "expected version, compression and data",
+		"v=4 compression=NONE hex=040000010500000000":       "expected version=",
+		"version=x compression=NONE hex=040000010500000000": "invalid frame dump version",
+		"version=4 compression=ZIP hex=040000010500000000":  "invalid frame dump compression: ZIP",
+		"version=4 compression=NONE raw=040000010500000000": "expected hex= or base64= prefix",
+		"version=4 compression=NONE hex=0400zz":             "invalid frame dump data",
+		"version=3 compression=NONE hex=040000010500000000": "version is ProtocolVersion OSS 3, but frame header version is ProtocolVersion OSS 4",
+	} {
+		_, err := ParseFrameDump(text)
+		require.Error(t, err, text)
+		assert.Contains(t, err.Error(), expected, text)
+	}
+}
@@ -15,20 +15,46 @@
 package frame
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
 
 func (c *codec) EncodeFrame(frame *Frame, dest io.Writer) error {
 	if frame.Header.Flags.Contains(primitive.HeaderFlagCompressed) {
-		return c.encodeFrameCompressed(frame, dest)
-	} else {
-		return c.encodeFrameUncompressed(frame, dest)
+		if uncompressed, err := c.maybeSkipCompression(frame); err != nil {
+			return err
+		} else if uncompressed == nil {
+			return c.encodeFrameCompressed(frame, dest)
+		} else {
+			frame = uncompressed
+		}
 	}
+	return c.encodeFrameUncompressed(frame, dest)
+}
+
+// maybeSkipCompression returns a copy of the frame without the compressed header flag if its uncompressed body length
+// is below the compression threshold, or nil if the frame should be compressed. The given frame is never modified.
+func (c *codec) maybeSkipCompression(frame *Frame) (uncompressed *Frame, err error) {
+	if c.compressionThreshold <= 0 {
+		return nil, nil
+	} else if uncompressedBodyLength, err := c.uncompressedBodyLength(frame.Header, frame.Body); err != nil {
+		return nil, fmt.Errorf("cannot compute length of uncompressed message body: %w", err)
+	} else if uncompressedBodyLength < c.compressionThreshold {
+		header := *frame.Header
+		header.Flags = header.Flags.Remove(primitive.HeaderFlagCompressed)
+		if c.compressionObserver != nil {
+			c.compressionObserver.OnCompressionSkipped(&CompressionStats{
+				Header:             &header,
+				UncompressedLength: uncompressedBodyLength,
+			})
+		}
+		return &Frame{Header: &header, Body: frame.Body}, nil
+	}
+	return nil, nil
 }
 
 func (c *codec) encodeFrameUncompressed(frame *Frame, dest io.Writer) error {
@@ -46,8 +72,9 @@ func (c *codec) encodeFrameUncompressed(frame *Frame, dest io.Writer) error {
 }
 
 func (c *codec) encodeFrameCompressed(frame *Frame, dest io.Writer) error {
-	compressedBody := bytes.Buffer{}
-	if err := c.EncodeBody(frame.Header, frame.Body, &compressedBody); err != nil {
+	compressedBody := acquireEncodeBuffer()
+	defer releaseEncodeBuffer(compressedBody)
+	if err := c.EncodeBody(frame.Header, frame.Body, compressedBody); err != nil {
 		return fmt.Errorf("cannot encode frame body: %w", err)
 	} else {
 		frame.Header.BodyLength = int32(compressedBody.Len())
@@ -60,8 +87,22 @@ func (c *codec) encodeFrameCompressed(frame *Frame, dest io.Writer) error {
 	return nil
 }
 
+func (c *codec) EncodedFrameLength(frame *Frame) (int, error) {
+	if err := primitive.CheckSupportedFramingProtocolVersion(frame.Header.Version); err != nil {
+		return -1, err
+	} else if frame.Header.OpCode != frame.Body.Message.GetOpCode() {
+		return -1, fmt.Errorf("opcode mismatch between header and body: %d != %d", frame.Header.OpCode, frame.Body.Message.GetOpCode())
+	} else if bodyLength, err := c.uncompressedBodyLength(frame.Header, frame.Body); err != nil {
+		return -1, fmt.Errorf("cannot compute length of uncompressed message body: %w", err)
+	} else if frame.Header.Flags.Contains(primitive.HeaderFlagCompressed) && bodyLength >= c.compressionThreshold {
+		return -1, errors.New("cannot compute length of compressed frame before compressing its body")
+	} else {
+		return frame.Header.Version.FrameHeaderLengthInBytes() + bodyLength, nil
+	}
+}
+
 func (c *codec) EncodeRawFrame(frame *RawFrame, dest io.Writer) error {
-	if err := primitive.CheckSupportedProtocolVersion(frame.Header.Version); err != nil {
+	if err := primitive.CheckSupportedFramingProtocolVersion(frame.Header.Version); err != nil {
 		return err
 	} else {
 		frame.Header.BodyLength = int32(len(frame.Body))
@@ -76,10 +117,18 @@ func (c *codec) EncodeRawFrame(frame *RawFrame, dest io.Writer) error {
 
 func (c *codec) EncodeHeader(header *Header, dest io.Writer) error {
 	useBetaFlag := header.Flags.Contains(primitive.HeaderFlagUseBeta)
-	if err := primitive.CheckSupportedProtocolVersion(header.Version); err != nil {
+	if err := primitive.CheckSupportedFramingProtocolVersion(header.Version); err != nil {
 		return NewProtocolVersionErr(err.Error(), header.Version, useBetaFlag)
 	} else if header.Version.IsBeta() && !useBetaFlag {
 		return NewProtocolVersionErr("expected USE_BETA flag to be set", header.Version, useBetaFlag)
+	} else if err := c.checkEncodeDirection(header); err != nil {
+		return err
+	} else if err := c.checkStreamId(header); err != nil {
+		return fmt.Errorf("cannot encode header stream id: %w", err)
+	} else if err := checkOpCode(header); err != nil {
+		return fmt.Errorf("cannot encode header opcode: %w", err)
+	} else if err := c.checkBodyLength(header.BodyLength); err != nil {
+		return fmt.Errorf("cannot encode header body length: %w", err)
 	}
 
 	versionAndDirection := uint8(header.Version)
@@ -109,12 +158,26 @@ func (c *codec) EncodeBody(header *Header, body *Body, dest io.Writer) error {
 		} else if uncompressedBodyLength, err := c.uncompressedBodyLength(header, body); err != nil {
 			return fmt.Errorf("cannot compute length of uncompressed message body: %w", err)
 		} else {
-			uncompressedBody := bytes.NewBuffer(make([]byte, 0, uncompressedBodyLength))
+			uncompressedBody := acquireEncodeBuffer()
+			defer releaseEncodeBuffer(uncompressedBody)
+			uncompressedBody.Grow(uncompressedBodyLength)
 			if err = c.encodeBodyUncompressed(header, body, uncompressedBody); err != nil {
 				return fmt.Errorf("cannot encode body: %w", err)
-			} else if err := c.compressor.CompressWithLength(uncompressedBody, dest); err != nil {
+			} else if c.compressionObserver == nil {
+				if err := c.compressor.CompressWithLength(uncompressedBody, dest); err != nil {
+					return fmt.Errorf("cannot compress body: %w", err)
+				}
+				return nil
+			}
+			stats := &CompressionStats{Header: header, UncompressedLength: uncompressedBody.Len()}
+			counter := &countingWriter{Writer: dest}
+			start := time.Now()
+			if err := c.compressor.CompressWithLength(uncompressedBody, counter); err != nil {
 				return fmt.Errorf("cannot compress body: %w", err)
 			}
+			stats.Duration = time.Since(start)
+			stats.CompressedLength = counter.count
+			c.compressionObserver.OnCompressed(stats)
 			return nil
 		}
 	} else {
@@ -131,6 +194,8 @@ func (c *codec) encodeBodyUncompressed(header *Header, body *Body, dest io.Write
 	if header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
 		if header.Version < primitive.ProtocolVersion4 {
 			return fmt.Errorf("custom payloads are not supported in protocol version %v", header.Version)
+		} else if err = primitive.CheckBytesMapLimits(body.CustomPayload, c.customPayloadLimits); err != nil {
+			return fmt.Errorf("cannot encode body custom payload: %w", err)
 		} else if err = primitive.WriteBytesMap(body.CustomPayload, dest); err != nil {
 			return fmt.Errorf("cannot encode body custom payload: %w", err)
 		}
@@ -142,21 +207,35 @@ func (c *codec) encodeBodyUncompressed(header *Header, body *Body, dest io.Write
 			return fmt.Errorf("cannot encode body warnings: %w", err)
 		}
 	}
-	if encoder, err := c.findMessageCodec(body.Message.GetOpCode()); err != nil {
+	if encoder, err := c.findMessageEncoder(body.Message.GetOpCode()); err != nil {
 		return err
-	} else if err = encoder.Encode(body.Message, dest, header.Version); err != nil {
-		return fmt.Errorf("cannot encode body message: %w", err)
+	} else if c.messageObserver == nil {
+		if err = encoder.Encode(body.Message, dest, header.Version); err != nil {
+			return fmt.Errorf("cannot encode body message: %w", err)
+		}
+	} else {
+		counter := &countingWriter{Writer: dest}
+		start := time.Now()
+		if err = encoder.Encode(body.Message, counter, header.Version); err != nil {
+			return fmt.Errorf("cannot encode body message: %w", err)
+		}
+		c.messageObserver.OnMessageEncoded(&MessageStats{
+			OpCode:   body.Message.GetOpCode(),
+			Version:  header.Version,
+			Length:   counter.count,
+			Duration: time.Since(start),
+		})
 	}
 	return nil
 }
 
 func (c *codec) uncompressedBodyLength(header *Header, body *Body) (length int, err error) {
-	if encoder, err := c.findMessageCodec(body.Message.GetOpCode()); err != nil {
+	if encoder, err := c.findMessageEncoder(body.Message.GetOpCode()); err != nil {
 		return -1, err
 	} else if length, err = encoder.EncodedLength(body.Message, header.Version); err != nil {
 		return -1, fmt.Errorf("cannot compute message length: %w", err)
 	}
-	if header.Flags.Contains(primitive.HeaderFlagTracing) {
+	if header.Flags.Contains(primitive.HeaderFlagTracing) && body.Message.IsResponse() {
 		length += primitive.LengthOfUuid
 	}
 	if header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
@@ -0,0 +1,38 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"sync"
+)
+
+// encodeBufferPool holds the buffers used to encode compressed frame bodies, which cannot be streamed directly to their
+// destination since the header must contain the compressed body length.
+var encodeBufferPool = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+
+// acquireEncodeBuffer returns an empty buffer, reusing a released one if possible.
+func acquireEncodeBuffer() *bytes.Buffer {
+	return encodeBufferPool.Get().(*bytes.Buffer)
+}
+
+// releaseEncodeBuffer makes the given buffer available for encoding other frames, unless it grew beyond
+// maxPooledArenaCapacity, in which case it is left to the garbage collector.
+func releaseEncodeBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledArenaCapacity {
+		buf.Reset()
+		encodeBufferPool.Put(buf)
+	}
+}
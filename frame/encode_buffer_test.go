@@ -0,0 +1,61 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestEncodeFrame_CompressedWithPooledBuffers(t *testing.T) {
+	codec := NewCodecWithCompression(lz4.Compressor{})
+	var frames []*Frame
+	for i, query := range []string{"SELECT * FROM system.local", "SELECT * FROM system.peers WHERE peer = ?", "Q"} {
+		f := NewFrame(primitive.ProtocolVersion4, int16(i), &message.Query{Query: query, Options: &message.QueryOptions{}})
+		f.SetCompress(true)
+		frames = append(frames, f)
+	}
+	// encode all the frames in the same destination, reusing the pooled buffers
+	dest := &bytes.Buffer{}
+	for _, f := range frames {
+		require.NoError(t, codec.EncodeFrame(f, dest))
+	}
+	for _, expected := range frames {
+		decoded, err := codec.DecodeFrame(dest)
+		require.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+	}
+	assert.Equal(t, 0, dest.Len())
+}
+
+func TestReleaseEncodeBuffer(t *testing.T) {
+	buf := acquireEncodeBuffer()
+	buf.WriteString("contents")
+	releaseEncodeBuffer(buf)
+	assert.Equal(t, 0, buf.Len())
+	large := &bytes.Buffer{}
+	large.Grow(maxPooledArenaCapacity + 1)
+	large.WriteString("contents")
+	releaseEncodeBuffer(large)
+	// large buffers are not pooled, hence not reset
+	assert.Equal(t, "contents", large.String())
+}
@@ -25,6 +25,8 @@ import (
 
 // Frame is a high-level representation of a frame, where the body is fully decoded.
 // Note that frames are called "envelopes" in protocol v5 specs.
+// Use Clone to copy a frame: the copy shares nothing with the original, including its tracing id, custom payload,
+// warnings and message, see message.Clone. Interceptors that mutate frames should mutate a copy.
 // +k8s:deepcopy-gen=true
 type Frame struct {
 	Header *Header
@@ -33,10 +35,14 @@ type Frame struct {
 
 // RawFrame is a low-level representation of a frame, where the body is not decoded.
 // Note that frames are called "envelopes" in protocol v5 specs.
+// RawFrame is useful for proxies that only need to inspect frame headers and forward frames: see
+// RawDecoder.DecodeRawFrame, RawEncoder.EncodeRawFrame, and RawConverter to convert to and from a fully decoded Frame.
 // +k8s:deepcopy-gen=true
 type RawFrame struct {
 	Header *Header
-	Body   []byte
+	// Body holds the body bytes exactly as found on the wire: if the header has the Compression flag set, they are
+	// compressed.
+	Body []byte
 }
 
 // Header is the header of a frame.
@@ -44,7 +50,9 @@ type RawFrame struct {
 type Header struct {
 	IsResponse bool
 	Version    primitive.ProtocolVersion
-	Flags      primitive.HeaderFlag
+	// The header flags. When decoding a frame, this field is set to the exact flag byte found on the wire, including
+	// unknown flags and flags that the codec ignored, see IgnoredFlags; the codec never alters it afterwards.
+	Flags primitive.HeaderFlag
 	// The stream id. A stream id is a signed byte (protocol versions 1 and 2) or a signed 16-bit integer (protocol
 	// versions 3 and higher). Note that the protocol specs refer to the stream id as a primitive [short] integer,
 	// but in fact stream ids are signed integers. Indeed, server-initiated messages, such as EVENT messages, have
@@ -70,6 +78,9 @@ type Body struct {
 	Warnings []string
 	// The body message.
 	Message message.Message
+	// The bytes found after the message when decoding, if any; only set with TrailingBytesPolicyAttach. This field is
+	// ignored when encoding.
+	TrailingBytes []byte
 }
 
 // NewFrame Creates a new Frame with the given version, stream id and message.
@@ -151,6 +162,28 @@ func (f *Frame) SetCompress(compress bool) {
 	}
 }
 
+// SetUseBeta configures this frame to carry the USE_BETA flag, adjusting the header flags accordingly. The flag is
+// independent of the frame version: this allows to negotiate a protocol version that is still in beta on the remote
+// peer, e.g. protocol version 5 with older Cassandra versions, while carrying the real version number. Frames whose
+// version is a beta version must always carry the USE_BETA flag, see NewFrame: an error is returned when trying to
+// remove the flag from such frames.
+func (f *Frame) SetUseBeta(useBeta bool) error {
+	if useBeta {
+		f.Header.Flags = f.Header.Flags.Add(primitive.HeaderFlagUseBeta)
+	} else if f.Header.Version.IsBeta() {
+		return fmt.Errorf("cannot remove USE_BETA flag: %v is a beta version", f.Header.Version)
+	} else {
+		f.Header.Flags = f.Header.Flags.Remove(primitive.HeaderFlagUseBeta)
+	}
+	return nil
+}
+
+// Clone returns a deep copy of this frame: the copy shares no header, tracing id, custom payload, warnings nor message
+// with this frame. It is equivalent to DeepCopy.
+func (f *Frame) Clone() *Frame {
+	return f.DeepCopy()
+}
+
 func (f *Frame) String() string {
 	return fmt.Sprintf("{header: %v, body: %v}", f.Header, f.Body)
 }
@@ -164,6 +197,47 @@ func (h *Header) String() string {
 		h.IsResponse, h.Version, h.Flags, h.StreamId, h.OpCode, h.BodyLength)
 }
 
+// IsCompressed returns true if the compressed flag is set.
+func (h *Header) IsCompressed() bool {
+	return h.Flags.Contains(primitive.HeaderFlagCompressed)
+}
+
+// IsTracing returns true if the tracing flag is set: for requests, this means that tracing is requested; for
+// responses, that the body contains a tracing id.
+func (h *Header) IsTracing() bool {
+	return h.Flags.Contains(primitive.HeaderFlagTracing)
+}
+
+// HasCustomPayload returns true if the custom payload flag is set.
+func (h *Header) HasCustomPayload() bool {
+	return h.Flags.Contains(primitive.HeaderFlagCustomPayload)
+}
+
+// HasWarnings returns true if the warning flag is set.
+func (h *Header) HasWarnings() bool {
+	return h.Flags.Contains(primitive.HeaderFlagWarning)
+}
+
+// UsesBeta returns true if the use beta flag is set.
+func (h *Header) UsesBeta() bool {
+	return h.Flags.Contains(primitive.HeaderFlagUseBeta)
+}
+
+// UnknownFlags returns the flags that are set but not defined by the protocol specification, if any.
+func (h *Header) UnknownFlags() primitive.HeaderFlag {
+	return h.Flags &^ primitive.KnownHeaderFlags
+}
+
+// IgnoredFlags returns the flags that are set but that the codec ignores when decoding the frame body, if any: unknown
+// flags, and the warning flag on requests, since only responses carry query warnings.
+func (h *Header) IgnoredFlags() primitive.HeaderFlag {
+	ignored := h.UnknownFlags()
+	if !h.IsResponse && h.HasWarnings() {
+		ignored = ignored.Add(primitive.HeaderFlagWarning)
+	}
+	return ignored
+}
+
 func (b *Body) String() string {
 	return fmt.Sprintf("{tracing id: %v, payload: %v, warnings: %v, message: %v}",
 		b.TracingId, b.CustomPayload, b.Warnings, b.Message)
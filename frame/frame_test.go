@@ -15,9 +15,11 @@
 package frame
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/datastax/go-cassandra-native-protocol/message"
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
@@ -39,6 +41,31 @@ func TestFrame_DeepCopy(t *testing.T) {
 	assert.Equal(t, &primitive.UUID{0x02, 0x03}, cloned.Body.TracingId)
 }
 
+func TestFrame_Clone(t *testing.T) {
+	f := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{
+		Query:   "SELECT * FROM ks.t WHERE k = ?",
+		Options: &message.QueryOptions{PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1, 2})}},
+	})
+	f.SetCustomPayload(map[string][]byte{"k": {3, 4}})
+	f.SetWarnings([]string{"w"})
+
+	cloned := f.Clone()
+	assert.Equal(t, f, cloned)
+
+	// mutating the original body buffers does not affect the clone
+	f.Body.Message.(*message.Query).Options.PositionalValues[0].Contents[0] = 0xff
+	f.Body.CustomPayload["k"][0] = 0xff
+	f.Body.Warnings[0] = "mutated"
+	assert.Equal(t, []byte{1, 2}, cloned.Body.Message.(*message.Query).Options.PositionalValues[0].Contents)
+	assert.Equal(t, []byte{3, 4}, cloned.Body.CustomPayload["k"])
+	assert.Equal(t, []string{"w"}, cloned.Body.Warnings)
+
+	msg := message.Clone(cloned.Body.Message)
+	assert.Equal(t, cloned.Body.Message, msg)
+	assert.NotSame(t, cloned.Body.Message, msg)
+	assert.Nil(t, message.Clone(nil))
+}
+
 func TestRawFrame_DeepCopy(t *testing.T) {
 	f := &RawFrame{
 		Header: &Header{
@@ -176,3 +203,46 @@ func TestBody_DeepCopy_WithNils(t *testing.T) {
 	assert.Equal(t, 2, len(cloned.Warnings))
 	assert.Equal(t, "q2", cloned.Message.(*message.Query).Query)
 }
+
+func TestHeader_Flags(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	query.RequestTracingId(true)
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(query, encoded))
+	// set the warning flag, which is ignored for requests, and an unknown flag
+	encoded.Bytes()[1] |= byte(primitive.HeaderFlagWarning) | 0x40
+	decoded, err := NewCodec().DecodeFrame(encoded)
+	require.NoError(t, err)
+	header := decoded.Header
+	assert.Equal(t, primitive.HeaderFlagTracing|primitive.HeaderFlagWarning|0x40, header.Flags)
+	assert.True(t, header.IsTracing())
+	assert.True(t, header.HasWarnings())
+	assert.False(t, header.IsCompressed())
+	assert.False(t, header.HasCustomPayload())
+	assert.False(t, header.UsesBeta())
+	assert.Equal(t, primitive.HeaderFlag(0x40), header.UnknownFlags())
+	assert.Equal(t, primitive.HeaderFlagWarning|0x40, header.IgnoredFlags())
+	assert.Nil(t, decoded.Body.Warnings)
+
+	header.IsResponse = true
+	assert.Equal(t, primitive.HeaderFlag(0x40), header.IgnoredFlags())
+	assert.Equal(t, primitive.HeaderFlag(0), NewFrame(primitive.ProtocolVersion5, 1, &message.Options{}).Header.IgnoredFlags())
+	header.Flags = header.Flags.Add(primitive.HeaderFlagUseBeta)
+	assert.True(t, header.UsesBeta())
+}
+
+func TestFrame_SetUseBeta(t *testing.T) {
+	startup := NewFrame(primitive.ProtocolVersion5, 1, message.NewStartup())
+	assert.False(t, startup.Header.UsesBeta())
+	require.NoError(t, startup.SetUseBeta(true))
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(startup, encoded))
+	assert.Equal(t, byte(primitive.HeaderFlagUseBeta), encoded.Bytes()[1])
+	decoded, err := NewCodec().DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.True(t, decoded.Header.UsesBeta())
+	assert.Equal(t, primitive.ProtocolVersion5, decoded.Header.Version)
+	require.NoError(t, startup.SetUseBeta(false))
+	assert.False(t, startup.Header.UsesBeta())
+	assert.Equal(t, primitive.HeaderFlag(0), startup.Header.Flags)
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrMemoryBudgetExceeded is returned, possibly wrapped, when a frame body cannot be decoded because its length would
+// exceed the remaining capacity of the codec MemoryBudget.
+var ErrMemoryBudgetExceeded = errors.New("memory budget exceeded")
+
+// MemoryBudget caps the total length of the frame bodies being decoded concurrently by one or more codecs, e.g. all the
+// codecs of a proxy, to protect applications from running out of memory under fan-in load. It can be set on a codec
+// with RawCodec.SetMemoryBudget. Before reading a frame body, the codec reserves the body length declared in the frame
+// header from the budget, and releases it when decoding is done; if the reservation would exceed the budget limit, the
+// body is left unread and the codec returns ErrMemoryBudgetExceeded. Note that the length of compressed bodies is
+// reserved before decompression. It is preferable to create MemoryBudget instances using the constructor function
+// NewMemoryBudget. It is safe for concurrent use.
+type MemoryBudget struct {
+	limit       int64
+	outstanding int64
+}
+
+// NewMemoryBudget creates a new MemoryBudget with the given limit, in bytes; an error is returned if the limit is not
+// strictly positive.
+func NewMemoryBudget(limit int64) (*MemoryBudget, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("invalid memory budget limit: %d, must be strictly positive", limit)
+	}
+	return &MemoryBudget{limit: limit}, nil
+}
+
+// Limit returns the maximum number of bytes that can be reserved at any time.
+func (b *MemoryBudget) Limit() int64 {
+	return b.limit
+}
+
+// Outstanding returns the number of bytes currently reserved.
+func (b *MemoryBudget) Outstanding() int64 {
+	return atomic.LoadInt64(&b.outstanding)
+}
+
+// Acquire reserves the given number of bytes, or returns an error wrapping ErrMemoryBudgetExceeded if the reservation
+// would exceed the budget limit. Each successful call must be followed by a call to Release with the same length.
+func (b *MemoryBudget) Acquire(length int64) error {
+	for {
+		outstanding := atomic.LoadInt64(&b.outstanding)
+		if outstanding+length > b.limit {
+			return fmt.Errorf("%w: cannot reserve %d bytes, %d of %d bytes outstanding",
+				ErrMemoryBudgetExceeded, length, outstanding, b.limit)
+		}
+		if atomic.CompareAndSwapInt64(&b.outstanding, outstanding, outstanding+length) {
+			return nil
+		}
+	}
+}
+
+// Release releases the given number of bytes, previously reserved with Acquire.
+func (b *MemoryBudget) Release(length int64) {
+	atomic.AddInt64(&b.outstanding, -length)
+}
+
+// acquireBody checks the body length of the given header against the codec maximum body length, then reserves it from
+// the codec memory budget, if any, and returns a function to release it.
+func (c *codec) acquireBody(header *Header) (release func(), err error) {
+	if err := c.checkBodyLength(header.BodyLength); err != nil {
+		return nil, err
+	} else if c.memoryBudget == nil || header.BodyLength <= 0 {
+		return func() {}, nil
+	}
+	length := int64(header.BodyLength)
+	if err := c.memoryBudget.Acquire(length); err != nil {
+		return nil, err
+	}
+	return func() { c.memoryBudget.Release(length) }, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	budget, err := NewMemoryBudget(100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), budget.Limit())
+	require.NoError(t, budget.Acquire(60))
+	require.NoError(t, budget.Acquire(40))
+	assert.Equal(t, int64(100), budget.Outstanding())
+	err = budget.Acquire(1)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMemoryBudgetExceeded))
+	assert.Contains(t, err.Error(), "cannot reserve 1 bytes, 100 of 100 bytes outstanding")
+	budget.Release(60)
+	assert.Equal(t, int64(40), budget.Outstanding())
+	require.NoError(t, budget.Acquire(1))
+	assert.Equal(t, int64(41), budget.Outstanding())
+}
+
+func TestNewMemoryBudget_InvalidLimit(t *testing.T) {
+	for _, limit := range []int64{0, -1} {
+		budget, err := NewMemoryBudget(limit)
+		require.Error(t, err)
+		assert.Nil(t, budget)
+		assert.Contains(t, err.Error(), "invalid memory budget limit")
+	}
+}
+
+func TestCodecMemoryBudget(t *testing.T) {
+	request := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewRawCodec().EncodeFrame(request, encoded))
+	bodyLength := int64(encoded.Len() - primitive.FrameHeaderLengthV3AndHigher)
+
+	budget, err := NewMemoryBudget(bodyLength)
+	require.NoError(t, err)
+	codec := NewRawCodec()
+	codec.SetMemoryBudget(budget)
+
+	decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, request.Header, decoded.Header)
+	assert.Equal(t, "SELECT * FROM system.local", decoded.Body.Message.(*message.Query).Query)
+	rawFrame, err := codec.DecodeRawFrame(bytes.NewReader(encoded.Bytes()))
+	require.NoError(t, err)
+	assert.Len(t, rawFrame.Body, int(bodyLength))
+	assert.Equal(t, int64(0), budget.Outstanding())
+
+	// simulate a concurrent decode holding part of the budget
+	require.NoError(t, budget.Acquire(1))
+	_, err = codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMemoryBudgetExceeded))
+	_, err = codec.DecodeRawFrame(bytes.NewReader(encoded.Bytes()))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMemoryBudgetExceeded))
+	budget.Release(1)
+	assert.Equal(t, int64(0), budget.Outstanding())
+
+	// bodies without contents do not consume the budget
+	require.NoError(t, budget.Acquire(bodyLength))
+	options := NewFrame(primitive.ProtocolVersion4, 1, &message.Options{})
+	encoded.Reset()
+	require.NoError(t, codec.EncodeFrame(options, encoded))
+	decoded, err = codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, options, decoded)
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// MessageStats holds statistics about the encoding or decoding of a single message.
+type MessageStats struct {
+	OpCode  primitive.OpCode
+	Version primitive.ProtocolVersion
+	// Length is the length of the encoded message, excluding the frame header and the body prefix (tracing id, custom
+	// payload and warnings), and before compression.
+	Length int
+	// Duration is the time spent encoding or decoding the message.
+	Duration time.Duration
+}
+
+// MessageObserver is a hook that receives statistics about each message encoded or decoded by a codec, e.g. to find
+// pathological message shapes in production. It can be set on a codec with RawCodec.SetMessageObserver. Its methods
+// are invoked synchronously by the codec: implementations must be safe for concurrent use and should return quickly.
+// When no observer is set, which is the default, no statistics are gathered at all.
+type MessageObserver interface {
+
+	// OnMessageEncoded is invoked after a message was successfully encoded.
+	OnMessageEncoded(stats *MessageStats)
+
+	// OnMessageDecoded is invoked after a message was successfully decoded.
+	OnMessageDecoded(stats *MessageStats)
+}
+
+// messageSizeBuckets are the upper bounds, in bytes and in increasing order, of the buckets of the size histograms
+// aggregated by MessageMetrics.
+var messageSizeBuckets = []int{
+	64,
+	256,
+	1024,
+	4 * 1024,
+	16 * 1024,
+	64 * 1024,
+	256 * 1024,
+	1024 * 1024,
+	4 * 1024 * 1024,
+	16 * 1024 * 1024,
+}
+
+// MessageSizeBuckets returns the upper bounds, in bytes and in increasing order, of the buckets of the size histograms
+// aggregated by MessageMetrics. The returned slice is a copy: modifying it has no effect on the histograms.
+func MessageSizeBuckets() []int {
+	return append([]int(nil), messageSizeBuckets...)
+}
+
+// SizeHistogram is a distribution of message lengths, as found in MessageStats.Length.
+type SizeHistogram struct {
+	// Counts holds the number of messages per bucket: Counts[i] is the number of messages whose length is lesser than
+	// or equal to MessageSizeBuckets()[i], and greater than the previous bound, if any. Counts has one more element than
+	// the bucket bounds: the last one is the number of messages larger than the largest bound.
+	Counts []uint64
+	// Count is the total number of messages.
+	Count uint64
+	// Sum is the total length of all messages.
+	Sum uint64
+	// Max is the length of the largest message.
+	Max int
+}
+
+// Mean returns the mean message length, or 0 if the histogram is empty.
+func (h *SizeHistogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return float64(h.Sum) / float64(h.Count)
+}
+
+func (h *SizeHistogram) record(length int) {
+	if h.Counts == nil {
+		h.Counts = make([]uint64, len(messageSizeBuckets)+1)
+	}
+	h.Counts[sort.SearchInts(messageSizeBuckets, length)]++
+	h.Count++
+	h.Sum += uint64(length)
+	if length > h.Max {
+		h.Max = length
+	}
+}
+
+func (h *SizeHistogram) copy() *SizeHistogram {
+	c := *h
+	c.Counts = append([]uint64(nil), h.Counts...)
+	return &c
+}
+
+// MessageMetrics is a MessageObserver that aggregates the distribution of encoded and decoded message lengths per
+// opcode, e.g. to spot oversized batches or result pages. The zero value is ready to use. It is safe for concurrent
+// use.
+type MessageMetrics struct {
+	lock    sync.Mutex
+	encoded map[primitive.OpCode]*SizeHistogram
+	decoded map[primitive.OpCode]*SizeHistogram
+}
+
+// MessageMetricsSnapshot is a point-in-time copy of the statistics aggregated by MessageMetrics. Opcodes for which no
+// message was encoded, or decoded, are absent from the corresponding map.
+type MessageMetricsSnapshot struct {
+	// Encoded holds the distribution of encoded message lengths per opcode.
+	Encoded map[primitive.OpCode]*SizeHistogram
+	// Decoded holds the distribution of decoded message lengths per opcode.
+	Decoded map[primitive.OpCode]*SizeHistogram
+}
+
+func (m *MessageMetrics) OnMessageEncoded(stats *MessageStats) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.encoded = recordMessageLength(m.encoded, stats)
+}
+
+func (m *MessageMetrics) OnMessageDecoded(stats *MessageStats) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.decoded = recordMessageLength(m.decoded, stats)
+}
+
+func recordMessageLength(
+	histograms map[primitive.OpCode]*SizeHistogram,
+	stats *MessageStats,
+) map[primitive.OpCode]*SizeHistogram {
+	if histograms == nil {
+		histograms = map[primitive.OpCode]*SizeHistogram{}
+	}
+	histogram, found := histograms[stats.OpCode]
+	if !found {
+		histogram = &SizeHistogram{}
+		histograms[stats.OpCode] = histogram
+	}
+	histogram.record(stats.Length)
+	return histograms
+}
+
+// Snapshot returns a copy of the statistics aggregated so far.
+func (m *MessageMetrics) Snapshot() MessageMetricsSnapshot {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	snapshot := MessageMetricsSnapshot{
+		Encoded: make(map[primitive.OpCode]*SizeHistogram, len(m.encoded)),
+		Decoded: make(map[primitive.OpCode]*SizeHistogram, len(m.decoded)),
+	}
+	for opCode, histogram := range m.encoded {
+		snapshot.Encoded[opCode] = histogram.copy()
+	}
+	for opCode, histogram := range m.decoded {
+		snapshot.Decoded[opCode] = histogram.copy()
+	}
+	return snapshot
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+type messageRecorder struct {
+	encoded []*MessageStats
+	decoded []*MessageStats
+}
+
+func (r *messageRecorder) OnMessageEncoded(stats *MessageStats) {
+	r.encoded = append(r.encoded, stats)
+}
+
+func (r *messageRecorder) OnMessageDecoded(stats *MessageStats) {
+	r.decoded = append(r.decoded, stats)
+}
+
+func TestCodecMessageStats(t *testing.T) {
+	recorder := &messageRecorder{}
+	codec := NewRawCodec()
+	codec.SetMessageObserver(recorder)
+
+	query := &message.Query{Query: "SELECT * FROM system.local"}
+	request := NewFrame(primitive.ProtocolVersion4, 1, query)
+	request.SetCustomPayload(map[string][]byte{"key": {1, 2, 3}})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(request, encoded))
+	_, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+
+	// the message length excludes the custom payload
+	messageLength := int(request.Header.BodyLength) - primitive.LengthOfBytesMap(request.Body.CustomPayload)
+	require.Len(t, recorder.encoded, 1)
+	require.Len(t, recorder.decoded, 1)
+	for _, stats := range []*MessageStats{recorder.encoded[0], recorder.decoded[0]} {
+		assert.Equal(t, primitive.OpCodeQuery, stats.OpCode)
+		assert.Equal(t, primitive.ProtocolVersion4, stats.Version)
+		assert.Equal(t, messageLength, stats.Length)
+		assert.GreaterOrEqual(t, int64(stats.Duration), int64(0))
+	}
+}
+
+func TestMessageMetrics(t *testing.T) {
+	metrics := &MessageMetrics{}
+	codec := NewRawCodec()
+	codec.SetMessageObserver(metrics)
+
+	small := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	large := NewFrame(primitive.ProtocolVersion4, 2, &message.Query{Query: string(make([]byte, 2000))})
+	for _, request := range []*Frame{small, small, large} {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(request, encoded))
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(NewFrame(primitive.ProtocolVersion4, 3, &message.Options{}), encoded))
+	_, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+
+	snapshot := metrics.Snapshot()
+	require.Len(t, snapshot.Encoded, 2)
+	query := snapshot.Encoded[primitive.OpCodeQuery]
+	require.NotNil(t, query)
+	require.Len(t, query.Counts, len(MessageSizeBuckets())+1)
+	// the bucket bounds cannot be altered
+	buckets := MessageSizeBuckets()
+	buckets[0] = 1 << 30
+	assert.Equal(t, 64, MessageSizeBuckets()[0])
+	assert.Equal(t, uint64(3), query.Count)
+	assert.Equal(t, uint64(2), query.Counts[0])
+	assert.Equal(t, uint64(1), query.Counts[3])
+	assert.Greater(t, query.Max, 2000)
+	assert.InDelta(t, float64(query.Sum)/3, query.Mean(), 0.001)
+	options := snapshot.Encoded[primitive.OpCodeOptions]
+	require.NotNil(t, options)
+	assert.Equal(t, uint64(1), options.Counts[0])
+	assert.Equal(t, 0, options.Max)
+	require.Len(t, snapshot.Decoded, 1)
+	assert.Equal(t, uint64(1), snapshot.Decoded[primitive.OpCodeOptions].Count)
+
+	// snapshots are copies
+	query.Counts[0] = 0
+	assert.Equal(t, uint64(2), metrics.Snapshot().Encoded[primitive.OpCodeQuery].Counts[0])
+	assert.Equal(t, float64(0), (&SizeHistogram{}).Mean())
+}
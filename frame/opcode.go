@@ -0,0 +1,39 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// bodyPrefixFlags are the header flags that cause a frame body to carry more than just its message.
+const bodyPrefixFlags = primitive.HeaderFlagCompressed |
+	primitive.HeaderFlagTracing |
+	primitive.HeaderFlagCustomPayload |
+	primitive.HeaderFlagWarning
+
+// checkOpCode checks the opcode of the given header against the opcode legality matrix: the opcode must exist in the
+// header protocol version and, if its messages never carry a body, the header must declare an empty body, unless its
+// flags indicate that the body carries something else than the message.
+func checkOpCode(header *Header) error {
+	if err := primitive.CheckSupportedOpCode(header.OpCode, header.Version); err != nil {
+		return err
+	} else if header.OpCode.IsBodyless() && header.BodyLength != 0 && header.Flags&bodyPrefixFlags == 0 {
+		return fmt.Errorf("%v must not carry a body, got body length: %v", header.OpCode, header.BodyLength)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// expectedOpCodeLegality is the opcode legality matrix as stated by the protocol specifications, independently of its
+// implementation in the primitive package. Opcodes unknown to this library are legal in all versions, since they may
+// belong to custom message codecs.
+func expectedOpCodeLegality(opCode primitive.OpCode, version primitive.ProtocolVersion) bool {
+	switch opCode {
+	case primitive.OpCodeCredentials:
+		return version == primitive.ProtocolVersion2
+	case primitive.OpCodeDseRevise:
+		return version.IsDse()
+	}
+	return true
+}
+
+// TestCodecOpCodeLegality generates a header for every combination of protocol version, opcode and direction, and
+// checks that the codec accepts or rejects it according to the opcode legality matrix.
+func TestCodecOpCodeLegality(t *testing.T) {
+	codec := NewRawCodec()
+	for _, version := range primitive.SupportedProtocolVersions() {
+		for code := 0; code <= 0xFF; code++ {
+			opCode := primitive.OpCode(code)
+			for _, isResponse := range []bool{false, true} {
+				header := &Header{IsResponse: isResponse, Version: version, StreamId: 1, OpCode: opCode}
+				if opCode == primitive.OpCodeEvent {
+					header.StreamId = -1
+				}
+				if version.IsBeta() {
+					header.Flags = header.Flags.Add(primitive.HeaderFlagUseBeta)
+				}
+				// the direction of the opcode is only checked when decoding
+				legal := expectedOpCodeLegality(opCode, version)
+				directionLegal := isResponse && opCode.IsResponse() || !isResponse && opCode.IsRequest()
+				encoded := &bytes.Buffer{}
+				err := codec.EncodeHeader(header, encoded)
+				if legal {
+					require.NoError(t, err, "%v %v response=%v", version, opCode, isResponse)
+				} else {
+					require.Error(t, err, "%v %v response=%v", version, opCode, isResponse)
+				}
+				// encode the header by hand to check decoding as well
+				encoded.Reset()
+				versionAndDirection := uint8(version)
+				if isResponse {
+					versionAndDirection |= 0b1000_0000
+				}
+				require.NoError(t, primitive.WriteByte(versionAndDirection, encoded))
+				require.NoError(t, primitive.WriteByte(uint8(header.Flags), encoded))
+				require.NoError(t, primitive.WriteStreamId(header.StreamId, encoded, version))
+				require.NoError(t, primitive.WriteByte(uint8(opCode), encoded))
+				require.NoError(t, primitive.WriteInt(0, encoded))
+				decoded, err := codec.DecodeHeader(encoded)
+				if legal && directionLegal {
+					require.NoError(t, err, "%v %v response=%v", version, opCode, isResponse)
+					assert.Equal(t, header, decoded)
+				} else {
+					require.Error(t, err, "%v %v response=%v", version, opCode, isResponse)
+				}
+			}
+		}
+	}
+}
+
+func TestCodecBodylessOpCodes(t *testing.T) {
+	codec := NewRawCodec()
+	for _, opCode := range []primitive.OpCode{primitive.OpCodeReady, primitive.OpCodeOptions} {
+		header := &Header{
+			IsResponse: opCode.IsResponse(),
+			Version:    primitive.ProtocolVersion4,
+			StreamId:   1,
+			OpCode:     opCode,
+			BodyLength: 4,
+		}
+		err := codec.EncodeHeader(header, &bytes.Buffer{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not carry a body, got body length: 4")
+		header.Flags = header.Flags.Add(primitive.HeaderFlagCustomPayload)
+		assert.NoError(t, codec.EncodeHeader(header, &bytes.Buffer{}))
+	}
+}
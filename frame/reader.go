@@ -0,0 +1,141 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrReaderClosed is returned by Reader.Next when the reader was closed.
+var ErrReaderClosed = errors.New("reader closed")
+
+// Reader reads frames ahead from a source, typically a connection, on behalf of a consumer. Frame headers are decoded
+// eagerly, but frame bodies are kept undecoded: frames are handed to the consumer as RawFrame instances, leaving it
+// to the consumer to decode the bodies when needed, e.g. with RawConverter.ConvertFromRawFrame.
+//
+// The total size of the bodies read ahead but not yet consumed is capped: when the cap is reached, the reader stops
+// reading from the source until the consumer catches up. This bounds the memory used per connection when consumers
+// are slow. A single frame whose body is larger than the cap is still read, but only when no other frame is pending.
+type Reader struct {
+	source           io.Reader
+	decoder          RawDecoder
+	maxBufferedBytes int
+
+	lock     *sync.Mutex
+	cond     *sync.Cond
+	pending  []*RawFrame
+	buffered int
+	err      error
+	closed   bool
+}
+
+// NewReader creates a new Reader and starts reading frames from the given source in the background, until the source
+// returns an error or the reader is closed. The parameter maxBufferedBytes is the maximum total size of frame bodies
+// read ahead and not yet consumed; an error is returned if it is not strictly positive.
+func NewReader(source io.Reader, decoder RawDecoder, maxBufferedBytes int) (*Reader, error) {
+	if maxBufferedBytes < 1 {
+		return nil, fmt.Errorf("invalid max buffered bytes: %d, must be strictly positive", maxBufferedBytes)
+	}
+	lock := &sync.Mutex{}
+	r := &Reader{
+		source:           source,
+		decoder:          decoder,
+		maxBufferedBytes: maxBufferedBytes,
+		lock:             lock,
+		cond:             sync.NewCond(lock),
+	}
+	go r.readLoop()
+	return r, nil
+}
+
+// Next returns the next frame read from the source, blocking until one is available. When the source is exhausted,
+// Next returns the error that stopped the reader, e.g. io.EOF, once all the frames read so far have been consumed. If
+// the reader was closed, Next returns ErrReaderClosed.
+func (r *Reader) Next() (*RawFrame, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for len(r.pending) == 0 && r.err == nil && !r.closed {
+		r.cond.Wait()
+	}
+	if r.closed {
+		return nil, ErrReaderClosed
+	} else if len(r.pending) == 0 {
+		return nil, r.err
+	}
+	frame := r.pending[0]
+	r.pending[0] = nil
+	r.pending = r.pending[1:]
+	r.buffered -= len(frame.Body)
+	r.cond.Broadcast()
+	return frame, nil
+}
+
+// Buffered returns the total size of the frame bodies read ahead and not yet consumed.
+func (r *Reader) Buffered() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.buffered
+}
+
+// Close closes this reader and discards all pending frames. Note that a background read that is blocked on the
+// source cannot be interrupted; closing the source itself is required for that.
+func (r *Reader) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.closed = true
+	r.pending = nil
+	r.buffered = 0
+	r.cond.Broadcast()
+}
+
+func (r *Reader) readLoop() {
+	for {
+		header, err := r.decoder.DecodeHeader(r.source)
+		if err == nil && !r.awaitCapacity(int(header.BodyLength)) {
+			return
+		}
+		var body []byte
+		if err == nil {
+			body, err = r.decoder.DecodeRawBody(header, r.source)
+		}
+		r.lock.Lock()
+		if r.closed {
+			r.lock.Unlock()
+			return
+		} else if err != nil {
+			r.err = err
+			r.cond.Broadcast()
+			r.lock.Unlock()
+			return
+		}
+		r.pending = append(r.pending, &RawFrame{Header: header, Body: body})
+		r.buffered += len(body)
+		r.cond.Broadcast()
+		r.lock.Unlock()
+	}
+}
+
+// awaitCapacity blocks until there is room for a body of the given length, and returns false if the reader was closed.
+func (r *Reader) awaitCapacity(bodyLength int) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for !r.closed && r.buffered > 0 && r.buffered+bodyLength > r.maxBufferedBytes {
+		r.cond.Wait()
+	}
+	return !r.closed
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestReader(t *testing.T) {
+	codec := NewRawCodec()
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	encodedQuery := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(query, encodedQuery))
+	bodyLength := encodedQuery.Len() - primitive.FrameHeaderLengthV3AndHigher
+	expected, err := codec.DecodeFrame(bytes.NewReader(encodedQuery.Bytes()))
+	require.NoError(t, err)
+
+	source, sink := io.Pipe()
+	// room for 2 bodies
+	reader, err := NewReader(source, codec, bodyLength*2)
+	require.NoError(t, err)
+	defer reader.Close()
+	written := make(chan int, 4)
+	go func() {
+		for i := 0; i < 4; i++ {
+			_, err := sink.Write(encodedQuery.Bytes())
+			if err != nil {
+				return
+			}
+			written <- i
+		}
+		_ = sink.Close()
+	}()
+
+	// the first 2 frames are buffered; the third one is blocked after its header was decoded
+	for i := 0; i < 2; i++ {
+		<-written
+	}
+	require.Eventually(t, func() bool { return reader.Buffered() == bodyLength*2 }, time.Second, time.Millisecond)
+	select {
+	case <-written:
+		t.Fatal("reader should not read ahead more than allowed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for i := 0; i < 4; i++ {
+		rawFrame, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, expected.Header, rawFrame.Header)
+		decoded, err := codec.ConvertFromRawFrame(rawFrame)
+		require.NoError(t, err)
+		assert.Equal(t, expected, decoded)
+	}
+	_, err = reader.Next()
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, 0, reader.Buffered())
+}
+
+func TestReader_Close(t *testing.T) {
+	source, sink := io.Pipe()
+	defer sink.Close()
+	reader, err := NewReader(source, NewRawCodec(), 1024)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := reader.Next()
+		done <- err
+	}()
+	reader.Close()
+	select {
+	case err := <-done:
+		assert.Equal(t, ErrReaderClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("Next should return when the reader is closed")
+	}
+}
+
+func TestNewReader_InvalidMaxBufferedBytes(t *testing.T) {
+	for _, maxBufferedBytes := range []int{0, -1} {
+		reader, err := NewReader(&bytes.Buffer{}, NewRawCodec(), maxBufferedBytes)
+		require.Error(t, err)
+		assert.Nil(t, reader)
+		assert.Contains(t, err.Error(), "invalid max buffered bytes")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// NewResponse creates a response frame for the given request frame, with the same protocol version and stream id as
+// the request, and the given message as body.
+func NewResponse(request *Frame, response message.Message) *Frame {
+	return NewFrame(request.Header.Version, request.Header.StreamId, response)
+}
+
+// NewReadyResponse creates a READY response frame for the given request frame, typically a STARTUP request.
+func NewReadyResponse(request *Frame) *Frame {
+	return NewResponse(request, &message.Ready{})
+}
+
+// NewAuthenticateResponse creates an AUTHENTICATE response frame for the given request frame, typically a STARTUP
+// request, with the given authenticator class name, e.g. "org.apache.cassandra.auth.PasswordAuthenticator".
+func NewAuthenticateResponse(request *Frame, authenticator string) *Frame {
+	return NewResponse(request, &message.Authenticate{Authenticator: authenticator})
+}
+
+// NewSupportedResponse creates a SUPPORTED response frame for the given request frame, typically an OPTIONS request.
+// The response advertises the CQL_VERSION and PROTOCOL_VERSIONS options, as sent by Cassandra: the former is set to
+// "3.0.0", the latter to all the non-DSE protocol versions supported by this library. The given options are added to
+// these defaults, and replace them if they have the same key; compression algorithms, in particular, are not
+// advertised unless the COMPRESSION option is given.
+func NewSupportedResponse(request *Frame, options map[string][]string) *Frame {
+	supported := &message.Supported{Options: map[string][]string{
+		message.StartupOptionCqlVersion:   {"3.0.0"},
+		message.SupportedProtocolVersions: supportedProtocolVersions(),
+	}}
+	for key, values := range options {
+		supported.Options[key] = values
+	}
+	return NewResponse(request, supported)
+}
+
+func supportedProtocolVersions() []string {
+	var versions []string
+	for _, version := range primitive.SupportedProtocolVersions() {
+		if !version.IsDse() {
+			description := fmt.Sprintf("%d/v%d", version, version)
+			if version.IsBeta() {
+				description += "-beta"
+			}
+			versions = append(versions, description)
+		}
+	}
+	return versions
+}
+
+// NewErrorResponse creates an ERROR response frame for the given request frame, with the given error code and
+// message. The error message type is the one matching the error code, see message.NewError: fields specific to the
+// error code, if any, are left to their zero values. To set them, create the error message explicitly and use
+// NewResponse instead.
+func NewErrorResponse(request *Frame, code primitive.ErrorCode, errorMessage string) *Frame {
+	return NewResponse(request, message.NewError(code, errorMessage))
+}
+
+// NewVoidResponse creates a RESULT Void response frame for the given request frame, typically a QUERY, EXECUTE or
+// BATCH request.
+func NewVoidResponse(request *Frame) *Frame {
+	return NewResponse(request, &message.VoidResult{})
+}
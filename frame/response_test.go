@@ -0,0 +1,66 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewResponse(t *testing.T) {
+	request := NewFrame(primitive.ProtocolVersion5, 42, &message.Options{})
+	codec := NewServerCodec()
+
+	supported := NewSupportedResponse(request, map[string][]string{
+		message.StartupOptionCompression: {"lz4"},
+		message.StartupOptionCqlVersion:  {"3.4.5"},
+	})
+	assert.True(t, supported.Header.IsResponse)
+	assert.Equal(t, primitive.ProtocolVersion5, supported.Header.Version)
+	assert.Equal(t, int16(42), supported.Header.StreamId)
+	assert.Equal(t, map[string][]string{
+		message.StartupOptionCqlVersion:   {"3.4.5"},
+		message.StartupOptionCompression:  {"lz4"},
+		message.SupportedProtocolVersions: {"2/v2", "3/v3", "4/v4", "5/v5"},
+	}, supported.Body.Message.(*message.Supported).Options)
+	versions, beta, ok := supported.Body.Message.(*message.Supported).ProtocolVersions()
+	assert.True(t, ok)
+	assert.Empty(t, beta)
+	assert.Contains(t, versions, primitive.ProtocolVersion5)
+
+	errorResponse := NewErrorResponse(request, primitive.ErrorCodeInvalid, "invalid query")
+	assert.Equal(t, &message.Invalid{ErrorMessage: "invalid query"}, errorResponse.Body.Message)
+
+	for _, response := range []*Frame{
+		supported,
+		errorResponse,
+		NewReadyResponse(request),
+		NewAuthenticateResponse(request, "org.apache.cassandra.auth.PasswordAuthenticator"),
+		NewVoidResponse(request),
+	} {
+		assert.Equal(t, request.Header.StreamId, response.Header.StreamId)
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.EncodeFrame(response, encoded))
+		decoded, err := NewClientCodec().DecodeFrame(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, response.Body.Message, decoded.Body.Message)
+	}
+}
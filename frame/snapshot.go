@@ -0,0 +1,215 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const snapshotIndent = "  "
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// Snapshot returns a canonical JSON rendering of the given decoded frame, intended for golden-file snapshot testing of
+// codecs and of tools built on top of them. The rendering is deterministic:
+//  - struct fields are rendered in declaration order;
+//  - map entries are sorted by key;
+//  - byte slices and arrays are rendered as hex strings, e.g. "0xcafe";
+//  - values whose type implements fmt.Stringer, such as enums, UUIDs and IP addresses, are rendered with their String
+//    method;
+//  - values held in interfaces, such as the frame message, are rendered along with their type, under the "@type" key.
+func Snapshot(f *Frame) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeSnapshot(buf, reflect.ValueOf(f), ""); err != nil {
+		return nil, fmt.Errorf("cannot snapshot frame: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeSnapshot(buf *bytes.Buffer, v reflect.Value, indent string) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+	}
+	if s, ok := snapshotString(v); ok {
+		return writeJson(buf, s)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return writeSnapshot(buf, v.Elem(), indent)
+	case reflect.Interface:
+		return writeSnapshotObject(buf, indent, v.Elem().Type().String(), v.Elem())
+	case reflect.Struct:
+		return writeSnapshotObject(buf, indent, "", v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return writeJson(buf, fmt.Sprintf("0x%x", bytesOf(v)))
+		}
+		if v.Len() == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + snapshotIndent)
+			if err := writeSnapshot(buf, v.Index(i), indent+snapshotIndent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+		return nil
+	case reflect.Map:
+		keys := sortedMapKeys(v, v)
+		if len(keys) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			name, _ := snapshotString(key)
+			if key.Kind() == reflect.String {
+				name = key.String()
+			}
+			if err := writeSnapshotField(buf, indent, name, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+		return nil
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return writeJson(buf, v.Interface())
+	}
+	return fmt.Errorf("unsupported kind: %v", v.Kind())
+}
+
+// writeSnapshotObject writes the exported fields of the given struct value as a JSON object. If typeName is not empty,
+// it is written first under the "@type" key.
+func writeSnapshotObject(buf *bytes.Buffer, indent string, typeName string, v reflect.Value) error {
+	buf.WriteString("{")
+	first := true
+	if typeName != "" {
+		buf.WriteString("\n" + indent + snapshotIndent + `"@type": `)
+		if err := writeJson(buf, typeName); err != nil {
+			return err
+		}
+		first = false
+	}
+	if s, ok := snapshotString(v); ok {
+		// e.g. an opaque struct held in an interface
+		v = reflect.ValueOf(s)
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		// e.g. a named slice or scalar held in an interface
+		if !first {
+			buf.WriteString(",")
+		}
+		if err := writeSnapshotField(buf, indent, "@value", v); err != nil {
+			return err
+		}
+		buf.WriteString("\n" + indent + "}")
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Type().Field(i); field.PkgPath == "" {
+			if !first {
+				buf.WriteString(",")
+			}
+			first = false
+			if err := writeSnapshotField(buf, indent, field.Name, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	if first {
+		buf.WriteString("}")
+	} else {
+		buf.WriteString("\n" + indent + "}")
+	}
+	return nil
+}
+
+func writeSnapshotField(buf *bytes.Buffer, indent string, name string, v reflect.Value) error {
+	buf.WriteString("\n" + indent + snapshotIndent)
+	if err := writeJson(buf, name); err != nil {
+		return err
+	}
+	buf.WriteString(": ")
+	return writeSnapshot(buf, v, indent+snapshotIndent)
+}
+
+// snapshotString returns the result of the String method of the given value, if its type implements fmt.Stringer and
+// is not a struct with exported fields, nor a pointer to such a struct: those are better rendered field by field.
+func snapshotString(v reflect.Value) (string, bool) {
+	if !v.CanInterface() {
+		return "", false
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Interface {
+		return "", false
+	}
+	elem := t
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() == reflect.Struct {
+		for i := 0; i < elem.NumField(); i++ {
+			if elem.Field(i).PkgPath == "" {
+				return "", false
+			}
+		}
+	}
+	if t.Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String(), true
+	} else if v.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(stringerType) {
+		ptr := reflect.New(t)
+		ptr.Elem().Set(v)
+		return ptr.Interface().(fmt.Stringer).String(), true
+	}
+	return "", false
+}
+
+func writeJson(buf *bytes.Buffer, v interface{}) error {
+	encoded := &bytes.Buffer{}
+	encoder := json.NewEncoder(encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
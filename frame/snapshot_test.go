@@ -0,0 +1,93 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestSnapshot(t *testing.T) {
+	f := NewFrame(primitive.ProtocolVersion4, -1, &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    &primitive.Inet{Addr: net.IPv4(192, 168, 1, 1), Port: 9042},
+	})
+	f.SetTracingId(&primitive.UUID{0xca, 0xfe, 0xba, 0xbe})
+	f.SetWarnings([]string{"<warning>"})
+	f.SetCustomPayload(map[string][]byte{"b": {0xca, 0xfe}, "a": {}})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(f, encoded))
+	decoded, err := NewCodec().DecodeFrame(encoded)
+	require.NoError(t, err)
+	snapshot, err := Snapshot(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "Header": {
+    "IsResponse": true,
+    "Version": "ProtocolVersion OSS 4",
+    "Flags": "HeaderFlag ? [0X0E 0b00001110]",
+    "StreamId": -1,
+    "OpCode": "OpCode EVENT [0x0C]",
+    "BodyLength": 75
+  },
+  "Body": {
+    "TracingId": "cafebabe-0000-0000-0000-000000000000",
+    "CustomPayload": {
+      "a": "0x",
+      "b": "0xcafe"
+    },
+    "Warnings": [
+      "<warning>"
+    ],
+    "Message": {
+      "@type": "*message.StatusChangeEvent",
+      "ChangeType": "UP",
+      "Address": {
+        "Addr": "192.168.1.1",
+        "Port": 9042
+      }
+    },
+    "TrailingBytes": null
+  }
+}
+`, string(snapshot))
+}
+
+func TestSnapshot_Deterministic(t *testing.T) {
+	newFrame := func(keys ...string) *Frame {
+		f := NewFrame(primitive.ProtocolVersion4, 1, message.NewStartup())
+		payload := map[string][]byte{}
+		for i, key := range keys {
+			payload[key] = []byte{byte(i)}
+			f.Body.Message.(*message.Startup).Options[key] = key
+		}
+		f.SetCustomPayload(payload)
+		return f
+	}
+	expected, err := Snapshot(newFrame("k1", "k2", "k3", "k4", "k5"))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		actual, err := Snapshot(newFrame("k1", "k2", "k3", "k4", "k5"))
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+}
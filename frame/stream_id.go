@@ -0,0 +1,85 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ErrInvalidStreamId is wrapped by the errors returned when encoding or decoding a frame header whose stream id is not
+// valid, see RawCodec.SetStreamIdValidation.
+var ErrInvalidStreamId = errors.New("invalid stream id")
+
+// checkStreamId checks that the stream id of the given header fits the stream id range of its protocol version and,
+// if stream id validation is enabled, that it honors the protocol specification: requests must use non-negative
+// stream ids; responses to requests must use the non-negative stream id of the request; and server-initiated EVENT
+// messages must use stream id -1.
+func (c *codec) checkStreamId(header *Header) error {
+	if err := primitive.CheckValidStreamId(header.StreamId, header.Version); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStreamId, err)
+	} else if !c.validateStreamIds {
+		return nil
+	} else if !header.IsResponse && header.StreamId < 0 {
+		return fmt.Errorf("%w: request stream id must not be negative, got: %v", ErrInvalidStreamId, header.StreamId)
+	} else if header.IsResponse && header.OpCode == primitive.OpCodeEvent && header.StreamId != -1 {
+		return fmt.Errorf("%w: %v stream id must be -1, got: %v", ErrInvalidStreamId, header.OpCode, header.StreamId)
+	} else if header.IsResponse && header.OpCode != primitive.OpCodeEvent && header.StreamId < 0 {
+		return fmt.Errorf("%w: response stream id must not be negative, got: %v", ErrInvalidStreamId, header.StreamId)
+	}
+	return nil
+}
+
+// RewriteStreamId patches the stream id and the direction bit of the given encoded frame in place, without decoding
+// nor re-encoding its body. This is useful for proxies multiplexing client streams onto upstream connections: a
+// request can be forwarded upstream with a different stream id, and the response can be sent back to the client with
+// the original stream id. The encoded frame must start with a frame header; the stream id must fit the stream id range
+// of the protocol version found in that header. Compressed frames can be rewritten as well, since the frame header is
+// never compressed.
+func RewriteStreamId(encodedFrame []byte, streamId int16, isResponse bool) error {
+	if len(encodedFrame) == 0 {
+		return fmt.Errorf("cannot rewrite stream id: empty frame")
+	}
+	version := primitive.ProtocolVersion(encodedFrame[0] & 0b0111_1111)
+	if headerLength := encodedHeaderLength(encodedFrame); len(encodedFrame) < headerLength {
+		return fmt.Errorf("cannot rewrite stream id: expecting at least %d bytes, got: %d", headerLength, len(encodedFrame))
+	} else if err := primitive.CheckValidStreamId(streamId, version); err != nil {
+		return fmt.Errorf("cannot rewrite stream id: %w: %v", ErrInvalidStreamId, err)
+	}
+	encodedFrame[0] = uint8(version)
+	if isResponse {
+		encodedFrame[0] |= 0b1000_0000
+	}
+	if version >= primitive.ProtocolVersion3 {
+		binary.BigEndian.PutUint16(encodedFrame[2:], uint16(streamId))
+	} else {
+		encodedFrame[2] = uint8(streamId)
+	}
+	return nil
+}
+
+// RewriteStreamId sets the stream id and the direction of this raw frame, without altering its body. It is the
+// counterpart of the RewriteStreamId function for raw frames.
+func (f *RawFrame) RewriteStreamId(streamId int16, isResponse bool) error {
+	if err := primitive.CheckValidStreamId(streamId, f.Header.Version); err != nil {
+		return fmt.Errorf("cannot rewrite stream id: %w: %v", ErrInvalidStreamId, err)
+	}
+	f.Header.StreamId = streamId
+	f.Header.IsResponse = isResponse
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecStreamIdValidation(t *testing.T) {
+	event := &message.StatusChangeEvent{
+		ChangeType: primitive.StatusChangeTypeUp,
+		Address:    &primitive.Inet{Addr: []byte{127, 0, 0, 1}, Port: 9042},
+	}
+	tests := []struct {
+		name  string
+		frame *Frame
+		valid bool
+	}{
+		{"request", NewFrame(primitive.ProtocolVersion4, 1, &message.Options{}), true},
+		{"negative request", NewFrame(primitive.ProtocolVersion4, -1, &message.Options{}), false},
+		{"response", NewFrame(primitive.ProtocolVersion4, 1, &message.Ready{}), true},
+		{"negative response", NewFrame(primitive.ProtocolVersion4, -2, &message.Ready{}), false},
+		{"event", NewFrame(primitive.ProtocolVersion4, -1, event), true},
+		{"event with request stream id", NewFrame(primitive.ProtocolVersion4, 1, event), false},
+		{"v2 event", NewFrame(primitive.ProtocolVersion2, -1, event), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lenient := NewRawCodec()
+			encoded := &bytes.Buffer{}
+			require.NoError(t, lenient.EncodeFrame(tt.frame, encoded))
+			decoded, err := lenient.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, tt.frame.Header.StreamId, decoded.Header.StreamId)
+
+			strict := NewRawCodec()
+			strict.SetStreamIdValidation(true)
+			encodeErr := strict.EncodeFrame(tt.frame, &bytes.Buffer{})
+			_, decodeErr := strict.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			if tt.valid {
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+			} else {
+				assert.True(t, errors.Is(encodeErr, ErrInvalidStreamId), encodeErr)
+				assert.True(t, errors.Is(decodeErr, ErrInvalidStreamId), decodeErr)
+			}
+		})
+	}
+}
+
+func TestCodecStreamIdRange(t *testing.T) {
+	err := NewCodec().EncodeFrame(NewFrame(primitive.ProtocolVersion2, 128, &message.Options{}), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidStreamId), err)
+	assert.Contains(t, err.Error(), "cannot encode header stream id: invalid stream id: stream id out of range for ProtocolVersion OSS 2: 128")
+}
+
+func TestRewriteStreamId(t *testing.T) {
+	codec := NewRawCodec()
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion2, primitive.ProtocolVersion4} {
+		t.Run(version.String(), func(t *testing.T) {
+			request := NewFrame(version, 1, &message.Query{Query: "SELECT * FROM system.local"})
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(request, encoded))
+			require.NoError(t, RewriteStreamId(encoded.Bytes(), 42, false))
+			decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, int16(42), decoded.Header.StreamId)
+			assert.False(t, decoded.Header.IsResponse)
+			assert.Equal(t, "SELECT * FROM system.local", decoded.Body.Message.(*message.Query).Query)
+
+			require.NoError(t, RewriteStreamId(encoded.Bytes(), 42, true))
+			assert.Equal(t, uint8(version)|0b1000_0000, encoded.Bytes()[0])
+
+			err = RewriteStreamId(encoded.Bytes(), 300, false)
+			if version >= primitive.ProtocolVersion3 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrInvalidStreamId), err)
+			}
+		})
+	}
+
+	err := RewriteStreamId([]byte{0x04, 0x00, 0x00}, 1, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expecting at least 9 bytes, got: 3")
+	require.Error(t, RewriteStreamId(nil, 1, false))
+
+	response := NewFrame(primitive.ProtocolVersion4, 42, &message.Ready{})
+	response.Header.IsResponse = true
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.EncodeFrame(response, encoded))
+	rawFrame, err := codec.DecodeRawFrame(encoded)
+	require.NoError(t, err)
+	body := rawFrame.Body
+	require.NoError(t, rawFrame.RewriteStreamId(1, true))
+	assert.Equal(t, int16(1), rawFrame.Header.StreamId)
+	assert.Equal(t, body, rawFrame.Body)
+	require.NoError(t, codec.EncodeRawFrame(rawFrame, encoded))
+	decoded, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, int16(1), decoded.Header.StreamId)
+	assert.IsType(t, &message.Ready{}, decoded.Body.Message)
+
+	rawFrame.Header.Version = primitive.ProtocolVersion2
+	err = rawFrame.RewriteStreamId(300, true)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidStreamId), err)
+	assert.Equal(t, int16(1), rawFrame.Header.StreamId)
+}
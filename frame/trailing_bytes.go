@@ -0,0 +1,92 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// ErrTrailingBytes is wrapped by the errors returned when decoding a frame body containing unread bytes after the
+// message, with TrailingBytesPolicyFail.
+var ErrTrailingBytes = errors.New("trailing bytes after message")
+
+// TrailingBytesPolicy determines how a codec reacts when unread bytes remain in a frame body after its message was
+// decoded. Such bytes are usually the sign of a codec bug, or of a peer that does not conform to the protocol
+// specification.
+type TrailingBytesPolicy int
+
+const (
+	// TrailingBytesPolicyIgnore is the default policy: trailing bytes are left unread, unless a TrailingBytesHandler is
+	// configured, in which case the handler is notified of them.
+	TrailingBytesPolicyIgnore = TrailingBytesPolicy(iota)
+	// TrailingBytesPolicyFail makes decoding fail with an error wrapping ErrTrailingBytes.
+	TrailingBytesPolicyFail
+	// TrailingBytesPolicyDiscard discards the trailing bytes; combined with a TrailingBytesHandler, this can be used
+	// to log them.
+	TrailingBytesPolicyDiscard
+	// TrailingBytesPolicyAttach attaches the trailing bytes to the decoded body, see Body.TrailingBytes.
+	TrailingBytesPolicyAttach
+)
+
+func (p TrailingBytesPolicy) String() string {
+	switch p {
+	case TrailingBytesPolicyIgnore:
+		return "TrailingBytesPolicy IGNORE"
+	case TrailingBytesPolicyFail:
+		return "TrailingBytesPolicy FAIL"
+	case TrailingBytesPolicyDiscard:
+		return "TrailingBytesPolicy DISCARD"
+	case TrailingBytesPolicyAttach:
+		return "TrailingBytesPolicy ATTACH"
+	}
+	return fmt.Sprintf("TrailingBytesPolicy ? [%d]", int(p))
+}
+
+// TrailingBytesHandler is a callback function that gets invoked whenever unread bytes remain in a frame body after its
+// message was decoded, before the TrailingBytesPolicy is applied. The body passed to the function contains the decoded
+// message.
+type TrailingBytesHandler func(header *Header, body *Body, trailing []byte)
+
+// checkTrailingBytes reads the bytes remaining in the given message source, which must be bounded to the frame body,
+// notifies the configured TrailingBytesHandler, if any, then applies the configured TrailingBytesPolicy.
+func (c *codec) checkTrailingBytes(header *Header, body *Body, source io.Reader) error {
+	if c.trailingBytesPolicy == TrailingBytesPolicyIgnore && c.onTrailingBytes == nil {
+		return nil
+	}
+	var trailing []byte
+	if buf, ok := source.(*bytes.Buffer); ok {
+		trailing = buf.Next(buf.Len())
+	} else if remaining, err := ioutil.ReadAll(source); err != nil {
+		return fmt.Errorf("cannot read trailing bytes: %w", err)
+	} else {
+		trailing = remaining
+	}
+	if len(trailing) == 0 {
+		return nil
+	} else if c.onTrailingBytes != nil {
+		c.onTrailingBytes(header, body, trailing)
+	}
+	switch c.trailingBytesPolicy {
+	case TrailingBytesPolicyFail:
+		return fmt.Errorf("%w: %d bytes (declared body length: %d)", ErrTrailingBytes, len(trailing), header.BodyLength)
+	case TrailingBytesPolicyAttach:
+		body.TrailingBytes = trailing
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecTrailingBytesPolicy(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(query, encoded))
+	bodyLength := encoded.Len() - primitive.FrameHeaderLengthV3AndHigher
+	binary.BigEndian.PutUint32(encoded.Bytes()[5:], uint32(bodyLength+2))
+	encoded.Write([]byte{0xca, 0xfe})
+	// a second frame, to check that the trailing bytes are consumed
+	require.NoError(t, NewCodec().EncodeFrame(NewFrame(primitive.ProtocolVersion4, 2, &message.Options{}), encoded))
+
+	decoders := map[string]func(codec RawCodec, source *bytes.Reader) (*Frame, error){
+		"DecodeFrame": func(codec RawCodec, source *bytes.Reader) (*Frame, error) {
+			return codec.DecodeFrame(source)
+		},
+		"DecodeFrameInArena": func(codec RawCodec, source *bytes.Reader) (*Frame, error) {
+			return codec.DecodeFrameInArena(source, AcquireArena())
+		},
+	}
+	tests := []struct {
+		policy   TrailingBytesPolicy
+		trailing []byte
+		err      error
+	}{
+		{TrailingBytesPolicyIgnore, nil, nil},
+		{TrailingBytesPolicyFail, nil, ErrTrailingBytes},
+		{TrailingBytesPolicyDiscard, nil, nil},
+		{TrailingBytesPolicyAttach, []byte{0xca, 0xfe}, nil},
+	}
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.policy.String(), func(t *testing.T) {
+					var reported []byte
+					codec := NewRawCodec()
+					codec.SetTrailingBytesPolicy(tt.policy, func(header *Header, body *Body, trailing []byte) {
+						assert.Equal(t, int16(1), header.StreamId)
+						assert.IsType(t, &message.Query{}, body.Message)
+						reported = trailing
+					})
+					source := bytes.NewReader(encoded.Bytes())
+					decoded, err := decode(codec, source)
+					assert.Equal(t, []byte{0xca, 0xfe}, reported)
+					if tt.err != nil {
+						require.Error(t, err)
+						assert.True(t, errors.Is(err, tt.err))
+						return
+					}
+					require.NoError(t, err)
+					assert.Equal(t, "SELECT * FROM system.local", decoded.Body.Message.(*message.Query).Query)
+					assert.Equal(t, tt.trailing, decoded.Body.TrailingBytes)
+					next, err := decode(codec, source)
+					require.NoError(t, err)
+					assert.Equal(t, int16(2), next.Header.StreamId)
+				})
+			}
+		})
+	}
+
+	t.Run("ignore", func(t *testing.T) {
+		decoded, err := NewRawCodec().DecodeFrame(bytes.NewReader(encoded.Bytes()))
+		require.NoError(t, err)
+		assert.Nil(t, decoded.Body.TrailingBytes)
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownHeaderFlags is wrapped by the errors returned when decoding a frame header with flags that are not defined
+// by the protocol specification, with UnknownHeaderFlagsPolicyFail.
+var ErrUnknownHeaderFlags = errors.New("unknown header flags")
+
+// UnknownHeaderFlagsPolicy determines how a codec reacts when a decoded frame header has flags that are not defined by
+// the protocol specification, see Header.UnknownFlags. Such flags may be introduced by future protocol versions or
+// by vendor extensions, and may change the meaning of the frame body: proxies forwarding such frames may want to
+// detect them rather than silently mishandle them.
+type UnknownHeaderFlagsPolicy int
+
+const (
+	// UnknownHeaderFlagsPolicyIgnore is the default policy: unknown flags are kept in Header.Flags, but are otherwise
+	// ignored.
+	UnknownHeaderFlagsPolicyIgnore = UnknownHeaderFlagsPolicy(iota)
+	// UnknownHeaderFlagsPolicyFail makes decoding fail with an error wrapping ErrUnknownHeaderFlags.
+	UnknownHeaderFlagsPolicyFail
+)
+
+func (p UnknownHeaderFlagsPolicy) String() string {
+	switch p {
+	case UnknownHeaderFlagsPolicyIgnore:
+		return "UnknownHeaderFlagsPolicy IGNORE"
+	case UnknownHeaderFlagsPolicyFail:
+		return "UnknownHeaderFlagsPolicy FAIL"
+	}
+	return fmt.Sprintf("UnknownHeaderFlagsPolicy ? [%d]", int(p))
+}
+
+// UnknownHeaderFlagsHandler is a callback function that gets invoked whenever a decoded frame header has unknown
+// flags, before the UnknownHeaderFlagsPolicy is applied. The header is fully decoded, but its body is not.
+type UnknownHeaderFlagsHandler func(header *Header)
+
+// checkUnknownFlags applies the configured UnknownHeaderFlagsPolicy to the given decoded header.
+func (c *codec) checkUnknownFlags(header *Header) error {
+	unknown := header.UnknownFlags()
+	if unknown == 0 {
+		return nil
+	} else if c.onUnknownFlags != nil {
+		c.onUnknownFlags(header)
+	}
+	if c.unknownFlagsPolicy == UnknownHeaderFlagsPolicyFail {
+		return fmt.Errorf("%w: %08b", ErrUnknownHeaderFlags, uint8(unknown))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCodecUnknownHeaderFlagsPolicy(t *testing.T) {
+	query := NewFrame(primitive.ProtocolVersion4, 1, &message.Query{Query: "SELECT * FROM system.local"})
+	encoded := &bytes.Buffer{}
+	require.NoError(t, NewCodec().EncodeFrame(query, encoded))
+	encoded.Bytes()[1] |= 0x40
+
+	for _, policy := range []UnknownHeaderFlagsPolicy{UnknownHeaderFlagsPolicyIgnore, UnknownHeaderFlagsPolicyFail} {
+		t.Run(policy.String(), func(t *testing.T) {
+			var reported []*Header
+			codec := NewRawCodec()
+			codec.SetUnknownHeaderFlagsPolicy(policy, func(header *Header) {
+				reported = append(reported, header)
+			})
+			decoded, err := codec.DecodeFrame(bytes.NewReader(encoded.Bytes()))
+			require.Len(t, reported, 1)
+			assert.Equal(t, primitive.HeaderFlag(0x40), reported[0].UnknownFlags())
+			if policy == UnknownHeaderFlagsPolicyFail {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrUnknownHeaderFlags))
+				assert.Contains(t, err.Error(), "cannot decode header flags: unknown header flags: 01000000")
+				_, err = codec.DecodeRawFrame(bytes.NewReader(encoded.Bytes()))
+				assert.True(t, errors.Is(err, ErrUnknownHeaderFlags))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, primitive.HeaderFlag(0x40), decoded.Header.Flags)
+			}
+		})
+	}
+
+	// known flags are never reported
+	codec := NewRawCodec()
+	codec.SetUnknownHeaderFlagsPolicy(UnknownHeaderFlagsPolicyFail, func(header *Header) {
+		t.Fatalf("unexpected unknown flags: %v", header.Flags)
+	})
+	query.RequestTracingId(true)
+	query.SetCustomPayload(map[string][]byte{"key": {1}})
+	encoded.Reset()
+	require.NoError(t, codec.EncodeFrame(query, encoded))
+	_, err := codec.DecodeFrame(encoded)
+	require.NoError(t, err)
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cluster is a multi-node Cassandra cluster where each node runs in its own Docker container. Nodes share a dedicated
+// Docker network, and can be stopped and restarted to trigger status change events.
+type cluster struct {
+	name    string
+	image   string
+	network string
+	nodes   []*node
+}
+
+// node is a node in a cluster.
+type node struct {
+	name        string
+	containerId string
+	// address is the local address the native protocol port of the node is published on.
+	address string
+	// ip is the address of the node in the cluster network, as reported in events and in system tables.
+	ip net.IP
+}
+
+// startCluster starts a cluster with the given number of nodes, using the given image, e.g. "cassandra:3.11" or
+// "cassandra:4.0". Nodes are started one after the other, since Cassandra does not support concurrent bootstraps.
+// The cluster is removed when the test and all its subtests complete.
+func startCluster(t *testing.T, image string, size int) *cluster {
+	name := fmt.Sprintf("integration-%d", time.Now().UnixNano())
+	c := &cluster{name: name, image: image, network: name}
+	if err := exec.Command("docker", "network", "create", c.network).Run(); err != nil {
+		t.Fatalf("cannot create network %v: %v", c.network, err)
+	}
+	t.Cleanup(c.remove)
+	for i := 1; i <= size; i++ {
+		n := &node{name: fmt.Sprintf("%s-node%d", name, i)}
+		var err error
+		if n.containerId, n.address, err = startContainer(
+			image,
+			"--name", n.name,
+			"--network", c.network,
+			"-e", "CASSANDRA_CLUSTER_NAME="+name,
+			"-e", "CASSANDRA_SEEDS="+name+"-node1",
+			// keep memory usage low enough for several nodes to run on a single machine
+			"-e", "MAX_HEAP_SIZE=512M",
+			"-e", "HEAP_NEWSIZE=128M",
+		); err != nil {
+			t.Fatalf("cannot start %v: %v", n.name, err)
+		}
+		c.nodes = append(c.nodes, n)
+		if n.ip, err = containerIp(n.containerId, c.network); err != nil {
+			t.Fatalf("cannot inspect %v: %v", n.name, err)
+		}
+		if err = awaitServer(n.address, 3*time.Minute); err != nil {
+			t.Fatalf("node %v not ready: %v", n.name, err)
+		}
+	}
+	return c
+}
+
+// stop stops the given node gracefully; the other nodes will see it as down.
+func (c *cluster) stop(n *node) error {
+	if err := exec.Command("docker", "stop", n.containerId).Run(); err != nil {
+		return fmt.Errorf("cannot stop %v: %w", n.name, err)
+	}
+	return nil
+}
+
+// start restarts the given node after a call to stop, and waits until it accepts client connections.
+func (c *cluster) start(n *node) (err error) {
+	if err = exec.Command("docker", "start", n.containerId).Run(); err != nil {
+		return fmt.Errorf("cannot start %v: %w", n.name, err)
+	}
+	if n.address, err = publishedAddress(n.containerId); err != nil {
+		return err
+	}
+	return awaitServer(n.address, 3*time.Minute)
+}
+
+func (c *cluster) remove() {
+	for _, n := range c.nodes {
+		stopContainer(n.containerId)
+	}
+	_ = exec.Command("docker", "network", "rm", c.network).Run()
+}
+
+func containerIp(containerId string, network string) (net.IP, error) {
+	format := fmt.Sprintf("{{(index .NetworkSettings.Networks %q).IPAddress}}", network)
+	out, err := exec.Command("docker", "inspect", "-f", format, containerId).Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker inspect failed: %w", err)
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(out)))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid container ip: %s", out)
+	}
+	return ip, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var testedVersions = []primitive.ProtocolVersion{
+	primitive.ProtocolVersion3,
+	primitive.ProtocolVersion4,
+	primitive.ProtocolVersion5,
+}
+
+// TestMessageCodecs sends one request of each type to the server, then checks that each request and each response
+// survives a decode/encode round trip: the re-encoded frame must be byte-for-byte identical to the original one, and
+// decoding it again must produce the same frame.
+func TestMessageCodecs(t *testing.T) {
+	for _, version := range testedVersions {
+		t.Run(version.String(), func(t *testing.T) {
+			conn, err := dial(serverAddress, version)
+			require.NoError(t, err)
+			defer conn.close()
+
+			ex, err := conn.exchange(&message.Options{})
+			require.NoError(t, err)
+			if protocolErr, ok := ex.response.Body.Message.(*message.ProtocolError); ok {
+				t.Skipf("%v not supported by server: %v", version, protocolErr.ErrorMessage)
+			}
+			require.IsType(t, &message.Supported{}, ex.response.Body.Message)
+			checkRoundTrips(t, ex)
+
+			ex, err = conn.handshake()
+			require.NoError(t, err)
+			checkRoundTrips(t, ex)
+
+			keyspace := fmt.Sprintf("integration_v%d", version)
+			for _, query := range []string{
+				fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}", keyspace),
+				fmt.Sprintf("USE %s", keyspace),
+				"CREATE TABLE IF NOT EXISTS t (pk int, cc int, v text, s set<int>, m map<text,int>, PRIMARY KEY (pk, cc))",
+				"INSERT INTO t (pk, cc, v, s, m) VALUES (1, 1, 'abc', {1, 2}, {'a': 1})",
+				"SELECT * FROM t WHERE pk = 1",
+				"SELECT * FROM system.local",
+				"SELECT * FROM nonexistent_table",
+				"NOT A CQL QUERY",
+			} {
+				ex = exchangeOk(t, conn, &message.Query{
+					Query:   query,
+					Options: &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+				})
+				checkRoundTrips(t, ex)
+			}
+
+			ex = exchangeOk(t, conn, &message.Prepare{Query: "SELECT * FROM t WHERE pk = ?"})
+			checkRoundTrips(t, ex)
+			require.IsType(t, &message.PreparedResult{}, ex.response.Body.Message)
+			prepared := ex.response.Body.Message.(*message.PreparedResult)
+
+			ex = exchangeOk(t, conn, &message.Execute{
+				QueryId:          prepared.PreparedQueryId,
+				ResultMetadataId: prepared.ResultMetadataId,
+				Options: &message.QueryOptions{
+					Consistency:      primitive.ConsistencyLevelOne,
+					PositionalValues: []*primitive.Value{primitive.NewValue([]byte{0, 0, 0, 1})},
+				},
+			})
+			checkRoundTrips(t, ex)
+			require.IsType(t, &message.RowsResult{}, ex.response.Body.Message)
+
+			ex = exchangeOk(t, conn, &message.Execute{
+				QueryId:          []byte("unknown"),
+				ResultMetadataId: prepared.ResultMetadataId,
+				Options:          &message.QueryOptions{Consistency: primitive.ConsistencyLevelOne},
+			})
+			checkRoundTrips(t, ex)
+			require.IsType(t, &message.Unprepared{}, ex.response.Body.Message)
+
+			ex = exchangeOk(t, conn, &message.Batch{
+				Type:        primitive.BatchTypeUnlogged,
+				Consistency: primitive.ConsistencyLevelOne,
+				Children: []*message.BatchChild{
+					{Query: "INSERT INTO t (pk, cc, v) VALUES (2, 1, 'def')"},
+					{Id: prepared.PreparedQueryId, Values: []*primitive.Value{primitive.NewValue([]byte{0, 0, 0, 1})}},
+				},
+			})
+			checkRoundTrips(t, ex)
+
+			ex = exchangeOk(t, conn, &message.Register{EventTypes: []primitive.EventType{
+				primitive.EventTypeSchemaChange,
+				primitive.EventTypeStatusChange,
+				primitive.EventTypeTopologyChange,
+			}})
+			checkRoundTrips(t, ex)
+			require.IsType(t, &message.Ready{}, ex.response.Body.Message)
+		})
+	}
+}
+
+func exchangeOk(t *testing.T, conn *rawConnection, msg message.Message) *exchange {
+	ex, err := conn.exchange(msg)
+	require.NoError(t, err)
+	return ex
+}
+
+func checkRoundTrips(t *testing.T, ex *exchange) {
+	// requests are decoded as a server would, responses are decoded as a client would
+	checkRoundTrip(t, "request", ex.encodedRequest, frame.NewServerCodec(), frame.NewClientCodec())
+	checkRoundTrip(t, "response", ex.encodedResponse, frame.NewClientCodec(), frame.NewServerCodec())
+}
+
+func checkRoundTrip(t *testing.T, what string, encoded []byte, decoder frame.Decoder, encoder frame.Encoder) {
+	decoded, err := decoder.DecodeFrame(bytes.NewReader(encoded))
+	require.NoError(t, err, "cannot decode %v", what)
+	reEncoded := &bytes.Buffer{}
+	require.NoError(t, encoder.EncodeFrame(decoded, reEncoded), "cannot re-encode %v: %v", what, decoded)
+	if !containsUnorderedMaps(decoded) {
+		assert.Equal(t, encoded, reEncoded.Bytes(), "byte-level round trip failed for %v: %v", what, decoded)
+	}
+	reDecoded, err := decoder.DecodeFrame(reEncoded)
+	require.NoError(t, err, "cannot decode re-encoded %v", what)
+	assert.Equal(t, decoded, reDecoded, "semantic round trip failed for %v", what)
+}
+
+// containsUnorderedMaps returns true if the frame contains protocol maps, which are decoded as Go maps: the order of
+// their entries is lost, so a byte-level comparison is not possible.
+func containsUnorderedMaps(f *frame.Frame) bool {
+	if f.Header.Flags.Contains(primitive.HeaderFlagCustomPayload) {
+		return true
+	}
+	switch msg := f.Body.Message.(type) {
+	case *message.Startup:
+		return len(msg.Options) > 1
+	case *message.Supported:
+		return len(msg.Options) > 1
+	case message.Error:
+		// some errors contain maps, e.g. the reason map of read and write failures
+		return msg.GetErrorCode() == primitive.ErrorCodeReadFailure || msg.GetErrorCode() == primitive.ErrorCodeWriteFailure
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+	"github.com/datastax/go-cassandra-native-protocol/segment"
+)
+
+// rawConnection is a minimal, synchronous connection that gives access to the exact bytes sent by the server, which
+// the client package does not expose. It does not support compression nor concurrent requests.
+type rawConnection struct {
+	conn         net.Conn
+	version      primitive.ProtocolVersion
+	codec        frame.RawCodec
+	segmentCodec segment.Codec
+	modernLayout bool
+	streamId     int16
+}
+
+// exchange is a request sent to the server and the response it produced, in encoded and decoded forms.
+type exchange struct {
+	request         *frame.Frame
+	encodedRequest  []byte
+	response        *frame.Frame
+	encodedResponse []byte
+}
+
+func dial(address string, version primitive.ProtocolVersion) (*rawConnection, error) {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &rawConnection{
+		conn:         conn,
+		version:      version,
+		codec:        frame.NewClientCodec(),
+		segmentCodec: segment.NewCodec(),
+	}, nil
+}
+
+// handshake sends a STARTUP request and switches to the modern framing layout if required.
+func (c *rawConnection) handshake() (*exchange, error) {
+	startup := message.NewStartup()
+	startup.SetDriverName("go-cassandra-native-protocol integration tests")
+	ex, err := c.exchange(startup)
+	if err != nil {
+		return nil, err
+	} else if _, ok := ex.response.Body.Message.(*message.Ready); !ok {
+		return nil, fmt.Errorf("expected READY, got: %v", ex.response.Body.Message)
+	}
+	c.modernLayout = c.version.SupportsModernFramingLayout()
+	return ex, nil
+}
+
+func (c *rawConnection) exchange(msg message.Message) (*exchange, error) {
+	c.streamId++
+	ex := &exchange{request: frame.NewFrame(c.version, c.streamId, msg)}
+	if err := c.conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return nil, err
+	}
+	encodedRequest := &bytes.Buffer{}
+	if err := c.codec.EncodeFrame(ex.request, encodedRequest); err != nil {
+		return nil, fmt.Errorf("cannot encode request: %w", err)
+	}
+	ex.encodedRequest = encodedRequest.Bytes()
+	if err := c.write(ex.encodedRequest); err != nil {
+		return nil, fmt.Errorf("cannot write request: %w", err)
+	}
+	encodedResponse, err := c.read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response: %w", err)
+	}
+	ex.encodedResponse = encodedResponse
+	if ex.response, err = c.codec.DecodeFrame(bytes.NewReader(encodedResponse)); err != nil {
+		return nil, fmt.Errorf("cannot decode response: %w", err)
+	} else if ex.response.Header.StreamId != c.streamId {
+		return nil, fmt.Errorf("expected stream id %d, got: %d", c.streamId, ex.response.Header.StreamId)
+	}
+	return ex, nil
+}
+
+func (c *rawConnection) write(encodedFrame []byte) error {
+	if !c.modernLayout {
+		_, err := c.conn.Write(encodedFrame)
+		return err
+	}
+	return c.segmentCodec.EncodeSegment(&segment.Segment{
+		Header:  &segment.Header{IsSelfContained: true},
+		Payload: &segment.Payload{UncompressedData: encodedFrame},
+	}, c.conn)
+}
+
+// read reads exactly one frame and returns its encoded form.
+func (c *rawConnection) read() ([]byte, error) {
+	if !c.modernLayout {
+		rawFrame, err := c.codec.DecodeRawFrame(c.conn)
+		if err != nil {
+			return nil, err
+		}
+		encodedFrame := &bytes.Buffer{}
+		if err = c.codec.EncodeRawFrame(rawFrame, encodedFrame); err != nil {
+			return nil, err
+		}
+		return encodedFrame.Bytes(), nil
+	}
+	var encodedFrame []byte
+	for {
+		seg, err := c.segmentCodec.DecodeSegment(c.conn)
+		if err != nil {
+			return nil, err
+		}
+		encodedFrame = append(encodedFrame, seg.Payload.UncompressedData...)
+		if seg.Header.IsSelfContained {
+			return encodedFrame, nil
+		}
+		header, err := c.codec.DecodeHeader(bytes.NewReader(encodedFrame))
+		if err != nil {
+			return nil, err
+		} else if len(encodedFrame) >= c.version.FrameHeaderLengthInBytes()+int(header.BodyLength) {
+			return encodedFrame, nil
+		}
+	}
+}
+
+func (c *rawConnection) close() {
+	_ = c.conn.Close()
+}
+
+// awaitServer waits until the server at the given address replies to an OPTIONS request.
+func awaitServer(address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := dial(address, primitive.ProtocolVersion4)
+		if err == nil {
+			_, err = conn.exchange(&message.Options{})
+			conn.close()
+			if err == nil {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+}
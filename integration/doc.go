@@ -0,0 +1,34 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package integration contains integration tests exercising the codecs in this library against live Cassandra
+// servers.
+//
+// Tests in this package are only compiled when the "integration" build tag is set. By default, a Cassandra server is
+// started in a Docker container before the tests run, and is removed afterwards; this requires a working docker
+// command. Alternatively, an existing server can be used instead:
+//
+//  # start a container with the default image
+//  go test -tags integration ./integration
+//  # start a container with a specific image
+//  go test -tags integration ./integration -image cassandra:3.11
+//  # use an existing server listening on localhost:9042 without authentication
+//  go test -tags integration ./integration -address 127.0.0.1:9042
+//
+// Tests exercising topology and status events require a multi-node cluster; they are skipped unless a cluster size is
+// specified. Each node of the cluster runs in its own container, using the image specified with -image:
+//
+//  # start a 3-node Cassandra 3.11 cluster
+//  go test -tags integration ./integration -image cassandra:3.11 -cluster-size 3
+package integration
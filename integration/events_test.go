@@ -0,0 +1,75 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestStatusChangeEvents(t *testing.T) {
+	if clusterSize < 2 {
+		t.Skip("cluster tests disabled, use -cluster-size to enable them")
+	}
+	c := startCluster(t, serverImage, clusterSize)
+	for _, version := range []primitive.ProtocolVersion{primitive.ProtocolVersion3, primitive.ProtocolVersion4} {
+		t.Run(version.String(), func(t *testing.T) {
+			cqlClient := client.NewCqlClient(c.nodes[0].address, nil)
+			// Cassandra delays status events, and may take a while to detect that a node is down
+			cqlClient.ReadTimeout = time.Minute
+			conn, err := cqlClient.ConnectAndInit(context.Background(), version, client.ManagedStreamId)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			register := frame.NewFrame(version, client.ManagedStreamId, &message.Register{
+				EventTypes: []primitive.EventType{primitive.EventTypeStatusChange, primitive.EventTypeTopologyChange},
+			})
+			response, err := conn.SendAndReceive(register)
+			require.NoError(t, err)
+			require.IsType(t, &message.Ready{}, response.Body.Message)
+
+			target := c.nodes[len(c.nodes)-1]
+			require.NoError(t, c.stop(target))
+			expectStatusChange(t, conn, primitive.StatusChangeTypeDown, target)
+			require.NoError(t, c.start(target))
+			expectStatusChange(t, conn, primitive.StatusChangeTypeUp, target)
+		})
+	}
+}
+
+// expectStatusChange waits for a status change event of the given type for the given node, ignoring other events.
+func expectStatusChange(t *testing.T, conn *client.CqlClientConnection, changeType primitive.StatusChangeType, n *node) {
+	for {
+		event, err := conn.ReceiveEvent()
+		require.NoError(t, err, "expected %v event for %v", changeType, n.name)
+		if statusChange, ok := event.Body.Message.(*message.StatusChangeEvent); ok &&
+			statusChange.ChangeType == changeType && statusChange.Address.Addr.Equal(n.ip) {
+			assert.EqualValues(t, 9042, statusChange.Address.Port)
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+const defaultImage = "cassandra:4.0"
+
+var (
+	serverAddress string
+	serverImage   string
+	clusterSize   int
+)
+
+func TestMain(m *testing.M) {
+	flag.StringVar(&serverAddress, "address", "", "the address of an existing server to use; if empty, a server is started with Docker")
+	flag.StringVar(&serverImage, "image", defaultImage, "the Docker image to use when starting a server")
+	flag.IntVar(&clusterSize, "cluster-size", 0, "the number of nodes of the clusters started by cluster tests; cluster tests are skipped if less than 2")
+	flag.Parse()
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	if serverAddress == "" {
+		containerId, address, err := startContainer(serverImage)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot start %v: %v\n", serverImage, err)
+			return 1
+		}
+		defer stopContainer(containerId)
+		serverAddress = address
+	}
+	if err := awaitServer(serverAddress, 3*time.Minute); err != nil {
+		fmt.Fprintf(os.Stderr, "server %v not ready: %v\n", serverAddress, err)
+		return 1
+	}
+	return m.Run()
+}
+
+// startContainer starts a container from the given image, publishing the native protocol port on a random local port,
+// and returns the container id and the published address. Additional arguments are passed to the docker run command.
+func startContainer(image string, dockerArgs ...string) (containerId string, address string, err error) {
+	args := append([]string{"run", "-d", "-p", "127.0.0.1::9042"}, dockerArgs...)
+	out, err := exec.Command("docker", append(args, image)...).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("docker run failed: %w", err)
+	}
+	containerId = strings.TrimSpace(string(out))
+	if address, err = publishedAddress(containerId); err != nil {
+		stopContainer(containerId)
+		return "", "", err
+	}
+	return containerId, address, nil
+}
+
+// publishedAddress returns the local address the native protocol port of the given container is published on. The
+// address changes every time the container is restarted.
+func publishedAddress(containerId string) (string, error) {
+	out, err := exec.Command("docker", "port", containerId, "9042/tcp").Output()
+	if err != nil {
+		return "", fmt.Errorf("docker port failed: %w", err)
+	}
+	// the output may contain one line per published address, e.g. one for IPv4 and one for IPv6
+	return strings.TrimSpace(strings.Split(string(out), "\n")[0]), nil
+}
+
+func stopContainer(containerId string) {
+	_ = exec.Command("docker", "rm", "-f", containerId).Run()
+}
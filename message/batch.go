@@ -225,45 +225,76 @@ func (c *batchCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 
 func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (msg Message, err error) {
 	batch := &Batch{}
+	if err = decodeBatch(batch, source, version); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (c *batchCodec) DecodeInto(msg Message, source io.Reader, version primitive.ProtocolVersion) error {
+	batch, ok := msg.(*Batch)
+	if !ok {
+		return errors.New(fmt.Sprintf("expected *message.Batch, got %T", msg))
+	}
+	return decodeBatch(batch, source, version)
+}
+
+// decodeBatch decodes a BATCH message into the given batch, overwriting all its fields. The children slice, and the
+// children and positional values it contains, are reused if present.
+func decodeBatch(batch *Batch, source io.Reader, version primitive.ProtocolVersion) (err error) {
+	children := batch.Children
+	*batch = Batch{}
 	var batchType uint8
 	if batchType, err = primitive.ReadByte(source); err != nil {
-		return nil, fmt.Errorf("cannot read BATCH type: %w", err)
+		return fmt.Errorf("cannot read BATCH type: %w", err)
 	}
 	batch.Type = primitive.BatchType(batchType)
 	if err = primitive.CheckValidBatchType(batch.Type); err != nil {
-		return nil, err
+		return err
 	}
 	var childrenCount uint16
 	if childrenCount, err = primitive.ReadShort(source); err != nil {
-		return nil, fmt.Errorf("cannot read BATCH query count: %w", err)
+		return fmt.Errorf("cannot read BATCH query count: %w", err)
 	}
-	batch.Children = make([]*BatchChild, childrenCount)
+	if children == nil || cap(children) < int(childrenCount) {
+		grown := make([]*BatchChild, childrenCount)
+		copy(grown, children[:cap(children)])
+		children = grown
+	} else {
+		children = children[:childrenCount]
+	}
+	batch.Children = children
 	for i := 0; i < int(childrenCount); i++ {
 		var childType uint8
 		if childType, err = primitive.ReadByte(source); err != nil {
-			return nil, fmt.Errorf("cannot read BATCH child type for child #%d: %w", i, err)
+			return fmt.Errorf("cannot read BATCH child type for child #%d: %w", i, err)
+		}
+		child := children[i]
+		if child == nil {
+			child = &BatchChild{}
+			children[i] = child
 		}
-		var child = &BatchChild{}
+		values := child.Values
+		*child = BatchChild{}
 		switch primitive.BatchChildType(childType) {
 		case primitive.BatchChildTypeQueryString:
 			if child.Query, err = primitive.ReadLongString(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH query string for child #%d: %w", i, err)
+				return fmt.Errorf("cannot read BATCH query string for child #%d: %w", i, err)
 			}
 		case primitive.BatchChildTypePreparedId:
 			if child.Id, err = primitive.ReadShortBytes(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH query id for child #%d: %w", i, err)
+				return fmt.Errorf("cannot read BATCH query id for child #%d: %w", i, err)
 			}
 		default:
-			return nil, fmt.Errorf("unsupported BATCH child type for child #%d: %v", i, childType)
+			return fmt.Errorf("unsupported BATCH child type for child #%d: %v", i, childType)
 		}
-		if child.Values, err = primitive.ReadPositionalValues(source, version); err != nil {
-			return nil, fmt.Errorf("cannot read BATCH positional values for child #%d: %w", i, err)
+		if child.Values, err = primitive.ReadPositionalValuesInto(values, source, version); err != nil {
+			return fmt.Errorf("cannot read BATCH positional values for child #%d: %w", i, err)
 		}
-		batch.Children[i] = child
 	}
 	var batchConsistency uint16
 	if batchConsistency, err = primitive.ReadShort(source); err != nil {
-		return nil, fmt.Errorf("cannot read BATCH consistency: %w", err)
+		return fmt.Errorf("cannot read BATCH consistency: %w", err)
 	}
 	batch.Consistency = primitive.ConsistencyLevel(batchConsistency)
 	if version.SupportsBatchQueryFlags() {
@@ -278,15 +309,15 @@ func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 			flags = primitive.QueryFlag(f)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("cannot read BATCH query flags: %w", err)
+			return fmt.Errorf("cannot read BATCH query flags: %w", err)
 		}
 		if flags.Contains(primitive.QueryFlagValueNames) {
-			return nil, errors.New("cannot use BATCH with named values, see CASSANDRA-10246")
+			return errors.New("cannot use BATCH with named values, see CASSANDRA-10246")
 		}
 		if flags.Contains(primitive.QueryFlagSerialConsistency) {
 			var batchSerialConsistencyUint uint16
 			if batchSerialConsistencyUint, err = primitive.ReadShort(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH serial consistency: %w", err)
+				return fmt.Errorf("cannot read BATCH serial consistency: %w", err)
 			}
 			batchSerialConsistency := primitive.ConsistencyLevel(batchSerialConsistencyUint)
 			batch.SerialConsistency = &batchSerialConsistency
@@ -294,24 +325,24 @@ func (c *batchCodec) Decode(source io.Reader, version primitive.ProtocolVersion)
 		if flags.Contains(primitive.QueryFlagDefaultTimestamp) {
 			var batchDefaultTimestamp int64
 			if batchDefaultTimestamp, err = primitive.ReadLong(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH default timestamp: %w", err)
+				return fmt.Errorf("cannot read BATCH default timestamp: %w", err)
 			}
 			batch.DefaultTimestamp = &batchDefaultTimestamp
 		}
 		if version.SupportsQueryFlag(primitive.QueryFlagWithKeyspace) && flags.Contains(primitive.QueryFlagWithKeyspace) {
 			if batch.Keyspace, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH keyspace: %w", err)
+				return fmt.Errorf("cannot read BATCH keyspace: %w", err)
 			}
 		}
 		if version.SupportsQueryFlag(primitive.QueryFlagNowInSeconds) && flags.Contains(primitive.QueryFlagNowInSeconds) {
 			var batchNowInSeconds int32
 			if batchNowInSeconds, err = primitive.ReadInt(source); err != nil {
-				return nil, fmt.Errorf("cannot read BATCH now-in-seconds: %w", err)
+				return fmt.Errorf("cannot read BATCH now-in-seconds: %w", err)
 			}
 			batch.NowInSeconds = &batchNowInSeconds
 		}
 	}
-	return batch, nil
+	return nil
 }
 
 func (c *batchCodec) GetOpCode() primitive.OpCode {
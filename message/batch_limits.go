@@ -0,0 +1,91 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// BatchLimits defines limits to enforce when encoding BATCH messages, in order to reject oversized batches before
+// they are sent, rather than having the server reject them. Limits that are zero or negative are not enforced.
+type BatchLimits struct {
+	// MaxStatements is the maximum number of child statements in a batch.
+	MaxStatements int
+	// MaxSize is the maximum serialized size in bytes of a BATCH message. This mirrors the batch_size_fail_threshold
+	// setting of Cassandra, whose default is 50 KiB; note however that the server only takes the size of the mutations
+	// into account, so the serialized size of a batch is a conservative approximation.
+	MaxSize int
+}
+
+// BatchLimitError is returned when encoding a BATCH message that does not honor a BatchLimits. Use errors.As to
+// detect it.
+type BatchLimitError struct {
+	// Limit is the name of the BatchLimits field that was not honored.
+	Limit string
+	// Actual is the actual number of statements or size of the batch.
+	Actual int
+	// Max is the limit that was not honored.
+	Max int
+}
+
+func (e *BatchLimitError) Error() string {
+	switch e.Limit {
+	case "MaxStatements":
+		return fmt.Sprintf("BATCH has too many statements: %d (max %d)", e.Actual, e.Max)
+	default:
+		return fmt.Sprintf("BATCH is too large: %d bytes (max %d)", e.Actual, e.Max)
+	}
+}
+
+// Check returns a BatchLimitError if the given batch does not honor these limits, once encoded with the given
+// protocol version.
+func (l *BatchLimits) Check(batch *Batch, version primitive.ProtocolVersion) error {
+	if l == nil {
+		return nil
+	} else if l.MaxStatements > 0 && len(batch.Children) > l.MaxStatements {
+		return &BatchLimitError{Limit: "MaxStatements", Actual: len(batch.Children), Max: l.MaxStatements}
+	} else if l.MaxSize > 0 {
+		if size, err := (&batchCodec{}).EncodedLength(batch, version); err != nil {
+			return err
+		} else if size > l.MaxSize {
+			return &BatchLimitError{Limit: "MaxSize", Actual: size, Max: l.MaxSize}
+		}
+	}
+	return nil
+}
+
+// NewBatchCodecWithLimits returns a Codec for BATCH messages that enforces the given limits when encoding. Decoding is
+// not affected. Register it in a frame codec to replace the default BATCH codec, e.g.
+// frame.NewClientCodec(message.NewBatchCodecWithLimits(limits)).
+func NewBatchCodecWithLimits(limits BatchLimits) Codec {
+	return &limitedBatchCodec{limits: limits}
+}
+
+type limitedBatchCodec struct {
+	batchCodec
+	limits BatchLimits
+}
+
+func (c *limitedBatchCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) error {
+	if batch, ok := msg.(*Batch); ok {
+		if err := c.limits.Check(batch, version); err != nil {
+			return err
+		}
+	}
+	return c.batchCodec.Encode(msg, dest, version)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestBatchLimits_Check(t *testing.T) {
+	batch := &Batch{Children: []*BatchChild{
+		{Query: "INSERT INTO ks.t (k, v) VALUES (1, ?)", Values: []*primitive.Value{primitive.NewValue(make([]byte, 100))}},
+		{Query: "INSERT INTO ks.t (k, v) VALUES (2, ?)", Values: []*primitive.Value{primitive.NewValue(make([]byte, 100))}},
+	}}
+	size, err := (&batchCodec{}).EncodedLength(batch, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		limits   *BatchLimits
+		expected error
+	}{
+		{"nil limits", nil, nil},
+		{"no limits", &BatchLimits{}, nil},
+		{"within limits", &BatchLimits{MaxStatements: 2, MaxSize: size}, nil},
+		{"too many statements", &BatchLimits{MaxStatements: 1}, &BatchLimitError{Limit: "MaxStatements", Actual: 2, Max: 1}},
+		{"too large", &BatchLimits{MaxSize: 200}, &BatchLimitError{Limit: "MaxSize", Actual: size, Max: 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.limits.Check(batch, primitive.ProtocolVersion4))
+		})
+	}
+	assert.Equal(t, "BATCH has too many statements: 2 (max 1)", tests[3].expected.Error())
+	assert.Contains(t, tests[4].expected.Error(), "BATCH is too large")
+}
+
+func TestNewBatchCodecWithLimits(t *testing.T) {
+	codec := NewBatchCodecWithLimits(BatchLimits{MaxStatements: 1})
+	assert.Equal(t, primitive.OpCodeBatch, codec.GetOpCode())
+	child := &BatchChild{Query: "INSERT INTO ks.t (k) VALUES (1)"}
+
+	dest := &bytes.Buffer{}
+	err := codec.Encode(&Batch{Children: []*BatchChild{child, child}}, dest, primitive.ProtocolVersion4)
+	var limitErr *BatchLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "MaxStatements", limitErr.Limit)
+	assert.Zero(t, dest.Len())
+
+	batch := &Batch{Children: []*BatchChild{child}}
+	require.NoError(t, codec.Encode(batch, dest, primitive.ProtocolVersion4))
+	decoded, err := codec.Decode(dest, primitive.ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Equal(t, child.Query, decoded.(*Batch).Children[0].Query)
+}
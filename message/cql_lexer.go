@@ -0,0 +1,201 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cqlTokenKind is the kind of a token produced by cqlLexer.
+type cqlTokenKind int
+
+const (
+	cqlTokenSpace = cqlTokenKind(iota)
+	cqlTokenComment
+	// cqlTokenString is a string literal, either quoted with single quotes or with $$.
+	cqlTokenString
+	// cqlTokenQuotedName is an identifier quoted with double quotes.
+	cqlTokenQuotedName
+	// cqlTokenNumber is an unsigned numeric literal, including floating point and blob literals.
+	cqlTokenNumber
+	cqlTokenUuid
+	// cqlTokenWord is a keyword or an unquoted identifier.
+	cqlTokenWord
+	// cqlTokenPunctuation is a single character that does not belong to any other token, e.g. a dot or a parenthesis.
+	cqlTokenPunctuation
+)
+
+// cqlToken is a token of a CQL query string; its text is the exact text found in the query.
+type cqlToken struct {
+	kind cqlTokenKind
+	text string
+}
+
+// cqlLexer splits CQL query strings into tokens. It is a lightweight lexer meant for query normalization and
+// inspection, not a full CQL parser: queries are not validated, and malformed queries, e.g. with unterminated string
+// literals or comments, are split on a best effort basis.
+type cqlLexer struct {
+	query []rune
+	pos   int
+}
+
+func newCqlLexer(query string) *cqlLexer {
+	return &cqlLexer{query: []rune(query)}
+}
+
+// next returns the next token, or false if the end of the query was reached.
+func (l *cqlLexer) next() (cqlToken, bool) {
+	if l.pos >= len(l.query) {
+		return cqlToken{}, false
+	}
+	start := l.pos
+	kind := cqlTokenPunctuation
+	switch r := l.query[l.pos]; {
+	case unicode.IsSpace(r):
+		for l.pos < len(l.query) && unicode.IsSpace(l.query[l.pos]) {
+			l.pos++
+		}
+		kind = cqlTokenSpace
+	case l.startsWith("--") || l.startsWith("//"):
+		l.skipUntil("\n")
+		kind = cqlTokenComment
+	case l.startsWith("/*"):
+		l.pos += 2
+		l.skipUntil("*/")
+		kind = cqlTokenComment
+	case r == '\'':
+		l.skipQuoted('\'')
+		kind = cqlTokenString
+	case l.startsWith("$$"):
+		l.pos += 2
+		l.skipUntil("$$")
+		kind = cqlTokenString
+	case r == '"':
+		l.skipQuoted('"')
+		kind = cqlTokenQuotedName
+	case l.isUuid():
+		l.pos += len(uuidPattern)
+		kind = cqlTokenUuid
+	case isCqlWordRune(r):
+		kind = cqlTokenWord
+		if l.isDigit(l.pos) {
+			kind = cqlTokenNumber
+		}
+		l.skipWord()
+	default:
+		l.pos++
+	}
+	return cqlToken{kind: kind, text: string(l.query[start:l.pos])}, true
+}
+
+// isDigit returns true if the rune at the given position is a decimal digit.
+func (l *cqlLexer) isDigit(pos int) bool {
+	return pos < len(l.query) && l.query[pos] >= '0' && l.query[pos] <= '9'
+}
+
+func (l *cqlLexer) startsWith(prefix string) bool {
+	return strings.HasPrefix(string(l.query[l.pos:minInt(l.pos+len(prefix), len(l.query))]), prefix)
+}
+
+// skipUntil advances past the next occurrence of the given delimiter, or to the end of the query if there is none.
+func (l *cqlLexer) skipUntil(delimiter string) {
+	for l.pos < len(l.query) && !l.startsWith(delimiter) {
+		l.pos++
+	}
+	l.pos = minInt(l.pos+len(delimiter), len(l.query))
+}
+
+// skipQuoted advances past the quoted string starting at the current position; doubled quotes are escaped quotes.
+func (l *cqlLexer) skipQuoted(quote rune) {
+	for l.pos++; l.pos < len(l.query); l.pos++ {
+		if l.query[l.pos] == quote {
+			if l.pos+1 < len(l.query) && l.query[l.pos+1] == quote {
+				l.pos++
+			} else {
+				l.pos++
+				return
+			}
+		}
+	}
+}
+
+// skipWord advances past the word starting at the current position. Words include identifiers, keywords, and numeric
+// and blob literals: floating point literals may contain dots and signed exponents.
+func (l *cqlLexer) skipWord() {
+	numeric := l.isDigit(l.pos)
+	for l.pos < len(l.query) {
+		r := l.query[l.pos]
+		if isCqlWordRune(r) {
+			l.pos++
+		} else if numeric && r == '.' && l.isDigit(l.pos+1) {
+			l.pos++
+		} else if numeric && (r == '+' || r == '-') && (l.query[l.pos-1] == 'e' || l.query[l.pos-1] == 'E') {
+			l.pos++
+		} else {
+			return
+		}
+	}
+}
+
+// uuidPattern is the pattern of UUID literals, where x is a hex digit.
+const uuidPattern = "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+
+// isUuid returns true if a UUID literal starts at the current position. UUID literals must be detected before other
+// words, since they may start with a letter, and contain dashes.
+func (l *cqlLexer) isUuid() bool {
+	end := l.pos + len(uuidPattern)
+	if end > len(l.query) || (end < len(l.query) && isCqlWordRune(l.query[end])) {
+		return false
+	}
+	for i, expected := range uuidPattern {
+		if r := l.query[l.pos+i]; (expected == '-' && r != '-') || (expected == 'x' && !isHexDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// unquoteName returns the name held by the given quoted name token, without its quotes and with escaped quotes
+// unescaped. Unterminated quoted names are accepted.
+func unquoteName(text string) string {
+	var name strings.Builder
+	quoted := []rune(text)
+	for i := 1; i < len(quoted); i++ {
+		if quoted[i] == '"' {
+			if i+1 >= len(quoted) || quoted[i+1] != '"' {
+				break
+			}
+			i++
+		}
+		name.WriteRune(quoted[i])
+	}
+	return name.String()
+}
+
+func isCqlWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
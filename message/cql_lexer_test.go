@@ -0,0 +1,60 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCqlLexer(t *testing.T) {
+	query := `SELECT "My""Col", v FROM ks.t /* it's */ WHERE k = 'it''s' AND u = 01234567-89ab-cdef-0123-456789abcdef ` +
+		`AND f = 1.5e-3 AND s = $$a'b$$ -- "tail`
+	expected := []cqlToken{
+		{cqlTokenWord, "SELECT"}, {cqlTokenSpace, " "}, {cqlTokenQuotedName, `"My""Col"`}, {cqlTokenPunctuation, ","},
+		{cqlTokenSpace, " "}, {cqlTokenWord, "v"}, {cqlTokenSpace, " "}, {cqlTokenWord, "FROM"}, {cqlTokenSpace, " "},
+		{cqlTokenWord, "ks"}, {cqlTokenPunctuation, "."}, {cqlTokenWord, "t"}, {cqlTokenSpace, " "},
+		{cqlTokenComment, "/* it's */"}, {cqlTokenSpace, " "}, {cqlTokenWord, "WHERE"}, {cqlTokenSpace, " "},
+		{cqlTokenWord, "k"}, {cqlTokenSpace, " "}, {cqlTokenPunctuation, "="}, {cqlTokenSpace, " "},
+		{cqlTokenString, "'it''s'"}, {cqlTokenSpace, " "}, {cqlTokenWord, "AND"}, {cqlTokenSpace, " "},
+		{cqlTokenWord, "u"}, {cqlTokenSpace, " "}, {cqlTokenPunctuation, "="}, {cqlTokenSpace, " "},
+		{cqlTokenUuid, "01234567-89ab-cdef-0123-456789abcdef"}, {cqlTokenSpace, " "}, {cqlTokenWord, "AND"},
+		{cqlTokenSpace, " "}, {cqlTokenWord, "f"}, {cqlTokenSpace, " "}, {cqlTokenPunctuation, "="},
+		{cqlTokenSpace, " "}, {cqlTokenNumber, "1.5e-3"}, {cqlTokenSpace, " "}, {cqlTokenWord, "AND"},
+		{cqlTokenSpace, " "}, {cqlTokenWord, "s"}, {cqlTokenSpace, " "}, {cqlTokenPunctuation, "="},
+		{cqlTokenSpace, " "}, {cqlTokenString, "$$a'b$$"}, {cqlTokenSpace, " "}, {cqlTokenComment, `-- "tail`},
+	}
+	var actual []cqlToken
+	lexer := newCqlLexer(query)
+	for token, ok := lexer.next(); ok; token, ok = lexer.next() {
+		actual = append(actual, token)
+	}
+	assert.Equal(t, expected, actual)
+
+	// unterminated tokens extend to the end of the query
+	token, _ := newCqlLexer(`'it''s`).next()
+	assert.Equal(t, cqlToken{cqlTokenString, `'it''s`}, token)
+	token, _ = newCqlLexer(`/* comment`).next()
+	assert.Equal(t, cqlToken{cqlTokenComment, `/* comment`}, token)
+}
+
+func TestUnquoteName(t *testing.T) {
+	assert.Equal(t, "MyTable", unquoteName(`"MyTable"`))
+	assert.Equal(t, `My"Table`, unquoteName(`"My""Table"`))
+	assert.Equal(t, `"`, unquoteName(`""""`))
+	assert.Equal(t, "", unquoteName(`""`))
+	assert.Equal(t, `unterminated"`, unquoteName(`"unterminated""`))
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// DefaultCqlMajorVersion is the major CQL version spoken by clients of this library.
+const DefaultCqlMajorVersion = 3
+
+// cqlVersionRegexp matches the numeric part of a CQL version; servers may append nonstandard suffixes to it, e.g.
+// "3.4.5-SNAPSHOT".
+var cqlVersionRegexp = regexp.MustCompile(`^\s*(\d+)\.(\d+)\.(\d+)`)
+
+// CqlVersionNegotiator selects the CQL version to request in STARTUP, among the CQL_VERSION values advertised by the
+// server in its SUPPORTED response. It is preferable to create CqlVersionNegotiator instances using the constructor
+// function NewCqlVersionNegotiator.
+type CqlVersionNegotiator struct {
+
+	// MajorVersion is the major CQL version that the client speaks; only advertised versions with the same major
+	// version are considered compatible. Defaults to DefaultCqlMajorVersion.
+	MajorVersion int
+
+	// Overrides maps CQL_VERSION values advertised by the server to the values to consider instead. This is useful for
+	// servers that advertise nonstandard values, e.g. {"3.x-custom": "3.4.0"}. Mapping a value to the empty string
+	// excludes it from the negotiation.
+	Overrides map[string]string
+}
+
+func NewCqlVersionNegotiator() *CqlVersionNegotiator {
+	return &CqlVersionNegotiator{MajorVersion: DefaultCqlMajorVersion}
+}
+
+// Negotiate returns the highest compatible CQL version among the CQL_VERSION values in the given SUPPORTED response.
+// Values with nonstandard suffixes are normalized to their numeric part, e.g. "3.4.5-SNAPSHOT" becomes "3.4.5".
+// Values that cannot be parsed are ignored, unless they are overridden. An error is returned if no compatible
+// version was advertised.
+func (n *CqlVersionNegotiator) Negotiate(supported *Supported) (string, error) {
+	var advertised []string
+	if supported != nil {
+		advertised = supported.Options[StartupOptionCqlVersion]
+	}
+	var best [3]int
+	var found bool
+	for _, value := range advertised {
+		if override, ok := n.Overrides[value]; ok {
+			value = override
+		}
+		matches := cqlVersionRegexp.FindStringSubmatch(value)
+		if matches == nil {
+			continue
+		}
+		var current [3]int
+		for i := range current {
+			current[i], _ = strconv.Atoi(matches[i+1])
+		}
+		if current[0] == n.MajorVersion && (!found || compareCqlVersions(current, best) > 0) {
+			best = current
+			found = true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no compatible CQL version found for major version %d, server advertised: %v",
+			n.MajorVersion, advertised)
+	}
+	return fmt.Sprintf("%d.%d.%d", best[0], best[1], best[2]), nil
+}
+
+// PopulateStartup negotiates the CQL version as in Negotiate, then sets it as the CQL_VERSION option of the given
+// STARTUP message.
+func (n *CqlVersionNegotiator) PopulateStartup(supported *Supported, startup *Startup) error {
+	if cqlVersion, err := n.Negotiate(supported); err != nil {
+		return err
+	} else {
+		if startup.Options == nil {
+			startup.Options = map[string]string{}
+		}
+		startup.SetCqlVersion(cqlVersion)
+		return nil
+	}
+}
+
+func compareCqlVersions(v1, v2 [3]int) int {
+	for i := range v1 {
+		if v1[i] != v2[i] {
+			return v1[i] - v2[i]
+		}
+	}
+	return 0
+}
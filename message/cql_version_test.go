@@ -0,0 +1,78 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCqlVersionNegotiator_Negotiate(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []string
+		overrides  map[string]string
+		expected   string
+		err        string
+	}{
+		{"single", []string{"3.4.5"}, nil, "3.4.5", ""},
+		{"highest", []string{"3.0.0", "3.11.2", "3.4.5"}, nil, "3.11.2", ""},
+		{"incompatible major", []string{"3.4.5", "4.0.0"}, nil, "3.4.5", ""},
+		{"nonstandard suffix", []string{"3.4.5-SNAPSHOT"}, nil, "3.4.5", ""},
+		{"unparseable", []string{"latest", "3.0.0"}, nil, "3.0.0", ""},
+		{"override", []string{"cql-3-custom", "3.0.0"}, map[string]string{"cql-3-custom": "3.4.0"}, "3.4.0", ""},
+		{"override exclusion", []string{"3.4.5", "3.0.0"}, map[string]string{"3.4.5": ""}, "3.0.0", ""},
+		{
+			"none compatible",
+			[]string{"4.0.0"},
+			nil,
+			"",
+			"no compatible CQL version found for major version 3, server advertised: [4.0.0]",
+		},
+		{
+			"none advertised",
+			nil,
+			nil,
+			"",
+			"no compatible CQL version found for major version 3, server advertised: []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			negotiator := NewCqlVersionNegotiator()
+			negotiator.Overrides = tt.overrides
+			supported := &Supported{Options: map[string][]string{StartupOptionCqlVersion: tt.advertised}}
+			actual, err := negotiator.Negotiate(supported)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCqlVersionNegotiator_PopulateStartup(t *testing.T) {
+	startup := NewStartup(StartupOptionCompression, "LZ4")
+	supported := &Supported{Options: map[string][]string{StartupOptionCqlVersion: {"3.4.5"}}}
+	require.NoError(t, NewCqlVersionNegotiator().PopulateStartup(supported, startup))
+	assert.Equal(t, "3.4.5", startup.GetCqlVersion())
+	assert.Equal(t, "LZ4", startup.Options[StartupOptionCompression])
+	assert.Error(t, NewCqlVersionNegotiator().PopulateStartup(&Supported{}, startup))
+	assert.Equal(t, "3.4.5", startup.GetCqlVersion())
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// Credentials is the legacy authentication request message, used by protocol versions 1 and 2 in response to an
+// Authenticate response message, when the server is configured with a non-SASL authenticator. It was replaced by
+// AuthResponse in protocol version 3, and cannot be encoded nor decoded with protocol versions 3 and higher.
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=github.com/datastax/go-cassandra-native-protocol/message.Message
+type Credentials struct {
+	// Credentials contains the credentials expected by the server authenticator. For the default
+	// PasswordAuthenticator, the expected keys are "username" and "password".
+	Credentials map[string]string
+}
+
+func (m *Credentials) IsResponse() bool {
+	return false
+}
+
+func (m *Credentials) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeCredentials
+}
+
+func (m *Credentials) String() string {
+	keys := make([]string, 0, len(m.Credentials))
+	for key := range m.Credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// values are not printed, since they usually contain secrets
+	return fmt.Sprint("CREDENTIALS ", keys)
+}
+
+type credentialsCodec struct{}
+
+func (c *credentialsCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) error {
+	credentials, ok := msg.(*Credentials)
+	if !ok {
+		return fmt.Errorf("expected *message.Credentials, got %T", msg)
+	} else if err := primitive.CheckLegacyCredentialsProtocolVersion(version); err != nil {
+		return err
+	} else if err := primitive.WriteStringMap(credentials.Credentials, dest); err != nil {
+		return fmt.Errorf("cannot write CREDENTIALS credentials: %w", err)
+	}
+	return nil
+}
+
+func (c *credentialsCodec) EncodedLength(msg Message, version primitive.ProtocolVersion) (int, error) {
+	credentials, ok := msg.(*Credentials)
+	if !ok {
+		return -1, fmt.Errorf("expected *message.Credentials, got %T", msg)
+	} else if err := primitive.CheckLegacyCredentialsProtocolVersion(version); err != nil {
+		return -1, err
+	}
+	return primitive.LengthOfStringMap(credentials.Credentials), nil
+}
+
+func (c *credentialsCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (Message, error) {
+	if err := primitive.CheckLegacyCredentialsProtocolVersion(version); err != nil {
+		return nil, err
+	} else if credentials, err := primitive.ReadStringMap(source); err != nil {
+		return nil, fmt.Errorf("cannot read CREDENTIALS credentials: %w", err)
+	} else {
+		return &Credentials{Credentials: credentials}, nil
+	}
+}
+
+func (c *credentialsCodec) GetOpCode() primitive.OpCode {
+	return primitive.OpCodeCredentials
+}
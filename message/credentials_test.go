@@ -0,0 +1,114 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestCredentials_DeepCopy(t *testing.T) {
+	msg := &Credentials{Credentials: map[string]string{"username": "cassandra"}}
+	cloned := msg.DeepCopy()
+	assert.Equal(t, msg, cloned)
+	cloned.Credentials["username"] = "admin"
+	assert.NotEqual(t, msg, cloned)
+	assert.Equal(t, "cassandra", msg.Credentials["username"])
+	assert.Equal(t, "admin", cloned.Credentials["username"])
+}
+
+func TestCredentials_String(t *testing.T) {
+	msg := &Credentials{Credentials: map[string]string{"username": "cassandra", "password": "s3cr3t"}}
+	assert.Equal(t, "CREDENTIALS [password username]", msg.String())
+}
+
+func TestCredentialsCodec_Encode(t *testing.T) {
+	codec := &credentialsCodec{}
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			var unsupported error
+			if version >= primitive.ProtocolVersion3 {
+				unsupported = fmt.Errorf("CREDENTIALS messages are not supported in %v", version)
+			}
+			tests := []encodeTestCase{
+				{
+					"simple credentials",
+					&Credentials{Credentials: map[string]string{"user": "cass"}},
+					[]byte{
+						0, 1, // map length
+						0, 4, u, s, e, r,
+						0, 4, c, a, s, s,
+					},
+					unsupported,
+				},
+				{
+					"nil credentials",
+					&Credentials{},
+					[]byte{0, 0},
+					unsupported,
+				},
+				{
+					"not credentials",
+					&AuthResponse{},
+					nil,
+					errors.New("expected *message.Credentials, got *message.AuthResponse"),
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					dest := &bytes.Buffer{}
+					err := codec.Encode(tt.input, dest, version)
+					assert.Equal(t, tt.err, err)
+					if err == nil {
+						assert.Equal(t, tt.expected, dest.Bytes())
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestCredentialsCodec_EncodedLength(t *testing.T) {
+	codec := &credentialsCodec{}
+	length, err := codec.EncodedLength(&Credentials{Credentials: map[string]string{"user": "cass"}}, primitive.ProtocolVersion2)
+	assert.NoError(t, err)
+	assert.Equal(t, primitive.LengthOfShort+primitive.LengthOfString("user")+primitive.LengthOfString("cass"), length)
+	_, err = codec.EncodedLength(&Credentials{}, primitive.ProtocolVersion3)
+	assert.EqualError(t, err, "CREDENTIALS messages are not supported in ProtocolVersion OSS 3")
+}
+
+func TestCredentialsCodec_Decode(t *testing.T) {
+	codec := &credentialsCodec{}
+	encoded := []byte{
+		0, 1, // map length
+		0, 4, u, s, e, r,
+		0, 4, c, a, s, s,
+	}
+	decoded, err := codec.Decode(bytes.NewBuffer(encoded), primitive.ProtocolVersion2)
+	assert.NoError(t, err)
+	assert.Equal(t, &Credentials{Credentials: map[string]string{"user": "cass"}}, decoded)
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion3) {
+		t.Run(version.String(), func(t *testing.T) {
+			_, err := codec.Decode(bytes.NewBuffer(encoded), version)
+			assert.EqualError(t, err, fmt.Sprintf("CREDENTIALS messages are not supported in %v", version))
+		})
+	}
+}
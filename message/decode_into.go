@@ -0,0 +1,57 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// InPlaceDecoder is implemented by codecs that can decode a message into a caller-provided message, instead of
+// allocating a new one for each decoded frame. This allows hot loops, e.g. in proxies or benchmarks, to reuse
+// preallocated messages. The built-in QUERY, EXECUTE, BATCH and RESULT codecs implement this interface.
+//
+// All the fields of the given message are overwritten; slices and nested structs, such as query options, positional
+// values and rows, are reused whenever possible. Therefore, references to those retained from a previous decode must
+// not be used after the message is decoded again. If decoding fails, the message is left in an unspecified state.
+//
+// Combined with frame.RawDecoder.DecodeBodyPrefix, this allows the body of a raw frame to be decoded into a reusable
+// message, by passing the message reader returned by DecodeBodyPrefix as the source.
+type InPlaceDecoder interface {
+	DecodeInto(msg Message, source io.Reader, version primitive.ProtocolVersion) error
+}
+
+// DecodeInto decodes a message with the given decoder into the given message, which must be a pointer to the message
+// type produced by the decoder. If the decoder implements InPlaceDecoder, the message is decoded in place; otherwise,
+// a new message is decoded and then copied into the given message.
+func DecodeInto(decoder Decoder, msg Message, source io.Reader, version primitive.ProtocolVersion) error {
+	if inPlace, ok := decoder.(InPlaceDecoder); ok {
+		return inPlace.DecodeInto(msg, source, version)
+	}
+	decoded, err := decoder.Decode(source, version)
+	if err != nil {
+		return err
+	}
+	dest := reflect.ValueOf(msg)
+	src := reflect.ValueOf(decoded)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() || src.Type() != dest.Type() {
+		return fmt.Errorf("cannot decode %T into %T", decoded, msg)
+	}
+	dest.Elem().Set(src.Elem())
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func encodeForDecodeInto(t *testing.T, codec Codec, msg Message, version primitive.ProtocolVersion) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(msg, buf, version))
+	return buf
+}
+
+func TestDecodeInto_Query(t *testing.T) {
+	codec := &queryCodec{}
+	serial := primitive.ConsistencyLevelLocalSerial
+	first := &Query{
+		Query: "SELECT * FROM ks.t WHERE k = ? AND c = ?",
+		Options: &QueryOptions{
+			Consistency:       primitive.ConsistencyLevelQuorum,
+			PositionalValues:  []*primitive.Value{primitive.NewValue([]byte{1}), primitive.NewValue([]byte{2})},
+			SerialConsistency: &serial,
+			PageSize:          100,
+		},
+	}
+	second := &Query{
+		Query: "SELECT * FROM ks.t WHERE k = ?",
+		Options: &QueryOptions{
+			Consistency:      primitive.ConsistencyLevelOne,
+			PositionalValues: []*primitive.Value{primitive.NewNullValue()},
+		},
+	}
+	query := &Query{}
+	require.NoError(t, codec.DecodeInto(query, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, first, query)
+	options := query.Options
+	values := query.Options.PositionalValues
+	require.NoError(t, codec.DecodeInto(query, encodeForDecodeInto(t, codec, second, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, second, query)
+	assert.Same(t, options, query.Options)
+	assert.Same(t, values[0], query.Options.PositionalValues[0])
+
+	err := codec.DecodeInto(&Execute{}, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected *message.Query, got *message.Execute")
+}
+
+func TestDecodeInto_Execute(t *testing.T) {
+	codec := &executeCodec{}
+	v5 := &Execute{
+		QueryId:          []byte{0xca, 0xfe},
+		ResultMetadataId: []byte{0xba, 0xbe},
+		Options:          &QueryOptions{PositionalValues: []*primitive.Value{primitive.NewValue([]byte{1})}},
+	}
+	v4 := &Execute{
+		QueryId: []byte{0xde, 0xad},
+		Options: &QueryOptions{Consistency: primitive.ConsistencyLevelAll},
+	}
+	execute := &Execute{}
+	require.NoError(t, codec.DecodeInto(execute, encodeForDecodeInto(t, codec, v5, primitive.ProtocolVersion5), primitive.ProtocolVersion5))
+	assert.Equal(t, v5, execute)
+	require.NoError(t, codec.DecodeInto(execute, encodeForDecodeInto(t, codec, v4, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Nil(t, execute.ResultMetadataId)
+	assert.Equal(t, v4, execute)
+}
+
+func TestDecodeInto_Batch(t *testing.T) {
+	codec := &batchCodec{}
+	first := &Batch{
+		Type: primitive.BatchTypeLogged,
+		Children: []*BatchChild{
+			{Query: "INSERT INTO ks.t (k) VALUES (?)", Values: []*primitive.Value{primitive.NewValue([]byte{1})}},
+			{Id: []byte{0xca, 0xfe}, Values: []*primitive.Value{}},
+		},
+		Consistency: primitive.ConsistencyLevelQuorum,
+	}
+	second := &Batch{
+		Type: primitive.BatchTypeUnlogged,
+		Children: []*BatchChild{
+			{Id: []byte{0xba, 0xbe}, Values: []*primitive.Value{primitive.NewValue([]byte{2})}},
+		},
+		Consistency: primitive.ConsistencyLevelOne,
+	}
+	batch := &Batch{}
+	require.NoError(t, codec.DecodeInto(batch, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, first, batch)
+	child := batch.Children[0]
+	require.NoError(t, codec.DecodeInto(batch, encodeForDecodeInto(t, codec, second, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, second, batch)
+	assert.Same(t, child, batch.Children[0])
+}
+
+func TestDecodeInto_Rows(t *testing.T) {
+	codec := &resultCodec{}
+	metadata := &RowsMetadata{
+		ColumnCount: 1,
+		Columns:     []*ColumnMetadata{{Keyspace: "ks", Table: "t", Name: "k", Type: datatype.Int}},
+	}
+	first := &RowsResult{
+		Metadata: metadata,
+		Data:     RowSet{{{0, 0, 0, 1}}, {{0, 0, 0, 2}}, {{0, 0, 0, 3}}},
+	}
+	second := &RowsResult{
+		Metadata: metadata,
+		Data:     RowSet{{{0, 0, 0, 4}}},
+	}
+	empty := &RowsResult{
+		Metadata: metadata,
+		Data:     RowSet{},
+	}
+	rows := &RowsResult{}
+	require.NoError(t, codec.DecodeInto(rows, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, first, rows)
+	data := rows.Data
+	require.NoError(t, codec.DecodeInto(rows, encodeForDecodeInto(t, codec, second, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, second, rows)
+	assert.Same(t, &data[0], &rows.Data[0])
+	require.NoError(t, codec.DecodeInto(rows, encodeForDecodeInto(t, codec, empty, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, empty, rows)
+
+	err := codec.DecodeInto(&VoidResult{}, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode RESULT ResultType Rows [0x00000002] into *message.VoidResult")
+	err = codec.DecodeInto(&Query{}, encodeForDecodeInto(t, codec, first, primitive.ProtocolVersion4), primitive.ProtocolVersion4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode RESULT ResultType Rows [0x00000002] into *message.Query")
+}
+
+func TestDecodeInto_Fallback(t *testing.T) {
+	codec := &startupCodec{}
+	expected := NewStartup()
+	startup := &Startup{Options: map[string]string{"foo": "bar"}}
+	require.NoError(t, DecodeInto(codec, startup, encodeForDecodeInto(t, codec, expected, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, expected, startup)
+
+	err := DecodeInto(codec, &Options{}, encodeForDecodeInto(t, codec, expected, primitive.ProtocolVersion4), primitive.ProtocolVersion4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot decode *message.Startup into *message.Options")
+
+	query := &Query{}
+	require.NoError(t, DecodeInto(&queryCodec{}, query, encodeForDecodeInto(t, &queryCodec{}, &Query{Query: "SELECT", Options: &QueryOptions{}}, primitive.ProtocolVersion4), primitive.ProtocolVersion4))
+	assert.Equal(t, "SELECT", query.Query)
+}
@@ -323,6 +323,37 @@ func (in *ContinuousPagingOptions) DeepCopy() *ContinuousPagingOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Credentials) DeepCopyInto(out *Credentials) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Credentials.
+func (in *Credentials) DeepCopy() *Credentials {
+	if in == nil {
+		return nil
+	}
+	out := new(Credentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyMessage is an autogenerated deepcopy function, copying the receiver, creating a new Message.
+func (in *Credentials) DeepCopyMessage() Message {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Execute) DeepCopyInto(out *Execute) {
 	*out = *in
@@ -0,0 +1,85 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// The functions below classify error responses, so that retry and backoff logic built on top of this package does
+// not need to switch on error codes. The classification follows the default retry policies of the DataStax drivers.
+
+// IsTimeout returns true if the given error is a ReadTimeout or a WriteTimeout: the coordinator did not receive
+// enough responses from replicas in time.
+func IsTimeout(err Error) bool {
+	switch err.(type) {
+	case *ReadTimeout, *WriteTimeout:
+		return true
+	}
+	return false
+}
+
+// IsUnavailable returns true if the given error is an Unavailable error: the coordinator knew in advance that not
+// enough replicas were alive to achieve the requested consistency level, and did not attempt to execute the request.
+func IsUnavailable(err Error) bool {
+	_, ok := err.(*Unavailable)
+	return ok
+}
+
+// IsSchemaRelated returns true if the given error was caused by the schema, or by a schema change: AlreadyExists and
+// ConfigError, typically returned by concurrent or conflicting DDL statements.
+func IsSchemaRelated(err Error) bool {
+	switch err.(type) {
+	case *AlreadyExists, *ConfigError:
+		return true
+	}
+	return false
+}
+
+// IsRetryable returns true if retrying the request that caused the given error, on the same coordinator or on
+// another one, may succeed: timeouts, Unavailable, Overloaded, IsBootstrapping, ServerError and TruncateError.
+// Other errors, e.g. query validation errors, read and write failures, or fatal errors, will occur again if the
+// request is retried as is. Unprepared errors are not considered retryable: the statement must be prepared again
+// first.
+//
+// Note that some retryable errors may occur after the request was applied; such requests should only be retried if
+// they are idempotent, see AffectsIdempotentOnly.
+func IsRetryable(err Error) bool {
+	switch err.(type) {
+	case *ReadTimeout,
+		*WriteTimeout,
+		*Unavailable,
+		*Overloaded,
+		*IsBootstrapping,
+		*ServerError,
+		*TruncateError:
+		return true
+	}
+	return false
+}
+
+// AffectsIdempotentOnly returns true if the given error is retryable, see IsRetryable, but the request that caused it
+// may have been applied, at least partially: such requests should only be retried if they are idempotent. This is the
+// case of WriteTimeout errors, except for batch log writes, since the batch is not applied when its batch log
+// cannot be written; and of ServerError errors, since the server state is unknown.
+func AffectsIdempotentOnly(err Error) bool {
+	switch e := err.(type) {
+	case *WriteTimeout:
+		return e.WriteType != primitive.WriteTypeBatchLog
+	case *ServerError:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		err                   Error
+		timeout               bool
+		unavailable           bool
+		schemaRelated         bool
+		retryable             bool
+		affectsIdempotentOnly bool
+	}{
+		{&ServerError{}, false, false, false, true, true},
+		{&ProtocolError{}, false, false, false, false, false},
+		{&AuthenticationError{}, false, false, false, false, false},
+		{&Unavailable{}, false, true, false, true, false},
+		{&Overloaded{}, false, false, false, true, false},
+		{&IsBootstrapping{}, false, false, false, true, false},
+		{&TruncateError{}, false, false, false, true, false},
+		{&ReadTimeout{}, true, false, false, true, false},
+		{&WriteTimeout{WriteType: primitive.WriteTypeSimple}, true, false, false, true, true},
+		{&WriteTimeout{WriteType: primitive.WriteTypeBatchLog}, true, false, false, true, false},
+		{&ReadFailure{}, false, false, false, false, false},
+		{&WriteFailure{}, false, false, false, false, false},
+		{&FunctionFailure{}, false, false, false, false, false},
+		{&SyntaxError{}, false, false, false, false, false},
+		{&Unauthorized{}, false, false, false, false, false},
+		{&Invalid{}, false, false, false, false, false},
+		{&ConfigError{}, false, false, true, false, false},
+		{&AlreadyExists{}, false, false, true, false, false},
+		{&Unprepared{}, false, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.GetErrorCode().String(), func(t *testing.T) {
+			assert.Equal(t, tt.timeout, IsTimeout(tt.err))
+			assert.Equal(t, tt.unavailable, IsUnavailable(tt.err))
+			assert.Equal(t, tt.schemaRelated, IsSchemaRelated(tt.err))
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+			assert.Equal(t, tt.affectsIdempotentOnly, AffectsIdempotentOnly(tt.err))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// NewLenientErrorCodec returns a Codec for ERROR messages that tolerates nonstandard error bodies, as sent by some
+// Cassandra-compatible backends such as the Cassandra API of Azure Cosmos DB. Error bodies that cannot be decoded
+// according to the protocol specification, e.g. because the fields specific to an error code are missing or
+// truncated, are decoded as the error type matching their error code, with only their error message set, all other
+// fields being left to their zero values; error bodies with an unknown error code are decoded as ServerError. Encoding
+// is strict, just like with the default ERROR codec. Register it in a frame codec to replace the default ERROR codec,
+// e.g. frame.NewClientCodec(message.NewLenientErrorCodec()).
+func NewLenientErrorCodec() Codec {
+	return &lenientErrorCodec{}
+}
+
+type lenientErrorCodec struct {
+	errorCodec
+}
+
+func (c *lenientErrorCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (Message, error) {
+	body, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ERROR body: %w", err)
+	}
+	if msg, err := c.errorCodec.Decode(bytes.NewReader(body), version); err == nil {
+		return msg, nil
+	}
+	reader := bytes.NewReader(body)
+	var code int32
+	if code, err = primitive.ReadInt(reader); err != nil {
+		return nil, fmt.Errorf("cannot read ERROR code: %w", err)
+	}
+	var errorMsg string
+	if errorMsg, err = primitive.ReadString(reader); err != nil {
+		return nil, fmt.Errorf("cannot read ERROR message: %w", err)
+	}
+	return NewError(primitive.ErrorCode(code), errorMsg), nil
+}
+
+// NewError creates an error message of the type matching the given code, with only its error message set: the fields
+// specific to the error code, if any, are left to their zero values. Unknown error codes yield a ServerError.
+func NewError(code primitive.ErrorCode, errorMsg string) Error {
+	switch code {
+	case primitive.ErrorCodeServerError:
+		return &ServerError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeProtocolError:
+		return &ProtocolError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeAuthenticationError:
+		return &AuthenticationError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeOverloaded:
+		return &Overloaded{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeIsBootstrapping:
+		return &IsBootstrapping{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeTruncateError:
+		return &TruncateError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeSyntaxError:
+		return &SyntaxError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeUnauthorized:
+		return &Unauthorized{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeInvalid:
+		return &Invalid{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeConfigError:
+		return &ConfigError{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeUnavailable:
+		return &Unavailable{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeReadTimeout:
+		return &ReadTimeout{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeWriteTimeout:
+		return &WriteTimeout{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeReadFailure:
+		return &ReadFailure{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeWriteFailure:
+		return &WriteFailure{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeFunctionFailure:
+		return &FunctionFailure{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeAlreadyExists:
+		return &AlreadyExists{ErrorMessage: errorMsg}
+	case primitive.ErrorCodeUnprepared:
+		return &Unprepared{ErrorMessage: errorMsg}
+	}
+	return &ServerError{ErrorMessage: fmt.Sprintf("unknown error code %v: %v", code, errorMsg)}
+}
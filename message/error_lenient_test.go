@@ -0,0 +1,90 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestLenientErrorCodec(t *testing.T) {
+	codec := NewLenientErrorCodec()
+	assert.Equal(t, primitive.OpCodeError, codec.GetOpCode())
+	tests := []struct {
+		name     string
+		input    []byte
+		expected Message
+	}{
+		{
+			"standard read timeout",
+			[]byte{
+				0, 0, 0x12, 0x00, // error code
+				0, 4, B, O, O, M,
+				0, 0x06, // consistency
+				0, 0, 0, 1, // received
+				0, 0, 0, 2, // block for
+				1, // data present
+			},
+			&ReadTimeout{ErrorMessage: "BOOM", Consistency: primitive.ConsistencyLevelLocalQuorum, Received: 1, BlockFor: 2, DataPresent: true},
+		},
+		{
+			"read timeout without specific fields",
+			[]byte{
+				0, 0, 0x12, 0x00, // error code
+				0, 4, B, O, O, M,
+			},
+			&ReadTimeout{ErrorMessage: "BOOM"},
+		},
+		{
+			"unavailable with truncated fields",
+			[]byte{
+				0, 0, 0x10, 0x00, // error code
+				0, 4, B, O, O, M,
+				0, 0x06, // consistency
+				0, 0, // truncated
+			},
+			&Unavailable{ErrorMessage: "BOOM"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := codec.Decode(bytes.NewReader(tt.input), primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, decoded)
+		})
+	}
+	t.Run("unknown error code", func(t *testing.T) {
+		decoded, err := codec.Decode(bytes.NewReader([]byte{0, 0, 0x20, 0x03, 0, 4, B, O, O, M}), primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		require.IsType(t, &ServerError{}, decoded)
+		assert.Contains(t, decoded.(*ServerError).ErrorMessage, "BOOM")
+		assert.Contains(t, decoded.(*ServerError).ErrorMessage, "unknown error code")
+	})
+	t.Run("missing error message", func(t *testing.T) {
+		_, err := codec.Decode(bytes.NewReader([]byte{0, 0, 0x20, 0x03, 0, 4, B}), primitive.ProtocolVersion4)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot read ERROR message")
+	})
+	t.Run("encode is strict", func(t *testing.T) {
+		encoded := &bytes.Buffer{}
+		require.NoError(t, codec.Encode(&Overloaded{ErrorMessage: "BOOM"}, encoded, primitive.ProtocolVersion4))
+		assert.Equal(t, []byte{0, 0, 0x10, 0x01, 0, 4, B, O, O, M}, encoded.Bytes())
+	})
+}
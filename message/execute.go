@@ -66,7 +66,11 @@ func (c *executeCodec) Encode(msg Message, dest io.Writer, version primitive.Pro
 			return fmt.Errorf("cannot write EXECUTE result metadata id: %w", err)
 		}
 	}
-	if err := EncodeQueryOptions(execute.Options, dest, version); err != nil {
+	if !version.SupportsQueryFlags() {
+		if err := encodeLegacyQueryOptions(execute.Options, true, dest, version); err != nil {
+			return fmt.Errorf("cannot write EXECUTE options: %w", err)
+		}
+	} else if err := EncodeQueryOptions(execute.Options, dest, version); err != nil {
 		return fmt.Errorf("cannot write EXECUTE options: %w", err)
 	}
 	return nil
@@ -81,7 +85,13 @@ func (c *executeCodec) EncodedLength(msg Message, version primitive.ProtocolVers
 	if version.SupportsResultMetadataId() {
 		size += primitive.LengthOfShortBytes(execute.ResultMetadataId)
 	}
-	if lengthOfQueryOptions, err := LengthOfQueryOptions(execute.Options, version); err == nil {
+	if !version.SupportsQueryFlags() {
+		if lengthOfQueryOptions, err := lengthOfLegacyQueryOptions(execute.Options, true); err == nil {
+			return size + lengthOfQueryOptions, nil
+		} else {
+			return -1, fmt.Errorf("cannot compute size EXECUTE query options: %w", err)
+		}
+	} else if lengthOfQueryOptions, err := LengthOfQueryOptions(execute.Options, version); err == nil {
 		return size + lengthOfQueryOptions, nil
 	} else {
 		return -1, fmt.Errorf("cannot compute size EXECUTE query options: %w", err)
@@ -89,25 +99,46 @@ func (c *executeCodec) EncodedLength(msg Message, version primitive.ProtocolVers
 }
 
 func (c *executeCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (msg Message, err error) {
-	var execute = &Execute{
-		Options: nil,
+	execute := &Execute{}
+	if err = decodeExecute(execute, source, version); err != nil {
+		return nil, err
+	}
+	return execute, nil
+}
+
+func (c *executeCodec) DecodeInto(msg Message, source io.Reader, version primitive.ProtocolVersion) error {
+	execute, ok := msg.(*Execute)
+	if !ok {
+		return errors.New(fmt.Sprintf("expected *message.Execute, got %T", msg))
 	}
+	return decodeExecute(execute, source, version)
+}
+
+func decodeExecute(execute *Execute, source io.Reader, version primitive.ProtocolVersion) (err error) {
 	if execute.QueryId, err = primitive.ReadShortBytes(source); err != nil {
-		return nil, fmt.Errorf("cannot read EXECUTE query id: %w", err)
+		return fmt.Errorf("cannot read EXECUTE query id: %w", err)
 	} else if len(execute.QueryId) == 0 {
-		return nil, errors.New("EXECUTE missing query id")
+		return errors.New("EXECUTE missing query id")
 	}
+	execute.ResultMetadataId = nil
 	if version.SupportsResultMetadataId() {
 		if execute.ResultMetadataId, err = primitive.ReadShortBytes(source); err != nil {
-			return nil, fmt.Errorf("cannot read EXECUTE result metadata id: %w", err)
+			return fmt.Errorf("cannot read EXECUTE result metadata id: %w", err)
 		} else if len(execute.ResultMetadataId) == 0 {
-			return nil, errors.New("EXECUTE missing result metadata id")
+			return errors.New("EXECUTE missing result metadata id")
 		}
 	}
-	if execute.Options, err = DecodeQueryOptions(source, version); err != nil {
-		return nil, fmt.Errorf("cannot read EXECUTE query options: %w", err)
+	if execute.Options == nil {
+		execute.Options = &QueryOptions{}
 	}
-	return execute, nil
+	if !version.SupportsQueryFlags() {
+		if err = decodeLegacyQueryOptionsInto(execute.Options, true, source, version); err != nil {
+			return fmt.Errorf("cannot read EXECUTE query options: %w", err)
+		}
+	} else if err = decodeQueryOptionsInto(execute.Options, source, version); err != nil {
+		return fmt.Errorf("cannot read EXECUTE query options: %w", err)
+	}
+	return nil
 }
 
 func (c *executeCodec) GetOpCode() primitive.OpCode {
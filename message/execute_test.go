@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -1631,3 +1632,39 @@ func TestExecuteCodec_Decode(t *testing.T) {
 		}
 	})
 }
+
+func TestExecuteCodec_ProtocolVersion1(t *testing.T) {
+	codec := &executeCodec{}
+	execute := &Execute{
+		QueryId: []byte{1, 2, 3, 4},
+		Options: &QueryOptions{
+			Consistency: primitive.ConsistencyLevelQuorum,
+			PositionalValues: []*primitive.Value{
+				{Type: primitive.ValueTypeRegular, Contents: []byte{h, e, l, l, o}},
+				{Type: primitive.ValueTypeNull},
+			},
+		},
+	}
+	expected := []byte{
+		0, 4, 1, 2, 3, 4, // query id
+		0, 2, // values length
+		0, 0, 0, 5, h, e, l, l, o, // value 1
+		0xff, 0xff, 0xff, 0xff, // value 2
+		0, 4, // consistency level, no flags
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(execute, encoded, primitive.ProtocolVersion1))
+	assert.Equal(t, expected, encoded.Bytes())
+	length, err := codec.EncodedLength(execute, primitive.ProtocolVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), length)
+	decoded, err := codec.Decode(bytes.NewReader(expected), primitive.ProtocolVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, execute, decoded)
+
+	// named values cannot be encoded in protocol version 1
+	named := &Execute{QueryId: []byte{1}, Options: &QueryOptions{NamedValues: map[string]*primitive.Value{"k": {}}}}
+	err = codec.Encode(named, &bytes.Buffer{}, primitive.ProtocolVersion1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query options not supported in ProtocolVersion OSS 1")
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// PreparedTableResolver returns the keyspace and table of the statement prepared under the given prepared id, or false
+// if the prepared id is unknown. Proxies typically implement it by recording PREPARE responses, see PreparedTable.
+type PreparedTableResolver func(preparedId []byte) (keyspace string, table string, found bool)
+
+// Fingerprint is a stable key identifying the shape of a request, regardless of its literal and bound values, and
+// suitable for caching, routing, or as a metrics label: since literals are stripped from query strings, the number
+// of distinct fingerprints remains bounded by the number of distinct statements. Fingerprints should be created with
+// NewFingerprint.
+type Fingerprint struct {
+	OpCode primitive.OpCode
+	// Hash is a 64-bit FNV-1a hash of: the normalized query string (see NormalizeQuery) for QUERY and PREPARE requests;
+	// the prepared id for EXECUTE requests; and the batch type and children for BATCH requests. It is zero for other
+	// requests.
+	Hash uint64
+	// Keyspace is the keyspace of the request, if known: the keyspace set on the request, if any, or the keyspace of
+	// the prepared statement for EXECUTE requests.
+	Keyspace string
+	// Table is the table of the prepared statement for EXECUTE requests, if known.
+	Table string
+}
+
+// NewFingerprint computes the fingerprint of the given request. The resolver is used to determine the keyspace and
+// table of EXECUTE requests; it may be nil.
+func NewFingerprint(msg Message, resolver PreparedTableResolver) *Fingerprint {
+	fingerprint := &Fingerprint{OpCode: msg.GetOpCode()}
+	h := fnv.New64a()
+	switch msg := msg.(type) {
+	case *Query:
+		_, _ = h.Write([]byte(NormalizeQuery(msg.Query)))
+		if msg.Options != nil {
+			fingerprint.Keyspace = msg.Options.Keyspace
+		}
+	case *Prepare:
+		_, _ = h.Write([]byte(NormalizeQuery(msg.Query)))
+		fingerprint.Keyspace = msg.Keyspace
+	case *Execute:
+		_, _ = h.Write(msg.QueryId)
+		if resolver != nil {
+			fingerprint.Keyspace, fingerprint.Table, _ = resolver(msg.QueryId)
+		}
+	case *Batch:
+		_, _ = h.Write([]byte{uint8(msg.Type)})
+		for _, child := range msg.Children {
+			writeBatchChild(h, child)
+		}
+		fingerprint.Keyspace = msg.Keyspace
+	default:
+		return fingerprint
+	}
+	fingerprint.Hash = h.Sum64()
+	return fingerprint
+}
+
+// writeBatchChild writes the given batch child to the given hash; children are separated with a zero byte, which
+// cannot appear in query strings, and prefixed with their kind, so that queries and prepared ids cannot collide.
+func writeBatchChild(h hash.Hash64, child *BatchChild) {
+	if child.Id != nil {
+		_, _ = h.Write([]byte{0, 'i'})
+		_, _ = h.Write(child.Id)
+	} else {
+		_, _ = h.Write([]byte{0, 'q'})
+		_, _ = h.Write([]byte(NormalizeQuery(child.Query)))
+	}
+}
+
+// Key returns a textual representation of this fingerprint, suitable as a cache key or metrics label, e.g.
+// "QUERY 9f4c8e2a1b3d5f60", or "EXECUTE 9f4c8e2a1b3d5f60 ks.t" when the keyspace and table are known. Requests without
+// statements are represented by their opcode only, e.g. "OPTIONS".
+func (f *Fingerprint) Key() string {
+	name := strings.TrimPrefix(f.OpCode.String(), "OpCode ")
+	if i := strings.Index(name, " ["); i >= 0 {
+		name = name[:i]
+	}
+	if f.Hash == 0 {
+		return name
+	}
+	key := fmt.Sprintf("%v %016x", name, f.Hash)
+	if f.Table != "" {
+		key += fmt.Sprintf(" %v.%v", f.Keyspace, f.Table)
+	} else if f.Keyspace != "" {
+		key += " " + f.Keyspace
+	}
+	return key
+}
+
+func (f *Fingerprint) String() string {
+	return f.Key()
+}
+
+// PreparedTable returns the keyspace and table of the given prepared statement, as found in the metadata of its bound
+// variables or, if it has none, of its result set columns. Both are empty if the statement has neither, e.g. a
+// statement that is not a SELECT and has no bound variables.
+func PreparedTable(result *PreparedResult) (keyspace string, table string) {
+	if result.VariablesMetadata != nil && len(result.VariablesMetadata.Columns) > 0 {
+		column := result.VariablesMetadata.Columns[0]
+		return column.Keyspace, column.Table
+	} else if result.ResultMetadata != nil && len(result.ResultMetadata.Columns) > 0 {
+		column := result.ResultMetadata.Columns[0]
+		return column.Keyspace, column.Table
+	}
+	return "", ""
+}
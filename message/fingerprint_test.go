@@ -0,0 +1,77 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestNewFingerprint(t *testing.T) {
+	query1 := NewFingerprint(&Query{Query: "SELECT * FROM ks.t WHERE k = 1"}, nil)
+	query2 := NewFingerprint(&Query{Query: "SELECT *   FROM ks.t WHERE k = 2", Options: &QueryOptions{}}, nil)
+	assert.Equal(t, query1, query2)
+	assert.NotZero(t, query1.Hash)
+	assert.Regexp(t, "^QUERY [0-9a-f]{16}$", query1.Key())
+	assert.NotEqual(t, query1.Key(), NewFingerprint(&Query{Query: "SELECT * FROM ks.t2 WHERE k = 1"}, nil).Key())
+
+	prepare := NewFingerprint(&Prepare{Query: "SELECT * FROM t WHERE k = ?", Keyspace: "ks"}, nil)
+	assert.Regexp(t, "^PREPARE [0-9a-f]{16} ks$", prepare.Key())
+
+	resolver := func(preparedId []byte) (string, string, bool) {
+		if string(preparedId) == "\xca\xfe" {
+			return "ks", "t", true
+		}
+		return "", "", false
+	}
+	execute := NewFingerprint(&Execute{QueryId: []byte{0xca, 0xfe}, Options: &QueryOptions{}}, resolver)
+	assert.Equal(t, "ks", execute.Keyspace)
+	assert.Equal(t, "t", execute.Table)
+	assert.Regexp(t, "^EXECUTE [0-9a-f]{16} ks.t$", execute.Key())
+	unknown := NewFingerprint(&Execute{QueryId: []byte{0xba, 0xbe}}, resolver)
+	assert.Regexp(t, "^EXECUTE [0-9a-f]{16}$", unknown.String())
+	assert.Equal(t, execute.Hash, NewFingerprint(&Execute{QueryId: []byte{0xca, 0xfe}}, nil).Hash)
+
+	children := []*BatchChild{{Query: "INSERT INTO ks.t (k) VALUES (1)"}, {Id: []byte{0xca, 0xfe}}}
+	batch1 := NewFingerprint(&Batch{Children: children}, nil)
+	batch2 := NewFingerprint(&Batch{Children: []*BatchChild{{Query: "INSERT INTO ks.t (k) VALUES (2)"}, {Id: []byte{0xca, 0xfe}}}}, nil)
+	assert.Equal(t, batch1, batch2)
+	unlogged := NewFingerprint(&Batch{Type: primitive.BatchTypeUnlogged, Children: children}, nil)
+	assert.NotEqual(t, batch1.Hash, unlogged.Hash)
+
+	options := NewFingerprint(&Options{}, nil)
+	assert.Equal(t, "OPTIONS", options.Key())
+	assert.Zero(t, options.Hash)
+}
+
+func TestPreparedTable(t *testing.T) {
+	column := &ColumnMetadata{Keyspace: "ks", Table: "t", Name: "k", Type: datatype.Int}
+	keyspace, table := PreparedTable(&PreparedResult{VariablesMetadata: &VariablesMetadata{Columns: []*ColumnMetadata{column}}})
+	assert.Equal(t, "ks", keyspace)
+	assert.Equal(t, "t", table)
+	keyspace, table = PreparedTable(&PreparedResult{
+		VariablesMetadata: &VariablesMetadata{},
+		ResultMetadata:    &RowsMetadata{ColumnCount: 1, Columns: []*ColumnMetadata{column}},
+	})
+	assert.Equal(t, "ks", keyspace)
+	assert.Equal(t, "t", table)
+	keyspace, table = PreparedTable(&PreparedResult{VariablesMetadata: &VariablesMetadata{}, ResultMetadata: &RowsMetadata{}})
+	assert.Empty(t, keyspace)
+	assert.Empty(t, table)
+}
@@ -23,6 +23,7 @@ var DefaultMessageCodecs = []Codec{
 	&registerCodec{},
 	&batchCodec{},
 	&authResponseCodec{},
+	&credentialsCodec{},
 	&errorCodec{},
 	&readyCodec{},
 	&authenticateCodec{},
@@ -23,9 +23,20 @@ import (
 type Message interface {
 	IsResponse() bool
 	GetOpCode() primitive.OpCode
+	// DeepCopyMessage returns a deep copy of this message, sharing no mutable state with it.
 	DeepCopyMessage() Message
 }
 
+// Clone returns a deep copy of the given message, sharing no mutable state with it, or nil if the message is nil. It is
+// a thin wrapper around Message.DeepCopyMessage, provided as a function rather than as a Message method so that
+// custom Message implementations need not implement it.
+func Clone(msg Message) Message {
+	if msg == nil {
+		return nil
+	}
+	return msg.DeepCopyMessage()
+}
+
 type Encoder interface {
 	Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) error
 	EncodedLength(msg Message, version primitive.ProtocolVersion) (int, error)
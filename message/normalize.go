@@ -0,0 +1,89 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// NormalizedQuery returns the normalized query string of the given QUERY or PREPARE message, see NormalizeQuery. The
+// returned boolean is false if the message is neither a QUERY nor a PREPARE message.
+func NormalizedQuery(msg Message) (string, bool) {
+	switch msg := msg.(type) {
+	case *Query:
+		return NormalizeQuery(msg.Query), true
+	case *Prepare:
+		return NormalizeQuery(msg.Query), true
+	}
+	return "", false
+}
+
+// NormalizeQuery normalizes the given CQL query string so that it can be safely logged, or used to aggregate
+// statistics per query shape: comments are removed; whitespace is collapsed into single spaces; and string, numeric,
+// blob and UUID literals are replaced with bind markers, e.g. "SELECT * FROM ks.t WHERE k = 'secret'" becomes
+// "SELECT * FROM ks.t WHERE k = ?". Keywords, identifiers, including quoted ones, and bind markers are kept as is.
+// The query is not validated: malformed queries, e.g. with unterminated string literals, are normalized on a best
+// effort basis.
+func NormalizeQuery(query string) string {
+	n := &queryNormalizer{lexer: newCqlLexer(query)}
+	n.normalize()
+	return n.result.String()
+}
+
+type queryNormalizer struct {
+	lexer  *cqlLexer
+	result strings.Builder
+	// space is true if a space must be written before the next token.
+	space bool
+	// last is the last rune written.
+	last rune
+}
+
+func (n *queryNormalizer) normalize() {
+	for token, ok := n.lexer.next(); ok; token, ok = n.lexer.next() {
+		switch token.kind {
+		case cqlTokenSpace, cqlTokenComment:
+			n.space = n.result.Len() > 0
+		case cqlTokenString, cqlTokenNumber, cqlTokenUuid:
+			n.write("?")
+		case cqlTokenPunctuation:
+			if token.text == "-" && n.lexer.isDigit(n.lexer.pos) && n.expectsOperand() {
+				// the sign of a numeric literal
+				n.lexer.next()
+				n.write("?")
+			} else {
+				n.write(token.text)
+			}
+		default:
+			n.write(token.text)
+		}
+	}
+}
+
+func (n *queryNormalizer) write(token string) {
+	if n.space {
+		n.result.WriteByte(' ')
+		n.space = false
+	}
+	n.result.WriteString(token)
+	n.last, _ = utf8.DecodeLastRuneInString(token)
+}
+
+// expectsOperand returns true if a dash can only be the sign of a numeric literal at this point, and not a
+// subtraction.
+func (n *queryNormalizer) expectsOperand() bool {
+	return n.result.Len() == 0 || strings.ContainsRune("(,=<>[{:+-*/%", n.last)
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"whitespace", "  SELECT *\n\tFROM   ks.t  ", "SELECT * FROM ks.t"},
+		{"string literal", "SELECT * FROM ks.t WHERE k = 'it''s secret'", "SELECT * FROM ks.t WHERE k = ?"},
+		{"dollar string literal", "INSERT INTO ks.t (k) VALUES ($$secret$$)", "INSERT INTO ks.t (k) VALUES (?)"},
+		{"numeric literals", "UPDATE ks.t SET a = -1, b = 1.5e-3 WHERE k IN (42,-7)", "UPDATE ks.t SET a = ?, b = ? WHERE k IN (?,?)"},
+		{"blob literal", "SELECT * FROM ks.t WHERE b = 0xCAFEbabe", "SELECT * FROM ks.t WHERE b = ?"},
+		{"uuid literals", "SELECT * FROM ks.t WHERE id IN (123e4567-e89b-12d3-a456-426614174000, a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11)", "SELECT * FROM ks.t WHERE id IN (?, ?)"},
+		{"subtraction", "SELECT a-1 FROM ks.t", "SELECT a-? FROM ks.t"},
+		{"identifiers", `SELECT "Col 1", t1, deadbeef FROM ks.t1 WHERE k = ? AND v = :v`, `SELECT "Col 1", t1, deadbeef FROM ks.t1 WHERE k = ? AND v = :v`},
+		{"comments", "SELECT * -- secret\nFROM /* 'x' */ ks.t // trailing", "SELECT * FROM ks.t"},
+		{"unterminated string literal", "SELECT * FROM ks.t WHERE k = 'oops", "SELECT * FROM ks.t WHERE k = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeQuery(tt.query))
+		})
+	}
+}
+
+func TestNormalizedQuery(t *testing.T) {
+	normalized, ok := NormalizedQuery(&Query{Query: "SELECT * FROM ks.t WHERE k = 1"})
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT * FROM ks.t WHERE k = ?", normalized)
+	normalized, ok = NormalizedQuery(&Prepare{Query: "SELECT *  FROM ks.t WHERE k = ?"})
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT * FROM ks.t WHERE k = ?", normalized)
+	_, ok = NormalizedQuery(&Options{})
+	assert.False(t, ok)
+}
@@ -52,6 +52,12 @@ func (c *queryCodec) Encode(msg Message, dest io.Writer, version primitive.Proto
 	if err := primitive.WriteLongString(query.Query, dest); err != nil {
 		return fmt.Errorf("cannot write QUERY query string: %w", err)
 	}
+	if !version.SupportsQueryFlags() {
+		if err := encodeLegacyQueryOptions(query.Options, false, dest, version); err != nil {
+			return fmt.Errorf("cannot write QUERY options: %w", err)
+		}
+		return nil
+	}
 	if err := EncodeQueryOptions(query.Options, dest, version); err != nil {
 		return fmt.Errorf("cannot write QUERY options: %w", err)
 	}
@@ -64,7 +70,13 @@ func (c *queryCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 		return -1, errors.New(fmt.Sprintf("expected *message.Query, got %T", msg))
 	}
 	lengthOfQuery := primitive.LengthOfLongString(query.Query)
-	lengthOfQueryOptions, err := LengthOfQueryOptions(query.Options, version)
+	var lengthOfQueryOptions int
+	var err error
+	if version.SupportsQueryFlags() {
+		lengthOfQueryOptions, err = LengthOfQueryOptions(query.Options, version)
+	} else {
+		lengthOfQueryOptions, err = lengthOfLegacyQueryOptions(query.Options, false)
+	}
 	if err != nil {
 		return -1, fmt.Errorf("cannot compute size of QUERY message: %w", err)
 	}
@@ -72,13 +84,32 @@ func (c *queryCodec) EncodedLength(msg Message, version primitive.ProtocolVersio
 }
 
 func (c *queryCodec) Decode(source io.Reader, version primitive.ProtocolVersion) (Message, error) {
-	if query, err := primitive.ReadLongString(source); err != nil {
-		return nil, err
-	} else if options, err := DecodeQueryOptions(source, version); err != nil {
+	query := &Query{}
+	if err := decodeQuery(query, source, version); err != nil {
 		return nil, err
-	} else {
-		return &Query{Query: query, Options: options}, nil
 	}
+	return query, nil
+}
+
+func (c *queryCodec) DecodeInto(msg Message, source io.Reader, version primitive.ProtocolVersion) error {
+	query, ok := msg.(*Query)
+	if !ok {
+		return errors.New(fmt.Sprintf("expected *message.Query, got %T", msg))
+	}
+	return decodeQuery(query, source, version)
+}
+
+func decodeQuery(query *Query, source io.Reader, version primitive.ProtocolVersion) (err error) {
+	if query.Query, err = primitive.ReadLongString(source); err != nil {
+		return err
+	}
+	if query.Options == nil {
+		query.Options = &QueryOptions{}
+	}
+	if !version.SupportsQueryFlags() {
+		return decodeLegacyQueryOptionsInto(query.Options, false, source, version)
+	}
+	return decodeQueryOptionsInto(query.Options, source, version)
 }
 
 func (c *queryCodec) GetOpCode() primitive.OpCode {
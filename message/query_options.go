@@ -131,6 +131,83 @@ func (o *QueryOptions) Flags() primitive.QueryFlag {
 	return flags
 }
 
+// encodeLegacyQueryOptions encodes the given options in the layout of protocol version 1, where QUERY messages only
+// carry a consistency level, and EXECUTE messages carry positional values followed by a consistency level; withValues
+// tells whether values are to be encoded. Options that cannot be encoded in that layout are rejected.
+func encodeLegacyQueryOptions(
+	options *QueryOptions,
+	withValues bool,
+	dest io.Writer,
+	version primitive.ProtocolVersion,
+) (err error) {
+	if options == nil {
+		options = &QueryOptions{} // use defaults if nil provided
+	}
+	if err = checkLegacyQueryOptions(options, withValues, version); err != nil {
+		return err
+	} else if withValues {
+		if err = primitive.WritePositionalValues(options.PositionalValues, dest, version); err != nil {
+			return fmt.Errorf("cannot write positional [value]s: %w", err)
+		}
+	}
+	if err = primitive.CheckValidConsistencyLevel(options.Consistency); err != nil {
+		return err
+	} else if err = primitive.WriteShort(uint16(options.Consistency), dest); err != nil {
+		return fmt.Errorf("cannot write consistency: %w", err)
+	}
+	return nil
+}
+
+func checkLegacyQueryOptions(options *QueryOptions, withValues bool, version primitive.ProtocolVersion) error {
+	flags := options.Flags()
+	if withValues {
+		flags = flags.Remove(primitive.QueryFlagValues)
+	}
+	if flags != 0 {
+		return fmt.Errorf("query options not supported in %v: %v", version, flags)
+	}
+	return nil
+}
+
+func lengthOfLegacyQueryOptions(options *QueryOptions, withValues bool) (length int, err error) {
+	length = primitive.LengthOfShort // consistency level
+	if withValues {
+		var values []*primitive.Value
+		if options != nil {
+			values = options.PositionalValues
+		}
+		if s, err := primitive.LengthOfPositionalValues(values); err != nil {
+			return -1, fmt.Errorf("cannot compute length of query options values: %w", err)
+		} else {
+			length += s
+		}
+	}
+	return length, nil
+}
+
+// decodeLegacyQueryOptionsInto decodes query options in the layout of protocol version 1 into the given options,
+// overwriting all their fields, see encodeLegacyQueryOptions.
+func decodeLegacyQueryOptionsInto(
+	options *QueryOptions,
+	withValues bool,
+	source io.Reader,
+	version primitive.ProtocolVersion,
+) (err error) {
+	positionalValues := options.PositionalValues
+	*options = QueryOptions{}
+	if withValues {
+		if options.PositionalValues, err = primitive.ReadPositionalValuesInto(positionalValues, source, version); err != nil {
+			return fmt.Errorf("cannot read [value]s: %w", err)
+		}
+	}
+	var consistency uint16
+	if consistency, err = primitive.ReadShort(source); err != nil {
+		return fmt.Errorf("cannot read consistency: %w", err)
+	}
+	options.Consistency = primitive.ConsistencyLevel(consistency)
+	return primitive.CheckValidConsistencyLevel(options.Consistency)
+}
+
 func EncodeQueryOptions(options *QueryOptions, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	if options == nil {
 		options = &QueryOptions{} // use defaults if nil provided
@@ -256,13 +333,27 @@ func LengthOfQueryOptions(options *QueryOptions, version primitive.ProtocolVersi
 
 func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (options *QueryOptions, err error) {
 	options = &QueryOptions{}
+	if err = decodeQueryOptionsInto(options, source, version); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// decodeQueryOptionsInto decodes query options into the given options, overwriting all their fields. The positional
+// values slice, and the serial consistency, default timestamp and now-in-seconds pointers are reused if present.
+func decodeQueryOptionsInto(options *QueryOptions, source io.Reader, version primitive.ProtocolVersion) (err error) {
+	positionalValues := options.PositionalValues
+	serialConsistency := options.SerialConsistency
+	defaultTimestamp := options.DefaultTimestamp
+	nowInSeconds := options.NowInSeconds
+	*options = QueryOptions{}
 	var consistency uint16
 	if consistency, err = primitive.ReadShort(source); err != nil {
-		return nil, fmt.Errorf("cannot read consistency: %w", err)
+		return fmt.Errorf("cannot read consistency: %w", err)
 	}
 	options.Consistency = primitive.ConsistencyLevel(consistency)
 	if err = primitive.CheckValidConsistencyLevel(options.Consistency); err != nil {
-		return nil, err
+		return err
 	}
 	var flags primitive.QueryFlag
 	if version.Uses4BytesQueryFlags() {
@@ -275,22 +366,22 @@ func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (op
 		flags = primitive.QueryFlag(f)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot read flags: %w", err)
+		return fmt.Errorf("cannot read flags: %w", err)
 	}
 	if flags.Contains(primitive.QueryFlagValues) {
 		if flags.Contains(primitive.QueryFlagValueNames) {
 			options.NamedValues, err = primitive.ReadNamedValues(source, version)
 		} else {
-			options.PositionalValues, err = primitive.ReadPositionalValues(source, version)
+			options.PositionalValues, err = primitive.ReadPositionalValuesInto(positionalValues, source, version)
 		}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot read [value]s: %w", err)
+		return fmt.Errorf("cannot read [value]s: %w", err)
 	}
 	options.SkipMetadata = flags.Contains(primitive.QueryFlagSkipMetadata)
 	if flags.Contains(primitive.QueryFlagPageSize) {
 		if options.PageSize, err = primitive.ReadInt(source); err != nil {
-			return nil, fmt.Errorf("cannot read page size: %w", err)
+			return fmt.Errorf("cannot read page size: %w", err)
 		}
 		if flags.Contains(primitive.QueryFlagDsePageSizeBytes) {
 			options.PageSizeInBytes = true
@@ -298,43 +389,51 @@ func DecodeQueryOptions(source io.Reader, version primitive.ProtocolVersion) (op
 	}
 	if flags.Contains(primitive.QueryFlagPagingState) {
 		if options.PagingState, err = primitive.ReadBytes(source); err != nil {
-			return nil, fmt.Errorf("cannot read paging state: %w", err)
+			return fmt.Errorf("cannot read paging state: %w", err)
 		}
 	}
 	if flags.Contains(primitive.QueryFlagSerialConsistency) {
 		var optionsSerialConsistencyUint uint16
 		if optionsSerialConsistencyUint, err = primitive.ReadShort(source); err != nil {
-			return nil, fmt.Errorf("cannot read serial consistency: %w", err)
+			return fmt.Errorf("cannot read serial consistency: %w", err)
 		}
 		optionsSerialConsistency := primitive.ConsistencyLevel(optionsSerialConsistencyUint)
 		if err = primitive.CheckValidConsistencyLevel(optionsSerialConsistency); err != nil {
-			return nil, err
+			return err
 		}
-		options.SerialConsistency = &optionsSerialConsistency
+		if serialConsistency == nil {
+			serialConsistency = new(primitive.ConsistencyLevel)
+		}
+		*serialConsistency = optionsSerialConsistency
+		options.SerialConsistency = serialConsistency
 	}
 	if flags.Contains(primitive.QueryFlagDefaultTimestamp) {
-		var optionsDefaultTimestamp int64
-		if optionsDefaultTimestamp, err = primitive.ReadLong(source); err != nil {
-			return nil, fmt.Errorf("cannot read default timestamp: %w", err)
+		if defaultTimestamp == nil {
+			defaultTimestamp = new(int64)
+		}
+		if *defaultTimestamp, err = primitive.ReadLong(source); err != nil {
+			return fmt.Errorf("cannot read default timestamp: %w", err)
 		}
-		options.DefaultTimestamp = &optionsDefaultTimestamp
+		options.DefaultTimestamp = defaultTimestamp
 	}
 	if flags.Contains(primitive.QueryFlagWithKeyspace) {
 		if options.Keyspace, err = primitive.ReadString(source); err != nil {
-			return nil, fmt.Errorf("cannot read keyspace: %w", err)
+			return fmt.Errorf("cannot read keyspace: %w", err)
 		}
 	}
 	if flags.Contains(primitive.QueryFlagNowInSeconds) {
-		var optionsNowInSeconds int32
-		if optionsNowInSeconds, err = primitive.ReadInt(source); err != nil {
-			return nil, fmt.Errorf("cannot read now-in-seconds: %w", err)
+		if nowInSeconds == nil {
+			nowInSeconds = new(int32)
+		}
+		if *nowInSeconds, err = primitive.ReadInt(source); err != nil {
+			return fmt.Errorf("cannot read now-in-seconds: %w", err)
 		}
-		options.NowInSeconds = &optionsNowInSeconds
+		options.NowInSeconds = nowInSeconds
 	}
 	if flags.Contains(primitive.QueryFlagDseWithContinuousPagingOptions) {
 		if options.ContinuousPagingOptions, err = DecodeContinuousPagingOptions(source, version); err != nil {
-			return nil, fmt.Errorf("cannot read continuous paging options: %w", err)
+			return fmt.Errorf("cannot read continuous paging options: %w", err)
 		}
 	}
-	return options, nil
+	return nil
 }
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -1956,3 +1957,27 @@ func TestQueryCodec_Decode(t *testing.T) {
 		}
 	})
 }
+
+func TestQueryCodec_ProtocolVersion1(t *testing.T) {
+	codec := &queryCodec{}
+	query := &Query{Query: "SELECT", Options: &QueryOptions{Consistency: primitive.ConsistencyLevelQuorum}}
+	expected := []byte{
+		0, 0, 0, 6, S, E, L, E, C, T,
+		0, 4, // consistency level, no flags
+	}
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(query, encoded, primitive.ProtocolVersion1))
+	assert.Equal(t, expected, encoded.Bytes())
+	length, err := codec.EncodedLength(query, primitive.ProtocolVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), length)
+	decoded, err := codec.Decode(bytes.NewReader(expected), primitive.ProtocolVersion1)
+	require.NoError(t, err)
+	assert.Equal(t, query, decoded)
+
+	// options other than the consistency level cannot be encoded in protocol version 1
+	paged := &Query{Query: "SELECT", Options: &QueryOptions{PageSize: 100}}
+	err = codec.Encode(paged, &bytes.Buffer{}, primitive.ProtocolVersion1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query options not supported in ProtocolVersion OSS 1")
+}
@@ -184,7 +184,22 @@ func (m *RowsResult) String() string {
 
 // CODEC
 
-type resultCodec struct{}
+type resultCodec struct {
+	// interner, if not nil, is used to intern the keyspace, table and column names of decoded metadata.
+	interner *StringInterner
+}
+
+// NewInterningResultCodec returns a Codec for RESULT messages that interns the keyspace, table and column names found
+// in Rows and Prepared metadata with the given interner, instead of allocating new strings for every decoded result;
+// if the interner is nil, a new one is created with DefaultStringInternerMaxSize. This is useful when paging through
+// large result sets, or when executing the same prepared statements many times. Register it in a frame codec to
+// replace the default RESULT codec, e.g. frame.NewClientCodec(message.NewInterningResultCodec(nil)).
+func NewInterningResultCodec(interner *StringInterner) Codec {
+	if interner == nil {
+		interner = NewStringInterner(DefaultStringInternerMaxSize)
+	}
+	return &resultCodec{interner: interner}
+}
 
 func (c *resultCodec) Encode(msg Message, dest io.Writer, version primitive.ProtocolVersion) (err error) {
 	result, ok := msg.(Result)
@@ -296,7 +311,9 @@ func (c *resultCodec) Encode(msg Message, dest io.Writer, version primitive.Prot
 		if err = encodeVariablesMetadata(p.VariablesMetadata, dest, version); err != nil {
 			return fmt.Errorf("cannot write RESULT Prepared variables metadata: %w", err)
 		}
-		if err = encodeRowsMetadata(p.ResultMetadata, dest, version); err != nil {
+		if !version.SupportsPreparedResultMetadata() {
+			break
+		} else if err = encodeRowsMetadata(p.ResultMetadata, dest, version); err != nil {
 			return fmt.Errorf("cannot write RESULT Prepared result metadata: %w", err)
 		}
 	case primitive.ResultTypeRows:
@@ -380,7 +397,9 @@ func (c *resultCodec) EncodedLength(msg Message, version primitive.ProtocolVersi
 		} else {
 			length += lengthOfMetadata
 		}
-		if lengthOfMetadata, err := lengthOfRowsMetadata(p.ResultMetadata, version); err != nil {
+		if !version.SupportsPreparedResultMetadata() {
+			break
+		} else if lengthOfMetadata, err := lengthOfRowsMetadata(p.ResultMetadata, version); err != nil {
 			return -1, fmt.Errorf("cannot compute length of RESULT Prepared result metadata: %w", err)
 		} else {
 			length += lengthOfMetadata
@@ -416,107 +435,152 @@ func (c *resultCodec) Decode(source io.Reader, version primitive.ProtocolVersion
 	if resultType, err = primitive.ReadInt(source); err != nil {
 		return nil, fmt.Errorf("cannot read RESULT type: %w", err)
 	}
+	var result Result
 	switch primitive.ResultType(resultType) {
 	case primitive.ResultTypeVoid:
-		return &VoidResult{}, nil
+		result = &VoidResult{}
 	case primitive.ResultTypeSetKeyspace:
-		setKeyspace := &SetKeyspaceResult{}
-		if setKeyspace.Keyspace, err = primitive.ReadString(source); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT SetKeyspaceResult.Keyspace: %w", err)
-		}
-		return setKeyspace, nil
+		result = &SetKeyspaceResult{}
 	case primitive.ResultTypeSchemaChange:
-		sc := &SchemaChangeResult{}
-		var changeType string
-		if changeType, err = primitive.ReadString(source); err != nil {
-			return nil, fmt.Errorf("cannot read SchemaChangeResult.ChangeType: %w", err)
-		}
-		sc.ChangeType = primitive.SchemaChangeType(changeType)
-		if version >= primitive.ProtocolVersion3 {
-			var target string
-			if target, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeResult.Target: %w", err)
-			}
-			sc.Target = primitive.SchemaChangeTarget(target)
-			if err = primitive.CheckValidSchemaChangeTarget(sc.Target, version); err != nil {
-				return nil, err
-			}
-			if sc.Keyspace, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeResult.Keyspace: %w", err)
-			}
-			switch sc.Target {
-			case primitive.SchemaChangeTargetKeyspace:
-			case primitive.SchemaChangeTargetTable:
-				fallthrough
-			case primitive.SchemaChangeTargetType:
-				if sc.Object, err = primitive.ReadString(source); err != nil {
-					return nil, fmt.Errorf("cannot read SchemaChangeResult.Object: %w", err)
-				}
-			case primitive.SchemaChangeTargetAggregate:
-				fallthrough
-			case primitive.SchemaChangeTargetFunction:
-				if sc.Object, err = primitive.ReadString(source); err != nil {
-					return nil, fmt.Errorf("cannot read SchemaChangeResult.Object: %w", err)
-				}
-				if sc.Arguments, err = primitive.ReadStringList(source); err != nil {
-					return nil, fmt.Errorf("cannot read SchemaChangeResult.Arguments: %w", err)
-				}
-			default:
-				return nil, fmt.Errorf("unknown schema change target: %v", sc.Target)
-			}
-		} else {
-			if sc.Keyspace, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeEvent.Keyspace: %w", err)
-			}
-			if sc.Object, err = primitive.ReadString(source); err != nil {
-				return nil, fmt.Errorf("cannot read SchemaChangeEvent.Object: %w", err)
-			}
-			if sc.Object == "" {
-				sc.Target = primitive.SchemaChangeTargetKeyspace
-			} else {
-				sc.Target = primitive.SchemaChangeTargetTable
-			}
-		}
-		return sc, nil
+		result = &SchemaChangeResult{}
 	case primitive.ResultTypePrepared:
-		p := &PreparedResult{}
-		if p.PreparedQueryId, err = primitive.ReadShortBytes(source); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT Prepared prepared query id: %w", err)
+		result = &PreparedResult{}
+	case primitive.ResultTypeRows:
+		result = &RowsResult{}
+	default:
+		return nil, fmt.Errorf("unknown RESULT type: %v", resultType)
+	}
+	if err = c.decodeResult(result, source, version); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DecodeInto decodes a RESULT message into the given message, which must be of the type matching the encoded result
+// type, e.g. *RowsResult for a Rows result.
+func (c *resultCodec) DecodeInto(msg Message, source io.Reader, version primitive.ProtocolVersion) error {
+	resultType, err := primitive.ReadInt(source)
+	if err != nil {
+		return fmt.Errorf("cannot read RESULT type: %w", err)
+	}
+	result, ok := msg.(Result)
+	if !ok || result.GetResultType() != primitive.ResultType(resultType) {
+		return fmt.Errorf("cannot decode RESULT %v into %T", primitive.ResultType(resultType), msg)
+	}
+	return c.decodeResult(result, source, version)
+}
+
+// decodeResult decodes the contents of a RESULT message, after its result type, into the given result, overwriting
+// all its fields. The rows of a Rows result are reused if present.
+func (c *resultCodec) decodeResult(result Result, source io.Reader, version primitive.ProtocolVersion) (err error) {
+	switch r := result.(type) {
+	case *VoidResult:
+	case *SetKeyspaceResult:
+		if r.Keyspace, err = primitive.ReadString(source); err != nil {
+			return fmt.Errorf("cannot read RESULT SetKeyspaceResult.Keyspace: %w", err)
+		}
+	case *SchemaChangeResult:
+		*r = SchemaChangeResult{}
+		return decodeSchemaChangeResult(r, source, version)
+	case *PreparedResult:
+		*r = PreparedResult{}
+		if r.PreparedQueryId, err = primitive.ReadShortBytes(source); err != nil {
+			return fmt.Errorf("cannot read RESULT Prepared prepared query id: %w", err)
 		}
 		if version.SupportsResultMetadataId() {
-			if p.ResultMetadataId, err = primitive.ReadShortBytes(source); err != nil {
-				return nil, fmt.Errorf("cannot read RESULT Prepared result metadata id: %w", err)
+			if r.ResultMetadataId, err = primitive.ReadShortBytes(source); err != nil {
+				return fmt.Errorf("cannot read RESULT Prepared result metadata id: %w", err)
 			}
 		}
-		if p.VariablesMetadata, err = decodeVariablesMetadata(source, version); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT Prepared variables metadata: %w", err)
+		if r.VariablesMetadata, err = decodeVariablesMetadata(source, version, c.interner); err != nil {
+			return fmt.Errorf("cannot read RESULT Prepared variables metadata: %w", err)
 		}
-		if p.ResultMetadata, err = decodeRowsMetadata(source, version); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT Prepared result metadata: %w", err)
+		if !version.SupportsPreparedResultMetadata() {
+			break
+		} else if r.ResultMetadata, err = decodeRowsMetadata(source, version, c.interner); err != nil {
+			return fmt.Errorf("cannot read RESULT Prepared result metadata: %w", err)
 		}
-		return p, nil
-	case primitive.ResultTypeRows:
-		rows := &RowsResult{}
-		if rows.Metadata, err = decodeRowsMetadata(source, version); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT Rows metadata: %w", err)
+	case *RowsResult:
+		data := r.Data
+		*r = RowsResult{}
+		if r.Metadata, err = decodeRowsMetadata(source, version, c.interner); err != nil {
+			return fmt.Errorf("cannot read RESULT Rows metadata: %w", err)
 		}
 		var rowsCount int32
 		if rowsCount, err = primitive.ReadInt(source); err != nil {
-			return nil, fmt.Errorf("cannot read RESULT Rows data length: %w", err)
-		}
-		rows.Data = make(RowSet, rowsCount)
-		for i := 0; i < int(rowsCount); i++ {
-			rows.Data[i] = make(Row, rows.Metadata.ColumnCount)
-			for j := 0; j < int(rows.Metadata.ColumnCount); j++ {
-				if rows.Data[i][j], err = primitive.ReadBytes(source); err != nil {
-					return nil, fmt.Errorf("cannot read RESULT Rows data row %d col %d: %w", i, j, err)
-				}
+			return fmt.Errorf("cannot read RESULT Rows data length: %w", err)
+		}
+		if data == nil || cap(data) < int(rowsCount) {
+			grown := make(RowSet, rowsCount)
+			copy(grown, data[:cap(data)])
+			data = grown
+		} else {
+			data = data[:rowsCount]
+		}
+		for i := range data {
+			if data[i], err = decodeRowInto(data[i], source, int(r.Metadata.ColumnCount)); err != nil {
+				return fmt.Errorf("cannot read RESULT Rows data row %d %w", i, err)
 			}
 		}
-		return rows, nil
+		r.Data = data
 	default:
-		return nil, fmt.Errorf("unknown RESULT type: %v", resultType)
+		return fmt.Errorf("unknown RESULT type: %v", result.GetResultType())
+	}
+	return nil
+}
+
+func decodeSchemaChangeResult(sc *SchemaChangeResult, source io.Reader, version primitive.ProtocolVersion) (err error) {
+	var changeType string
+	if changeType, err = primitive.ReadString(source); err != nil {
+		return fmt.Errorf("cannot read SchemaChangeResult.ChangeType: %w", err)
 	}
+	sc.ChangeType = primitive.SchemaChangeType(changeType)
+	if version >= primitive.ProtocolVersion3 {
+		var target string
+		if target, err = primitive.ReadString(source); err != nil {
+			return fmt.Errorf("cannot read SchemaChangeResult.Target: %w", err)
+		}
+		sc.Target = primitive.SchemaChangeTarget(target)
+		if err = primitive.CheckValidSchemaChangeTarget(sc.Target, version); err != nil {
+			return err
+		}
+		if sc.Keyspace, err = primitive.ReadString(source); err != nil {
+			return fmt.Errorf("cannot read SchemaChangeResult.Keyspace: %w", err)
+		}
+		switch sc.Target {
+		case primitive.SchemaChangeTargetKeyspace:
+		case primitive.SchemaChangeTargetTable:
+			fallthrough
+		case primitive.SchemaChangeTargetType:
+			if sc.Object, err = primitive.ReadString(source); err != nil {
+				return fmt.Errorf("cannot read SchemaChangeResult.Object: %w", err)
+			}
+		case primitive.SchemaChangeTargetAggregate:
+			fallthrough
+		case primitive.SchemaChangeTargetFunction:
+			if sc.Object, err = primitive.ReadString(source); err != nil {
+				return fmt.Errorf("cannot read SchemaChangeResult.Object: %w", err)
+			}
+			if sc.Arguments, err = primitive.ReadStringList(source); err != nil {
+				return fmt.Errorf("cannot read SchemaChangeResult.Arguments: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown schema change target: %v", sc.Target)
+		}
+	} else {
+		if sc.Keyspace, err = primitive.ReadString(source); err != nil {
+			return fmt.Errorf("cannot read SchemaChangeEvent.Keyspace: %w", err)
+		}
+		if sc.Object, err = primitive.ReadString(source); err != nil {
+			return fmt.Errorf("cannot read SchemaChangeEvent.Object: %w", err)
+		}
+		if sc.Object == "" {
+			sc.Target = primitive.SchemaChangeTargetKeyspace
+		} else {
+			sc.Target = primitive.SchemaChangeTargetTable
+		}
+	}
+	return nil
 }
 
 func (c *resultCodec) GetOpCode() primitive.OpCode {
@@ -140,7 +140,7 @@ func lengthOfVariablesMetadata(metadata *VariablesMetadata, version primitive.Pr
 	return length, nil
 }
 
-func decodeVariablesMetadata(source io.Reader, version primitive.ProtocolVersion) (metadata *VariablesMetadata, err error) {
+func decodeVariablesMetadata(source io.Reader, version primitive.ProtocolVersion, interner *StringInterner) (metadata *VariablesMetadata, err error) {
 	metadata = &VariablesMetadata{}
 	var f int32
 	if f, err = primitive.ReadInt(source); err != nil {
@@ -167,7 +167,7 @@ func decodeVariablesMetadata(source io.Reader, version primitive.ProtocolVersion
 	}
 	if columnCount > 0 {
 		globalTableSpec := flags.Contains(primitive.VariablesFlagGlobalTablesSpec)
-		if metadata.Columns, err = decodeColumnsMetadata(globalTableSpec, columnCount, source, version); err != nil {
+		if metadata.Columns, err = decodeColumnsMetadata(globalTableSpec, columnCount, source, version, interner); err != nil {
 			return nil, fmt.Errorf("cannot read RESULT Prepared variables metadata column cols: %w", err)
 		}
 	}
@@ -244,7 +244,7 @@ func lengthOfRowsMetadata(metadata *RowsMetadata, version primitive.ProtocolVers
 	return length, nil
 }
 
-func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion) (metadata *RowsMetadata, err error) {
+func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion, interner *StringInterner) (metadata *RowsMetadata, err error) {
 	metadata = &RowsMetadata{}
 	var f int32
 	if f, err = primitive.ReadInt(source); err != nil {
@@ -272,7 +272,7 @@ func decodeRowsMetadata(source io.Reader, version primitive.ProtocolVersion) (me
 	}
 	if flags&primitive.RowsFlagNoMetadata == 0 {
 		globalTableSpec := flags.Contains(primitive.RowsFlagGlobalTablesSpec)
-		if metadata.Columns, err = decodeColumnsMetadata(globalTableSpec, metadata.ColumnCount, source, version); err != nil {
+		if metadata.Columns, err = decodeColumnsMetadata(globalTableSpec, metadata.ColumnCount, source, version, interner); err != nil {
 			return nil, fmt.Errorf("cannot read RESULT Rows metadata column cols: %w", err)
 		}
 	}
@@ -329,14 +329,20 @@ func lengthOfColumnsMetadata(globalTableSpec bool, cols []*ColumnMetadata, versi
 	return
 }
 
-func decodeColumnsMetadata(globalTableSpec bool, columnCount int32, source io.Reader, version primitive.ProtocolVersion) (cols []*ColumnMetadata, err error) {
+func decodeColumnsMetadata(
+	globalTableSpec bool,
+	columnCount int32,
+	source io.Reader,
+	version primitive.ProtocolVersion,
+	interner *StringInterner,
+) (cols []*ColumnMetadata, err error) {
 	var globalKsName string
 	var globalTableName string
 	if globalTableSpec {
-		if globalKsName, err = primitive.ReadString(source); err != nil {
+		if globalKsName, err = readMetadataString(source, interner); err != nil {
 			return nil, fmt.Errorf("cannot read column col global keyspace: %w", err)
 		}
-		if globalTableName, err = primitive.ReadString(source); err != nil {
+		if globalTableName, err = readMetadataString(source, interner); err != nil {
 			return nil, fmt.Errorf("cannot read column col global table: %w", err)
 		}
 	}
@@ -346,18 +352,18 @@ func decodeColumnsMetadata(globalTableSpec bool, columnCount int32, source io.Re
 		if globalTableSpec {
 			cols[i].Keyspace = globalKsName
 		} else {
-			if cols[i].Keyspace, err = primitive.ReadString(source); err != nil {
+			if cols[i].Keyspace, err = readMetadataString(source, interner); err != nil {
 				return nil, fmt.Errorf("cannot read column col %d keyspace: %w", i, err)
 			}
 		}
 		if globalTableSpec {
 			cols[i].Table = globalTableName
 		} else {
-			if cols[i].Table, err = primitive.ReadString(source); err != nil {
+			if cols[i].Table, err = readMetadataString(source, interner); err != nil {
 				return nil, fmt.Errorf("cannot read column col %d table: %w", i, err)
 			}
 		}
-		if cols[i].Name, err = primitive.ReadString(source); err != nil {
+		if cols[i].Name, err = readMetadataString(source, interner); err != nil {
 			return nil, fmt.Errorf("cannot read column col %d name: %w", i, err)
 		}
 		if cols[i].Type, err = datatype.ReadDataType(source, version); err != nil {
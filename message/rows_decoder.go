@@ -0,0 +1,196 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// ErrStopIteration can be returned by a RowsDecoder.ForEachRow callback to stop the iteration early without failing.
+var ErrStopIteration = errors.New("stop iteration")
+
+// RowsDecoder decodes a RESULT Rows message incrementally: the rows metadata is decoded upfront, then rows are decoded
+// one at a time, directly from the source, without materializing the entire message in memory. This is useful when
+// dealing with very large result pages.
+//
+// A RowsDecoder is not safe for concurrent use.
+type RowsDecoder struct {
+	source    io.Reader
+	version   primitive.ProtocolVersion
+	metadata  *RowsMetadata
+	rowsCount int
+	rowsRead  int
+}
+
+// NewRowsDecoder creates a new RowsDecoder. The source must be positioned at the beginning of a RESULT message, see
+// for example frame.RawDecoder.DecodeBodyPrefix. This function reads the result type, which must be Rows, the rows
+// metadata and the rows count, leaving the rows unread.
+func NewRowsDecoder(source io.Reader, version primitive.ProtocolVersion) (*RowsDecoder, error) {
+	return NewRowsDecoderWithMetadata(source, version, nil)
+}
+
+// NewRowsDecoderWithMetadata is similar to NewRowsDecoder, but attaches the given cached metadata, typically the
+// result metadata of the PreparedResult of the statement being executed, to pages decoded with the NO_METADATA flag
+// set, i.e. pages of statements executed with QueryOptions.SkipMetadata. Such pages only carry their column count and
+// paging state, so no column metadata is decoded at all; instead, the metadata returned by Metadata shares the columns
+// of the cached metadata, which must not be modified. An error is returned if the column count of the page does not
+// match the cached metadata. Pages that carry their own column metadata are decoded normally, and their metadata takes
+// precedence over the cached one. If the cached metadata is nil, this function behaves exactly like NewRowsDecoder.
+func NewRowsDecoderWithMetadata(source io.Reader, version primitive.ProtocolVersion, cached *RowsMetadata) (*RowsDecoder, error) {
+	if resultType, err := primitive.ReadInt(source); err != nil {
+		return nil, fmt.Errorf("cannot read RESULT type: %w", err)
+	} else if primitive.ResultType(resultType) != primitive.ResultTypeRows {
+		return nil, fmt.Errorf("expected RESULT type %v, got: %v", primitive.ResultTypeRows, primitive.ResultType(resultType))
+	} else if metadata, err := decodeRowsMetadata(source, version, nil); err != nil {
+		return nil, fmt.Errorf("cannot read RESULT Rows metadata: %w", err)
+	} else if metadata, err = attachCachedMetadata(metadata, cached); err != nil {
+		return nil, err
+	} else if rowsCount, err := primitive.ReadInt(source); err != nil {
+		return nil, fmt.Errorf("cannot read RESULT Rows data length: %w", err)
+	} else if rowsCount < 0 {
+		return nil, fmt.Errorf("invalid RESULT Rows data length: %d", rowsCount)
+	} else {
+		return &RowsDecoder{
+			source:    source,
+			version:   version,
+			metadata:  metadata,
+			rowsCount: int(rowsCount),
+		}, nil
+	}
+}
+
+// attachCachedMetadata returns the given page metadata, completed with the columns of the cached metadata if the page
+// metadata has none.
+func attachCachedMetadata(metadata *RowsMetadata, cached *RowsMetadata) (*RowsMetadata, error) {
+	if cached == nil || metadata.Columns != nil {
+		return metadata, nil
+	}
+	cachedCount := cached.ColumnCount
+	if cached.Columns != nil {
+		cachedCount = int32(len(cached.Columns))
+	}
+	if metadata.ColumnCount != cachedCount {
+		return nil, fmt.Errorf(
+			"cannot attach cached RESULT Rows metadata: expected %d columns, got %d",
+			cachedCount,
+			metadata.ColumnCount,
+		)
+	}
+	metadata.Columns = cached.Columns
+	return metadata, nil
+}
+
+// Metadata returns the rows metadata.
+func (d *RowsDecoder) Metadata() *RowsMetadata {
+	return d.metadata
+}
+
+// RowsCount returns the total number of rows in the message.
+func (d *RowsDecoder) RowsCount() int {
+	return d.rowsCount
+}
+
+// Remaining returns the number of rows not yet decoded nor skipped.
+func (d *RowsDecoder) Remaining() int {
+	return d.rowsCount - d.rowsRead
+}
+
+// HasNext returns true if there are more rows to decode.
+func (d *RowsDecoder) HasNext() bool {
+	return d.rowsRead < d.rowsCount
+}
+
+// Next decodes the next row. It returns io.EOF if there are no more rows to decode.
+func (d *RowsDecoder) Next() (Row, error) {
+	if !d.HasNext() {
+		return nil, io.EOF
+	}
+	row, err := decodeRow(d.source, int(d.metadata.ColumnCount))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read RESULT Rows data row %d %w", d.rowsRead, err)
+	}
+	d.rowsRead++
+	return row, nil
+}
+
+// Discard skips all the remaining rows, without decoding them. After this method returns successfully, the source is
+// positioned right after the end of the message.
+func (d *RowsDecoder) Discard() error {
+	for d.HasNext() {
+		for j := 0; j < int(d.metadata.ColumnCount); j++ {
+			if length, err := primitive.ReadInt(d.source); err != nil {
+				return fmt.Errorf("cannot discard RESULT Rows data row %d col %d: %w", d.rowsRead, j, err)
+			} else if length > 0 {
+				if _, err := io.CopyN(ioutil.Discard, d.source, int64(length)); err != nil {
+					return fmt.Errorf("cannot discard RESULT Rows data row %d col %d: %w", d.rowsRead, j, err)
+				}
+			}
+		}
+		d.rowsRead++
+	}
+	return nil
+}
+
+// ForEachRow decodes the remaining rows one at a time and invokes the given callback for each of them; the columns
+// passed to the callback are the columns of the rows metadata, which may be nil if the NO_METADATA flag was set and no
+// cached metadata was attached, see NewRowsDecoderWithMetadata.
+//
+// If the callback returns an error, the iteration stops and the rows that were not yet decoded are discarded, so that
+// the source is always positioned right after the end of the message when this method returns, unless reading from
+// the source itself fails. The error returned by the callback is then returned by this method, except ErrStopIteration,
+// which stops the iteration without failing.
+func (d *RowsDecoder) ForEachRow(callback func(columns []*ColumnMetadata, cells Row) error) error {
+	for d.HasNext() {
+		row, err := d.Next()
+		if err != nil {
+			return err
+		}
+		if err = callback(d.metadata.Columns, row); err != nil {
+			discardErr := d.Discard()
+			if err == ErrStopIteration {
+				return discardErr
+			} else if discardErr != nil {
+				return fmt.Errorf("%w (additionally: %v)", err, discardErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeRow(source io.Reader, columnCount int) (row Row, err error) {
+	return decodeRowInto(nil, source, columnCount)
+}
+
+// decodeRowInto is similar to decodeRow, but reuses the given row if its capacity allows.
+func decodeRowInto(row Row, source io.Reader, columnCount int) (Row, error) {
+	if row == nil || cap(row) < columnCount {
+		row = make(Row, columnCount)
+	} else {
+		row = row[:columnCount]
+	}
+	var err error
+	for j := 0; j < columnCount; j++ {
+		if row[j], err = primitive.ReadBytes(source); err != nil {
+			return nil, fmt.Errorf("col %d: %w", j, err)
+		}
+	}
+	return row, nil
+}
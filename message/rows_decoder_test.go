@@ -0,0 +1,191 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func newRowsDecoderTestResult() *RowsResult {
+	return &RowsResult{
+		Metadata: &RowsMetadata{
+			ColumnCount: 2,
+			Columns: []*ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "col1", Index: 0, Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "col2", Index: 1, Type: datatype.Varchar},
+			},
+		},
+		Data: RowSet{
+			{{0, 0, 0, 1}, []byte("a")},
+			{{0, 0, 0, 2}, nil},
+			{{0, 0, 0, 3}, []byte{}},
+		},
+	}
+}
+
+func TestRowsDecoder(t *testing.T) {
+	codec := &resultCodec{}
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			rows := newRowsDecoderTestResult()
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.Encode(rows, encoded, version))
+			expected, err := codec.Decode(bytes.NewReader(encoded.Bytes()), version)
+			require.NoError(t, err)
+			encoded.Write([]byte{0xca, 0xfe}) // trailing bytes
+
+			t.Run("next", func(t *testing.T) {
+				source := bytes.NewReader(encoded.Bytes())
+				decoder, err := NewRowsDecoder(source, version)
+				require.NoError(t, err)
+				assert.Equal(t, expected.(*RowsResult).Metadata, decoder.Metadata())
+				assert.Equal(t, 3, decoder.RowsCount())
+				var decoded RowSet
+				for decoder.HasNext() {
+					row, err := decoder.Next()
+					require.NoError(t, err)
+					decoded = append(decoded, row)
+				}
+				assert.Equal(t, rows.Data, decoded)
+				assert.Equal(t, 0, decoder.Remaining())
+				_, err = decoder.Next()
+				assert.Equal(t, io.EOF, err)
+				assert.Equal(t, 2, source.Len())
+			})
+
+			t.Run("discard", func(t *testing.T) {
+				source := bytes.NewReader(encoded.Bytes())
+				decoder, err := NewRowsDecoder(source, version)
+				require.NoError(t, err)
+				row, err := decoder.Next()
+				require.NoError(t, err)
+				assert.Equal(t, rows.Data[0], row)
+				assert.Equal(t, 2, decoder.Remaining())
+				require.NoError(t, decoder.Discard())
+				assert.Equal(t, 0, decoder.Remaining())
+				assert.Equal(t, 2, source.Len())
+			})
+		})
+	}
+}
+
+func TestNewRowsDecoder_WrongResultType(t *testing.T) {
+	encoded := &bytes.Buffer{}
+	require.NoError(t, (&resultCodec{}).Encode(&VoidResult{}, encoded, primitive.ProtocolVersion4))
+	_, err := NewRowsDecoder(encoded, primitive.ProtocolVersion4)
+	assert.EqualError(t, err, "expected RESULT type ResultType Rows [0x00000002], got: ResultType Void [0x00000001]")
+}
+
+func TestRowsDecoder_ForEachRow(t *testing.T) {
+	codec := &resultCodec{}
+	rows := newRowsDecoderTestResult()
+	encoded := &bytes.Buffer{}
+	require.NoError(t, codec.Encode(rows, encoded, primitive.ProtocolVersion4))
+	encoded.Write([]byte{0xca, 0xfe}) // trailing bytes
+	callbackErr := errors.New("callback failed")
+	tests := []struct {
+		name      string
+		stopAfter int
+		stopErr   error
+		wantRows  int
+		wantErr   error
+	}{
+		{"all rows", -1, nil, 3, nil},
+		{"stop iteration", 1, ErrStopIteration, 1, nil},
+		{"callback error", 2, callbackErr, 2, callbackErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := bytes.NewReader(encoded.Bytes())
+			decoder, err := NewRowsDecoder(source, primitive.ProtocolVersion4)
+			require.NoError(t, err)
+			var decoded RowSet
+			err = decoder.ForEachRow(func(columns []*ColumnMetadata, cells Row) error {
+				assert.Len(t, columns, 2)
+				decoded = append(decoded, cells)
+				if len(decoded) == tt.stopAfter {
+					return tt.stopErr
+				}
+				return nil
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, rows.Data[:tt.wantRows], decoded)
+			assert.Equal(t, 0, decoder.Remaining())
+			assert.Equal(t, 2, source.Len())
+		})
+	}
+}
+
+func TestNewRowsDecoderWithMetadata(t *testing.T) {
+	codec := &resultCodec{}
+	cached := newRowsDecoderTestResult().Metadata
+	for _, version := range primitive.SupportedProtocolVersions() {
+		t.Run(version.String(), func(t *testing.T) {
+			page := newRowsDecoderTestResult()
+			page.Metadata = &RowsMetadata{ColumnCount: 2, PagingState: []byte{0xca, 0xfe}}
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.Encode(page, encoded, version))
+
+			t.Run("no metadata", func(t *testing.T) {
+				decoder, err := NewRowsDecoderWithMetadata(bytes.NewReader(encoded.Bytes()), version, cached)
+				require.NoError(t, err)
+				assert.Equal(t, int32(2), decoder.Metadata().ColumnCount)
+				assert.Equal(t, []byte{0xca, 0xfe}, decoder.Metadata().PagingState)
+				assert.Equal(t, cached.Columns, decoder.Metadata().Columns)
+				var columns []*ColumnMetadata
+				require.NoError(t, decoder.ForEachRow(func(cols []*ColumnMetadata, _ Row) error {
+					columns = cols
+					return nil
+				}))
+				assert.Equal(t, cached.Columns, columns)
+			})
+
+			t.Run("page metadata takes precedence", func(t *testing.T) {
+				withMetadata := &bytes.Buffer{}
+				require.NoError(t, codec.Encode(newRowsDecoderTestResult(), withMetadata, version))
+				other := &RowsMetadata{ColumnCount: 2, Columns: []*ColumnMetadata{
+					{Keyspace: "ks2", Table: "table2", Name: "col1", Type: datatype.Int},
+					{Keyspace: "ks2", Table: "table2", Name: "col2", Type: datatype.Int},
+				}}
+				decoder, err := NewRowsDecoderWithMetadata(withMetadata, version, other)
+				require.NoError(t, err)
+				assert.Equal(t, "ks1", decoder.Metadata().Columns[0].Keyspace)
+			})
+
+			t.Run("column count mismatch", func(t *testing.T) {
+				_, err := NewRowsDecoderWithMetadata(bytes.NewReader(encoded.Bytes()), version, &RowsMetadata{
+					ColumnCount: 1,
+					Columns:     []*ColumnMetadata{{Keyspace: "ks1", Table: "table1", Name: "col1", Type: datatype.Int}},
+				})
+				assert.EqualError(t, err, "cannot attach cached RESULT Rows metadata: expected 1 columns, got 2")
+			})
+
+			t.Run("nil cached metadata", func(t *testing.T) {
+				decoder, err := NewRowsDecoderWithMetadata(bytes.NewReader(encoded.Bytes()), version, nil)
+				require.NoError(t, err)
+				assert.Nil(t, decoder.Metadata().Columns)
+			})
+		})
+	}
+}
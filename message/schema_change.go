@@ -0,0 +1,111 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// SchemaChange is a common view of SchemaChangeEvent and SchemaChangeResult, which carry the same payload: a schema
+// change pushed by the server as an event, or returned by the server in response to a DDL statement. It allows
+// consumers to handle both uniformly.
+type SchemaChange interface {
+	Message
+
+	// GetChangeType returns the schema change type.
+	GetChangeType() primitive.SchemaChangeType
+
+	// GetTarget returns the schema change target, that is, the kind of schema object affected by the change.
+	GetTarget() primitive.SchemaChangeTarget
+
+	// GetKeyspace returns the name of the keyspace affected by the change.
+	GetKeyspace() string
+
+	// GetObject returns the name of the schema object affected by the change, or an empty string if the affected
+	// object is the keyspace itself.
+	GetObject() string
+
+	// GetArguments returns the arguments of the function or aggregate affected by the change, if any.
+	GetArguments() []string
+}
+
+func (m *SchemaChangeEvent) GetChangeType() primitive.SchemaChangeType {
+	return m.ChangeType
+}
+
+func (m *SchemaChangeEvent) GetTarget() primitive.SchemaChangeTarget {
+	return m.Target
+}
+
+func (m *SchemaChangeEvent) GetKeyspace() string {
+	return m.Keyspace
+}
+
+func (m *SchemaChangeEvent) GetObject() string {
+	return m.Object
+}
+
+func (m *SchemaChangeEvent) GetArguments() []string {
+	return m.Arguments
+}
+
+// ToResult converts this event into a SchemaChangeResult with the same payload. The arguments are copied.
+func (m *SchemaChangeEvent) ToResult() *SchemaChangeResult {
+	return &SchemaChangeResult{
+		ChangeType: m.ChangeType,
+		Target:     m.Target,
+		Keyspace:   m.Keyspace,
+		Object:     m.Object,
+		Arguments:  copyArguments(m.Arguments),
+	}
+}
+
+func (m *SchemaChangeResult) GetChangeType() primitive.SchemaChangeType {
+	return m.ChangeType
+}
+
+func (m *SchemaChangeResult) GetTarget() primitive.SchemaChangeTarget {
+	return m.Target
+}
+
+func (m *SchemaChangeResult) GetKeyspace() string {
+	return m.Keyspace
+}
+
+func (m *SchemaChangeResult) GetObject() string {
+	return m.Object
+}
+
+func (m *SchemaChangeResult) GetArguments() []string {
+	return m.Arguments
+}
+
+// ToEvent converts this result into a SchemaChangeEvent with the same payload. The arguments are copied.
+func (m *SchemaChangeResult) ToEvent() *SchemaChangeEvent {
+	return &SchemaChangeEvent{
+		ChangeType: m.ChangeType,
+		Target:     m.Target,
+		Keyspace:   m.Keyspace,
+		Object:     m.Object,
+		Arguments:  copyArguments(m.Arguments),
+	}
+}
+
+func copyArguments(arguments []string) []string {
+	if arguments == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(arguments)), arguments...)
+}
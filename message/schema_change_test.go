@@ -0,0 +1,61 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestSchemaChange(t *testing.T) {
+	event := &SchemaChangeEvent{
+		ChangeType: primitive.SchemaChangeTypeCreated,
+		Target:     primitive.SchemaChangeTargetFunction,
+		Keyspace:   "ks1",
+		Object:     "func1",
+		Arguments:  []string{"int", "varchar"},
+	}
+	result := &SchemaChangeResult{
+		ChangeType: primitive.SchemaChangeTypeCreated,
+		Target:     primitive.SchemaChangeTargetFunction,
+		Keyspace:   "ks1",
+		Object:     "func1",
+		Arguments:  []string{"int", "varchar"},
+	}
+	t.Run("conversions", func(t *testing.T) {
+		assert.Equal(t, result, event.ToResult())
+		assert.Equal(t, event, result.ToEvent())
+		assert.Equal(t, event, event.ToResult().ToEvent())
+		converted := event.ToResult()
+		converted.Arguments[0] = "bigint"
+		assert.Equal(t, "int", event.Arguments[0])
+		assert.Nil(t, (&SchemaChangeEvent{Keyspace: "ks1"}).ToResult().Arguments)
+	})
+	t.Run("common view", func(t *testing.T) {
+		for _, change := range []SchemaChange{event, result} {
+			assert.True(t, change.IsResponse())
+			assert.Equal(t, primitive.SchemaChangeTypeCreated, change.GetChangeType())
+			assert.Equal(t, primitive.SchemaChangeTargetFunction, change.GetTarget())
+			assert.Equal(t, "ks1", change.GetKeyspace())
+			assert.Equal(t, "func1", change.GetObject())
+			assert.Equal(t, []string{"int", "varchar"}, change.GetArguments())
+		}
+		assert.Equal(t, primitive.OpCodeEvent, SchemaChange(event).GetOpCode())
+		assert.Equal(t, primitive.OpCodeResult, SchemaChange(result).GetOpCode())
+	})
+}
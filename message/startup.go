@@ -94,6 +94,14 @@ func (m *Startup) SetCompression(compression primitive.Compression) {
 	}
 }
 
+func (m *Startup) GetCqlVersion() string {
+	return m.Options[StartupOptionCqlVersion]
+}
+
+func (m *Startup) SetCqlVersion(cqlVersion string) {
+	m.Options[StartupOptionCqlVersion] = cqlVersion
+}
+
 func (m *Startup) GetClientId() string {
 	return m.Options[StartupOptionClientId]
 }
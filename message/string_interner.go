@@ -0,0 +1,106 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// DefaultStringInternerMaxSize is the default maximum number of distinct strings retained by a StringInterner.
+const DefaultStringInternerMaxSize = 10000
+
+// StringInterner deduplicates the keyspace, table and column names decoded from result metadata, so that decoding
+// many pages of the same result set, or many results of the same prepared statement, does not allocate the same names
+// over and over again. Once the interner holds its maximum number of distinct strings, new strings are decoded
+// normally, without being interned. A StringInterner is safe for concurrent use, and is typically shared by all the
+// connections using the same codec, see NewInterningResultCodec.
+type StringInterner struct {
+	maxSize int
+	lock    sync.RWMutex
+	strings map[string]string
+	buffers sync.Pool
+}
+
+// NewStringInterner creates a new StringInterner retaining at most maxSize distinct strings. If maxSize is zero or
+// negative, DefaultStringInternerMaxSize is used.
+func NewStringInterner(maxSize int) *StringInterner {
+	if maxSize <= 0 {
+		maxSize = DefaultStringInternerMaxSize
+	}
+	return &StringInterner{
+		maxSize: maxSize,
+		strings: make(map[string]string),
+		buffers: sync.Pool{New: func() interface{} { return new([]byte) }},
+	}
+}
+
+// Intern returns a string equal to the given bytes, reusing a previously interned string if there is one.
+func (i *StringInterner) Intern(b []byte) string {
+	i.lock.RLock()
+	s, ok := i.strings[string(b)]
+	i.lock.RUnlock()
+	if ok {
+		return s
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if s, ok = i.strings[string(b)]; ok {
+		return s
+	}
+	s = string(b)
+	if len(i.strings) < i.maxSize {
+		i.strings[s] = s
+	}
+	return s
+}
+
+// Len returns the number of distinct strings currently interned.
+func (i *StringInterner) Len() int {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return len(i.strings)
+}
+
+// ReadString reads a [string] from the given source, just like primitive.ReadString, and interns it.
+func (i *StringInterner) ReadString(source io.Reader) (string, error) {
+	if length, err := primitive.ReadShort(source); err != nil {
+		return "", fmt.Errorf("cannot read [string] length: %w", err)
+	} else if length == 0 {
+		return "", nil
+	} else {
+		buf := i.buffers.Get().(*[]byte)
+		defer i.buffers.Put(buf)
+		if cap(*buf) < int(length) {
+			*buf = make([]byte, length)
+		}
+		decoded := (*buf)[:length]
+		if _, err := io.ReadFull(source, decoded); err != nil {
+			return "", fmt.Errorf("cannot read [string] content: %w", err)
+		}
+		return i.Intern(decoded), nil
+	}
+}
+
+// readMetadataString reads a [string] from the given source, interning it if the given interner is not nil.
+func readMetadataString(source io.Reader, interner *StringInterner) (string, error) {
+	if interner == nil {
+		return primitive.ReadString(source)
+	}
+	return interner.ReadString(source)
+}
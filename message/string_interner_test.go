@@ -0,0 +1,107 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestStringInterner(t *testing.T) {
+	interner := NewStringInterner(2)
+	ks1 := interner.Intern([]byte("ks"))
+	ks2 := interner.Intern([]byte("ks"))
+	assert.Equal(t, "ks", ks1)
+	assert.Equal(t, stringData(ks1), stringData(ks2))
+	assert.Equal(t, 1, interner.Len())
+	assert.Equal(t, "t", interner.Intern([]byte("t")))
+	assert.Equal(t, 2, interner.Len())
+	// max size reached
+	c1 := interner.Intern([]byte("col"))
+	c2 := interner.Intern([]byte("col"))
+	assert.Equal(t, "col", c1)
+	assert.Equal(t, "col", c2)
+	assert.NotEqual(t, stringData(c1), stringData(c2))
+	assert.Equal(t, 2, interner.Len())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, primitive.WriteString("ks", buf))
+	require.NoError(t, primitive.WriteString("", buf))
+	s, err := interner.ReadString(buf)
+	require.NoError(t, err)
+	assert.Equal(t, stringData(ks1), stringData(s))
+	s, err = interner.ReadString(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "", s)
+	_, err = interner.ReadString(bytes.NewBuffer([]byte{0, 5, 'a'}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read [string] content")
+}
+
+func TestNewInterningResultCodec(t *testing.T) {
+	interner := NewStringInterner(0)
+	codec := NewInterningResultCodec(interner)
+	rows := &RowsResult{
+		Metadata: &RowsMetadata{
+			ColumnCount: 2,
+			Columns: []*ColumnMetadata{
+				{Keyspace: "ks1", Table: "table1", Name: "col1", Type: datatype.Int},
+				{Keyspace: "ks1", Table: "table1", Name: "col2", Type: datatype.Varchar},
+			},
+		},
+		Data: RowSet{},
+	}
+	prepared := &PreparedResult{
+		PreparedQueryId: []byte{0xca, 0xfe},
+		VariablesMetadata: &VariablesMetadata{
+			PkIndices: []uint16{0},
+			Columns: []*ColumnMetadata{
+				{Keyspace: "ks1", Table: "table2", Name: "col1", Type: datatype.Int},
+			},
+		},
+		ResultMetadata: &RowsMetadata{ColumnCount: 0},
+	}
+	var decoded []Message
+	for _, msg := range []Message{rows, rows, prepared} {
+		buf := &bytes.Buffer{}
+		require.NoError(t, codec.Encode(msg, buf, primitive.ProtocolVersion4))
+		actual, err := codec.Decode(buf, primitive.ProtocolVersion4)
+		require.NoError(t, err)
+		assert.Equal(t, msg, actual)
+		decoded = append(decoded, actual)
+	}
+	first := decoded[0].(*RowsResult).Metadata.Columns
+	second := decoded[1].(*RowsResult).Metadata.Columns
+	third := decoded[2].(*PreparedResult).VariablesMetadata.Columns
+	assert.Equal(t, stringData(first[0].Keyspace), stringData(second[0].Keyspace))
+	assert.Equal(t, stringData(first[1].Name), stringData(second[1].Name))
+	assert.Equal(t, stringData(first[0].Keyspace), stringData(third[0].Keyspace))
+	assert.Equal(t, stringData(first[0].Name), stringData(third[0].Name))
+	assert.Equal(t, 5, interner.Len())
+	assert.Equal(t, primitive.OpCodeResult, codec.GetOpCode())
+	assert.NotNil(t, NewInterningResultCodec(nil).(*resultCodec).interner)
+}
@@ -18,6 +18,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/datastax/go-cassandra-native-protocol/primitive"
 )
@@ -51,6 +53,58 @@ func (m *Supported) String() string {
 	return fmt.Sprintf("SUPPORTED %v", m.Options)
 }
 
+// ProtocolVersions returns the protocol versions advertised under the SupportedProtocolVersions key, split into
+// non-beta and beta versions, both in ascending order. It returns false if the key is absent, which is the case of
+// servers older than Cassandra 4.0, or if one of its values cannot be parsed.
+func (m *Supported) ProtocolVersions() (supported []primitive.ProtocolVersion, beta []primitive.ProtocolVersion, ok bool) {
+	values, found := m.Options[SupportedProtocolVersions]
+	if !found {
+		return nil, nil, false
+	}
+	for _, value := range values {
+		if version, isBeta, err := parseProtocolVersionItem(value); err != nil {
+			return nil, nil, false
+		} else if isBeta {
+			beta = append(beta, version)
+		} else {
+			supported = append(supported, version)
+		}
+	}
+	sortProtocolVersions(supported)
+	sortProtocolVersions(beta)
+	return supported, beta, true
+}
+
+// HighestSupportedVersion returns the highest non-beta protocol version advertised by the server that is also
+// supported by this library, or false if there is no such version, or if the server did not advertise its protocol
+// versions, see ProtocolVersions.
+func (m *Supported) HighestSupportedVersion() (primitive.ProtocolVersion, bool) {
+	supported, _, ok := m.ProtocolVersions()
+	if ok {
+		for i := len(supported) - 1; i >= 0; i-- {
+			if supported[i].IsSupported() {
+				return supported[i], true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseProtocolVersionItem parses a protocol version as advertised by servers, in the form <number>/<name>, e.g.
+// "4/v4", "5/v5-beta" or "65/dse-v1"; the name is optional.
+func parseProtocolVersionItem(item string) (version primitive.ProtocolVersion, beta bool, err error) {
+	item = strings.TrimSpace(item)
+	number, name := item, ""
+	if i := strings.Index(item, "/"); i >= 0 {
+		number, name = item[:i], item[i+1:]
+	}
+	v, err := strconv.ParseUint(number, 10, 8)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid protocol version: %q", item)
+	}
+	return primitive.ProtocolVersion(v), strings.HasSuffix(strings.ToLower(name), "-beta"), nil
+}
+
 type supportedCodec struct{}
 
 func (c *supportedCodec) Encode(msg Message, dest io.Writer, _ primitive.ProtocolVersion) error {
@@ -0,0 +1,124 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"math/bits"
+	"strconv"
+)
+
+// Supported.Options multimap keys returned by ScyllaDB, describing the sharding of the node the connection is
+// established to.
+const (
+	SupportedScyllaShard             = "SCYLLA_SHARD"
+	SupportedScyllaNrShards          = "SCYLLA_NR_SHARDS"
+	SupportedScyllaPartitioner       = "SCYLLA_PARTITIONER"
+	SupportedScyllaShardingAlgorithm = "SCYLLA_SHARDING_ALGORITHM"
+	SupportedScyllaShardingIgnoreMsb = "SCYLLA_SHARDING_IGNORE_MSB"
+	SupportedScyllaShardAwarePort    = "SCYLLA_SHARD_AWARE_PORT"
+	SupportedScyllaShardAwarePortSsl = "SCYLLA_SHARD_AWARE_PORT_SSL"
+	// SupportedScyllaTabletsRoutingV1 is advertised by ScyllaDB nodes that can attach tablet routing information to
+	// responses; clients opt in by sending it as a Startup option, with an empty value.
+	SupportedScyllaTabletsRoutingV1 = "TABLETS_ROUTING_V1"
+)
+
+// ScyllaShardingInfo describes the sharding of a ScyllaDB node, as advertised in its SUPPORTED responses. See
+// Supported.ScyllaShardingInfo.
+type ScyllaShardingInfo struct {
+	// Shard is the shard that handles the connection the SUPPORTED response was received on.
+	Shard int
+	// NrShards is the number of shards of the node.
+	NrShards int
+	// Partitioner is the fully-qualified class name of the partitioner, e.g.
+	// "org.apache.cassandra.dht.Murmur3Partitioner".
+	Partitioner string
+	// ShardingAlgorithm is the algorithm used to map tokens to shards, e.g. "biased-token-round-robin".
+	ShardingAlgorithm string
+	// ShardingIgnoreMsb is the number of most significant bits of a token to ignore when mapping it to a shard.
+	ShardingIgnoreMsb int
+	// ShardAwarePort is the port on which the node routes incoming connections to the shard of their choice, or zero
+	// if the node does not advertise it.
+	ShardAwarePort int
+	// ShardAwarePortSsl is the TLS variant of ShardAwarePort, or zero if the node does not advertise it.
+	ShardAwarePortSsl int
+}
+
+// ScyllaShardingInfo returns the sharding information advertised by ScyllaDB nodes. It returns false if the
+// SupportedScyllaShard or SupportedScyllaNrShards keys are absent, which is the case of servers other than ScyllaDB,
+// or if one of the numeric values cannot be parsed.
+func (m *Supported) ScyllaShardingInfo() (*ScyllaShardingInfo, bool) {
+	info := &ScyllaShardingInfo{
+		Partitioner:       m.firstOption(SupportedScyllaPartitioner),
+		ShardingAlgorithm: m.firstOption(SupportedScyllaShardingAlgorithm),
+	}
+	for _, field := range []struct {
+		key      string
+		dest     *int
+		required bool
+	}{
+		{SupportedScyllaShard, &info.Shard, true},
+		{SupportedScyllaNrShards, &info.NrShards, true},
+		{SupportedScyllaShardingIgnoreMsb, &info.ShardingIgnoreMsb, false},
+		{SupportedScyllaShardAwarePort, &info.ShardAwarePort, false},
+		{SupportedScyllaShardAwarePortSsl, &info.ShardAwarePortSsl, false},
+	} {
+		if value := m.firstOption(field.key); value == "" {
+			if field.required {
+				return nil, false
+			}
+		} else if parsed, err := strconv.Atoi(value); err != nil || parsed < 0 {
+			return nil, false
+		} else {
+			*field.dest = parsed
+		}
+	}
+	if info.NrShards == 0 || info.Shard >= info.NrShards {
+		return nil, false
+	}
+	return info, true
+}
+
+func (m *Supported) firstOption(key string) string {
+	if values := m.Options[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ShardOf returns the shard that owns the given Murmur3 token, according to the "biased-token-round-robin" sharding
+// algorithm.
+func (i *ScyllaShardingInfo) ShardOf(token int64) int {
+	biased := uint64(token) + (1 << 63)
+	biased <<= uint(i.ShardingIgnoreMsb)
+	shard, _ := bits.Mul64(biased, uint64(i.NrShards))
+	return int(shard)
+}
+
+// ShardOfLocalPort returns the shard that a connection to the shard-aware port is routed to, given the local port of
+// the connection.
+func (i *ScyllaShardingInfo) ShardOfLocalPort(localPort int) int {
+	return localPort % i.NrShards
+}
+
+// LocalPortForShard returns the lowest local port greater than or equal to minPort that routes connections to the
+// shard-aware port to the given shard. Clients should try successive candidates, adding NrShards each time, until
+// they find a port that is available.
+func (i *ScyllaShardingInfo) LocalPortForShard(shard int, minPort int) int {
+	port := minPort - minPort%i.NrShards + shard
+	if port < minPort {
+		port += i.NrShards
+	}
+	return port
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupported_ScyllaShardingInfo(t *testing.T) {
+	scylla := map[string][]string{
+		SupportedScyllaShard:             {"3"},
+		SupportedScyllaNrShards:          {"12"},
+		SupportedScyllaPartitioner:       {"org.apache.cassandra.dht.Murmur3Partitioner"},
+		SupportedScyllaShardingAlgorithm: {"biased-token-round-robin"},
+		SupportedScyllaShardingIgnoreMsb: {"12"},
+		SupportedScyllaShardAwarePort:    {"19042"},
+		SupportedScyllaShardAwarePortSsl: {"19142"},
+	}
+	tests := []struct {
+		name     string
+		options  map[string][]string
+		expected *ScyllaShardingInfo
+	}{
+		{"absent", map[string][]string{"CQL_VERSION": {"3.4.5"}}, nil},
+		{
+			"scylla",
+			scylla,
+			&ScyllaShardingInfo{
+				Shard:             3,
+				NrShards:          12,
+				Partitioner:       "org.apache.cassandra.dht.Murmur3Partitioner",
+				ShardingAlgorithm: "biased-token-round-robin",
+				ShardingIgnoreMsb: 12,
+				ShardAwarePort:    19042,
+				ShardAwarePortSsl: 19142,
+			},
+		},
+		{
+			"scylla without shard-aware port",
+			map[string][]string{SupportedScyllaShard: {"0"}, SupportedScyllaNrShards: {"1"}},
+			&ScyllaShardingInfo{Shard: 0, NrShards: 1},
+		},
+		{"invalid shard count", map[string][]string{SupportedScyllaShard: {"0"}, SupportedScyllaNrShards: {"zero"}}, nil},
+		{"zero shard count", map[string][]string{SupportedScyllaShard: {"0"}, SupportedScyllaNrShards: {"0"}}, nil},
+		{"shard out of range", map[string][]string{SupportedScyllaShard: {"2"}, SupportedScyllaNrShards: {"2"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Supported{Options: tt.options}
+			info, ok := msg.ScyllaShardingInfo()
+			assert.Equal(t, tt.expected, info)
+			assert.Equal(t, tt.expected != nil, ok)
+		})
+	}
+}
+
+func TestScyllaShardingInfo_ShardOf(t *testing.T) {
+	info := &ScyllaShardingInfo{NrShards: 4}
+	assert.Equal(t, 0, info.ShardOf(math.MinInt64))
+	assert.Equal(t, 1, info.ShardOf(-1))
+	assert.Equal(t, 2, info.ShardOf(0))
+	assert.Equal(t, 3, info.ShardOf(math.MaxInt64))
+	info.ShardingIgnoreMsb = 12
+	assert.Equal(t, 0, info.ShardOf(0))
+	// the 12 most significant bits are ignored: only the remaining 52 bits determine the shard
+	assert.Equal(t, 2, info.ShardOf(math.MinInt64+1<<51))
+	assert.Equal(t, 2, info.ShardOf(1<<51))
+	assert.Equal(t, 3, info.ShardOf(-1))
+}
+
+func TestScyllaShardingInfo_LocalPorts(t *testing.T) {
+	info := &ScyllaShardingInfo{NrShards: 12}
+	for shard := 0; shard < info.NrShards; shard++ {
+		port := info.LocalPortForShard(shard, 49152)
+		require.GreaterOrEqual(t, port, 49152)
+		require.Less(t, port, 49152+info.NrShards)
+		assert.Equal(t, shard, info.ShardOfLocalPort(port))
+	}
+}
@@ -278,3 +278,45 @@ func TestSupportedCodec_Decode(test *testing.T) {
 		})
 	}
 }
+
+func TestSupported_ProtocolVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   map[string][]string
+		supported []primitive.ProtocolVersion
+		beta      []primitive.ProtocolVersion
+		ok        bool
+		highest   primitive.ProtocolVersion
+	}{
+		{"absent", map[string][]string{"CQL_VERSION": {"3.4.5"}}, nil, nil, false, 0},
+		{
+			"cassandra 4.0",
+			map[string][]string{SupportedProtocolVersions: {"5/v5", "3/v3", "4/v4", "6/v6-beta"}},
+			[]primitive.ProtocolVersion{3, 4, 5},
+			[]primitive.ProtocolVersion{6},
+			true,
+			primitive.ProtocolVersion5,
+		},
+		{
+			"only unsupported versions",
+			map[string][]string{SupportedProtocolVersions: {"1/v1", "99/v99"}},
+			[]primitive.ProtocolVersion{1, 99},
+			nil,
+			true,
+			0,
+		},
+		{"invalid", map[string][]string{SupportedProtocolVersions: {"v4"}}, nil, nil, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Supported{Options: tt.options}
+			supported, beta, ok := msg.ProtocolVersions()
+			assert.Equal(t, tt.supported, supported)
+			assert.Equal(t, tt.beta, beta)
+			assert.Equal(t, tt.ok, ok)
+			highest, found := msg.HighestSupportedVersion()
+			assert.Equal(t, tt.highest, highest)
+			assert.Equal(t, tt.highest != 0, found)
+		})
+	}
+}
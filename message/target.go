@@ -0,0 +1,213 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"strings"
+)
+
+// StatementTarget is the keyspace and table targeted by a statement, as identified by ParseStatementTarget or
+// NewStatementTarget. It is typically used by proxies to route requests, e.g. per tenant or per shard.
+type StatementTarget struct {
+	// Keyspace is the keyspace targeted by the statement; it is empty if the statement does not qualify the table
+	// name, and the request does not specify a keyspace either: the keyspace of the connection is then used.
+	Keyspace string
+	// Table is the table targeted by the statement; it is empty for statements that target a keyspace, such as USE or
+	// CREATE KEYSPACE.
+	Table string
+}
+
+func (t *StatementTarget) String() string {
+	if t.Table == "" {
+		return t.Keyspace
+	} else if t.Keyspace == "" {
+		return t.Table
+	}
+	return t.Keyspace + "." + t.Table
+}
+
+// NewStatementTarget identifies the keyspace and table targeted by the given request: for QUERY and PREPARE requests,
+// by parsing their query string, see ParseStatementTarget, the keyspace defaulting to the keyspace set on the request,
+// if any; for EXECUTE requests, with the given resolver, which may be nil; and for BATCH requests, from their
+// children, as long as they all target the same table. The returned boolean is false if the target could not be
+// identified.
+func NewStatementTarget(msg Message, resolver PreparedTableResolver) (*StatementTarget, bool) {
+	switch msg := msg.(type) {
+	case *Query:
+		var keyspace string
+		if msg.Options != nil {
+			keyspace = msg.Options.Keyspace
+		}
+		return parseStatementTarget(msg.Query, keyspace)
+	case *Prepare:
+		return parseStatementTarget(msg.Query, msg.Keyspace)
+	case *Execute:
+		return resolveStatementTarget(msg.QueryId, resolver)
+	case *Batch:
+		var target *StatementTarget
+		for _, child := range msg.Children {
+			var childTarget *StatementTarget
+			var ok bool
+			if child.Id != nil {
+				childTarget, ok = resolveStatementTarget(child.Id, resolver)
+			} else {
+				childTarget, ok = parseStatementTarget(child.Query, msg.Keyspace)
+			}
+			if !ok || (target != nil && *target != *childTarget) {
+				return nil, false
+			}
+			target = childTarget
+		}
+		return target, target != nil
+	}
+	return nil, false
+}
+
+func resolveStatementTarget(preparedId []byte, resolver PreparedTableResolver) (*StatementTarget, bool) {
+	if resolver == nil {
+		return nil, false
+	} else if keyspace, table, found := resolver(preparedId); found {
+		return &StatementTarget{Keyspace: keyspace, Table: table}, true
+	}
+	return nil, false
+}
+
+// ParseStatementTarget identifies the keyspace and table targeted by the given CQL query string, without a full CQL
+// parser: only the leading keywords of the statement and the name that follows them are examined. Supported statements
+// are SELECT, INSERT, UPDATE, DELETE, TRUNCATE, USE, BEGIN BATCH (from its first statement), and CREATE, ALTER and
+// DROP statements for tables and keyspaces. Unquoted names are converted to lower case, as CQL names are
+// case-insensitive unless quoted. The returned boolean is false if the target could not be identified.
+func ParseStatementTarget(query string) (*StatementTarget, bool) {
+	return parseStatementTarget(query, "")
+}
+
+// IsSelectStatement returns true if the given CQL query string is a SELECT statement. Comments and whitespace before
+// the statement are ignored. Note that all other statements, including conditional updates, may mutate data.
+func IsSelectStatement(query string) bool {
+	tokens := tokenizeStatement(query)
+	return len(tokens) > 0 && tokens[0].is("select")
+}
+
+func parseStatementTarget(query string, defaultKeyspace string) (*StatementTarget, bool) {
+	tokens := tokenizeStatement(query)
+	for len(tokens) > 0 && tokens[0].is("begin") {
+		// skip to the first statement of the batch
+		tokens = tokens[1:]
+		for len(tokens) > 0 && !tokens[0].is("insert") && !tokens[0].is("update") && !tokens[0].is("delete") {
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, false
+	}
+	var rest []statementToken
+	keyspaceOnly := false
+	switch first := tokens[0]; {
+	case first.is("select"), first.is("delete"):
+		rest = afterTopLevel(tokens, "from")
+	case first.is("insert"):
+		rest = afterKeywords(tokens[1:], "into")
+	case first.is("update"):
+		rest = tokens[1:]
+	case first.is("truncate"):
+		rest = tokens[1:]
+		if len(rest) > 1 && (rest[0].is("table") || rest[0].is("columnfamily")) {
+			rest = rest[1:]
+		}
+	case first.is("use"):
+		rest, keyspaceOnly = tokens[1:], true
+	case first.is("create"), first.is("alter"), first.is("drop"):
+		if len(tokens) > 1 && (tokens[1].is("table") || tokens[1].is("columnfamily")) {
+			rest = skipIfExists(tokens[2:])
+		} else if len(tokens) > 1 && tokens[1].is("keyspace") {
+			rest, keyspaceOnly = skipIfExists(tokens[2:]), true
+		}
+	}
+	if len(rest) == 0 || !rest[0].identifier {
+		return nil, false
+	} else if keyspaceOnly {
+		return &StatementTarget{Keyspace: rest[0].text}, true
+	} else if len(rest) > 2 && rest[1].text == "." && rest[2].identifier {
+		return &StatementTarget{Keyspace: rest[0].text, Table: rest[2].text}, true
+	}
+	return &StatementTarget{Keyspace: defaultKeyspace, Table: rest[0].text}, true
+}
+
+// afterTopLevel returns the tokens following the first occurrence of the given keyword outside parentheses.
+func afterTopLevel(tokens []statementToken, keyword string) []statementToken {
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.text == "(":
+			depth++
+		case token.text == ")":
+			depth--
+		case depth == 0 && token.is(keyword):
+			return tokens[i+1:]
+		}
+	}
+	return nil
+}
+
+// afterKeywords returns the tokens following the given keywords, or nil if the tokens do not start with them.
+func afterKeywords(tokens []statementToken, keywords ...string) []statementToken {
+	for _, keyword := range keywords {
+		if len(tokens) == 0 || !tokens[0].is(keyword) {
+			return nil
+		}
+		tokens = tokens[1:]
+	}
+	return tokens
+}
+
+// skipIfExists skips the IF EXISTS or IF NOT EXISTS clause at the beginning of the given tokens, if any.
+func skipIfExists(tokens []statementToken) []statementToken {
+	if rest := afterKeywords(tokens, "if", "exists"); rest != nil {
+		return rest
+	} else if rest = afterKeywords(tokens, "if", "not", "exists"); rest != nil {
+		return rest
+	}
+	return tokens
+}
+
+type statementToken struct {
+	text string
+	// identifier is true if the token is a keyword or a name; the text of unquoted identifiers is in lower case.
+	identifier bool
+	quoted     bool
+}
+
+// is returns true if the token is the given unquoted keyword, which must be in lower case.
+func (t statementToken) is(keyword string) bool {
+	return t.identifier && !t.quoted && t.text == keyword
+}
+
+// tokenizeStatement splits the given CQL query string into tokens. Comments, whitespace and literals are dropped;
+// punctuation is kept as single-character tokens.
+func tokenizeStatement(query string) []statementToken {
+	var tokens []statementToken
+	lexer := newCqlLexer(query)
+	for token, ok := lexer.next(); ok; token, ok = lexer.next() {
+		switch token.kind {
+		case cqlTokenQuotedName:
+			tokens = append(tokens, statementToken{text: unquoteName(token.text), identifier: true, quoted: true})
+		case cqlTokenWord:
+			tokens = append(tokens, statementToken{text: strings.ToLower(token.text), identifier: true})
+		case cqlTokenNumber, cqlTokenUuid, cqlTokenPunctuation:
+			tokens = append(tokens, statementToken{text: token.text})
+		}
+	}
+	return tokens
+}
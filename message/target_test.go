@@ -0,0 +1,110 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatementTarget(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *StatementTarget
+	}{
+		{"SELECT * FROM ks.t WHERE k = 1", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"select count(*), writetime(v) from T", &StatementTarget{Table: "t"}},
+		{`SELECT (int) v FROM "MyKs"."My""Table"`, &StatementTarget{Keyspace: "MyKs", Table: `My"Table`}},
+		{"SELECT json * FROM ks . t", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"INSERT INTO ks.t (k, v) VALUES (1, 'from x')", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"UPDATE ks.t USING TTL 10 SET v = 1 WHERE k = 1", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"DELETE v FROM ks.t WHERE k = 1", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"DELETE FROM t WHERE k = 1", &StatementTarget{Table: "t"}},
+		{"TRUNCATE TABLE ks.t", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"TRUNCATE t", &StatementTarget{Table: "t"}},
+		{"USE \"Ks\"", &StatementTarget{Keyspace: "Ks"}},
+		{"CREATE TABLE IF NOT EXISTS ks.t (k int PRIMARY KEY)", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"ALTER TABLE ks.t ADD v int", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"DROP TABLE IF EXISTS ks.t", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"CREATE KEYSPACE ks WITH replication = {}", &StatementTarget{Keyspace: "ks"}},
+		{"DROP KEYSPACE IF EXISTS ks", &StatementTarget{Keyspace: "ks"}},
+		{"BEGIN UNLOGGED BATCH USING TIMESTAMP 1 INSERT INTO ks.t (k) VALUES (1); APPLY BATCH", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"/* from x */ -- from y\nSELECT $$from z$$ FROM ks.t", &StatementTarget{Keyspace: "ks", Table: "t"}},
+		{"", nil},
+		{"SELECT now()", nil},
+		{"LIST ROLES", nil},
+		{"CREATE INDEX ON ks.t (v)", nil},
+		{"INSERT ks.t", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			target, ok := ParseStatementTarget(tt.query)
+			if tt.expected == nil {
+				assert.False(t, ok, "%v", target)
+			} else {
+				require.True(t, ok)
+				assert.Equal(t, tt.expected, target)
+			}
+		})
+	}
+}
+
+func TestNewStatementTarget(t *testing.T) {
+	target, ok := NewStatementTarget(&Query{Query: "SELECT * FROM t", Options: &QueryOptions{Keyspace: "ks"}}, nil)
+	require.True(t, ok)
+	assert.Equal(t, "ks.t", target.String())
+	target, ok = NewStatementTarget(&Prepare{Query: "SELECT * FROM ks2.t", Keyspace: "ks"}, nil)
+	require.True(t, ok)
+	assert.Equal(t, "ks2.t", target.String())
+
+	resolver := func(preparedId []byte) (string, string, bool) {
+		return "ks", "t", string(preparedId) == "\x01"
+	}
+	target, ok = NewStatementTarget(&Execute{QueryId: []byte{1}}, resolver)
+	require.True(t, ok)
+	assert.Equal(t, &StatementTarget{Keyspace: "ks", Table: "t"}, target)
+	_, ok = NewStatementTarget(&Execute{QueryId: []byte{2}}, resolver)
+	assert.False(t, ok)
+	_, ok = NewStatementTarget(&Execute{QueryId: []byte{1}}, nil)
+	assert.False(t, ok)
+
+	target, ok = NewStatementTarget(&Batch{Children: []*BatchChild{
+		{Query: "INSERT INTO ks.t (k) VALUES (1)"},
+		{Id: []byte{1}},
+	}}, resolver)
+	require.True(t, ok)
+	assert.Equal(t, "ks.t", target.String())
+	_, ok = NewStatementTarget(&Batch{Children: []*BatchChild{
+		{Query: "INSERT INTO ks.t (k) VALUES (1)"},
+		{Query: "INSERT INTO ks.t2 (k) VALUES (1)"},
+	}}, resolver)
+	assert.False(t, ok)
+	_, ok = NewStatementTarget(&Options{}, nil)
+	assert.False(t, ok)
+	assert.Equal(t, "ks", (&StatementTarget{Keyspace: "ks"}).String())
+	assert.Equal(t, "t", (&StatementTarget{Table: "t"}).String())
+}
+
+func TestIsSelectStatement(t *testing.T) {
+	assert.True(t, IsSelectStatement("SELECT * FROM ks.t"))
+	assert.True(t, IsSelectStatement("/* comment */ select json * FROM t"))
+	assert.False(t, IsSelectStatement("UPDATE ks.t SET v = 1 WHERE k = 1 IF v = 0"))
+	assert.False(t, IsSelectStatement("INSERT INTO ks.t (k) VALUES (1) IF NOT EXISTS"))
+	assert.False(t, IsSelectStatement("-- SELECT\nDELETE FROM ks.t WHERE k = 1"))
+	assert.False(t, IsSelectStatement(`"select"`))
+	assert.False(t, IsSelectStatement(""))
+}
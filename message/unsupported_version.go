@@ -0,0 +1,103 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var (
+	unsupportedVersionRegexp = regexp.MustCompile(`(?i)invalid or unsupported protocol version\s*(?::\s*|\()(\d+)`)
+	// e.g. "supported versions are (3/v3, 4/v4, 5/v5-beta)", as sent by Cassandra 4.0+ and DSE 6+
+	supportedVersionsListRegexp = regexp.MustCompile(`(?i)supported versions are \(([^)]*)\)`)
+	// e.g. "the lowest supported version is 3 and the greatest is 4", as sent by Cassandra 3.x
+	supportedVersionsRangeRegexp = regexp.MustCompile(`(?i)lowest supported version is (\d+) and the greatest is (\d+)`)
+)
+
+// UnsupportedVersionHint is the structured form of the ProtocolError message sent by servers when a client uses a
+// protocol version that they do not support, typically in response to STARTUP or OPTIONS.
+type UnsupportedVersionHint struct {
+
+	// Version is the rejected protocol version.
+	Version primitive.ProtocolVersion
+
+	// SupportedVersions are the non-beta protocol versions supported by the server, in ascending order. It is empty if
+	// the server did not advertise them, which is the case of Cassandra 2.x.
+	SupportedVersions []primitive.ProtocolVersion
+
+	// BetaVersions are the beta protocol versions supported by the server, in ascending order. Using a beta version
+	// requires the USE_BETA header flag to be set.
+	BetaVersions []primitive.ProtocolVersion
+}
+
+// UnsupportedVersionHint parses the error message of this ProtocolError, and returns the structured hint it contains
+// if it was sent in response to a request using an unsupported protocol version. It returns false if the message
+// does not have one of the formats known to be used by Cassandra and DSE servers.
+func (m *ProtocolError) UnsupportedVersionHint() (*UnsupportedVersionHint, bool) {
+	matches := unsupportedVersionRegexp.FindStringSubmatch(m.ErrorMessage)
+	if matches == nil {
+		return nil, false
+	}
+	version, err := strconv.ParseUint(matches[1], 10, 8)
+	if err != nil {
+		return nil, false
+	}
+	hint := &UnsupportedVersionHint{Version: primitive.ProtocolVersion(version)}
+	if matches = supportedVersionsListRegexp.FindStringSubmatch(m.ErrorMessage); matches != nil {
+		for _, item := range strings.Split(matches[1], ",") {
+			if v, beta, err := parseProtocolVersionItem(item); err != nil {
+				return nil, false
+			} else if beta {
+				hint.BetaVersions = append(hint.BetaVersions, v)
+			} else {
+				hint.SupportedVersions = append(hint.SupportedVersions, v)
+			}
+		}
+	} else if matches = supportedVersionsRangeRegexp.FindStringSubmatch(m.ErrorMessage); matches != nil {
+		lowest, err1 := strconv.ParseUint(matches[1], 10, 8)
+		greatest, err2 := strconv.ParseUint(matches[2], 10, 8)
+		if err1 != nil || err2 != nil || lowest > greatest {
+			return nil, false
+		}
+		for v := lowest; v <= greatest; v++ {
+			hint.SupportedVersions = append(hint.SupportedVersions, primitive.ProtocolVersion(v))
+		}
+	}
+	sortProtocolVersions(hint.SupportedVersions)
+	sortProtocolVersions(hint.BetaVersions)
+	return hint, true
+}
+
+// HighestSupportedVersion returns the highest non-beta protocol version advertised by the server that is also
+// supported by this library and lesser than the rejected version, or false if there is no such version. This is the
+// version a client should try next when negotiating the protocol version.
+func (h *UnsupportedVersionHint) HighestSupportedVersion() (primitive.ProtocolVersion, bool) {
+	for i := len(h.SupportedVersions) - 1; i >= 0; i-- {
+		v := h.SupportedVersions[i]
+		if v < h.Version && v.IsSupported() {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func sortProtocolVersions(versions []primitive.ProtocolVersion) {
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestProtocolError_UnsupportedVersionHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected *UnsupportedVersionHint
+		highest  primitive.ProtocolVersion
+	}{
+		{
+			"Cassandra 4.0",
+			"Invalid or unsupported protocol version (6); supported versions are (3/v3, 4/v4, 5/v5, 6/v6-beta)",
+			&UnsupportedVersionHint{
+				Version:           6,
+				SupportedVersions: []primitive.ProtocolVersion{3, 4, 5},
+				BetaVersions:      []primitive.ProtocolVersion{6},
+			},
+			primitive.ProtocolVersion5,
+		},
+		{
+			"DSE",
+			"Invalid or unsupported protocol version (67); supported versions are (3/v3, 4/v4, 5/v5-beta, 65/dse-v1, 66/dse-v2)",
+			&UnsupportedVersionHint{
+				Version:           67,
+				SupportedVersions: []primitive.ProtocolVersion{3, 4, 65, 66},
+				BetaVersions:      []primitive.ProtocolVersion{5},
+			},
+			primitive.ProtocolVersionDse2,
+		},
+		{
+			"Cassandra 3.x",
+			"Invalid or unsupported protocol version (5); the lowest supported version is 3 and the greatest is 4",
+			&UnsupportedVersionHint{
+				Version:           5,
+				SupportedVersions: []primitive.ProtocolVersion{3, 4},
+			},
+			primitive.ProtocolVersion4,
+		},
+		{
+			"Cassandra 2.x",
+			"Invalid or unsupported protocol version: 3",
+			&UnsupportedVersionHint{Version: 3},
+			0,
+		},
+		{"other error", "handshake failed", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hint, ok := (&ProtocolError{ErrorMessage: tt.message}).UnsupportedVersionHint()
+			assert.Equal(t, tt.expected != nil, ok)
+			assert.Equal(t, tt.expected, hint)
+			if ok {
+				highest, found := hint.HighestSupportedVersion()
+				assert.Equal(t, tt.highest != 0, found)
+				assert.Equal(t, tt.highest, highest)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Error is returned when a decoded [string] or [long string] is not valid UTF-8, as required by the
+// protocol specification, see ValidateUTF8. Use errors.As to detect it.
+type InvalidUTF8Error struct {
+	// Path is the path to the invalid string in the decoded message, e.g. "(*message.Query).Query".
+	Path string
+	// Value is the invalid string.
+	Value string
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("invalid UTF-8 string at %v: %q", e.Path, e.Value)
+}
+
+// ValidateUTF8 returns an InvalidUTF8Error if any string in the given message, including map keys and strings nested
+// in data types, is not valid UTF-8. Since all the strings of a message are decoded from [string] and [long string]
+// payloads, this detects peers violating the protocol specification before garbage propagates to applications; see
+// also frame.RawCodec.SetUTF8Validation.
+func ValidateUTF8(msg Message) error {
+	if msg == nil {
+		return nil
+	}
+	v := reflect.ValueOf(msg)
+	return validateUTF8(fmt.Sprintf("(%v)", v.Type()), v)
+}
+
+func validateUTF8(path string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		if s := v.String(); !utf8.ValidString(s) {
+			return &InvalidUTF8Error{Path: path, Value: s}
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return validateUTF8(path, v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.PkgPath == "" {
+				if err := validateUTF8(path+"."+field.Name, v.Field(i)); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// [bytes] and the like are not strings
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := validateUTF8(fmt.Sprintf("%s[%d]", path, i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			entryPath := fmt.Sprintf("%s[%q]", path, fmt.Sprint(iter.Key()))
+			if err := validateUTF8(entryPath, iter.Key()); err != nil {
+				return err
+			} else if err := validateUTF8(entryPath, iter.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
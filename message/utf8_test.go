@@ -0,0 +1,62 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package message
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+func TestValidateUTF8(t *testing.T) {
+	invalid := string([]byte{0xc3, 0x28})
+	tests := []struct {
+		name string
+		msg  Message
+		path string
+	}{
+		{"nil", nil, ""},
+		{"valid", &Query{Query: "SELECT * FROM ks.tablé", Options: &QueryOptions{
+			PositionalValues: []*primitive.Value{primitive.NewValue([]byte{0xff})},
+		}}, ""},
+		{"string field", &Prepare{Query: invalid}, "(*message.Prepare).Query"},
+		{"map key", &Startup{Options: map[string]string{invalid: "v"}}, `(*message.Startup).Options["\xc3("]`},
+		{"map value", &Startup{Options: map[string]string{"k": invalid}}, `(*message.Startup).Options["k"]`},
+		{"string multimap", &Supported{Options: map[string][]string{"k": {"a", invalid}}}, `(*message.Supported).Options["k"][1]`},
+		{"data type", &RowsResult{Metadata: &RowsMetadata{ColumnCount: 1, Columns: []*ColumnMetadata{
+			{Keyspace: "ks", Table: "t", Name: "c", Type: datatype.NewList(datatype.NewCustom(invalid))},
+		}}}, "(*message.RowsResult).Metadata.Columns[0].Type.ElementType.ClassName"},
+		{"enum", &SchemaChangeEvent{ChangeType: primitive.SchemaChangeType(invalid)}, "(*message.SchemaChangeEvent).ChangeType"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUTF8(tt.msg)
+			if tt.path == "" {
+				assert.NoError(t, err)
+			} else {
+				var utf8Err *InvalidUTF8Error
+				require.True(t, errors.As(err, &utf8Err), err)
+				assert.Equal(t, tt.path, utf8Err.Path)
+				assert.Equal(t, invalid, utf8Err.Value)
+				assert.Contains(t, err.Error(), "invalid UTF-8 string at "+tt.path)
+			}
+		})
+	}
+}
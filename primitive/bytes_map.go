@@ -17,19 +17,88 @@ package primitive
 import (
 	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 // [bytes map]
 
+// BytesMapLimits defines limits to enforce when reading or writing a [bytes map], such as a frame custom payload.
+// Limits that are zero or negative are not enforced.
+type BytesMapLimits struct {
+	// MaxEntries is the maximum number of entries in the map.
+	MaxEntries int
+	// MaxKeyLength is the maximum length in bytes of each key.
+	MaxKeyLength int
+	// MaxValueLength is the maximum length in bytes of each value.
+	MaxValueLength int
+	// ValidateUtf8Keys, if true, rejects keys that are not valid UTF-8 strings.
+	ValidateUtf8Keys bool
+}
+
+func (l *BytesMapLimits) checkLength(length int) error {
+	if l != nil && l.MaxEntries > 0 && length > l.MaxEntries {
+		return fmt.Errorf("[bytes map] has too many entries: %d (max %d)", length, l.MaxEntries)
+	}
+	return nil
+}
+
+func (l *BytesMapLimits) checkKeyLength(length int) error {
+	if l != nil && l.MaxKeyLength > 0 && length > l.MaxKeyLength {
+		return fmt.Errorf("key is too long: %d bytes (max %d)", length, l.MaxKeyLength)
+	}
+	return nil
+}
+
+func (l *BytesMapLimits) checkKey(key string) error {
+	if err := l.checkKeyLength(len(key)); err != nil {
+		return err
+	} else if l != nil && l.ValidateUtf8Keys && !utf8.ValidString(key) {
+		return fmt.Errorf("key is not a valid UTF-8 string: %q", key)
+	}
+	return nil
+}
+
+func (l *BytesMapLimits) checkValueLength(length int) error {
+	if l != nil && l.MaxValueLength > 0 && length > l.MaxValueLength {
+		return fmt.Errorf("value is too long: %d bytes (max %d)", length, l.MaxValueLength)
+	}
+	return nil
+}
+
+// CheckBytesMapLimits returns an error if the given map does not honor the given limits. If limits is nil, no check
+// is performed.
+func CheckBytesMapLimits(m map[string][]byte, limits *BytesMapLimits) error {
+	if err := limits.checkLength(len(m)); err != nil {
+		return err
+	}
+	for key, value := range m {
+		if err := limits.checkKey(key); err != nil {
+			return fmt.Errorf("invalid [bytes map] entry key: %w", err)
+		} else if err := limits.checkValueLength(len(value)); err != nil {
+			return fmt.Errorf("invalid [bytes map] entry '%v' value: %w", key, err)
+		}
+	}
+	return nil
+}
+
 func ReadBytesMap(source io.Reader) (map[string][]byte, error) {
+	return ReadBytesMapWithLimits(source, nil)
+}
+
+// ReadBytesMapWithLimits reads a [bytes map] and enforces the given limits. Lengths are checked before the
+// corresponding contents are read, so that oversized entries are rejected without being allocated. If limits is nil,
+// this function behaves exactly like ReadBytesMap.
+func ReadBytesMapWithLimits(source io.Reader, limits *BytesMapLimits) (map[string][]byte, error) {
 	if length, err := ReadShort(source); err != nil {
 		return nil, fmt.Errorf("cannot read [bytes map] length: %w", err)
+	} else if err := limits.checkLength(int(length)); err != nil {
+		return nil, err
 	} else {
 		decoded := make(map[string][]byte, length)
 		for i := uint16(0); i < length; i++ {
-			if key, err := ReadString(source); err != nil {
+			if key, err := readBytesMapKey(source, limits); err != nil {
 				return nil, fmt.Errorf("cannot read [bytes map] entry %d key: %w", i, err)
-			} else if value, err := ReadBytes(source); err != nil {
+			} else if value, err := readBytesMapValue(source, limits); err != nil {
 				return nil, fmt.Errorf("cannot read [bytes map] entry %d value: %w", i, err)
 			} else {
 				decoded[key] = value
@@ -39,6 +108,44 @@ func ReadBytesMap(source io.Reader) (map[string][]byte, error) {
 	}
 }
 
+func readBytesMapKey(source io.Reader, limits *BytesMapLimits) (string, error) {
+	if limits == nil {
+		return ReadString(source)
+	} else if length, err := ReadShort(source); err != nil {
+		return "", fmt.Errorf("cannot read [string] length: %w", err)
+	} else if err := limits.checkKeyLength(int(length)); err != nil {
+		return "", err
+	} else {
+		decoded := make([]byte, length)
+		if _, err := io.ReadFull(source, decoded); err != nil {
+			return "", fmt.Errorf("cannot read [string] content: %w", err)
+		}
+		key := string(decoded)
+		if err := limits.checkKey(key); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+}
+
+func readBytesMapValue(source io.Reader, limits *BytesMapLimits) ([]byte, error) {
+	if limits == nil {
+		return ReadBytes(source)
+	} else if length, err := ReadInt(source); err != nil {
+		return nil, fmt.Errorf("cannot read [bytes] length: %w", err)
+	} else if length < 0 {
+		return nil, nil
+	} else if err := limits.checkValueLength(int(length)); err != nil {
+		return nil, err
+	} else {
+		decoded := make([]byte, length)
+		if _, err := io.ReadFull(source, decoded); err != nil {
+			return nil, fmt.Errorf("cannot read [bytes] content: %w", err)
+		}
+		return decoded, nil
+	}
+}
+
 func WriteBytesMap(m map[string][]byte, dest io.Writer) error {
 	if err := WriteShort(uint16(len(m)), dest); err != nil {
 		return fmt.Errorf("cannot write [bytes map] length: %w", err)
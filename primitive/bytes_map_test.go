@@ -167,3 +167,78 @@ func TestWriteBytesMap(t *testing.T) {
 		})
 	}
 }
+
+func TestReadBytesMapWithLimits(t *testing.T) {
+	limits := &BytesMapLimits{MaxEntries: 1, MaxKeyLength: 5, MaxValueLength: 5, ValidateUtf8Keys: true}
+	tests := []struct {
+		name      string
+		source    []byte
+		expected  map[string][]byte
+		remaining []byte
+		err       error
+	}{
+		{"map 1 key", []byte{
+			0, 1, // map length
+			0, 5, h, e, l, l, o, // key: hello
+			0, 0, 0, 5, w, o, r, l, d, // value1: world
+		}, map[string][]byte{"hello": {w, o, r, l, d}}, []byte{}, nil},
+		{
+			"too many entries",
+			[]byte{0, 2},
+			nil,
+			[]byte{},
+			errors.New("[bytes map] has too many entries: 2 (max 1)"),
+		},
+		{
+			"key too long",
+			[]byte{0, 1, 0, 6, h, e, l, l, o, o},
+			nil,
+			[]byte{h, e, l, l, o, o},
+			fmt.Errorf(
+				"cannot read [bytes map] entry 0 key: %w",
+				errors.New("key is too long: 6 bytes (max 5)"),
+			),
+		},
+		{
+			"key not UTF-8",
+			[]byte{0, 1, 0, 2, 0xc3, 0x28},
+			nil,
+			[]byte{},
+			fmt.Errorf(
+				"cannot read [bytes map] entry 0 key: %w",
+				errors.New("key is not a valid UTF-8 string: \"\\xc3(\""),
+			),
+		},
+		{
+			"value too long",
+			[]byte{0, 1, 0, 1, k, 0, 0, 0, 6, w, o, r, l, d, d},
+			nil,
+			[]byte{w, o, r, l, d, d},
+			fmt.Errorf(
+				"cannot read [bytes map] entry 0 value: %w",
+				errors.New("value is too long: 6 bytes (max 5)"),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewReader(tt.source)
+			actual, err := ReadBytesMapWithLimits(buf, limits)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.err, err)
+			remaining, _ := ioutil.ReadAll(buf)
+			assert.Equal(t, tt.remaining, remaining)
+		})
+	}
+}
+
+func TestCheckBytesMapLimits(t *testing.T) {
+	assert.NoError(t, CheckBytesMapLimits(map[string][]byte{"hello": {w, o, r, l, d}}, nil))
+	assert.NoError(t, CheckBytesMapLimits(map[string][]byte{"hello": {w, o, r, l, d}}, &BytesMapLimits{}))
+	assert.EqualError(t,
+		CheckBytesMapLimits(map[string][]byte{"hello": {w, o, r, l, d}}, &BytesMapLimits{MaxValueLength: 4}),
+		"invalid [bytes map] entry 'hello' value: value is too long: 5 bytes (max 4)")
+	assert.EqualError(t,
+		CheckBytesMapLimits(map[string][]byte{"hello": nil, "world": nil}, &BytesMapLimits{MaxEntries: 1}),
+		"[bytes map] has too many entries: 2 (max 1)")
+}
@@ -18,8 +18,11 @@ import "fmt"
 
 type ProtocolVersion uint8
 
-// Supported OSS versions
+// Supported OSS versions. Protocol versions 1 and 2 use the legacy 8-byte frame header, with a 1-byte stream id, see
+// FrameHeaderLengthInBytes. Protocol version 1 is only supported by frame codecs, see
+// CheckSupportedFramingProtocolVersion; it is not included in SupportedProtocolVersions.
 const (
+	ProtocolVersion1 = ProtocolVersion(0x1)
 	ProtocolVersion2 = ProtocolVersion(0x2)
 	ProtocolVersion3 = ProtocolVersion(0x3)
 	ProtocolVersion4 = ProtocolVersion(0x4)
@@ -44,6 +47,7 @@ func (v ProtocolVersion) IsSupported() bool {
 
 func (v ProtocolVersion) IsOss() bool {
 	switch v {
+	case ProtocolVersion1:
 	case ProtocolVersion2:
 	case ProtocolVersion3:
 	case ProtocolVersion4:
@@ -70,6 +74,8 @@ func (v ProtocolVersion) IsBeta() bool {
 
 func (v ProtocolVersion) String() string {
 	switch v {
+	case ProtocolVersion1:
+		return "ProtocolVersion OSS 1"
 	case ProtocolVersion2:
 		return "ProtocolVersion OSS 2"
 	case ProtocolVersion3:
@@ -106,6 +112,18 @@ func (v ProtocolVersion) SupportsCompression(compression Compression) bool {
 	return false // unknown compression
 }
 
+// SupportsQueryFlags returns true if QUERY and EXECUTE messages carry query flags in this version; in protocol version
+// 1, they only carry a consistency level, along with positional values for EXECUTE messages.
+func (v ProtocolVersion) SupportsQueryFlags() bool {
+	return v >= ProtocolVersion2
+}
+
+// SupportsPreparedResultMetadata returns true if Prepared results carry the metadata of the rows returned by the
+// prepared statement in this version; they don't in protocol version 1.
+func (v ProtocolVersion) SupportsPreparedResultMetadata() bool {
+	return v >= ProtocolVersion2
+}
+
 func (v ProtocolVersion) SupportsBatchQueryFlags() bool {
 	return v >= ProtocolVersion3
 }
@@ -246,6 +264,8 @@ const (
 	FrameHeaderLengthV2AndLower  = 8
 )
 
+// FrameHeaderLengthInBytes returns the length of the frame header for this version: 9 bytes with a 2-byte stream id for
+// versions 3 and higher, and 8 bytes with a 1-byte stream id for versions 1 and 2.
 func (v ProtocolVersion) FrameHeaderLengthInBytes() int {
 	if v >= ProtocolVersion3 {
 		return FrameHeaderLengthV3AndHigher
@@ -262,11 +282,63 @@ func (v ProtocolVersion) SupportsUnsetValues() bool {
 	return v >= ProtocolVersion4
 }
 
+// SupportsOpCode returns true if the given opcode exists in this protocol version, according to the opcode legality
+// matrix. Opcodes unknown to this library, e.g. opcodes of custom message codecs, are considered supported in all
+// versions.
+func (v ProtocolVersion) SupportsOpCode(c OpCode) bool {
+	availability, found := opCodeAvailabilities[c]
+	if !found {
+		return true
+	} else if availability.dseOnly {
+		return v.IsDse()
+	}
+	return (availability.minVersion == 0 || v >= availability.minVersion) &&
+		(availability.maxVersion == 0 || v <= availability.maxVersion)
+}
+
+// opCodeAvailability describes in which protocol versions an opcode exists, and whether its messages carry a body.
+type opCodeAvailability struct {
+	// minVersion is the first protocol version in which the opcode exists, or zero if it exists in all versions.
+	minVersion ProtocolVersion
+	// maxVersion is the last protocol version in which the opcode exists, or zero if it still exists.
+	maxVersion ProtocolVersion
+	// dseOnly is true if the opcode only exists in DSE protocol versions.
+	dseOnly bool
+	// bodyless is true if messages with the opcode never carry a body.
+	bodyless bool
+}
+
+// opCodeAvailabilities is the opcode legality matrix: it maps each known opcode to the protocol versions in which it
+// exists.
+var opCodeAvailabilities = map[OpCode]opCodeAvailability{
+	// requests
+	OpCodeStartup:      {},
+	OpCodeCredentials:  {maxVersion: ProtocolVersion2}, // replaced by AUTH_RESPONSE in version 3
+	OpCodeOptions:      {bodyless: true},
+	OpCodeQuery:        {},
+	OpCodePrepare:      {},
+	OpCodeExecute:      {},
+	OpCodeRegister:     {},
+	OpCodeBatch:        {minVersion: ProtocolVersion2},
+	OpCodeAuthResponse: {minVersion: ProtocolVersion2},
+	OpCodeDseRevise:    {dseOnly: true},
+	// responses
+	OpCodeError:         {},
+	OpCodeReady:         {bodyless: true},
+	OpCodeAuthenticate:  {},
+	OpCodeSupported:     {},
+	OpCodeResult:        {},
+	OpCodeEvent:         {},
+	OpCodeAuthChallenge: {minVersion: ProtocolVersion2},
+	OpCodeAuthSuccess:   {minVersion: ProtocolVersion2},
+}
+
 type OpCode uint8
 
 // requests
 const (
 	OpCodeStartup      = OpCode(0x01)
+	OpCodeCredentials  = OpCode(0x04) // legacy, protocol versions 1 and 2 only
 	OpCodeOptions      = OpCode(0x05)
 	OpCodeQuery        = OpCode(0x07)
 	OpCodePrepare      = OpCode(0x09)
@@ -292,6 +364,7 @@ const (
 func (c OpCode) IsValid() bool {
 	switch c {
 	case OpCodeStartup:
+	case OpCodeCredentials:
 	case OpCodeOptions:
 	case OpCodeQuery:
 	case OpCodePrepare:
@@ -317,6 +390,7 @@ func (c OpCode) IsValid() bool {
 func (c OpCode) IsRequest() bool {
 	switch c {
 	case OpCodeStartup:
+	case OpCodeCredentials:
 	case OpCodeOptions:
 	case OpCodeQuery:
 	case OpCodePrepare:
@@ -347,6 +421,11 @@ func (c OpCode) IsResponse() bool {
 	return true
 }
 
+// IsBodyless returns true if messages with this opcode never carry a body, e.g. READY and OPTIONS.
+func (c OpCode) IsBodyless() bool {
+	return opCodeAvailabilities[c].bodyless
+}
+
 func (c OpCode) IsDse() bool {
 	switch c {
 	case OpCodeDseRevise:
@@ -360,6 +439,8 @@ func (c OpCode) String() string {
 	switch c {
 	case OpCodeStartup:
 		return "OpCode STARTUP [0x01]"
+	case OpCodeCredentials:
+		return "OpCode CREDENTIALS [0x04]"
 	case OpCodeOptions:
 		return "OpCode OPTIONS [0x05]"
 	case OpCodeQuery:
@@ -1046,6 +1127,13 @@ const (
 	HeaderFlagUseBeta       = HeaderFlag(0x10)
 )
 
+// KnownHeaderFlags contains all the header flags defined by the protocol specification.
+const KnownHeaderFlags = HeaderFlagCompressed |
+	HeaderFlagTracing |
+	HeaderFlagCustomPayload |
+	HeaderFlagWarning |
+	HeaderFlagUseBeta
+
 func (f HeaderFlag) Add(other HeaderFlag) HeaderFlag {
 	return f | other
 }
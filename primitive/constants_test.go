@@ -83,3 +83,44 @@ func TestDataTypeCode_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestProtocolVersion_SupportsOpCode(t *testing.T) {
+	tests := []struct {
+		name string
+		v    ProtocolVersion
+		c    OpCode
+		want bool
+	}{
+		{"v2 CREDENTIALS", ProtocolVersion2, OpCodeCredentials, true},
+		{"v3 CREDENTIALS", ProtocolVersion3, OpCodeCredentials, false},
+		{"DSE v1 CREDENTIALS", ProtocolVersionDse1, OpCodeCredentials, false},
+		{"v2 AUTH_RESPONSE", ProtocolVersion2, OpCodeAuthResponse, true},
+		{"v4 REVISE_REQUEST", ProtocolVersion4, OpCodeDseRevise, false},
+		{"DSE v2 REVISE_REQUEST", ProtocolVersionDse2, OpCodeDseRevise, true},
+		{"v5 QUERY", ProtocolVersion5, OpCodeQuery, true},
+		{"v4 unknown", ProtocolVersion4, OpCode(0x42), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.SupportsOpCode(tt.c); got != tt.want {
+				t.Errorf("SupportsOpCode() = %v, want %v", got, tt.want)
+			}
+			if err := CheckSupportedOpCode(tt.c, tt.v); (err == nil) != tt.want {
+				t.Errorf("CheckSupportedOpCode() = %v, want error: %v", err, !tt.want)
+			}
+		})
+	}
+}
+
+func TestOpCode_IsBodyless(t *testing.T) {
+	for _, c := range []OpCode{OpCodeReady, OpCodeOptions} {
+		if !c.IsBodyless() {
+			t.Errorf("%v.IsBodyless() = false, want true", c)
+		}
+	}
+	for _, c := range []OpCode{OpCodeStartup, OpCodeQuery, OpCodeResult, OpCode(0x42)} {
+		if c.IsBodyless() {
+			t.Errorf("%v.IsBodyless() = true, want false", c)
+		}
+	}
+}
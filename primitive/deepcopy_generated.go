@@ -65,23 +65,3 @@ func (in *Inet) DeepCopy() *Inet {
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Value) DeepCopyInto(out *Value) {
-	*out = *in
-	if in.Contents != nil {
-		in, out := &in.Contents, &out.Contents
-		*out = make([]byte, len(*in))
-		copy(*out, *in)
-	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Value.
-func (in *Value) DeepCopy() *Value {
-	if in == nil {
-		return nil
-	}
-	out := new(Value)
-	in.DeepCopyInto(out)
-	return out
-}
@@ -0,0 +1,43 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"io"
+)
+
+// Slicer is a source that can hand out its next bytes without copying them. When the source passed to ReadBytes,
+// ReadShortBytes or ReadValue implements Slicer, the decoded contents are slices of the source's underlying memory
+// instead of newly allocated byte slices; this is used to decode frames in an arena, see frame.Arena.
+type Slicer interface {
+	io.Reader
+
+	// Slice returns the next length bytes of the source and advances past them. The returned slice must not be
+	// modified, and its capacity must be equal to its length so that appending to it never overwrites the source. It
+	// returns io.ErrUnexpectedEOF if less than length bytes remain.
+	Slice(length int) ([]byte, error)
+}
+
+// readContents reads the given number of bytes from the source, without copying them if the source is a Slicer.
+func readContents(source io.Reader, length int) ([]byte, error) {
+	if slicer, ok := source.(Slicer); ok {
+		return slicer.Slice(length)
+	}
+	decoded := make([]byte, length)
+	if _, err := io.ReadFull(source, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
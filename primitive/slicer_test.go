@@ -0,0 +1,67 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSlicer is a minimal Slicer over a byte slice.
+type testSlicer struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (s *testSlicer) Slice(length int) ([]byte, error) {
+	pos := len(s.data) - s.Len()
+	if length > s.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	_, _ = s.Seek(int64(length), io.SeekCurrent)
+	return s.data[pos : pos+length : pos+length], nil
+}
+
+func newTestSlicer(data []byte) *testSlicer {
+	return &testSlicer{Reader: bytes.NewReader(data), data: data}
+}
+
+func TestReadContents_Slicer(t *testing.T) {
+	data := []byte{
+		0, 0, 0, 2, 0xca, 0xfe, // [bytes]
+		0, 1, 0xba, // [short bytes]
+		0, 0, 0, 1, 0xbe, // [value]
+	}
+	source := newTestSlicer(data)
+	b, err := ReadBytes(source)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xca, 0xfe}, b)
+	assert.Same(t, &data[4], &b[0])
+	assert.Equal(t, 2, cap(b))
+	sb, err := ReadShortBytes(source)
+	require.NoError(t, err)
+	assert.Same(t, &data[8], &sb[0])
+	value, err := ReadValue(source, ProtocolVersion4)
+	require.NoError(t, err)
+	assert.Same(t, &data[13], &value.Contents[0])
+
+	_, err = ReadBytes(newTestSlicer([]byte{0, 0, 0, 2, 0xca}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read [bytes] content: unexpected EOF")
+}
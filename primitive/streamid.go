@@ -32,13 +32,30 @@ func ReadStreamId(source io.Reader, version ProtocolVersion) (int16, error) {
 	}
 }
 
+// MaxStreamId returns the maximum stream id allowed by the given version: math.MaxInt8 for versions 1 and 2, where
+// stream ids are 8-bit integers, and math.MaxInt16 for versions 3+, where stream ids are 16-bit integers.
+func MaxStreamId(version ProtocolVersion) int16 {
+	if version >= ProtocolVersion3 {
+		return math.MaxInt16
+	}
+	return math.MaxInt8
+}
+
+// CheckValidStreamId returns an error if the given stream id does not fit in the stream id range of the given version.
+func CheckValidStreamId(streamId int16, version ProtocolVersion) error {
+	if streamId > MaxStreamId(version) || streamId < -MaxStreamId(version)-1 {
+		return fmt.Errorf("stream id out of range for %v: %v", version, streamId)
+	}
+	return nil
+}
+
 // WriteStreamId writes the given stream id to the given destination, using the given version to determine if the
 // stream id is a 16-bit integer (versions 3+) or an 8-bit integer (versions 1 and 2).
 func WriteStreamId(streamId int16, dest io.Writer, version ProtocolVersion) error {
 	if version >= ProtocolVersion3 {
 		return WriteShort(uint16(streamId), dest)
-	} else if streamId > math.MaxInt8 || streamId < math.MinInt8 {
-		return fmt.Errorf("stream id out of range for %v: %v", version, streamId)
+	} else if err := CheckValidStreamId(streamId, version); err != nil {
+		return err
 	} else {
 		return WriteByte(uint8(streamId), dest)
 	}
@@ -188,3 +188,22 @@ func TestWriteStreamId(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckValidStreamId(t *testing.T) {
+	for _, version := range SupportedProtocolVersionsLesserThanOrEqualTo(ProtocolVersion2) {
+		t.Run(version.String(), func(t *testing.T) {
+			assert.Equal(t, int16(math.MaxInt8), MaxStreamId(version))
+			assert.Nil(t, CheckValidStreamId(math.MaxInt8, version))
+			assert.Nil(t, CheckValidStreamId(math.MinInt8, version))
+			assert.Equal(t, fmt.Errorf("stream id out of range for %v: 128", version), CheckValidStreamId(128, version))
+			assert.Equal(t, fmt.Errorf("stream id out of range for %v: -129", version), CheckValidStreamId(-129, version))
+		})
+	}
+	for _, version := range SupportedProtocolVersionsGreaterThanOrEqualTo(ProtocolVersion3) {
+		t.Run(version.String(), func(t *testing.T) {
+			assert.Equal(t, int16(math.MaxInt16), MaxStreamId(version))
+			assert.Nil(t, CheckValidStreamId(math.MaxInt16, version))
+			assert.Nil(t, CheckValidStreamId(math.MinInt16, version))
+		})
+	}
+}
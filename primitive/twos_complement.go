@@ -0,0 +1,70 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import "math/big"
+
+var oneBigInt = big.NewInt(1)
+
+// Implementation note: the encoding scheme used for CQL varint, and for the unscaled value of CQL decimal, is dictated
+// by Java's implementation of BigInteger.toByteArray(). This scheme has nothing to do with the "Varint" functions
+// declared in Go's binary package, nor with [vint].
+// Relevant readings for varint encoding in Go:
+// https://groups.google.com/g/golang-nuts/c/TV4bRVrHZUw
+// https://github.com/gocql/gocql/blob/go1.2/marshal.go#L729-L767
+
+// EncodeTwosComplement encodes the given integer as a big-endian two's-complement byte slice of arbitrary length.
+// The encoding is minimal: it contains the minimum number of bytes required to represent the integer, including at
+// least one sign bit; e.g. 127 is encoded as 0x7f, but 128 is encoded as 0x0080. Zero is encoded as a single zero
+// byte. If the integer is nil, nil is returned.
+func EncodeTwosComplement(n *big.Int) []byte {
+	if n == nil {
+		return nil
+	}
+	switch n.Sign() {
+	case 1:
+		b := n.Bytes()
+		if b[0]&0x80 > 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	case -1:
+		length := uint(n.BitLen()/8+1) * 8
+		b := new(big.Int).Add(n, new(big.Int).Lsh(oneBigInt, length)).Bytes()
+		// When the most significant bit is on a byte
+		// boundary, we can get some extra significant
+		// bits, so strip them off when that happens.
+		if len(b) >= 2 && b[0] == 0xff && b[1]&0x80 != 0 {
+			b = b[1:]
+		}
+		return b
+	default:
+		return []byte{0}
+	}
+}
+
+// DecodeTwosComplement decodes the given big-endian two's-complement byte slice of arbitrary length into an integer.
+// The encoding does not need to be minimal: redundant sign-extension bytes, e.g. 0xffff for -1, are accepted. If the
+// source is empty, nil is returned.
+func DecodeTwosComplement(source []byte) (val *big.Int) {
+	length := len(source)
+	if length > 0 {
+		val = new(big.Int).SetBytes(source)
+		if source[0]&0x80 > 0 {
+			val.Sub(val, new(big.Int).Lsh(oneBigInt, uint(length)*8))
+		}
+	}
+	return
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package primitive
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeTwosComplement(t *testing.T) {
+	hugeNeg, ok := new(big.Int).SetString("-1042342234234123423435647768234", 10)
+	assert.True(t, ok)
+	tests := []struct {
+		name     string
+		val      *big.Int
+		expected []byte
+	}{
+		{"nil", nil, nil},
+		{"zero", big.NewInt(0), []byte{0}},
+		{"1", big.NewInt(1), []byte{1}},
+		{"-1", big.NewInt(-1), []byte{0xff}},
+		{"100", big.NewInt(100), []byte{0x64}},
+		{"-100", big.NewInt(-100), []byte{0x9c}},
+		{"127", big.NewInt(127), []byte{0x7f}},
+		{"128", big.NewInt(128), []byte{0x00, 0x80}},
+		{"-128", big.NewInt(-128), []byte{0x80}},
+		{"-129", big.NewInt(-129), []byte{0xff, 0x7f}},
+		{"255", big.NewInt(255), []byte{0x00, 0xff}},
+		{"256", big.NewInt(256), []byte{0x01, 0x00}},
+		{"-256", big.NewInt(-256), []byte{0xff, 0x00}},
+		{"MinInt16", big.NewInt(math.MinInt16), []byte{0x80, 0x00}},
+		{"MaxInt16", big.NewInt(math.MaxInt16), []byte{0x7f, 0xff}},
+		{"MinInt32", big.NewInt(math.MinInt32), []byte{0x80, 0x00, 0x00, 0x00}},
+		{"MaxInt32", big.NewInt(math.MaxInt32), []byte{0x7f, 0xff, 0xff, 0xff}},
+		{"MinInt64", big.NewInt(math.MinInt64), []byte{0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{"MaxInt64", big.NewInt(math.MaxInt64), []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"MinInt64-1", new(big.Int).Sub(big.NewInt(math.MinInt64), big.NewInt(1)), []byte{0xff, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge neg", hugeNeg, []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}},
+		{"huge pos", new(big.Int).SetUint64(math.MaxUint64), []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := EncodeTwosComplement(tt.val)
+			assert.Equal(t, tt.expected, actual)
+			if tt.val != nil {
+				assert.Zero(t, tt.val.Cmp(DecodeTwosComplement(actual)))
+			}
+		})
+	}
+}
+
+func TestDecodeTwosComplement(t *testing.T) {
+	hugeNeg, ok := new(big.Int).SetString("-1042342234234123423435647768234", 10)
+	assert.True(t, ok)
+	tests := []struct {
+		name     string
+		source   []byte
+		expected *big.Int
+	}{
+		{"zero", []byte{0}, big.NewInt(0)},
+		{"-1", []byte{0xff}, big.NewInt(-1)},
+		{"100", []byte{0x64}, big.NewInt(100)},
+		{"-100", []byte{0x9c}, big.NewInt(-100)},
+		{"128", []byte{0x00, 0x80}, big.NewInt(128)},
+		{"-128", []byte{0x80}, big.NewInt(-128)},
+		{"255", []byte{0x00, 0xff}, big.NewInt(255)},
+		{"huge neg", []byte{0xf2, 0xd8, 0x02, 0xb6, 0x52, 0x7f, 0x99, 0xee, 0x98, 0x23, 0x99, 0xa9, 0x56}, hugeNeg},
+		{"huge pos", []byte{0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, new(big.Int).SetUint64(math.MaxUint64)},
+		// non-minimal encodings with redundant sign extension
+		{"zero sign-extended", []byte{0, 0, 0}, big.NewInt(0)},
+		{"-1 sign-extended", []byte{0xff, 0xff, 0xff, 0xff}, big.NewInt(-1)},
+		{"1 sign-extended", []byte{0, 0, 0, 1}, big.NewInt(1)},
+		{"-128 sign-extended", []byte{0xff, 0xff, 0x80}, big.NewInt(-128)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := DecodeTwosComplement(tt.source)
+			assert.Zero(t, tt.expected.Cmp(actual), "expected %v, got %v", tt.expected, actual)
+		})
+	}
+	assert.Nil(t, DecodeTwosComplement(nil))
+	assert.Nil(t, DecodeTwosComplement([]byte{}))
+}
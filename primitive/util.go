@@ -86,6 +86,30 @@ func CheckSupportedProtocolVersion(version ProtocolVersion) error {
 	return nil
 }
 
+// CheckSupportedFramingProtocolVersion returns an error if frames of the given version cannot be encoded nor decoded.
+// Frame codecs support all the versions returned by SupportedProtocolVersions, and protocol version 1, which shares the
+// legacy frame header and most message layouts of protocol version 2.
+func CheckSupportedFramingProtocolVersion(version ProtocolVersion) error {
+	if version != ProtocolVersion1 && !version.IsSupported() {
+		return fmt.Errorf("invalid protocol version: %v", version)
+	}
+	return nil
+}
+
+func CheckLegacyCredentialsProtocolVersion(version ProtocolVersion) error {
+	if !version.SupportsOpCode(OpCodeCredentials) {
+		return fmt.Errorf("CREDENTIALS messages are not supported in %v", version)
+	}
+	return nil
+}
+
+func CheckSupportedOpCode(code OpCode, version ProtocolVersion) error {
+	if !version.SupportsOpCode(code) {
+		return fmt.Errorf("%v is not supported in %v", code, version)
+	}
+	return nil
+}
+
 func CheckDseProtocolVersion(version ProtocolVersion) error {
 	if !version.IsDse() {
 		return fmt.Errorf("invalid DSE protocol version: %v", version)
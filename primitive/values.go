@@ -18,6 +18,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"sync"
 )
 
 type ValueType = int32
@@ -29,10 +31,25 @@ const (
 )
 
 // Value models the [value] protocol primitive structure.
-// +k8s:deepcopy-gen=true
+// Its deep copy functions are not generated, because of stream values, see DeepCopyInto.
+// +k8s:deepcopy-gen=false
 type Value struct {
 	Type     ValueType
 	Contents []byte
+	// stream, if not nil, supplies the contents of a regular value when it is written, see NewStreamValue.
+	stream *valueStream
+}
+
+type valueStream struct {
+	lock   sync.Mutex
+	source io.Reader
+	length int
+	// written is true once the contents were streamed from the source.
+	written bool
+	// buffered holds the contents read from the source, if the value was copied, see Value.DeepCopyInto.
+	buffered []byte
+	// err is the error that occurred when reading the contents into buffered, if any.
+	err error
 }
 
 func NewValue(contents []byte) *Value {
@@ -43,6 +60,22 @@ func NewValue(contents []byte) *Value {
 	}
 }
 
+// NewStreamValue creates a regular value whose contents, of the given length, are read from the given source only when
+// the value is written, instead of being held in memory. This allows very large values, e.g. blobs of hundreds of
+// megabytes, to be streamed into the frame body. Since the source is consumed when the value is written, such a value
+// can only be written once, and its Contents field is always nil. Writing fails if the source cannot supply exactly
+// the given number of bytes. Copying a stream value, e.g. with DeepCopy, or with Frame.DeepCopy to retry a request,
+// reads its whole contents into memory, so that the value and its copy can both be written, any number of times; if
+// the value was already written, its copies fail to be written.
+func NewStreamValue(source io.Reader, length int) *Value {
+	return &Value{Type: ValueTypeRegular, stream: &valueStream{source: source, length: length}}
+}
+
+// IsStream returns true if the value was created with NewStreamValue.
+func (v *Value) IsStream() bool {
+	return v.stream != nil
+}
+
 func NewNullValue() *Value {
 	return NewValue(nil)
 }
@@ -54,26 +87,36 @@ func NewUnsetValue() *Value {
 // [value]
 
 func ReadValue(source io.Reader, version ProtocolVersion) (*Value, error) {
+	value := &Value{}
+	if err := readValueInto(value, source, version); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// readValueInto reads a [value] into the given Value, overwriting all its fields.
+func readValueInto(value *Value, source io.Reader, version ProtocolVersion) error {
 	if length, err := ReadInt(source); err != nil {
-		return nil, fmt.Errorf("cannot read [value] length: %w", err)
+		return fmt.Errorf("cannot read [value] length: %w", err)
 	} else if length == ValueTypeNull {
-		return NewNullValue(), nil
+		*value = Value{Type: ValueTypeNull}
 	} else if length == ValueTypeUnset {
 		if version < ProtocolVersion4 {
-			return nil, fmt.Errorf("cannot use unset value with %v", version)
+			return fmt.Errorf("cannot use unset value with %v", version)
 		}
-		return NewUnsetValue(), nil
+		*value = Value{Type: ValueTypeUnset}
 	} else if length < 0 {
-		return nil, fmt.Errorf("invalid [value] length: %v", length)
+		return fmt.Errorf("invalid [value] length: %v", length)
 	} else if length == 0 {
-		return NewValue([]byte{}), nil
+		*value = Value{Type: ValueTypeRegular, Contents: []byte{}}
 	} else {
-		decoded := make([]byte, length)
-		if _, err := io.ReadFull(source, decoded); err != nil {
-			return nil, fmt.Errorf("cannot read [value] content: %w", err)
+		decoded, err := readContents(source, int(length))
+		if err != nil {
+			return fmt.Errorf("cannot read [value] content: %w", err)
 		}
-		return NewValue(decoded), nil
+		*value = Value{Type: ValueTypeRegular, Contents: decoded}
 	}
+	return nil
 }
 
 func WriteValue(value *Value, dest io.Writer, version ProtocolVersion) error {
@@ -89,7 +132,9 @@ func WriteValue(value *Value, dest io.Writer, version ProtocolVersion) error {
 		}
 		return WriteInt(ValueTypeUnset, dest)
 	case ValueTypeRegular:
-		if value.Contents == nil {
+		if value.stream != nil {
+			return writeStreamValue(value.stream, dest)
+		} else if value.Contents == nil {
 			return WriteInt(ValueTypeNull, dest)
 		} else {
 			length := len(value.Contents)
@@ -107,6 +152,87 @@ func WriteValue(value *Value, dest io.Writer, version ProtocolVersion) error {
 	}
 }
 
+func writeStreamValue(stream *valueStream, dest io.Writer) error {
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	if stream.length < 0 || stream.length > math.MaxInt32 {
+		return fmt.Errorf("invalid [value] length: %v", stream.length)
+	} else if stream.err != nil {
+		return stream.err
+	} else if stream.written {
+		return errors.New("cannot write [value] content: stream value already written")
+	} else if err := WriteInt(int32(stream.length), dest); err != nil {
+		return fmt.Errorf("cannot write [value] length: %w", err)
+	} else if stream.buffered != nil {
+		if _, err := dest.Write(stream.buffered); err != nil {
+			return fmt.Errorf("cannot write [value] content: %w", err)
+		}
+		return nil
+	}
+	stream.written = true
+	if n, err := io.CopyN(dest, stream.source, int64(stream.length)); err == io.EOF {
+		return fmt.Errorf("cannot write [value] content: source ended after %d of %d bytes", n, stream.length)
+	} else if err != nil {
+		return fmt.Errorf("cannot write [value] content: %w", err)
+	}
+	return nil
+}
+
+// copy reads the contents of this stream into memory, if not done yet, so that they can be written several times, then
+// returns a copy of this stream. The buffered contents are shared, but never modified.
+func (s *valueStream) copy() *valueStream {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.buffered == nil && s.err == nil {
+		s.buffer()
+	}
+	return &valueStream{length: s.length, buffered: s.buffered, err: s.err}
+}
+
+// buffer reads the contents of this stream into memory. Must be called while holding the lock.
+func (s *valueStream) buffer() {
+	if s.written {
+		s.err = errors.New("cannot write [value] content: stream value was copied after being written")
+	} else if s.length < 0 || s.length > math.MaxInt32 {
+		s.err = fmt.Errorf("invalid [value] length: %v", s.length)
+	} else {
+		buffered := make([]byte, s.length)
+		if n, err := io.ReadFull(s.source, buffered); err == io.EOF || err == io.ErrUnexpectedEOF {
+			s.err = fmt.Errorf("cannot write [value] content: source ended after %d of %d bytes", n, s.length)
+		} else if err != nil {
+			s.err = fmt.Errorf("cannot write [value] content: %w", err)
+		} else {
+			s.buffered = buffered
+		}
+	}
+	s.source = nil
+}
+
+// DeepCopyInto copies the receiver, writing into out. in must be non-nil. The contents of stream values are read into
+// memory, so that both values can be written, see NewStreamValue.
+func (in *Value) DeepCopyInto(out *Value) {
+	out.Type = in.Type
+	out.Contents = nil
+	out.stream = nil
+	if in.Contents != nil {
+		out.Contents = make([]byte, len(in.Contents))
+		copy(out.Contents, in.Contents)
+	}
+	if in.stream != nil {
+		out.stream = in.stream.copy()
+	}
+}
+
+// DeepCopy copies the receiver, creating a new Value, see DeepCopyInto.
+func (in *Value) DeepCopy() *Value {
+	if in == nil {
+		return nil
+	}
+	out := new(Value)
+	in.DeepCopyInto(out)
+	return out
+}
+
 func LengthOfValue(value *Value) (int, error) {
 	if value == nil {
 		return -1, errors.New("cannot compute length of a nil [value]")
@@ -117,6 +243,9 @@ func LengthOfValue(value *Value) (int, error) {
 	case ValueTypeUnset:
 		return LengthOfInt, nil
 	case ValueTypeRegular:
+		if value.stream != nil {
+			return LengthOfInt + value.stream.length, nil
+		}
 		return LengthOfInt + len(value.Contents), nil
 	default:
 		return -1, fmt.Errorf("unknown [value] type: %v", value.Type)
@@ -141,6 +270,33 @@ func ReadPositionalValues(source io.Reader, version ProtocolVersion) ([]*Value,
 	}
 }
 
+// ReadPositionalValuesInto is similar to ReadPositionalValues, but reuses the given slice, and the values it points to,
+// instead of allocating new ones whenever possible; the returned slice must be used instead of the given one. This is
+// useful in hot loops decoding many messages. Since reused values are overwritten, references to them that were
+// retained by the caller must not be used anymore.
+func ReadPositionalValuesInto(dest []*Value, source io.Reader, version ProtocolVersion) ([]*Value, error) {
+	length, err := ReadShort(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read positional [value]s length: %w", err)
+	}
+	if dest == nil || cap(dest) < int(length) {
+		grown := make([]*Value, length)
+		copy(grown, dest[:cap(dest)])
+		dest = grown
+	} else {
+		dest = dest[:length]
+	}
+	for i := range dest {
+		if dest[i] == nil {
+			dest[i] = &Value{}
+		}
+		if err := readValueInto(dest[i], source, version); err != nil {
+			return nil, fmt.Errorf("cannot read positional [value]s element %d content: %w", i, err)
+		}
+	}
+	return dest, nil
+}
+
 func WritePositionalValues(values []*Value, dest io.Writer, version ProtocolVersion) error {
 	length := len(values)
 	if err := WriteShort(uint16(length), dest); err != nil {
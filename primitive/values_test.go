@@ -418,6 +418,54 @@ func TestLengthOfValue(t *testing.T) {
 	}
 }
 
+func TestStreamValue(t *testing.T) {
+	value := NewStreamValue(bytes.NewReader([]byte{1, 2, 3, 4, 5}), 4)
+	assert.True(t, value.IsStream())
+	assert.False(t, NewValue([]byte{1, 2, 3, 4}).IsStream())
+	length, err := LengthOfValue(value)
+	assert.NoError(t, err)
+	assert.Equal(t, LengthOfInt+4, length)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteValue(value, buf, ProtocolVersion4))
+	assert.Equal(t, []byte{0, 0, 0, 4, 1, 2, 3, 4}, buf.Bytes())
+	assert.Nil(t, value.Contents)
+
+	buf.Reset()
+	err = WriteValue(NewStreamValue(bytes.NewReader([]byte{1, 2}), 4), buf, ProtocolVersion4)
+	assert.Equal(t, errors.New("cannot write [value] content: source ended after 2 of 4 bytes"), err)
+
+	buf.Reset()
+	err = WriteValue(NewStreamValue(bytes.NewReader(nil), -1), buf, ProtocolVersion4)
+	assert.Equal(t, errors.New("invalid [value] length: -1"), err)
+
+	// stream values can only be written once
+	buf.Reset()
+	err = WriteValue(value, buf, ProtocolVersion4)
+	assert.Equal(t, errors.New("cannot write [value] content: stream value already written"), err)
+	err = WriteValue(value.DeepCopy(), buf, ProtocolVersion4)
+	assert.Equal(t, errors.New("cannot write [value] content: stream value was copied after being written"), err)
+}
+
+func TestStreamValue_DeepCopy(t *testing.T) {
+	value := NewStreamValue(bytes.NewReader([]byte{1, 2, 3, 4, 5}), 4)
+	copied := value.DeepCopy()
+	assert.True(t, copied.IsStream())
+	assert.Nil(t, copied.Contents)
+	length, err := LengthOfValue(copied)
+	assert.NoError(t, err)
+	assert.Equal(t, LengthOfInt+4, length)
+	// both the copy and the original can be written several times
+	for _, v := range []*Value{copied, copied, value, value, copied.DeepCopy()} {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, WriteValue(v, buf, ProtocolVersion4))
+		assert.Equal(t, []byte{0, 0, 0, 4, 1, 2, 3, 4}, buf.Bytes())
+	}
+
+	short := NewStreamValue(bytes.NewReader([]byte{1, 2}), 4).DeepCopy()
+	err = WriteValue(short, &bytes.Buffer{}, ProtocolVersion4)
+	assert.Equal(t, errors.New("cannot write [value] content: source ended after 2 of 4 bytes"), err)
+}
+
 func TestReadPositionalValues(t *testing.T) {
 	for _, version := range SupportedProtocolVersions() {
 		t.Run(version.String(), func(t *testing.T) {
@@ -512,6 +560,33 @@ func TestReadPositionalValues(t *testing.T) {
 	}
 }
 
+func TestReadPositionalValuesInto(t *testing.T) {
+	first := []byte{
+		0, 2, // length of list
+		0, 0, 0, 1, 0xca, // element 1
+		0xff, 0xff, 0xff, 0xff, // element 2: null
+	}
+	values, err := ReadPositionalValuesInto(nil, bytes.NewBuffer(first), ProtocolVersion4)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Value{NewValue([]byte{0xca}), NewNullValue()}, values)
+	reused := values[1]
+	second := []byte{
+		0, 2, // length of list
+		0xff, 0xff, 0xff, 0xfe, // element 1: unset
+		0, 0, 0, 1, 0xfe, // element 2
+	}
+	values, err = ReadPositionalValuesInto(values, bytes.NewBuffer(second), ProtocolVersion4)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Value{NewUnsetValue(), NewValue([]byte{0xfe})}, values)
+	assert.Same(t, reused, values[1])
+	values, err = ReadPositionalValuesInto(values, bytes.NewBuffer([]byte{0, 0}), ProtocolVersion4)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Value{}, values)
+	values, err = ReadPositionalValuesInto(values, bytes.NewBuffer(first[:5]), ProtocolVersion4)
+	assert.Nil(t, values)
+	assert.Contains(t, err.Error(), "cannot read positional [value]s element 0 content")
+}
+
 func TestWritePositionalValues(t *testing.T) {
 	// versions < 4
 	for _, version := range SupportedProtocolVersionsLesserThan(ProtocolVersion4) {
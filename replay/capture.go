@@ -0,0 +1,137 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+// CapturedFrame is a frame of a Capture.
+type CapturedFrame struct {
+	// At is the time elapsed since the first frame of the capture, or zero if unknown.
+	At time.Duration
+	// Frame is the captured frame.
+	Frame *frame.Frame
+	// Compression is the compression algorithm negotiated on the connection the frame was captured from.
+	Compression primitive.Compression
+}
+
+// Capture is the ordered sequence of frames exchanged on a client connection, requests and responses alike.
+type Capture struct {
+	Frames []*CapturedFrame
+}
+
+// ReadCapture reads a capture in text form, see the package documentation.
+func ReadCapture(source io.Reader) (*Capture, error) {
+	capture := &Capture{}
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 64*1024), 512*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		captured, err := parseCapturedFrame(line)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read capture: line %d: %w", lineNumber, err)
+		}
+		capture.Frames = append(capture.Frames, captured)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read capture: %w", err)
+	}
+	return capture, nil
+}
+
+func parseCapturedFrame(line string) (*CapturedFrame, error) {
+	captured := &CapturedFrame{}
+	if strings.HasPrefix(line, "at=") {
+		fields := strings.SplitN(line, " ", 2)
+		at, err := time.ParseDuration(strings.TrimPrefix(fields[0], "at="))
+		if err != nil {
+			return nil, fmt.Errorf("invalid frame time: %w", err)
+		} else if len(fields) < 2 {
+			return nil, fmt.Errorf("missing frame dump")
+		}
+		captured.At, line = at, fields[1]
+	}
+	dump, err := frame.ParseFrameDump(line)
+	if err != nil {
+		return nil, err
+	}
+	codec := frame.NewCodecWithCompression(client.NewBodyCompressor(dump.Compression))
+	if captured.Frame, err = dump.Decode(codec); err != nil {
+		return nil, fmt.Errorf("cannot decode frame dump: %w", err)
+	}
+	captured.Compression = dump.Compression
+	return captured, nil
+}
+
+// Add adds the given frame to this capture; it is useful to build captures programmatically, e.g. from a proxy. The
+// frame is captured at the given time since the first frame, and with the given compression algorithm.
+func (c *Capture) Add(f *frame.Frame, at time.Duration, compression primitive.Compression) {
+	if compression == "" {
+		compression = primitive.CompressionNone
+	}
+	c.Frames = append(c.Frames, &CapturedFrame{At: at, Frame: f, Compression: compression})
+}
+
+// NewCaptureFromRecorder creates a capture from the frames recorded by the given recorder on the given connection, see
+// client.RecordedFrame.Connection; if the connection is empty, the connection of the first recorded frame is used. The
+// recorder must keep frames, see client.FrameRecorder.KeepFrames. The frames are captured at the time
+// they were recorded, relative to the first one, and can be recorded on either side of the connection: requests and
+// responses are told apart by their headers.
+func NewCaptureFromRecorder(recorder *client.FrameRecorder, connection string) (*Capture, error) {
+	capture := &Capture{}
+	var start time.Time
+	for i, recorded := range recorder.Frames() {
+		if connection == "" {
+			connection = recorded.Connection
+		} else if recorded.Connection != connection {
+			continue
+		}
+		if recorded.Frame == nil {
+			return nil, fmt.Errorf("cannot create capture: frame %d was recorded without its contents", i)
+		}
+		if start.IsZero() {
+			start = recorded.Time
+		}
+		capture.Add(recorded.Frame, recorded.Time.Sub(start), recorded.Compression)
+	}
+	return capture, nil
+}
+
+// WriteTo writes this capture in text form to the given writer, see the package documentation.
+func (c *Capture) WriteTo(w io.Writer) (int64, error) {
+	sb := &strings.Builder{}
+	for i, captured := range c.Frames {
+		codec := frame.NewCodecWithCompression(client.NewBodyCompressor(captured.Compression))
+		dump, err := frame.DumpFrame(captured.Frame, codec, captured.Compression, frame.DumpEncodingHex)
+		if err != nil {
+			return 0, fmt.Errorf("cannot write capture: frame %d: %w", i, err)
+		}
+		_, _ = fmt.Fprintf(sb, "at=%v %v\n", captured.At, dump)
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
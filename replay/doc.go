@@ -0,0 +1,33 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replay contains tools to replay captured CQL traffic against a server, and to report the responses that
+// differ from the captured ones.
+//
+// A Capture is the ordered sequence of frames exchanged on a client connection, requests and responses alike. Its text
+// form has one frame per line, in the frame dump format, see frame.FrameDump, optionally preceded by the time elapsed
+// since the first frame, used for pacing; blank lines and lines starting with # are ignored:
+//
+//  at=0s version=4 compression=NONE hex=040000010500000000
+//  at=2ms version=4 compression=NONE hex=8400000106000000020000
+//
+// Captures can be created programmatically with Capture.Add, or from the frames recorded on a connection by a
+// client.FrameRecorder configured to keep frames, see NewCaptureFromRecorder; Capture.WriteTo then writes them in text
+// form. Packet captures, e.g. pcap files, are not supported: they must be converted to the text form first.
+//
+// The Replayer replays the client side of a capture against a target server, and produces a Report. The same
+// functionality is available from the command line with the cqlreplay tool:
+//
+//  go run github.com/datastax/go-cassandra-native-protocol/cmd/cqlreplay -address 127.0.0.1:9042 -capture capture.txt
+package replay
@@ -0,0 +1,230 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const replayTestAddress = "127.0.0.1:9045"
+
+// overloadedHandler responds to INSERT queries with an Overloaded error.
+var overloadedHandler client.RequestHandler = func(request *frame.Frame, _ *client.CqlServerConnection, _ client.RequestHandlerContext) *frame.Frame {
+	if query, ok := request.Body.Message.(*message.Query); ok && strings.HasPrefix(query.Query, "INSERT") {
+		return frame.NewFrame(request.Header.Version, request.Header.StreamId, &message.Overloaded{ErrorMessage: "overloaded"})
+	}
+	return nil
+}
+
+func newTestCapture() *Capture {
+	v := primitive.ProtocolVersion4
+	response := func(streamId int16, msg message.Message) *frame.Frame {
+		f := frame.NewFrame(v, streamId, msg)
+		f.Header.IsResponse = true
+		return f
+	}
+	capture := &Capture{}
+	add := func(f *frame.Frame, at time.Duration) { capture.Add(f, at, primitive.CompressionNone) }
+	add(frame.NewFrame(v, 1, message.NewStartup()), 0)
+	add(response(1, &message.Ready{}), time.Millisecond)
+	add(frame.NewFrame(v, 2, &message.Options{}), 2*time.Millisecond)
+	add(frame.NewFrame(v, 3, &message.Query{Query: "SELECT * FROM system.local"}), 3*time.Millisecond)
+	add(response(2, &message.Supported{Options: map[string][]string{}}), 4*time.Millisecond)
+	add(response(3, &message.RowsResult{Metadata: &message.RowsMetadata{}, Data: message.RowSet{}}), 5*time.Millisecond)
+	add(frame.NewFrame(v, 2, &message.Register{EventTypes: []primitive.EventType{primitive.EventTypeTopologyChange}}), 6*time.Millisecond)
+	add(response(2, &message.Ready{}), 7*time.Millisecond)
+	add(frame.NewFrame(v, 4, &message.Query{Query: "INSERT INTO ks.t (k) VALUES (1)"}), 8*time.Millisecond)
+	add(response(4, &message.VoidResult{}), 9*time.Millisecond)
+	add(frame.NewFrame(v, 5, &message.Options{}), 10*time.Millisecond)
+	add(frame.NewFrame(v, 6, &message.Query{Query: "SELECT * FROM ks.unhandled"}), 11*time.Millisecond)
+	add(response(6, &message.VoidResult{}), 12*time.Millisecond)
+	return capture
+}
+
+func TestCapture_RoundTrip(t *testing.T) {
+	capture := newTestCapture()
+	buf := &bytes.Buffer{}
+	_, err := capture.WriteTo(buf)
+	require.NoError(t, err)
+	text := buf.String()
+	assert.True(t, strings.HasPrefix(text, "at=0s version=4 compression=NONE hex="), text)
+
+	read, err := ReadCapture(strings.NewReader("# comment\n\n" + text))
+	require.NoError(t, err)
+	require.Len(t, read.Frames, len(capture.Frames))
+	for i, captured := range read.Frames {
+		assert.Equal(t, capture.Frames[i].At, captured.At)
+		assert.Equal(t, capture.Frames[i].Compression, captured.Compression)
+		assert.Equal(t, capture.Frames[i].Frame.Header.StreamId, captured.Frame.Header.StreamId)
+	}
+	rewritten := &bytes.Buffer{}
+	_, err = read.WriteTo(rewritten)
+	require.NoError(t, err)
+	assert.Equal(t, text, rewritten.String())
+
+	_, err = ReadCapture(strings.NewReader(text + "at=1s version=4\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot read capture: line 14")
+	_, err = ReadCapture(strings.NewReader("at=later version=4 compression=NONE hex=040000010500000000\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid frame time")
+
+	untimed, err := ReadCapture(strings.NewReader("version=4 compression=NONE hex=040000010500000000\n"))
+	require.NoError(t, err)
+	require.Len(t, untimed.Frames, 1)
+	assert.Equal(t, time.Duration(0), untimed.Frames[0].At)
+	assert.Equal(t, primitive.OpCodeOptions, untimed.Frames[0].Frame.Header.OpCode)
+}
+
+func TestReplayer_Run(t *testing.T) {
+	server := client.NewCqlServer(replayTestAddress, nil)
+	server.RequestHandlers = []client.RequestHandler{
+		client.HandshakeHandler,
+		client.HeartbeatHandler,
+		client.RegisterHandler,
+		client.NewSystemTablesHandler("cluster", "dc1"),
+		overloadedHandler,
+	}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	replayer := NewReplayer(replayTestAddress, nil)
+	replayer.Timeout = 500 * time.Millisecond
+	report, err := replayer.Run(ctx, newTestCapture())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Results, 6)
+	expected := []struct {
+		index   int
+		outcome Outcome
+	}{
+		{2, OutcomeMatched},
+		{3, OutcomeMatched},
+		{6, OutcomeMatched},
+		{8, OutcomeMismatched},
+		{10, OutcomeUnverified},
+		{11, OutcomeFailed},
+	}
+	for i, result := range report.Results {
+		assert.Equal(t, expected[i].index, result.Index, result.String())
+		assert.Equal(t, expected[i].outcome, result.Outcome, result.String())
+	}
+	assert.Contains(t, report.Results[3].Details, "OVERLOADED")
+	assert.Equal(t, 3, report.Count(OutcomeMatched))
+	assert.False(t, report.Passed())
+
+	buf := &bytes.Buffer{}
+	_, err = report.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Replay report for "+replayTestAddress)
+	assert.Contains(t, buf.String(), "3 matched, 1 mismatched, 1 failed, 1 unverified, 1 skipped")
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestReplayer_Pacing(t *testing.T) {
+	server := client.NewCqlServer(replayTestAddress, nil)
+	server.RequestHandlers = []client.RequestHandler{client.HandshakeHandler, client.HeartbeatHandler}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	capture := &Capture{}
+	for i := 0; i < 3; i++ {
+		capture.Add(frame.NewFrame(primitive.ProtocolVersion4, int16(i), &message.Options{}), 0, primitive.CompressionNone)
+	}
+	replayer := NewReplayer(replayTestAddress, nil)
+	replayer.Interval = 100 * time.Millisecond
+	start := time.Now()
+	report, err := replayer.Run(ctx, capture)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+	assert.Equal(t, 3, report.Count(OutcomeUnverified))
+	assert.True(t, report.Passed())
+
+	_, err = replayer.Run(ctx, &Capture{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "capture contains no requests to replay")
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
+
+func TestNewCaptureFromRecorder(t *testing.T) {
+	server := client.NewCqlServer(replayTestAddress, nil)
+	server.RequestHandlers = []client.RequestHandler{
+		client.HandshakeHandler,
+		client.HeartbeatHandler,
+		client.NewSystemTablesHandler("cluster", "dc1"),
+	}
+	server.Recorder = client.NewFrameRecorder()
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	require.NoError(t, server.Start(ctx))
+
+	clt := client.NewCqlClient(replayTestAddress, nil)
+	clt.Recorder = client.NewFrameRecorder()
+	clt.Recorder.KeepFrames = true
+	conn, err := clt.ConnectAndInit(ctx, primitive.ProtocolVersion4, client.ManagedStreamId)
+	require.NoError(t, err)
+	_, err = conn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, &message.Options{}))
+	require.NoError(t, err)
+	query := &message.Query{Query: "SELECT * FROM system.local", Options: &message.QueryOptions{}}
+	_, err = conn.SendAndReceive(frame.NewFrame(primitive.ProtocolVersion4, client.ManagedStreamId, query))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	capture, err := NewCaptureFromRecorder(clt.Recorder, "")
+	require.NoError(t, err)
+	require.Len(t, capture.Frames, 6)
+	assert.Equal(t, time.Duration(0), capture.Frames[0].At)
+	assert.LessOrEqual(t, int64(capture.Frames[4].At), int64(capture.Frames[5].At))
+	assert.Equal(t, query.Query, capture.Frames[4].Frame.Body.Message.(*message.Query).Query)
+
+	// the capture survives a round trip through its text form, and replays successfully
+	buf := &bytes.Buffer{}
+	_, err = capture.WriteTo(buf)
+	require.NoError(t, err)
+	read, err := ReadCapture(buf)
+	require.NoError(t, err)
+	report, err := NewReplayer(replayTestAddress, nil).Run(ctx, read)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 2, report.Count(OutcomeMatched))
+	assert.True(t, report.Passed())
+
+	// frames recorded without their contents cannot be captured
+	_, err = NewCaptureFromRecorder(server.Recorder, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "frame 0 was recorded without its contents")
+
+	cancelFn()
+	assert.Eventually(t, server.IsClosed, time.Second*10, time.Millisecond*10)
+}
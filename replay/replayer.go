@@ -0,0 +1,220 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/client"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const DefaultReplayTimeout = 10 * time.Second
+
+// ResponseComparator compares a captured response with an actual one, and returns a description of their
+// differences, or an empty string if they match.
+type ResponseComparator func(expected, actual *frame.Frame) string
+
+// Replayer replays the client side of a Capture against a server, and reports the responses that do not match the
+// captured ones. Captured STARTUP and AUTH_RESPONSE requests are not replayed: the replayer performs its own
+// handshake, using the protocol version and compression of the first captured request. Other requests are replayed in
+// capture order, with managed stream ids. It is preferable to create Replayer instances using the constructor function
+// NewReplayer.
+type Replayer struct {
+	// Address is the address of the server to replay the capture against.
+	Address string
+	// Credentials are the credentials to use if the server requires authentication; leave nil if the server does not
+	// require authentication.
+	Credentials *client.AuthCredentials
+	// Speed is the pacing factor applied to the capture timeline, see CapturedFrame.At: 1 replays requests at the
+	// captured pace, 2 twice as fast, and so on. Zero, the default, replays requests as fast as possible.
+	Speed float64
+	// Interval is the minimum delay between two successive requests. Zero, the default, applies no delay.
+	Interval time.Duration
+	// Concurrent, if true, sends requests without waiting for the response to the previous request, as dictated by
+	// the pacing only. This reproduces concurrent traffic more faithfully, but requests that depend on the response
+	// to a previous request, e.g. EXECUTE after PREPARE, may then fail.
+	Concurrent bool
+	// Timeout is the timeout to apply when connecting and when waiting for a response.
+	Timeout time.Duration
+	// Compare is the function used to compare responses. Defaults to CompareResponses if nil.
+	Compare ResponseComparator
+}
+
+// NewReplayer creates a new Replayer for the server at the given address, with default options. Leave credentials nil
+// if the server does not require authentication.
+func NewReplayer(address string, credentials *client.AuthCredentials) *Replayer {
+	return &Replayer{
+		Address:     address,
+		Credentials: credentials,
+		Timeout:     DefaultReplayTimeout,
+		Compare:     CompareResponses,
+	}
+}
+
+// exchange is a captured request, along with its captured response, if any.
+type exchange struct {
+	index    int
+	request  *CapturedFrame
+	expected *frame.Frame
+	result   *ExchangeResult
+	inFlight client.InFlightRequest
+}
+
+// Run replays the given capture and returns the replay report. An error is returned if the capture contains no
+// requests, if the connection to the server cannot be established, or if the context is canceled; in the latter case,
+// the report contains the results of the requests replayed so far.
+func (r *Replayer) Run(ctx context.Context, capture *Capture) (*Report, error) {
+	report := &Report{Target: r.Address}
+	exchanges := collectExchanges(capture, report)
+	if len(exchanges) == 0 {
+		return report, fmt.Errorf("capture contains no requests to replay")
+	}
+	first := exchanges[0].request
+	clt := client.NewCqlClient(r.Address, r.Credentials)
+	clt.Compression = first.Compression
+	if r.Timeout > 0 {
+		clt.ConnectTimeout = r.Timeout
+		clt.ReadTimeout = r.Timeout
+	}
+	conn, err := clt.ConnectAndInit(ctx, first.Frame.Header.Version, client.ManagedStreamId)
+	if err != nil {
+		return report, fmt.Errorf("cannot connect to %v: %w", r.Address, err)
+	}
+	defer func() { _ = conn.Close() }()
+	start := time.Now()
+	var lastSent time.Time
+	var pending []*exchange
+	for _, ex := range exchanges {
+		if err := r.pace(ctx, start, lastSent, ex.request.At); err != nil {
+			r.receiveAll(conn, pending, report)
+			return report, err
+		}
+		ex.result = &ExchangeResult{Index: ex.index, Request: ex.request.Frame, Expected: ex.expected}
+		request := ex.request.Frame.DeepCopy()
+		request.Header.StreamId = client.ManagedStreamId
+		lastSent = time.Now()
+		if ex.inFlight, err = conn.SendWithTimeout(request, clt.ReadTimeout); err != nil {
+			ex.result.Outcome, ex.result.Details = OutcomeFailed, err.Error()
+		}
+		pending = append(pending, ex)
+		if !r.Concurrent {
+			r.receiveAll(conn, pending, report)
+			pending = nil
+		}
+	}
+	r.receiveAll(conn, pending, report)
+	return report, nil
+}
+
+// collectExchanges collects the requests to replay, along with their captured responses. A response is matched with
+// the closest preceding request with the same stream id.
+func collectExchanges(capture *Capture, report *Report) []*exchange {
+	var exchanges []*exchange
+	pending := map[int16]*exchange{}
+	for i, captured := range capture.Frames {
+		header := captured.Frame.Header
+		if header.IsResponse {
+			if ex, found := pending[header.StreamId]; found {
+				ex.expected = captured.Frame
+				delete(pending, header.StreamId)
+			}
+		} else if header.OpCode == primitive.OpCodeStartup || header.OpCode == primitive.OpCodeAuthResponse {
+			report.Skipped++
+			delete(pending, header.StreamId)
+		} else {
+			ex := &exchange{index: i, request: captured}
+			exchanges = append(exchanges, ex)
+			pending[header.StreamId] = ex
+		}
+	}
+	return exchanges
+}
+
+// pace waits until the given request can be sent, according to the configured pacing.
+func (r *Replayer) pace(ctx context.Context, start time.Time, lastSent time.Time, at time.Duration) error {
+	var deadline time.Time
+	if r.Speed > 0 {
+		deadline = start.Add(time.Duration(float64(at) / r.Speed))
+	}
+	if r.Interval > 0 && !lastSent.IsZero() && lastSent.Add(r.Interval).After(deadline) {
+		deadline = lastSent.Add(r.Interval)
+	}
+	if delay := time.Until(deadline); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+func (r *Replayer) receiveAll(conn *client.CqlClientConnection, pending []*exchange, report *Report) {
+	compare := r.Compare
+	if compare == nil {
+		compare = CompareResponses
+	}
+	for _, ex := range pending {
+		result := ex.result
+		if ex.inFlight != nil {
+			var err error
+			if result.Actual, err = conn.Receive(ex.inFlight); err != nil {
+				result.Outcome, result.Details = OutcomeFailed, err.Error()
+			} else if result.Actual == nil {
+				result.Outcome, result.Details = OutcomeFailed, "no response received"
+			} else if result.Expected == nil {
+				result.Outcome, result.Details = OutcomeUnverified, fmt.Sprintf("got %v", result.Actual.Body.Message)
+			} else if diff := compare(result.Expected, result.Actual); diff != "" {
+				result.Outcome, result.Details = OutcomeMismatched, diff
+			} else {
+				result.Outcome = OutcomeMatched
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+}
+
+// CompareResponses is the default ResponseComparator. It only compares the kinds of responses: their opcodes, along
+// with the result types of RESULT responses, and the error codes of ERROR responses. The contents of responses, e.g.
+// rows, are not compared, since they typically depend on the server state.
+func CompareResponses(expected, actual *frame.Frame) string {
+	if expected.Header.OpCode != actual.Header.OpCode {
+		return fmt.Sprintf("expected %v, got %v", expected.Body.Message, actual.Body.Message)
+	}
+	switch e := expected.Body.Message.(type) {
+	case message.Result:
+		if a, ok := actual.Body.Message.(message.Result); ok && e.GetResultType() != a.GetResultType() {
+			return fmt.Sprintf("expected %v, got %v", e, a)
+		}
+	case message.Error:
+		if a, ok := actual.Body.Message.(message.Error); ok && e.GetErrorCode() != a.GetErrorCode() {
+			return fmt.Sprintf("expected %v, got %v", e, a)
+		}
+	}
+	return ""
+}
+
+// CompareResponsesStrict is a ResponseComparator that compares the bodies of responses field by field, see
+// frame.Diff.
+func CompareResponsesStrict(expected, actual *frame.Frame) string {
+	return frame.Diff(&frame.Frame{Body: expected.Body}, &frame.Frame{Body: actual.Body})
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+)
+
+// Outcome is the outcome of a single replayed request.
+type Outcome string
+
+const (
+	// OutcomeMatched is reported when the actual response matches the captured one.
+	OutcomeMatched = Outcome("MATCHED")
+	// OutcomeMismatched is reported when the actual response does not match the captured one.
+	OutcomeMismatched = Outcome("MISMATCHED")
+	// OutcomeFailed is reported when the request could not be sent, or when no response was received in time.
+	OutcomeFailed = Outcome("FAILED")
+	// OutcomeUnverified is reported when a response was received, but the capture contains no response to compare
+	// it with.
+	OutcomeUnverified = Outcome("UNVERIFIED")
+)
+
+// ExchangeResult is the result of a single replayed request.
+type ExchangeResult struct {
+	// Index is the index of the request in the capture.
+	Index int
+	// Request is the replayed request.
+	Request *frame.Frame
+	// Expected is the captured response; it is nil if the capture contains no response to the request.
+	Expected *frame.Frame
+	// Actual is the actual response; it is nil if no response was received.
+	Actual *frame.Frame
+	// Outcome is the outcome of the exchange.
+	Outcome Outcome
+	// Details contains additional information about the outcome, e.g. the differences between the captured and the
+	// actual responses.
+	Details string
+}
+
+func (r *ExchangeResult) String() string {
+	return fmt.Sprintf("#%d %v: %v: %v", r.Index, r.Request.Header.OpCode, r.Outcome, r.Details)
+}
+
+// Report is a replay report, as produced by a Replayer.
+type Report struct {
+	// Target is the address of the server the capture was replayed against.
+	Target string
+	// Results contains the result of each replayed request, in capture order.
+	Results []*ExchangeResult
+	// Skipped is the number of captured requests that were not replayed, e.g. handshake requests, since the replayer
+	// performs its own handshake.
+	Skipped int
+}
+
+// Count returns the number of replayed requests with the given outcome.
+func (r *Report) Count(outcome Outcome) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// Passed returns true if no replayed request failed or got a mismatching response.
+func (r *Report) Passed() bool {
+	return r.Count(OutcomeMismatched) == 0 && r.Count(OutcomeFailed) == 0
+}
+
+// WriteTo writes a human-readable version of this report to the given writer.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	sb := &strings.Builder{}
+	_, _ = fmt.Fprintf(sb, "Replay report for %v\n\n", r.Target)
+	tw := tabwriter.NewWriter(sb, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "INDEX\tREQUEST\tOUTCOME\tDETAILS")
+	for _, result := range r.Results {
+		details := strings.ReplaceAll(result.Details, "\n", "; ")
+		_, _ = fmt.Fprintf(tw, "%d\t%v\t%v\t%v\n", result.Index, result.Request.Header.OpCode, result.Outcome, details)
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintf(sb, "\n%d matched, %d mismatched, %d failed, %d unverified, %d skipped\n",
+		r.Count(OutcomeMatched), r.Count(OutcomeMismatched), r.Count(OutcomeFailed), r.Count(OutcomeUnverified), r.Skipped)
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrChecksumMismatch is wrapped by the errors returned when decoding a segment whose payload fails checksum
+// verification, with ChecksumPolicyFail.
+var ErrChecksumMismatch = errors.New("crc mismatch")
+
+// ChecksumPolicy determines how a segment decoder reacts when a segment payload fails checksum verification. It does
+// not apply to segment headers: when a header fails checksum verification, its payload length cannot be trusted, and
+// decoding the stream cannot be resumed; such failures are always reported as errors.
+type ChecksumPolicy int
+
+const (
+	// ChecksumPolicyFail is the default policy: DecodeSegment returns an error wrapping ErrChecksumMismatch. Since the
+	// stream may be out of sync afterwards, the connection should be closed.
+	ChecksumPolicyFail = ChecksumPolicy(iota)
+	// ChecksumPolicySkip discards the corrupt segment, and decodes the next segment in the source instead. This may be
+	// appropriate for capture tools; note that if the discarded segment was a multi-segment part, the frame it
+	// belongs to cannot be reassembled anymore.
+	ChecksumPolicySkip
+	// ChecksumPolicyFlag returns the corrupt segment as if it were valid, but with its Payload.Unverified field set to
+	// true. This may be appropriate for proxies that must forward the corrupt data as is.
+	ChecksumPolicyFlag
+)
+
+func (p ChecksumPolicy) String() string {
+	switch p {
+	case ChecksumPolicyFail:
+		return "ChecksumPolicy FAIL"
+	case ChecksumPolicySkip:
+		return "ChecksumPolicy SKIP"
+	case ChecksumPolicyFlag:
+		return "ChecksumPolicy FLAG"
+	}
+	return fmt.Sprintf("ChecksumPolicy ? [%d]", int(p))
+}
+
+// ChecksumFailure describes a segment payload that failed checksum verification.
+type ChecksumFailure struct {
+	// Header is the header of the corrupt segment.
+	Header *Header
+	// ReceivedCrc32 is the CRC-32 checksum present in the payload trailer.
+	ReceivedCrc32 uint32
+	// ComputedCrc32 is the CRC-32 checksum computed from the received payload.
+	ComputedCrc32 uint32
+}
+
+func (f *ChecksumFailure) String() string {
+	return fmt.Sprintf("crc mismatch on payload: received %x, computed %x", f.ReceivedCrc32, f.ComputedCrc32)
+}
+
+// ChecksumFailureHandler is a callback function that gets invoked whenever a segment payload fails checksum
+// verification, before the ChecksumPolicy is applied.
+type ChecksumFailureHandler func(failure *ChecksumFailure)
@@ -45,10 +45,17 @@ type Decoder interface {
 type Codec interface {
 	Encoder
 	Decoder
+
+	// SetChecksumPolicy sets the ChecksumPolicy to apply when a decoded segment payload fails checksum verification,
+	// and an optional handler to notify of such failures. The default policy is ChecksumPolicyFail. This method should
+	// be called before the codec is used.
+	SetChecksumPolicy(policy ChecksumPolicy, onFailure ChecksumFailureHandler)
 }
 
 type codec struct {
-	compressor PayloadCompressor
+	compressor        PayloadCompressor
+	checksumPolicy    ChecksumPolicy
+	onChecksumFailure ChecksumFailureHandler
 }
 
 func NewCodec() Codec {
@@ -58,3 +65,8 @@ func NewCodec() Codec {
 func NewCodecWithCompression(compressor PayloadCompressor) Codec {
 	return &codec{compressor: compressor}
 }
+
+func (c *codec) SetChecksumPolicy(policy ChecksumPolicy, onFailure ChecksumFailureHandler) {
+	c.checksumPolicy = policy
+	c.onChecksumFailure = onFailure
+}
@@ -25,15 +25,18 @@ import (
 )
 
 func (c *codec) DecodeSegment(source io.Reader) (*Segment, error) {
-	if header, err := c.decodeSegmentHeader(source); err != nil {
-		return nil, fmt.Errorf("cannot decode segment header: %w", err)
-	} else if payload, err := c.decodeSegmentPayload(header, source); err != nil {
-		return nil, fmt.Errorf("cannot decode segment payload: %w", err)
-	} else {
-		return &Segment{
-			Header:  header,
-			Payload: payload,
-		}, nil
+	for {
+		if header, err := c.decodeSegmentHeader(source); err != nil {
+			return nil, fmt.Errorf("cannot decode segment header: %w", err)
+		} else if payload, err := c.decodeSegmentPayload(header, source); err != nil {
+			return nil, fmt.Errorf("cannot decode segment payload: %w", err)
+		} else if payload != nil {
+			return &Segment{
+				Header:  header,
+				Payload: payload,
+			}, nil
+		}
+		// corrupt segment skipped, see ChecksumPolicySkip
 	}
 }
 
@@ -102,12 +105,23 @@ func (c *codec) decodeSegmentPayload(header *Header, source io.Reader) (*Payload
 		return nil, fmt.Errorf("cannot read segment payload CRC: %w", err)
 	}
 	actualPayloadCrc := crc.ChecksumIEEE(encodedPayload)
+	payload := &Payload{Crc32: actualPayloadCrc}
 	if actualPayloadCrc != expectedPayloadCrc {
-		return nil, fmt.Errorf(
-			"crc mismatch on payload: received %x, computed %x",
-			expectedPayloadCrc, actualPayloadCrc)
+		failure := &ChecksumFailure{Header: header, ReceivedCrc32: expectedPayloadCrc, ComputedCrc32: actualPayloadCrc}
+		if c.onChecksumFailure != nil {
+			c.onChecksumFailure(failure)
+		}
+		switch c.checksumPolicy {
+		case ChecksumPolicySkip:
+			return nil, nil
+		case ChecksumPolicyFlag:
+			payload.Unverified = true
+		default:
+			return nil, fmt.Errorf(
+				"%w on payload: received %x, computed %x",
+				ErrChecksumMismatch, expectedPayloadCrc, actualPayloadCrc)
+		}
 	}
-	payload := &Payload{Crc32: actualPayloadCrc}
 	// Decompress payload if needed
 	if c.compressor == nil || header.CompressedPayloadLength == 0 {
 		payload.UncompressedData = encodedPayload
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
 	"github.com/datastax/go-cassandra-native-protocol/crc"
@@ -409,3 +410,46 @@ func Test_codec_decodeSegmentHeader(t *testing.T) {
 		})
 	}
 }
+
+func Test_codec_DecodeSegment_ChecksumPolicy(t *testing.T) {
+	encode := func(codec Codec, payload []byte, corrupt bool) []byte {
+		encoded := &bytes.Buffer{}
+		segment := &Segment{Header: &Header{IsSelfContained: true}, Payload: &Payload{UncompressedData: payload}}
+		require.NoError(t, codec.EncodeSegment(segment, encoded))
+		if corrupt {
+			encoded.Bytes()[encoded.Len()-1] ^= 0xff
+		}
+		return encoded.Bytes()
+	}
+	tests := []struct {
+		policy   ChecksumPolicy
+		expected *Payload
+		err      error
+	}{
+		{ChecksumPolicyFail, nil, ErrChecksumMismatch},
+		{ChecksumPolicySkip, &Payload{UncompressedData: []byte{2}, Crc32: crc.ChecksumIEEE([]byte{2})}, nil},
+		{ChecksumPolicyFlag, &Payload{UncompressedData: []byte{1}, Crc32: crc.ChecksumIEEE([]byte{1}), Unverified: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			var failures []*ChecksumFailure
+			codec := NewCodec()
+			codec.SetChecksumPolicy(tt.policy, func(failure *ChecksumFailure) {
+				failures = append(failures, failure)
+			})
+			source := bytes.NewReader(append(encode(codec, []byte{1}, true), encode(codec, []byte{2}, false)...))
+			actual, err := codec.DecodeSegment(source)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Nil(t, actual)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, actual.Payload)
+			}
+			require.Len(t, failures, 1)
+			assert.True(t, failures[0].Header.IsSelfContained)
+			assert.Equal(t, crc.ChecksumIEEE([]byte{1}), failures[0].ComputedCrc32)
+			assert.Equal(t, crc.ChecksumIEEE([]byte{1})^0xff000000, failures[0].ReceivedCrc32)
+		})
+	}
+}
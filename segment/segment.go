@@ -64,6 +64,10 @@ type Payload struct {
 	// payload. When decoding a segment, the CRC-32 present in the payload trailer is verified against the actual
 	// decoded payload, then set to that value if it matches.
 	Crc32 uint32
+	// Unverified is true if the payload failed checksum verification when decoded, and was returned anyway as
+	// mandated by ChecksumPolicyFlag; Crc32 is then the checksum computed from the received payload. This field is not
+	// read when encoding a segment.
+	Unverified bool
 }
 
 func (s *Segment) String() string {
@@ -0,0 +1,177 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Writer writes encoded frames (envelopes) to a destination in the modern framing layout introduced in protocol v5.
+// Small envelopes are coalesced into shared self-contained segments, as permitted by the protocol specification: this
+// amortizes the cost of segment headers, checksums and compression for chatty workloads. Envelopes that are too large
+// to fit in a single segment are split into multi-segment parts. Envelopes are buffered until Flush is called, or
+// until the buffered envelopes cannot accommodate the next one in the same segment. Writer instances should be created
+// with NewWriter; they are not safe for concurrent use.
+type Writer struct {
+	dest             io.Writer
+	encoder          Encoder
+	maxPayloadLength int
+	pending          *bytes.Buffer
+}
+
+// NewWriter creates a new Writer that encodes segments with the given encoder, and writes them to dest. The segment
+// payloads are at most MaxPayloadLength bytes long.
+func NewWriter(dest io.Writer, encoder Encoder) *Writer {
+	return newWriter(dest, encoder, MaxPayloadLength)
+}
+
+// NewWriterWithMaxPayloadLength is similar to NewWriter, but limits segment payloads to the given length instead of
+// MaxPayloadLength. An error is returned if the length is not strictly positive, or is greater than MaxPayloadLength.
+func NewWriterWithMaxPayloadLength(dest io.Writer, encoder Encoder, maxPayloadLength int) (*Writer, error) {
+	if maxPayloadLength < 1 || maxPayloadLength > MaxPayloadLength {
+		return nil, fmt.Errorf(
+			"invalid max payload length: %d, must be between 1 and %d",
+			maxPayloadLength,
+			MaxPayloadLength,
+		)
+	}
+	return newWriter(dest, encoder, maxPayloadLength), nil
+}
+
+func newWriter(dest io.Writer, encoder Encoder, maxPayloadLength int) *Writer {
+	return &Writer{
+		dest:             dest,
+		encoder:          encoder,
+		maxPayloadLength: maxPayloadLength,
+		pending:          &bytes.Buffer{},
+	}
+}
+
+// WriteEnvelope buffers the given encoded frame. If the frame does not fit in the segment being buffered, the buffered
+// frames are flushed first. If the frame is larger than the maximum payload length, the buffered frames are flushed,
+// then the frame is immediately written as a sequence of multi-segment parts.
+func (w *Writer) WriteEnvelope(envelope []byte) error {
+	if w.pending.Len()+len(envelope) > w.maxPayloadLength {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(envelope) <= w.maxPayloadLength {
+		w.pending.Write(envelope)
+		return nil
+	}
+	for start := 0; start < len(envelope); start += w.maxPayloadLength {
+		end := start + w.maxPayloadLength
+		if end > len(envelope) {
+			end = len(envelope)
+		}
+		if err := w.writeSegment(false, envelope[start:end]); err != nil {
+			return fmt.Errorf("cannot write multi-segment part: %w", err)
+		}
+	}
+	return nil
+}
+
+// LargeEnvelopeWriter returns an io.WriteCloser to write an encoded frame of the given length incrementally, without
+// holding it entirely in memory: this is typically used to encode frames containing very large values directly into
+// segments. The buffered frames are flushed first. If the frame is larger than the maximum payload length, each
+// multi-segment part is written as soon as enough bytes were received; otherwise, the frame is buffered on Close, just
+// like with WriteEnvelope. Close must be called once the entire frame was written; it fails if the number of bytes
+// written does not match the given length. No other method of this Writer should be called until Close returns.
+func (w *Writer) LargeEnvelopeWriter(length int) (io.WriteCloser, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("invalid envelope length: %v", length)
+	} else if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return &largeEnvelopeWriter{writer: w, length: length}, nil
+}
+
+type largeEnvelopeWriter struct {
+	writer  *Writer
+	length  int
+	written int
+	part    []byte
+}
+
+func (e *largeEnvelopeWriter) Write(p []byte) (int, error) {
+	if e.written+len(p) > e.length {
+		return 0, fmt.Errorf("cannot write %d bytes: envelope length would exceed %d bytes", len(p), e.length)
+	}
+	maxPayloadLength := e.writer.maxPayloadLength
+	if e.part == nil {
+		partLength := maxPayloadLength
+		if e.length < partLength {
+			partLength = e.length
+		}
+		e.part = make([]byte, 0, partLength)
+	}
+	n := 0
+	for n < len(p) {
+		chunk := p[n:]
+		if room := cap(e.part) - len(e.part); len(chunk) > room {
+			chunk = chunk[:room]
+		}
+		e.part = append(e.part, chunk...)
+		n += len(chunk)
+		e.written += len(chunk)
+		if e.length > maxPayloadLength && len(e.part) == cap(e.part) {
+			if err := e.writer.writeSegment(false, e.part); err != nil {
+				return n, fmt.Errorf("cannot write multi-segment part: %w", err)
+			}
+			e.part = e.part[:0]
+		}
+	}
+	return n, nil
+}
+
+func (e *largeEnvelopeWriter) Close() error {
+	if e.written != e.length {
+		return fmt.Errorf("incomplete envelope: expected %d bytes, got %d", e.length, e.written)
+	} else if e.length <= e.writer.maxPayloadLength {
+		return e.writer.WriteEnvelope(e.part)
+	} else if len(e.part) > 0 {
+		if err := e.writer.writeSegment(false, e.part); err != nil {
+			return fmt.Errorf("cannot write multi-segment part: %w", err)
+		}
+	}
+	return nil
+}
+
+// Buffered returns the total length of the frames buffered so far.
+func (w *Writer) Buffered() int {
+	return w.pending.Len()
+}
+
+// Flush writes the buffered frames, if any, as a single self-contained segment.
+func (w *Writer) Flush() error {
+	if w.pending.Len() == 0 {
+		return nil
+	}
+	defer w.pending.Reset()
+	if err := w.writeSegment(true, w.pending.Bytes()); err != nil {
+		return fmt.Errorf("cannot write self-contained segment: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) writeSegment(selfContained bool, payload []byte) error {
+	return w.encoder.EncodeSegment(&Segment{
+		Header:  &Header{IsSelfContained: selfContained},
+		Payload: &Payload{UncompressedData: payload},
+	}, w.dest)
+}
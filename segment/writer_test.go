@@ -0,0 +1,154 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package segment
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/compression/lz4"
+)
+
+func decodeAllSegments(t *testing.T, codec Codec, encoded *bytes.Buffer) []*Segment {
+	var segments []*Segment
+	for encoded.Len() > 0 {
+		segment, err := codec.DecodeSegment(encoded)
+		require.NoError(t, err)
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+func TestWriter(t *testing.T) {
+	for name, codec := range map[string]Codec{
+		"uncompressed": NewCodec(),
+		"compressed":   NewCodecWithCompression(lz4.Compressor{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("coalescing", func(t *testing.T) {
+				encoded := &bytes.Buffer{}
+				writer := NewWriter(encoded, codec)
+				require.NoError(t, writer.WriteEnvelope([]byte{1, 2, 3}))
+				require.NoError(t, writer.WriteEnvelope([]byte{4, 5}))
+				require.NoError(t, writer.WriteEnvelope([]byte{6}))
+				assert.Equal(t, 6, writer.Buffered())
+				assert.Zero(t, encoded.Len())
+				require.NoError(t, writer.Flush())
+				assert.Zero(t, writer.Buffered())
+				segments := decodeAllSegments(t, codec, encoded)
+				require.Len(t, segments, 1)
+				assert.True(t, segments[0].Header.IsSelfContained)
+				assert.Equal(t, []byte{1, 2, 3, 4, 5, 6}, segments[0].Payload.UncompressedData)
+				// flushing with no buffered envelopes is a no-op
+				require.NoError(t, writer.Flush())
+				assert.Zero(t, encoded.Len())
+			})
+			t.Run("segment full", func(t *testing.T) {
+				encoded := &bytes.Buffer{}
+				writer, err := NewWriterWithMaxPayloadLength(encoded, codec, 5)
+				require.NoError(t, err)
+				require.NoError(t, writer.WriteEnvelope([]byte{1, 2, 3}))
+				require.NoError(t, writer.WriteEnvelope([]byte{4, 5}))
+				require.NoError(t, writer.WriteEnvelope([]byte{6}))
+				require.NoError(t, writer.Flush())
+				segments := decodeAllSegments(t, codec, encoded)
+				require.Len(t, segments, 2)
+				assert.Equal(t, []byte{1, 2, 3, 4, 5}, segments[0].Payload.UncompressedData)
+				assert.Equal(t, []byte{6}, segments[1].Payload.UncompressedData)
+			})
+			t.Run("multi-segment envelope", func(t *testing.T) {
+				encoded := &bytes.Buffer{}
+				writer, err := NewWriterWithMaxPayloadLength(encoded, codec, 4)
+				require.NoError(t, err)
+				require.NoError(t, writer.WriteEnvelope([]byte{1}))
+				require.NoError(t, writer.WriteEnvelope([]byte{2, 3, 4, 5, 6, 7, 8, 9, 10}))
+				require.NoError(t, writer.WriteEnvelope([]byte{11}))
+				require.NoError(t, writer.Flush())
+				segments := decodeAllSegments(t, codec, encoded)
+				require.Len(t, segments, 5)
+				assert.True(t, segments[0].Header.IsSelfContained)
+				assert.Equal(t, []byte{1}, segments[0].Payload.UncompressedData)
+				assert.False(t, segments[1].Header.IsSelfContained)
+				assert.Equal(t, []byte{2, 3, 4, 5}, segments[1].Payload.UncompressedData)
+				assert.False(t, segments[2].Header.IsSelfContained)
+				assert.Equal(t, []byte{6, 7, 8, 9}, segments[2].Payload.UncompressedData)
+				assert.False(t, segments[3].Header.IsSelfContained)
+				assert.Equal(t, []byte{10}, segments[3].Payload.UncompressedData)
+				assert.True(t, segments[4].Header.IsSelfContained)
+				assert.Equal(t, []byte{11}, segments[4].Payload.UncompressedData)
+			})
+			t.Run("large envelope writer", func(t *testing.T) {
+				encoded := &bytes.Buffer{}
+				writer, err := NewWriterWithMaxPayloadLength(encoded, codec, 4)
+				require.NoError(t, err)
+				require.NoError(t, writer.WriteEnvelope([]byte{1}))
+				envelope, err := writer.LargeEnvelopeWriter(9)
+				require.NoError(t, err)
+				// the buffered envelope is flushed first
+				assert.Zero(t, writer.Buffered())
+				for _, chunk := range [][]byte{{2, 3}, {4, 5, 6, 7, 8}, {9, 10}} {
+					n, err := envelope.Write(chunk)
+					require.NoError(t, err)
+					assert.Equal(t, len(chunk), n)
+				}
+				_, err = envelope.Write([]byte{11})
+				require.Error(t, err)
+				require.NoError(t, envelope.Close())
+				segments := decodeAllSegments(t, codec, encoded)
+				require.Len(t, segments, 4)
+				assert.True(t, segments[0].Header.IsSelfContained)
+				assert.Equal(t, []byte{1}, segments[0].Payload.UncompressedData)
+				assert.False(t, segments[1].Header.IsSelfContained)
+				assert.Equal(t, []byte{2, 3, 4, 5}, segments[1].Payload.UncompressedData)
+				assert.False(t, segments[2].Header.IsSelfContained)
+				assert.Equal(t, []byte{6, 7, 8, 9}, segments[2].Payload.UncompressedData)
+				assert.False(t, segments[3].Header.IsSelfContained)
+				assert.Equal(t, []byte{10}, segments[3].Payload.UncompressedData)
+			})
+			t.Run("large envelope writer with small envelope", func(t *testing.T) {
+				encoded := &bytes.Buffer{}
+				writer, err := NewWriterWithMaxPayloadLength(encoded, codec, 4)
+				require.NoError(t, err)
+				envelope, err := writer.LargeEnvelopeWriter(3)
+				require.NoError(t, err)
+				_, err = envelope.Write([]byte{1, 2})
+				require.NoError(t, err)
+				assert.Error(t, envelope.Close())
+				_, err = envelope.Write([]byte{3})
+				require.NoError(t, err)
+				require.NoError(t, envelope.Close())
+				// small envelopes are buffered, just like with WriteEnvelope
+				assert.Equal(t, 3, writer.Buffered())
+				require.NoError(t, writer.Flush())
+				segments := decodeAllSegments(t, codec, encoded)
+				require.Len(t, segments, 1)
+				assert.True(t, segments[0].Header.IsSelfContained)
+				assert.Equal(t, []byte{1, 2, 3}, segments[0].Payload.UncompressedData)
+			})
+		})
+	}
+}
+
+func TestNewWriterWithMaxPayloadLength_InvalidLength(t *testing.T) {
+	for _, maxPayloadLength := range []int{0, -1, MaxPayloadLength + 1} {
+		writer, err := NewWriterWithMaxPayloadLength(&bytes.Buffer{}, NewCodec(), maxPayloadLength)
+		require.Error(t, err)
+		assert.Nil(t, writer)
+		assert.Contains(t, err.Error(), "invalid max payload length")
+	}
+}
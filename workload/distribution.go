@@ -0,0 +1,110 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Distribution is a distribution of non-negative integers, e.g. value sizes, batch sizes or partition keys.
+type Distribution interface {
+	// Next draws the next integer from this distribution, using the given source of randomness.
+	Next(r *rand.Rand) int
+}
+
+type constantDistribution int
+
+// NewConstantDistribution returns a Distribution that always produces the given value.
+func NewConstantDistribution(value int) Distribution {
+	return constantDistribution(value)
+}
+
+func (d constantDistribution) Next(*rand.Rand) int {
+	return int(d)
+}
+
+type uniformDistribution struct {
+	min int
+	max int
+}
+
+// NewUniformDistribution returns a Distribution that produces values uniformly distributed between min and max,
+// inclusive. The bounds are swapped if min is greater than max.
+func NewUniformDistribution(min int, max int) Distribution {
+	if min > max {
+		min, max = max, min
+	}
+	return &uniformDistribution{min: min, max: max}
+}
+
+func (d *uniformDistribution) Next(r *rand.Rand) int {
+	return d.min + r.Intn(d.max-d.min+1)
+}
+
+type normalDistribution struct {
+	mean   float64
+	stdDev float64
+}
+
+// NewNormalDistribution returns a Distribution that produces normally distributed values with the given mean and
+// standard deviation. Values are rounded to the nearest integer; negative values are clamped to zero.
+func NewNormalDistribution(mean float64, stdDev float64) Distribution {
+	return &normalDistribution{mean: mean, stdDev: stdDev}
+}
+
+func (d *normalDistribution) Next(r *rand.Rand) int {
+	value := math.Round(r.NormFloat64()*d.stdDev + d.mean)
+	if value < 0 {
+		return 0
+	}
+	return int(value)
+}
+
+type zipfDistribution struct {
+	// cdf is the cumulative distribution function: cdf[i] is the probability of drawing a value lesser than or equal
+	// to i.
+	cdf []float64
+}
+
+// NewZipfDistribution returns a Distribution that produces values between 0 and n-1, following Zipf's law with the
+// given exponent s, which must be positive: the probability of drawing value k is proportional to 1/(k+1)^s. Low
+// values are thus drawn far more often than high values; this is useful to simulate hot partitions. The distribution
+// is precomputed, and its memory footprint is proportional to n.
+func NewZipfDistribution(n int, s float64) Distribution {
+	if n < 1 {
+		n = 1
+	}
+	cdf := make([]float64, n)
+	sum := 0.0
+	for k := 0; k < n; k++ {
+		sum += 1 / math.Pow(float64(k+1), s)
+		cdf[k] = sum
+	}
+	for k := range cdf {
+		cdf[k] /= sum
+	}
+	return &zipfDistribution{cdf: cdf}
+}
+
+func (d *zipfDistribution) Next(r *rand.Rand) int {
+	p := r.Float64()
+	k := sort.SearchFloat64s(d.cdf, p)
+	if k >= len(d.cdf) {
+		k = len(d.cdf) - 1
+	}
+	return k
+}
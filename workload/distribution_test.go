@@ -0,0 +1,69 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name         string
+		distribution Distribution
+		min          int
+		max          int
+	}{
+		{"constant", NewConstantDistribution(7), 7, 7},
+		{"uniform", NewUniformDistribution(3, 5), 3, 5},
+		{"uniform swapped bounds", NewUniformDistribution(5, 3), 3, 5},
+		{"normal", NewNormalDistribution(2, 10), 0, 1000},
+		{"zipf", NewZipfDistribution(10, 1.2), 0, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				value := tt.distribution.Next(r)
+				assert.GreaterOrEqual(t, value, tt.min)
+				assert.LessOrEqual(t, value, tt.max)
+			}
+		})
+	}
+}
+
+func TestZipfDistribution_Skew(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	distribution := NewZipfDistribution(100, 1.5)
+	counts := make([]int, 100)
+	for i := 0; i < 10000; i++ {
+		counts[distribution.Next(r)]++
+	}
+	assert.Greater(t, counts[0], counts[1])
+	assert.Greater(t, counts[1], counts[10])
+	assert.Greater(t, counts[0], 10000/3)
+}
+
+func TestNormalDistribution_Mean(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	distribution := NewNormalDistribution(100, 10)
+	sum := 0
+	for i := 0; i < 10000; i++ {
+		sum += distribution.Next(r)
+	}
+	assert.InDelta(t, 100, float64(sum)/10000, 1)
+}
@@ -0,0 +1,31 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workload contains tools to generate synthetic CQL workloads, for benchmarking proxies and servers built on
+// top of this library.
+//
+// A Generator produces a stream of QUERY, EXECUTE and BATCH request frames against a single statement, with
+// configurable operation and consistency level mixes, and with bound values drawn from configurable distributions, see
+// Distribution. Generators are seeded, so that the same workload can be reproduced across runs:
+//
+//  generator, err := workload.NewGenerator(
+//      primitive.ProtocolVersion4,
+//      "INSERT INTO ks.t (pk, v) VALUES (?, ?)",
+//      []datatype.DataType{datatype.Bigint, datatype.Varchar},
+//      42,
+//  )
+//  generator.Keys = workload.NewZipfDistribution(1_000_000, 1.1)
+//  generator.ValueSize = workload.NewNormalDistribution(256, 64)
+//  frames, err := generator.Generate(10_000)
+package workload
@@ -0,0 +1,370 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+const (
+	// DefaultPageSize is the default page size of generated QUERY and EXECUTE requests.
+	DefaultPageSize = 5000
+	// DefaultKeyCount is the default number of distinct partition keys; keys are uniformly distributed by default.
+	DefaultKeyCount = 1000
+	// DefaultValueSize is the default size, in bytes or characters, of generated variable-length values.
+	DefaultValueSize = 16
+)
+
+// supportedColumnTypes contains the codes of the CQL types for which a Generator can generate values.
+var supportedColumnTypes = map[primitive.DataTypeCode]bool{
+	primitive.DataTypeCodeAscii:     true,
+	primitive.DataTypeCodeBigint:    true,
+	primitive.DataTypeCodeBlob:      true,
+	primitive.DataTypeCodeBoolean:   true,
+	primitive.DataTypeCodeCounter:   true,
+	primitive.DataTypeCodeDate:      true,
+	primitive.DataTypeCodeDouble:    true,
+	primitive.DataTypeCodeFloat:     true,
+	primitive.DataTypeCodeInt:       true,
+	primitive.DataTypeCodeSmallint:  true,
+	primitive.DataTypeCodeTime:      true,
+	primitive.DataTypeCodeTimestamp: true,
+	primitive.DataTypeCodeTimeuuid:  true,
+	primitive.DataTypeCodeTinyint:   true,
+	primitive.DataTypeCodeUuid:      true,
+	primitive.DataTypeCodeVarchar:   true,
+	primitive.DataTypeCodeVarint:    true,
+}
+
+const valueLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// maxGeneratedTimestamp bounds generated timestamps to the first century after the epoch.
+const maxGeneratedTimestamp = int64(100 * 365 * 24 * time.Hour / time.Millisecond)
+
+// Generator generates a synthetic workload against a single CQL statement: a stream of QUERY, EXECUTE and BATCH
+// request frames, in proportions dictated by the operation mix. Each generated request binds one value per statement
+// column: the first column is considered the partition key, and its values are drawn from the Keys distribution; the
+// values of other columns are random, and variable-length values have sizes drawn from the ValueSize distribution.
+// Generated frames have stream id zero; they are typically sent with client.ManagedStreamId, or have their stream ids
+// assigned by the caller. A Generator is deterministic for a given seed and configuration; it is not safe for
+// concurrent use. It is preferable to create Generator instances using the constructor function NewGenerator.
+type Generator struct {
+	// Version is the protocol version of the generated frames.
+	Version primitive.ProtocolVersion
+	// Query is the CQL statement of QUERY requests and of unprepared BATCH children.
+	Query string
+	// PreparedId is the prepared id of the statement, as returned by the server when preparing Query. It is
+	// mandatory when the operation mix includes EXECUTE requests. When set, BATCH children are prepared statements
+	// as well.
+	PreparedId []byte
+	// ResultMetadataId is the result metadata id of the prepared statement, for EXECUTE requests with protocol
+	// version 5 and DSE protocol version 2.
+	ResultMetadataId []byte
+	// Operations is the operation mix: it maps each of primitive.OpCodeQuery, primitive.OpCodeExecute and
+	// primitive.OpCodeBatch to its relative weight. Defaults to QUERY requests only.
+	Operations map[primitive.OpCode]int
+	// Consistencies is the consistency level mix: it maps consistency levels to their relative weights. Defaults to
+	// LOCAL_ONE only.
+	Consistencies map[primitive.ConsistencyLevel]int
+	// BatchType is the type of generated BATCH requests. Defaults to UNLOGGED.
+	BatchType primitive.BatchType
+	// BatchSize is the distribution of the number of children of BATCH requests; sizes lesser than 1 are rounded up
+	// to 1. Defaults to a uniform distribution between 2 and 10.
+	BatchSize Distribution
+	// Keys is the distribution of partition keys, i.e. the values of the first column. Defaults to a uniform
+	// distribution of DefaultKeyCount keys.
+	Keys Distribution
+	// ValueSize is the distribution of the sizes of variable-length values, in bytes for blobs, in characters for
+	// strings. Defaults to a constant DefaultValueSize.
+	ValueSize Distribution
+	// NullRatio is the probability, between 0 and 1, that a value other than the partition key is null. Defaults to
+	// zero.
+	NullRatio float64
+	// PageSize is the page size of QUERY and EXECUTE requests. Defaults to DefaultPageSize.
+	PageSize int32
+
+	codecs []datacodec.Codec
+	rand   *rand.Rand
+}
+
+// NewGenerator creates a new Generator for the given statement, with default options. The columns are the types of
+// the statement bind markers, in order. An error is returned if one of the column types is not supported by the
+// protocol version, if no codec is available to encode it, or if the Generator does not know how to generate values
+// for it.
+func NewGenerator(
+	version primitive.ProtocolVersion,
+	query string,
+	columns []datatype.DataType,
+	seed int64,
+) (*Generator, error) {
+	codecs := make([]datacodec.Codec, len(columns))
+	for i, column := range columns {
+		if !supportedColumnTypes[column.Code()] {
+			return nil, fmt.Errorf("unsupported type for column %d: %v", i, column)
+		} else if err := primitive.CheckValidDataTypeCode(column.Code(), version); err != nil {
+			return nil, fmt.Errorf("invalid type for column %d: %w", i, err)
+		}
+		var err error
+		if codecs[i], err = datacodec.NewCodec(column); err != nil {
+			return nil, fmt.Errorf("cannot create codec for column %d: %w", i, err)
+		}
+	}
+	return &Generator{
+		Version:       version,
+		Query:         query,
+		Operations:    map[primitive.OpCode]int{primitive.OpCodeQuery: 1},
+		Consistencies: map[primitive.ConsistencyLevel]int{primitive.ConsistencyLevelLocalOne: 1},
+		BatchType:     primitive.BatchTypeUnlogged,
+		BatchSize:     NewUniformDistribution(2, 10),
+		Keys:          NewUniformDistribution(0, DefaultKeyCount-1),
+		ValueSize:     NewConstantDistribution(DefaultValueSize),
+		PageSize:      DefaultPageSize,
+		codecs:        codecs,
+		rand:          rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Generate generates the given number of request frames, see Next.
+func (g *Generator) Generate(count int) ([]*frame.Frame, error) {
+	frames := make([]*frame.Frame, 0, count)
+	for i := 0; i < count; i++ {
+		f, err := g.Next()
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+// Next generates the next request frame. An error is returned if the operation or consistency level mixes are
+// invalid, if the mix includes EXECUTE requests but PreparedId is not set, or if a value cannot be encoded.
+func (g *Generator) Next() (*frame.Frame, error) {
+	opCode, err := g.nextOpCode()
+	if err != nil {
+		return nil, err
+	}
+	consistency, err := g.nextConsistency()
+	if err != nil {
+		return nil, err
+	}
+	var msg message.Message
+	switch opCode {
+	case primitive.OpCodeQuery:
+		values, err := g.nextValues()
+		if err != nil {
+			return nil, err
+		}
+		msg = &message.Query{Query: g.Query, Options: g.queryOptions(consistency, values)}
+	case primitive.OpCodeExecute:
+		values, err := g.nextValues()
+		if err != nil {
+			return nil, err
+		}
+		msg = &message.Execute{
+			QueryId:          g.PreparedId,
+			ResultMetadataId: g.ResultMetadataId,
+			Options:          g.queryOptions(consistency, values),
+		}
+	default:
+		batch := &message.Batch{Type: g.BatchType, Consistency: consistency}
+		size := g.BatchSize.Next(g.rand)
+		if size < 1 {
+			size = 1
+		}
+		for i := 0; i < size; i++ {
+			values, err := g.nextValues()
+			if err != nil {
+				return nil, err
+			}
+			child := &message.BatchChild{Values: values}
+			if len(g.PreparedId) > 0 {
+				child.Id = g.PreparedId
+			} else {
+				child.Query = g.Query
+			}
+			batch.Children = append(batch.Children, child)
+		}
+		msg = batch
+	}
+	return frame.NewFrame(g.Version, 0, msg), nil
+}
+
+func (g *Generator) queryOptions(consistency primitive.ConsistencyLevel, values []*primitive.Value) *message.QueryOptions {
+	return &message.QueryOptions{
+		Consistency:      consistency,
+		PositionalValues: values,
+		PageSize:         g.PageSize,
+	}
+}
+
+func (g *Generator) nextOpCode() (primitive.OpCode, error) {
+	weights := map[int]int{}
+	for opCode, weight := range g.Operations {
+		if opCode != primitive.OpCodeQuery && opCode != primitive.OpCodeExecute && opCode != primitive.OpCodeBatch {
+			return 0, fmt.Errorf("invalid operation mix: unsupported operation %v", opCode)
+		}
+		weights[int(opCode)] = weight
+	}
+	choice, err := chooseWeighted(g.rand, weights)
+	if err != nil {
+		return 0, fmt.Errorf("invalid operation mix: %w", err)
+	}
+	opCode := primitive.OpCode(choice)
+	if opCode == primitive.OpCodeExecute && len(g.PreparedId) == 0 {
+		return 0, fmt.Errorf("invalid operation mix: EXECUTE requests require a prepared id")
+	}
+	return opCode, nil
+}
+
+func (g *Generator) nextConsistency() (primitive.ConsistencyLevel, error) {
+	weights := map[int]int{}
+	for consistency, weight := range g.Consistencies {
+		weights[int(consistency)] = weight
+	}
+	choice, err := chooseWeighted(g.rand, weights)
+	if err != nil {
+		return 0, fmt.Errorf("invalid consistency level mix: %w", err)
+	}
+	return primitive.ConsistencyLevel(choice), nil
+}
+
+// chooseWeighted draws one of the keys of the given map, with a probability proportional to its weight. Keys are
+// visited in sorted order, so that draws are deterministic for a given source of randomness.
+func chooseWeighted(r *rand.Rand, weights map[int]int) (int, error) {
+	keys := make([]int, 0, len(weights))
+	total := 0
+	for key, weight := range weights {
+		if weight < 0 {
+			return 0, fmt.Errorf("negative weight %d", weight)
+		}
+		keys = append(keys, key)
+		total += weight
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("total weight is zero")
+	}
+	sort.Ints(keys)
+	draw := r.Intn(total)
+	for _, key := range keys {
+		if draw < weights[key] {
+			return key, nil
+		}
+		draw -= weights[key]
+	}
+	panic("unreachable")
+}
+
+func (g *Generator) nextValues() ([]*primitive.Value, error) {
+	values := make([]*primitive.Value, len(g.codecs))
+	for i, codec := range g.codecs {
+		if i > 0 && g.NullRatio > 0 && g.rand.Float64() < g.NullRatio {
+			values[i] = primitive.NewValue(nil)
+			continue
+		}
+		encoded, err := codec.Encode(g.nextValue(codec.DataType(), i == 0), g.Version)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode value for column %d: %w", i, err)
+		}
+		values[i] = primitive.NewValue(encoded)
+	}
+	return values, nil
+}
+
+// nextValue generates a value of the given type. Keys are derived from the Keys distribution, so that equal keys
+// produce equal values.
+func (g *Generator) nextValue(dt datatype.DataType, key bool) interface{} {
+	var n int64
+	if key {
+		n = int64(g.Keys.Next(g.rand))
+	} else {
+		n = g.rand.Int63()
+	}
+	switch dt.Code() {
+	case primitive.DataTypeCodeAscii, primitive.DataTypeCodeVarchar:
+		if key {
+			return fmt.Sprintf("key%d", n)
+		}
+		value := make([]byte, g.nextValueSize())
+		for i := range value {
+			value[i] = valueLetters[g.rand.Intn(len(valueLetters))]
+		}
+		return string(value)
+	case primitive.DataTypeCodeBlob:
+		if key {
+			value := make([]byte, 8)
+			binary.BigEndian.PutUint64(value, uint64(n))
+			return value
+		}
+		value := make([]byte, g.nextValueSize())
+		g.rand.Read(value)
+		return value
+	case primitive.DataTypeCodeBoolean:
+		return n%2 == 0
+	case primitive.DataTypeCodeInt:
+		return int32(n)
+	case primitive.DataTypeCodeBigint, primitive.DataTypeCodeCounter:
+		return n
+	case primitive.DataTypeCodeSmallint:
+		return int16(n)
+	case primitive.DataTypeCodeTinyint:
+		return int8(n)
+	case primitive.DataTypeCodeFloat:
+		return float32(n % (1 << 24))
+	case primitive.DataTypeCodeDouble:
+		return float64(n)
+	case primitive.DataTypeCodeVarint:
+		return big.NewInt(n)
+	case primitive.DataTypeCodeTimestamp:
+		return time.Unix(0, 0).Add(time.Duration(n%maxGeneratedTimestamp) * time.Millisecond).UTC()
+	case primitive.DataTypeCodeDate:
+		return time.Unix(0, 0).UTC().AddDate(0, 0, int(n%36500))
+	case primitive.DataTypeCodeTime:
+		return time.Duration(n % int64(24*time.Hour))
+	default:
+		// UUID or timeuuid
+		var value primitive.UUID
+		if key {
+			binary.BigEndian.PutUint64(value[8:], uint64(n))
+		} else {
+			g.rand.Read(value[:])
+		}
+		if dt.Code() == primitive.DataTypeCodeTimeuuid {
+			value[6] = value[6]&0x0f | 0x10
+		} else {
+			value[6] = value[6]&0x0f | 0x40
+		}
+		value[8] = value[8]&0x3f | 0x80
+		return value
+	}
+}
+
+func (g *Generator) nextValueSize() int {
+	if size := g.ValueSize.Next(g.rand); size > 0 {
+		return size
+	}
+	return 0
+}
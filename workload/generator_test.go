@@ -0,0 +1,236 @@
+// Copyright 2020 DataStax
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datastax/go-cassandra-native-protocol/datacodec"
+	"github.com/datastax/go-cassandra-native-protocol/datatype"
+	"github.com/datastax/go-cassandra-native-protocol/frame"
+	"github.com/datastax/go-cassandra-native-protocol/message"
+	"github.com/datastax/go-cassandra-native-protocol/primitive"
+)
+
+var allColumnTypes = []datatype.DataType{
+	datatype.Bigint, datatype.Ascii, datatype.Blob, datatype.Boolean, datatype.Counter, datatype.Date,
+	datatype.Double, datatype.Float, datatype.Int, datatype.Smallint, datatype.Time, datatype.Timestamp,
+	datatype.Timeuuid, datatype.Tinyint, datatype.Uuid, datatype.Varchar, datatype.Varint,
+}
+
+func TestNewGenerator(t *testing.T) {
+	_, err := NewGenerator(primitive.ProtocolVersion4, "q", []datatype.DataType{datatype.Int, datatype.Decimal}, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported type for column 1: decimal")
+	_, err = NewGenerator(primitive.ProtocolVersion3, "q", []datatype.DataType{datatype.Int, datatype.Date}, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid type for column 1")
+	generator, err := NewGenerator(primitive.ProtocolVersion4, "q", allColumnTypes, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(DefaultPageSize), generator.PageSize)
+	assert.Equal(t, primitive.BatchTypeUnlogged, generator.BatchType)
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	generate := func(seed int64) []*frame.Frame {
+		generator, err := NewGenerator(primitive.ProtocolVersion4, "INSERT", allColumnTypes, seed)
+		require.NoError(t, err)
+		generator.PreparedId = []byte{0xca, 0xfe}
+		generator.Operations = map[primitive.OpCode]int{
+			primitive.OpCodeQuery:   1,
+			primitive.OpCodeExecute: 1,
+			primitive.OpCodeBatch:   1,
+		}
+		generator.Consistencies = map[primitive.ConsistencyLevel]int{
+			primitive.ConsistencyLevelOne:         1,
+			primitive.ConsistencyLevelLocalQuorum: 1,
+		}
+		frames, err := generator.Generate(50)
+		require.NoError(t, err)
+		return frames
+	}
+	assert.Equal(t, generate(42), generate(42))
+	assert.NotEqual(t, generate(42), generate(43))
+}
+
+func TestGenerator_Mix(t *testing.T) {
+	generator, err := NewGenerator(primitive.ProtocolVersion4, "INSERT", []datatype.DataType{datatype.Int}, 1)
+	require.NoError(t, err)
+	generator.PreparedId = []byte{0xca, 0xfe}
+	generator.Operations = map[primitive.OpCode]int{primitive.OpCodeQuery: 3, primitive.OpCodeExecute: 1}
+	generator.Consistencies = map[primitive.ConsistencyLevel]int{
+		primitive.ConsistencyLevelQuorum: 1,
+		primitive.ConsistencyLevelAll:    0,
+	}
+	opCodes := map[primitive.OpCode]int{}
+	frames, err := generator.Generate(1000)
+	require.NoError(t, err)
+	for _, f := range frames {
+		opCodes[f.Header.OpCode]++
+		switch msg := f.Body.Message.(type) {
+		case *message.Query:
+			assert.Equal(t, primitive.ConsistencyLevelQuorum, msg.Options.Consistency)
+		case *message.Execute:
+			assert.Equal(t, []byte{0xca, 0xfe}, msg.QueryId)
+			assert.Equal(t, primitive.ConsistencyLevelQuorum, msg.Options.Consistency)
+		}
+	}
+	assert.Zero(t, opCodes[primitive.OpCodeBatch])
+	assert.InDelta(t, 750, opCodes[primitive.OpCodeQuery], 75)
+	assert.InDelta(t, 250, opCodes[primitive.OpCodeExecute], 75)
+}
+
+func TestGenerator_Batch(t *testing.T) {
+	generator, err := NewGenerator(primitive.ProtocolVersion4, "INSERT", []datatype.DataType{datatype.Varchar}, 1)
+	require.NoError(t, err)
+	generator.Operations = map[primitive.OpCode]int{primitive.OpCodeBatch: 1}
+	generator.BatchSize = NewUniformDistribution(3, 5)
+	generator.Keys = NewConstantDistribution(12)
+	for i := 0; i < 100; i++ {
+		f, err := generator.Next()
+		require.NoError(t, err)
+		batch, ok := f.Body.Message.(*message.Batch)
+		require.True(t, ok)
+		assert.Equal(t, primitive.BatchTypeUnlogged, batch.Type)
+		assert.GreaterOrEqual(t, len(batch.Children), 3)
+		assert.LessOrEqual(t, len(batch.Children), 5)
+		for _, child := range batch.Children {
+			assert.Equal(t, "INSERT", child.Query)
+			assert.Nil(t, child.Id)
+			assert.Equal(t, []byte("key12"), child.Values[0].Contents)
+		}
+	}
+	generator.PreparedId = []byte{1}
+	f, err := generator.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, f.Body.Message.(*message.Batch).Children[0].Id)
+	assert.Empty(t, f.Body.Message.(*message.Batch).Children[0].Query)
+}
+
+func TestGenerator_Values(t *testing.T) {
+	generator, err := NewGenerator(primitive.ProtocolVersion5, "INSERT", allColumnTypes, 1)
+	require.NoError(t, err)
+	generator.ValueSize = NewUniformDistribution(10, 20)
+	generator.NullRatio = 0.2
+	nulls := 0
+	frames, err := generator.Generate(100)
+	require.NoError(t, err)
+	for _, f := range frames {
+		values := f.Body.Message.(*message.Query).Options.PositionalValues
+		require.Len(t, values, len(allColumnTypes))
+		assert.Equal(t, primitive.ValueTypeRegular, values[0].Type)
+		for i, value := range values {
+			if value.Type == primitive.ValueTypeNull {
+				nulls++
+				continue
+			}
+			codec, err := datacodec.NewCodec(allColumnTypes[i])
+			require.NoError(t, err)
+			var dest interface{}
+			_, err = codec.Decode(value.Contents, &dest, primitive.ProtocolVersion5)
+			require.NoError(t, err, "column %d", i)
+			switch allColumnTypes[i] {
+			case datatype.Ascii, datatype.Varchar:
+				assert.GreaterOrEqual(t, len(dest.(string)), 10)
+				assert.LessOrEqual(t, len(dest.(string)), 20)
+			case datatype.Blob:
+				assert.GreaterOrEqual(t, len(dest.([]byte)), 10)
+				assert.LessOrEqual(t, len(dest.([]byte)), 20)
+			}
+		}
+	}
+	assert.InDelta(t, 0.2*100*float64(len(allColumnTypes)-1), nulls, 100)
+}
+
+func TestGenerator_Encode(t *testing.T) {
+	codec := frame.NewCodec()
+	for _, version := range primitive.SupportedProtocolVersionsGreaterThanOrEqualTo(primitive.ProtocolVersion4) {
+		generator, err := NewGenerator(version, "INSERT", allColumnTypes, 1)
+		require.NoError(t, err)
+		generator.PreparedId = []byte{0xca, 0xfe}
+		generator.Operations = map[primitive.OpCode]int{
+			primitive.OpCodeQuery:   1,
+			primitive.OpCodeExecute: 1,
+			primitive.OpCodeBatch:   1,
+		}
+		if version.SupportsResultMetadataId() {
+			generator.ResultMetadataId = []byte{0xba, 0xbe}
+		}
+		frames, err := generator.Generate(30)
+		require.NoError(t, err)
+		for _, f := range frames {
+			encoded := &bytes.Buffer{}
+			require.NoError(t, codec.EncodeFrame(f, encoded), version.String())
+			decoded, err := codec.DecodeFrame(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, f.Body.Message, decoded.Body.Message)
+		}
+	}
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	generator, err := NewGenerator(primitive.ProtocolVersion4, "INSERT", []datatype.DataType{datatype.Int}, 1)
+	require.NoError(t, err)
+	tests := []struct {
+		name          string
+		operations    map[primitive.OpCode]int
+		consistencies map[primitive.ConsistencyLevel]int
+		expected      string
+	}{
+		{
+			"execute without prepared id",
+			map[primitive.OpCode]int{primitive.OpCodeExecute: 1},
+			nil,
+			"invalid operation mix: EXECUTE requests require a prepared id",
+		},
+		{
+			"unsupported operation",
+			map[primitive.OpCode]int{primitive.OpCodePrepare: 1},
+			nil,
+			"invalid operation mix: unsupported operation",
+		},
+		{
+			"empty operation mix",
+			map[primitive.OpCode]int{},
+			nil,
+			"invalid operation mix: total weight is zero",
+		},
+		{
+			"negative consistency weight",
+			nil,
+			map[primitive.ConsistencyLevel]int{primitive.ConsistencyLevelOne: -1},
+			"invalid consistency level mix: negative weight -1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator.Operations = map[primitive.OpCode]int{primitive.OpCodeQuery: 1}
+			generator.Consistencies = map[primitive.ConsistencyLevel]int{primitive.ConsistencyLevelOne: 1}
+			if tt.operations != nil {
+				generator.Operations = tt.operations
+			}
+			if tt.consistencies != nil {
+				generator.Consistencies = tt.consistencies
+			}
+			_, err := generator.Next()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}